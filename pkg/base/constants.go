@@ -46,6 +46,10 @@ const (
 	// stores profiles when the periodic CPU profile dump is enabled.
 	CPUProfileDir = "pprof_dump"
 
+	// ContinuousProfileDir is the directory name where the continuous profiler
+	// stores the CPU and heap profiles it periodically collects.
+	ContinuousProfileDir = "continuous_profiles"
+
 	// MinRangeMaxBytes is the minimum value for range max bytes.
 	MinRangeMaxBytes = 64 << 10 // 64 KB
 )
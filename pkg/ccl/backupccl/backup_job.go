@@ -214,6 +214,7 @@ func backup(
 		roachpb.MVCCFilter(backupManifest.MVCCFilter),
 		backupManifest.StartTime,
 		backupManifest.EndTime,
+		backupSpanFingerprintsEnabled.Get(&settings.SV),
 	)
 	if err != nil {
 		return RowCount{}, err
@@ -262,6 +262,7 @@ func runBackupProcessor(
 					TargetFileSize:                      targetFileSize,
 					ReturnSST:                           writeSSTsInProcessor,
 					OmitChecksum:                        true,
+					Fingerprint:                         spec.Fingerprint,
 				}
 
 				// If we're doing re-attempts but are not yet in the priority regime,
@@ -358,6 +359,7 @@ func runBackupProcessor(
 						Sha512:      file.Sha512,
 						EntryCounts: countRows(file.Exported, spec.PKIDs),
 						LocalityKV:  file.LocalityKV,
+						Fingerprint: file.Fingerprint,
 					}
 					if span.start != spec.BackupStartTime {
 						f.StartTime = span.start
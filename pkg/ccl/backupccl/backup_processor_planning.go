@@ -37,6 +37,7 @@ func distBackupPlanSpecs(
 	encryption *jobspb.BackupEncryptionOptions,
 	mvccFilter roachpb.MVCCFilter,
 	startTime, endTime hlc.Timestamp,
+	fingerprint bool,
 ) (map[roachpb.NodeID]*execinfrapb.BackupDataSpec, error) {
 	user := execCtx.User()
 	execCfg := execCtx.ExecCfg()
@@ -94,6 +95,7 @@ func distBackupPlanSpecs(
 			BackupStartTime:  startTime,
 			BackupEndTime:    endTime,
 			UserProto:        user.EncodeProto(),
+			Fingerprint:      fingerprint,
 		}
 		nodeToSpec[partition.Node] = spec
 	}
@@ -115,6 +117,7 @@ func distBackupPlanSpecs(
 				BackupStartTime:  startTime,
 				BackupEndTime:    endTime,
 				UserProto:        user.EncodeProto(),
+				Fingerprint:      fingerprint,
 			}
 			nodeToSpec[partition.Node] = spec
 		}
@@ -0,0 +1,397 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobsprotectedts"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	// Register the postgres driver used to connect to peer clusters.
+	_ "github.com/lib/pq"
+)
+
+// backupSpanFingerprintsEnabled controls whether backups record the
+// fingerprints of the spans they contain.
+var backupSpanFingerprintsEnabled = settings.RegisterBoolSetting(
+	"bulkio.backup.span_fingerprints.enabled",
+	"if set, backups record fingerprints of the data they contain, against which "+
+		"crdb_internal.check_fingerprints can check the live data later on",
+	false,
+)
+
+// fingerprintCheckpointInterval is the minimum interval between two updates
+// of the progress of a fingerprint check job.
+const fingerprintCheckpointInterval = 10 * time.Second
+
+type fingerprintCheckResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &fingerprintCheckResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *fingerprintCheckResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	p := execCtx.(sql.JobExecContext)
+	details := r.job.Details().(jobspb.FingerprintCheckDetails)
+
+	var mismatches []jobspb.FingerprintCheckProgress_Mismatch
+	var err error
+	if sql.IsPeerClusterURI(details.Source) {
+		mismatches, err = r.checkAgainstPeer(ctx, p, details)
+	} else {
+		mismatches, err = r.checkAgainstBackup(ctx, p, details)
+	}
+	if err != nil {
+		return err
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	var buf strings.Builder
+	for i, m := range mismatches {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%s (expected %d keys with fingerprint %x, found %d keys with fingerprint %x)",
+			m.Span, m.ExpectedKeyCount, m.ExpectedFingerprint, m.ActualKeyCount, m.ActualFingerprint)
+	}
+	return errors.Errorf("%d diverging spans: %s", len(mismatches), buf.String())
+}
+
+// checkAgainstBackup compares the fingerprints recorded in the most recent
+// backup in the collection at details.Source, including its incremental
+// layers, with those of the live data as of the end time of its last layer.
+func (r *fingerprintCheckResumer) checkAgainstBackup(
+	ctx context.Context, p sql.JobExecContext, details jobspb.FingerprintCheckDetails,
+) ([]jobspb.FingerprintCheckProgress_Mismatch, error) {
+	layers, err := readLatestBackupChain(ctx, p, details.Source)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := expectedFingerprints(layers, details.Span)
+	if err != nil {
+		return nil, errors.WithHintf(
+			errors.Wrapf(err, "backup in %s", RedactURIForErrorMessage(details.Source)),
+			"set %s to record them in future backups", "bulkio.backup.span_fingerprints.enabled")
+	}
+
+	endTime := layers[len(layers)-1].EndTime
+	spans := make([]roachpb.Span, len(expected))
+	for i := range expected {
+		spans[i] = expected[i].Span
+	}
+	release, err := r.protectTimestamp(ctx, p.ExecCfg(), spans, endTime)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	db := p.ExecCfg().DB
+	return r.compareFingerprints(ctx, len(expected),
+		func(i int) (roachpb.SpanFingerprint, roachpb.SpanFingerprint, error) {
+			actual := roachpb.SpanFingerprint{Span: expected[i].Span}
+			fps, err := db.FingerprintSpan(ctx, actual.Span, endTime)
+			if err != nil {
+				return roachpb.SpanFingerprint{}, roachpb.SpanFingerprint{}, err
+			}
+			for _, fp := range fps {
+				actual.Combine(fp)
+			}
+			return expected[i], actual, nil
+		},
+	)
+}
+
+// readLatestBackupChain reads the manifests of the most recent backup in a
+// collection, followed by those of the incremental layers appended to it, in
+// order. If the collection has a LATEST file, the backup it points to is used.
+func readLatestBackupChain(
+	ctx context.Context, p sql.JobExecContext, collectionURI string,
+) ([]BackupManifest, error) {
+	makeCloudStorage := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI
+	uri := collectionURI
+	collection, err := makeCloudStorage(ctx, collectionURI, p.User())
+	if err != nil {
+		return nil, err
+	}
+	defer collection.Close()
+	latestFile, err := collection.ReadFile(ctx, latestFileName)
+	if err == nil {
+		latest, err := ioutil.ReadAll(latestFile)
+		latestFile.Close()
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := url.Parse(collectionURI)
+		if err != nil {
+			return nil, err
+		}
+		parsed.Path = path.Join(parsed.Path, string(latest))
+		uri = parsed.String()
+	} else if !errors.Is(err, cloudimpl.ErrFileDoesNotExist) {
+		return nil, err
+	}
+
+	store, err := makeCloudStorage(ctx, uri, p.User())
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	base, err := readBackupManifestFromStore(ctx, store, nil /* encryption */)
+	if err != nil {
+		return nil, err
+	}
+	layerNames, err := findPriorBackupNames(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	layers := []BackupManifest{base}
+	for _, name := range layerNames {
+		layer, err := readBackupManifest(ctx, store, name, nil /* encryption */)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// expectedFingerprints returns the fingerprints of the data contained in a
+// chain of backup layers as of the end time of the last one, for the parts of
+// its spans that overlap span. They are computed by combining the fingerprints
+// of the files of all the layers: those of the full backup at the base of the
+// chain, and of the spans introduced by later layers, are the fingerprints of
+// their data, while those of the other files of incremental layers are the
+// changes made to it since the previous layer.
+//
+// The files of earlier layers may straddle the boundaries of the spans of the
+// last one, e.g. if the indexes of a table changed in between, so the spans
+// over which fingerprints are returned are those of the last layer merged with
+// all the files that overlap them.
+func expectedFingerprints(
+	layers []BackupManifest, span roachpb.Span,
+) ([]roachpb.SpanFingerprint, error) {
+	var latest, spans []roachpb.Span
+	for _, s := range layers[len(layers)-1].Spans {
+		if s.Overlaps(span) {
+			latest = append(latest, s)
+		}
+	}
+	spans = append(spans, latest...)
+	for _, layer := range layers {
+		for _, f := range layer.Files {
+			spans = append(spans, f.Span)
+		}
+	}
+	spans = coalesceOverlappingSpans(spans)
+	// Skip the spans that only contain files of spans that were dropped from
+	// later layers.
+	keep := make([]bool, len(spans))
+	for _, s := range latest {
+		keep[sort.Search(len(spans), func(i int) bool {
+			return s.Key.Compare(spans[i].EndKey) < 0
+		})] = true
+	}
+	var fps []roachpb.SpanFingerprint
+	for i, s := range spans {
+		if keep[i] {
+			fps = append(fps, roachpb.SpanFingerprint{Span: s})
+		}
+	}
+	for _, layer := range layers {
+		for _, f := range layer.Files {
+			i := sort.Search(len(fps), func(i int) bool {
+				return f.Span.Key.Compare(fps[i].Span.EndKey) < 0
+			})
+			if i == len(fps) || !fps[i].Span.Contains(f.Span) {
+				continue
+			}
+			if f.Fingerprint == nil {
+				return nil, errors.Newf("layer ending at %s does not contain span fingerprints",
+					layer.EndTime)
+			}
+			fps[i].Combine(*f.Fingerprint)
+		}
+	}
+	return fps, nil
+}
+
+// coalesceOverlappingSpans sorts spans and merges those that overlap. Unlike
+// roachpb.MergeSpans, it does not merge adjacent spans.
+func coalesceOverlappingSpans(spans []roachpb.Span) []roachpb.Span {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Key.Compare(spans[j].Key) < 0
+	})
+	var res []roachpb.Span
+	for _, s := range spans {
+		if n := len(res); n > 0 && s.Key.Compare(res[n-1].EndKey) < 0 {
+			if res[n-1].EndKey.Compare(s.EndKey) < 0 {
+				res[n-1].EndKey = s.EndKey
+			}
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// protectTimestamp protects the data in spans as of ts from garbage collection
+// while it is being fingerprinted, and verifies that it has not been collected
+// already. The returned function releases the protection.
+func (r *fingerprintCheckResumer) protectTimestamp(
+	ctx context.Context, execCfg *sql.ExecutorConfig, spans []roachpb.Span, ts hlc.Timestamp,
+) (release func(), _ error) {
+	// Tenants cannot protect timestamps.
+	if len(spans) == 0 || !execCfg.Codec.ForSystemTenant() {
+		return func() {}, nil
+	}
+	pts := execCfg.ProtectedTimestampProvider
+	rec := jobsprotectedts.MakeRecord(uuid.MakeV4(), *r.job.ID(), ts, spans)
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		return pts.Protect(ctx, txn, rec)
+	}); err != nil {
+		return nil, err
+	}
+	release = func() {
+		if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+			return pts.Release(ctx, txn, rec.ID)
+		}); err != nil {
+			log.Warningf(ctx, "failed to release protected timestamp record %s: %v", rec.ID, err)
+		}
+	}
+	if err := pts.Verify(ctx, rec.ID); err != nil {
+		release()
+		return nil, errors.Wrapf(err, "data as of %s may already have been garbage collected", ts)
+	}
+	return release, nil
+}
+
+// checkAgainstPeer compares the fingerprints of the live data in each range
+// touching details.Span with those of the same span in the peer cluster that
+// details.Source connects to, both as of details.AsOf.
+func (r *fingerprintCheckResumer) checkAgainstPeer(
+	ctx context.Context, p sql.JobExecContext, details jobspb.FingerprintCheckDetails,
+) ([]jobspb.FingerprintCheckProgress_Mismatch, error) {
+	release, err := r.protectTimestamp(
+		ctx, p.ExecCfg(), []roachpb.Span{details.Span}, details.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	actual, err := p.ExecCfg().DB.FingerprintSpan(ctx, details.Span, details.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	peer, err := gosql.Open("postgres", details.Source)
+	if err != nil {
+		return nil, err
+	}
+	defer peer.Close()
+
+	query := fmt.Sprintf(`SELECT COALESCE(xor_agg(fingerprint), 0), COALESCE(sum(key_count), 0)
+FROM crdb_internal.span_fingerprints($1, $2) AS OF SYSTEM TIME '%s'`, details.AsOf.AsOfSystemTime())
+	return r.compareFingerprints(ctx, len(actual),
+		func(i int) (roachpb.SpanFingerprint, roachpb.SpanFingerprint, error) {
+			span := actual[i].Span
+			var fingerprint, keyCount int64
+			if err := peer.QueryRowContext(ctx, query, []byte(span.Key), []byte(span.EndKey)).Scan(
+				&fingerprint, &keyCount,
+			); err != nil {
+				return roachpb.SpanFingerprint{}, roachpb.SpanFingerprint{},
+					errors.Wrapf(err, "fingerprinting %s on peer cluster", span)
+			}
+			expected := roachpb.SpanFingerprint{
+				Span: span, Fingerprint: uint64(fingerprint), KeyCount: keyCount,
+			}
+			return expected, actual[i], nil
+		},
+	)
+}
+
+// compareFingerprints compares n pairs of expected and actual fingerprints
+// returned by fingerprints, recording the progress of the job as well as any
+// mismatches in its progress details.
+func (r *fingerprintCheckResumer) compareFingerprints(
+	ctx context.Context,
+	n int,
+	fingerprints func(i int) (expected, actual roachpb.SpanFingerprint, _ error),
+) ([]jobspb.FingerprintCheckProgress_Mismatch, error) {
+	var mismatches []jobspb.FingerprintCheckProgress_Mismatch
+	lastCheckpoint := timeutil.Now()
+	checkpoint := func(done int) error {
+		lastCheckpoint = timeutil.Now()
+		return r.job.FractionProgressed(ctx,
+			func(ctx context.Context, details jobspb.ProgressDetails) float32 {
+				prog := details.(*jobspb.Progress_FingerprintCheck).FingerprintCheck
+				prog.Mismatches = mismatches
+				return float32(done) / float32(n)
+			},
+		)
+	}
+	for i := 0; i < n; i++ {
+		exp, act, err := fingerprints(i)
+		if err != nil {
+			return nil, err
+		}
+		mismatch := act.Fingerprint != exp.Fingerprint || act.KeyCount != exp.KeyCount
+		if mismatch {
+			log.Warningf(ctx, "fingerprint of %s diverges: expected %d keys with fingerprint %x, "+
+				"found %d keys with fingerprint %x",
+				exp.Span, exp.KeyCount, exp.Fingerprint, act.KeyCount, act.Fingerprint)
+			mismatches = append(mismatches, jobspb.FingerprintCheckProgress_Mismatch{
+				Span:                exp.Span,
+				ExpectedFingerprint: exp.Fingerprint,
+				ExpectedKeyCount:    exp.KeyCount,
+				ActualFingerprint:   act.Fingerprint,
+				ActualKeyCount:      act.KeyCount,
+			})
+		}
+		if mismatch || i == n-1 || timeutil.Since(lastCheckpoint) > fingerprintCheckpointInterval {
+			if err := checkpoint(i + 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return mismatches, nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (r *fingerprintCheckResumer) OnFailOrCancel(context.Context, interface{}) error {
+	return nil
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeFingerprintCheck,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &fingerprintCheckResumer{job: job}
+		},
+	)
+}
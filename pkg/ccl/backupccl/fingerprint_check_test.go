@@ -0,0 +1,173 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprintCheck(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	defer jobs.TestingSetAdoptAndCancelIntervals(100*time.Millisecond, 100*time.Millisecond)()
+
+	const numAccounts = 100
+	ctx, tc, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	var tableID uint32
+	sqlDB.QueryRow(t, `SELECT 'data.bank'::regclass::int`).Scan(&tableID)
+	tablePrefix := keys.SystemSQLCodec.TablePrefix(tableID)
+	tableSpan := roachpb.Span{Key: tablePrefix, EndKey: tablePrefix.PrefixEnd()}
+	startKey, endKey := []byte(tableSpan.Key), []byte(tableSpan.EndKey)
+
+	// checkFingerprints runs a fingerprint check job and returns its error, if
+	// it failed.
+	checkFingerprints := func(t *testing.T, source string) string {
+		var jobID int64
+		sqlDB.QueryRow(t, `SELECT crdb_internal.check_fingerprints($1, $2, $3)`,
+			startKey, endKey, source,
+		).Scan(&jobID)
+		var status, jobErr string
+		testutils.SucceedsSoon(t, func() error {
+			sqlDB.QueryRow(t, `SELECT status, error FROM [SHOW JOBS] WHERE job_id = $1`, jobID).
+				Scan(&status, &jobErr)
+			if status != string(jobs.StatusSucceeded) && status != string(jobs.StatusFailed) {
+				return errors.Newf("job is %s", status)
+			}
+			return nil
+		})
+		return jobErr
+	}
+
+	t.Run("backup without fingerprints", func(t *testing.T) {
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, LocalFoo+"/none")
+		require.Regexp(t, "does not contain span fingerprints",
+			checkFingerprints(t, LocalFoo+"/none"))
+	})
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.backup.span_fingerprints.enabled = true`)
+
+	t.Run("backup collection", func(t *testing.T) {
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, LocalFoo+"/collection")
+		// Changes made after the backup do not matter, as the data is compared
+		// as of the end time of the backup.
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id = 1`)
+		require.Equal(t, "", checkFingerprints(t, LocalFoo+"/collection"))
+
+		// Incremental backups appended to the latest backup are used over its
+		// base.
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, LocalFoo+"/collection")
+		sqlDB.Exec(t, `DELETE FROM data.bank WHERE id = 2`)
+		require.Equal(t, "", checkFingerprints(t, LocalFoo+"/collection"))
+
+		// The changes recorded by each incremental layer are accounted for.
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, LocalFoo+"/collection")
+		require.Equal(t, "", checkFingerprints(t, LocalFoo+"/collection"))
+	})
+
+	t.Run("diverging backup", func(t *testing.T) {
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, LocalFoo+"/diverging")
+
+		// Tamper with the fingerprints of the table in the backup.
+		execCfg := tc.Server(0).ExecutorConfig().(sql.ExecutorConfig)
+		store, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, LocalFoo+"/diverging",
+			security.RootUserName())
+		require.NoError(t, err)
+		defer store.Close()
+		manifest, err := readBackupManifestFromStore(ctx, store, nil /* encryption */)
+		require.NoError(t, err)
+		tampered := false
+		for i := range manifest.Files {
+			if manifest.Files[i].Span.Overlaps(tableSpan) {
+				manifest.Files[i].Fingerprint.Fingerprint++
+				tampered = true
+				break
+			}
+		}
+		require.True(t, tampered)
+		require.NoError(t, writeBackupManifest(ctx, execCfg.Settings, store, backupManifestName,
+			nil /* encryption */, &manifest))
+
+		require.Regexp(t, "1 diverging spans: /Table/[0-9]+",
+			checkFingerprints(t, LocalFoo+"/diverging"))
+	})
+
+	t.Run("peer cluster", func(t *testing.T) {
+		pgURL, cleanup := sqlutils.PGUrl(t, tc.Server(0).ServingSQLAddr(),
+			"TestFingerprintCheck", url.User(security.RootUser))
+		defer cleanup()
+		require.Equal(t, "", checkFingerprints(t, pgURL.String()))
+
+		sqlDB.ExpectErr(t, "as_of can only be specified when checking against a peer cluster",
+			`SELECT crdb_internal.check_fingerprints($1, $2, $3, now())`,
+			startKey, endKey, LocalFoo+"/collection")
+	})
+}
+
+func TestExpectedFingerprints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sp := func(start, end string) roachpb.Span {
+		return roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+	}
+	file := func(start, end string, fp uint64, keyCount int64) BackupManifest_File {
+		return BackupManifest_File{
+			Span:        sp(start, end),
+			Fingerprint: &roachpb.SpanFingerprint{Span: sp(start, end), Fingerprint: fp, KeyCount: keyCount},
+		}
+	}
+	layers := []BackupManifest{
+		{
+			Spans: []roachpb.Span{sp("a", "c"), sp("e", "f"), sp("x", "y")},
+			Files: []BackupManifest_File{
+				file("a", "c", 1, 3), file("e", "f", 4, 4), file("x", "y", 8, 8),
+			},
+		},
+		{
+			// The span [x, y) was dropped, and [a, c) was split and widened, so
+			// the file of the first layer straddles the new spans.
+			Spans: []roachpb.Span{sp("a", "b"), sp("b", "d"), sp("e", "f")},
+			Files: []BackupManifest_File{file("b", "d", 16, -1), file("e", "f", 32, 1)},
+		},
+	}
+
+	fps, err := expectedFingerprints(layers, sp("a", "z"))
+	require.NoError(t, err)
+	require.Equal(t, []roachpb.SpanFingerprint{
+		{Span: sp("a", "d"), Fingerprint: 1 ^ 16, KeyCount: 2},
+		{Span: sp("e", "f"), Fingerprint: 4 ^ 32, KeyCount: 5},
+	}, fps)
+
+	fps, err = expectedFingerprints(layers, sp("e", "g"))
+	require.NoError(t, err)
+	require.Equal(t, []roachpb.SpanFingerprint{
+		{Span: sp("e", "f"), Fingerprint: 4 ^ 32, KeyCount: 5},
+	}, fps)
+
+	layers[0].Files[0].Fingerprint = nil
+	_, err = expectedFingerprints(layers, sp("a", "z"))
+	require.Regexp(t, "does not contain span fingerprints", err)
+	_, err = expectedFingerprints(layers, sp("e", "g"))
+	require.NoError(t, err)
+}
@@ -112,6 +112,10 @@ CREATE TABLE data2.foo (a int);
 	sqlDB.Exec(t, `GRANT CREATE, SELECT ON DATABASE data TO system_ops;`)
 	sqlDB.Exec(t, `GRANT system_ops TO maxroach1;`)
 
+	// Populate system.alert_rules.
+	sqlDB.Exec(t, `INSERT INTO system.alert_rules (name, metric, threshold, duration) VALUES ($1, $2, $3, $4)`,
+		"high_conns", "cr.node.sql.conns", 100, "5m")
+
 	// Populate system.scheduled_jobs table.
 	sqlDB.Exec(t, `CREATE SCHEDULE FOR BACKUP data.bank INTO $1 RECURRING '@hourly' FULL BACKUP ALWAYS`, LocalFoo)
 
@@ -167,6 +171,7 @@ CREATE TABLE data2.foo (a int);
 		// Note the absence of the jobs table. Jobs are tested by another test as
 		// jobs are created during the RESTORE process.
 		systemTablesToVerify := []string{
+			systemschema.AlertRulesTable.GetName(),
 			systemschema.CommentsTable.GetName(),
 			systemschema.LocationsTable.GetName(),
 			systemschema.RoleMembersTable.GetName(),
@@ -508,6 +513,7 @@ func TestClusterRestoreFailCleanup(t *testing.T) {
 		sqlDBRestore.CheckQueryResults(t,
 			`SELECT name FROM crdb_internal.tables WHERE state = 'DROP' ORDER BY name`,
 			[][]string{
+				{"alert_rules"},
 				{"bank"},
 				{"comments"},
 				{"jobs"},
@@ -548,6 +554,7 @@ func TestClusterRestoreFailCleanup(t *testing.T) {
 		sqlDBRestore.CheckQueryResults(t,
 			`SELECT name FROM crdb_internal.tables WHERE state = 'DROP' ORDER BY name`,
 			[][]string{
+				{"alert_rules"},
 				{"bank"},
 				{"comments"},
 				{"jobs"},
@@ -162,6 +162,9 @@ var systemTableBackupConfiguration = map[string]systemBackupConfiguration{
 	systemschema.CommentsTable.GetName(): {
 		includeInClusterBackup: optInToClusterBackup,
 	},
+	systemschema.AlertRulesTable.GetName(): {
+		includeInClusterBackup: optInToClusterBackup,
+	},
 	systemschema.JobsTable.GetName(): {
 		includeInClusterBackup: optInToClusterBackup,
 		customRestoreFunc:      jobsRestoreFunc,
@@ -194,6 +197,9 @@ var systemTableBackupConfiguration = map[string]systemBackupConfiguration{
 	systemschema.ProtectedTimestampsRecordsTable.GetName(): {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
+	systemschema.PublicationsTable.GetName(): {
+		includeInClusterBackup: optOutOfClusterBackup,
+	},
 	systemschema.RangeEventTable.GetName(): {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
@@ -209,6 +215,9 @@ var systemTableBackupConfiguration = map[string]systemBackupConfiguration{
 	systemschema.ReplicationStatsTable.GetName(): {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
+	systemschema.ReplicationSlotsTable.GetName(): {
+		includeInClusterBackup: optOutOfClusterBackup,
+	},
 	systemschema.SqllivenessTable.GetName(): {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
@@ -221,9 +230,24 @@ var systemTableBackupConfiguration = map[string]systemBackupConfiguration{
 	systemschema.StatementDiagnosticsRequestsTable.GetName(): {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
+	systemschema.StatementHintsTable.GetName(): {
+		includeInClusterBackup: optOutOfClusterBackup,
+	},
+	systemschema.StatementStatisticsTable.GetName(): {
+		includeInClusterBackup: optOutOfClusterBackup,
+	},
 	systemschema.TenantsTable.GetName(): {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
+	systemschema.TenantUsageTable.GetName(): {
+		includeInClusterBackup: optOutOfClusterBackup,
+	},
+	systemschema.TransactionContentionEventsTable.GetName(): {
+		includeInClusterBackup: optOutOfClusterBackup,
+	},
+	systemschema.TransactionStatisticsTable.GetName(): {
+		includeInClusterBackup: optOutOfClusterBackup,
+	},
 	systemschema.WebSessionsTable.GetName(): {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
@@ -160,11 +160,12 @@ func changefeedPlanHook(
 		}
 		var initialHighWater hlc.Timestamp
 		if cursor, ok := opts[changefeedbase.OptCursor]; ok {
-			asOf := tree.AsOfClause{Expr: tree.NewStrVal(cursor)}
-			var err error
-			if initialHighWater, err = p.EvalAsOfTimestamp(ctx, asOf); err != nil {
+			asOfClause := tree.AsOfClause{Expr: tree.NewStrVal(cursor)}
+			asOf, err := p.EvalAsOfTimestamp(ctx, asOfClause)
+			if err != nil {
 				return err
 			}
+			initialHighWater = asOf.Timestamp
 			statementTime = initialHighWater
 		}
 
@@ -1486,6 +1486,54 @@ func TestChangefeedTruncateOrDrop(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedDropTableRangeTombstone(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `ALTER TABLE foo CONFIGURE ZONE USING gc.ttlseconds = 1`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1)`)
+		var tableID uint32
+		sqlDB.QueryRow(t, `SELECT 'foo'::REGCLASS::INT`).Scan(&tableID)
+		var cursor string
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&cursor)
+
+		// The data of the dropped table is deleted with a range tombstone while
+		// a changefeed runs on it.
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo`)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{`foo: [1]->{"after": {"a": 1}}`})
+		sqlDB.Exec(t, `DROP TABLE foo`)
+		prefix := keys.SystemSQLCodec.TablePrefix(tableID)
+		testutils.SucceedsSoon(t, func() error {
+			kvs, err := f.Server().DB().Scan(context.Background(), prefix, prefix.PrefixEnd(), 0 /* maxRows */)
+			if err != nil {
+				return err
+			}
+			if len(kvs) != 0 {
+				return errors.Errorf("expected the data of foo to be deleted, found %d keys", len(kvs))
+			}
+			return nil
+		})
+		if _, err := foo.Next(); !testutils.IsError(err, `"foo" was dropped`) {
+			t.Errorf(`expected ""foo" was dropped" error got: %+v`, err)
+		}
+
+		// A changefeed which starts before the drop catches up on the range
+		// tombstone, and fails because the table was dropped.
+		caughtUp := feed(t, f, `CREATE CHANGEFEED FOR foo WITH cursor=$1`, cursor)
+		defer closeFeed(t, caughtUp)
+		if _, err := caughtUp.Next(); !testutils.IsError(err, `"foo" was dropped`) {
+			t.Errorf(`expected ""foo" was dropped" error got: %+v`, err)
+		}
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedMonitoring(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
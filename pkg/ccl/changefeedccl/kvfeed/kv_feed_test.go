@@ -73,6 +73,14 @@ func TestKVFeed(t *testing.T) {
 			},
 		}
 	}
+	deleteRangeEvent := func(span roachpb.Span, ts hlc.Timestamp) roachpb.RangeFeedEvent {
+		return roachpb.RangeFeedEvent{
+			DeleteRange: &roachpb.RangeFeedDeleteRange{
+				Span:      span,
+				Timestamp: ts,
+			},
+		}
+	}
 	type testCase struct {
 		name               string
 		needsInitialScan   bool
@@ -173,6 +181,25 @@ func TestKVFeed(t *testing.T) {
 			},
 			expEvents: 1,
 		},
+		{
+			name:               "range deletion",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			needsInitialScan:   true,
+			initialHighWater:   ts(2),
+			spans: []roachpb.Span{
+				tableSpan(42),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(3)),
+				deleteRangeEvent(tableSpan(42), ts(4)),
+				kvEvent(42, "a", "b", ts(5)),
+			},
+			expScans: []hlc.Timestamp{
+				ts(2),
+			},
+			expEvents: 2,
+		},
 		{
 			name:               "one table event - backfill",
 			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
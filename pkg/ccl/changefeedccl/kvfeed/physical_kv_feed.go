@@ -104,6 +104,13 @@ func (p *rangefeed) addEventsToBuffer(ctx context.Context) error {
 				if err := p.memBuf.AddResolved(ctx, t.Span, t.ResolvedTS, false); err != nil {
 					return err
 				}
+			case *roachpb.RangeFeedDeleteRange:
+				// Range deletions only delete the data of dropped tables, which
+				// the schema feed stops the changefeed at, and the data ingested
+				// by a rolled back IMPORT into an empty table, which is offline
+				// and whose ingested rows rangefeeds don't emit either. The
+				// changefeed thus has no rows to delete.
+				continue
 			default:
 				log.Fatalf(ctx, "unexpected RangeFeedEvent variant %v", t)
 			}
@@ -0,0 +1,457 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	foreignTableOptLocation = "location"
+	foreignTableOptFormat   = "format"
+)
+
+// parquetAllowedOptions are the options of foreign tables backed by parquet
+// files. Parquet is not supported by IMPORT.
+var parquetAllowedOptions = makeStringSet(avroStrict, csvRowLimit)
+
+// createForeignTableOpts validates the OPTIONS of a CREATE FOREIGN TABLE
+// statement. Apart from the location and format of the backing files, the
+// options are the same as the ones IMPORT accepts for the given format.
+func createForeignTableOpts(
+	ctx context.Context, p sql.PlanHookState, opts map[string]string,
+) (*descpb.TableDescriptor_ForeignTableOpts, error) {
+	location := opts[foreignTableOptLocation]
+	if location == "" {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"the %s option is required for foreign tables", foreignTableOptLocation)
+	}
+
+	// Certain ExternalStorage URIs require super-user access, as they would
+	// otherwise allow reading arbitrary files on the nodes.
+	hasExplicitAuth, uriScheme, err := cloud.AccessIsWithExplicitAuth(location)
+	if err != nil {
+		return nil, err
+	}
+	if !hasExplicitAuth {
+		if err := p.RequireAdminRole(ctx,
+			fmt.Sprintf("CREATE FOREIGN TABLE with the specified %s URI", uriScheme)); err != nil {
+			return nil, err
+		}
+	}
+
+	formatOpts := make(map[string]string, len(opts))
+	for k, v := range opts {
+		if k != foreignTableOptLocation && k != foreignTableOptFormat {
+			formatOpts[k] = v
+		}
+	}
+
+	var format roachpb.IOFileFormat
+	switch formatName := strings.ToUpper(opts[foreignTableOptFormat]); formatName {
+	case "CSV":
+		if err := validateForeignTableFormatOptions(formatName, formatOpts, csvAllowedOptions); err != nil {
+			return nil, err
+		}
+		if err := parseCSVOptions(formatOpts, &format); err != nil {
+			return nil, err
+		}
+	case "AVRO":
+		if err := validateForeignTableFormatOptions(formatName, formatOpts, avroAllowedOptions); err != nil {
+			return nil, err
+		}
+		if err := parseAvroOptions(ctx, formatOpts, p, &format); err != nil {
+			return nil, err
+		}
+	case "PARQUET":
+		if err := validateForeignTableFormatOptions(formatName, formatOpts, parquetAllowedOptions); err != nil {
+			return nil, err
+		}
+		if err := parseParquetOptions(formatOpts, &format); err != nil {
+			return nil, err
+		}
+	case "":
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"the %s option is required for foreign tables", foreignTableOptFormat)
+	default:
+		return nil, unimplemented.Newf("foreign_table.format",
+			"unsupported foreign table format: %q", opts[foreignTableOptFormat])
+	}
+	if err := parseDecompressOption(formatOpts, &format); err != nil {
+		return nil, err
+	}
+
+	return &descpb.TableDescriptor_ForeignTableOpts{
+		Locations: []string{location},
+		Format:    format,
+	}, nil
+}
+
+func parseParquetOptions(opts map[string]string, format *roachpb.IOFileFormat) error {
+	format.Format = roachpb.IOFileFormat_Parquet
+	// Parquet files are read with random access, and their pages are
+	// compressed by the file format itself.
+	if _, ok := opts[importOptionDecompress]; ok {
+		return errors.Errorf("option %q is not supported for the PARQUET foreign table format",
+			importOptionDecompress)
+	}
+	_, format.Parquet.StrictMode = opts[avroStrict]
+	if override, ok := opts[csvRowLimit]; ok {
+		rowLimit, err := strconv.Atoi(override)
+		if err != nil {
+			return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", csvRowLimit)
+		}
+		if rowLimit <= 0 {
+			return pgerror.Newf(pgcode.Syntax, "%s must be > 0", csvRowLimit)
+		}
+		format.Parquet.RowLimit = int64(rowLimit)
+	}
+	return nil
+}
+
+// validateForeignTableFormatOptions is like validateFormatOptions, but only
+// allows the options that make sense when the files are read in place. It
+// also checks that options which do not take a value were not given one.
+func validateForeignTableFormatOptions(
+	format string, specified map[string]string, formatAllowed map[string]struct{},
+) error {
+	for opt, val := range specified {
+		if _, ok := formatAllowed[opt]; !ok && opt != importOptionDecompress {
+			return errors.Errorf("invalid option %q specified for %s foreign table format", opt, format)
+		}
+		if importOptionExpectValues[opt] == sql.KVStringOptRequireNoValue && val != "" {
+			return errors.Errorf("option %q does not take a value", opt)
+		}
+	}
+	return nil
+}
+
+// expandForeignTableLocations expands any glob patterns in the locations of a
+// foreign table. The returned files are sorted so that the rows of a foreign
+// table, and the rowids synthesized for them, are stable across scans.
+func expandForeignTableLocations(
+	ctx context.Context,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+	locations []string,
+	user security.SQLUsername,
+) ([]string, error) {
+	var files []string
+	for _, location := range locations {
+		if !cloudimpl.URINeedsGlobExpansion(location) {
+			files = append(files, location)
+			continue
+		}
+		s, err := makeExternalStorageFromURI(ctx, location, user)
+		if err != nil {
+			return nil, err
+		}
+		expanded, err := s.ListFiles(ctx, "")
+		s.Close()
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(expanded)
+		files = append(files, expanded...)
+	}
+	return files, nil
+}
+
+const foreignTableScanProcessorName = "foreignTableScan"
+
+// foreignTableScanProcessor is a processor that does not take any inputs. It
+// reads the files backing a foreign table using the IMPORT readers and emits
+// one row per record, containing all of the public columns of the table.
+// The files are read sequentially by a worker goroutine started in Start(),
+// and every record is assigned a rowid derived from its file and position, so
+// that rows are emitted in primary key order.
+type foreignTableScanProcessor struct {
+	execinfra.ProcessorBase
+
+	flowCtx *execinfra.FlowCtx
+	spec    execinfrapb.ForeignTableScanSpec
+	desc    catalog.TableDescriptor
+	// colTypes are the types of all public columns of the table, which are the
+	// types of the rows produced by the scan, before any post-processing.
+	colTypes []*types.T
+
+	rowCh    chan rowenc.EncDatumRow
+	stopScan context.CancelFunc
+	scanErr  error
+}
+
+var _ execinfra.Processor = &foreignTableScanProcessor{}
+var _ execinfra.RowSource = &foreignTableScanProcessor{}
+
+func newForeignTableScanProcessor(
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec execinfrapb.ForeignTableScanSpec,
+	post *execinfrapb.PostProcessSpec,
+	output execinfra.RowReceiver,
+) (execinfra.Processor, error) {
+	fs := &foreignTableScanProcessor{
+		flowCtx: flowCtx,
+		spec:    spec,
+		rowCh:   make(chan rowenc.EncDatumRow, 64),
+	}
+	desc := tabledesc.NewImmutable(fs.spec.Table)
+	if !desc.IsForeignTable() {
+		return nil, errors.AssertionFailedf("%q is not a foreign table", desc.GetName())
+	}
+	fs.desc = desc
+	fs.colTypes = desc.ColumnTypes()
+	// The column types alias the types in the descriptor, so hydrating them in
+	// Init also hydrates the types used to decode the records.
+	if err := fs.Init(fs, post, fs.colTypes, flowCtx, processorID, output, nil, /* memMonitor */
+		execinfra.ProcStateOpts{
+			// This processor doesn't have any inputs to drain.
+			InputsToDrain: nil,
+			TrailingMetaCallback: func(context.Context) []execinfrapb.ProducerMetadata {
+				fs.close()
+				return nil
+			},
+		}); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// Start is part of the RowSource interface.
+func (fs *foreignTableScanProcessor) Start(ctx context.Context) context.Context {
+	ctx = fs.StartInternal(ctx, foreignTableScanProcessorName)
+	scanCtx, stopScan := fs.flowCtx.Stopper().WithCancelOnQuiesce(ctx)
+	fs.stopScan = stopScan
+	// The task stops sending rows once the processor is closed or the server
+	// quiesces, and Next loops over rowCh, which is closed when it returns.
+	if err := fs.flowCtx.Stopper().RunAsyncTask(scanCtx, "foreign-table-scan", func(ctx context.Context) {
+		defer close(fs.rowCh)
+		fs.scanErr = fs.scan(ctx)
+	}); err != nil {
+		// The task never ran, so rowCh has to be closed here for Next to return.
+		fs.scanErr = err
+		close(fs.rowCh)
+	}
+	return ctx
+}
+
+// Next is part of the RowSource interface.
+func (fs *foreignTableScanProcessor) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetadata) {
+	for fs.State == execinfra.StateRunning {
+		row, ok := <-fs.rowCh
+		if !ok {
+			fs.MoveToDraining(fs.scanErr)
+			break
+		}
+		if outRow := fs.ProcessRowHelper(row); outRow != nil {
+			return outRow, nil
+		}
+	}
+	return nil, fs.DrainHelper()
+}
+
+// ConsumerClosed is part of the RowSource interface.
+func (fs *foreignTableScanProcessor) ConsumerClosed() {
+	// The consumer is done, Next() will not be called again.
+	fs.close()
+}
+
+func (fs *foreignTableScanProcessor) close() {
+	if fs.InternalClose() {
+		if fs.stopScan != nil {
+			fs.stopScan()
+		}
+	}
+}
+
+// scan reads all files backing the table in order and sends the decoded rows
+// on rowCh.
+func (fs *foreignTableScanProcessor) scan(ctx context.Context) error {
+	opts := fs.desc.TableDesc().ForeignTableOpts
+	files, err := expandForeignTableLocations(
+		ctx, fs.flowCtx.Cfg.ExternalStorageFromURI, opts.Locations, fs.spec.User(),
+	)
+	if err != nil {
+		return err
+	}
+
+	r := &foreignTableReader{
+		format:    opts.Format,
+		typs:      fs.colTypes,
+		rowCh:     fs.rowCh,
+		importCtx: &parallelImportContext{evalCtx: fs.EvalCtx, tableDesc: fs.desc},
+	}
+	r.conv, err = row.NewDatumRowConverter(
+		ctx, fs.desc, nil /* targetColNames */, fs.EvalCtx, nil /* kvCh */, nil, /* seqChunkProvider */
+	)
+	if err != nil {
+		return err
+	}
+	r.datums = make(tree.Datums, len(r.typs))
+
+	for i, file := range files {
+		if opts.Format.Format == roachpb.IOFileFormat_Parquet {
+			if err := r.readParquetFile(
+				ctx, int32(i), file, fs.flowCtx.Cfg.ExternalStorageFromURI, fs.spec.User(),
+			); err != nil {
+				return err
+			}
+			continue
+		}
+		// Files are read one at a time, rather than all at once by a single
+		// call to readInputFiles, so that they are read in order.
+		if err := readInputFiles(
+			ctx, map[int32]string{int32(i): file}, nil /* resumePos */, opts.Format,
+			r.readFile, fs.flowCtx.Cfg.ExternalStorage, fs.spec.User(),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// foreignTableReader decodes the records of the files backing a foreign
+// table into rows.
+type foreignTableReader struct {
+	format    roachpb.IOFileFormat
+	typs      []*types.T
+	rowCh     chan<- rowenc.EncDatumRow
+	importCtx *parallelImportContext
+	conv      *row.DatumRowConverter
+	datums    tree.Datums
+}
+
+// readFile implements the readFileFunc signature. The records are read
+// sequentially, so that every record is assigned a stable rowid.
+func (r *foreignTableReader) readFile(
+	ctx context.Context, input *fileReader, inputIdx int32, _ int64, _ chan string,
+) error {
+	var producer importRowProducer
+	var consumer importRowConsumer
+	var skip, rowLimit int64
+	switch r.format.Format {
+	case roachpb.IOFileFormat_CSV:
+		c := &csvInputReader{
+			importCtx:           r.importCtx,
+			numExpectedDataCols: len(r.importCtx.tableDesc.VisibleColumns()),
+			opts:                r.format.Csv,
+		}
+		producer, consumer = newCSVPipeline(c, input)
+		skip, rowLimit = int64(r.format.Csv.Skip), r.format.Csv.RowLimit
+	case roachpb.IOFileFormat_Avro:
+		a := &avroInputReader{importContext: r.importCtx, opts: r.format.Avro}
+		var err error
+		if producer, consumer, err = newImportAvroPipeline(a, input); err != nil {
+			return err
+		}
+		rowLimit = r.format.Avro.RowLimit
+	default:
+		return errors.Errorf("unsupported foreign table format %s", r.format.Format)
+	}
+	return r.readRecords(ctx, inputIdx, producer, consumer, skip, rowLimit)
+}
+
+// readParquetFile reads a parquet file. Unlike the other formats, parquet
+// files are not read by readInputFiles, since they cannot be read as a
+// stream.
+func (r *foreignTableReader) readParquetFile(
+	ctx context.Context,
+	inputIdx int32,
+	file string,
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory,
+	user security.SQLUsername,
+) error {
+	es, err := makeExternalStorageFromURI(ctx, file, user)
+	if err != nil {
+		return err
+	}
+	defer es.Close()
+	f, err := openSeekableExternalFile(ctx, es, "")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	producer, consumer, err := newParquetPipeline(r.importCtx, r.format.Parquet, f)
+	if err != nil {
+		return err
+	}
+	return r.readRecords(ctx, inputIdx, producer, consumer, 0 /* skip */, r.format.Parquet.RowLimit)
+}
+
+// readRecords converts the records of a single file into rows and sends them
+// on rowCh.
+func (r *foreignTableReader) readRecords(
+	ctx context.Context,
+	inputIdx int32,
+	producer importRowProducer,
+	consumer importRowConsumer,
+	skip, rowLimit int64,
+) error {
+	var count int64
+	for producer.Scan() {
+		count++
+		if count <= skip {
+			if err := producer.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		if rowLimit != 0 && count-skip > rowLimit {
+			break
+		}
+		record, err := producer.Row()
+		if err != nil {
+			return err
+		}
+		if err := consumer.FillDatums(record, count, r.conv); err != nil {
+			return err
+		}
+		if err := r.conv.GenerateRow(inputIdx, count, r.datums); err != nil {
+			return newImportRowError(err, fmt.Sprintf("%v", record), count)
+		}
+		encRow := make(rowenc.EncDatumRow, len(r.datums))
+		for i, d := range r.datums {
+			encRow[i] = rowenc.DatumToEncDatum(r.typs[i], d)
+		}
+		select {
+		case r.rowCh <- encRow:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return producer.Err()
+}
+
+func init() {
+	sql.CreateForeignTableOptsCCL = createForeignTableOpts
+	rowexec.NewForeignTableScanProcessor = newForeignTableScanProcessor
+}
@@ -0,0 +1,186 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForeignTableCSV(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	require.NoError(t, os.Mkdir(filepath.Join(baseDir, "ft"), 0755))
+	for name, data := range map[string]string{
+		"ft/1.csv": "a,b\n1,one\n2,two\n",
+		"ft/2.csv": "a,b\n3,three\n",
+		"bad.csv":  "x,y\n",
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(baseDir, name), []byte(data), 0644))
+	}
+
+	tc := testcluster.StartTestCluster(
+		t, 1, base.TestClusterArgs{ServerArgs: base.TestServerArgs{ExternalIODir: baseDir}})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+
+	sqlDB.Exec(t, `CREATE FOREIGN TABLE one (a INT, b STRING)
+		OPTIONS (location = 'nodelocal://0/ft/1.csv', format = 'csv', skip = '1')`)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM one`, [][]string{{"1", "one"}, {"2", "two"}})
+
+	sqlDB.Exec(t, `CREATE FOREIGN TABLE glob (a INT, b STRING)
+		OPTIONS (location = 'nodelocal://0/ft/*.csv', format = 'csv', skip = '1')`)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM glob`,
+		[][]string{{"1", "one"}, {"2", "two"}, {"3", "three"}})
+	sqlDB.CheckQueryResults(t, `SELECT b FROM glob WHERE a > 1 ORDER BY a DESC`,
+		[][]string{{"three"}, {"two"}})
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM glob JOIN one USING (a)`, [][]string{{"2"}})
+
+	t.Run("scans", func(t *testing.T) {
+		// Foreign tables can only be read in full, in primary key order, so
+		// filters on the primary key, reverse orderings and joins all have to be
+		// planned on top of a full scan.
+		sqlDB.CheckQueryResults(t, `SELECT b FROM glob WHERE rowid = (SELECT max(rowid) FROM glob)`,
+			[][]string{{"three"}})
+		sqlDB.CheckQueryResults(t, `SELECT a FROM glob ORDER BY rowid DESC`,
+			[][]string{{"3"}, {"2"}, {"1"}})
+		sqlDB.CheckQueryResults(t, `SELECT a FROM glob ORDER BY rowid DESC LIMIT 1`, [][]string{{"3"}})
+		sqlDB.CheckQueryResults(t, `SELECT a FROM glob ORDER BY rowid LIMIT 1`, [][]string{{"1"}})
+		sqlDB.CheckQueryResults(t,
+			`SELECT g.b FROM (SELECT max(rowid) AS r FROM one) JOIN glob AS g ON g.rowid = r`,
+			[][]string{{"two"}})
+		sqlDB.ExpectErr(t, `could not produce a query plan conforming to the LOOKUP JOIN hint`,
+			`SELECT * FROM one INNER LOOKUP JOIN glob ON one.rowid = glob.rowid`)
+		sqlDB.ExpectErr(t, `index flags not allowed with foreign tables`, `SELECT * FROM one@primary`)
+		sqlDB.ExpectErr(t, `FOR UPDATE not allowed with foreign tables`, `SELECT * FROM one FOR UPDATE`)
+	})
+
+	t.Run("if-not-exists", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE FOREIGN TABLE IF NOT EXISTS one (x INT)
+			OPTIONS (location = 'nodelocal://0/ft/2.csv', format = 'csv')`)
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM one`, [][]string{{"2"}})
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `cannot mutate foreign table "one"`, `INSERT INTO one VALUES (4, 'four')`)
+		sqlDB.ExpectErr(t, `cannot mutate foreign table "one"`, `DELETE FROM one`)
+		sqlDB.ExpectErr(t, `cannot create index on foreign table "one"`, `CREATE INDEX ON one (a)`)
+		sqlDB.ExpectErr(t, `cannot truncate foreign table "one"`, `TRUNCATE one`)
+		sqlDB.ExpectErr(t, `cannot alter foreign table "one"`, `ALTER TABLE one ADD COLUMN c INT`)
+		sqlDB.Exec(t, `DROP TABLE glob`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `the location option is required`,
+			`CREATE FOREIGN TABLE bad (a INT) OPTIONS (format = 'csv')`)
+		sqlDB.ExpectErr(t, `the format option is required`,
+			`CREATE FOREIGN TABLE bad (a INT) OPTIONS (location = 'nodelocal://0/bad.csv')`)
+		sqlDB.ExpectErr(t, `unsupported foreign table format: "orc"`,
+			`CREATE FOREIGN TABLE bad (a INT) OPTIONS (location = 'nodelocal://0/bad.csv', format = 'orc')`)
+		sqlDB.ExpectErr(t, `invalid option "data_as_json_records"`,
+			`CREATE FOREIGN TABLE bad (a INT) OPTIONS (location = 'nodelocal://0/bad.csv', format = 'csv', data_as_json_records)`)
+		sqlDB.ExpectErr(t, `foreign table columns only support a type and nullability`,
+			`CREATE FOREIGN TABLE bad (a INT PRIMARY KEY) OPTIONS (location = 'nodelocal://0/bad.csv', format = 'csv')`)
+		sqlDB.ExpectErr(t, `is not supported on foreign tables`,
+			`CREATE FOREIGN TABLE bad (a INT, INDEX (a)) OPTIONS (location = 'nodelocal://0/bad.csv', format = 'csv')`)
+
+		sqlDB.Exec(t, `CREATE FOREIGN TABLE bad (a INT, b INT)
+			OPTIONS (location = 'nodelocal://0/bad.csv', format = 'csv')`)
+		sqlDB.ExpectErr(t, `could not parse "x" as type int`, `SELECT * FROM bad`)
+	})
+}
+
+func TestForeignTableParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 a;
+		optional binary b (STRING);
+		optional int32 d (DATE);
+		optional int64 ts (TIMESTAMP(MICROS, true));
+		optional double extra;
+	}`)
+	require.NoError(t, err)
+	writeParquet := func(name string, rows []map[string]interface{}) {
+		f, err := os.Create(filepath.Join(baseDir, name))
+		require.NoError(t, err)
+		defer f.Close()
+		w := goparquet.NewFileWriter(f,
+			goparquet.WithSchemaDefinition(sd),
+			goparquet.WithCompressionCodec(parquet.CompressionCodec_SNAPPY),
+		)
+		for _, row := range rows {
+			require.NoError(t, w.AddData(row))
+		}
+		require.NoError(t, w.Close())
+	}
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 8000, time.UTC)
+	writeParquet("1.parquet", []map[string]interface{}{
+		{"a": int64(1), "b": []byte("one"), "d": int32(18690), "ts": ts.UnixNano() / 1000, "extra": 1.5},
+		{"a": int64(2), "b": []byte("two")},
+	})
+	writeParquet("2.parquet", []map[string]interface{}{
+		{"a": int64(3)},
+	})
+
+	tc := testcluster.StartTestCluster(
+		t, 1, base.TestClusterArgs{ServerArgs: base.TestServerArgs{ExternalIODir: baseDir}})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+
+	sqlDB.Exec(t, `CREATE FOREIGN TABLE p (a INT, b STRING, d DATE, ts TIMESTAMPTZ, c INT)
+		OPTIONS (location = 'nodelocal://0/*.parquet', format = 'parquet')`)
+	sqlDB.CheckQueryResults(t, `SELECT a, b, d, ts, c FROM p`, [][]string{
+		{"1", "one", "2021-03-04 00:00:00 +0000 +0000", "2021-03-04 05:06:07.000008 +0000 UTC", "NULL"},
+		{"2", "two", "NULL", "NULL", "NULL"},
+		{"3", "NULL", "NULL", "NULL", "NULL"},
+	})
+	sqlDB.CheckQueryResults(t, `SELECT a FROM p WHERE b IS NOT NULL ORDER BY rowid DESC`,
+		[][]string{{"2"}, {"1"}})
+
+	sqlDB.Exec(t, `CREATE FOREIGN TABLE limited (a INT)
+		OPTIONS (location = 'nodelocal://0/*.parquet', format = 'parquet', row_limit = '1')`)
+	sqlDB.CheckQueryResults(t, `SELECT a FROM limited`, [][]string{{"1"}, {"3"}})
+
+	sqlDB.Exec(t, `CREATE FOREIGN TABLE strict (a INT, b STRING)
+		OPTIONS (location = 'nodelocal://0/1.parquet', format = 'parquet', strict_validation)`)
+	sqlDB.ExpectErr(t, `could not find column for record field`, `SELECT * FROM strict`)
+
+	sqlDB.ExpectErr(t, `option "decompress" is not supported for the PARQUET foreign table format`,
+		`CREATE FOREIGN TABLE bad (a INT)
+			OPTIONS (location = 'nodelocal://0/1.parquet', format = 'parquet', decompress = 'gzip')`)
+	sqlDB.ExpectErr(t, `invalid option "skip"`,
+		`CREATE FOREIGN TABLE bad (a INT)
+			OPTIONS (location = 'nodelocal://0/1.parquet', format = 'parquet', skip = '1')`)
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -426,54 +427,8 @@ func importPlanHook(
 			if err = validateFormatOptions(importStmt.FileFormat, opts, csvAllowedOptions); err != nil {
 				return err
 			}
-			format.Format = roachpb.IOFileFormat_CSV
-			// Set the default CSV separator for the cases when it is not overwritten.
-			format.Csv.Comma = ','
-			if override, ok := opts[csvDelimiter]; ok {
-				comma, err := util.GetSingleRune(override)
-				if err != nil {
-					return pgerror.Wrap(err, pgcode.Syntax, "invalid comma value")
-				}
-				format.Csv.Comma = comma
-			}
-
-			if override, ok := opts[csvComment]; ok {
-				comment, err := util.GetSingleRune(override)
-				if err != nil {
-					return pgerror.Wrap(err, pgcode.Syntax, "invalid comment value")
-				}
-				format.Csv.Comment = comment
-			}
-
-			if override, ok := opts[csvNullIf]; ok {
-				format.Csv.NullEncoding = &override
-			}
-
-			if override, ok := opts[csvSkip]; ok {
-				skip, err := strconv.Atoi(override)
-				if err != nil {
-					return pgerror.Wrapf(err, pgcode.Syntax, "invalid %s value", csvSkip)
-				}
-				if skip < 0 {
-					return pgerror.Newf(pgcode.Syntax, "%s must be >= 0", csvSkip)
-				}
-				format.Csv.Skip = uint32(skip)
-			}
-			if _, ok := opts[csvStrictQuotes]; ok {
-				format.Csv.StrictQuotes = true
-			}
-			if _, ok := opts[importOptionSaveRejected]; ok {
-				format.SaveRejected = true
-			}
-			if override, ok := opts[csvRowLimit]; ok {
-				rowLimit, err := strconv.Atoi(override)
-				if err != nil {
-					return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", csvRowLimit)
-				}
-				if rowLimit <= 0 {
-					return pgerror.Newf(pgcode.Syntax, "%s must be > 0", csvRowLimit)
-				}
-				format.Csv.RowLimit = int64(rowLimit)
+			if err := parseCSVOptions(opts, &format); err != nil {
+				return err
 			}
 		case "DELIMITED":
 			if err = validateFormatOptions(importStmt.FileFormat, opts, mysqlOutAllowedOptions); err != nil {
@@ -655,18 +610,8 @@ func importPlanHook(
 			skipFKs = true
 		}
 
-		if override, ok := opts[importOptionDecompress]; ok {
-			found := false
-			for name, value := range roachpb.IOFileFormat_Compression_value {
-				if strings.EqualFold(name, override) {
-					format.Compression = roachpb.IOFileFormat_Compression(value)
-					found = true
-					break
-				}
-			}
-			if !found {
-				return unimplemented.Newf("import.compression", "unsupported compression value: %q", override)
-			}
+		if err := parseDecompressOption(opts, &format); err != nil {
+			return err
 		}
 
 		var tableDetails []jobspb.ImportDetails_Table
@@ -919,6 +864,79 @@ func importPlanHook(
 	return fn, utilccl.BulkJobExecutionResultHeader, nil, false, nil
 }
 
+// parseCSVOptions populates format from the CSV specific options in opts.
+func parseCSVOptions(opts map[string]string, format *roachpb.IOFileFormat) error {
+	format.Format = roachpb.IOFileFormat_CSV
+	// Set the default CSV separator for the cases when it is not overwritten.
+	format.Csv.Comma = ','
+	if override, ok := opts[csvDelimiter]; ok {
+		comma, err := util.GetSingleRune(override)
+		if err != nil {
+			return pgerror.Wrap(err, pgcode.Syntax, "invalid comma value")
+		}
+		format.Csv.Comma = comma
+	}
+
+	if override, ok := opts[csvComment]; ok {
+		comment, err := util.GetSingleRune(override)
+		if err != nil {
+			return pgerror.Wrap(err, pgcode.Syntax, "invalid comment value")
+		}
+		format.Csv.Comment = comment
+	}
+
+	if override, ok := opts[csvNullIf]; ok {
+		format.Csv.NullEncoding = &override
+	}
+
+	if override, ok := opts[csvSkip]; ok {
+		skip, err := strconv.Atoi(override)
+		if err != nil {
+			return pgerror.Wrapf(err, pgcode.Syntax, "invalid %s value", csvSkip)
+		}
+		if skip < 0 {
+			return pgerror.Newf(pgcode.Syntax, "%s must be >= 0", csvSkip)
+		}
+		format.Csv.Skip = uint32(skip)
+	}
+	if _, ok := opts[csvStrictQuotes]; ok {
+		format.Csv.StrictQuotes = true
+	}
+	if _, ok := opts[importOptionSaveRejected]; ok {
+		format.SaveRejected = true
+	}
+	if override, ok := opts[csvRowLimit]; ok {
+		rowLimit, err := strconv.Atoi(override)
+		if err != nil {
+			return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", csvRowLimit)
+		}
+		if rowLimit <= 0 {
+			return pgerror.Newf(pgcode.Syntax, "%s must be > 0", csvRowLimit)
+		}
+		format.Csv.RowLimit = int64(rowLimit)
+	}
+	return nil
+}
+
+// parseDecompressOption sets the compression of format from the decompress
+// option in opts, if it is present.
+func parseDecompressOption(opts map[string]string, format *roachpb.IOFileFormat) error {
+	if override, ok := opts[importOptionDecompress]; ok {
+		found := false
+		for name, value := range roachpb.IOFileFormat_Compression_value {
+			if strings.EqualFold(name, override) {
+				format.Compression = roachpb.IOFileFormat_Compression(value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return unimplemented.Newf("import.compression", "unsupported compression value: %q", override)
+		}
+	}
+	return nil
+}
+
 func parseAvroOptions(
 	ctx context.Context, opts map[string]string, p sql.PlanHookState, format *roachpb.IOFileFormat,
 ) error {
@@ -1680,7 +1698,17 @@ func (r *importResumer) dropTables(
 		}
 	}
 
+	// Tables which were empty before the IMPORT are rolled back by deleting all
+	// their data. Where possible, this uses MVCC range tombstones rather than
+	// clearing the data, which would also remove its history.
+	useRangeTombstones := execCfg.Settings.Version.IsActive(ctx, clusterversion.MVCCRangeTombstones)
 	for i := range empty {
+		if useRangeTombstones && !empty[i].IsInterleaved() {
+			if err := gcjob.DeleteTableDataWithRangeTombstones(ctx, execCfg.DB, execCfg.Codec, empty[i]); err != nil {
+				return errors.Wrapf(err, "deleting data for table %d", empty[i].GetID())
+			}
+			continue
+		}
 		if err := gcjob.ClearTableData(ctx, execCfg.DB, execCfg.DistSender, execCfg.Codec, empty[i]); err != nil {
 			return errors.Wrapf(err, "clearing data for table %d", empty[i].GetID())
 		}
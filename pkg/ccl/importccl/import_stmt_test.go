@@ -2607,6 +2607,7 @@ func TestImportIntoCSV(t *testing.T) {
 	tc := testcluster.StartTestCluster(t, nodes, base.TestClusterArgs{ServerArgs: base.TestServerArgs{ExternalIODir: baseDir}})
 	defer tc.Stopper().Stop(ctx)
 	conn := tc.Conns[0]
+	kvDB := tc.Server(0).DB()
 
 	var forceFailure bool
 	var importBodyFinished chan struct{}
@@ -2947,6 +2948,48 @@ func TestImportIntoCSV(t *testing.T) {
 		sqlDB.Exec(t, fmt.Sprintf(`IMPORT INTO t (a, b) CSV DATA (%s)`, testFiles.files[1]))
 	})
 
+	// Verify that a failed IMPORT INTO an empty table deletes the imported data
+	// with MVCC range tombstones, which preserve its history.
+	t.Run("import-into-empty-table-rollback", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY, b STRING)`)
+		defer sqlDB.Exec(t, `DROP TABLE t`)
+		var tableID uint32
+		sqlDB.QueryRow(t, `SELECT 't'::REGCLASS::INT`).Scan(&tableID)
+
+		// Hit a failure during import.
+		forceFailure = true
+		sqlDB.ExpectErr(
+			t, `testing injected failure`,
+			fmt.Sprintf(`IMPORT INTO t (a, b) CSV DATA (%s)`, testFiles.files[1]),
+		)
+		forceFailure = false
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM t`, [][]string{{"0"}})
+
+		// The imported data is deleted, but can still be read at the time it was
+		// ingested.
+		var buf []byte
+		sqlDB.QueryRow(t, `
+SELECT payload FROM system.jobs WHERE id = (
+  SELECT job_id FROM [SHOW JOBS] WHERE job_type = 'IMPORT' ORDER BY created DESC LIMIT 1
+)`).Scan(&buf)
+		var payload jobspb.Payload
+		require.NoError(t, protoutil.Unmarshal(buf, &payload))
+		ingested := hlc.Timestamp{WallTime: payload.GetImport().Walltime}
+		tablePrefix := keys.SystemSQLCodec.TablePrefix(tableID)
+		kvs, err := kvDB.Scan(ctx, tablePrefix, tablePrefix.PrefixEnd(), 0 /* maxRows */)
+		require.NoError(t, err)
+		require.Empty(t, kvs)
+		require.NoError(t, kvDB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+			txn.SetFixedTimestamp(ctx, ingested)
+			kvs, err := txn.Scan(ctx, tablePrefix, tablePrefix.PrefixEnd(), 0 /* maxRows */)
+			if err != nil {
+				return err
+			}
+			require.NotEmpty(t, kvs)
+			return nil
+		}))
+	})
+
 	// Verify that during IMPORT INTO the table is offline.
 	t.Run("offline-state", func(t *testing.T) {
 		sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY, b STRING)`)
@@ -0,0 +1,348 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// parquetRowStream is an importRowProducer which reads the rows of a parquet
+// file one at a time.
+type parquetRowStream struct {
+	reader *goparquet.FileReader
+	row    map[string]interface{}
+	read   int64
+	err    error
+}
+
+var _ importRowProducer = &parquetRowStream{}
+
+// Scan implements importRowProducer interface.
+func (p *parquetRowStream) Scan() bool {
+	if p.err != nil {
+		return false
+	}
+	p.row, p.err = p.reader.NextRow()
+	if p.err == io.EOF {
+		p.err = nil
+		return false
+	}
+	if p.err != nil {
+		return false
+	}
+	p.read++
+	return true
+}
+
+// Err implements importRowProducer interface.
+func (p *parquetRowStream) Err() error {
+	return p.err
+}
+
+// Skip implements importRowProducer interface.
+func (p *parquetRowStream) Skip() error {
+	p.row = nil
+	return nil
+}
+
+// Row implements importRowProducer interface.
+func (p *parquetRowStream) Row() (interface{}, error) {
+	res := p.row
+	p.row = nil
+	return res, nil
+}
+
+// Progress implements importRowProducer interface.
+func (p *parquetRowStream) Progress() float32 {
+	if n := p.reader.NumRows(); n > 0 {
+		return float32(p.read) / float32(n)
+	}
+	return 0
+}
+
+// parquetConsumer is an importRowConsumer which converts the rows produced by
+// parquetRowStream into datums.
+type parquetConsumer struct {
+	// columns maps the normalized name of every column of the parquet file
+	// which has a matching visible column in the table to its schema element,
+	// which describes how its values are encoded.
+	columns        map[string]*parquet.SchemaElement
+	fieldNameToIdx map[string]int
+	strict         bool
+}
+
+var _ importRowConsumer = &parquetConsumer{}
+
+// FillDatums implements importRowConsumer interface.
+func (p *parquetConsumer) FillDatums(
+	native interface{}, rowIndex int64, conv *row.DatumRowConverter,
+) error {
+	record, ok := native.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected native type; expected map[string]interface{} found %T instead", native)
+	}
+
+	// Unlike the records of other formats, the rows of a parquet file do not
+	// necessarily set every column, so clear the datums of the previous row.
+	for i := range conv.Datums {
+		if conv.TargetColOrds.Contains(i) {
+			conv.Datums[i] = nil
+		}
+	}
+	for f, v := range record {
+		field := lexbase.NormalizeName(f)
+		idx, ok := p.fieldNameToIdx[field]
+		if !ok {
+			if p.strict {
+				return fmt.Errorf("could not find column for record field %s", field)
+			}
+			continue
+		}
+		datum, err := parquetValueToDatum(v, conv.VisibleColTypes[idx], p.columns[field], conv.EvalCtx)
+		if err != nil {
+			return errors.Wrapf(err, "column %s", conv.VisibleCols[idx].Name)
+		}
+		conv.Datums[idx] = datum
+	}
+
+	// Optional values which are not set are omitted from the rows returned by
+	// the parquet reader, so set any nil datums to DNull.
+	for i := range conv.Datums {
+		if conv.TargetColOrds.Contains(i) && conv.Datums[i] == nil {
+			if p.strict {
+				return fmt.Errorf("field %s was not set in the parquet file", conv.VisibleCols[i].Name)
+			}
+			conv.Datums[i] = tree.DNull
+		}
+	}
+	return nil
+}
+
+// parquetValueToDatum converts a value read from a parquet file to a datum of
+// the given type. Values with a logical type that do not map directly onto a
+// go type, such as dates and timestamps, are converted according to the
+// schema element of their column. All other values are converted like the
+// native values of an avro record.
+func parquetValueToDatum(
+	v interface{}, targetT *types.T, elem *parquet.SchemaElement, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	var t time.Time
+	switch x := v.(type) {
+	case nil:
+		return tree.DNull, nil
+	case [12]byte:
+		// INT96 is the legacy encoding of timestamps.
+		t = goparquet.Int96ToTime(x)
+	case int32:
+		if !parquetIsDate(elem) {
+			return nativeToDatum(v, targetT, nil /* avroT */, evalCtx)
+		}
+		if targetT.Family() != types.DateFamily {
+			return nil, fmt.Errorf("cannot convert date to %s", targetT)
+		}
+		// Dates are stored as the number of days since the unix epoch.
+		d, err := pgdate.MakeDateFromUnixEpoch(int64(x))
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDDate(d), nil
+	case int64:
+		unit, ok := parquetTimestampUnit(elem)
+		if !ok {
+			return nativeToDatum(v, targetT, nil /* avroT */, evalCtx)
+		}
+		// Timestamps are stored as the number of units since the unix epoch.
+		t = timeutil.Unix(0, x*int64(unit))
+	default:
+		return nativeToDatum(v, targetT, nil /* avroT */, evalCtx)
+	}
+
+	var d tree.Datum
+	var err error
+	switch targetT.Family() {
+	case types.TimestampFamily:
+		d, err = tree.MakeDTimestamp(t, time.Microsecond)
+	case types.TimestampTZFamily:
+		d, err = tree.MakeDTimestampTZ(t, time.Microsecond)
+	default:
+		return nil, fmt.Errorf("cannot convert timestamp to %s", targetT)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// parquetIsDate returns whether the int32 values of a column are dates.
+func parquetIsDate(elem *parquet.SchemaElement) bool {
+	if elem == nil {
+		return false
+	}
+	if lt := elem.GetLogicalType(); lt != nil && lt.IsSetDATE() {
+		return true
+	}
+	return elem.IsSetConvertedType() && elem.GetConvertedType() == parquet.ConvertedType_DATE
+}
+
+// parquetTimestampUnit returns the unit of the int64 values of a column if
+// they are timestamps.
+func parquetTimestampUnit(elem *parquet.SchemaElement) (time.Duration, bool) {
+	if elem == nil {
+		return 0, false
+	}
+	if lt := elem.GetLogicalType(); lt != nil && lt.IsSetTIMESTAMP() {
+		switch unit := lt.TIMESTAMP.GetUnit(); {
+		case unit.IsSetMILLIS():
+			return time.Millisecond, true
+		case unit.IsSetMICROS():
+			return time.Microsecond, true
+		case unit.IsSetNANOS():
+			return time.Nanosecond, true
+		}
+	}
+	if elem.IsSetConvertedType() {
+		switch elem.GetConvertedType() {
+		case parquet.ConvertedType_TIMESTAMP_MILLIS:
+			return time.Millisecond, true
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			return time.Microsecond, true
+		}
+	}
+	return 0, false
+}
+
+func newParquetPipeline(
+	importCtx *parallelImportContext, opts roachpb.ParquetOptions, input io.ReadSeeker,
+) (importRowProducer, importRowConsumer, error) {
+	reader, err := goparquet.NewFileReader(input)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "reading parquet file")
+	}
+
+	fieldIdxByName := make(map[string]int)
+	for idx, col := range importCtx.tableDesc.VisibleColumns() {
+		fieldIdxByName[col.Name] = idx
+	}
+	columns := make(map[string]*parquet.SchemaElement)
+	for _, col := range reader.Columns() {
+		name := lexbase.NormalizeName(col.Name())
+		if _, ok := fieldIdxByName[name]; !ok {
+			continue
+		}
+		if !col.DataColumn() {
+			return nil, nil, unimplemented.Newf("parquet.nested",
+				"nested parquet column %s is not supported", col.Name())
+		}
+		columns[name] = col.Element()
+	}
+
+	producer := &parquetRowStream{reader: reader}
+	consumer := &parquetConsumer{
+		columns:        columns,
+		fieldNameToIdx: fieldIdxByName,
+		strict:         opts.StrictMode,
+	}
+	return producer, consumer, nil
+}
+
+// seekableExternalFile adapts a file in external storage to an io.ReadSeeker.
+// Parquet files have to be read out of order, starting with the metadata in
+// their footer, so rather than reading the whole file into memory the file
+// is reopened at the new offset whenever the reader seeks.
+type seekableExternalFile struct {
+	ctx      context.Context
+	es       cloud.ExternalStorage
+	basename string
+	size     int64
+
+	// body is the reader of the file at pos, or nil if it has to be reopened.
+	body io.ReadCloser
+	pos  int64
+}
+
+var _ io.ReadSeeker = &seekableExternalFile{}
+
+func openSeekableExternalFile(
+	ctx context.Context, es cloud.ExternalStorage, basename string,
+) (*seekableExternalFile, error) {
+	// Do an initial read of the file, from the beginning, to get its size,
+	// which is needed to seek relative to the end of the file.
+	body, size, err := es.ReadFileAt(ctx, basename, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &seekableExternalFile{ctx: ctx, es: es, basename: basename, size: size, body: body}, nil
+}
+
+// Read implements the io.Reader interface.
+func (f *seekableExternalFile) Read(p []byte) (int, error) {
+	if f.pos >= f.size {
+		return 0, io.EOF
+	}
+	if f.body == nil {
+		body, _, err := f.es.ReadFileAt(f.ctx, f.basename, f.pos)
+		if err != nil {
+			return 0, err
+		}
+		f.body = body
+	}
+	n, err := f.body.Read(p)
+	f.pos += int64(n)
+	return n, err
+}
+
+// Seek implements the io.Seeker interface.
+func (f *seekableExternalFile) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = f.pos + offset
+	case io.SeekEnd:
+		pos = f.size + offset
+	default:
+		return 0, errors.Newf("invalid whence %d", whence)
+	}
+	if pos < 0 {
+		return 0, errors.Newf("negative position %d", pos)
+	}
+	if pos != f.pos && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.pos = pos
+	return pos, nil
+}
+
+// Close closes the current reader of the file, if any.
+func (f *seekableExternalFile) Close() error {
+	if f.body == nil {
+		return nil
+	}
+	err := f.body.Close()
+	f.body = nil
+	return err
+}
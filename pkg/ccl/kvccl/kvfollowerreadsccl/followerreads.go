@@ -76,7 +76,7 @@ func evalFollowerReadOffset(clusterID uuid.UUID, st *cluster.Settings) (time.Dur
 // batchCanBeEvaluatedOnFollower determines if a batch consists exclusively of
 // requests that can be evaluated on a follower replica.
 func batchCanBeEvaluatedOnFollower(ba roachpb.BatchRequest) bool {
-	return !ba.IsLocking() && ba.IsAllTransactional()
+	return !ba.IsLocking() && (ba.IsAllTransactional() || ba.IsAllQueryResolvedTimestamp())
 }
 
 // txnCanPerformFollowerRead determines if the provided transaction can perform
@@ -91,14 +91,18 @@ func txnCanPerformFollowerRead(txn *roachpb.Transaction) bool {
 
 // canUseFollowerRead determines if a query can be sent to a follower.
 func canUseFollowerRead(clusterID uuid.UUID, st *cluster.Settings, ts hlc.Timestamp) bool {
-	if !kvserver.FollowerReadsEnabled.Get(&st.SV) {
+	if !followerReadsAllowed(clusterID, st) {
 		return false
 	}
 	threshold := (-1 * getFollowerReadDuration(st)) - 1*base.DefaultMaxClockOffset
-	if timeutil.Since(ts.GoTime()) < threshold {
-		return false
-	}
-	return checkEnterpriseEnabled(clusterID, st) == nil
+	return timeutil.Since(ts.GoTime()) >= threshold
+}
+
+// followerReadsAllowed determines whether follower reads are enabled and
+// licensed in the cluster, irrespective of the timestamp of any request.
+func followerReadsAllowed(clusterID uuid.UUID, st *cluster.Settings) bool {
+	return kvserver.FollowerReadsEnabled.Get(&st.SV) &&
+		checkEnterpriseEnabled(clusterID, st) == nil
 }
 
 // canSendToFollower implements the logic for checking whether a batch request
@@ -106,8 +110,18 @@ func canUseFollowerRead(clusterID uuid.UUID, st *cluster.Settings, ts hlc.Timest
 // TODO(aayush): We should try to bind clusterID to the function here, rather
 // than having callers plumb it in every time.
 func canSendToFollower(clusterID uuid.UUID, st *cluster.Settings, ba roachpb.BatchRequest) bool {
-	return batchCanBeEvaluatedOnFollower(ba) &&
-		txnCanPerformFollowerRead(ba.Txn) &&
+	if !batchCanBeEvaluatedOnFollower(ba) {
+		return false
+	}
+	if ba.RoutingPolicy == roachpb.RoutingPolicy_NEAREST {
+		// The sender has already determined that the batch can be served by the
+		// nearest replica (e.g. by negotiating a bounded staleness timestamp at
+		// or below each range's resolved timestamp), so don't second-guess it
+		// based on the age of the batch's timestamp.
+		return (ba.Txn == nil || txnCanPerformFollowerRead(ba.Txn)) &&
+			followerReadsAllowed(clusterID, st)
+	}
+	return txnCanPerformFollowerRead(ba.Txn) &&
 		canUseFollowerRead(clusterID, st, forward(ba.Txn.ReadTimestamp, ba.Txn.MaxTimestamp))
 }
 
@@ -134,8 +148,14 @@ func newOracleFactory(cfg replicaoracle.Config) replicaoracle.OracleFactory {
 }
 
 func (f oracleFactory) Oracle(txn *kv.Txn) replicaoracle.Oracle {
-	if txn != nil && canUseFollowerRead(f.clusterID.Get(), f.st, txn.ReadTimestamp()) {
-		return f.closest.Oracle(txn)
+	if txn != nil {
+		if txn.RoutingPolicy() == roachpb.RoutingPolicy_NEAREST {
+			if followerReadsAllowed(f.clusterID.Get(), f.st) {
+				return f.closest.Oracle(txn)
+			}
+		} else if canUseFollowerRead(f.clusterID.Get(), f.st, txn.ReadTimestamp()) {
+			return f.closest.Oracle(txn)
+		}
 	}
 	return f.binPacking.Oracle(txn)
 }
@@ -38,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -132,10 +133,36 @@ func TestCanSendToFollower(t *testing.T) {
 	if canSendToFollower(uuid.MakeV4(), st, roNew) {
 		t.Fatalf("should not be able to send a ro batch with new MaxTimestamp to a follower")
 	}
+	roNewNearest := roachpb.BatchRequest{Header: roNew.Header}
+	roNewNearest.RoutingPolicy = roachpb.RoutingPolicy_NEAREST
+	roNewNearest.Add(&roachpb.GetRequest{})
+	if !canSendToFollower(uuid.MakeV4(), st, roNewNearest) {
+		t.Fatalf("should be able to send a new ro batch with the nearest routing policy to a follower")
+	}
+	rwNearest := roachpb.BatchRequest{Header: roNewNearest.Header}
+	rwNearest.Add(&roachpb.PutRequest{})
+	if canSendToFollower(uuid.MakeV4(), st, rwNearest) {
+		t.Fatalf("should not be able to send a rw batch with the nearest routing policy to a follower")
+	}
+	qrts := roachpb.BatchRequest{}
+	qrts.Add(&roachpb.QueryResolvedTimestampRequest{})
+	if canSendToFollower(uuid.MakeV4(), st, qrts) {
+		t.Fatalf("should not be able to send a QueryResolvedTimestamp batch with the leaseholder " +
+			"routing policy to a follower")
+	}
+	qrts.RoutingPolicy = roachpb.RoutingPolicy_NEAREST
+	if !canSendToFollower(uuid.MakeV4(), st, qrts) {
+		t.Fatalf("should be able to send a QueryResolvedTimestamp batch with the nearest " +
+			"routing policy to a follower")
+	}
 	disableEnterprise()
 	if canSendToFollower(uuid.MakeV4(), st, roOld) {
 		t.Fatalf("should not be able to send an old ro batch to a follower without enterprise enabled")
 	}
+	if canSendToFollower(uuid.MakeV4(), st, roNewNearest) {
+		t.Fatalf("should not be able to send a ro batch with the nearest routing policy to a " +
+			"follower without enterprise enabled")
+	}
 }
 
 func TestFollowerReadMultipleValidation(t *testing.T) {
@@ -181,6 +208,13 @@ func TestOracleFactory(t *testing.T) {
 		t.Fatalf("expected types of %T and %T to differ", followerReadOracle,
 			noFollowerReadOracle)
 	}
+	nearestTxn := kv.NewTxn(context.Background(), c, 0)
+	nearestTxn.SetRoutingPolicy(roachpb.RoutingPolicy_NEAREST)
+	nearestOracle := of.Oracle(nearestTxn)
+	if reflect.TypeOf(nearestOracle) != reflect.TypeOf(followerReadOracle) {
+		t.Fatalf("expected types of %T and %T not to differ", nearestOracle,
+			followerReadOracle)
+	}
 	disableEnterprise()
 	disabledFollowerReadOracle := of.Oracle(txn)
 	if reflect.TypeOf(disabledFollowerReadOracle) != reflect.TypeOf(noFollowerReadOracle) {
@@ -325,3 +359,74 @@ func TestFollowerReadsWithStaleDescriptor(t *testing.T) {
 	require.NoError(t, err)
 	require.Greater(t, followerReadsCountAfter, followerReadsCountBefore)
 }
+
+// TestBoundedStalenessReads tests that bounded staleness reads negotiate a
+// timestamp that can be served by the nearest replica and are then served by
+// that replica, without involving the leaseholder.
+func TestBoundedStalenessReads(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	defer utilccl.TestingEnableEnterprise()()
+
+	boundedStalenessQuery := `SELECT * FROM test AS OF SYSTEM TIME with_max_staleness('1h', true) WHERE k = 1`
+	recCh := make(chan tracing.Recording, 1)
+
+	var n2Addr syncutil.AtomicString
+	tc := testcluster.StartTestCluster(t, 3,
+		base.TestClusterArgs{
+			ReplicationMode: base.ReplicationManual,
+			ServerArgs:      base.TestServerArgs{UseDatabase: "t"},
+			// n3 pretends to have low latency to n2, so that it routes its bounded
+			// staleness reads to it.
+			ServerArgsPerNode: map[int]base.TestServerArgs{
+				2: {
+					UseDatabase: "t",
+					Knobs: base.TestingKnobs{
+						KVClient: &kvcoord.ClientTestingKnobs{
+							LatencyFunc: func(addr string) (time.Duration, bool) {
+								if addr == n2Addr.Get() {
+									return time.Millisecond, true
+								}
+								return 100 * time.Millisecond, true
+							},
+						},
+						SQLExecutor: &sql.ExecutorTestingKnobs{
+							WithStatementTrace: func(trace tracing.Recording, stmt string) {
+								if stmt == boundedStalenessQuery {
+									recCh <- trace
+								}
+							},
+						},
+					},
+				},
+			},
+		})
+	defer tc.Stopper().Stop(ctx)
+	n2Addr.Set(tc.Servers[1].RPCAddr())
+
+	n1 := sqlutils.MakeSQLRunner(tc.Conns[0])
+	n1.Exec(t, `CREATE DATABASE t`)
+	n1.Exec(t, `CREATE TABLE test (k INT PRIMARY KEY, v INT)`)
+	n1.Exec(t, `ALTER TABLE test EXPERIMENTAL_RELOCATE VALUES (ARRAY[1,2], 1)`)
+	n1.Exec(t, `INSERT INTO test VALUES (1, 10)`)
+	// Speed up closing of timestamps, so that n2 is quickly able to serve reads
+	// that observe the write above.
+	n1.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+
+	testutils.SucceedsSoon(t, func() error {
+		// The query fails with an UnsatisfiableBoundedStaleness error until n2
+		// has closed a timestamp above the table's creation.
+		var k, v int
+		err := tc.Conns[2].QueryRow(boundedStalenessQuery).Scan(&k, &v)
+		rec := <-recCh
+		if err != nil {
+			return err
+		}
+		if !kv.OnlyFollowerReads(rec) {
+			return errors.Errorf("query was not served through follower reads: %s", rec)
+		}
+		return nil
+	})
+}
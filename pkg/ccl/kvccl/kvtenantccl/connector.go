@@ -346,6 +346,31 @@ func (c *Connector) RangeLookup(
 	return nil, nil, ctx.Err()
 }
 
+// ReportTenantConsumption implements the kvtenant.Connector interface.
+func (c *Connector) ReportTenantConsumption(
+	ctx context.Context, req *roachpb.ReportTenantConsumptionRequest,
+) error {
+	ctx = c.AnnotateCtx(ctx)
+	for ctx.Err() == nil {
+		client, err := c.getClient(ctx)
+		if err != nil {
+			continue
+		}
+		if _, err := client.ReportTenantConsumption(ctx, req); err != nil {
+			log.Warningf(ctx, "error issuing ReportTenantConsumption RPC: %v", err)
+			if grpcutil.IsAuthenticationError(err) {
+				// Authentication error. Propagate.
+				return err
+			}
+			// Soft RPC error. Drop client and retry.
+			c.tryForgetClient(ctx, client)
+			continue
+		}
+		return nil
+	}
+	return ctx.Err()
+}
+
 // FirstRange implements the kvcoord.RangeDescriptorDB interface.
 func (c *Connector) FirstRange() (*roachpb.RangeDescriptor, error) {
 	return nil, status.Error(codes.Unauthenticated, "kvtenant.Proxy does not have access to FirstRange")
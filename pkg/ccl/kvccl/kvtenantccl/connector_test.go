@@ -65,6 +65,12 @@ func (*mockServer) ResetQuorum(
 	panic("unimplemented")
 }
 
+func (*mockServer) ReportTenantConsumption(
+	context.Context, *roachpb.ReportTenantConsumptionRequest,
+) (*roachpb.ReportTenantConsumptionResponse, error) {
+	panic("unimplemented")
+}
+
 func (*mockServer) Batch(context.Context, *roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
 	panic("unimplemented")
 }
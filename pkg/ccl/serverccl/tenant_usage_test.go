@@ -0,0 +1,84 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package serverccl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTenantUsage verifies that the resources consumed by a tenant are metered
+// by the KV node, persisted in system.tenant_usage, and exposed through
+// crdb_internal.tenant_usage and the status server.
+func TestTenantUsage(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	s, hostDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	hostSQL := sqlutils.MakeSQLRunner(hostDB)
+	hostSQL.Exec(t, `SET CLUSTER SETTING tenant_usage.flush.interval = '10ms'`)
+
+	tenantID := roachpb.MakeTenantID(10)
+	_, tenantDB := serverutils.StartTenant(t, s, base.TestTenantArgs{TenantID: tenantID})
+	defer tenantDB.Close()
+	tenantSQL := sqlutils.MakeSQLRunner(tenantDB)
+	tenantSQL.Exec(t, `CREATE TABLE kv (k INT PRIMARY KEY, v STRING)`)
+	tenantSQL.Exec(t, `INSERT INTO kv SELECT i, repeat('x', 100) FROM generate_series(1, 100) AS g(i)`)
+	tenantSQL.Exec(t, `SELECT * FROM kv`)
+
+	// The CPU usage of the tenant's SQL pod is reported periodically, so wait
+	// until it shows up along with the KV usage.
+	testutils.SucceedsSoon(t, func() error {
+		var readRequests, readBytes, writeRequests, writeBytes int64
+		var ru, cpuSeconds float64
+		hostSQL.QueryRow(t, `
+SELECT
+  COALESCE(sum(ru), 0), COALESCE(sum(read_requests), 0), COALESCE(sum(read_bytes), 0),
+  COALESCE(sum(write_requests), 0), COALESCE(sum(write_bytes), 0),
+  COALESCE(sum(sql_pods_cpu_seconds), 0)
+FROM crdb_internal.tenant_usage WHERE tenant_id = $1`, tenantID.ToUint64(),
+		).Scan(&ru, &readRequests, &readBytes, &writeRequests, &writeBytes, &cpuSeconds)
+		if readRequests == 0 || readBytes == 0 || writeRequests == 0 || writeBytes == 0 {
+			return errors.Newf("KV usage not yet persisted")
+		}
+		if cpuSeconds == 0 {
+			return errors.Newf("SQL pod CPU usage not yet persisted")
+		}
+		require.Greater(t, ru, 0.0)
+		return nil
+	})
+
+	// The host tenant is not metered.
+	hostSQL.CheckQueryResults(t,
+		`SELECT count(*) FROM crdb_internal.tenant_usage WHERE tenant_id = 1`, [][]string{{"0"}})
+
+	var resp serverpb.TenantUsageResponse
+	require.NoError(t, serverutils.GetJSONProto(s, "/_status/tenant_usage?tenant_id=10", &resp))
+	require.Len(t, resp.Tenants, 1)
+	require.Equal(t, tenantID.ToUint64(), resp.Tenants[0].TenantID)
+	require.Greater(t, resp.Tenants[0].Consumption.RU, 0.0)
+	require.Greater(t, resp.Tenants[0].Consumption.WriteBytes, uint64(0))
+	require.Greater(t, resp.Tenants[0].Consumption.SQLPodsCPUSeconds, 0.0)
+
+	// The system table is not visible to tenants.
+	tenantSQL.CheckQueryResults(t, `SELECT count(*) FROM crdb_internal.tenant_usage`, [][]string{{"0"}})
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -188,6 +189,23 @@ func evalExport(
 			}
 		}
 
+		span := roachpb.Span{Key: start}
+		if resume != nil {
+			span.EndKey = resume
+		} else {
+			span.EndKey = args.EndKey
+		}
+
+		var fingerprint *roachpb.SpanFingerprint
+		if args.Fingerprint {
+			// Fingerprint the exported data itself, before it is encrypted.
+			fp, err := fingerprintExportedSST(ctx, batch, data, span, args.StartTime)
+			if err != nil {
+				return result.Result{}, errors.Wrap(err, "fingerprinting exported data")
+			}
+			fingerprint = &fp
+		}
+
 		if args.Encryption != nil {
 			// TODO(dt): cluster version gate use EncryptFileChunked.
 			data, err = EncryptFile(data, args.Encryption.Key)
@@ -196,17 +214,12 @@ func evalExport(
 			}
 		}
 
-		span := roachpb.Span{Key: start}
-		if resume != nil {
-			span.EndKey = resume
-		} else {
-			span.EndKey = args.EndKey
-		}
 		exported := roachpb.ExportResponse_File{
-			Span:       span,
-			Exported:   summary,
-			Sha512:     checksum,
-			LocalityKV: localityKV,
+			Span:        span,
+			Exported:    summary,
+			Sha512:      checksum,
+			LocalityKV:  localityKV,
+			Fingerprint: fingerprint,
 		}
 
 		if exportStore != nil {
@@ -279,6 +292,52 @@ func evalExport(
 	return result.Result{}, nil
 }
 
+// fingerprintExportedSST returns the fingerprint of the live data in an SST
+// exported over the given span. If startTime is empty, the SST contains the
+// live data as of the time it was exported at, and so the fingerprint is that
+// of the span as of that time. Otherwise, the SST only contains the keys that
+// changed since startTime, and the returned fingerprint is the change in the
+// span's fingerprint since then: the values that the changed keys had as of
+// startTime are read from reader and removed from it.
+func fingerprintExportedSST(
+	ctx context.Context,
+	reader storage.Reader,
+	data []byte,
+	span roachpb.Span,
+	startTime hlc.Timestamp,
+) (roachpb.SpanFingerprint, error) {
+	fp := roachpb.SpanFingerprint{Span: span}
+	iter, err := storage.NewMemSSTIterator(data, false /* verify */)
+	if err != nil {
+		return fp, err
+	}
+	defer iter.Close()
+	// Only the most recent version of each key is live, and the versions of a
+	// key are sorted by decreasing timestamp.
+	for iter.SeekGE(storage.MVCCKey{Key: span.Key}); ; iter.NextKey() {
+		if ok, err := iter.Valid(); err != nil {
+			return fp, err
+		} else if !ok {
+			break
+		}
+		key := iter.UnsafeKey().Key
+		// Deletion tombstones have an empty value.
+		if value := iter.UnsafeValue(); len(value) > 0 {
+			fp.Add(key, roachpb.Value{RawBytes: value})
+		}
+		if !startTime.IsEmpty() {
+			prev, _, err := storage.MVCCGet(ctx, reader, key, startTime, storage.MVCCGetOptions{})
+			if err != nil {
+				return fp, err
+			}
+			if prev != nil {
+				fp.Remove(key, *prev)
+			}
+		}
+	}
+	return fp, nil
+}
+
 // SHA512ChecksumData returns the SHA512 checksum of data.
 func SHA512ChecksumData(data []byte) ([]byte, error) {
 	h := sha512.New()
@@ -441,6 +441,76 @@ func TestExportGCThreshold(t *testing.T) {
 	}
 }
 
+func TestExportFingerprint(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 1, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+	kvDB := tc.Server(0).DB()
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+	span := roachpb.Span{Key: keys.UserTableDataMin, EndKey: keys.MaxKey}
+
+	// exportFingerprint exports span as of end, returning the combined
+	// fingerprints of the exported files.
+	exportFingerprint := func(
+		start, end hlc.Timestamp, mvccFilter roachpb.MVCCFilter,
+	) roachpb.SpanFingerprint {
+		req := &roachpb.ExportRequest{
+			RequestHeader: roachpb.RequestHeaderFromSpan(span),
+			StartTime:     start,
+			MVCCFilter:    mvccFilter,
+			ReturnSST:     true,
+			Fingerprint:   true,
+			// Produce several files.
+			TargetFileSize: 1,
+		}
+		res, pErr := kv.SendWrappedWith(ctx, kvDB.NonTransactionalSender(), roachpb.Header{Timestamp: end}, req)
+		require.NoError(t, pErr.GoError())
+		files := res.(*roachpb.ExportResponse).Files
+		require.NotEmpty(t, files)
+		fp := roachpb.SpanFingerprint{Span: span}
+		for _, f := range files {
+			require.NotNil(t, f.Fingerprint)
+			require.Equal(t, f.Span, f.Fingerprint.Span)
+			fp.Combine(*f.Fingerprint)
+		}
+		return fp
+	}
+	liveFingerprint := func(ts hlc.Timestamp) roachpb.SpanFingerprint {
+		fps, err := kvDB.FingerprintSpan(ctx, span, ts)
+		require.NoError(t, err)
+		fp := roachpb.SpanFingerprint{Span: span}
+		for _, f := range fps {
+			fp.Combine(f)
+		}
+		return fp
+	}
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.t (k INT PRIMARY KEY, v STRING)`)
+	sqlDB.Exec(t, `INSERT INTO d.t SELECT i, 'a' FROM generate_series(1, 20) AS g(i)`)
+	ts1 := tc.Server(0).Clock().Now()
+	sqlDB.Exec(t, `UPDATE d.t SET v = 'b' WHERE k < 5`)
+	sqlDB.Exec(t, `UPDATE d.t SET v = 'c' WHERE k < 3`)
+	sqlDB.Exec(t, `DELETE FROM d.t WHERE k > 15`)
+	sqlDB.Exec(t, `INSERT INTO d.t SELECT i, 'd' FROM generate_series(100, 105) AS g(i)`)
+	ts2 := tc.Server(0).Clock().Now()
+
+	for _, mvccFilter := range []roachpb.MVCCFilter{roachpb.MVCCFilter_Latest, roachpb.MVCCFilter_All} {
+		t.Run(mvccFilter.String(), func(t *testing.T) {
+			full := exportFingerprint(hlc.Timestamp{}, ts1, mvccFilter)
+			require.Equal(t, liveFingerprint(ts1), full)
+			require.Equal(t, liveFingerprint(ts2), exportFingerprint(hlc.Timestamp{}, ts2, mvccFilter))
+
+			// The fingerprint of an incremental export is the change since its
+			// start time.
+			full.Combine(exportFingerprint(ts1, ts2, mvccFilter))
+			require.Equal(t, liveFingerprint(ts2), full)
+		})
+	}
+}
+
 // exportUsingGoIterator uses the legacy implementation of export, and is used
 // as an oracle to check the correctness of pebbleExportToSst.
 func exportUsingGoIterator(
@@ -1181,6 +1181,7 @@ var debugCmds = append(DebugCmdsForRocksDB,
 	debugZipCmd,
 	debugMergeLogsCommand,
 	debugResetQuorumCmd,
+	debugAllocatorSimCmd,
 )
 
 // DebugCmd is the root of all debug commands. Exported to allow modification by CCL code.
@@ -1273,6 +1274,20 @@ func init() {
 	f.BoolVar(&debugDecodeProtoEmitDefaults, "emit-defaults", true,
 		"encode default values for every field")
 
+	f = debugAllocatorSimCmd.Flags()
+	f.StringVar(&debugAllocatorSimOpts.zoneConfig, "zone-config", "",
+		"YAML file with the zone config to apply to all ranges, on top of the default zone config")
+	f.DurationVar(&debugAllocatorSimOpts.duration, "duration", debugAllocatorSimOpts.duration,
+		"virtual time after which to stop the simulation if it hasn't converged")
+	f.DurationVar(&debugAllocatorSimOpts.tick, "tick", debugAllocatorSimOpts.tick,
+		"virtual time between consecutive passes of the replicate queue and store rebalancers")
+	f.IntSliceVar(&debugAllocatorSimOpts.deadNodes, "dead-nodes", nil,
+		"list of node IDs to consider dead")
+	f.IntSliceVar(&debugAllocatorSimOpts.decommissioningNodes, "decommissioning-nodes", nil,
+		"list of node IDs to consider decommissioning")
+	f.BoolVar(&debugAllocatorSimOpts.printChanges, "print-changes", false,
+		"print every replica and lease movement")
+
 	f = debugCheckLogConfigCmd.Flags()
 	f.Var(&debugLogChanSel, "only-channels", "selection of channels to include in the output diagram.")
 }
@@ -0,0 +1,451 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var debugAllocatorSimCmd = &cobra.Command{
+	Use:   "allocator-sim <debug_zip_dir | spec.yaml>",
+	Short: "simulate replica placement and rebalancing in virtual time",
+	Long: `
+Simulate the allocator, the replicate queue and the store rebalancer in
+virtual time against a snapshot of a cluster, and report the replica and
+lease movements they would make as well as the resulting balance of the
+stores. This can be used to preview the effect of a zone config change or
+of a node failure or decommissioning without touching a real cluster.
+
+The cluster is either loaded from a directory created by unzipping the
+output of 'cockroach debug zip', or described by a YAML spec such as:
+
+  stores:
+  - node: 1
+    locality: region=us-east1,zone=a
+    capacity: 1TiB
+  - node: 2
+    locality: region=us-west1,zone=a
+    status: dead
+  ranges:
+  - count: 100
+    replicas: [1, 2]
+    non_voters: [3]
+    size: 64MiB
+    qps: 10
+
+Each store entry describes a node with a single store, unless a store ID is
+given. The first replica of each range holds the lease. Non-voting replicas
+count toward the load of their stores, but only the voters are rebalanced.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDebugAllocatorSim,
+}
+
+var debugAllocatorSimOpts = struct {
+	zoneConfig           string
+	duration             time.Duration
+	tick                 time.Duration
+	deadNodes            []int
+	decommissioningNodes []int
+	printChanges         bool
+}{
+	duration: 24 * time.Hour,
+	tick:     time.Minute,
+}
+
+// allocatorSimSpec is the YAML description of a synthetic cluster.
+type allocatorSimSpec struct {
+	Stores []allocatorSimStoreSpec `yaml:"stores"`
+	Ranges []allocatorSimRangeSpec `yaml:"ranges"`
+}
+
+type allocatorSimStoreSpec struct {
+	Node     roachpb.NodeID  `yaml:"node"`
+	Store    roachpb.StoreID `yaml:"store"`
+	Locality string          `yaml:"locality"`
+	Attrs    []string        `yaml:"attrs"`
+	Capacity string          `yaml:"capacity"`
+	Status   string          `yaml:"status"`
+}
+
+// allocatorSimStatuses maps the node statuses accepted in a spec to their
+// liveness status.
+var allocatorSimStatuses = map[string]livenesspb.NodeLivenessStatus{
+	"live":            livenesspb.NodeLivenessStatus_LIVE,
+	"dead":            livenesspb.NodeLivenessStatus_DEAD,
+	"decommissioning": livenesspb.NodeLivenessStatus_DECOMMISSIONING,
+}
+
+type allocatorSimRangeSpec struct {
+	Count               int               `yaml:"count"`
+	Replicas            []roachpb.StoreID `yaml:"replicas"`
+	NonVoters           []roachpb.StoreID `yaml:"non_voters"`
+	Size                string            `yaml:"size"`
+	QPS                 float64           `yaml:"qps"`
+	WritesPerSecond     float64           `yaml:"writes_per_second"`
+	WriteBytesPerSecond float64           `yaml:"write_bytes_per_second"`
+}
+
+func runDebugAllocatorSim(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	opts := debugAllocatorSimOpts
+
+	info, err := os.Stat(args[0])
+	if err != nil {
+		return err
+	}
+	var input kvserver.AllocatorSimulationInput
+	if info.IsDir() {
+		input, err = loadAllocatorSimZipDir(args[0])
+	} else {
+		input, err = loadAllocatorSimSpec(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	input.Zone = zonepb.DefaultZoneConfig()
+	if opts.zoneConfig != "" {
+		data, err := ioutil.ReadFile(opts.zoneConfig)
+		if err != nil {
+			return err
+		}
+		if err := yaml.UnmarshalStrict(data, &input.Zone); err != nil {
+			return errors.Wrapf(err, "could not parse zone config %s", opts.zoneConfig)
+		}
+		if err := input.Zone.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid zone config %s", opts.zoneConfig)
+		}
+	}
+	for _, nodes := range []struct {
+		ids    []int
+		status livenesspb.NodeLivenessStatus
+	}{
+		{opts.deadNodes, livenesspb.NodeLivenessStatus_DEAD},
+		{opts.decommissioningNodes, livenesspb.NodeLivenessStatus_DECOMMISSIONING},
+	} {
+		for _, id := range nodes.ids {
+			if input.NodeStatus == nil {
+				input.NodeStatus = make(map[roachpb.NodeID]livenesspb.NodeLivenessStatus)
+			}
+			input.NodeStatus[roachpb.NodeID(id)] = nodes.status
+		}
+	}
+	input.Duration = opts.duration
+	input.Tick = opts.tick
+
+	res, err := kvserver.SimulateAllocator(ctx, cluster.MakeClusterSettings(), input)
+	if err != nil {
+		return err
+	}
+	printAllocatorSimResult(cmd.OutOrStdout(), input, res, opts.printChanges)
+	return nil
+}
+
+// loadAllocatorSimSpec loads a synthetic cluster from a YAML spec.
+func loadAllocatorSimSpec(path string) (kvserver.AllocatorSimulationInput, error) {
+	var input kvserver.AllocatorSimulationInput
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return input, err
+	}
+	var spec allocatorSimSpec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return input, errors.Wrapf(err, "could not parse %s", path)
+	}
+
+	for _, s := range spec.Stores {
+		if s.Node == 0 {
+			return input, errors.Errorf("store without a node ID in %s", path)
+		}
+		desc := roachpb.StoreDescriptor{
+			StoreID: s.Store,
+			Attrs:   roachpb.Attributes{Attrs: s.Attrs},
+			Node:    roachpb.NodeDescriptor{NodeID: s.Node},
+		}
+		if desc.StoreID == 0 {
+			desc.StoreID = roachpb.StoreID(s.Node)
+		}
+		if s.Locality != "" {
+			if err := desc.Node.Locality.Set(s.Locality); err != nil {
+				return input, errors.Wrapf(err, "invalid locality of s%d", desc.StoreID)
+			}
+		}
+		desc.Capacity.Capacity = 1 << 40 // 1 TiB
+		if s.Capacity != "" {
+			if desc.Capacity.Capacity, err = humanizeutil.ParseBytes(s.Capacity); err != nil {
+				return input, errors.Wrapf(err, "invalid capacity of s%d", desc.StoreID)
+			}
+		}
+		if s.Status != "" {
+			status, ok := allocatorSimStatuses[s.Status]
+			if !ok {
+				return input, errors.Errorf("invalid status %q of n%d", s.Status, s.Node)
+			}
+			if input.NodeStatus == nil {
+				input.NodeStatus = make(map[roachpb.NodeID]livenesspb.NodeLivenessStatus)
+			}
+			input.NodeStatus[s.Node] = status
+		}
+		input.Stores = append(input.Stores, desc)
+	}
+
+	nodeIDs := make(map[roachpb.StoreID]roachpb.NodeID, len(input.Stores))
+	for _, desc := range input.Stores {
+		nodeIDs[desc.StoreID] = desc.Node.NodeID
+	}
+	var rangeID roachpb.RangeID
+	for _, r := range spec.Ranges {
+		if len(r.Replicas) == 0 {
+			return input, errors.Errorf("ranges without replicas in %s", path)
+		}
+		size := int64(64 << 20)
+		if r.Size != "" {
+			if size, err = humanizeutil.ParseBytes(r.Size); err != nil {
+				return input, errors.Wrap(err, "invalid range size")
+			}
+		}
+		count := r.Count
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			rangeID++
+			desc := roachpb.RangeDescriptor{RangeID: rangeID}
+			for _, replicas := range []struct {
+				storeIDs []roachpb.StoreID
+				typ      roachpb.ReplicaType
+			}{
+				{r.Replicas, roachpb.VOTER_FULL},
+				{r.NonVoters, roachpb.NON_VOTER},
+			} {
+				for _, storeID := range replicas.storeIDs {
+					nodeID, ok := nodeIDs[storeID]
+					if !ok {
+						return input, errors.Errorf("replica on unknown store s%d", storeID)
+					}
+					desc.AddReplica(nodeID, storeID, replicas.typ)
+				}
+			}
+			input.Ranges = append(input.Ranges, kvserver.SimulatedRange{
+				Desc:                desc,
+				Leaseholder:         r.Replicas[0],
+				LogicalBytes:        size,
+				QueriesPerSecond:    r.QPS,
+				WritesPerSecond:     r.WritesPerSecond,
+				WriteBytesPerSecond: r.WriteBytesPerSecond,
+			})
+		}
+	}
+	return input, nil
+}
+
+// loadAllocatorSimZipDir loads a cluster from the node and range statuses
+// in a directory created by unzipping the output of debug zip. The learners
+// of the ranges aren't loaded, and ranges in joint configurations are loaded
+// as their outgoing configuration.
+func loadAllocatorSimZipDir(dir string) (kvserver.AllocatorSimulationInput, error) {
+	var input kvserver.AllocatorSimulationInput
+	nodesDir := filepath.Join(dir, "debug", "nodes")
+	nodeDirs, err := ioutil.ReadDir(nodesDir)
+	if err != nil {
+		return input, errors.Wrapf(err, "%s does not look like an unzipped debug zip", dir)
+	}
+
+	ranges := make(map[roachpb.RangeID][]serverpb.RangeInfo)
+	for _, nodeDir := range nodeDirs {
+		if !nodeDir.IsDir() {
+			continue
+		}
+		var status statuspb.NodeStatus
+		statusPath := filepath.Join(nodesDir, nodeDir.Name(), "status.json")
+		if err := readJSONFile(statusPath, &status); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return input, err
+		}
+		for _, ss := range status.StoreStatuses {
+			input.Stores = append(input.Stores, ss.Desc)
+		}
+
+		rangeFiles, err := filepath.Glob(filepath.Join(nodesDir, nodeDir.Name(), "ranges", "*.json"))
+		if err != nil {
+			return input, err
+		}
+		for _, rangeFile := range rangeFiles {
+			var info serverpb.RangeInfo
+			if err := readJSONFile(rangeFile, &info); err != nil {
+				return input, err
+			}
+			rangeID := info.State.Desc.RangeID
+			ranges[rangeID] = append(ranges[rangeID], info)
+		}
+	}
+	if len(input.Stores) == 0 {
+		return input, errors.Errorf("no store statuses found in %s", nodesDir)
+	}
+
+	rangeIDs := make([]roachpb.RangeID, 0, len(ranges))
+	for rangeID := range ranges {
+		rangeIDs = append(rangeIDs, rangeID)
+	}
+	sort.Slice(rangeIDs, func(i, j int) bool { return rangeIDs[i] < rangeIDs[j] })
+	for _, rangeID := range rangeIDs {
+		infos := ranges[rangeID]
+		// Prefer the leaseholder's view of the range, since only the
+		// leaseholder knows the range's QPS.
+		info := infos[0]
+		for _, other := range infos {
+			if other.State.Lease != nil && other.SourceStoreID == other.State.Lease.Replica.StoreID {
+				info = other
+				break
+			}
+		}
+		if info.State.Desc == nil {
+			continue
+		}
+		desc := *info.State.Desc
+		desc.InternalReplicas = nil
+		var leaseholder roachpb.StoreID
+		for _, repl := range info.State.Desc.Replicas().VoterFullAndNonVoterDescriptors() {
+			if leaseholder == 0 && repl.GetType() == roachpb.VOTER_FULL {
+				leaseholder = repl.StoreID
+			}
+			desc.InternalReplicas = append(desc.InternalReplicas, repl)
+		}
+		if leaseholder == 0 {
+			continue
+		}
+		r := kvserver.SimulatedRange{
+			Desc:             desc,
+			Leaseholder:      leaseholder,
+			QueriesPerSecond: info.Stats.QueriesPerSecond,
+			WritesPerSecond:  info.Stats.WritesPerSecond,
+		}
+		if lease := info.State.Lease; lease != nil {
+			if repl, ok := desc.GetReplicaDescriptor(lease.Replica.StoreID); ok &&
+				repl.GetType() == roachpb.VOTER_FULL {
+				r.Leaseholder = lease.Replica.StoreID
+			}
+		}
+		if info.State.Stats != nil {
+			r.LogicalBytes = info.State.Stats.Total()
+		}
+		input.Ranges = append(input.Ranges, r)
+	}
+	return input, nil
+}
+
+func readJSONFile(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(json.Unmarshal(data, v), "could not parse %s", path)
+}
+
+func printAllocatorSimResult(
+	w io.Writer,
+	input kvserver.AllocatorSimulationInput,
+	res kvserver.AllocatorSimulationResult,
+	printChanges bool,
+) {
+	fmt.Fprintf(w, "simulated %d stores and %d ranges for %s: ",
+		len(input.Stores), len(input.Ranges), res.Elapsed)
+	if res.Converged {
+		fmt.Fprintf(w, "converged\n")
+	} else {
+		fmt.Fprintf(w, "did not converge\n")
+	}
+
+	var replicaChanges, leaseTransfers int
+	for _, c := range res.Changes {
+		if len(c.Added) > 0 || len(c.Removed) > 0 {
+			replicaChanges++
+		}
+		if c.LeaseTarget != 0 {
+			leaseTransfers++
+		}
+	}
+	fmt.Fprintf(w, "%d replica changes moving %s, %d lease transfers\n\n",
+		replicaChanges, humanizeutil.IBytes(res.BytesMoved()), leaseTransfers)
+
+	tw := tabwriter.NewWriter(w, 2, 1, 2, ' ', 0)
+	if printChanges && len(res.Changes) > 0 {
+		fmt.Fprintf(tw, "elapsed\trange\tsource\treason\tadded\tremoved\tlease\tbytes\n")
+		for _, c := range res.Changes {
+			lease := ""
+			if c.LeaseTarget != 0 {
+				lease = fmt.Sprintf("s%d", c.LeaseTarget)
+			}
+			fmt.Fprintf(tw, "%s\tr%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				c.Elapsed, c.RangeID, c.Source, c.Reason,
+				formatSimTargets(c.Added), formatSimTargets(c.Removed), lease,
+				humanizeutil.IBytes(c.BytesMoved))
+		}
+		_ = tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(tw, "store\tnode\tstatus\tranges\tleases\tlogical bytes\tqps\n")
+	for i, after := range res.After {
+		before := res.Before[i]
+		status := "live"
+		if s, ok := input.NodeStatus[after.Node.NodeID]; ok {
+			status = strings.ToLower(strings.TrimPrefix(s.String(), "NODE_STATUS_"))
+		}
+		fmt.Fprintf(tw, "s%d\tn%d\t%s\t%d -> %d\t%d -> %d\t%s -> %s\t%.0f -> %.0f\n",
+			after.StoreID, after.Node.NodeID, status,
+			before.Capacity.RangeCount, after.Capacity.RangeCount,
+			before.Capacity.LeaseCount, after.Capacity.LeaseCount,
+			humanizeutil.IBytes(before.Capacity.LogicalBytes), humanizeutil.IBytes(after.Capacity.LogicalBytes),
+			before.Capacity.QueriesPerSecond, after.Capacity.QueriesPerSecond)
+	}
+	_ = tw.Flush()
+}
+
+func formatSimTargets(replicas []roachpb.ReplicaDescriptor) string {
+	var parts []string
+	for _, r := range replicas {
+		switch r.GetType() {
+		case roachpb.NON_VOTER:
+			parts = append(parts, fmt.Sprintf("s%d (non-voter)", r.StoreID))
+		case roachpb.WITNESS:
+			parts = append(parts, fmt.Sprintf("s%d (witness)", r.StoreID))
+		default:
+			parts = append(parts, fmt.Sprintf("s%d", r.StoreID))
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugAllocatorSimSpec(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanupFn := testutils.TempDir(t)
+	defer cleanupFn()
+
+	spec := `
+stores:
+- node: 1
+  locality: region=a
+- node: 2
+  locality: region=b
+- node: 3
+  locality: region=c
+- node: 4
+  locality: region=d
+  status: dead
+ranges:
+- count: 5
+  replicas: [1, 2, 4]
+  size: 32MiB
+  qps: 10
+`
+	specPath := filepath.Join(dir, "spec.yaml")
+	require.NoError(t, ioutil.WriteFile(specPath, []byte(spec), 0644))
+
+	input, err := loadAllocatorSimSpec(specPath)
+	require.NoError(t, err)
+	require.Len(t, input.Stores, 4)
+	require.Len(t, input.Ranges, 5)
+	require.Equal(t, livenesspb.NodeLivenessStatus_DEAD, input.NodeStatus[4])
+	require.Equal(t, int64(32<<20), input.Ranges[0].LogicalBytes)
+	require.Equal(t, "region=d", input.Stores[3].Node.Locality.String())
+
+	input.Duration = time.Hour
+	res, err := kvserver.SimulateAllocator(context.Background(), cluster.MakeTestingClusterSettings(), input)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	printAllocatorSimResult(&buf, input, res, true /* printChanges */)
+	out := buf.String()
+	require.Contains(t, out, "simulated 4 stores and 5 ranges")
+	require.Contains(t, out, "5 replica changes moving 160 MiB")
+	require.Contains(t, out, "range under-replicated")
+	require.Regexp(t, `s3\s+n3\s+live\s+0 -> 5`, out)
+	require.Regexp(t, `s4\s+n4\s+dead\s+5 -> 0`, out)
+
+	spec = `
+stores:
+- node: 1
+- node: 2
+- node: 3
+ranges:
+- replicas: [1]
+  non_voters: [2, 3]
+`
+	require.NoError(t, ioutil.WriteFile(specPath, []byte(spec), 0644))
+	input, err = loadAllocatorSimSpec(specPath)
+	require.NoError(t, err)
+	require.Len(t, input.Ranges, 1)
+	require.Len(t, input.Ranges[0].Desc.Replicas().VoterDescriptors(), 1)
+	require.Len(t, input.Ranges[0].Desc.Replicas().NonVoterDescriptors(), 2)
+}
+
+func TestDebugAllocatorSimZipDir(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanupFn := testutils.TempDir(t)
+	defer cleanupFn()
+
+	writeJSON := func(path string, v interface{}) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		b, err := json.MarshalIndent(v, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(path, b, 0644))
+	}
+
+	desc := roachpb.RangeDescriptor{
+		RangeID:  7,
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("b"),
+	}
+	desc.AddReplica(1, 1, roachpb.VOTER_FULL)
+	desc.AddReplica(2, 2, roachpb.VOTER_FULL)
+	desc.AddReplica(3, 3, roachpb.NON_VOTER)
+	desc.AddReplica(3, 4, roachpb.LEARNER)
+	lease := roachpb.Lease{Replica: desc.InternalReplicas[1]}
+	for i := 1; i <= 3; i++ {
+		nodeDir := filepath.Join(dir, "debug", "nodes", fmt.Sprint(i))
+		writeJSON(filepath.Join(nodeDir, "status.json"), statuspb.NodeStatus{
+			Desc: roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)},
+			StoreStatuses: []statuspb.StoreStatus{{
+				Desc: roachpb.StoreDescriptor{
+					StoreID:  roachpb.StoreID(i),
+					Node:     roachpb.NodeDescriptor{NodeID: roachpb.NodeID(i)},
+					Capacity: roachpb.StoreCapacity{Capacity: 1 << 30, Available: 1 << 29, Used: 1 << 20},
+				},
+			}},
+		})
+		info := serverpb.RangeInfo{
+			State: kvserverpb.RangeInfo{
+				ReplicaState: kvserverpb.ReplicaState{
+					Desc:  &desc,
+					Lease: &lease,
+					Stats: &enginepb.MVCCStats{KeyBytes: 100, ValBytes: 900},
+				},
+			},
+			SourceNodeID:  roachpb.NodeID(i),
+			SourceStoreID: roachpb.StoreID(i),
+		}
+		if i == 2 {
+			info.Stats.QueriesPerSecond = 42
+		}
+		writeJSON(filepath.Join(nodeDir, "ranges", "7.json"), info)
+	}
+
+	input, err := loadAllocatorSimZipDir(dir)
+	require.NoError(t, err)
+	require.Len(t, input.Stores, 3)
+	require.Len(t, input.Ranges, 1)
+	r := input.Ranges[0]
+	require.Equal(t, roachpb.RangeID(7), r.Desc.RangeID)
+	require.Equal(t, roachpb.StoreID(2), r.Leaseholder)
+	require.Equal(t, 42.0, r.QueriesPerSecond)
+	require.Equal(t, int64(1000), r.LogicalBytes)
+	require.Len(t, r.Desc.InternalReplicas, 3)
+	require.Len(t, r.Desc.Replicas().NonVoterDescriptors(), 1)
+
+	_, err = loadAllocatorSimZipDir(filepath.Join(dir, "debug"))
+	require.Error(t, err)
+}
@@ -67,6 +67,8 @@ var debugZipTablesPerCluster = []string{
 	"crdb_internal.cluster_settings",
 	"crdb_internal.cluster_transactions",
 
+	"crdb_internal.hot_keys",
+
 	"crdb_internal.jobs",
 	"system.jobs",       // get the raw, restorable jobs records too.
 	"system.descriptor", // descriptors also contain job-like mutation state.
@@ -80,6 +82,13 @@ var debugZipTablesPerCluster = []string{
 	"crdb_internal.partitions",
 	"crdb_internal.zones",
 	"crdb_internal.invalid_objects",
+
+	"crdb_internal.statement_statistics",
+	"crdb_internal.transaction_statistics",
+
+	"crdb_internal.tenant_usage",
+
+	"crdb_internal.transaction_contention_events",
 }
 
 // Tables collected from each node in a debug zip.
@@ -629,6 +638,30 @@ func runDebugZip(cmd *cobra.Command, args []string) (retErr error) {
 				}
 			}
 
+			var continuousProfiles *serverpb.GetFilesResponse
+			if err := runZipRequestWithTimeout(baseCtx, "requesting continuous profile files for node "+id, timeout,
+				func(ctx context.Context) error {
+					continuousProfiles, err = status.GetFiles(ctx, &serverpb.GetFilesRequest{
+						NodeId:   id,
+						Type:     serverpb.FileType_CONTINUOUS_PROFILES,
+						Patterns: []string{"*"},
+					})
+					return err
+				}); err != nil {
+				if err := z.createError(prefix+"/continuous_profiles", err); err != nil {
+					return err
+				}
+			} else {
+				fmt.Printf("%d found\n", len(continuousProfiles.Files))
+				for _, file := range continuousProfiles.Files {
+					// NB: the files have a .pprof suffix already.
+					name := prefix + "/continuous_profiles/" + file.Name
+					if err := z.createRaw(name, file.Contents); err != nil {
+						return err
+					}
+				}
+			}
+
 			var logs *serverpb.LogFilesListResponse
 			if err := runZipRequestWithTimeout(baseCtx, "requesting log files list", timeout,
 				func(ctx context.Context) error {
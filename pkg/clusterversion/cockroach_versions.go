@@ -219,6 +219,44 @@ const (
 	PostTruncatedAndRangeAppliedStateMigration
 	// NewSchemaChanger enables the new schema changer.
 	NewSchemaChanger
+	// PublicationsAndReplicationSlots adds the system.publications and
+	// system.replication_slots tables used by logical replication.
+	PublicationsAndReplicationSlots
+	// SQLStatsTables adds the system.statement_statistics and
+	// system.transaction_statistics tables that persist SQL statistics.
+	SQLStatsTables
+	// StatementHintsTable adds the system.statement_hints table that stores
+	// the plans pinned for statement fingerprints.
+	StatementHintsTable
+	// MVCCRangeTombstones enables DeleteRange requests to delete their span
+	// with a single MVCC range tombstone, stored in the range-local tombstone
+	// keyspace which all nodes need to account for in stats and checksums.
+	MVCCRangeTombstones
+	// ReadCommittedIsolation allows transactions to run at the READ COMMITTED
+	// isolation level, which all nodes need to understand to let them commit
+	// with a pushed write timestamp.
+	ReadCommittedIsolation
+	// ReplicatedLocks allows locking reads to acquire replicated Shared and
+	// Upgrade locks, stored in the range-local replicated lock keyspace which
+	// all nodes need to account for in stats, checksums and snapshots.
+	ReplicatedLocks
+	// WitnessReplicas allows ranges to contain WITNESS replicas, which vote in
+	// Raft but hold no user data, and which all nodes need to understand in
+	// range descriptors, snapshots and Raft configuration changes.
+	WitnessReplicas
+	// BoundedStaleness adds the QueryResolvedTimestamp request, with which
+	// bounded staleness reads negotiate the timestamp they read at.
+	BoundedStaleness
+	// TenantUsageTable adds the system.tenant_usage table, into which the
+	// resources consumed by tenants are periodically rolled up.
+	TenantUsageTable
+	// AlertRulesTable adds the system.alert_rules table, which stores the
+	// user-defined alert rules evaluated against the time series database.
+	AlertRulesTable
+	// TransactionContentionEventsTable adds the
+	// system.transaction_contention_events table, which stores the history of
+	// the contention events between transactions.
+	TransactionContentionEventsTable
 
 	// Step (1): Add new versions here.
 )
@@ -361,6 +399,50 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     NewSchemaChanger,
 		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 18},
 	},
+	{
+		Key:     PublicationsAndReplicationSlots,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 20},
+	},
+	{
+		Key:     SQLStatsTables,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 22},
+	},
+	{
+		Key:     StatementHintsTable,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 24},
+	},
+	{
+		Key:     MVCCRangeTombstones,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 26},
+	},
+	{
+		Key:     ReadCommittedIsolation,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 28},
+	},
+	{
+		Key:     ReplicatedLocks,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 30},
+	},
+	{
+		Key:     WitnessReplicas,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 32},
+	},
+	{
+		Key:     BoundedStaleness,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 34},
+	},
+	{
+		Key:     TenantUsageTable,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 36},
+	},
+	{
+		Key:     AlertRulesTable,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 38},
+	},
+	{
+		Key:     TransactionContentionEventsTable,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 40},
+	},
 	// Step (2): Add new versions here.
 })
 
@@ -13,6 +13,8 @@ package jobs
 import (
 	"context"
 	"fmt"
+	"runtime/pprof"
+	"strconv"
 	"sync"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -232,8 +234,13 @@ func (r *Registry) runJob(
 	ctx, span = r.ac.AnnotateCtxWithSpan(ctx, spanName)
 	defer span.Finish()
 
-	// Run the actual job.
-	err := r.stepThroughStateMachine(ctx, execCtx, resumer, job, status, finalResumeError)
+	// Run the actual job. Its CPU profile samples, such as those collected by
+	// the continuous profiler, are labeled with the job's ID and type.
+	var err error
+	labels := pprof.Labels("job.id", strconv.FormatInt(*job.ID(), 10), "job.type", typ.String())
+	pprof.Do(ctx, labels, func(ctx context.Context) {
+		err = r.stepThroughStateMachine(ctx, execCtx, resumer, job, status, finalResumeError)
+	})
 	// If the context has been canceled, disregard errors for the sake of logging
 	// as presumably they are due to the context cancellation which commonly
 	// happens during shutdown.
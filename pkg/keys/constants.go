@@ -52,9 +52,9 @@ var (
 	// key suffixes.
 	localSuffixLength = 4
 
-	// There are five types of local key data enumerated below: replicated
-	// range-ID, unreplicated range-ID, range local, store-local, and range lock
-	// keys.
+	// There are seven types of local key data enumerated below: replicated
+	// range-ID, unreplicated range-ID, range local, store-local, MVCC range
+	// tombstone, replicated lock and range lock keys.
 
 	// 1. Replicated Range-ID keys
 	//
@@ -184,7 +184,24 @@ var (
 	// LocalStoreCachedSettingsKeyMax is the end of span of possible cached settings keys.
 	LocalStoreCachedSettingsKeyMax = LocalStoreCachedSettingsKeyMin.PrefixEnd()
 
-	// 5. Lock table keys
+	// 5. MVCC range tombstone keys
+	//
+	// LocalMVCCRangeTombstonePrefix specifies the key prefix for MVCC range
+	// tombstone fragments. It is followed by the encoded start key of the
+	// fragment. The fragment's end key and the timestamps at which the span was
+	// deleted are stored in the value (see enginepb.MVCCRangeTombstoneFragment).
+	LocalMVCCRangeTombstonePrefix = roachpb.Key(makeKey(localPrefix, roachpb.RKey("t")))
+
+	// 6. Replicated lock keys
+	//
+	// LocalReplicatedLockPrefix specifies the key prefix for the Shared and
+	// Upgrade locks acquired with the Replicated durability by locking reads.
+	// It is followed by the encoded locked key and the ID of the transaction
+	// holding the lock. The strength of the lock is stored in the value (see
+	// enginepb.ReplicatedLock).
+	LocalReplicatedLockPrefix = roachpb.Key(makeKey(localPrefix, roachpb.RKey("x")))
+
+	// 7. Lock table keys
 	//
 	// LocalRangeLockTablePrefix specifies the key prefix for the lock
 	// table. It is immediately followed by the LockTableSingleKeyInfix,
@@ -399,6 +416,14 @@ const (
 	ScheduledJobsTableID                = 37
 	TenantsRangesID                     = 38 // pseudo
 	SqllivenessID                       = 39
+	PublicationsTableID                 = 40
+	ReplicationSlotsTableID             = 41
+	StatementStatisticsTableID          = 42
+	TransactionStatisticsTableID        = 43
+	StatementHintsTableID               = 44
+	TenantUsageTableID                  = 45
+	AlertRulesTableID                   = 46
+	TransactionContentionEventsTableID  = 47
 
 	// CommentType is type for system.comments
 	DatabaseCommentType = 0
@@ -158,9 +158,9 @@ package keys
 var _ = [...]interface{}{
 	MinKey,
 
-	// There are five types of local key data enumerated below: replicated
-	// range-ID, unreplicated range-ID, range local, store-local, and range lock
-	// keys. Range lock keys are required to be last category of keys in the
+	// There are seven types of local key data enumerated below: replicated
+	// range-ID, unreplicated range-ID, range local, store-local, MVCC range
+	// tombstone, replicated lock and range lock keys. Range lock keys are required to be last category of keys in the
 	// lock key space.
 	// Local keys are constructed using a prefix, an optional infix, and a
 	// suffix. The prefix and infix are used to disambiguate between the four
@@ -173,10 +173,13 @@ var _ = [...]interface{}{
 	// 		`localRangeIDUnreplicatedInfix`.
 	// 	  - Range local keys all share `LocalRangePrefix`.
 	//	  - Store keys all share `localStorePrefix`.
+	//	  - MVCC range tombstone keys all share `LocalMVCCRangeTombstonePrefix`.
+	//	  - Replicated lock keys all share `LocalReplicatedLockPrefix`.
 	// 	  - Range lock (which are also local keys) all share
 	//	  `LocalRangeLockTablePrefix`.
 	//
-	// `LocalRangeIDPrefix`, `localRangePrefix`, `localStorePrefix`, and
+	// `LocalRangeIDPrefix`, `localRangePrefix`, `localStorePrefix`,
+	// `LocalMVCCRangeTombstonePrefix`, `LocalReplicatedLockPrefix` and
 	// `LocalRangeLockTablePrefix` all in turn share `localPrefix`.
 	// `localPrefix` was chosen arbitrarily. Local keys would work just as well
 	// with a different prefix, like 0xff, or even with a suffix.
@@ -224,7 +227,22 @@ var _ = [...]interface{}{
 	StoreLastUpKey,         // "uptm"
 	StoreCachedSettingsKey, // "stng"
 
-	//   5. Range lock keys for all replicated locks. All range locks share
+	//   5. MVCC range tombstone keys: These store the fragments of MVCC range
+	//   tombstones, i.e. deletions of a span of global keys at a timestamp.
+	//   They are replicated and addressable, and are keyed by the start key of
+	//   the fragment so that they split and merge along range boundaries. They
+	//   all share `LocalMVCCRangeTombstonePrefix`.
+	MVCCRangeTombstoneKey,
+
+	//   6. Replicated lock keys: These store the Shared and Upgrade locks
+	//   acquired with the Replicated durability by locking reads, one per
+	//   locked key and transaction. Like MVCC range tombstone keys, they are
+	//   replicated and addressable, and are keyed by the locked key so that
+	//   they split and merge along range boundaries. They all share
+	//   `LocalReplicatedLockPrefix`.
+	ReplicatedLockKey,
+
+	//   7. Range lock keys for all replicated locks. All range locks share
 	//   LocalRangeLockTablePrefix. Locks can be acquired on global keys and on
 	//   range local keys. Currently, locks are only on single keys, i.e., not
 	//   on a range of keys. Only exclusive locks are currently supported, and
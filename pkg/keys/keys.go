@@ -468,6 +468,70 @@ func DecodeLockTableSingleKey(key roachpb.Key) (lockedKey roachpb.Key, err error
 	return lockedKey, err
 }
 
+// MVCCRangeTombstoneKey returns the key under which the MVCC range tombstone
+// fragment starting at the given global key is stored. The encoding preserves
+// the ordering of the start keys, so the fragments overlapping a span
+// [start, end) are found in [MVCCRangeTombstoneKey(start),
+// MVCCRangeTombstoneKey(end)) plus the fragment preceding it.
+func MVCCRangeTombstoneKey(key roachpb.Key) roachpb.Key {
+	buf := make(roachpb.Key, 0, len(LocalMVCCRangeTombstonePrefix)+len(key)+3)
+	buf = append(buf, LocalMVCCRangeTombstonePrefix...)
+	return encoding.EncodeBytesAscending(buf, key)
+}
+
+// DecodeMVCCRangeTombstoneKey decodes an MVCC range tombstone key to return the
+// start key of the fragment stored under it.
+func DecodeMVCCRangeTombstoneKey(key roachpb.Key) (roachpb.Key, error) {
+	if !bytes.HasPrefix(key, LocalMVCCRangeTombstonePrefix) {
+		return nil, errors.Errorf("key %q does not have %q prefix",
+			key, LocalMVCCRangeTombstonePrefix)
+	}
+	b, startKey, err := encoding.DecodeBytesAscending(key[len(LocalMVCCRangeTombstonePrefix):], nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 0 {
+		return nil, errors.Errorf("key %q has left-over bytes %d after decoding",
+			key, len(b))
+	}
+	return startKey, nil
+}
+
+// ReplicatedLockKeyPrefix returns the key prefix under which the replicated
+// locks held on the given global key are stored, one per transaction. As with
+// MVCC range tombstone keys, the encoding preserves the ordering of the locked
+// keys, so the replicated locks on the keys in a span [start, end) are found
+// in [ReplicatedLockKeyPrefix(start), ReplicatedLockKeyPrefix(end)).
+func ReplicatedLockKeyPrefix(key roachpb.Key) roachpb.Key {
+	buf := make(roachpb.Key, 0, len(LocalReplicatedLockPrefix)+len(key)+3+uuid.Size)
+	buf = append(buf, LocalReplicatedLockPrefix...)
+	return encoding.EncodeBytesAscending(buf, key)
+}
+
+// ReplicatedLockKey returns the key under which the replicated lock held on
+// the given global key by the given transaction is stored.
+func ReplicatedLockKey(key roachpb.Key, txnID uuid.UUID) roachpb.Key {
+	return append(ReplicatedLockKeyPrefix(key), txnID.GetBytes()...)
+}
+
+// DecodeReplicatedLockKey decodes a replicated lock key to return the locked
+// key and the ID of the transaction holding the lock.
+func DecodeReplicatedLockKey(key roachpb.Key) (roachpb.Key, uuid.UUID, error) {
+	if !bytes.HasPrefix(key, LocalReplicatedLockPrefix) {
+		return nil, uuid.UUID{}, errors.Errorf("key %q does not have %q prefix",
+			key, LocalReplicatedLockPrefix)
+	}
+	b, lockedKey, err := encoding.DecodeBytesAscending(key[len(LocalReplicatedLockPrefix):], nil)
+	if err != nil {
+		return nil, uuid.UUID{}, err
+	}
+	txnID, err := uuid.FromBytes(b)
+	if err != nil {
+		return nil, uuid.UUID{}, errors.Wrapf(err, "key %q has invalid transaction ID", key)
+	}
+	return lockedKey, txnID, nil
+}
+
 // IsLocal performs a cheap check that returns true iff a range-local key is
 // passed, that is, a key for which `Addr` would return a non-identical RKey
 // (or a decoding error).
@@ -512,6 +576,20 @@ func Addr(k roachpb.Key) (roachpb.RKey, error) {
 		if bytes.HasPrefix(k, LocalRangeIDPrefix) {
 			return nil, errors.Errorf("local range ID key %q is not addressable", k)
 		}
+		if bytes.HasPrefix(k, LocalMVCCRangeTombstonePrefix) {
+			startKey, err := DecodeMVCCRangeTombstoneKey(k)
+			if err != nil {
+				return nil, err
+			}
+			return roachpb.RKey(startKey), nil
+		}
+		if bytes.HasPrefix(k, LocalReplicatedLockPrefix) {
+			lockedKey, _, err := DecodeReplicatedLockKey(k)
+			if err != nil {
+				return nil, err
+			}
+			return roachpb.RKey(lockedKey), nil
+		}
 		if !bytes.HasPrefix(k, LocalRangePrefix) {
 			return nil, errors.Errorf("local key %q malformed; should contain prefix %q",
 				k, LocalRangePrefix)
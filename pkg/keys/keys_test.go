@@ -734,3 +734,56 @@ func TestLockTableKeyEncodeDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestMVCCRangeTombstoneKeyEncodeDecode(t *testing.T) {
+	testCases := []roachpb.Key{
+		roachpb.Key("foo"),
+		roachpb.Key("a\x00b"),
+		roachpb.Key(""),
+	}
+	for _, key := range testCases {
+		t.Run("", func(t *testing.T) {
+			rtKey := MVCCRangeTombstoneKey(key)
+			require.True(t, bytes.HasPrefix(rtKey, LocalMVCCRangeTombstonePrefix))
+			k, err := DecodeMVCCRangeTombstoneKey(rtKey)
+			require.NoError(t, err)
+			require.Equal(t, key, k)
+			addr, err := Addr(rtKey)
+			require.NoError(t, err)
+			require.Equal(t, roachpb.RKey(key), addr)
+		})
+	}
+	// The encoding must preserve the ordering of the start keys.
+	require.True(t, MVCCRangeTombstoneKey(roachpb.Key("a")).Compare(
+		MVCCRangeTombstoneKey(roachpb.Key("a\x00"))) < 0)
+	require.True(t, MVCCRangeTombstoneKey(roachpb.Key("a\xff")).Compare(
+		MVCCRangeTombstoneKey(roachpb.Key("b"))) < 0)
+}
+
+func TestReplicatedLockKeyEncodeDecode(t *testing.T) {
+	txnID := uuid.MakeV4()
+	testCases := []roachpb.Key{
+		roachpb.Key("foo"),
+		roachpb.Key("a\x00b"),
+		roachpb.Key(""),
+	}
+	for _, key := range testCases {
+		t.Run("", func(t *testing.T) {
+			lockKey := ReplicatedLockKey(key, txnID)
+			require.True(t, bytes.HasPrefix(lockKey, ReplicatedLockKeyPrefix(key)))
+			k, id, err := DecodeReplicatedLockKey(lockKey)
+			require.NoError(t, err)
+			require.Equal(t, key, k)
+			require.Equal(t, txnID, id)
+			addr, err := Addr(lockKey)
+			require.NoError(t, err)
+			require.Equal(t, roachpb.RKey(key), addr)
+		})
+	}
+	// The locks on a key must sort between the locks on the keys before and
+	// after it.
+	require.True(t, ReplicatedLockKeyPrefix(roachpb.Key("a")).PrefixEnd().Compare(
+		ReplicatedLockKey(roachpb.Key("a\x00"), txnID)) <= 0)
+	require.True(t, ReplicatedLockKey(roachpb.Key("a\xff"), txnID).Compare(
+		ReplicatedLockKeyPrefix(roachpb.Key("b"))) < 0)
+}
@@ -77,6 +77,10 @@ var (
 				ppFunc: localRangeIDKeyPrint, PSFunc: localRangeIDKeyParse},
 			{Name: "/Range", prefix: LocalRangePrefix, ppFunc: localRangeKeyPrint,
 				PSFunc: parseUnsupported},
+			{Name: "/MVCCRangeTombstone", prefix: LocalMVCCRangeTombstonePrefix,
+				ppFunc: localMVCCRangeTombstonePrint, PSFunc: parseUnsupported},
+			{Name: "/ReplicatedLock", prefix: LocalReplicatedLockPrefix,
+				ppFunc: localReplicatedLockPrint, PSFunc: parseUnsupported},
 			{Name: "/Lock", prefix: LocalRangeLockTablePrefix, ppFunc: localRangeLockTablePrint,
 				PSFunc: parseUnsupported},
 		}},
@@ -547,6 +551,26 @@ func localRangeLockTablePrint(valDirs []encoding.Direction, key roachpb.Key) str
 	return buf.String()
 }
 
+func localMVCCRangeTombstonePrint(valDirs []encoding.Direction, key roachpb.Key) string {
+	b, startKey, err := encoding.DecodeBytesAscending(key, nil)
+	if err != nil || len(b) != 0 {
+		return fmt.Sprintf("/\"%x\"", key)
+	}
+	return lockTablePrintLockedKey(valDirs, startKey, true)
+}
+
+func localReplicatedLockPrint(valDirs []encoding.Direction, key roachpb.Key) string {
+	b, lockedKey, err := encoding.DecodeBytesAscending(key, nil)
+	if err != nil {
+		return fmt.Sprintf("/\"%x\"", key)
+	}
+	txnID, err := uuid.FromBytes(b)
+	if err != nil {
+		return fmt.Sprintf("/\"%x\"", key)
+	}
+	return fmt.Sprintf("%s/%q", lockTablePrintLockedKey(valDirs, lockedKey, true), txnID)
+}
+
 // ErrUglifyUnsupported is returned when UglyPrint doesn't know how to process a
 // key.
 type ErrUglifyUnsupported struct {
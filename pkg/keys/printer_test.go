@@ -89,6 +89,8 @@ func TestPrettyPrint(t *testing.T) {
 		{keys.QueueLastProcessedKey(roachpb.RKey(tenSysCodec.TablePrefix(42)), "foo"), `/Local/Range/Table/42/QueueLastProcessed/"foo"`, revertSupportUnknown},
 		{lockTableKey(keys.RangeDescriptorKey(roachpb.RKey(tenSysCodec.TablePrefix(42)))), `/Local/Lock/Intent/Local/Range/Table/42/RangeDescriptor`, revertSupportUnknown},
 		{lockTableKey(tenSysCodec.TablePrefix(111)), "/Local/Lock/Intent/Table/111", revertSupportUnknown},
+		{keys.MVCCRangeTombstoneKey(tenSysCodec.TablePrefix(111)), "/Local/MVCCRangeTombstone/Table/111", revertSupportUnknown},
+		{keys.ReplicatedLockKey(tenSysCodec.TablePrefix(111), txnID), fmt.Sprintf(`/Local/ReplicatedLock/Table/111/%q`, txnID), revertSupportUnknown},
 
 		{keys.MakeRangeKeyPrefix(roachpb.RKey(ten5Codec.TenantPrefix())), `/Local/Range/Tenant/5`, revertSupportUnknown},
 		{keys.MakeRangeKeyPrefix(roachpb.RKey(ten5Codec.TablePrefix(42))), `/Local/Range/Tenant/5/Table/42`, revertSupportUnknown},
@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -255,6 +256,12 @@ type DB struct {
 	ctx     DBContext
 	// crs is the sender used for non-transactional requests.
 	crs CrossRangeTxnWrapperSender
+
+	// SQLKVResponseAdmissionQ is used by SQL clients of the DB to queue the
+	// processing of KV responses when the node is overloaded. It is placed
+	// here for plumbing convenience, since most SQL code already has access
+	// to the kv.DB. It may be nil.
+	SQLKVResponseAdmissionQ *admission.WorkQueue
 }
 
 // NonTransactionalSender returns a Sender that can be used for sending
@@ -704,6 +711,71 @@ func (db *DB) Migrate(ctx context.Context, begin, end interface{}, version roach
 	return getOneErr(db.Run(ctx, b), b)
 }
 
+// QueryResolvedTimestamp returns the minimum resolved timestamp across the
+// provided key spans, as seen by the nearest replica of each range that they
+// overlap. Reads over the spans at or below the returned timestamp can be
+// served by those replicas without blocking on the leaseholder. An empty
+// timestamp is returned if no spans are provided.
+func (db *DB) QueryResolvedTimestamp(
+	ctx context.Context, spans ...roachpb.Span,
+) (hlc.Timestamp, error) {
+	if len(spans) == 0 {
+		return hlc.Timestamp{}, nil
+	}
+	var ba roachpb.BatchRequest
+	ba.RoutingPolicy = roachpb.RoutingPolicy_NEAREST
+	for _, sp := range spans {
+		ba.Add(&roachpb.QueryResolvedTimestampRequest{
+			RequestHeader: roachpb.RequestHeaderFromSpan(sp),
+		})
+	}
+	br, pErr := db.send(ctx, ba)
+	if pErr != nil {
+		return hlc.Timestamp{}, pErr.GoError()
+	}
+	var resolved hlc.Timestamp
+	for i, ru := range br.Responses {
+		ts := ru.GetInner().(*roachpb.QueryResolvedTimestampResponse).ResolvedTS
+		if i == 0 || ts.Less(resolved) {
+			resolved = ts
+		}
+	}
+	return resolved, nil
+}
+
+// fingerprintBatchKeyLimit is the maximum number of keys fingerprinted by each
+// batch sent by FingerprintSpan.
+const fingerprintBatchKeyLimit = 10000
+
+// FingerprintSpan returns fingerprints of the live data in the provided key
+// span as of the provided timestamp, in key order. The span is fingerprinted
+// in batches of a bounded number of keys, and a fingerprint is returned for
+// each part of the span that a batch covered in each range. See
+// roachpb.SpanFingerprint.
+func (db *DB) FingerprintSpan(
+	ctx context.Context, span roachpb.Span, ts hlc.Timestamp,
+) ([]roachpb.SpanFingerprint, error) {
+	var fps []roachpb.SpanFingerprint
+	for {
+		var ba roachpb.BatchRequest
+		ba.Timestamp = ts
+		ba.MaxSpanRequestKeys = fingerprintBatchKeyLimit
+		ba.Add(&roachpb.FingerprintRequest{
+			RequestHeader: roachpb.RequestHeaderFromSpan(span),
+		})
+		br, pErr := db.send(ctx, ba)
+		if pErr != nil {
+			return nil, pErr.GoError()
+		}
+		resp := br.Responses[0].GetFingerprint()
+		fps = append(fps, resp.Fingerprints...)
+		if resp.ResumeSpan == nil {
+			return fps, nil
+		}
+		span = *resp.ResumeSpan
+	}
+}
+
 // sendAndFill is a helper which sends the given batch and fills its results,
 // returning the appropriate error which is either from the first failing call,
 // or an "internal" error.
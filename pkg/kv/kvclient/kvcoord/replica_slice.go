@@ -49,7 +49,7 @@ const (
 	// VOTER_FULL).
 	OnlyPotentialLeaseholders ReplicaSliceFilter = iota
 	// AllExtantReplicas prescribes that the ReplicaSlice should include all
-	// replicas that are not LEARNERs, VOTER_OUTGOING, or
+	// replicas that are not LEARNERs, WITNESSes, VOTER_OUTGOING, or
 	// VOTER_DEMOTING_{LEARNER/NON_VOTER}.
 	AllExtantReplicas
 )
@@ -87,15 +87,18 @@ func NewReplicaSlice(
 		return true
 	}
 
-	// Learner replicas won't serve reads/writes, so we'll send only to the voters
-	// and non-voting replicas. This is just an optimization to save a network
-	// hop, everything would still work if we had `All` here.
+	// Learner and witness replicas won't serve reads/writes, so we'll send only
+	// to the voters and non-voting replicas that hold data. This is just an
+	// optimization to save a network hop, everything would still work if we had
+	// `All` here.
 	var replicas []roachpb.ReplicaDescriptor
 	switch filter {
 	case OnlyPotentialLeaseholders:
 		replicas = desc.Replicas().Filter(canReceiveLease).Descriptors()
 	case AllExtantReplicas:
-		replicas = desc.Replicas().VoterAndNonVoterDescriptors()
+		replicas = desc.Replicas().Filter(func(rDesc roachpb.ReplicaDescriptor) bool {
+			return rDesc.GetType() != roachpb.WITNESS
+		}).VoterAndNonVoterDescriptors()
 	default:
 		log.Fatalf(ctx, "unknown ReplicaSliceFilter %v", filter)
 	}
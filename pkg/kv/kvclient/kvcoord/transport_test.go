@@ -146,6 +146,12 @@ func (*mockInternalClient) ResetQuorum(
 	panic("unimplemented")
 }
 
+func (*mockInternalClient) ReportTenantConsumption(
+	context.Context, *roachpb.ReportTenantConsumptionRequest, ...grpc.CallOption,
+) (*roachpb.ReportTenantConsumptionResponse, error) {
+	panic("unimplemented")
+}
+
 // Batch is part of the roachpb.InternalClient interface.
 func (m *mockInternalClient) Batch(
 	ctx context.Context, in *roachpb.BatchRequest, opts ...grpc.CallOption,
@@ -48,6 +48,14 @@ const (
 	// batches except EndTxn(commit=false) will be rejected.
 	txnError
 
+	// txnRetryableError means that a batch of a transaction that reads from a
+	// new snapshot in each statement encountered a retryable error, and the
+	// restart of the transaction has been deferred. Further batches except
+	// EndTxn(commit=false) will be rejected until the client either rolls back
+	// to a savepoint taken before the failed statement, in order to retry only
+	// that statement, or calls PrepareForRetry to restart the transaction.
+	txnRetryableError
+
 	// txnFinalized means that an EndTxn(commit=true) has been executed
 	// successfully, or an EndTxn(commit=false) was sent - regardless of
 	// whether it executed successfully or not. Further batches except
@@ -99,8 +107,8 @@ type TxnCoordSender struct {
 		syncutil.Mutex
 
 		txnState txnState
-		// storedErr is set when txnState == txnError. This storedErr is returned to
-		// clients on Send().
+		// storedErr is set when txnState == txnError or txnRetryableError. This
+		// storedErr is returned to clients on Send().
 		storedErr *roachpb.Error
 
 		// active is set whenever the transaction has sent any requests. Rolling
@@ -494,6 +502,11 @@ func (tc *TxnCoordSender) Send(
 	// Clone the Txn's Proto so that future modifications can be made without
 	// worrying about synchronization.
 	ba.Txn = tc.mu.txn.Clone()
+	// The user priority is ignored by evaluation when a Txn is set, but is
+	// used to order the batch in admission control. The transaction's priority
+	// can't be used for that, since it may have been upgraded since it was
+	// derived from the user priority.
+	ba.UserPriority = tc.mu.userPriority
 
 	// Send the command through the txnInterceptor stack.
 	br, pErr := tc.interceptorStack[0].SendLocked(ctx, ba)
@@ -574,7 +587,7 @@ func (tc *TxnCoordSender) maybeRejectClientLocked(
 	switch tc.mu.txnState {
 	case txnPending:
 		// All good.
-	case txnError:
+	case txnError, txnRetryableError:
 		return tc.mu.storedErr
 	case txnFinalized:
 		msg := fmt.Sprintf("client already committed or rolled back the transaction. "+
@@ -705,6 +718,21 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 		return retErr
 	}
 
+	// Transactions that read from a new snapshot in each statement don't
+	// restart right away. The client can retry only the statement that
+	// encountered the error by rolling back to a savepoint taken before it, at
+	// which point the statement's writes are discarded. If it doesn't, it
+	// restarts the transaction through PrepareForRetry.
+	if tc.typ == kv.RootTxn && tc.mu.txn.IsoLevel.ToleratesWriteSkew() {
+		log.VEventf(ctx, 2, "deferring restart of transaction on retryable error")
+		tc.mu.txn.ReadTimestamp.Forward(newTxn.ReadTimestamp)
+		tc.mu.txn.WriteTimestamp.Forward(newTxn.WriteTimestamp)
+		tc.interceptorAlloc.txnSpanRefresher.readTimestampSteppedLocked(tc.mu.txn.ReadTimestamp)
+		tc.mu.txnState = txnRetryableError
+		tc.mu.storedErr = roachpb.NewError(retErr)
+		return retErr
+	}
+
 	// This is where we get a new epoch.
 	tc.mu.txn.Update(&newTxn)
 
@@ -865,6 +893,24 @@ func (tc *TxnCoordSender) SetUserPriority(pri roachpb.UserPriority) error {
 	return nil
 }
 
+// SetIsolationLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) SetIsolationLevel(isoLevel enginepb.IsolationLevel) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.mu.active && isoLevel != tc.mu.txn.IsoLevel {
+		return errors.New("cannot change the isolation level of a running transaction")
+	}
+	tc.mu.txn.IsoLevel = isoLevel
+	return nil
+}
+
+// IsolationLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) IsolationLevel() enginepb.IsolationLevel {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.mu.txn.IsoLevel
+}
+
 // SetDebugName is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) SetDebugName(name string) {
 	tc.mu.Lock()
@@ -956,11 +1002,32 @@ func (tc *TxnCoordSender) ManualRestart(
 	tc.mu.txnState = txnPending
 }
 
+// PrepareForRetry is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) PrepareForRetry(ctx context.Context) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.mu.txnState != txnRetryableError {
+		return
+	}
+	log.VEventf(ctx, 2, "restarting transaction after deferred retryable error")
+	tc.mu.txn.Restart(tc.mu.userPriority, 0 /* upgradePriority */, tc.mu.txn.WriteTimestamp)
+	for _, reqInt := range tc.interceptorStack {
+		reqInt.epochBumpedLocked()
+	}
+	tc.mu.txnState = txnPending
+	tc.mu.storedErr = nil
+}
+
 // IsSerializablePushAndRefreshNotPossible is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) IsSerializablePushAndRefreshNotPossible() bool {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
+	if tc.mu.txn.IsoLevel.ToleratesWriteSkew() {
+		// The transaction can commit at a pushed timestamp without refreshing.
+		return false
+	}
 	isTxnPushed := tc.mu.txn.WriteTimestamp != tc.mu.txn.ReadTimestamp
 	refreshAttemptNotPossible := tc.interceptorAlloc.txnSpanRefresher.refreshInvalid ||
 		tc.mu.txn.CommitTimestampFixed
@@ -1126,6 +1193,31 @@ func (tc *TxnCoordSender) Step(ctx context.Context) error {
 	return tc.interceptorAlloc.txnSeqNumAllocator.stepLocked(ctx)
 }
 
+// StepReadTimestamp is part of the TxnSender interface.
+func (tc *TxnCoordSender) StepReadTimestamp(ctx context.Context) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.typ != kv.RootTxn || !tc.mu.txn.IsoLevel.ToleratesWriteSkew() {
+		return nil
+	}
+	if tc.mu.txnState != txnPending || tc.mu.txn.CommitTimestampFixed {
+		// The transaction's read timestamp can't be moved. This is the case for
+		// transactions that have failed or that have leaked their timestamp.
+		return nil
+	}
+	// Establish a new read snapshot at the current time. The transaction's
+	// uncertainty interval is reset to begin at the new read timestamp, which
+	// means that observed timestamps collected so far no longer apply.
+	now := tc.clock.Now()
+	tc.mu.txn.ReadTimestamp.Forward(now)
+	tc.mu.txn.WriteTimestamp.Forward(now)
+	tc.mu.txn.MaxTimestamp.Forward(now.Add(tc.clock.MaxOffset().Nanoseconds(), 0))
+	tc.mu.txn.ResetObservedTimestamps()
+	tc.interceptorAlloc.txnSpanRefresher.readTimestampSteppedLocked(tc.mu.txn.ReadTimestamp)
+	log.VEventf(ctx, 2, "stepped read timestamp to %s", tc.mu.txn.ReadTimestamp)
+	return nil
+}
+
 // ConfigureStepping is part of the TxnSender interface.
 func (tc *TxnCoordSender) ConfigureStepping(
 	ctx context.Context, mode kv.SteppingMode,
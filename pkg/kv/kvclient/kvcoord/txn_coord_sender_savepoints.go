@@ -114,6 +114,10 @@ func (tc *TxnCoordSender) RollbackToSavepoint(ctx context.Context, s kv.Savepoin
 	// will be discarded. See
 	// https://github.com/cockroachdb/cockroach/issues/47587.
 	//
+	// Rolling back after a retryable error whose transaction restart was
+	// deferred is allowed; it is how a single statement is retried (see
+	// txnRetryableError).
+	//
 	// TODO(andrei): White-list more errors.
 	if tc.mu.txnState == txnError {
 		return unimplemented.New("rollback_error", "cannot rollback to savepoint after error")
@@ -135,6 +139,7 @@ func (tc *TxnCoordSender) RollbackToSavepoint(ctx context.Context, s kv.Savepoin
 
 	// Restore the transaction's state, in case we're rewiding after an error.
 	tc.mu.txnState = txnPending
+	tc.mu.storedErr = nil
 
 	tc.mu.active = sp.active
 
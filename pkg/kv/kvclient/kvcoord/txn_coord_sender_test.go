@@ -2399,3 +2399,78 @@ func TestPutsInStagingTxn(t *testing.T) {
 	// seen a batch with the STAGING status.
 	require.True(t, putInStagingSeen)
 }
+
+// TestTxnCoordSenderReadCommitted verifies that READ COMMITTED transactions
+// observe writes committed before each read snapshot was established and
+// don't track refresh spans. Writing over a key that was modified since the
+// read snapshot was established returns a retryable error without restarting
+// the transaction, so that the write can be retried at a new read snapshot
+// after rolling back to a savepoint.
+func TestTxnCoordSenderReadCommitted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	s := createTestDB(t)
+	defer s.Stop()
+
+	ctx := context.Background()
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	txn := kv.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */)
+	require.NoError(t, txn.SetIsolationLevel(enginepb.READ_COMMITTED))
+	require.Equal(t, enginepb.READ_COMMITTED, txn.IsolationLevel())
+
+	// Stepping the read timestamp before the transaction is active is
+	// harmless.
+	require.NoError(t, txn.StepReadTimestamp(ctx))
+
+	for _, key := range []roachpb.Key{keyA, keyB} {
+		res, err := txn.Get(ctx, key)
+		require.NoError(t, err)
+		require.False(t, res.Exists())
+	}
+
+	// The isolation level can't be changed once the transaction is active.
+	require.Regexp(t, "cannot change the isolation level of a running transaction",
+		txn.SetIsolationLevel(enginepb.SERIALIZABLE))
+
+	// No refresh spans were recorded for the reads.
+	tcs := txn.Sender().(*TxnCoordSender)
+	require.True(t, tcs.interceptorAlloc.txnSpanRefresher.refreshFootprint.empty())
+
+	// A concurrent writer commits to both keys.
+	require.NoError(t, s.DB.Put(ctx, keyA, "a"))
+	require.NoError(t, s.DB.Put(ctx, keyB, "b"))
+
+	// The write is not visible until a new read snapshot is established.
+	res, err := txn.Get(ctx, keyA)
+	require.NoError(t, err)
+	require.False(t, res.Exists())
+
+	// Write over the key that was modified since the transaction read it. The
+	// write is rejected, but the transaction is not restarted.
+	sp, err := txn.CreateSavepoint(ctx)
+	require.NoError(t, err)
+	err = txn.Put(ctx, keyB, "c")
+	require.True(t, errors.HasType(err, (*roachpb.TransactionRetryWithProtoRefreshError)(nil)), "%+v", err)
+	require.Equal(t, enginepb.TxnEpoch(0), txn.Epoch())
+
+	// Further requests are rejected until the transaction rolls back to the
+	// savepoint.
+	_, err = txn.Get(ctx, keyA)
+	require.True(t, errors.HasType(err, (*roachpb.TransactionRetryWithProtoRefreshError)(nil)), "%+v", err)
+	require.NoError(t, txn.RollbackToSavepoint(ctx, sp))
+
+	// Retry the write at a new read snapshot.
+	require.NoError(t, txn.StepReadTimestamp(ctx))
+	res, err = txn.Get(ctx, keyA)
+	require.NoError(t, err)
+	require.True(t, res.Exists())
+	require.NoError(t, txn.Put(ctx, keyB, "c"))
+
+	// The transaction commits without restarting.
+	require.NoError(t, txn.Commit(ctx))
+	require.Equal(t, enginepb.TxnEpoch(0), txn.Epoch())
+
+	res, err = s.DB.Get(ctx, keyB)
+	require.NoError(t, err)
+	require.Equal(t, []byte("c"), res.ValueBytes())
+}
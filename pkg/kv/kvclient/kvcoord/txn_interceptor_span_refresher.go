@@ -211,8 +211,10 @@ func (sr *txnSpanRefresher) SendLocked(
 	}
 
 	// Iterate over and aggregate refresh spans in the requests, qualified by
-	// possible resume spans in the responses.
-	if !sr.refreshInvalid {
+	// possible resume spans in the responses. Transactions that tolerate write
+	// skew never need to validate their reads at a later timestamp, so they
+	// don't track any refresh spans.
+	if !sr.refreshInvalid && !br.Txn.IsoLevel.ToleratesWriteSkew() {
 		if err := sr.appendRefreshSpans(ctx, ba, br); err != nil {
 			return nil, roachpb.NewError(err)
 		}
@@ -322,6 +324,13 @@ func (sr *txnSpanRefresher) sendLockedWithRefreshAttempts(
 func (sr *txnSpanRefresher) maybeRefreshAndRetrySend(
 	ctx context.Context, ba roachpb.BatchRequest, pErr *roachpb.Error, maxRefreshAttempts int,
 ) (*roachpb.BatchResponse, *roachpb.Error) {
+	// Transactions that read from a new snapshot in each statement don't
+	// track the reads of the current statement, so they can't refresh them.
+	// The error is propagated so that the statement can be retried.
+	if ba.Txn.IsoLevel.ToleratesWriteSkew() {
+		return nil, pErr
+	}
+
 	// Check for an error which can be retried after updating spans.
 	canRefreshTxn, refreshTxn := roachpb.CanTransactionRefresh(ctx, pErr)
 	if !canRefreshTxn || !sr.canAutoRetry {
@@ -458,6 +467,12 @@ func (sr *txnSpanRefresher) maybeRefreshPreemptively(
 		return ba, nil
 	}
 
+	// Transactions that tolerate write skew can commit at a pushed timestamp
+	// without refreshing.
+	if ba.Txn.IsoLevel.ToleratesWriteSkew() {
+		return ba, nil
+	}
+
 	// If true, tryUpdatingTxnSpans will trivially succeed.
 	refreshFree := ba.CanForwardReadTimestamp
 
@@ -607,8 +622,14 @@ func (sr *txnSpanRefresher) appendRefreshSpans(
 //
 // Note that when deciding whether a transaction can be bumped to a particular
 // timestamp, the transaction's deadling must also be taken into account.
+//
+// Transactions that tolerate write skew don't track refresh spans, so their
+// empty refresh footprint says nothing about the reads performed by the
+// current statement. Forwarding their read timestamp in the middle of a
+// statement could base a write on a stale read, so it is never allowed.
 func (sr *txnSpanRefresher) canForwardReadTimestampWithoutRefresh(txn *roachpb.Transaction) bool {
-	return sr.canAutoRetry && !sr.refreshInvalid && sr.refreshFootprint.empty() && !txn.CommitTimestampFixed
+	return sr.canAutoRetry && !sr.refreshInvalid && sr.refreshFootprint.empty() &&
+		!txn.CommitTimestampFixed && !txn.IsoLevel.ToleratesWriteSkew()
 }
 
 // forwardRefreshTimestampOnResponse updates the refresher's tracked
@@ -670,6 +691,14 @@ func (sr *txnSpanRefresher) importLeafFinalState(
 	}
 }
 
+// readTimestampSteppedLocked is called by the TxnCoordSender when a
+// transaction that tolerates write skew moves its read timestamp forward to
+// establish a new read snapshot. Such a transaction tracks no refresh spans, so
+// the new timestamp can be adopted without a refresh.
+func (sr *txnSpanRefresher) readTimestampSteppedLocked(ts hlc.Timestamp) {
+	sr.refreshedTimestamp.Forward(ts)
+}
+
 // epochBumpedLocked implements the txnInterceptor interface.
 func (sr *txnSpanRefresher) epochBumpedLocked() {
 	sr.refreshFootprint.clear()
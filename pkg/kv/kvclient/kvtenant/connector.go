@@ -58,6 +58,11 @@ type Connector interface {
 	// obviates the need for SQL-only tenant processes to join the cluster-wide
 	// gossip network.
 	config.SystemConfigProvider
+
+	// ReportTenantConsumption reports the resources consumed by the SQL-only
+	// tenant process, such as CPU time, to the KV nodes, which meter them
+	// alongside the resources consumed by the tenant's KV requests.
+	ReportTenantConsumption(context.Context, *roachpb.ReportTenantConsumptionRequest) error
 }
 
 // ConnectorConfig encompasses the configuration required to create a Connector.
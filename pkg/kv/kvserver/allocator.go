@@ -214,21 +214,27 @@ func makeAllocatorRand(source rand.Source) allocatorRand {
 // RangeUsageInfo contains usage information (sizes and traffic) needed by the
 // allocator to make rebalancing decisions for a given range.
 type RangeUsageInfo struct {
-	LogicalBytes     int64
-	QueriesPerSecond float64
-	WritesPerSecond  float64
+	LogicalBytes        int64
+	QueriesPerSecond    float64
+	WritesPerSecond     float64
+	WriteBytesPerSecond float64
 }
 
-func rangeUsageInfoForRepl(repl *Replica) RangeUsageInfo {
+// rangeUsageInfo returns the usage of the replica's range, as measured while
+// the replica held the lease.
+func (r *Replica) rangeUsageInfo() RangeUsageInfo {
 	info := RangeUsageInfo{
-		LogicalBytes: repl.GetMVCCStats().Total(),
+		LogicalBytes: r.GetMVCCStats().Total(),
 	}
-	if queriesPerSecond, dur := repl.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
+	if queriesPerSecond, dur := r.leaseholderStats.avgQPS(); dur >= MinStatsDuration {
 		info.QueriesPerSecond = queriesPerSecond
 	}
-	if writesPerSecond, dur := repl.writeStats.avgQPS(); dur >= MinStatsDuration {
+	if writesPerSecond, dur := r.writeStats.avgQPS(); dur >= MinStatsDuration {
 		info.WritesPerSecond = writesPerSecond
 	}
+	if writeBytesPerSecond, dur := r.writeBytesStats.avgQPS(); dur >= MinStatsDuration {
+		info.WriteBytesPerSecond = writeBytesPerSecond
+	}
 	return info
 }
 
@@ -1078,10 +1084,15 @@ func (a Allocator) shouldTransferLeaseUsingStats(
 		if !ok {
 			continue
 		}
-		addr, err := a.storePool.gossip.GetNodeIDAddress(repl.NodeID)
-		if err != nil {
-			log.Errorf(ctx, "missing address for n%d: %+v", repl.NodeID, err)
-			continue
+		// The allocator simulator runs without gossip, in which case the address
+		// of the node is taken from its store descriptor.
+		addr := &storeDesc.Node.Address
+		if a.storePool.gossip != nil {
+			var err error
+			if addr, err = a.storePool.gossip.GetNodeIDAddress(repl.NodeID); err != nil {
+				log.Errorf(ctx, "missing address for n%d: %+v", repl.NodeID, err)
+				continue
+			}
 		}
 		remoteLatency, ok := a.nodeLatencyFn(addr.String())
 		if !ok {
@@ -91,7 +91,10 @@ var rangeRebalanceThreshold = func() *settings.FloatSetting {
 type scorerOptions struct {
 	deterministic           bool
 	rangeRebalanceThreshold float64
-	qpsRebalanceThreshold   float64 // only considered if non-zero
+	// loadRebalanceThreshold is the threshold for the dimension of load
+	// selected by loadObjective. It is only considered if non-zero.
+	loadRebalanceThreshold float64
+	loadObjective          LBRebalancingObjective
 }
 
 type balanceDimensions struct {
@@ -435,12 +438,14 @@ func allocateCandidates(
 		diversityScore := diversityAllocateScore(s, existingStoreLocalities)
 		balanceScore := balanceScore(candidateStores, s.Capacity, options)
 		var convergesScore int
-		if options.qpsRebalanceThreshold > 0 {
-			if s.Capacity.QueriesPerSecond < underfullThreshold(candidateStores.candidateQueriesPerSecond.mean, options.qpsRebalanceThreshold) {
+		if options.loadRebalanceThreshold > 0 {
+			load := options.loadObjective.storeLoad(s.Capacity)
+			meanLoad := options.loadObjective.meanLoad(candidateStores)
+			if load < underfullThreshold(meanLoad, options.loadRebalanceThreshold) {
 				convergesScore = 1
-			} else if s.Capacity.QueriesPerSecond < candidateStores.candidateQueriesPerSecond.mean {
+			} else if load < meanLoad {
 				convergesScore = 0
-			} else if s.Capacity.QueriesPerSecond < overfullThreshold(candidateStores.candidateQueriesPerSecond.mean, options.qpsRebalanceThreshold) {
+			} else if load < overfullThreshold(meanLoad, options.loadRebalanceThreshold) {
 				convergesScore = -1
 			} else {
 				convergesScore = -2
@@ -0,0 +1,873 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/raft/v3/tracker"
+)
+
+// The components of the simulated cluster that make replica and lease
+// movements.
+const (
+	SimulatedSourceReplicateQueue  = "replicate queue"
+	SimulatedSourceStoreRebalancer = "store rebalancer"
+)
+
+// SimulatedRange describes a range of the cluster simulated by
+// SimulateAllocator.
+type SimulatedRange struct {
+	// Desc is the descriptor of the range. Its replicas must be voters,
+	// non-voters or witnesses; learners and joint configurations aren't
+	// supported.
+	Desc roachpb.RangeDescriptor
+	// Leaseholder is the store holding the range's lease. It must hold one of
+	// the range's voters that isn't a witness.
+	Leaseholder roachpb.StoreID
+	// Zone is the zone config of the range. If nil, the simulation's default
+	// zone config is used.
+	Zone *zonepb.ZoneConfig
+
+	// The load of the range. The requests counted by QueriesPerSecond are
+	// assumed to come evenly from all the live nodes of the cluster.
+	LogicalBytes        int64
+	QueriesPerSecond    float64
+	EvalNanosPerSecond  float64
+	WritesPerSecond     float64
+	WriteBytesPerSecond float64
+}
+
+// AllocatorSimulationInput describes the cluster simulated by
+// SimulateAllocator.
+type AllocatorSimulationInput struct {
+	// Stores are the descriptors of the stores in the cluster. The load and
+	// range statistics of their capacities are recomputed from Ranges. If
+	// Capacity.Available is unset, it is derived from Capacity.Capacity.
+	Stores []roachpb.StoreDescriptor
+	// NodeStatus is the liveness status of the nodes in the cluster. Nodes
+	// that are not listed are live.
+	NodeStatus map[roachpb.NodeID]livenesspb.NodeLivenessStatus
+	Ranges     []SimulatedRange
+	// Zone is the zone config of ranges that don't specify their own. If its
+	// NumReplicas is unset, the default zone config is used.
+	Zone zonepb.ZoneConfig
+	// Tick is the virtual time between consecutive passes of the replicate
+	// queue and the store rebalancers over the cluster. It defaults to the
+	// interval at which the store rebalancer runs.
+	Tick time.Duration
+	// Duration is the virtual time after which the simulation is stopped if
+	// the cluster hasn't converged to a stable state by then.
+	Duration time.Duration
+}
+
+// SimulatedChange is a replica or lease movement made in the simulated
+// cluster.
+type SimulatedChange struct {
+	// Elapsed is the virtual time, since the start of the simulation, at
+	// which the change was made.
+	Elapsed time.Duration
+	RangeID roachpb.RangeID
+	// Source is the component that decided on the change.
+	Source string
+	// Reason describes why the change was made.
+	Reason string
+	// Added and Removed are the replicas added to and removed from the range.
+	// Replicas whose type changed, such as non-voters promoted to voters, are
+	// listed as added with their new type.
+	Added   []roachpb.ReplicaDescriptor
+	Removed []roachpb.ReplicaDescriptor
+	// LeaseTarget is the store the lease was moved to, if any.
+	LeaseTarget roachpb.StoreID
+	// BytesMoved is the number of bytes sent in snapshots to the added
+	// replicas.
+	BytesMoved int64
+}
+
+// AllocatorSimulationResult is the outcome of SimulateAllocator.
+type AllocatorSimulationResult struct {
+	Changes []SimulatedChange
+	// Before and After are the descriptors of the stores at the start and at
+	// the end of the simulation.
+	Before []roachpb.StoreDescriptor
+	After  []roachpb.StoreDescriptor
+	// Elapsed is the virtual time the simulation ran for.
+	Elapsed time.Duration
+	// Converged is true if the simulation stopped because the cluster reached
+	// a state in which no further changes were made.
+	Converged bool
+}
+
+// BytesMoved returns the total number of bytes sent in snapshots.
+func (r AllocatorSimulationResult) BytesMoved() int64 {
+	var bytes int64
+	for _, c := range r.Changes {
+		bytes += c.BytesMoved
+	}
+	return bytes
+}
+
+// SimulateAllocator runs the allocator, the replicate queue and the store
+// rebalancer in virtual time against a snapshot of a cluster, and reports the
+// replica and lease movements they would make. The decisions are made by the
+// same code that runs in a real cluster, configured by the provided settings;
+// only their execution is simulated.
+//
+// Every tick, the store descriptors are refreshed from the simulated ranges,
+// every range is processed by the replicate queue in priority order, and then
+// the store rebalancer runs on every live store. Replica additions are limited
+// by the snapshot rate of the receiving store, so the simulation gives an idea
+// of how long the movements take. Lease transfers for rebalancing are limited
+// by kv.allocator.min_lease_transfer_interval. Non-voting replicas and
+// witnesses are carried along with the ranges, but the replicate queue makes no
+// decisions about non-voters.
+func SimulateAllocator(
+	ctx context.Context, st *cluster.Settings, input AllocatorSimulationInput,
+) (AllocatorSimulationResult, error) {
+	s, err := newAllocatorSimulator(st, input)
+	if err != nil {
+		return AllocatorSimulationResult{}, err
+	}
+	return s.run(ctx, input.Duration), nil
+}
+
+type simStore struct {
+	desc  roachpb.StoreDescriptor
+	store *Store
+	// rq is the replicate queue of the store. It decides on the changes to the
+	// ranges whose lease is held by the store.
+	rq *replicateQueue
+	// snapshotBudget is the time left in the current tick to receive
+	// snapshots. It goes negative when a snapshot takes longer than the
+	// remaining time, delaying further snapshots to the store.
+	snapshotBudget time.Duration
+	// leaseTransfers is the number of lease transfers left in the current tick.
+	leaseTransfers int
+}
+
+// simRange is a range of the simulated cluster.
+type simRange struct {
+	SimulatedRange
+	s    *allocatorSimulator
+	zone *zonepb.ZoneConfig
+	// stats are the requests received by the leaseholder, attributed evenly to
+	// the live nodes of the cluster. They're recorded every tick.
+	stats *replicaStats
+}
+
+func (r *simRange) String() string {
+	return fmt.Sprintf("r%d", r.Desc.RangeID)
+}
+
+// simReplica is the replica of a simulated range on the store processing the
+// range, which holds the lease. It implements replicateQueueRange, applying the
+// changes decided by the replicate queue to the simulated cluster.
+type simReplica struct {
+	*simRange
+	storeID roachpb.StoreID
+}
+
+var _ replicateQueueRange = simReplica{}
+
+// StoreID implements the replicateQueueRange interface.
+func (r simReplica) StoreID() roachpb.StoreID {
+	return r.storeID
+}
+
+// DescAndZone implements the replicateQueueRange interface.
+func (r *simRange) DescAndZone() (*roachpb.RangeDescriptor, *zonepb.ZoneConfig) {
+	return &r.Desc, r.zone
+}
+
+// LastReplicaAdded implements the replicateQueueRange interface. Added
+// replicas are caught up immediately, so they're never protected from
+// removal.
+func (r *simRange) LastReplicaAdded() (roachpb.ReplicaID, time.Time) {
+	return 0, time.Time{}
+}
+
+// RaftStatus implements the replicateQueueRange interface.
+func (r *simRange) RaftStatus() *raft.Status {
+	return r.s.raftStatus(r)
+}
+
+// getLeaseholderStats implements the replicateQueueRange interface.
+func (r *simRange) getLeaseholderStats() *replicaStats {
+	return r.stats
+}
+
+// recordRequests records the requests received by the leaseholder over the
+// given duration.
+func (r *simRange) recordRequests(d time.Duration, liveNodes []roachpb.NodeID) {
+	if r.QueriesPerSecond == 0 || len(liveNodes) == 0 {
+		return
+	}
+	count := r.QueriesPerSecond * d.Seconds() / float64(len(liveNodes))
+	for _, nodeID := range liveNodes {
+		r.stats.recordCount(count, nodeID)
+	}
+}
+
+// rangeUsageInfo implements the replicateQueueRange interface.
+func (r *simRange) rangeUsageInfo() RangeUsageInfo {
+	return RangeUsageInfo{
+		LogicalBytes:        r.LogicalBytes,
+		QueriesPerSecond:    r.QueriesPerSecond,
+		WritesPerSecond:     r.WritesPerSecond,
+		WriteBytesPerSecond: r.WriteBytesPerSecond,
+	}
+}
+
+// changeReplicasImpl implements the replicateQueueRange interface.
+func (r *simRange) changeReplicasImpl(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	priority SnapshotRequest_Priority,
+	reason kvserverpb.RangeLogEventReason,
+	details string,
+	chgs roachpb.ReplicationChanges,
+) (*roachpb.RangeDescriptor, error) {
+	if err := r.s.changeReplicas(r, chgs, priority, string(reason), false /* force */); err != nil {
+		return nil, err
+	}
+	return &r.Desc, nil
+}
+
+// AdminTransferLease implements the replicateQueueRange interface.
+func (r *simRange) AdminTransferLease(ctx context.Context, target roachpb.StoreID) error {
+	repl, ok := r.Desc.GetReplicaDescriptor(target)
+	if !ok || repl.GetType() != roachpb.VOTER_FULL {
+		return errors.Errorf("%s: s%d does not hold a voter that can take the lease", r, target)
+	}
+	r.s.transferLease(r, target)
+	return nil
+}
+
+// finalizeAtomicReplicationChange implements the replicateQueueRange
+// interface.
+func (r *simRange) finalizeAtomicReplicationChange(ctx context.Context) error {
+	return errors.AssertionFailedf("%s: simulated ranges are never in joint configurations", r)
+}
+
+// errSnapshotBudgetExhausted is returned when a change is deferred because
+// one of the stores receiving a replica has no snapshot budget left in the
+// current tick.
+var errSnapshotBudgetExhausted = errors.New("receiving store has no snapshot budget left")
+
+type allocatorSimulator struct {
+	log.AmbientContext
+	st        *cluster.Settings
+	manual    *hlc.ManualClock
+	clock     *hlc.Clock
+	storePool *StorePool
+	allocator Allocator
+	tick      time.Duration
+	elapsed   time.Duration
+
+	nodeStatus map[roachpb.NodeID]livenesspb.NodeLivenessStatus
+	stores     map[roachpb.StoreID]*simStore
+	storeIDs   roachpb.StoreIDSlice
+	ranges     []*simRange
+	rangeByID  map[roachpb.RangeID]*simRange
+	// source is the component making changes to the cluster.
+	source string
+
+	changes []SimulatedChange
+	// deferred counts the changes in the current tick that were postponed
+	// because the receiving store had no snapshot budget left.
+	deferred int
+}
+
+func newAllocatorSimulator(
+	st *cluster.Settings, input AllocatorSimulationInput,
+) (*allocatorSimulator, error) {
+	s := &allocatorSimulator{
+		AmbientContext: log.AmbientContext{Tracer: st.Tracer},
+		st:             st,
+		manual:         hlc.NewManualClock(1),
+		tick:           input.Tick,
+		nodeStatus:     input.NodeStatus,
+		stores:         make(map[roachpb.StoreID]*simStore, len(input.Stores)),
+		rangeByID:      make(map[roachpb.RangeID]*simRange, len(input.Ranges)),
+	}
+	s.AddLogTag("allocator-sim", nil)
+	if s.tick <= 0 {
+		s.tick = storeRebalancerTimerDuration
+	}
+	s.clock = hlc.NewClock(s.manual.UnixNano, time.Nanosecond)
+	s.storePool = NewStorePool(
+		s.AmbientContext,
+		st,
+		nil, /* gossip */
+		s.clock,
+		s.nodeCount,
+		func(nodeID roachpb.NodeID, _ time.Time, _ time.Duration) livenesspb.NodeLivenessStatus {
+			return s.livenessStatus(nodeID)
+		},
+		true, /* deterministic */
+	)
+	// The latency between the simulated nodes is unknown, so it is assumed to
+	// be zero. Lease transfers are then balanced by count, regardless of where
+	// the requests come from.
+	s.allocator = MakeAllocator(s.storePool, func(string) (time.Duration, bool) {
+		return 0, true
+	})
+
+	defaultZone := input.Zone
+	if defaultZone.NumReplicas == nil {
+		defaultZone = *zonepb.DefaultZoneConfigRef()
+	}
+
+	for _, desc := range input.Stores {
+		if _, ok := s.stores[desc.StoreID]; ok {
+			return nil, errors.Errorf("duplicate store s%d", desc.StoreID)
+		}
+		desc.Capacity.RangeCount = 0
+		desc.Capacity.LeaseCount = 0
+		desc.Capacity.LogicalBytes = 0
+		desc.Capacity.QueriesPerSecond = 0
+		desc.Capacity.EvalNanosPerSecond = 0
+		desc.Capacity.WritesPerSecond = 0
+		desc.Capacity.WriteBytesPerSecond = 0
+		if desc.Capacity.Available == 0 {
+			desc.Capacity.Available = desc.Capacity.Capacity - desc.Capacity.Used
+		}
+		ss := &simStore{
+			desc: desc,
+			store: &Store{
+				Ident: &roachpb.StoreIdent{NodeID: desc.Node.NodeID, StoreID: desc.StoreID},
+				cfg: StoreConfig{
+					AmbientCtx: s.AmbientContext,
+					Clock:      s.clock,
+					Settings:   st,
+					StorePool:  s.storePool,
+				},
+			},
+		}
+		ss.rq = &replicateQueue{
+			baseQueue: &baseQueue{store: ss.store},
+			metrics:   makeReplicateQueueMetrics(),
+			allocator: s.allocator,
+		}
+		s.stores[desc.StoreID] = ss
+		s.storeIDs = append(s.storeIDs, desc.StoreID)
+	}
+	sort.Sort(s.storeIDs)
+
+	// The disk usage of the stores is only adjusted for the logical bytes of
+	// their replicas if it wasn't provided.
+	adjustUsage := make(map[roachpb.StoreID]bool)
+	for _, ss := range s.stores {
+		adjustUsage[ss.desc.StoreID] = ss.desc.Capacity.Used == 0
+	}
+
+	for i := range input.Ranges {
+		r := &simRange{
+			SimulatedRange: input.Ranges[i],
+			s:              s,
+			zone:           input.Ranges[i].Zone,
+			stats:          newReplicaStats(s.clock, s.storePool.getNodeLocalityString),
+		}
+		if r.zone == nil {
+			r.zone = &defaultZone
+		}
+		rangeID := r.Desc.RangeID
+		if _, ok := s.rangeByID[rangeID]; ok {
+			return nil, errors.Errorf("duplicate range r%d", rangeID)
+		}
+		r.Desc.InternalReplicas = append([]roachpb.ReplicaDescriptor(nil), r.Desc.InternalReplicas...)
+		if r.Desc.NextReplicaID == 0 {
+			for _, repl := range r.Desc.InternalReplicas {
+				if repl.ReplicaID >= r.Desc.NextReplicaID {
+					r.Desc.NextReplicaID = repl.ReplicaID + 1
+				}
+			}
+		}
+		if leaseholder, ok := r.Desc.GetReplicaDescriptor(r.Leaseholder); !ok ||
+			leaseholder.GetType() != roachpb.VOTER_FULL {
+			return nil, errors.Errorf("leaseholder s%d of r%d is not one of its voters %s",
+				r.Leaseholder, rangeID, r.Desc.Replicas())
+		}
+		for _, repl := range r.Desc.InternalReplicas {
+			switch repl.GetType() {
+			case roachpb.VOTER_FULL, roachpb.NON_VOTER, roachpb.WITNESS:
+			default:
+				return nil, errors.Errorf("replica %s of r%d has unsupported type %s",
+					repl, rangeID, repl.GetType())
+			}
+			ss, ok := s.stores[repl.StoreID]
+			if !ok {
+				return nil, errors.Errorf("replica of r%d on unknown store s%d", rangeID, repl.StoreID)
+			}
+			s.addReplicaLoad(ss, r, repl.GetType(), 1)
+			if !adjustUsage[repl.StoreID] && repl.GetType() != roachpb.WITNESS {
+				ss.desc.Capacity.Used -= r.LogicalBytes
+				ss.desc.Capacity.Available += r.LogicalBytes
+			}
+		}
+		s.addLeaseLoad(s.stores[r.Leaseholder], r, 1)
+		s.ranges = append(s.ranges, r)
+		s.rangeByID[rangeID] = r
+	}
+	sort.Slice(s.ranges, func(i, j int) bool {
+		return s.ranges[i].Desc.RangeID < s.ranges[j].Desc.RangeID
+	})
+	return s, nil
+}
+
+func (s *allocatorSimulator) livenessStatus(nodeID roachpb.NodeID) livenesspb.NodeLivenessStatus {
+	if status, ok := s.nodeStatus[nodeID]; ok {
+		return status
+	}
+	return livenesspb.NodeLivenessStatus_LIVE
+}
+
+// nodeCount mirrors NodeLiveness.GetNodeCount, counting dead nodes but not
+// decommissioning or decommissioned ones.
+func (s *allocatorSimulator) nodeCount() int {
+	nodes := make(map[roachpb.NodeID]struct{})
+	for _, ss := range s.stores {
+		switch s.livenessStatus(ss.desc.Node.NodeID) {
+		case livenesspb.NodeLivenessStatus_DECOMMISSIONING, livenesspb.NodeLivenessStatus_DECOMMISSIONED:
+		default:
+			nodes[ss.desc.Node.NodeID] = struct{}{}
+		}
+	}
+	return len(nodes)
+}
+
+func (s *allocatorSimulator) isLive(storeID roachpb.StoreID) bool {
+	switch s.livenessStatus(s.stores[storeID].desc.Node.NodeID) {
+	case livenesspb.NodeLivenessStatus_LIVE, livenesspb.NodeLivenessStatus_DECOMMISSIONING:
+		return true
+	}
+	return false
+}
+
+// addReplicaLoad adds (sign = 1) or removes (sign = -1) the range's replica
+// of the given type to the statistics of the store. Witnesses don't hold the
+// range's data, so they only count as a replica.
+func (s *allocatorSimulator) addReplicaLoad(
+	ss *simStore, r *simRange, typ roachpb.ReplicaType, sign int,
+) {
+	c := &ss.desc.Capacity
+	c.RangeCount += int32(sign)
+	if typ == roachpb.WITNESS {
+		return
+	}
+	c.LogicalBytes += int64(sign) * r.LogicalBytes
+	c.Used += int64(sign) * r.LogicalBytes
+	c.Available -= int64(sign) * r.LogicalBytes
+	c.WritesPerSecond += float64(sign) * r.WritesPerSecond
+	c.WriteBytesPerSecond += float64(sign) * r.WriteBytesPerSecond
+}
+
+// addLeaseLoad adds (sign = 1) or removes (sign = -1) the range's lease to the
+// statistics of the store.
+func (s *allocatorSimulator) addLeaseLoad(ss *simStore, r *simRange, sign int) {
+	c := &ss.desc.Capacity
+	c.LeaseCount += int32(sign)
+	c.QueriesPerSecond += float64(sign) * r.QueriesPerSecond
+	c.EvalNanosPerSecond += float64(sign) * r.EvalNanosPerSecond
+}
+
+func (s *allocatorSimulator) storeDescriptors() []roachpb.StoreDescriptor {
+	descs := make([]roachpb.StoreDescriptor, 0, len(s.storeIDs))
+	for _, storeID := range s.storeIDs {
+		descs = append(descs, s.stores[storeID].desc)
+	}
+	return descs
+}
+
+// run simulates the cluster until it converges or the duration elapses. At
+// the start of every tick, the store descriptors are made known to the
+// StorePool as gossip would; within a tick, the StorePool only learns of the
+// changes through the updates made by the replicate queue itself.
+func (s *allocatorSimulator) run(ctx context.Context, duration time.Duration) AllocatorSimulationResult {
+	ctx = s.AnnotateCtx(ctx)
+	res := AllocatorSimulationResult{Before: s.storeDescriptors()}
+	minLeaseTransfer := minLeaseTransferInterval.Get(&s.st.SV)
+	for s.elapsed < duration {
+		s.manual.Increment(s.tick.Nanoseconds())
+		s.elapsed += s.tick
+		var liveNodes []roachpb.NodeID
+		seenNodes := make(map[roachpb.NodeID]struct{})
+		for _, storeID := range s.storeIDs {
+			ss := s.stores[storeID]
+			ss.snapshotBudget += s.tick
+			if ss.snapshotBudget > s.tick {
+				ss.snapshotBudget = s.tick
+			}
+			ss.leaseTransfers = 1
+			if minLeaseTransfer > 0 && s.tick > minLeaseTransfer {
+				ss.leaseTransfers = int(s.tick / minLeaseTransfer)
+			}
+			s.storePool.storeDescriptorUpdate(ss.desc)
+			nodeID := ss.desc.Node.NodeID
+			if _, ok := seenNodes[nodeID]; !ok && s.livenessStatus(nodeID) == livenesspb.NodeLivenessStatus_LIVE {
+				seenNodes[nodeID] = struct{}{}
+				liveNodes = append(liveNodes, nodeID)
+			}
+		}
+		for _, r := range s.ranges {
+			r.recordRequests(s.tick, liveNodes)
+		}
+
+		numChanges := len(s.changes)
+		s.deferred = 0
+		s.acquireLeases()
+		s.runReplicateQueue(ctx)
+		s.runStoreRebalancers(ctx)
+		if len(s.changes) == numChanges && s.deferred == 0 {
+			res.Converged = true
+			break
+		}
+	}
+	res.Changes = s.changes
+	res.After = s.storeDescriptors()
+	res.Elapsed = s.elapsed
+	return res
+}
+
+// acquireLeases moves the leases held by stores on nodes that aren't live to
+// another live replica that can hold the lease, as the next request to the
+// range would.
+func (s *allocatorSimulator) acquireLeases() {
+	for _, r := range s.ranges {
+		if s.isLive(r.Leaseholder) {
+			continue
+		}
+		for _, repl := range r.Desc.Replicas().DataVoterDescriptors() {
+			if s.isLive(repl.StoreID) {
+				s.moveLease(r, repl.StoreID)
+				break
+			}
+		}
+	}
+}
+
+// moveLease moves the lease of the range to the target store. As with a real
+// lease change, the request statistics of the range are reset.
+func (s *allocatorSimulator) moveLease(r *simRange, target roachpb.StoreID) {
+	s.addLeaseLoad(s.stores[r.Leaseholder], r, -1)
+	s.addLeaseLoad(s.stores[target], r, 1)
+	r.Leaseholder = target
+	r.stats.resetRequestCounts()
+}
+
+func (s *allocatorSimulator) transferLease(r *simRange, target roachpb.StoreID) {
+	s.stores[r.Leaseholder].leaseTransfers--
+	s.moveLease(r, target)
+	reason := "lease rebalance"
+	if s.source == SimulatedSourceStoreRebalancer {
+		reason = "load-based lease transfer"
+	}
+	s.changes = append(s.changes, SimulatedChange{
+		Elapsed:     s.elapsed,
+		RangeID:     r.Desc.RangeID,
+		Source:      s.source,
+		Reason:      reason,
+		LeaseTarget: target,
+	})
+}
+
+// snapshotDuration returns how long it takes to send a snapshot of the range
+// at the given rate.
+func snapshotDuration(r *simRange, rate int64) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(r.LogicalBytes) / float64(rate) * float64(time.Second))
+}
+
+// changeReplicas applies the replication changes to the range. It returns
+// errSnapshotBudgetExhausted if the change was deferred because one of the
+// stores receiving a replica has no snapshot budget left in the current tick,
+// unless force is set. Unless force is set, the changes are validated like
+// those of a real range. Otherwise, replicas added to a store that already
+// holds one of the range's replicas change the type of that replica and need
+// no snapshot, as done by the relocations of the store rebalancer.
+func (s *allocatorSimulator) changeReplicas(
+	r *simRange,
+	chgs roachpb.ReplicationChanges,
+	priority SnapshotRequest_Priority,
+	reason string,
+	force bool,
+) error {
+	needsSnapshot := func(chg roachpb.ReplicationChange) bool {
+		if !chg.ChangeType.IsAddition() || chg.ChangeType == roachpb.ADD_WITNESS {
+			return false
+		}
+		_, ok := r.Desc.GetReplicaDescriptor(chg.Target.StoreID)
+		return !ok
+	}
+	if !force {
+		if err := validateReplicationChanges(&r.Desc, chgs); err != nil {
+			return err
+		}
+		for _, chg := range chgs {
+			if needsSnapshot(chg) && s.stores[chg.Target.StoreID].snapshotBudget <= 0 {
+				s.deferred++
+				return errSnapshotBudgetExhausted
+			}
+		}
+	}
+	var snapshotRate int64
+	switch priority {
+	case SnapshotRequest_RECOVERY:
+		snapshotRate = recoverySnapshotRate.Get(&s.st.SV)
+	default:
+		snapshotRate = rebalanceSnapshotRate.Get(&s.st.SV)
+	}
+
+	change := SimulatedChange{
+		Elapsed: s.elapsed,
+		RangeID: r.Desc.RangeID,
+		Source:  s.source,
+		Reason:  reason,
+	}
+	for _, chg := range chgs {
+		ss := s.stores[chg.Target.StoreID]
+		if chg.ChangeType.IsRemoval() {
+			repl, ok := r.Desc.RemoveReplica(chg.Target.NodeID, chg.Target.StoreID)
+			if !ok {
+				return errors.AssertionFailedf("%s: no replica to remove on s%d", r, chg.Target.StoreID)
+			}
+			s.addReplicaLoad(ss, r, repl.GetType(), -1)
+			change.Removed = append(change.Removed, repl)
+			continue
+		}
+		typ := roachpb.VOTER_FULL
+		switch chg.ChangeType {
+		case roachpb.ADD_NON_VOTER:
+			typ = roachpb.NON_VOTER
+		case roachpb.ADD_WITNESS:
+			typ = roachpb.WITNESS
+		}
+		if needsSnapshot(chg) {
+			ss.snapshotBudget -= snapshotDuration(r, snapshotRate)
+			change.BytesMoved += r.LogicalBytes
+		}
+		if prev, ok := r.Desc.GetReplicaDescriptor(chg.Target.StoreID); ok {
+			s.addReplicaLoad(ss, r, prev.GetType(), -1)
+			repl, _, _ := r.Desc.SetReplicaType(chg.Target.NodeID, chg.Target.StoreID, typ)
+			change.Added = append(change.Added, repl)
+		} else {
+			change.Added = append(change.Added, r.Desc.AddReplica(chg.Target.NodeID, chg.Target.StoreID, typ))
+		}
+		s.addReplicaLoad(ss, r, typ, 1)
+	}
+	s.changes = append(s.changes, change)
+	return nil
+}
+
+// raftStatus returns the raft status of the range as seen by its leaseholder,
+// with all live replicas caught up and the others being probed.
+func (s *allocatorSimulator) raftStatus(r *simRange) *raft.Status {
+	status := &raft.Status{
+		Progress: make(map[uint64]tracker.Progress),
+	}
+	status.RaftState = raft.StateLeader
+	status.Commit = 1
+	for _, repl := range r.Desc.InternalReplicas {
+		if repl.StoreID == r.Leaseholder {
+			status.ID = uint64(repl.ReplicaID)
+			status.Lead = uint64(repl.ReplicaID)
+		}
+		progress := tracker.Progress{State: tracker.StateProbe}
+		if s.isLive(repl.StoreID) {
+			progress = tracker.Progress{Match: 1, State: tracker.StateReplicate}
+		}
+		progress.IsLearner = repl.GetType() == roachpb.NON_VOTER
+		status.Progress[uint64(repl.ReplicaID)] = progress
+	}
+	return status
+}
+
+// runReplicateQueue processes every range whose leaseholder is live in order
+// of decreasing priority, making at most one change per range.
+func (s *allocatorSimulator) runReplicateQueue(ctx context.Context) {
+	s.source = SimulatedSourceReplicateQueue
+	type queued struct {
+		r        *simRange
+		priority float64
+	}
+	var queue []queued
+	for _, r := range s.ranges {
+		if !s.isLive(r.Leaseholder) {
+			continue
+		}
+		if action, priority := s.allocator.ComputeAction(ctx, r.zone, &r.Desc); action != AllocatorNoop {
+			queue = append(queue, queued{r: r, priority: priority})
+		}
+	}
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].priority > queue[j].priority
+	})
+	for _, q := range queue {
+		ss := s.stores[q.r.Leaseholder]
+		canTransferLease := func() bool { return ss.leaseTransfers > 0 }
+		repl := simReplica{simRange: q.r, storeID: ss.desc.StoreID}
+		if _, err := ss.rq.processOneChangeImpl(
+			ctx, repl, canTransferLease, false, /* dryRun */
+		); err != nil {
+			log.VEventf(ctx, 1, "%s: %v", q.r, err)
+		}
+	}
+}
+
+// runStoreRebalancers runs the store rebalancer of every live store, in
+// order of store ID.
+func (s *allocatorSimulator) runStoreRebalancers(ctx context.Context) {
+	s.source = SimulatedSourceStoreRebalancer
+	mode := LBRebalancingMode(LoadBasedRebalancingMode.Get(&s.st.SV))
+	if mode == LBRebalancingOff {
+		return
+	}
+	obj := LBRebalancingObjective(LoadBasedRebalancingObjective.Get(&s.st.SV))
+	for _, storeID := range s.storeIDs {
+		if !s.isLive(storeID) {
+			continue
+		}
+		sr := s.makeStoreRebalancer(s.stores[storeID])
+		storeList, _, _ := s.storePool.getStoreList(storeFilterNone)
+		sr.rebalanceStore(sr.AnnotateCtx(ctx), mode, obj, storeList)
+	}
+}
+
+// makeReplica returns a replica of the range on the store that is only
+// suitable for the store rebalancer's inspection of its descriptor, zone
+// config and lease.
+func (s *allocatorSimulator) makeReplica(ss *simStore, r *simRange) *Replica {
+	repl := &Replica{RangeID: r.Desc.RangeID, store: ss.store}
+	desc := r.Desc
+	desc.InternalReplicas = append([]roachpb.ReplicaDescriptor(nil), r.Desc.InternalReplicas...)
+	leaseholder, _ := desc.GetReplicaDescriptor(r.Leaseholder)
+	repl.mu.state.Desc = &desc
+	repl.mu.state.Lease = &roachpb.Lease{
+		Expiration: &hlc.MaxTimestamp,
+		Replica:    leaseholder,
+	}
+	repl.mu.state.Stats = &enginepb.MVCCStats{ValBytes: r.LogicalBytes}
+	repl.mu.zone = r.zone
+	repl.leaseholderStats = r.stats
+	return repl
+}
+
+func (s *allocatorSimulator) makeStoreRebalancer(ss *simStore) *StoreRebalancer {
+	rr := newReplicaRankings()
+	acc := rr.newAccumulator()
+	for _, r := range s.ranges {
+		if r.Leaseholder != ss.desc.StoreID {
+			continue
+		}
+		acc.addReplica(replicaWithStats{
+			repl:       s.makeReplica(ss, r),
+			qps:        r.QueriesPerSecond,
+			evalTime:   r.EvalNanosPerSecond,
+			writeBytes: r.WriteBytesPerSecond,
+		})
+	}
+	rr.update(acc)
+
+	sr := &StoreRebalancer{
+		AmbientContext: s.AmbientContext,
+		metrics:        makeStoreRebalancerMetrics(),
+		st:             s.st,
+		rq:             ss.rq,
+		replRankings:   rr,
+		getRaftStatusFn: func(repl *Replica) *raft.Status {
+			return s.raftStatus(s.rangeByID[repl.RangeID])
+		},
+	}
+	sr.AddLogTag("s", ss.desc.StoreID)
+	sr.transferLeaseFn = func(
+		ctx context.Context, repl *Replica, target roachpb.ReplicaDescriptor, rangeQPS float64,
+	) error {
+		r := simReplica{simRange: s.rangeByID[repl.RangeID], storeID: ss.desc.StoreID}
+		return sr.rq.transferLease(ctx, r, target, rangeQPS)
+	}
+	sr.relocateRangeFn = func(
+		ctx context.Context,
+		repl *Replica,
+		desc roachpb.RangeDescriptor,
+		voterTargets, nonVoterTargets []roachpb.ReplicationTarget,
+	) error {
+		r := s.rangeByID[repl.RangeID]
+		prevLeaseholder := r.Leaseholder
+		chgs := relocationChanges(&r.Desc, voterTargets, nonVoterTargets)
+		// RelocateRange transfers the lease to the first voter target. The
+		// lease is moved first so that the leaseholder is never removed.
+		leaseTarget := voterTargets[0].StoreID
+		if repl, ok := r.Desc.GetReplicaDescriptor(leaseTarget); ok &&
+			repl.GetType() == roachpb.VOTER_FULL && leaseTarget != r.Leaseholder {
+			s.moveLease(r, leaseTarget)
+		}
+		// The rebalancer acts on a snapshot of the store list and doesn't
+		// retry failed relocations, so relocations are never deferred; they
+		// still consume the snapshot budget of the receiving stores.
+		if err := s.changeReplicas(r, chgs, SnapshotRequest_REBALANCE,
+			"load-based rebalance", true /* force */); err != nil {
+			return err
+		}
+		if leaseTarget != r.Leaseholder {
+			s.moveLease(r, leaseTarget)
+		}
+		if leaseTarget != prevLeaseholder {
+			s.changes[len(s.changes)-1].LeaseTarget = leaseTarget
+		}
+		return nil
+	}
+	return sr
+}
+
+// relocationChanges returns the changes that turn the replicas of the range
+// into voters on the voter targets and non-voters on the non-voter targets.
+// Replicas moving between voters and non-voters change type in place, since
+// additions on a store holding a replica of the range only change its type.
+func relocationChanges(
+	desc *roachpb.RangeDescriptor, voterTargets, nonVoterTargets []roachpb.ReplicationTarget,
+) roachpb.ReplicationChanges {
+	voters := desc.Replicas().Voters().ReplicationTargets()
+	nonVoters := desc.Replicas().NonVoters().ReplicationTargets()
+	targets := append(append([]roachpb.ReplicationTarget(nil), voterTargets...), nonVoterTargets...)
+	var chgs roachpb.ReplicationChanges
+	for _, op := range []struct {
+		changeType  roachpb.ReplicaChangeType
+		left, right []roachpb.ReplicationTarget
+	}{
+		{roachpb.ADD_VOTER, voterTargets, voters},
+		{roachpb.ADD_NON_VOTER, nonVoterTargets, nonVoters},
+		{roachpb.REMOVE_VOTER, voters, targets},
+		{roachpb.REMOVE_NON_VOTER, nonVoters, targets},
+	} {
+		for _, repl := range subtractTargets(op.left, op.right) {
+			chgs = append(chgs, roachpb.ReplicationChange{
+				ChangeType: op.changeType,
+				Target:     roachpb.ReplicationTarget{NodeID: repl.NodeID, StoreID: repl.StoreID},
+			})
+		}
+	}
+	return chgs
+}
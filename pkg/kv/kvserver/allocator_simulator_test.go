@@ -0,0 +1,245 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// makeSimulatedCluster returns an input with the given number of single-store
+// nodes, each in its own region, and ranges whose replicas are on the given
+// stores, with the lease on the first one.
+func makeSimulatedCluster(
+	numStores int, numRanges int, replicas []roachpb.StoreID, qps float64,
+) AllocatorSimulationInput {
+	var input AllocatorSimulationInput
+	for i := 1; i <= numStores; i++ {
+		input.Stores = append(input.Stores, roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i),
+			Node: roachpb.NodeDescriptor{
+				NodeID: roachpb.NodeID(i),
+				Locality: roachpb.Locality{
+					Tiers: []roachpb.Tier{{Key: "region", Value: fmt.Sprintf("r%d", i)}},
+				},
+			},
+			Capacity: roachpb.StoreCapacity{Capacity: 1 << 40},
+		})
+	}
+	for i := 1; i <= numRanges; i++ {
+		desc := roachpb.RangeDescriptor{RangeID: roachpb.RangeID(i)}
+		for _, storeID := range replicas {
+			desc.AddReplica(roachpb.NodeID(storeID), storeID, roachpb.VOTER_FULL)
+		}
+		input.Ranges = append(input.Ranges, SimulatedRange{
+			Desc:             desc,
+			Leaseholder:      replicas[0],
+			LogicalBytes:     64 << 20,
+			QueriesPerSecond: qps,
+		})
+	}
+	input.Duration = 24 * time.Hour
+	return input
+}
+
+func storeDescByID(descs []roachpb.StoreDescriptor, storeID roachpb.StoreID) roachpb.StoreDescriptor {
+	for _, desc := range descs {
+		if desc.StoreID == storeID {
+			return desc
+		}
+	}
+	return roachpb.StoreDescriptor{}
+}
+
+func TestSimulateAllocatorUpreplication(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	input := makeSimulatedCluster(5, 20, []roachpb.StoreID{1}, 0)
+
+	res, err := SimulateAllocator(ctx, st, input)
+	require.NoError(t, err)
+	require.True(t, res.Converged)
+	require.Equal(t, int32(20), storeDescByID(res.Before, 1).Capacity.RangeCount)
+
+	var rangeCount int32
+	for _, desc := range res.After {
+		rangeCount += desc.Capacity.RangeCount
+		// The up-replicated ranges are spread over all the stores.
+		require.Greater(t, desc.Capacity.RangeCount, int32(0), "s%d", desc.StoreID)
+	}
+	require.Equal(t, int32(60), rangeCount)
+
+	var added int
+	for _, c := range res.Changes {
+		require.Equal(t, SimulatedSourceReplicateQueue, c.Source)
+		added += len(c.Added)
+	}
+	require.GreaterOrEqual(t, added, 40)
+	require.Equal(t, int64(added)*(64<<20), res.BytesMoved())
+
+	// The snapshot rate limits how quickly the replicas can be added. At the
+	// default rate of 8 MiB/s, a minute is enough to receive 7.5 replicas, and
+	// the 40 replicas are spread over 4 stores.
+	require.Greater(t, int64(res.Elapsed), int64(time.Minute))
+}
+
+func TestSimulateAllocatorDeadNode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	input := makeSimulatedCluster(4, 10, []roachpb.StoreID{1, 2, 3}, 0)
+	input.NodeStatus = map[roachpb.NodeID]livenesspb.NodeLivenessStatus{
+		1: livenesspb.NodeLivenessStatus_DEAD,
+	}
+
+	res, err := SimulateAllocator(ctx, st, input)
+	require.NoError(t, err)
+	require.True(t, res.Converged)
+	require.Equal(t, int32(0), storeDescByID(res.After, 1).Capacity.RangeCount)
+	require.Equal(t, int32(0), storeDescByID(res.After, 1).Capacity.LeaseCount)
+	require.Equal(t, int32(10), storeDescByID(res.After, 4).Capacity.RangeCount)
+	for _, c := range res.Changes {
+		if len(c.Removed) > 0 {
+			require.Equal(t, roachpb.StoreID(1), c.Removed[0].StoreID)
+			require.Equal(t, "range under-replicated", c.Reason)
+		}
+	}
+}
+
+func TestSimulateAllocatorZoneConfigChange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	input := makeSimulatedCluster(5, 10, []roachpb.StoreID{1, 2, 3}, 0)
+	input.Zone = *zonepb.DefaultZoneConfigRef()
+	input.Zone.NumReplicas = func(i int32) *int32 { return &i }(5)
+
+	res, err := SimulateAllocator(ctx, st, input)
+	require.NoError(t, err)
+	require.True(t, res.Converged)
+	for _, desc := range res.After {
+		require.Equal(t, int32(10), desc.Capacity.RangeCount, "s%d", desc.StoreID)
+	}
+}
+
+func TestSimulateAllocatorLoadBasedRebalancing(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	input := makeSimulatedCluster(3, 30, []roachpb.StoreID{1, 2, 3}, 100)
+
+	res, err := SimulateAllocator(ctx, st, input)
+	require.NoError(t, err)
+	require.Equal(t, 3000.0, storeDescByID(res.Before, 1).Capacity.QueriesPerSecond)
+
+	// The leases, and with them the QPS, are spread over the stores.
+	for _, desc := range res.After {
+		require.Greater(t, desc.Capacity.LeaseCount, int32(0), "s%d", desc.StoreID)
+		require.Less(t, desc.Capacity.QueriesPerSecond, 3000.0, "s%d", desc.StoreID)
+	}
+	var transfers int
+	for _, c := range res.Changes {
+		if c.LeaseTarget != 0 {
+			transfers++
+		}
+	}
+	require.Greater(t, transfers, 0)
+}
+
+// TestSimulateAllocatorNonVoters verifies that the non-voting replicas of the
+// ranges count toward the load of their stores and are left alone by the
+// replicate queue, which makes its decisions about the voters.
+func TestSimulateAllocatorNonVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	input := makeSimulatedCluster(5, 10, []roachpb.StoreID{1, 2, 3}, 0)
+	for i := range input.Ranges {
+		input.Ranges[i].Desc.AddReplica(4, 4, roachpb.NON_VOTER)
+	}
+	input.NodeStatus = map[roachpb.NodeID]livenesspb.NodeLivenessStatus{
+		1: livenesspb.NodeLivenessStatus_DEAD,
+	}
+
+	res, err := SimulateAllocator(ctx, st, input)
+	require.NoError(t, err)
+	require.True(t, res.Converged)
+	before := storeDescByID(res.Before, 4)
+	require.Equal(t, int32(10), before.Capacity.RangeCount)
+	require.Equal(t, int64(10*64<<20), before.Capacity.LogicalBytes)
+
+	// The dead voters can't be replaced on s4, which holds a non-voter of every
+	// range, so they're all replaced on s5.
+	require.Equal(t, int32(0), storeDescByID(res.After, 1).Capacity.RangeCount)
+	require.Equal(t, int32(10), storeDescByID(res.After, 4).Capacity.RangeCount)
+	require.Equal(t, int32(10), storeDescByID(res.After, 5).Capacity.RangeCount)
+	for _, c := range res.Changes {
+		for _, repl := range c.Added {
+			require.Equal(t, roachpb.StoreID(5), repl.StoreID)
+			require.Equal(t, roachpb.VOTER_FULL, repl.GetType())
+		}
+		for _, repl := range c.Removed {
+			require.Equal(t, roachpb.StoreID(1), repl.StoreID)
+		}
+	}
+}
+
+func TestSimulateAllocatorInvalidInput(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+
+	input := makeSimulatedCluster(3, 1, []roachpb.StoreID{1, 2, 4}, 0)
+	_, err := SimulateAllocator(ctx, st, input)
+	require.EqualError(t, err, "replica of r1 on unknown store s4")
+
+	input = makeSimulatedCluster(3, 1, []roachpb.StoreID{1, 2, 3}, 0)
+	input.Ranges[0].Leaseholder = 4
+	_, err = SimulateAllocator(ctx, st, input)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "leaseholder s4 of r1 is not one of its voters")
+
+	input = makeSimulatedCluster(3, 1, []roachpb.StoreID{1, 2}, 0)
+	input.Ranges[0].Desc.AddReplica(3, 3, roachpb.NON_VOTER)
+	input.Ranges[0].Leaseholder = 3
+	_, err = SimulateAllocator(ctx, st, input)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "leaseholder s3 of r1 is not one of its voters")
+
+	input = makeSimulatedCluster(3, 1, []roachpb.StoreID{1, 2}, 0)
+	input.Ranges[0].Desc.AddReplica(3, 3, roachpb.LEARNER)
+	_, err = SimulateAllocator(ctx, st, input)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "has unsupported type LEARNER")
+}
@@ -45,6 +45,9 @@ func declareKeysClearRange(
 	// We look up the range descriptor key to check whether the span
 	// is equal to the entire range for fast stats updating.
 	latchSpans.AddNonMVCC(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeDescriptorKey(rs.GetStartKey())})
+	// The MVCC range tombstones over the span are cleared along with the
+	// versions they delete.
+	declareRangeTombstoneKeys(rs, req.Header().Span(), latchSpans)
 }
 
 // ClearRange wipes all MVCC versions of keys covered by the specified
@@ -81,6 +84,10 @@ func ClearRange(
 	}
 	cArgs.Stats.Subtract(statsDelta)
 
+	if err := storage.MVCCClearRangeTombstones(ctx, readWriter, cArgs.Stats, from, to); err != nil {
+		return result.Result{}, err
+	}
+
 	// If the total size of data to be cleared is less than
 	// clearRangeBytesThreshold, clear the individual values manually,
 	// instead of using a range tombstone (inefficient for small ranges).
@@ -140,9 +147,7 @@ func computeStatsDelta(
 	// If we can't use the fast stats path, or race test is enabled,
 	// compute stats across the key span to be cleared.
 	if !fast || util.RaceEnabled {
-		iter := readWriter.NewMVCCIterator(storage.MVCCKeyAndIntentsIterKind, storage.IterOptions{UpperBound: to})
-		computed, err := iter.ComputeStats(from, to, delta.LastUpdateNanos)
-		iter.Close()
+		computed, err := storage.ComputeStats(readWriter, from, to, delta.LastUpdateNanos)
 		if err != nil {
 			return enginepb.MVCCStats{}, err
 		}
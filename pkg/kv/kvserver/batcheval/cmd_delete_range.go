@@ -13,13 +13,20 @@ package batcheval
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
 )
 
+// rangeTombstoneMaxIntents bounds the number of intents returned in the
+// WriteIntentError of a DeleteRange using a range tombstone, so that a span with
+// many intents is resolved in batches.
+const rangeTombstoneMaxIntents = 1000
+
 func init() {
 	RegisterReadWriteCommand(roachpb.DeleteRange, declareKeysDeleteRange, DeleteRange)
 }
@@ -36,6 +43,9 @@ func declareKeysDeleteRange(
 	} else {
 		DefaultDeclareIsolatedKeys(rs, header, req, latchSpans, lockSpans)
 	}
+	if args.UseRangeTombstone {
+		declareRangeTombstoneKeys(rs, args.Span(), latchSpans)
+	}
 }
 
 // DeleteRange deletes the range of key/value pairs specified by
@@ -47,6 +57,21 @@ func DeleteRange(
 	h := cArgs.Header
 	reply := resp.(*roachpb.DeleteRangeResponse)
 
+	if args.UseRangeTombstone && cArgs.EvalCtx.ClusterSettings().Version.IsActive(
+		ctx, clusterversion.MVCCRangeTombstones) {
+		if h.Txn != nil {
+			return result.Result{}, errors.AssertionFailedf(
+				"DeleteRange with range tombstone cannot be transactional")
+		}
+		if args.ReturnKeys || args.Inline || h.MaxSpanRequestKeys != 0 {
+			return result.Result{}, errors.AssertionFailedf(
+				"DeleteRange with range tombstone does not support returning keys, " +
+					"inline values or key limits")
+		}
+		return result.Result{}, storage.MVCCDeleteRangeUsingTombstone(
+			ctx, readWriter, cArgs.Stats, args.Key, args.EndKey, h.Timestamp, rangeTombstoneMaxIntents)
+	}
+
 	var timestamp hlc.Timestamp
 	if !args.Inline {
 		timestamp = h.Timestamp
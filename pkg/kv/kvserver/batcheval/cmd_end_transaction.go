@@ -92,6 +92,7 @@ func declareKeysEndTxn(
 		// be resolved eagerly.
 		for _, span := range et.LockSpans {
 			latchSpans.AddMVCC(spanset.SpanReadWrite, span, minTxnTS)
+			declareReplicatedLockKeys(span, latchSpans)
 		}
 
 		if et.InternalCommitTrigger != nil {
@@ -118,6 +119,12 @@ func declareKeysEndTxn(
 					Key:    keys.MakeRangeKeyPrefix(st.LeftDesc.StartKey),
 					EndKey: keys.MakeRangeKeyPrefix(st.RightDesc.EndKey).PrefixEnd(),
 				})
+				// Splits rewrite the MVCC range tombstone fragment straddling the
+				// split key, which may start anywhere in the LHS.
+				declareRangeTombstoneKeys(rs, roachpb.Span{
+					Key:    st.LeftDesc.StartKey.AsRawKey(),
+					EndKey: st.RightDesc.EndKey.AsRawKey(),
+				}, latchSpans)
 
 				leftRangeIDPrefix := keys.MakeRangeIDReplicatedPrefix(rs.GetRangeID())
 				latchSpans.AddNonMVCC(spanset.SpanReadOnly, roachpb.Span{
@@ -388,19 +395,21 @@ func IsEndTxnExceedingDeadline(t hlc.Timestamp, args *roachpb.EndTxnRequest) boo
 func IsEndTxnTriggeringRetryError(
 	txn *roachpb.Transaction, args *roachpb.EndTxnRequest,
 ) (retry bool, reason roachpb.TransactionRetryReason, extraMsg string) {
-	// If we saw any WriteTooOldErrors, we must restart to avoid lost
-	// update anomalies.
-	if txn.WriteTooOld {
+	switch {
+	case txn.WriteTooOld:
+		// If we saw any WriteTooOldErrors, we must restart to avoid lost
+		// update anomalies. This applies to all isolation levels: a write that
+		// was moved above a newer committed value was based on a read that
+		// didn't observe that value.
 		retry, reason = true, roachpb.RETRY_WRITE_TOO_OLD
-	} else {
-		readTimestamp := txn.ReadTimestamp
-		isTxnPushed := txn.WriteTimestamp != readTimestamp
-
+	case txn.IsoLevel.ToleratesWriteSkew():
+		// Transactions running at an isolation level that tolerates write skew
+		// are allowed to commit with a write timestamp that differs from their
+		// read timestamp.
+	case txn.WriteTimestamp != txn.ReadTimestamp:
 		// Return a transaction retry error if the commit timestamp isn't equal to
 		// the txn timestamp.
-		if isTxnPushed {
-			retry, reason = true, roachpb.RETRY_SERIALIZABLE
-		}
+		retry, reason = true, roachpb.RETRY_SERIALIZABLE
 	}
 
 	// A transaction must obey its deadline, if set.
@@ -869,6 +878,14 @@ func splitTrigger(
 			split.RightDesc.StartKey, split.RightDesc.EndKey, desc)
 	}
 
+	// Split the MVCC range tombstone fragment straddling the split key, so
+	// that each side of the split holds the fragments covering its keys.
+	if err := storage.MVCCSplitRangeTombstones(
+		ctx, batch, &bothDeltaMS, split.RightDesc.StartKey.AsRawKey(),
+	); err != nil {
+		return enginepb.MVCCStats{}, result.Result{}, errors.Wrap(err, "unable to split range tombstones")
+	}
+
 	// Compute the absolute stats for the (post-split) LHS. No more
 	// modifications to it are allowed after this line.
 
@@ -60,12 +60,20 @@ func TestEndTxnUpdatesTransactionRecord(t *testing.T) {
 	restartedAndPushedHeaderTxn := txn.Clone()
 	restartedAndPushedHeaderTxn.Restart(-1, 0, ts2)
 	restartedAndPushedHeaderTxn.WriteTimestamp.Forward(ts3)
+	rcPushedHeaderTxn := txn.Clone()
+	rcPushedHeaderTxn.IsoLevel = enginepb.READ_COMMITTED
+	rcPushedHeaderTxn.WriteTimestamp.Forward(ts2)
 
 	pendingRecord := func() *roachpb.TransactionRecord {
 		record := txn.AsRecord()
 		record.Status = roachpb.PENDING
 		return &record
 	}()
+	rcPendingRecord := func() *roachpb.TransactionRecord {
+		record := *pendingRecord
+		record.IsoLevel = enginepb.READ_COMMITTED
+		return &record
+	}()
 	stagingRecord := func() *roachpb.TransactionRecord {
 		record := txn.AsRecord()
 		record.Status = roachpb.STAGING
@@ -524,6 +532,53 @@ func TestEndTxnUpdatesTransactionRecord(t *testing.T) {
 			// Expected result.
 			expError: "TransactionRetryError: retry txn (RETRY_WRITE_TOO_OLD)",
 		},
+		{
+			// The READ COMMITTED transaction's commit timestamp was increased
+			// during its lifetime, but it hasn't refreshed up to its new commit
+			// timestamp. The commit will succeed.
+			name: "record pending, try commit at pushed timestamp with read committed isolation",
+			// Replica state.
+			existingTxn: rcPendingRecord,
+			// Request state.
+			headerTxn: rcPushedHeaderTxn,
+			commit:    true,
+			// Expected result.
+			expTxn: func() *roachpb.TransactionRecord {
+				record := *committedRecord
+				record.IsoLevel = enginepb.READ_COMMITTED
+				record.WriteTimestamp.Forward(ts2)
+				return &record
+			}(),
+		},
+		{
+			// The READ COMMITTED transaction has run into a WriteTooOld error
+			// during its lifetime. The commit will be rejected.
+			name: "record pending, try commit after write too old with read committed isolation",
+			// Replica state.
+			existingTxn: rcPendingRecord,
+			// Request state.
+			headerTxn: func() *roachpb.Transaction {
+				clone := rcPushedHeaderTxn.Clone()
+				clone.WriteTooOld = true
+				return clone
+			}(),
+			commit: true,
+			// Expected result.
+			expError: "TransactionRetryError: retry txn (RETRY_WRITE_TOO_OLD)",
+		},
+		{
+			// The READ COMMITTED transaction's commit timestamp was pushed past
+			// its deadline. The commit will be rejected.
+			name: "record pending, try commit at pushed timestamp past deadline with read committed isolation",
+			// Replica state.
+			existingTxn: rcPendingRecord,
+			// Request state.
+			headerTxn: rcPushedHeaderTxn,
+			commit:    true,
+			deadline:  &ts,
+			// Expected result.
+			expError: "TransactionRetryError: retry txn (RETRY_COMMIT_DEADLINE_EXCEEDED",
+		},
 		{
 			// Standard case where a transaction is rolled back after it has
 			// written a record at a lower epoch. The existing record is
@@ -0,0 +1,72 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/iterutil"
+)
+
+func init() {
+	RegisterReadOnlyCommand(roachpb.Fingerprint, DefaultDeclareIsolatedKeys, Fingerprint)
+}
+
+// Fingerprint computes a fingerprint of the live MVCC data in the key span it
+// is issued over, as of the batch's timestamp. The fingerprint is the XOR of
+// the hash of each live key and its value, which makes it independent of the
+// order in which keys are visited and of the way in which the key span is
+// split into ranges: the fingerprints of adjacent spans can be combined by
+// XORing them.
+//
+// If the batch has a key limit, at most that many keys are fingerprinted, and
+// the rest of the span is returned as the resume span.
+func Fingerprint(
+	ctx context.Context, reader storage.Reader, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.FingerprintRequest)
+	h := cArgs.Header
+	reply := resp.(*roachpb.FingerprintResponse)
+
+	if h.MaxSpanRequestKeys < 0 {
+		// The limit was exhausted by the preceding requests in the batch.
+		reply.ResumeSpan = &roachpb.Span{Key: args.Key, EndKey: args.EndKey}
+		reply.ResumeReason = roachpb.RESUME_KEY_LIMIT
+		return result.Result{}, nil
+	}
+
+	fp := roachpb.SpanFingerprint{Span: args.Span()}
+	var resumeKey roachpb.Key
+	if _, err := storage.MVCCIterate(
+		ctx, reader, args.Key, args.EndKey, h.Timestamp, storage.MVCCScanOptions{},
+		func(kv roachpb.KeyValue) error {
+			if h.MaxSpanRequestKeys > 0 && fp.KeyCount == h.MaxSpanRequestKeys {
+				resumeKey = kv.Key
+				return iterutil.StopIteration()
+			}
+			fp.Add(kv.Key, kv.Value)
+			return nil
+		},
+	); err != nil {
+		return result.Result{}, err
+	}
+	if resumeKey != nil {
+		fp.Span.EndKey = resumeKey
+		reply.ResumeSpan = &roachpb.Span{Key: resumeKey, EndKey: args.EndKey}
+		reply.ResumeReason = roachpb.RESUME_KEY_LIMIT
+	}
+	reply.NumKeys = fp.KeyCount
+	reply.Fingerprints = []roachpb.SpanFingerprint{fp}
+	return result.Result{}, nil
+}
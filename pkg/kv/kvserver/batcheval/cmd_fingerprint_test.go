@@ -0,0 +1,124 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+
+	makeTS := func(ts int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: ts}
+	}
+	writeValue := func(db storage.Engine, k, v string, ts int64) {
+		require.NoError(t, storage.MVCCPut(
+			ctx, db, nil, roachpb.Key(k), makeTS(ts), roachpb.MakeValueFromString(v), nil,
+		))
+	}
+	fingerprint := func(db storage.Engine, start, end string, ts int64) roachpb.SpanFingerprint {
+		cArgs := CommandArgs{
+			Header: roachpb.Header{Timestamp: makeTS(ts)},
+			Args: &roachpb.FingerprintRequest{
+				RequestHeader: roachpb.RequestHeader{
+					Key:    roachpb.Key(start),
+					EndKey: roachpb.Key(end),
+				},
+			},
+		}
+		var resp roachpb.FingerprintResponse
+		_, err := Fingerprint(ctx, db, cArgs, &resp)
+		require.NoError(t, err)
+		require.Len(t, resp.Fingerprints, 1)
+		require.Equal(t, cArgs.Args.Header().Span(), resp.Fingerprints[0].Span)
+		return resp.Fingerprints[0]
+	}
+
+	// Setup:
+	//
+	//  a: v1 @ 5
+	//  b: v2 @ 10, deleted @ 20
+	//  c: v3 @ 15
+	//
+	db := storage.NewDefaultInMem()
+	defer db.Close()
+	writeValue(db, "a", "v1", 5)
+	writeValue(db, "b", "v2", 10)
+	require.NoError(t, storage.MVCCDelete(ctx, db, nil, roachpb.Key("b"), makeTS(20), nil))
+	writeValue(db, "c", "v3", 15)
+
+	// The same data written at different timestamps, without the deleted key.
+	other := storage.NewDefaultInMem()
+	defer other.Close()
+	writeValue(other, "c", "v3", 1)
+	writeValue(other, "a", "v1", 2)
+
+	require.Equal(t, int64(0), fingerprint(db, "a", "d", 1).KeyCount)
+	require.Equal(t, uint64(0), fingerprint(db, "a", "d", 1).Fingerprint)
+	require.Equal(t, int64(3), fingerprint(db, "a", "d", 15).KeyCount)
+	require.Equal(t, int64(2), fingerprint(db, "a", "d", 20).KeyCount)
+
+	// Fingerprints only depend on the live data.
+	require.NotEqual(t, fingerprint(db, "a", "d", 15).Fingerprint, fingerprint(other, "a", "d", 15).Fingerprint)
+	require.Equal(t, fingerprint(db, "a", "d", 20).Fingerprint, fingerprint(other, "a", "d", 20).Fingerprint)
+
+	// Fingerprints of adjacent spans can be combined.
+	require.Equal(t,
+		fingerprint(db, "a", "d", 20).Fingerprint,
+		fingerprint(db, "a", "b", 20).Fingerprint^fingerprint(db, "b", "d", 20).Fingerprint,
+	)
+
+	// A different value changes the fingerprint.
+	writeValue(other, "a", "v2", 30)
+	require.NotEqual(t, fingerprint(db, "a", "d", 30).Fingerprint, fingerprint(other, "a", "d", 30).Fingerprint)
+
+	// With a key limit, the fingerprint covers the span up to the first key
+	// beyond the limit, and the rest is returned as the resume span.
+	limited := func(limit int64) roachpb.FingerprintResponse {
+		cArgs := CommandArgs{
+			Header: roachpb.Header{Timestamp: makeTS(20), MaxSpanRequestKeys: limit},
+			Args: &roachpb.FingerprintRequest{
+				RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a"), EndKey: roachpb.Key("d")},
+			},
+		}
+		var resp roachpb.FingerprintResponse
+		_, err := Fingerprint(ctx, db, cArgs, &resp)
+		require.NoError(t, err)
+		return resp
+	}
+	resp := limited(1)
+	require.Len(t, resp.Fingerprints, 1)
+	require.Equal(t, fingerprint(db, "a", "c", 20), resp.Fingerprints[0])
+	require.Equal(t, int64(1), resp.NumKeys)
+	require.Equal(t, &roachpb.Span{Key: roachpb.Key("c"), EndKey: roachpb.Key("d")}, resp.ResumeSpan)
+	require.Equal(t, roachpb.RESUME_KEY_LIMIT, resp.ResumeReason)
+
+	resp = limited(2)
+	require.Equal(t, fingerprint(db, "a", "d", 20), resp.Fingerprints[0])
+	require.Nil(t, resp.ResumeSpan)
+
+	// An exhausted limit fingerprints nothing.
+	resp = limited(-1)
+	require.Empty(t, resp.Fingerprints)
+	require.Equal(t, &roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("d")}, resp.ResumeSpan)
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -41,6 +42,9 @@ func declareKeysGC(
 			latchSpans.AddMVCC(spanset.SpanReadWrite, roachpb.Span{Key: key.Key}, header.Timestamp)
 		}
 	}
+	for _, rt := range gcr.RangeTombstones {
+		declareRangeTombstoneKeys(rs, roachpb.Span{Key: rt.StartKey, EndKey: rt.EndKey}, latchSpans)
+	}
 	// Be smart here about blocking on the threshold keys. The GC queue can send an empty
 	// request first to bump the thresholds, and then another one that actually does work
 	// but can avoid declaring these keys below.
@@ -89,6 +93,19 @@ func GC(
 		}
 	}
 
+	// Remove the specified range tombstones, now that the versions they delete
+	// have been garbage collected.
+	for _, rt := range args.RangeTombstones {
+		if !kvserverbase.ContainsKeyRange(cArgs.EvalCtx.Desc(), rt.StartKey, rt.EndKey) {
+			continue
+		}
+		if err := storage.MVCCGarbageCollectRangeTombstones(
+			ctx, readWriter, cArgs.Stats, rt.StartKey, rt.EndKey, rt.Timestamp,
+		); err != nil {
+			return result.Result{}, err
+		}
+	}
+
 	// Optionally bump the GC threshold timestamp.
 	var res result.Result
 	if !args.Threshold.IsEmpty() {
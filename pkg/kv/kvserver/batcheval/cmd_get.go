@@ -21,7 +21,7 @@ import (
 )
 
 func init() {
-	RegisterReadOnlyCommand(roachpb.Get, DefaultDeclareIsolatedKeys, Get)
+	RegisterReadOnlyCommand(roachpb.Get, declareKeysLockingRead, Get)
 }
 
 // Get returns the value for a specified key.
@@ -66,8 +66,27 @@ func Get(
 
 	var res result.Result
 	if args.KeyLocking != lock.None && h.Txn != nil && val != nil {
-		acq := roachpb.MakeLockAcquisition(h.Txn, args.Key, lock.Unreplicated)
-		res.Local.AcquiredLocks = []roachpb.LockAcquisition{acq}
+		if args.KeyLockingReplicated {
+			readWriter, err := replicatedLockWriter(reader)
+			if err != nil {
+				return result.Result{}, err
+			}
+			if err := storage.MVCCAcquireReplicatedLock(
+				ctx, readWriter, cArgs.Stats, h.Txn, args.KeyLocking, args.Key,
+			); err != nil {
+				return result.Result{}, err
+			}
+		} else {
+			// Unreplicated locks are only tracked in the lock table, so check
+			// for conflicts with replicated locks before acquiring them.
+			if err := storage.MVCCCheckReplicatedLocks(
+				ctx, reader, h.Txn, args.KeyLocking, args.Key,
+			); err != nil {
+				return result.Result{}, err
+			}
+			acq := roachpb.MakeLockAcquisition(h.Txn, args.Key, lock.Unreplicated)
+			res.Local.AcquiredLocks = []roachpb.LockAcquisition{acq}
+		}
 	}
 	res.Local.EncounteredIntents = intents
 	return res, err
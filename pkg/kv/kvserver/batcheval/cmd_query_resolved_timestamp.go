@@ -0,0 +1,131 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+func init() {
+	RegisterReadOnlyCommand(roachpb.QueryResolvedTimestamp, DefaultDeclareKeys, QueryResolvedTimestamp)
+}
+
+// QueryResolvedTimestampIntentCleanupAge configures the minimum age of an
+// intent that a QueryResolvedTimestamp request will hand off for asynchronous
+// resolution. Such intents hold back the resolved timestamp of the keys they
+// are on, so abandoned ones need to be cleaned up for the resolved timestamp
+// to make progress.
+var QueryResolvedTimestampIntentCleanupAge = settings.RegisterDurationSetting(
+	"kv.query_resolved_timestamp.intent_cleanup_age",
+	"minimum intent age that QueryResolvedTimestamp requests will consider for async intent cleanup",
+	10*time.Second,
+	settings.NonNegativeDuration,
+)
+
+// queryResolvedTimestampMaxEncounteredIntents is the maximum number of old
+// intents that a single QueryResolvedTimestamp request hands off for
+// asynchronous resolution.
+const queryResolvedTimestampMaxEncounteredIntents = 10
+
+// QueryResolvedTimestamp requests the resolved timestamp of the key span it is
+// issued over. The resolved timestamp is the replica's closed timestamp, held
+// back by the oldest intent in the key span: the replica can serve reads over
+// the key span at or below it without blocking.
+func QueryResolvedTimestamp(
+	ctx context.Context, reader storage.Reader, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.QueryResolvedTimestampRequest)
+	reply := resp.(*roachpb.QueryResolvedTimestampResponse)
+
+	// Grab the closed timestamp before scanning for intents. Any intent below
+	// the closed timestamp was written by a command that applied before the
+	// closed timestamp was observed, so the scan is guaranteed to see it.
+	closedTS := cArgs.EvalCtx.GetClosedTimestamp(ctx)
+
+	st := cArgs.EvalCtx.ClusterSettings()
+	intentCleanupAge := QueryResolvedTimestampIntentCleanupAge.Get(&st.SV)
+	intentCleanupThresh := cArgs.EvalCtx.Clock().Now().Add(-intentCleanupAge.Nanoseconds(), 0)
+	minIntentTS, encounteredIntents, err := computeMinIntentTimestamp(
+		reader, args.Span(), intentCleanupThresh,
+	)
+	if err != nil {
+		return result.Result{}, errors.Wrap(err, "computing minimum intent timestamp")
+	}
+
+	reply.ResolvedTS = closedTS
+	if !minIntentTS.IsEmpty() {
+		reply.ResolvedTS.Backward(minIntentTS.Prev())
+	}
+
+	var res result.Result
+	res.Local.EncounteredIntents = encounteredIntents
+	return res, nil
+}
+
+// computeMinIntentTimestamp scans the given key span for intents and returns
+// the minimum timestamp of any of them, along with up to
+// queryResolvedTimestampMaxEncounteredIntents of the intents that are older
+// than the provided cleanup threshold.
+func computeMinIntentTimestamp(
+	reader storage.Reader, span roachpb.Span, intentCleanupThresh hlc.Timestamp,
+) (hlc.Timestamp, []roachpb.Intent, error) {
+	endKey := span.EndKey
+	if len(endKey) == 0 {
+		endKey = span.Key.Next()
+	}
+	iter := reader.NewMVCCIterator(storage.MVCCKeyAndIntentsIterKind, storage.IterOptions{
+		LowerBound: span.Key,
+		UpperBound: endKey,
+	})
+	defer iter.Close()
+
+	var meta enginepb.MVCCMetadata
+	var minTS hlc.Timestamp
+	var encountered []roachpb.Intent
+	for iter.SeekGE(storage.MakeMVCCMetadataKey(span.Key)); ; iter.NextKey() {
+		if ok, err := iter.Valid(); err != nil {
+			return hlc.Timestamp{}, nil, err
+		} else if !ok {
+			break
+		}
+		key := iter.UnsafeKey()
+		if key.IsValue() {
+			// The key has no intent.
+			continue
+		}
+		if err := protoutil.Unmarshal(iter.UnsafeValue(), &meta); err != nil {
+			return hlc.Timestamp{}, nil, errors.Wrapf(err, "unmarshaling mvcc meta: %v", key)
+		}
+		if meta.Txn == nil {
+			// An inline value.
+			continue
+		}
+		intentTS := meta.Txn.WriteTimestamp
+		if minTS.IsEmpty() || intentTS.Less(minTS) {
+			minTS = intentTS
+		}
+		if intentTS.Less(intentCleanupThresh) &&
+			len(encountered) < queryResolvedTimestampMaxEncounteredIntents {
+			encountered = append(encountered, roachpb.MakeIntent(meta.Txn, append(roachpb.Key(nil), key.Key...)))
+		}
+	}
+	return minTS, encountered, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryResolvedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	db := storage.NewDefaultInMem()
+	defer db.Close()
+
+	makeTS := func(ts int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: ts}
+	}
+	writeValue := func(k string, ts int64) {
+		require.NoError(t, storage.MVCCPut(
+			ctx, db, nil, roachpb.Key(k), makeTS(ts), roachpb.MakeValueFromString("val"), nil,
+		))
+	}
+	writeIntent := func(k string, ts int64) {
+		txn := roachpb.MakeTransaction("test", roachpb.Key(k), 0, makeTS(ts), 0)
+		require.NoError(t, storage.MVCCPut(
+			ctx, db, nil, roachpb.Key(k), makeTS(ts), roachpb.MakeValueFromString("val"), &txn,
+		))
+	}
+
+	// Setup:
+	//
+	//  a: value  @ 5
+	//  b: intent @ 10
+	//  c: value  @ 20
+	//  d: intent @ 30
+	//  e: intent @ 40
+	//
+	writeValue("a", 5)
+	writeIntent("b", 10)
+	writeValue("c", 20)
+	writeIntent("d", 30)
+	writeIntent("e", 40)
+
+	for _, test := range []struct {
+		name                 string
+		span                 [2]string
+		closedTS             hlc.Timestamp
+		intentCleanupAge     time.Duration
+		expResolvedTS        hlc.Timestamp
+		expEncounteredIntent []string
+	}{
+		{
+			name:          "no intents",
+			span:          [2]string{"a", "b"},
+			closedTS:      makeTS(35),
+			expResolvedTS: makeTS(35),
+		},
+		{
+			name:          "no intents, no closed timestamp",
+			span:          [2]string{"a", "b"},
+			closedTS:      hlc.Timestamp{},
+			expResolvedTS: hlc.Timestamp{},
+		},
+		{
+			name:          "intent above closed timestamp",
+			span:          [2]string{"e", "f"},
+			closedTS:      makeTS(35),
+			expResolvedTS: makeTS(35),
+		},
+		{
+			name:          "intent below closed timestamp",
+			span:          [2]string{"c", "f"},
+			closedTS:      makeTS(35),
+			expResolvedTS: makeTS(30).Prev(),
+		},
+		{
+			name:          "multiple intents below closed timestamp",
+			span:          [2]string{"a", "f"},
+			closedTS:      makeTS(35),
+			expResolvedTS: makeTS(10).Prev(),
+		},
+		{
+			name:          "point span",
+			span:          [2]string{"d", ""},
+			closedTS:      makeTS(35),
+			expResolvedTS: makeTS(30).Prev(),
+		},
+		{
+			name:                 "old intents are encountered",
+			span:                 [2]string{"a", "f"},
+			closedTS:             makeTS(35),
+			intentCleanupAge:     time.Duration(15),
+			expResolvedTS:        makeTS(10).Prev(),
+			expEncounteredIntent: []string{"b", "d"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			st := cluster.MakeTestingClusterSettings()
+			cleanupAge := test.intentCleanupAge
+			if cleanupAge == 0 {
+				cleanupAge = time.Hour
+			}
+			QueryResolvedTimestampIntentCleanupAge.Override(&st.SV, cleanupAge)
+			clock := hlc.NewClock(hlc.NewManualClock(50).UnixNano, time.Nanosecond)
+
+			evalCtx := &MockEvalCtx{
+				ClusterSettings: st,
+				Clock:           clock,
+				ClosedTimestamp: test.closedTS,
+			}
+			cArgs := CommandArgs{
+				EvalCtx: evalCtx.EvalContext(),
+				Args: &roachpb.QueryResolvedTimestampRequest{
+					RequestHeader: roachpb.RequestHeader{
+						Key:    roachpb.Key(test.span[0]),
+						EndKey: roachpb.Key(test.span[1]),
+					},
+				},
+			}
+
+			var resp roachpb.QueryResolvedTimestampResponse
+			res, err := QueryResolvedTimestamp(ctx, db, cArgs, &resp)
+			require.NoError(t, err)
+			require.Equal(t, test.expResolvedTS, resp.ResolvedTS)
+
+			var encountered []string
+			for _, intent := range res.Local.EncounteredIntents {
+				encountered = append(encountered, string(intent.Key))
+			}
+			require.Equal(t, test.expEncounteredIntent, encountered)
+		})
+	}
+}
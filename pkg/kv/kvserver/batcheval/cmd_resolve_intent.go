@@ -48,6 +48,11 @@ func declareKeysResolveIntentCombined(
 		// intent, but we can't tell whether we will or not ahead of time.
 		latchSpans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{Key: keys.AbortSpanKey(rs.GetRangeID(), txnID)})
 	}
+	if status.IsFinalized() {
+		// Resolving the locks of a finalized transaction releases its
+		// replicated locks.
+		declareReplicatedLockKeys(req.Header().Span(), latchSpans)
+	}
 }
 
 func declareKeysResolveIntent(
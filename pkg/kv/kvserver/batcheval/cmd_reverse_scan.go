@@ -21,7 +21,7 @@ import (
 )
 
 func init() {
-	RegisterReadOnlyCommand(roachpb.ReverseScan, DefaultDeclareIsolatedKeys, ReverseScan)
+	RegisterReadOnlyCommand(roachpb.ReverseScan, declareKeysLockingRead, ReverseScan)
 }
 
 // ReverseScan scans the key range specified by start key through
@@ -87,7 +87,18 @@ func ReverseScan(
 	}
 
 	if args.KeyLocking != lock.None && h.Txn != nil {
-		err = acquireUnreplicatedLocksOnKeys(&res, h.Txn, args.ScanFormat, &scanRes)
+		if args.KeyLockingReplicated {
+			err = acquireReplicatedLocksOnKeys(
+				ctx, reader, cArgs.Stats, h.Txn, args.KeyLocking, args.ScanFormat, &scanRes)
+		} else {
+			// Unreplicated locks are only tracked in the lock table, so check
+			// for conflicts with replicated locks before acquiring them.
+			err = checkReplicatedLocksOnKeys(
+				ctx, reader, h.Txn, args.KeyLocking, args.ScanFormat, &scanRes)
+			if err == nil {
+				err = acquireUnreplicatedLocksOnKeys(&res, h.Txn, args.ScanFormat, &scanRes)
+			}
+		}
 		if err != nil {
 			return result.Result{}, err
 		}
@@ -38,12 +38,26 @@ func declareKeysRevertRange(
 	// is equal to the entire range for fast stats updating.
 	latchSpans.AddNonMVCC(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeDescriptorKey(rs.GetStartKey())})
 	latchSpans.AddNonMVCC(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeLastGCKey(rs.GetRangeID())})
+	// The MVCC range tombstones in the time range are removed as well.
+	declareRangeTombstoneKeys(rs, req.Header().Span(), latchSpans)
 }
 
 // isEmptyKeyTimeRange checks if the span has no writes in (since,until].
 func isEmptyKeyTimeRange(
-	readWriter storage.ReadWriter, from, to roachpb.Key, since, until hlc.Timestamp,
+	ctx context.Context,
+	readWriter storage.ReadWriter,
+	from, to roachpb.Key,
+	since, until hlc.Timestamp,
 ) (bool, error) {
+	rangeTombstones, err := storage.MVCCScanRangeTombstones(ctx, readWriter, from, to)
+	if err != nil {
+		return false, err
+	}
+	for _, rt := range rangeTombstones {
+		if since.Less(rt.Timestamp) && rt.Timestamp.LessEq(until) {
+			return false, nil
+		}
+	}
 	// Use a TBI to check if there is anything to delete -- the first key Seek hits
 	// may not be in the time range but the fact the TBI found any key indicates
 	// that there is *a* key in the SST that is in the time range. Thus we should
@@ -77,7 +91,7 @@ func RevertRange(
 	var pd result.Result
 
 	if empty, err := isEmptyKeyTimeRange(
-		readWriter, args.Key, args.EndKey, args.TargetTime, cArgs.Header.Timestamp,
+		ctx, readWriter, args.Key, args.EndKey, args.TargetTime, cArgs.Header.Timestamp,
 	); err != nil {
 		return result.Result{}, err
 	} else if empty {
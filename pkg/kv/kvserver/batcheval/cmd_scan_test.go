@@ -15,8 +15,11 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -112,3 +115,72 @@ func testScanReverseScanInner(
 		require.Len(t, rows, expN)
 	}
 }
+
+// TestScanReverseScanReplicatedLocking verifies that locking scans with the
+// Replicated durability acquire replicated locks on the keys they return
+// instead of reporting unreplicated lock acquisitions.
+func TestScanReverseScanReplicatedLocking(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	k1, k2, k3 := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+	ts := hlc.Timestamp{WallTime: 1}
+
+	testutils.RunTrueAndFalse(t, "reverse", func(t *testing.T, reverse bool) {
+		for _, sf := range []roachpb.ScanFormat{roachpb.KEY_VALUES, roachpb.BATCH_RESPONSE} {
+			t.Run(fmt.Sprintf("format=%s", sf), func(t *testing.T) {
+				eng := storage.NewDefaultInMem()
+				defer eng.Close()
+
+				for _, k := range []roachpb.Key{k1, k2} {
+					err := storage.MVCCPut(ctx, eng, nil, k, ts, roachpb.MakeValueFromString("value-"+string(k)), nil)
+					require.NoError(t, err)
+				}
+
+				txn := roachpb.MakeTransaction("test", k1, 0, ts.Next(), 0)
+				var req roachpb.Request
+				var resp roachpb.Response
+				if !reverse {
+					req = &roachpb.ScanRequest{ScanFormat: sf, KeyLocking: lock.Shared, KeyLockingReplicated: true}
+					resp = &roachpb.ScanResponse{}
+				} else {
+					req = &roachpb.ReverseScanRequest{ScanFormat: sf, KeyLocking: lock.Shared, KeyLockingReplicated: true}
+					resp = &roachpb.ReverseScanResponse{}
+				}
+				req.SetHeader(roachpb.RequestHeader{Key: k1, EndKey: roachpb.KeyMax})
+				require.False(t, roachpb.IsReadOnly(req))
+				require.Equal(t, lock.Replicated, roachpb.LockingDurability(req))
+
+				var ms enginepb.MVCCStats
+				cArgs := CommandArgs{
+					Args:   req,
+					Header: roachpb.Header{Timestamp: txn.ReadTimestamp, Txn: &txn},
+					Stats:  &ms,
+				}
+				var res result.Result
+				var err error
+				if !reverse {
+					res, err = Scan(ctx, eng, cArgs, resp)
+				} else {
+					res, err = ReverseScan(ctx, eng, cArgs, resp)
+				}
+				require.NoError(t, err)
+				require.Empty(t, res.Local.AcquiredLocks)
+				require.EqualValues(t, 2, ms.SysCount)
+
+				for _, k := range []roachpb.Key{k1, k2, k3} {
+					locks, err := storage.MVCCGetReplicatedLocks(ctx, eng, k)
+					require.NoError(t, err)
+					if k.Equal(k3) {
+						require.Empty(t, locks)
+						continue
+					}
+					require.Len(t, locks, 1)
+					require.Equal(t, txn.ID, locks[0].Txn.ID)
+					require.Equal(t, lock.Shared, locks[0].Strength)
+				}
+			})
+		}
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/spanset"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -68,6 +69,38 @@ func DefaultDeclareIsolatedKeys(
 	lockSpans.AddNonMVCC(access, req.Header().Span())
 }
 
+// declareKeysLockingRead is DefaultDeclareIsolatedKeys for reads which may
+// acquire locks. Reads which acquire their locks with the Replicated durability
+// additionally declare the replicated lock keys of their span.
+func declareKeysLockingRead(
+	rs ImmutableRangeState,
+	header roachpb.Header,
+	req roachpb.Request,
+	latchSpans, lockSpans *spanset.SpanSet,
+) {
+	DefaultDeclareIsolatedKeys(rs, header, req, latchSpans, lockSpans)
+	if header.Txn != nil && acquiresReplicatedLocks(req) {
+		declareReplicatedLockKeys(req.Header().Span(), latchSpans)
+	}
+}
+
+// acquiresReplicatedLocks returns whether the request is a locking read which
+// acquires its locks with the Replicated durability. Non-locking reads and
+// reads which acquire Unreplicated locks only consult the replicated lock
+// keys, which are protected by the latches on the keys themselves.
+func acquiresReplicatedLocks(req roachpb.Request) bool {
+	switch t := req.(type) {
+	case *roachpb.GetRequest:
+		return t.KeyLocking != lock.None && t.KeyLockingReplicated
+	case *roachpb.ScanRequest:
+		return t.KeyLocking != lock.None && t.KeyLockingReplicated
+	case *roachpb.ReverseScanRequest:
+		return t.KeyLocking != lock.None && t.KeyLockingReplicated
+	default:
+		return false
+	}
+}
+
 // DeclareKeysForBatch adds all keys that the batch with the provided header
 // touches to the given SpanSet. This does not include keys touched during the
 // processing of the batch's individual commands.
@@ -93,3 +126,42 @@ type CommandArgs struct {
 	// *Stats should be mutated to reflect any writes made by the command.
 	Stats *enginepb.MVCCStats
 }
+
+// declareRangeTombstoneKeys declares a write latch over the MVCC range
+// tombstone fragments which a command rewrites when it adds or removes range
+// tombstones in the given span. Since a fragment overlapping the span may
+// start anywhere in the range before it, the latch extends back to the start
+// of the range.
+func declareRangeTombstoneKeys(
+	rs ImmutableRangeState, span roachpb.Span, latchSpans *spanset.SpanSet,
+) {
+	startKey := rs.GetStartKey().AsRawKey()
+	if rs.GetStartKey().Equal(roachpb.RKeyMin) {
+		startKey = keys.LocalMax
+	}
+	endKey := span.EndKey
+	if len(endKey) == 0 {
+		endKey = span.Key.Next()
+	}
+	latchSpans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{
+		Key:    keys.MVCCRangeTombstoneKey(startKey),
+		EndKey: keys.MVCCRangeTombstoneKey(endKey),
+	})
+}
+
+// declareReplicatedLockKeys declares a write latch over the replicated lock
+// keys of the global keys in the given span, which a command acquires or
+// releases replicated locks on.
+func declareReplicatedLockKeys(span roachpb.Span, latchSpans *spanset.SpanSet) {
+	if keys.IsLocal(span.Key) {
+		return
+	}
+	endKey := span.EndKey
+	if len(endKey) == 0 {
+		endKey = span.Key.Next()
+	}
+	latchSpans.AddNonMVCC(spanset.SpanReadWrite, roachpb.Span{
+		Key:    keys.ReplicatedLockKeyPrefix(span.Key),
+		EndKey: keys.ReplicatedLockKeyPrefix(endKey),
+	})
+}
@@ -68,6 +68,10 @@ type EvalContext interface {
 	GetTerm(uint64) (uint64, error)
 	GetLeaseAppliedIndex() uint64
 	GetTracker() closedts.TrackerI
+	// GetClosedTimestamp returns the closed timestamp of the range, as observed
+	// by the evaluating replica, at or below which the replica can serve
+	// consistent reads. It is empty if the replica can't serve follower reads.
+	GetClosedTimestamp(ctx context.Context) hlc.Timestamp
 
 	Desc() *roachpb.RangeDescriptor
 	ContainsKey(key roachpb.Key) bool
@@ -113,6 +117,7 @@ type MockEvalCtx struct {
 	QPS              float64
 	AbortSpan        *abortspan.AbortSpan
 	GCThreshold      hlc.Timestamp
+	ClosedTimestamp  hlc.Timestamp
 	Term, FirstIndex uint64
 	CanCreateTxn     func() (bool, hlc.Timestamp, roachpb.TransactionAbortedReason)
 	Lease            roachpb.Lease
@@ -184,6 +189,9 @@ func (m *mockEvalCtxImpl) GetLeaseAppliedIndex() uint64 {
 func (m *mockEvalCtxImpl) GetTracker() closedts.TrackerI {
 	panic("unimplemented")
 }
+func (m *mockEvalCtxImpl) GetClosedTimestamp(context.Context) hlc.Timestamp {
+	return m.ClosedTimestamp
+}
 func (m *mockEvalCtxImpl) Desc() *roachpb.RangeDescriptor {
 	return m.MockEvalCtx.Desc
 }
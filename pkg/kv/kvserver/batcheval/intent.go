@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
 )
@@ -129,3 +130,74 @@ func acquireUnreplicatedLocksOnKeys(
 		panic("unexpected scanFormat")
 	}
 }
+
+// replicatedLockWriter returns the ReadWriter through which a locking read
+// acquires replicated locks. Get, Scan and ReverseScan are registered as
+// read-only commands, but a locking read which acquires replicated locks is
+// not a read-only request (see roachpb.flagForReplicatedLocking), so it is
+// evaluated on a batch that is written through Raft.
+func replicatedLockWriter(reader storage.Reader) (storage.ReadWriter, error) {
+	readWriter, ok := reader.(storage.ReadWriter)
+	if !ok {
+		return nil, errors.AssertionFailedf(
+			"replicated locking read evaluated on read-only %T", reader)
+	}
+	return readWriter, nil
+}
+
+// acquireReplicatedLocksOnKeys acquires a replicated lock of the given strength
+// for the transaction on each key in the scan result.
+func acquireReplicatedLocksOnKeys(
+	ctx context.Context,
+	reader storage.Reader,
+	ms *enginepb.MVCCStats,
+	txn *roachpb.Transaction,
+	str lock.Strength,
+	scanFmt roachpb.ScanFormat,
+	scanRes *storage.MVCCScanResult,
+) error {
+	readWriter, err := replicatedLockWriter(reader)
+	if err != nil {
+		return err
+	}
+	return forEachKeyInScanResult(scanFmt, scanRes, func(key roachpb.Key) error {
+		return storage.MVCCAcquireReplicatedLock(ctx, readWriter, ms, txn, str, key)
+	})
+}
+
+// checkReplicatedLocksOnKeys returns a WriteIntentError if other transactions
+// hold replicated locks which conflict with a lock of the given strength on
+// any key in the scan result.
+func checkReplicatedLocksOnKeys(
+	ctx context.Context,
+	reader storage.Reader,
+	txn *roachpb.Transaction,
+	str lock.Strength,
+	scanFmt roachpb.ScanFormat,
+	scanRes *storage.MVCCScanResult,
+) error {
+	return forEachKeyInScanResult(scanFmt, scanRes, func(key roachpb.Key) error {
+		return storage.MVCCCheckReplicatedLocks(ctx, reader, txn, str, key)
+	})
+}
+
+// forEachKeyInScanResult calls fn for each key in the scan result.
+func forEachKeyInScanResult(
+	scanFmt roachpb.ScanFormat, scanRes *storage.MVCCScanResult, fn func(roachpb.Key) error,
+) error {
+	switch scanFmt {
+	case roachpb.BATCH_RESPONSE:
+		return storage.MVCCScanDecodeKeyValues(scanRes.KVData, func(key storage.MVCCKey, _ []byte) error {
+			return fn(key.Key)
+		})
+	case roachpb.KEY_VALUES:
+		for _, row := range scanRes.KVs {
+			if err := fn(row.Key); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		panic("unexpected scanFormat")
+	}
+}
@@ -3088,9 +3088,11 @@ func TestStoreRangeMergeRaftSnapshot(t *testing.T) {
 		// TODO(sumeer): fix this test (and others in this file) when
 		// DisallowSeparatedIntents=false
 
-		// The seven to nine SSTs we are expecting to ingest are in the following order:
+		// The nine to eleven SSTs we are expecting to ingest are in the following order:
 		// - Replicated range-id local keys of the range in the snapshot.
 		// - Range-local keys of the range in the snapshot.
+		// - MVCC range tombstone keys of the range in the snapshot.
+		// - Replicated lock keys of the range in the snapshot.
 		// - Optionally, two SSTs for the lock table keys of the range in the
 		//   snapshot
 		// - User keys of the range in the snapshot.
@@ -3101,10 +3103,10 @@ func TestStoreRangeMergeRaftSnapshot(t *testing.T) {
 		//   RangeID 4.
 		// - SST to clear the user keys of the subsumed replicas.
 		//
-		// NOTE: There are no range-local keys or lock table keys, in [d, /Max) in
-		// the store we're sending a snapshot to, so we aren't expecting SSTs to
-		// clear those keys.
-		expectedSSTCount := 7
+		// NOTE: There are no range-local keys, MVCC range tombstone keys,
+		// replicated lock keys or lock table keys, in [d, /Max) in the store we're
+		// sending a snapshot to, so we aren't expecting SSTs to clear those keys.
+		expectedSSTCount := 9
 		indexAdjustment := 0
 		if !storage.DisallowSeparatedIntents {
 			expectedSSTCount += 2
@@ -3120,23 +3122,24 @@ func TestStoreRangeMergeRaftSnapshot(t *testing.T) {
 		// - Clearing rhe range-id local keys of the subsumed replicas.
 		// - Clearing the user keys of the subsumed replicas.
 		// The snapshot SSTs that are excluded from this checking are the
-		// replicated range-id, range-local keys, lock table keys in the snapshot,
-		// and the unreplicated range-id local keys in the snapshot. The latter is
-		// excluded since the state of the Raft log can be non-deterministic with
-		// extra entries being appended to the sender's log after the snapshot has
+		// replicated range-id, range-local keys, MVCC range tombstone keys,
+		// replicated lock keys, lock table keys in the snapshot, and the
+		// unreplicated range-id local keys in the snapshot. The latter is excluded
+		// since the state of the Raft log can be non-deterministic with extra
+		// entries being appended to the sender's log after the snapshot has
 		// already been sent.
 		var sstNamesSubset []string
 		// The SST with the user keys in the snapshot.
-		sstNamesSubset = append(sstNamesSubset, sstNames[2+indexAdjustment])
+		sstNamesSubset = append(sstNamesSubset, sstNames[4+indexAdjustment])
 		// Remaining ones from the predict list above.
-		sstNamesSubset = append(sstNamesSubset, sstNames[4+indexAdjustment:]...)
+		sstNamesSubset = append(sstNamesSubset, sstNames[6+indexAdjustment:]...)
 
 		// Construct the expected SSTs and ensure that they are byte-by-byte
 		// equal. This verification ensures that the SSTs have the same
 		// tombstones and range deletion tombstones.
 		var expectedSSTs [][]byte
 
-		// Construct SSTs for the the first 4 bullets as numbered above, but only
+		// Construct SSTs for the the first 6 bullets as numbered above, but only
 		// ultimately keep the last one.
 		keyRanges := rditer.MakeReplicatedKeyRanges(inSnap.State.Desc)
 		it := rditer.NewReplicaEngineDataIterator(inSnap.State.Desc, sendingEng, true /* replicatedOnly */)
@@ -3170,9 +3173,9 @@ func TestStoreRangeMergeRaftSnapshot(t *testing.T) {
 				}
 			}
 		}
-		if len(expectedSSTs) != 3+indexAdjustment {
+		if len(expectedSSTs) != 5+indexAdjustment {
 			return errors.Errorf("len of expectedSSTs should expected to be %d, but got %d",
-				3+indexAdjustment, len(expectedSSTs))
+				5+indexAdjustment, len(expectedSSTs))
 		}
 		// Keep the last one which contains the user keys.
 		expectedSSTs = expectedSSTs[len(expectedSSTs)-1:]
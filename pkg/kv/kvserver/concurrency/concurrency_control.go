@@ -369,6 +369,17 @@ type Response = []roachpb.ResponseUnion
 // Error is an alias for a roachpb.Error.
 type Error = roachpb.Error
 
+// ContentionEventListener is notified of the ContentionEvents of the requests
+// which waited on conflicting locks, once the duration of each wait is known.
+// Unlike the ContentionEvents recorded on the tracing span of a request, it
+// observes contention regardless of whether the request is traced.
+type ContentionEventListener interface {
+	// OnContentionEvent is called with each ContentionEvent, along with the ID
+	// of the transaction which waited, or the zero UUID if the request which
+	// waited was not transactional.
+	OnContentionEvent(waitingTxnID uuid.UUID, ev roachpb.ContentionEvent)
+}
+
 ///////////////////////////////////
 // Internal Structure Interfaces //
 ///////////////////////////////////
@@ -61,6 +61,8 @@ type Config struct {
 	DisableTxnPushing bool
 	OnContentionEvent func(*roachpb.ContentionEvent) // may be nil; allowed to mutate the event
 	TxnWaitKnobs      txnwait.TestingKnobs
+	// Observability.
+	ContentionEventListener ContentionEventListener // may be nil
 }
 
 func (c *Config) initDefaults() {
@@ -94,6 +96,7 @@ func NewManager(cfg Config) Manager {
 			lt:                lt,
 			disableTxnPushing: cfg.DisableTxnPushing,
 			onContentionEvent: cfg.OnContentionEvent,
+			contentionEvents:  cfg.ContentionEventListener,
 		},
 		// TODO(nvanbenschoten): move pkg/storage/txnwait to a new
 		// pkg/storage/concurrency/txnwait package.
@@ -104,6 +104,8 @@ type lockTableWaiterImpl struct {
 	// When set, called just before each ContentionEvent is emitted.
 	// Is allowed to mutate the event.
 	onContentionEvent func(ev *roachpb.ContentionEvent)
+	// When set, notified of each ContentionEvent once it is emitted.
+	contentionEvents ContentionEventListener
 }
 
 // IntentResolver is an interface used by lockTableWaiterImpl to push
@@ -138,8 +140,12 @@ func (w *lockTableWaiterImpl) WaitOn(
 	var timerWaitingState waitingState
 
 	h := contentionEventHelper{
-		sp:      tracing.SpanFromContext(ctx),
-		onEvent: w.onContentionEvent,
+		sp:       tracing.SpanFromContext(ctx),
+		onEvent:  w.onContentionEvent,
+		listener: w.contentionEvents,
+	}
+	if req.Txn != nil {
+		h.waitingTxnID = req.Txn.ID
 	}
 	defer h.emit()
 
@@ -674,8 +680,11 @@ func (c *txnCache) insertFrontLocked(txn *roachpb.Transaction) {
 
 // contentionEventHelper tracks and emits ContentionEvents.
 type contentionEventHelper struct {
-	sp      *tracing.Span
-	onEvent func(event *roachpb.ContentionEvent) // may be nil
+	sp       *tracing.Span
+	onEvent  func(event *roachpb.ContentionEvent) // may be nil
+	listener ContentionEventListener              // may be nil
+	// waitingTxnID is the ID of the transaction which waits, if any.
+	waitingTxnID uuid.UUID
 
 	// Internal.
 	ev     *roachpb.ContentionEvent
@@ -693,7 +702,12 @@ func (h *contentionEventHelper) emit() {
 		// this interceptor gets to mutate the event (used for test determinism).
 		h.onEvent(h.ev)
 	}
-	h.sp.LogStructured(h.ev)
+	if h.sp != nil {
+		h.sp.LogStructured(h.ev)
+	}
+	if h.listener != nil {
+		h.listener.OnContentionEvent(h.waitingTxnID, *h.ev)
+	}
 	h.ev = nil
 }
 
@@ -702,8 +716,9 @@ func (h *contentionEventHelper) emit() {
 // same event and no action is taken. If they differ, the open event (if any) is
 // finalized and added to the Span, and a new event initialized from the inputs.
 func (h *contentionEventHelper) emitAndInit(s waitingState) {
-	if h.sp == nil {
-		// No span to attach payloads to - don't do any work.
+	if h.sp == nil && h.listener == nil {
+		// No span to attach payloads to nor listener to notify - don't do any
+		// work.
 		//
 		// TODO(tbg): we could special case the noop span here too, but the plan is for
 		// nobody to use noop spans any more (trace.mode=background).
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -696,3 +697,39 @@ func TestContentionEventHelper(t *testing.T) {
 	require.Equal(t, roachpb.Key("a"), sl[0].Key)
 	require.NotZero(t, sl[0].Duration)
 }
+
+type contentionEventRecorder struct {
+	waitingTxnIDs []uuid.UUID
+	events        []roachpb.ContentionEvent
+}
+
+func (r *contentionEventRecorder) OnContentionEvent(
+	waitingTxnID uuid.UUID, ev roachpb.ContentionEvent,
+) {
+	r.waitingTxnIDs = append(r.waitingTxnIDs, waitingTxnID)
+	r.events = append(r.events, ev)
+}
+
+func TestContentionEventHelperListener(t *testing.T) {
+	// The listener is notified of the contention events even if the request is
+	// not traced.
+	var r contentionEventRecorder
+	waiter := makeTxnProto("waiter")
+	h := contentionEventHelper{
+		listener:     &r,
+		waitingTxnID: waiter.ID,
+	}
+	txn := makeTxnProto("foo")
+	h.emitAndInit(waitingState{
+		kind: waitForDistinguished,
+		key:  roachpb.Key("a"),
+		txn:  &txn.TxnMeta,
+	})
+	require.Empty(t, r.events)
+	h.emitAndInit(waitingState{kind: doneWaiting})
+	require.Len(t, r.events, 1)
+	require.Equal(t, []uuid.UUID{waiter.ID}, r.waitingTxnIDs)
+	require.Equal(t, txn.TxnMeta, r.events[0].TxnMeta)
+	require.Equal(t, roachpb.Key("a"), r.events[0].Key)
+	require.NotZero(t, r.events[0].Duration)
+}
@@ -18,6 +18,7 @@ package gc
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -76,6 +77,9 @@ type PureGCer interface {
 type GCer interface {
 	Thresholder
 	PureGCer
+	// GCRangeTombstones removes MVCC range tombstones once the versions they
+	// delete have been garbage collected.
+	GCRangeTombstones(context.Context, []roachpb.GCRequest_GCRangeTombstone) error
 }
 
 // NoopGCer implements GCer by doing nothing.
@@ -89,6 +93,11 @@ func (NoopGCer) SetGCThreshold(context.Context, Threshold) error { return nil }
 // GC implements storage.GCer.
 func (NoopGCer) GC(context.Context, []roachpb.GCRequest_GCKey) error { return nil }
 
+// GCRangeTombstones implements storage.GCer.
+func (NoopGCer) GCRangeTombstones(context.Context, []roachpb.GCRequest_GCRangeTombstone) error {
+	return nil
+}
+
 // Threshold holds the key and txn span GC thresholds, respectively.
 type Threshold struct {
 	Key hlc.Timestamp
@@ -227,6 +236,9 @@ func Run(
 //
 // The logic iterates all versions of all keys in the range from oldest to
 // newest. Expired intents are written into the txnMap and intentKeyMap.
+// Versions deleted by an MVCC range tombstone at or below the threshold are
+// garbage, and such range tombstones are removed once all the garbage has been
+// collected successfully.
 func processReplicatedKeyRange(
 	ctx context.Context,
 	desc *roachpb.RangeDescriptor,
@@ -238,6 +250,14 @@ func processReplicatedKeyRange(
 	intentKeyMap map[uuid.UUID][]roachpb.Key,
 	info *Info,
 ) error {
+	// Read the range tombstones before opening the GC iterator over the
+	// snapshot.
+	rangeTombstones, err := storage.MVCCScanRangeTombstones(
+		ctx, snap, desc.StartKey.AsRawKey(), desc.EndKey.AsRawKey())
+	if err != nil {
+		return err
+	}
+
 	var alloc bufalloc.ByteAllocator
 	// Compute intent expiration (intent age at which we attempt to resolve).
 	intentExp := now.Add(-IntentAgeThreshold.Nanoseconds(), 0)
@@ -289,6 +309,7 @@ func processReplicatedKeyRange(
 		haveGarbageForThisKey bool
 		gcTimestampForThisKey hlc.Timestamp
 		sentBatchForThisKey   bool
+		failedBatch           bool
 	)
 	it := makeGCIterator(desc, snap)
 	defer it.close()
@@ -308,7 +329,8 @@ func processReplicatedKeyRange(
 			continue
 		}
 		isNewest := s.curIsNewest()
-		if isGarbage(threshold, s.cur, s.next, isNewest) {
+		if isGarbage(threshold, s.cur, s.next, isNewest) ||
+			isDeletedByRangeTombstone(threshold, s.cur, rangeTombstones) {
 			keyBytes := int64(s.cur.Key.EncodedSize())
 			batchGCKeysBytes += keyBytes
 			haveGarbageForThisKey = true
@@ -345,6 +367,7 @@ func processReplicatedKeyRange(
 				// thresholds. We may leave some inconsistent history
 				// behind, but nobody can read it.
 				log.Warningf(ctx, "failed to GC a batch of keys: %v", err)
+				failedBatch = true
 			}
 			batchGCKeys = nil
 			batchGCKeysBytes = 0
@@ -356,9 +379,47 @@ func processReplicatedKeyRange(
 			return err
 		}
 	}
+	// The range tombstones can only be removed if all the versions they delete
+	// are gone, since these would otherwise reappear.
+	if failedBatch {
+		return nil
+	}
+	var gcRangeTombstones []roachpb.GCRequest_GCRangeTombstone
+	for _, rt := range rangeTombstones {
+		if rt.Timestamp.LessEq(threshold) {
+			gcRangeTombstones = append(gcRangeTombstones, roachpb.GCRequest_GCRangeTombstone{
+				StartKey:  rt.StartKey,
+				EndKey:    rt.EndKey,
+				Timestamp: rt.Timestamp,
+			})
+		}
+	}
+	if len(gcRangeTombstones) > 0 {
+		if err := gcer.GCRangeTombstones(ctx, gcRangeTombstones); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// isDeletedByRangeTombstone returns whether a version ('cur') was deleted by
+// one of the range tombstones at or below the threshold, which makes it garbage
+// regardless of any newer versions. The range tombstones must be ordered by
+// key, as returned by storage.MVCCScanRangeTombstones.
+func isDeletedByRangeTombstone(
+	threshold hlc.Timestamp, cur *storage.MVCCKeyValue, rangeTombstones []storage.MVCCRangeTombstone,
+) bool {
+	i := sort.Search(len(rangeTombstones), func(i int) bool {
+		return cur.Key.Key.Compare(rangeTombstones[i].EndKey) < 0
+	})
+	for ; i < len(rangeTombstones) && rangeTombstones[i].StartKey.Compare(cur.Key.Key) <= 0; i++ {
+		if ts := rangeTombstones[i].Timestamp; cur.Key.Timestamp.Less(ts) && ts.LessEq(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
 // isGarbage makes a determination whether a key ('cur') is garbage. If 'next'
 // is non-nil, it should be the chronologically newer version of the same key
 // (or the metadata KV if cur is an intent). If isNewest is false, next must be
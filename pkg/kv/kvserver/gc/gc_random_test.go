@@ -181,10 +181,11 @@ func BenchmarkRun(b *testing.B) {
 }
 
 type fakeGCer struct {
-	gcKeys     map[string]roachpb.GCRequest_GCKey
-	threshold  Threshold
-	intents    []roachpb.Intent
-	txnIntents []txnIntents
+	gcKeys            map[string]roachpb.GCRequest_GCKey
+	gcRangeTombstones []roachpb.GCRequest_GCRangeTombstone
+	threshold         Threshold
+	intents           []roachpb.Intent
+	txnIntents        []txnIntents
 }
 
 func makeFakeGCer() fakeGCer {
@@ -207,6 +208,13 @@ func (f *fakeGCer) GC(ctx context.Context, keys []roachpb.GCRequest_GCKey) error
 	return nil
 }
 
+func (f *fakeGCer) GCRangeTombstones(
+	ctx context.Context, rangeTombstones []roachpb.GCRequest_GCRangeTombstone,
+) error {
+	f.gcRangeTombstones = append(f.gcRangeTombstones, rangeTombstones...)
+	return nil
+}
+
 func (f *fakeGCer) resolveIntentsAsync(_ context.Context, txn *roachpb.Transaction) error {
 	f.txnIntents = append(f.txnIntents, txnIntents{txn: txn, intents: txn.LocksAsLockUpdates()})
 	return nil
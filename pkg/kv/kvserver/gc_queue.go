@@ -405,6 +405,17 @@ func (r *replicaGCer) GC(ctx context.Context, keys []roachpb.GCRequest_GCKey) er
 	return r.send(ctx, req)
 }
 
+func (r *replicaGCer) GCRangeTombstones(
+	ctx context.Context, rangeTombstones []roachpb.GCRequest_GCRangeTombstone,
+) error {
+	if len(rangeTombstones) == 0 {
+		return nil
+	}
+	req := r.template()
+	req.RangeTombstones = rangeTombstones
+	return r.send(ctx, req)
+}
+
 // process first determines whether the replica can run GC given its view of
 // the protected timestamp subsystem and its current state. This check also
 // determines the most recent time which can be used for the purposes of updating
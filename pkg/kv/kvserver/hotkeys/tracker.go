@@ -0,0 +1,191 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package hotkeys
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+const (
+	// maxTrackedKeys is the number of keys, and separately of key prefixes,
+	// whose access counts are retained when a Tracker prunes its counts. Up to
+	// twice as many are tracked in between prunings.
+	maxTrackedKeys = 32
+
+	// halfLife is the duration after which the access counts are halved, so
+	// that the counts reflect the recent accesses.
+	halfLife = time.Minute
+
+	// decayInterval is the minimum interval between two applications of the
+	// decay to the access counts.
+	decayInterval = time.Second
+
+	// minCount is the access count below which a key is no longer tracked.
+	minCount = 1
+)
+
+// A KeyQPS is a key, or a key prefix, and the estimated rate at which it is
+// accessed.
+type KeyQPS struct {
+	Key roachpb.Key
+	QPS float64
+}
+
+// A Tracker keeps an approximate count of the accesses to the keys of a
+// Replica which are accessed the most, as well as of the accesses to the SQL
+// indexes they belong to. The latter reveals hotspots spread across many keys
+// which are each accessed rarely, such as sequential insertions at the end of
+// an index.
+//
+// The counts are exponentially decayed, and the number of keys tracked is
+// bounded: when there are too many, only the keys with the highest counts are
+// retained. The keys recorded are typically sampled by the caller, which
+// weighs them accordingly.
+//
+// The zero value is ready to use, and only allocates memory once keys are
+// recorded. This is relevant since many Trackers may exist in the system at
+// any given point in time.
+type Tracker struct {
+	mu struct {
+		syncutil.Mutex
+		lastDecay time.Time
+		keys      map[string]float64
+		prefixes  map[string]float64
+	}
+}
+
+// Record records an access to the given span, with the given weight. The
+// access is recorded against the start key of the span.
+func (t *Tracker) Record(now time.Time, weight float64, span roachpb.Span) {
+	key := span.Key
+	if len(key) == 0 {
+		return
+	}
+	prefix, isSQL := indexPrefix(key)
+	if isSQL && len(span.EndKey) == 0 {
+		// Count accesses to the different column families of a row as accesses
+		// to the row.
+		if rowKey, err := keys.EnsureSafeSplitKey(key); err == nil {
+			key = rowKey
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.keys == nil {
+		t.mu.lastDecay = now
+		t.mu.keys = make(map[string]float64)
+		t.mu.prefixes = make(map[string]float64)
+	}
+	t.maybeDecayLocked(now)
+	t.mu.keys[string(key)] += weight
+	if isSQL {
+		t.mu.prefixes[string(prefix)] += weight
+	}
+	pruneCounts(t.mu.keys)
+	pruneCounts(t.mu.prefixes)
+}
+
+// HottestKeys returns the tracked keys and key prefixes, along with the
+// estimated rate at which they are accessed, sorted by decreasing rate.
+func (t *Tracker) HottestKeys(now time.Time) (hotKeys, hotPrefixes []KeyQPS) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mu.keys == nil {
+		return nil, nil
+	}
+	t.maybeDecayLocked(now)
+	return countsToQPS(t.mu.keys), countsToQPS(t.mu.prefixes)
+}
+
+func (t *Tracker) maybeDecayLocked(now time.Time) {
+	elapsed := now.Sub(t.mu.lastDecay)
+	if elapsed < decayInterval {
+		return
+	}
+	t.mu.lastDecay = now
+	factor := math.Exp2(-float64(elapsed) / float64(halfLife))
+	for _, counts := range []map[string]float64{t.mu.keys, t.mu.prefixes} {
+		for k, count := range counts {
+			if count *= factor; count < minCount {
+				delete(counts, k)
+			} else {
+				counts[k] = count
+			}
+		}
+	}
+}
+
+// countsToQPS converts decayed access counts to access rates. A key accessed
+// at a steady rate r has a count of r*halfLife/ln(2).
+func countsToQPS(counts map[string]float64) []KeyQPS {
+	res := make([]KeyQPS, 0, len(counts))
+	for k, count := range counts {
+		res = append(res, KeyQPS{
+			Key: roachpb.Key(k),
+			QPS: count * math.Ln2 / halfLife.Seconds(),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].QPS > res[j].QPS
+	})
+	return res
+}
+
+// pruneCounts retains the maxTrackedKeys highest counts if more than twice as
+// many keys are tracked.
+func pruneCounts(counts map[string]float64) {
+	if len(counts) <= 2*maxTrackedKeys {
+		return
+	}
+	byCount := make(keyCounts, 0, len(counts))
+	for k, count := range counts {
+		byCount = append(byCount, keyCount{key: k, count: count})
+	}
+	util.MoveTopKToFront(byCount, maxTrackedKeys)
+	for _, kc := range byCount[maxTrackedKeys:] {
+		delete(counts, kc.key)
+	}
+}
+
+// indexPrefix returns the prefix of the SQL index the given key belongs to,
+// or false if it is not a SQL key.
+func indexPrefix(key roachpb.Key) (roachpb.Key, bool) {
+	_, tenID, err := keys.DecodeTenantPrefix(key)
+	if err != nil {
+		return nil, false
+	}
+	codec := keys.MakeSQLCodec(tenID)
+	_, tableID, indexID, err := codec.DecodeIndexPrefix(key)
+	if err != nil {
+		return nil, false
+	}
+	return codec.IndexPrefix(tableID, indexID), true
+}
+
+type keyCount struct {
+	key   string
+	count float64
+}
+
+// keyCounts sorts keys by decreasing count.
+type keyCounts []keyCount
+
+func (kc keyCounts) Len() int           { return len(kc) }
+func (kc keyCounts) Less(i, j int) bool { return kc[i].count > kc[j].count }
+func (kc keyCounts) Swap(i, j int)      { kc[i], kc[j] = kc[j], kc[i] }
@@ -0,0 +1,91 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package hotkeys
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	codec := keys.SystemSQLCodec
+	primary := codec.IndexPrefix(53, 1)
+	secondary := codec.IndexPrefix(53, 2)
+	// The row key of a hot row, and the key of one of its column families.
+	hotRow := roachpb.Key(encoding.EncodeVarintAscending(primary[:len(primary):len(primary)], 7))
+	hotFamily := keys.MakeFamilyKey(hotRow[:len(hotRow):len(hotRow)], 1)
+	// A hot non-SQL key.
+	metaKey := keys.RangeMetaKey(roachpb.RKey("a")).AsRawKey()
+
+	start := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	var tr Tracker
+	// For ten minutes, record:
+	// - 100 accesses per second to a column family of the hot row,
+	// - 50 sequential insertions per second into the secondary index, each of
+	//   a different key,
+	// - 20 accesses per second to the meta key,
+	// as one every 10ms, sampled at a rate of 10%.
+	var now time.Time
+	for i := 0; i < 10*60*100; i++ {
+		now = start.Add(time.Duration(i) * 10 * time.Millisecond)
+		if i%10 == 0 {
+			tr.Record(now, 10, roachpb.Span{Key: hotFamily})
+		}
+		if i%20 == 0 {
+			key := append(secondary[:len(secondary):len(secondary)], byte(i), byte(i>>8), byte(i>>16))
+			tr.Record(now, 10, roachpb.Span{Key: key})
+		}
+		if i%50 == 0 {
+			tr.Record(now, 10, roachpb.Span{Key: metaKey, EndKey: metaKey.Next()})
+		}
+	}
+
+	hotKeys, hotPrefixes := tr.HottestKeys(now)
+	require.LessOrEqual(t, len(hotKeys), 2*maxTrackedKeys)
+	require.Equal(t, hotRow, hotKeys[0].Key)
+	require.InEpsilon(t, 100, hotKeys[0].QPS, 0.05)
+	require.Equal(t, metaKey, hotKeys[1].Key)
+	require.InEpsilon(t, 20, hotKeys[1].QPS, 0.05)
+	// None of the sequentially inserted keys is hot on its own...
+	for _, k := range hotKeys[2:] {
+		require.Less(t, k.QPS, 1.0)
+	}
+	// ... but the secondary index is.
+	require.Len(t, hotPrefixes, 2)
+	require.Equal(t, primary, hotPrefixes[0].Key)
+	require.InEpsilon(t, 100, hotPrefixes[0].QPS, 0.05)
+	require.Equal(t, secondary, hotPrefixes[1].Key)
+	require.InEpsilon(t, 50, hotPrefixes[1].QPS, 0.05)
+
+	// Keys which are no longer accessed are eventually forgotten.
+	hotKeys, hotPrefixes = tr.HottestKeys(now.Add(time.Hour))
+	require.Empty(t, hotKeys)
+	require.Empty(t, hotPrefixes)
+}
+
+func TestTrackerZeroValue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var tr Tracker
+	hotKeys, hotPrefixes := tr.HottestKeys(time.Now())
+	require.Empty(t, hotKeys)
+	require.Empty(t, hotPrefixes)
+	tr.Record(time.Now(), 1, roachpb.Span{})
+	require.Nil(t, tr.mu.keys)
+}
@@ -272,10 +272,11 @@ func (mq *mergeQueue) process(
 	}
 	leftRepls, rightRepls := lhsDesc.Replicas().Descriptors(), rhsDesc.Replicas().Descriptors()
 
-	// Defensive sanity check that the ranges involved only have either VOTER_FULL
-	// and NON_VOTER replicas.
+	// Defensive sanity check that the ranges involved only have either VOTER_FULL,
+	// NON_VOTER and WITNESS replicas.
 	for i := range leftRepls {
-		if typ := leftRepls[i].GetType(); !(typ == roachpb.VOTER_FULL || typ == roachpb.NON_VOTER) {
+		if typ := leftRepls[i].GetType(); !(typ == roachpb.VOTER_FULL || typ == roachpb.NON_VOTER ||
+			typ == roachpb.WITNESS) {
 			return false,
 				errors.AssertionFailedf(
 					`cannot merge because lhs is either in a joint state or has learner replicas: %v`,
@@ -284,7 +285,8 @@ func (mq *mergeQueue) process(
 		}
 	}
 	for i := range rightRepls {
-		if typ := rightRepls[i].GetType(); !(typ == roachpb.VOTER_FULL || typ == roachpb.NON_VOTER) {
+		if typ := rightRepls[i].GetType(); !(typ == roachpb.VOTER_FULL || typ == roachpb.NON_VOTER ||
+			typ == roachpb.WITNESS) {
 			return false,
 				errors.AssertionFailedf(
 					`cannot merge because rhs is either in a joint state or has learner replicas: %v`,
@@ -293,6 +295,15 @@ func (mq *mergeQueue) process(
 		}
 	}
 
+	// A witness only holds the range-local state of its own range, so merging
+	// it with a replica holding data (or vice versa) would leave the merged
+	// replica with a partial copy of the data. AdminRelocateRange doesn't move
+	// witnesses around, so skip the merge unless they're already collocated.
+	if !replicasCollocated(lhsDesc.Replicas().WitnessDescriptors(), rhsDesc.Replicas().WitnessDescriptors()) {
+		log.VEventf(ctx, 2, "skipping merge: witnesses of %s and %s are not collocated", lhsDesc, rhsDesc)
+		return false, nil
+	}
+
 	// Range merges require that the set of stores that contain a replica for the
 	// RHS range be equal to the set of stores that contain a replica for the LHS
 	// range. The LHS and RHS ranges' leaseholders do not need to be co-located
@@ -110,6 +110,19 @@ func (rq *raftSnapshotQueue) processRaftSnapshot(
 	}
 	snapType := SnapshotRequest_VIA_SNAPSHOT_QUEUE
 
+	repl.mu.RLock()
+	isWitness := repl.isWitnessRLocked()
+	repl.mu.RUnlock()
+	if isWitness && repDesc.GetType() != roachpb.WITNESS {
+		// Witnesses don't hold the range's user data, so they can only send
+		// snapshots to other witnesses. A witness only leads the range until a
+		// data voter has caught up from its log, and the data voter then sends
+		// the snapshot instead.
+		err := errors.Errorf("%s: witness cannot send snapshot to %s", repl, repDesc)
+		repl.reportSnapshotStatus(ctx, repDesc.ReplicaID, err)
+		return err
+	}
+
 	if repDesc.GetType() == roachpb.LEARNER {
 		if fn := repl.store.cfg.TestingKnobs.ReplicaSkipLearnerSnapshot; fn != nil && fn() {
 			return nil
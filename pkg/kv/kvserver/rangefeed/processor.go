@@ -181,6 +181,13 @@ func NewProcessor(cfg Config) *Processor {
 // from underneath a stopper task to ensure that the engine has not been closed.
 type IteratorConstructor func() storage.SimpleMVCCIterator
 
+// RangeTombstoneScanner is optionally implemented by catch-up iterators to
+// provide the MVCC range tombstones in a span, which are not visible to the
+// iterator itself.
+type RangeTombstoneScanner interface {
+	ScanRangeTombstones(ctx context.Context, span roachpb.Span) ([]storage.MVCCRangeTombstone, error)
+}
+
 // Start launches a goroutine to process rangefeed events and send them to
 // registrations.
 //
@@ -582,6 +589,10 @@ func (p *Processor) consumeLogicalOps(ctx context.Context, ops []enginepb.MVCCLo
 		case *enginepb.MVCCAbortTxnOp:
 			// No updates to publish.
 
+		case *enginepb.MVCCDeleteRangeOp:
+			// Publish the range deletion directly.
+			p.publishDeleteRange(ctx, t.StartKey, t.EndKey, t.Timestamp)
+
 		default:
 			panic(errors.AssertionFailedf("unknown logical op %T", t))
 		}
@@ -629,6 +640,22 @@ func (p *Processor) publishValue(
 	p.reg.PublishToOverlapping(roachpb.Span{Key: key}, &event)
 }
 
+func (p *Processor) publishDeleteRange(
+	ctx context.Context, startKey, endKey roachpb.Key, timestamp hlc.Timestamp,
+) {
+	span := roachpb.Span{Key: startKey, EndKey: endKey}
+	if !p.Span.ContainsKeyRange(roachpb.RKey(startKey), roachpb.RKey(endKey)) {
+		log.Fatalf(ctx, "span %v not in Processor's key range %v", span, p.Span)
+	}
+
+	var event roachpb.RangeFeedEvent
+	event.MustSetValue(&roachpb.RangeFeedDeleteRange{
+		Span:      span,
+		Timestamp: timestamp,
+	})
+	p.reg.PublishToOverlapping(span, &event)
+}
+
 func (p *Processor) publishCheckpoint(ctx context.Context) {
 	// TODO(nvanbenschoten): persist resolvedTimestamp. Give Processor a client.DB.
 	// TODO(nvanbenschoten): rate limit these? send them periodically?
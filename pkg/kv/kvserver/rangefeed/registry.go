@@ -150,6 +150,13 @@ func (r *registration) validateEvent(event *roachpb.RangeFeedEvent) {
 		if t.Span.Key == nil {
 			panic(fmt.Sprintf("unexpected empty RangeFeedCheckpoint.Span.Key: %v", t))
 		}
+	case *roachpb.RangeFeedDeleteRange:
+		if t.Span.Key == nil || t.Span.EndKey == nil {
+			panic(fmt.Sprintf("unexpected empty RangeFeedDeleteRange.Span: %v", t))
+		}
+		if t.Timestamp.IsEmpty() {
+			panic(fmt.Sprintf("unexpected empty RangeFeedDeleteRange.Timestamp: %v", t))
+		}
 	default:
 		panic(fmt.Sprintf("unexpected RangeFeedEvent variant: %v", t))
 	}
@@ -196,6 +203,18 @@ func (r *registration) maybeStripEvent(event *roachpb.RangeFeedEvent) *roachpb.R
 			t = copyOnWrite().(*roachpb.RangeFeedCheckpoint)
 			t.Span = r.span
 		}
+	case *roachpb.RangeFeedDeleteRange:
+		if !r.span.Contains(t.Span) {
+			// Range deletions may extend beyond the span of a registration
+			// overlapping them. Constrain them to the span it's listening on.
+			t = copyOnWrite().(*roachpb.RangeFeedDeleteRange)
+			if t.Span.Key.Compare(r.span.Key) < 0 {
+				t.Span.Key = r.span.Key
+			}
+			if r.span.EndKey.Compare(t.Span.EndKey) < 0 {
+				t.Span.EndKey = r.span.EndKey
+			}
+		}
 	default:
 		panic(fmt.Sprintf("unexpected RangeFeedEvent variant: %v", t))
 	}
@@ -232,7 +251,7 @@ func (r *registration) disconnect(pErr *roachpb.Error) {
 // have been emitted.
 func (r *registration) outputLoop(ctx context.Context) error {
 	// If the registration has a catch-up scan, run it.
-	if err := r.maybeRunCatchupScan(); err != nil {
+	if err := r.maybeRunCatchupScan(ctx); err != nil {
 		err = errors.Wrap(err, "catch-up scan failed")
 		log.Errorf(ctx, "%v", err)
 		return err
@@ -279,7 +298,7 @@ func (r *registration) runOutputLoop(ctx context.Context) {
 //
 // If the registration does not have a catchUpIteratorConstructor, this method
 // is a no-op.
-func (r *registration) maybeRunCatchupScan() error {
+func (r *registration) maybeRunCatchupScan(ctx context.Context) error {
 	if r.catchupIterConstructor == nil {
 		return nil
 	}
@@ -408,7 +427,33 @@ func (r *registration) maybeRunCatchupScan() error {
 	}
 
 	// Output events for the last key encountered.
-	return outputEvents()
+	if err := outputEvents(); err != nil {
+		return err
+	}
+
+	// Output the range deletions after the timestamp. These are not ordered
+	// with respect to the values of the keys they delete, which consumers need
+	// to order by timestamp.
+	if scanner, ok := catchupIter.(RangeTombstoneScanner); ok {
+		rangeTombstones, err := scanner.ScanRangeTombstones(ctx, r.span)
+		if err != nil {
+			return err
+		}
+		for _, rt := range rangeTombstones {
+			if !r.catchupTimestamp.Less(rt.Timestamp) {
+				continue
+			}
+			var event roachpb.RangeFeedEvent
+			event.MustSetValue(&roachpb.RangeFeedDeleteRange{
+				Span:      roachpb.Span{Key: rt.StartKey, EndKey: rt.EndKey},
+				Timestamp: rt.Timestamp,
+			})
+			if err := r.stream.Send(&event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // ID implements interval.Interface.
@@ -480,6 +525,10 @@ func (reg *registry) PublishToOverlapping(span roachpb.Span, event *roachpb.Rang
 		// TODO(dan): It's unclear if this is the right contract, it's certainly
 		// surprising. Revisit this once RangeFeed has more users.
 		minTS = hlc.MaxTimestamp
+	case *roachpb.RangeFeedDeleteRange:
+		// Only publish range deletions to registrations with starting
+		// timestamps equal to or greater than the deletion's timestamp.
+		minTS = t.Timestamp
 	default:
 		panic(fmt.Sprintf("unexpected RangeFeedEvent variant: %v", t))
 	}
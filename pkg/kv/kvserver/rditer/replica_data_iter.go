@@ -73,8 +73,10 @@ func MakeAllKeyRanges(d *roachpb.RangeDescriptor) []KeyRange {
 //
 // 1. Replicated range-id local key range
 // 2. Range-local key range
-// 3. Lock-table key ranges (optional)
-// 4. User key range
+// 3. MVCC range tombstone key range
+// 4. Replicated lock key range
+// 5. Lock-table key ranges (optional)
+// 6. User key range
 func MakeReplicatedKeyRanges(d *roachpb.RangeDescriptor) []KeyRange {
 	return makeRangeKeyRanges(d, true /* replicatedOnly */)
 }
@@ -82,19 +84,25 @@ func MakeReplicatedKeyRanges(d *roachpb.RangeDescriptor) []KeyRange {
 func makeRangeKeyRanges(d *roachpb.RangeDescriptor, replicatedOnly bool) []KeyRange {
 	rangeIDLocal := MakeRangeIDLocalKeyRange(d.RangeID, replicatedOnly)
 	rangeLocal := makeRangeLocalKeyRange(d)
+	rangeTombstones := makeRangeTombstoneKeyRange(d)
+	replicatedLocks := makeReplicatedLockKeyRange(d)
 	user := MakeUserKeyRange(d)
 	if storage.DisallowSeparatedIntents {
 		return []KeyRange{
 			rangeIDLocal,
 			rangeLocal,
+			rangeTombstones,
+			replicatedLocks,
 			user,
 		}
 	}
 	rangeLockTable := makeRangeLockTableKeyRanges(d)
-	ranges := make([]KeyRange, 3+len(rangeLockTable))
+	ranges := make([]KeyRange, 5+len(rangeLockTable))
 	ranges[0] = rangeIDLocal
 	ranges[1] = rangeLocal
-	i := 2
+	ranges[2] = rangeTombstones
+	ranges[3] = replicatedLocks
+	i := 4
 	for j := range rangeLockTable {
 		ranges[i] = rangeLockTable[j]
 		i++
@@ -108,11 +116,15 @@ func makeRangeKeyRanges(d *roachpb.RangeDescriptor, replicatedOnly bool) []KeyRa
 // returned in the following sorted order:
 // 1. Replicated range-id local key range
 // 2. Range-local key range
-// 3. User key range
+// 3. MVCC range tombstone key range
+// 4. Replicated lock key range
+// 5. User key range
 func MakeReplicatedKeyRangesExceptLockTable(d *roachpb.RangeDescriptor) []KeyRange {
 	return []KeyRange{
 		MakeRangeIDLocalKeyRange(d.RangeID, true /* replicatedOnly */),
 		makeRangeLocalKeyRange(d),
+		makeRangeTombstoneKeyRange(d),
+		makeReplicatedLockKeyRange(d),
 		MakeUserKeyRange(d),
 	}
 }
@@ -121,21 +133,29 @@ func MakeReplicatedKeyRangesExceptLockTable(d *roachpb.RangeDescriptor) []KeyRan
 // replicated for the given Range, except for the replicated range-id local key range.
 // These are returned in the following sorted order:
 // 1. Range-local key range
-// 2. Lock-table key ranges (optional)
-// 3. User key range
+// 2. MVCC range tombstone key range
+// 3. Replicated lock key range
+// 4. Lock-table key ranges (optional)
+// 5. User key range
 func MakeReplicatedKeyRangesExceptRangeID(d *roachpb.RangeDescriptor) []KeyRange {
 	rangeLocal := makeRangeLocalKeyRange(d)
+	rangeTombstones := makeRangeTombstoneKeyRange(d)
+	replicatedLocks := makeReplicatedLockKeyRange(d)
 	user := MakeUserKeyRange(d)
 	if storage.DisallowSeparatedIntents {
 		return []KeyRange{
 			rangeLocal,
+			rangeTombstones,
+			replicatedLocks,
 			user,
 		}
 	}
 	rangeLockTable := makeRangeLockTableKeyRanges(d)
-	ranges := make([]KeyRange, 2+len(rangeLockTable))
+	ranges := make([]KeyRange, 4+len(rangeLockTable))
 	ranges[0] = rangeLocal
-	i := 1
+	ranges[1] = rangeTombstones
+	ranges[2] = replicatedLocks
+	i := 3
 	for j := range rangeLockTable {
 		ranges[i] = rangeLockTable[j]
 		i++
@@ -144,6 +164,23 @@ func MakeReplicatedKeyRangesExceptRangeID(d *roachpb.RangeDescriptor) []KeyRange
 	return ranges
 }
 
+// MakeWitnessKeyRanges returns the subset of the fully Raft replicated key
+// ranges of the given Range that are held by witness replicas. A witness
+// doesn't hold any of the range's user data, so these are, in sorted order:
+// 1. Replicated range-id local key range
+// 2. Range-local key range
+// 3. Range-local lock-table key range (optional)
+func MakeWitnessKeyRanges(d *roachpb.RangeDescriptor) []KeyRange {
+	ranges := []KeyRange{
+		MakeRangeIDLocalKeyRange(d.RangeID, true /* replicatedOnly */),
+		makeRangeLocalKeyRange(d),
+	}
+	if !storage.DisallowSeparatedIntents {
+		ranges = append(ranges, makeRangeLockTableKeyRanges(d)[0])
+	}
+	return ranges
+}
+
 // MakeRangeIDLocalKeyRange returns the range-id local key range. If
 // replicatedOnly is true, then it returns only the replicated keys, otherwise,
 // it only returns both the replicated and unreplicated keys.
@@ -172,6 +209,33 @@ func makeRangeLocalKeyRange(d *roachpb.RangeDescriptor) KeyRange {
 	}
 }
 
+// makeRangeTombstoneKeyRange returns the key range holding the fragments of the
+// MVCC range tombstones which cover the range's user keys.
+func makeRangeTombstoneKeyRange(d *roachpb.RangeDescriptor) KeyRange {
+	// As with the lock table, the first range's data starts at LocalMax.
+	startKey := d.StartKey.AsRawKey()
+	if d.StartKey.Equal(roachpb.RKeyMin) {
+		startKey = keys.LocalMax
+	}
+	return KeyRange{
+		Start: storage.MakeMVCCMetadataKey(keys.MVCCRangeTombstoneKey(startKey)),
+		End:   storage.MakeMVCCMetadataKey(keys.MVCCRangeTombstoneKey(roachpb.Key(d.EndKey))),
+	}
+}
+
+// makeReplicatedLockKeyRange returns the key range holding the replicated locks
+// on the range's user keys.
+func makeReplicatedLockKeyRange(d *roachpb.RangeDescriptor) KeyRange {
+	startKey := d.StartKey.AsRawKey()
+	if d.StartKey.Equal(roachpb.RKeyMin) {
+		startKey = keys.LocalMax
+	}
+	return KeyRange{
+		Start: storage.MakeMVCCMetadataKey(keys.ReplicatedLockKeyPrefix(startKey)),
+		End:   storage.MakeMVCCMetadataKey(keys.ReplicatedLockKeyPrefix(roachpb.Key(d.EndKey))),
+	}
+}
+
 // makeRangeLockTableKeyRanges returns the 2 lock table key ranges.
 func makeRangeLockTableKeyRanges(d *roachpb.RangeDescriptor) [2]KeyRange {
 	// Handle doubly-local lock table keys since range descriptor key
@@ -372,6 +436,21 @@ func NewReplicaEngineDataIterator(
 	return ri
 }
 
+// NewWitnessEngineDataIterator creates a ReplicaEngineDataIterator over the
+// key ranges held by a witness replica of the given range. See
+// MakeWitnessKeyRanges.
+func NewWitnessEngineDataIterator(
+	d *roachpb.RangeDescriptor, reader storage.Reader,
+) *ReplicaEngineDataIterator {
+	it := reader.NewEngineIterator(storage.IterOptions{UpperBound: d.EndKey.AsRawKey()})
+	ri := &ReplicaEngineDataIterator{
+		ranges: MakeWitnessKeyRanges(d),
+		it:     it,
+	}
+	ri.seekStart()
+	return ri
+}
+
 // seekStart seeks the iterator to the start of its data range.
 func (ri *ReplicaEngineDataIterator) seekStart() {
 	ri.curIndex = 0
@@ -23,22 +23,14 @@ func ComputeStatsForRange(
 	d *roachpb.RangeDescriptor, reader storage.Reader, nowNanos int64,
 ) (enginepb.MVCCStats, error) {
 	ms := enginepb.MVCCStats{}
-	var err error
 	for _, keyRange := range MakeReplicatedKeyRangesExceptLockTable(d) {
-		func() {
-			iter := reader.NewMVCCIterator(storage.MVCCKeyAndIntentsIterKind,
-				storage.IterOptions{UpperBound: keyRange.End.Key})
-			defer iter.Close()
-
-			var msDelta enginepb.MVCCStats
-			if msDelta, err = iter.ComputeStats(keyRange.Start.Key, keyRange.End.Key, nowNanos); err != nil {
-				return
-			}
-			ms.Add(msDelta)
-		}()
+		// storage.ComputeStats accounts for the versions deleted by MVCC range
+		// tombstones.
+		msDelta, err := storage.ComputeStats(reader, keyRange.Start.Key, keyRange.End.Key, nowNanos)
 		if err != nil {
 			return enginepb.MVCCStats{}, err
 		}
+		ms.Add(msDelta)
 	}
 	return ms, nil
 }
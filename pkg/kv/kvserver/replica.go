@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/ctpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/gc"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/hotkeys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rangefeed"
@@ -220,6 +221,13 @@ type Replica struct {
 	// writeStats tracks the number of keys written by applied raft commands
 	// in order to aid in replica rebalancing decisions.
 	writeStats *replicaStats
+	// evalStats tracks the wall time, in nanoseconds, spent evaluating
+	// BatchRequests on the replica in order to aid in lease and replica
+	// rebalancing decisions. See EvalNanosPerSecond.
+	evalStats *replicaStats
+	// writeBytesStats tracks the number of bytes written by applied raft
+	// commands in order to aid in replica rebalancing decisions.
+	writeBytesStats *replicaStats
 
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
@@ -333,6 +341,10 @@ type Replica struct {
 		// log was checked for truncation or at the time of the last Raft log
 		// truncation.
 		raftLogLastCheckSize int64
+		// witnessTruncationIndex is, on witness replicas, the highest index up
+		// to which the leader allowed the raft log to be truncated. See
+		// RaftMessageRequest.WitnessTruncationIndex.
+		witnessTruncationIndex uint64
 		// pendingLeaseRequest is used to coalesce RequestLease requests.
 		pendingLeaseRequest pendingLeaseRequest
 		// minLeaseProposedTS is the minimum acceptable lease.ProposedTS; only
@@ -580,6 +592,10 @@ type Replica struct {
 	// loadBasedSplitter keeps information about load-based splitting.
 	loadBasedSplitter split.Decider
 
+	// hotKeys keeps track of the keys of the replica which are accessed the
+	// most.
+	hotKeys hotkeys.Tracker
+
 	unreachablesMu struct {
 		syncutil.Mutex
 		remotes map[roachpb.ReplicaID]struct{}
@@ -943,6 +959,12 @@ func (r *Replica) GetMVCCStats() enginepb.MVCCStats {
 	return *r.mu.state.Stats
 }
 
+// getLeaseholderStats returns the statistics of the requests received by the
+// replica while it held the lease.
+func (r *Replica) getLeaseholderStats() *replicaStats {
+	return r.leaseholderStats
+}
+
 // GetSplitQPS returns the Replica's queries/s request rate.
 //
 // NOTE: This should only be used for load based splitting, only
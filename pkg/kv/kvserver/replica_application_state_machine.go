@@ -386,11 +386,16 @@ type replicaAppBatch struct {
 	// changeRemovesReplica tracks whether the command in the batch (there must
 	// be only one) removes this replica from the range.
 	changeRemovesReplica bool
+	// appliedTerm is the term of the last entry staged in the batch. It is
+	// used by witness replicas that truncate their raft log up to the batch's
+	// applied index when the batch is committed.
+	appliedTerm uint64
 
 	// Statistics.
 	entries      int
 	emptyEntries int
 	mutations    int
+	writeBytes   int
 	start        time.Time
 }
 
@@ -530,12 +535,43 @@ func (b *replicaAppBatch) stageWriteBatch(ctx context.Context, cmd *replicatedCm
 	} else {
 		b.mutations += mutations
 	}
+	b.writeBytes += len(wb.Data)
+	if b.isWitness() {
+		// Witnesses don't hold any of the range's user data, so only the
+		// mutations to the range's local keys are applied.
+		if err := applyWitnessWriteBatch(b.batch, wb.Data); err != nil {
+			return wrapWithNonDeterministicFailure(err, "unable to apply WriteBatch on witness")
+		}
+		return nil
+	}
 	if err := b.batch.ApplyBatchRepr(wb.Data, false); err != nil {
 		return wrapWithNonDeterministicFailure(err, "unable to apply WriteBatch")
 	}
 	return nil
 }
 
+// isWitness returns whether the local replica is a witness as of the batch's
+// view of the range descriptor.
+func (b *replicaAppBatch) isWitness() bool {
+	repDesc, ok := b.state.Desc.GetReplicaDescriptor(b.r.store.StoreID())
+	return ok && repDesc.GetType() == roachpb.WITNESS
+}
+
+// changePromotesStoreToWitness returns true if the change turns the replica on
+// the given store into a witness.
+func changePromotesStoreToWitness(
+	desc *roachpb.RangeDescriptor, change *kvserverpb.ChangeReplicas, storeID roachpb.StoreID,
+) bool {
+	if change.Desc == nil {
+		return false
+	}
+	if curReplica, ok := desc.GetReplicaDescriptor(storeID); !ok || curReplica.GetType() == roachpb.WITNESS {
+		return false
+	}
+	newReplica, ok := change.Desc.GetReplicaDescriptor(storeID)
+	return ok && newReplica.GetType() == roachpb.WITNESS
+}
+
 // changeRemovesStore returns true if any of the removals in this change have storeID.
 func changeRemovesStore(
 	desc *roachpb.RangeDescriptor, change *kvserverpb.ChangeReplicas, storeID roachpb.StoreID,
@@ -592,6 +628,11 @@ func (b *replicaAppBatch) runPreApplyTriggersAfterStagingWriteBatch(
 	// NB: any command which has an AddSSTable is non-trivial and will be
 	// applied in its own batch so it's not possible that any other commands
 	// which precede this command can shadow writes from this SSTable.
+	//
+	// Witnesses don't hold user data, so they skip the ingestion altogether.
+	if res.AddSSTable != nil && b.isWitness() {
+		res.AddSSTable = nil
+	}
 	if res.AddSSTable != nil {
 		copied := addSSTablePreApply(
 			ctx,
@@ -610,6 +651,7 @@ func (b *replicaAppBatch) runPreApplyTriggersAfterStagingWriteBatch(
 		if added := res.Delta.KeyCount; added > 0 {
 			b.r.writeStats.recordCount(float64(added), 0)
 		}
+		b.r.writeBytesStats.recordCount(float64(len(res.AddSSTable.Data)), 0)
 		res.AddSSTable = nil
 	}
 
@@ -777,6 +819,16 @@ func (b *replicaAppBatch) runPreApplyTriggersAfterStagingWriteBatch(
 		}
 	}
 
+	// Detect if this command promotes us to a witness. If so we stage the
+	// removal of the range's user data, which we may have received while we
+	// were a learner, into this batch.
+	if change := res.ChangeReplicas; change != nil && !b.changeRemovesReplica &&
+		changePromotesStoreToWitness(b.state.Desc, change, b.r.store.StoreID()) {
+		if err := clearWitnessExcludedData(b.batch, b.state.Desc); err != nil {
+			return wrapWithNonDeterministicFailure(err, "unable to clear data on promotion to witness")
+		}
+	}
+
 	// Provide the command's corresponding logical operations to the Replica's
 	// rangefeed. Only do so if the WriteBatch is non-nil, in which case the
 	// rangefeed requires there to be a corresponding logical operation log or
@@ -803,6 +855,7 @@ func (b *replicaAppBatch) stageTrivialReplicatedEvalResult(
 ) {
 	if raftAppliedIndex := cmd.ent.Index; raftAppliedIndex != 0 {
 		b.state.RaftAppliedIndex = raftAppliedIndex
+		b.appliedTerm = cmd.ent.Term
 	}
 	if leaseAppliedIndex := cmd.leaseIndex; leaseAppliedIndex != 0 {
 		b.state.LeaseAppliedIndex = leaseAppliedIndex
@@ -845,6 +898,17 @@ func (b *replicaAppBatch) ApplyToStateMachine(ctx context.Context) error {
 		}
 	}
 
+	// Witnesses truncate their raft log as soon as the entries are applied, up
+	// to the point that the leader knows all data voters to have reached.
+	var witnessTruncatedState *roachpb.RaftTruncatedState
+	if !b.changeRemovesReplica && b.isWitness() {
+		var err error
+		witnessTruncatedState, err = b.stageWitnessLogTruncation(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Apply the write batch to RockDB. Entry application is done without
 	// syncing to disk. The atomicity guarantees of the batch and the fact that
 	// the applied state is stored in this batch, ensure that if the batch ends
@@ -860,6 +924,13 @@ func (b *replicaAppBatch) ApplyToStateMachine(ctx context.Context) error {
 	b.batch.Close()
 	b.batch = nil
 
+	if witnessTruncatedState != nil {
+		r.handleTruncatedStateResult(ctx, witnessTruncatedState)
+		r.mu.Lock()
+		r.mu.raftLogSizeTrusted = false
+		r.mu.Unlock()
+	}
+
 	// Update the replica's applied indexes and mvcc stats.
 	r.mu.Lock()
 	r.mu.state.RaftAppliedIndex = b.state.RaftAppliedIndex
@@ -888,6 +959,7 @@ func (b *replicaAppBatch) ApplyToStateMachine(ctx context.Context) error {
 	// Record the write activity, passing a 0 nodeID because replica.writeStats
 	// intentionally doesn't track the origin of the writes.
 	b.r.writeStats.recordCount(float64(b.mutations), 0 /* nodeID */)
+	b.r.writeBytesStats.recordCount(float64(b.writeBytes), 0 /* nodeID */)
 
 	now := timeutil.Now()
 	if needsSplitBySize && r.splitQueueThrottle.ShouldProcess(now) {
@@ -904,6 +976,52 @@ func (b *replicaAppBatch) ApplyToStateMachine(ctx context.Context) error {
 	return nil
 }
 
+// stageWitnessLogTruncation stages the truncation of the raft log into the
+// application batch, up to the batch's applied index or to the witness
+// truncation index last received from the leader, whichever is lower. Entries
+// above the latter may be missing from some data voter, which the witness
+// would then have to catch up if the leader failed. It returns the new
+// truncated state, or nil if the log doesn't need to be truncated.
+func (b *replicaAppBatch) stageWitnessLogTruncation(
+	ctx context.Context,
+) (*roachpb.RaftTruncatedState, error) {
+	b.r.mu.RLock()
+	index := b.r.mu.witnessTruncationIndex
+	b.r.mu.RUnlock()
+	if index > b.state.RaftAppliedIndex {
+		index = b.state.RaftAppliedIndex
+	}
+
+	loader := &b.r.raftMu.stateLoader
+	oldTruncatedState, isLegacy, err := loader.LoadRaftTruncatedState(ctx, b.batch)
+	if err != nil {
+		return nil, wrapWithNonDeterministicFailure(err, "unable to load truncated state")
+	}
+	if isLegacy || oldTruncatedState.Index >= index {
+		return nil, nil
+	}
+	newTruncatedState := &roachpb.RaftTruncatedState{
+		Index: index,
+		Term:  b.appliedTerm,
+	}
+	if index < b.state.RaftAppliedIndex {
+		// The entry was appended to the log before it was applied, so its term
+		// can be read from the engine.
+		newTruncatedState.Term, err = term(
+			ctx, *loader, b.r.store.Engine(), b.r.RangeID, b.r.store.raftEntryCache, index,
+		)
+		if err != nil {
+			return nil, wrapWithNonDeterministicFailure(err, "unable to load term of witness truncation index")
+		}
+	}
+	if _, err := handleTruncatedStateBelowRaft(
+		ctx, &oldTruncatedState, newTruncatedState, *loader, b.batch, true, /* assertNoLegacy */
+	); err != nil {
+		return nil, wrapWithNonDeterministicFailure(err, "unable to truncate witness raft log")
+	}
+	return newTruncatedState, nil
+}
+
 // addAppliedStateKeyToBatch adds the applied state key to the application
 // batch's RocksDB batch. This records the highest raft and lease index that
 // have been applied as of this batch. It also records the Range's mvcc stats.
@@ -21,6 +21,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
@@ -655,6 +656,9 @@ func (r *Replica) AdminMerge(
 		if !replicasCollocated(lReplicas.Descriptors(), rReplicas.Descriptors()) {
 			return errors.Errorf("ranges not collocated; %s != %s", lReplicas, rReplicas)
 		}
+		if !replicasCollocated(lReplicas.WitnessDescriptors(), rReplicas.WitnessDescriptors()) {
+			return errors.Errorf("witnesses not collocated; %s != %s", lReplicas, rReplicas)
+		}
 		mergeReplicas := lReplicas.Descriptors()
 
 		updatedLeftDesc := *origLeftDesc
@@ -990,6 +994,12 @@ func (r *Replica) changeReplicasImpl(
 		return nil, err
 	}
 
+	if len(chgs.WitnessAdditions()) > 0 &&
+		!r.ClusterSettings().Version.IsActive(ctx, clusterversion.WitnessReplicas) {
+		return nil, errors.Errorf("cannot add witness replicas until the cluster version is at least %s",
+			clusterversion.ByKey(clusterversion.WitnessReplicas))
+	}
+
 	if adds := chgs.NonVoterAdditions(); len(adds) > 0 {
 		desc, err = addRaftLearners(ctx, r.store, desc, reason, details, adds, internalChangeTypeAddNonVoter)
 		if err != nil {
@@ -1001,7 +1011,7 @@ func (r *Replica) changeReplicasImpl(
 		r.store.raftSnapshotQueue.AddAsync(ctx, r, raftSnapshotPriority)
 	}
 
-	if removals := chgs.NonVoterRemovals(); len(removals) > 0 {
+	if removals := append(chgs.NonVoterRemovals(), chgs.WitnessRemovals()...); len(removals) > 0 {
 		for _, rem := range removals {
 			iChgs := []internalReplicationChange{{target: rem, typ: internalChangeTypeRemove}}
 			var err error
@@ -1019,6 +1029,13 @@ func (r *Replica) changeReplicasImpl(
 		}
 	}
 
+	if adds := chgs.WitnessAdditions(); len(adds) > 0 {
+		desc, err = r.addWitnesses(ctx, desc, priority, reason, details, adds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if adds := chgs.VoterAdditions(); len(adds) > 0 {
 		// Lock learner snapshots even before we run the ConfChange txn to add them
 		// to prevent a race with the raft snapshot queue trying to send it first.
@@ -1102,6 +1119,13 @@ func maybeLeaveAtomicChangeReplicas(
 		})
 }
 
+// finalizeAtomicReplicationChange leaves the joint config of the range, if
+// any, and removes its learners.
+func (r *Replica) finalizeAtomicReplicationChange(ctx context.Context) error {
+	_, err := maybeLeaveAtomicChangeReplicasAndRemoveLearners(ctx, r.store, r.Desc())
+	return err
+}
+
 // maybeLeaveAtomicChangeReplicasAndRemoveLearners transitions out of the joint
 // config (if there is one), and then removes all learners. After this function
 // returns, all remaining replicas will be of type VOTER_FULL.
@@ -1207,6 +1231,14 @@ func validateReplicationChanges(
 		// See https://github.com/cockroachdb/cockroach/issues/40333.
 		if ok {
 			if chg.ChangeType.IsRemoval() {
+				// Witnesses can only be removed as witnesses, and only witnesses
+				// can be removed as such, since they're removed through a simple
+				// configuration change of their own.
+				if isWitness := rDesc.GetType() == roachpb.WITNESS; isWitness != (chg.ChangeType == roachpb.REMOVE_WITNESS) {
+					return errors.Mark(errors.Errorf(
+						"unable to remove replica %v of type %s with a %s change", chg.Target, rDesc.GetType(), chg.ChangeType),
+						errMarkInvalidReplicationChange)
+				}
 				continue
 			}
 			// Looks like we found a replica with the same store and node id. If the
@@ -1224,6 +1256,11 @@ func validateReplicationChanges(
 					"unable to add replica %v which is already present as a non-voter in %s", chg.Target, desc),
 					errMarkInvalidReplicationChange)
 			}
+			if rDesc.GetType() == roachpb.WITNESS {
+				return errors.Mark(errors.Errorf(
+					"unable to add replica %v which is already present as a witness in %s", chg.Target, desc),
+					errMarkInvalidReplicationChange)
+			}
 
 			// Otherwise, we already had a full voter replica. Can't add another to
 			// this store.
@@ -1358,32 +1395,8 @@ func (r *Replica) atomicReplicationChange(
 	// this may want to detect that and retry, sending a snapshot and promoting
 	// both sides.
 
-	// Wait for our replica to catch up with the descriptor change. The replica is
-	// expected to usually be already caught up because it's expected to usually
-	// be the leaseholder - but it doesn't have to be. Being caught up is
-	// important because we might need to send snapshots below to newly-added
-	// replicas, and those snapshots would be invalid if our stale descriptor
-	// doesn't contain the respective replicas.
-	// TODO(andrei): Find a better way to wait for replication. If we knew the
-	// LAI of the respective command, we could use waitForApplication().
-	descriptorOK := false
-	start := timeutil.Now()
-	retOpts := retry.Options{InitialBackoff: time.Second, MaxBackoff: time.Second, MaxRetries: 10}
-	for re := retry.StartWithCtx(ctx, retOpts); re.Next(); {
-		rDesc := r.Desc()
-		if rDesc.Generation >= desc.Generation {
-			descriptorOK = true
-			break
-		}
-		log.VEventf(ctx, 1, "stale descriptor detected; waiting to catch up to replication. want: %s, have: %s",
-			desc, rDesc)
-		if _, err := r.IsDestroyed(); err != nil {
-			return nil, errors.Wrapf(err, "replica destroyed while waiting desc replication")
-		}
-	}
-	if !descriptorOK {
-		return nil, errors.Newf(
-			"waited for %s and replication hasn't caught up with descriptor update", timeutil.Since(start))
+	if err := r.waitForDescriptorReplication(ctx, desc); err != nil {
+		return nil, err
 	}
 
 	iChgs := make([]internalReplicationChange, 0, len(chgs))
@@ -1460,6 +1473,94 @@ func (r *Replica) atomicReplicationChange(
 	return maybeLeaveAtomicChangeReplicasAndRemoveLearners(ctx, r.store, desc)
 }
 
+// waitForDescriptorReplication waits for our replica to catch up with the
+// given descriptor change. The replica is expected to usually be already
+// caught up because it's expected to usually be the leaseholder - but it
+// doesn't have to be. Being caught up is important because we might need to
+// send snapshots to newly-added replicas, and those snapshots would be invalid
+// if our stale descriptor doesn't contain the respective replicas.
+//
+// TODO(andrei): Find a better way to wait for replication. If we knew the
+// LAI of the respective command, we could use waitForApplication().
+func (r *Replica) waitForDescriptorReplication(
+	ctx context.Context, desc *roachpb.RangeDescriptor,
+) error {
+	start := timeutil.Now()
+	retOpts := retry.Options{InitialBackoff: time.Second, MaxBackoff: time.Second, MaxRetries: 10}
+	for re := retry.StartWithCtx(ctx, retOpts); re.Next(); {
+		rDesc := r.Desc()
+		if rDesc.Generation >= desc.Generation {
+			return nil
+		}
+		log.VEventf(ctx, 1, "stale descriptor detected; waiting to catch up to replication. want: %s, have: %s",
+			desc, rDesc)
+		if _, err := r.IsDestroyed(); err != nil {
+			return errors.Wrapf(err, "replica destroyed while waiting desc replication")
+		}
+	}
+	return errors.Newf(
+		"waited for %s and replication hasn't caught up with descriptor update", timeutil.Since(start))
+}
+
+// addWitnesses adds witness replicas on the given targets. Like voters, each
+// witness is first added as a learner and caught up through an initial
+// snapshot, which doesn't contain any of the range's user data. It is then
+// promoted to a witness through a simple configuration change of its own,
+// since a witness can't be part of the incoming configuration of a joint
+// one. On error, the learners that weren't promoted are rolled back.
+func (r *Replica) addWitnesses(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	priority SnapshotRequest_Priority,
+	reason kvserverpb.RangeLogEventReason,
+	details string,
+	targets []roachpb.ReplicationTarget,
+) (_ *roachpb.RangeDescriptor, err error) {
+	// See the comment in changeReplicasImpl about locking learner snapshots.
+	releaseSnapshotLockFn := r.lockLearnerSnapshot(ctx, targets)
+	defer releaseSnapshotLockFn()
+
+	desc, err = addRaftLearners(ctx, r.store, desc, reason, details, targets, internalChangeTypeAddLearner)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			log.Infof(ctx, "could not promote %v to witness, rolling back: %v", targets, err)
+			for _, target := range targets {
+				r.tryRollBackLearnerReplica(ctx, r.Desc(), target, reason, details)
+			}
+		}
+	}()
+	if err := r.waitForDescriptorReplication(ctx, desc); err != nil {
+		return nil, err
+	}
+
+	for _, target := range targets {
+		rDesc, ok := desc.GetReplicaDescriptor(target.StoreID)
+		if !ok {
+			return nil, errors.Errorf("programming error: replica %v not found in %v", target, desc)
+		}
+		if fn := r.store.cfg.TestingKnobs.ReplicaSkipLearnerSnapshot; fn == nil || !fn() {
+			if err := r.sendSnapshot(ctx, rDesc, SnapshotRequest_WITNESS_INITIAL, priority); err != nil {
+				return nil, err
+			}
+		}
+		desc, err = execChangeReplicasTxn(ctx, desc, reason, details,
+			[]internalReplicationChange{{target: target, typ: internalChangeTypePromoteLearnerToWitness}},
+			changeReplicasTxnArgs{
+				db:                                   r.store.DB(),
+				liveAndDeadReplicas:                  r.store.allocator.storePool.liveAndDeadReplicas,
+				logChange:                            r.store.logChange,
+				testAllowDangerousReplicationChanges: r.store.TestingKnobs().AllowDangerousReplicationChanges,
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return desc, nil
+}
+
 // tryRollbackLearnerReplica attempts to remove a learner specified by the
 // target. If no such learner is found in the descriptor (including when it is a
 // voter instead), no action is taken. Otherwise, a single time-limited
@@ -1516,6 +1617,11 @@ const (
 	internalChangeTypeAddLearner
 	internalChangeTypeAddNonVoter
 	internalChangeTypePromoteLearner
+	// internalChangeTypePromoteLearnerToWitness changes a learner into a
+	// witness. Witnesses are voters as far as raft is concerned, but can't be
+	// part of the incoming configuration of a joint one (they would have to be
+	// tracked as incoming witnesses), so this always uses a simple change.
+	internalChangeTypePromoteLearnerToWitness
 	// internalChangeTypeDemoteVoter changes a voter to an ephemeral learner. This will
 	// necessarily go through joint consensus since it requires two individual
 	// changes (only one changes the quorum, so we could allow it in a simple
@@ -1585,6 +1691,15 @@ func prepareChangeReplicasTrigger(
 					return nil, errors.Errorf("cannot promote target %v which is missing as Learner", chg.target)
 				}
 				added = append(added, rDesc)
+			case internalChangeTypePromoteLearnerToWitness:
+				if useJoint {
+					return nil, errors.Errorf("cannot promote target %v to a witness using joint consensus", chg.target)
+				}
+				rDesc, prevTyp, ok := updatedDesc.SetReplicaType(chg.target.NodeID, chg.target.StoreID, roachpb.WITNESS)
+				if !ok || prevTyp != roachpb.LEARNER {
+					return nil, errors.Errorf("cannot promote target %v which is missing as Learner", chg.target)
+				}
+				added = append(added, rDesc)
 			case internalChangeTypeRemove:
 				rDesc, ok := updatedDesc.GetReplicaDescriptor(chg.target.StoreID)
 				if !ok {
@@ -1592,7 +1707,9 @@ func prepareChangeReplicasTrigger(
 				}
 				prevTyp := rDesc.GetType()
 				isRaftLearner := prevTyp == roachpb.LEARNER || prevTyp == roachpb.NON_VOTER
-				if !useJoint || isRaftLearner {
+				// Witnesses are always removed through a simple change of their own,
+				// see internalChangeTypePromoteLearnerToWitness.
+				if !useJoint || isRaftLearner || prevTyp == roachpb.WITNESS {
 					rDesc, _ = updatedDesc.RemoveReplica(chg.target.NodeID, chg.target.StoreID)
 				} else if prevTyp != roachpb.VOTER_FULL {
 					// NB: prevTyp is already known to be VOTER_FULL because of
@@ -1879,18 +1996,23 @@ func recordRangeEventsInLog(
 	logChange logChangeFn,
 ) error {
 	for _, repDesc := range repDescs {
-		isNonVoter := repDesc.GetType() == roachpb.NON_VOTER
 		var typ roachpb.ReplicaChangeType
-		if added {
-			typ = roachpb.ADD_VOTER
-			if isNonVoter {
-				typ = roachpb.ADD_NON_VOTER
-			}
-		} else {
-			typ = roachpb.REMOVE_VOTER
-			if isNonVoter {
+		switch repDesc.GetType() {
+		case roachpb.NON_VOTER:
+			typ = roachpb.ADD_NON_VOTER
+			if !added {
 				typ = roachpb.REMOVE_NON_VOTER
 			}
+		case roachpb.WITNESS:
+			typ = roachpb.ADD_WITNESS
+			if !added {
+				typ = roachpb.REMOVE_WITNESS
+			}
+		default:
+			typ = roachpb.ADD_VOTER
+			if !added {
+				typ = roachpb.REMOVE_VOTER
+			}
 		}
 		if err := logChange(
 			ctx, txn, typ, repDesc, *rangeDesc, reason, details,
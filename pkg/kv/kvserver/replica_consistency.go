@@ -360,8 +360,13 @@ func (r *Replica) RunConsistencyCheck(
 		}
 
 		// Move the local replica to the front (which makes it the "master"
-		// we're comparing against).
-		orderedReplicas = append(orderedReplicas, desc.Replicas().Descriptors()...)
+		// we're comparing against). Witnesses don't hold the range's user data,
+		// so they don't compute checksums.
+		for _, rDesc := range desc.Replicas().Descriptors() {
+			if rDesc.GetType() != roachpb.WITNESS {
+				orderedReplicas = append(orderedReplicas, rDesc)
+			}
+		}
 
 		sort.Slice(orderedReplicas, func(i, j int) bool {
 			return orderedReplicas[i] == localReplica
@@ -632,12 +637,9 @@ func (r *Replica) sha512(
 		// we will probably not have any interleaved intents so we could stop
 		// using MVCCKeyAndIntentsIterKind and consider all locks here.
 		for _, span := range rditer.MakeReplicatedKeyRangesExceptLockTable(&desc) {
-			iter := snap.NewMVCCIterator(storage.MVCCKeyAndIntentsIterKind,
-				storage.IterOptions{UpperBound: span.End.Key})
-			spanMS, err := storage.ComputeStatsForRange(
-				iter, span.Start.Key, span.End.Key, 0 /* nowNanos */, visitor,
+			spanMS, err := storage.ComputeStats(
+				snap, span.Start.Key, span.End.Key, 0 /* nowNanos */, visitor,
 			)
-			iter.Close()
 			if err != nil {
 				return nil, err
 			}
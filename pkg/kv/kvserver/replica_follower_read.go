@@ -36,14 +36,17 @@ var FollowerReadsEnabled = settings.RegisterBoolSetting(
 // the batch can be served as a follower read despite the error. Only
 // non-locking, read-only requests can be served as follower reads. The batch
 // must be composed exclusively only this kind of request to be accepted as a
-// follower read.
+// follower read. Batches composed exclusively of QueryResolvedTimestamp
+// requests are also accepted, regardless of their timestamp, since they report
+// the timestamp at which the replica can serve follower reads.
 func (r *Replica) canServeFollowerRead(
 	ctx context.Context, ba *roachpb.BatchRequest, pErr *roachpb.Error,
 ) *roachpb.Error {
 	lErr, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError)
+	queryResolvedTS := ba.IsAllQueryResolvedTimestamp()
 	eligible := ok &&
 		lErr.LeaseHolder != nil && lErr.Lease.Type() == roachpb.LeaseEpoch &&
-		(!ba.IsLocking() && ba.IsAllTransactional()) && // followerreadsccl.batchCanBeEvaluatedOnFollower
+		(!ba.IsLocking() && (ba.IsAllTransactional() || queryResolvedTS)) && // followerreadsccl.batchCanBeEvaluatedOnFollower
 		(ba.Txn == nil || !ba.Txn.IsLocking()) && // followerreadsccl.txnCanPerformFollowerRead
 		FollowerReadsEnabled.Get(&r.store.cfg.Settings.SV)
 
@@ -64,6 +67,11 @@ func (r *Replica) canServeFollowerRead(
 		return pErr
 	}
 
+	if queryResolvedTS {
+		log.Eventf(ctx, "%s; QueryResolvedTimestamp request", kvbase.FollowerReadServingMsg)
+		return nil
+	}
+
 	ts := ba.Timestamp
 	if ba.Txn != nil {
 		ts.Forward(ba.Txn.MaxTimestamp)
@@ -105,6 +113,23 @@ func (r *Replica) canServeFollowerRead(
 	return nil
 }
 
+// GetClosedTimestamp returns the maximum closed timestamp for this range, as
+// observed by this replica, if the replica is of a type that can serve follower
+// reads. Otherwise, it returns an empty timestamp. See maxClosed.
+func (r *Replica) GetClosedTimestamp(ctx context.Context) hlc.Timestamp {
+	repDesc, err := r.GetReplicaDescriptor()
+	if err != nil {
+		return hlc.Timestamp{}
+	}
+	switch repDesc.GetType() {
+	case roachpb.VOTER_FULL, roachpb.VOTER_INCOMING, roachpb.NON_VOTER:
+	default:
+		return hlc.Timestamp{}
+	}
+	maxClosed, _ := r.maxClosed(ctx)
+	return maxClosed
+}
+
 // maxClosed returns the maximum closed timestamp for this range.
 // It is computed as the most recent of the known closed timestamp for the
 // current lease holder for this range as tracked by the closed timestamp
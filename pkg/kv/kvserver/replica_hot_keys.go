@@ -0,0 +1,64 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"math/rand"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// HotKeysSampleRate wraps "kv.hot_keys.sample_rate".
+var HotKeysSampleRate = settings.RegisterFloatSetting(
+	"kv.hot_keys.sample_rate",
+	"the fraction of batches whose keys are recorded to detect the hottest keys "+
+		"of each range, or 0 to disable",
+	0.01,
+	func(v float64) error {
+		if v < 0 || v > 1 {
+			return errors.Errorf("sample rate must be between 0 and 1: %f", v)
+		}
+		return nil
+	},
+).WithPublic()
+
+// hotKeysRand is the source of randomness used by the replicas of a store to
+// sample the batches whose keys are recorded. Unlike the global source of
+// math/rand, it is only shared by the batches of the store. There is one per
+// store rather than one per replica since a rand.Rand takes several kilobytes.
+type hotKeysRand struct {
+	syncutil.Mutex
+	*rand.Rand
+}
+
+// sample returns true with the given probability.
+func (h *hotKeysRand) sample(rate float64) bool {
+	h.Lock()
+	defer h.Unlock()
+	return h.Float64() < rate
+}
+
+// recordBatchForHotKeys samples the batch, and records the keys of its
+// requests to be reported as hot keys.
+func (r *Replica) recordBatchForHotKeys(ba *roachpb.BatchRequest) {
+	rate := HotKeysSampleRate.Get(&r.store.cfg.Settings.SV)
+	if rate == 0 || !r.store.hotKeysRand.sample(rate) {
+		return
+	}
+	now := timeutil.Now()
+	for _, union := range ba.Requests {
+		r.hotKeys.Record(now, 1/rate, union.GetInner().Header().Span())
+	}
+}
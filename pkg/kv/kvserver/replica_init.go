@@ -83,6 +83,8 @@ func newUnloadedReplica(
 			SlowLatchGauge:    store.metrics.SlowLatchRequests,
 			DisableTxnPushing: store.TestingKnobs().DontPushOnWriteIntentError,
 			TxnWaitKnobs:      store.TestingKnobs().TxnWaitKnobs,
+
+			ContentionEventListener: store.cfg.ContentionEventListener,
 		}),
 	}
 	r.mu.pendingLeaseRequest = makePendingLeaseRequest(r)
@@ -107,6 +109,8 @@ func newUnloadedReplica(
 	// Pass nil for the localityOracle because we intentionally don't track the
 	// origin locality of write load.
 	r.writeStats = newReplicaStats(store.Clock(), nil)
+	r.evalStats = newReplicaStats(store.Clock(), nil)
+	r.writeBytesStats = newReplicaStats(store.Clock(), nil)
 
 	// Init rangeStr with the range ID.
 	r.rangeStr.store(replicaID, &roachpb.RangeDescriptor{RangeID: desc.RangeID})
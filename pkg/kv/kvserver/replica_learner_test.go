@@ -22,21 +22,25 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -239,6 +243,144 @@ func TestAddRemoveNonVotingReplicasBasic(t *testing.T) {
 	require.Len(t, desc.Replicas().NonVoterDescriptors(), 0)
 }
 
+// TestAddRemoveWitnessReplicas verifies that witnesses take part in the range's
+// raft group without holding any of its user data, that they truncate their
+// raft log once the data voters have the entries, that they can't receive the
+// lease, and that they can be removed.
+func TestAddRemoveWitnessReplicas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 3, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+	})
+	defer tc.Stopper().Stop(ctx)
+
+	scratchStartKey := tc.ScratchRange(t)
+	tc.AddVotersOrFatal(t, scratchStartKey, tc.Target(1))
+	desc := tc.AddWitnessesOrFatal(t, scratchStartKey, tc.Target(2))
+	require.Len(t, desc.Replicas().VoterDescriptors(), 3)
+	require.Len(t, desc.Replicas().WitnessDescriptors(), 1)
+	require.Len(t, desc.Replicas().DataVoterDescriptors(), 2)
+
+	key := scratchStartKey.Next()
+	_, err := tc.Server(0).DB().Inc(ctx, key, 5)
+	require.NoError(t, err)
+	tc.WaitForValues(t, key, []int64{5, 5, 0})
+
+	witnessStore, witnessRepl := getFirstStoreReplica(t, tc.Server(2), scratchStartKey)
+	writeIndex := witnessRepl.State().RaftAppliedIndex
+	require.NotZero(t, writeIndex)
+	// The witness truncates its log as it applies entries, up to the index that
+	// the leader last told it the data voters have reached, so it takes further
+	// writes for the write above to be truncated.
+	testutils.SucceedsSoon(t, func() error {
+		if _, err := tc.Server(0).DB().Inc(ctx, key, 0); err != nil {
+			return err
+		}
+		state := witnessRepl.State().ReplicaState
+		if state.TruncatedState.Index < writeIndex {
+			return errors.Errorf("witness raft log not truncated: truncated=%d write=%d",
+				state.TruncatedState.Index, writeIndex)
+		}
+		return nil
+	})
+	// The witness holds the range descriptor, but none of the user data.
+	var witnessDesc roachpb.RangeDescriptor
+	ok, err := storage.MVCCGetProto(ctx, witnessStore.Engine(), keys.RangeDescriptorKey(desc.StartKey),
+		hlc.MaxTimestamp, &witnessDesc, storage.MVCCGetOptions{Inconsistent: true})
+	require.NoError(t, err)
+	require.True(t, ok)
+	res, err := storage.MVCCScan(ctx, witnessStore.Engine(), desc.StartKey.AsRawKey(),
+		desc.EndKey.AsRawKey(), hlc.MaxTimestamp, storage.MVCCScanOptions{Inconsistent: true})
+	require.NoError(t, err)
+	require.Empty(t, res.KVs)
+
+	err = tc.TransferRangeLease(desc, tc.Target(2))
+	require.True(t, testutils.IsError(err, `cannot transfer lease to replica of type WITNESS`), "%+v", err)
+
+	// Witnesses can only be removed as such.
+	_, err = tc.RemoveVoters(scratchStartKey, tc.Target(2))
+	require.True(t, testutils.IsError(err, `unable to remove replica .* of type WITNESS`), "%+v", err)
+	desc, err = tc.RemoveWitnesses(scratchStartKey, tc.Target(2))
+	require.NoError(t, err)
+	require.Len(t, desc.Replicas().WitnessDescriptors(), 0)
+	require.Len(t, desc.Replicas().VoterDescriptors(), 2)
+}
+
+// TestWitnessCatchesUpDataVoterAfterLeaderFailure verifies that witnesses
+// keep the committed entries that a data voter lacks in their raft log, and
+// that they replicate them to the data voter if the leader fails.
+func TestWitnessCatchesUpDataVoterAfterLeaderFailure(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 3, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+	})
+	defer tc.Stopper().Stop(ctx)
+
+	// The scratch range is led by n2, so that the cluster's other ranges, which
+	// only have a replica on n1, outlive the leader.
+	scratchStartKey := tc.ScratchRange(t)
+	tc.AddVotersOrFatal(t, scratchStartKey, tc.Target(1))
+	desc := tc.AddWitnessesOrFatal(t, scratchStartKey, tc.Target(2))
+	require.NoError(t, tc.TransferRangeLease(desc, tc.Target(1)))
+	laggingStore, laggingRepl := getFirstStoreReplica(t, tc.Server(0), scratchStartKey)
+	_, leaderRepl := getFirstStoreReplica(t, tc.Server(1), scratchStartKey)
+	_, witnessRepl := getFirstStoreReplica(t, tc.Server(2), scratchStartKey)
+	testutils.SucceedsSoon(t, func() error {
+		if leaderRepl.RaftStatus().RaftState != raft.StateLeader {
+			return errors.New("raft leadership hasn't followed the lease yet")
+		}
+		return nil
+	})
+
+	// Drop all of the range's raft traffic to n1, so that the write is
+	// committed by the leader and the witness alone.
+	tc.Servers[0].RaftTransport().Listen(laggingStore.StoreID(), &unreliableRaftHandler{
+		rangeID:            desc.RangeID,
+		RaftMessageHandler: laggingStore,
+	})
+	key := scratchStartKey.Next()
+	require.NoError(t, tc.Server(1).DB().Put(ctx, key, "v"))
+	writeIndex := leaderRepl.State().RaftAppliedIndex
+	testutils.SucceedsSoon(t, func() error {
+		if applied := witnessRepl.State().RaftAppliedIndex; applied < writeIndex {
+			return errors.Errorf("witness applied index %d below %d", applied, writeIndex)
+		}
+		return nil
+	})
+	laggingIndex, err := laggingRepl.GetLastIndex()
+	require.NoError(t, err)
+	require.Less(t, laggingIndex, writeIndex)
+	require.LessOrEqual(t, witnessRepl.State().TruncatedState.Index, laggingIndex)
+
+	// Kill the leader and let the traffic through to n1 again. The witness is
+	// the only replica left with the write, so it has to catch n1 up.
+	tc.StopServer(1)
+	tc.Servers[0].RaftTransport().Listen(laggingStore.StoreID(), laggingStore)
+	testutils.SucceedsSoon(t, func() error {
+		val, _, err := storage.MVCCGet(ctx, laggingStore.Engine(), key, hlc.MaxTimestamp,
+			storage.MVCCGetOptions{Inconsistent: true})
+		if err != nil {
+			return err
+		}
+		if val == nil {
+			return errors.Errorf("n1 hasn't caught up on the write yet")
+		}
+		return nil
+	})
+	testutils.SucceedsSoon(t, func() error {
+		if laggingRepl.RaftStatus().RaftState != raft.StateLeader {
+			return errors.New("the witness hasn't handed the raft leadership to n1 yet")
+		}
+		return nil
+	})
+}
+
 func TestLearnerRaftConfState(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -12,6 +12,7 @@ package kvserver
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
@@ -19,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"go.etcd.io/etcd/raft/v3"
 )
 
@@ -243,6 +245,38 @@ func (r *Replica) WritesPerSecond() float64 {
 	return wps
 }
 
+// EvalNanosPerSecond returns the average number of nanoseconds per second
+// that the range spent evaluating requests. Like QueriesPerSecond, this only
+// includes the requests evaluated while the replica was the leaseholder (or
+// serving follower reads).
+//
+// The time spent evaluating a request is wall time. It excludes the time the
+// request spent waiting on latches, locks and replication, but includes the
+// time it spent blocked on disk reads or descheduled by the Go runtime, so it
+// is not a measure of the CPU time the request consumed: an overloaded node
+// reports higher evaluation times for the same requests. Use it to find the
+// ranges whose requests are expensive to serve, not to account for CPU usage.
+func (r *Replica) EvalNanosPerSecond() float64 {
+	evalTime, _ := r.evalStats.avgQPS()
+	return evalTime
+}
+
+// WriteBytesPerSecond returns the range's average bytes written per second,
+// as measured by the size of the write batches applied by Raft. Unlike
+// EvalNanosPerSecond, this load is incurred by every replica of the range.
+func (r *Replica) WriteBytesPerSecond() float64 {
+	wbps, _ := r.writeBytesStats.avgQPS()
+	return wbps
+}
+
+// recordEvalDuration records the wall time spent evaluating a batch, which
+// started at the provided time, in the replica's evaluation stats.
+func (r *Replica) recordEvalDuration(start time.Time) {
+	if r.evalStats != nil {
+		r.evalStats.recordCount(float64(timeutil.Since(start).Nanoseconds()), 0 /* nodeID */)
+	}
+}
+
 func (r *Replica) needsSplitBySizeRLocked() bool {
 	exceeded, _ := r.exceedsMultipleOfSplitSizeRLocked(1)
 	return exceeded
@@ -197,8 +197,17 @@ func (r *Replica) computeChecksumPostApply(ctx context.Context, cc kvserverpb.Co
 	// Create an entry with checksum == nil and gcTimestamp unset.
 	r.mu.checksums[cc.ChecksumID] = ReplicaChecksum{started: true, notify: notify}
 	desc := *r.mu.state.Desc
+	isWitness := r.isWitnessRLocked()
 	r.mu.Unlock()
 
+	if isWitness {
+		// Witnesses don't hold the range's user data, so there is nothing to
+		// compare against the other replicas. The consistency checker doesn't
+		// collect checksums from them.
+		r.computeChecksumDone(ctx, cc.ChecksumID, nil, nil)
+		return
+	}
+
 	if cc.Version != batcheval.ReplicaChecksumVersion {
 		r.computeChecksumDone(ctx, cc.ChecksumID, nil, nil)
 		log.Infof(ctx, "incompatible ComputeChecksum versions (requested: %d, have: %d)",
@@ -387,6 +396,9 @@ func (r *Replica) leasePostApplyLocked(
 		if r.leaseholderStats != nil {
 			r.leaseholderStats.resetRequestCounts()
 		}
+		if r.evalStats != nil {
+			r.evalStats.resetRequestCounts()
+		}
 	}
 
 	// Inform the concurrency manager that the lease holder has been updated.
@@ -441,6 +453,9 @@ func (r *Replica) leasePostApplyLocked(
 		if r.leaseholderStats != nil {
 			r.leaseholderStats.resetRequestCounts()
 		}
+		if r.evalStats != nil {
+			r.evalStats.resetRequestCounts()
+		}
 	}
 
 	// Potentially re-gossip if the range contains system data (e.g. system
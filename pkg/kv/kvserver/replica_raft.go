@@ -908,6 +908,7 @@ func (r *Replica) tick(ctx context.Context, livenessMap liveness.IsLiveMap) (boo
 	}
 
 	r.maybeTransferRaftLeadershipToLeaseholderLocked(ctx)
+	r.maybeTransferRaftLeadershipFromWitnessLocked(ctx)
 
 	// For followers, we update lastUpdateTimes when we step a message from them
 	// into the local Raft group. The leader won't hit that path, so we update
@@ -918,6 +919,12 @@ func (r *Replica) tick(ctx context.Context, livenessMap liveness.IsLiveMap) (boo
 	}
 
 	r.mu.ticks++
+	// Witnesses tick like the other voters, so that they campaign if the leader
+	// fails before all data voters have its committed entries. The data voters
+	// can't win an election in that case, since the witness doesn't vote for a
+	// shorter log than its own. A witness that becomes the leader hands the
+	// leadership over to a data voter as soon as one has caught up, see
+	// maybeTransferRaftLeadershipFromWitnessLocked.
 	r.mu.internalRaftGroup.Tick()
 
 	refreshAtDelta := r.store.cfg.RaftElectionTimeoutTicks
@@ -1053,6 +1060,7 @@ func (r *Replica) maybeCoalesceHeartbeat(
 	toReplica, fromReplica roachpb.ReplicaDescriptor,
 	quiesce bool,
 	lagging laggingReplicaSet,
+	witnessTruncationIndex uint64,
 ) bool {
 	var hbMap map[roachpb.StoreIdent][]RaftHeartbeat
 	switch msg.Type {
@@ -1074,6 +1082,7 @@ func (r *Replica) maybeCoalesceHeartbeat(
 		Quiesce:                           quiesce,
 		LaggingFollowersOnQuiesce:         lagging,
 		LaggingFollowersOnQuiesceAccurate: quiesce,
+		WitnessTruncationIndex:            witnessTruncationIndex,
 	}
 	if log.V(4) {
 		log.Infof(ctx, "coalescing beat: %+v", beat)
@@ -1161,6 +1170,10 @@ func (r *Replica) sendRaftMessage(ctx context.Context, msg raftpb.Message) {
 			}
 		})
 	}
+	var witnessTruncationIndex uint64
+	if msg.Type == raftpb.MsgApp || msg.Type == raftpb.MsgHeartbeat {
+		witnessTruncationIndex = r.witnessTruncationIndexRLocked(toReplica)
+	}
 	r.mu.RUnlock()
 
 	if fromErr != nil {
@@ -1180,17 +1193,18 @@ func (r *Replica) sendRaftMessage(ctx context.Context, msg raftpb.Message) {
 		return
 	}
 
-	if r.maybeCoalesceHeartbeat(ctx, msg, toReplica, fromReplica, false, nil) {
+	if r.maybeCoalesceHeartbeat(ctx, msg, toReplica, fromReplica, false, nil, witnessTruncationIndex) {
 		return
 	}
 
 	req := newRaftMessageRequest()
 	*req = RaftMessageRequest{
-		RangeID:       r.RangeID,
-		ToReplica:     toReplica,
-		FromReplica:   fromReplica,
-		Message:       msg,
-		RangeStartKey: startKey, // usually nil
+		RangeID:                r.RangeID,
+		ToReplica:              toReplica,
+		FromReplica:            fromReplica,
+		Message:                msg,
+		RangeStartKey:          startKey, // usually nil
+		WitnessTruncationIndex: witnessTruncationIndex,
 	}
 	if !r.sendRaftMessageRequest(ctx, req) {
 		if err := r.withRaftGroup(true, func(raftGroup *raft.RawNode) (bool, error) {
@@ -1472,6 +1486,10 @@ func (r *Replica) maybeCampaignOnWakeLocked(ctx context.Context) {
 	if _, currentMember := r.mu.state.Desc.GetReplicaDescriptorByID(r.mu.replicaID); !currentMember {
 		return
 	}
+	// Witnesses only campaign as a last resort, see tick.
+	if r.isWitnessRLocked() {
+		return
+	}
 
 	leaseStatus := r.leaseStatusAtRLocked(ctx, r.store.Clock().NowAsClockTimestamp())
 	raftStatus := r.mu.internalRaftGroup.BasicStatus()
@@ -1795,10 +1813,11 @@ func maybeCampaignAfterConfChange(
 		// initial snapshot, but it's hard to tell. Don't do anything.
 		return
 	}
-	// If the leader is no longer in the descriptor but we are the first voter,
-	// campaign.
+	// If the leader is no longer in the descriptor but we are the first voter
+	// holding data (i.e. not a witness), campaign.
 	_, leaderStillThere := desc.GetReplicaDescriptorByID(roachpb.ReplicaID(st.Lead))
-	if !leaderStillThere && storeID == desc.Replicas().VoterDescriptors()[0].StoreID {
+	dataVoters := desc.Replicas().DataVoterDescriptors()
+	if !leaderStillThere && len(dataVoters) > 0 && storeID == dataVoters[0].StoreID {
 		log.VEventf(ctx, 3, "leader got removed by conf change; campaigning")
 		_ = raftGroup.Campaign()
 	}
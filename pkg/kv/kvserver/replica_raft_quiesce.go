@@ -420,7 +420,10 @@ func (r *Replica) quiesceAndNotifyLocked(
 			Commit: commit,
 		}
 
-		if !r.maybeCoalesceHeartbeat(ctx, msg, toReplica, fromReplica, quiesce, curLagging) {
+		witnessTruncationIndex := r.witnessTruncationIndexRLocked(toReplica)
+		if !r.maybeCoalesceHeartbeat(
+			ctx, msg, toReplica, fromReplica, quiesce, curLagging, witnessTruncationIndex,
+		) {
 			log.Fatalf(ctx, "failed to coalesce known heartbeat: %v", msg)
 		}
 	}
@@ -444,7 +444,7 @@ func (r *Replica) GetSnapshot(
 	// create a new state loader.
 	snapData, err := snapshot(
 		ctx, snapUUID, stateloader.Make(rangeID), snapType,
-		snap, rangeID, r.store.raftEntryCache, withSideloaded, startKey,
+		snap, rangeID, r.store.raftEntryCache, withSideloaded, startKey, recipientStore,
 	)
 	if err != nil {
 		log.Errorf(ctx, "error generating snapshot: %+v", err)
@@ -537,6 +537,7 @@ func snapshot(
 	eCache *raftentry.Cache,
 	withSideloaded func(func(SideloadStorage) error) error,
 	startKey roachpb.RKey,
+	recipientStore roachpb.StoreID,
 ) (OutgoingSnapshot, error) {
 	var desc roachpb.RangeDescriptor
 	// We ignore intents on the range descriptor (consistent=false) because we
@@ -570,7 +571,15 @@ func snapshot(
 
 	// Intentionally let this iterator and the snapshot escape so that the
 	// streamer can send chunks from it bit by bit.
-	iter := rditer.NewReplicaEngineDataIterator(&desc, snap, true /* replicatedOnly */)
+	//
+	// Witnesses don't hold any of the range's user data, so only the range-local
+	// state is sent to them.
+	var iter *rditer.ReplicaEngineDataIterator
+	if isWitnessSnapshot(snapType, &desc, recipientStore) {
+		iter = rditer.NewWitnessEngineDataIterator(&desc, snap)
+	} else {
+		iter = rditer.NewReplicaEngineDataIterator(&desc, snap, true /* replicatedOnly */)
+	}
 
 	return OutgoingSnapshot{
 		RaftEntryCache: eCache,
@@ -592,6 +601,18 @@ func snapshot(
 	}, nil
 }
 
+// isWitnessSnapshot returns whether a snapshot of the given type and range
+// descriptor is destined for a witness replica on the given store.
+func isWitnessSnapshot(
+	snapType SnapshotRequest_Type, desc *roachpb.RangeDescriptor, recipientStore roachpb.StoreID,
+) bool {
+	if snapType == SnapshotRequest_WITNESS_INITIAL {
+		return true
+	}
+	repDesc, ok := desc.GetReplicaDescriptor(recipientStore)
+	return ok && repDesc.GetType() == roachpb.WITNESS
+}
+
 // append the given entries to the raft log. Takes the previous values of
 // r.mu.lastIndex, r.mu.lastTerm, and r.mu.raftLogSize, and returns new values.
 // We do this rather than modifying them directly because these modifications
@@ -783,6 +804,8 @@ func (r *Replica) applySnapshot(
 					r.store.metrics.RangeSnapshotsAppliedByVoters.Inc(1)
 				case roachpb.NON_VOTER:
 					r.store.metrics.RangeSnapshotsAppliedByNonVoters.Inc(1)
+				case roachpb.WITNESS:
+					r.store.metrics.RangeSnapshotsAppliedByWitnesses.Inc(1)
 				default:
 					log.Fatalf(ctx, "unexpected replica type %s while applying snapshot", typ)
 				}
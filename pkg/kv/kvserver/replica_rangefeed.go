@@ -113,14 +113,24 @@ func (tp *rangefeedTxnPusher) ResolveIntents(
 
 type iteratorWithCloser struct {
 	storage.SimpleMVCCIterator
-	close func()
+	reader storage.Reader
+	close  func()
 }
 
+var _ rangefeed.RangeTombstoneScanner = iteratorWithCloser{}
+
 func (i iteratorWithCloser) Close() {
 	i.SimpleMVCCIterator.Close()
 	i.close()
 }
 
+// ScanRangeTombstones implements rangefeed.RangeTombstoneScanner.
+func (i iteratorWithCloser) ScanRangeTombstones(
+	ctx context.Context, span roachpb.Span,
+) ([]storage.MVCCRangeTombstone, error) {
+	return storage.MVCCScanRangeTombstones(ctx, i.reader, span.Key, span.EndKey)
+}
+
 // RangeFeed registers a rangefeed over the specified span. It sends updates to
 // the provided stream and returns with an optional error when the rangefeed is
 // complete. The provided ConcurrentRequestLimiter is used to limit the number
@@ -200,8 +210,10 @@ func (r *Replica) RangeFeed(
 	var catchUpIterFunc rangefeed.IteratorConstructor
 	if usingCatchupIter {
 		catchUpIterFunc = func() storage.SimpleMVCCIterator {
-
-			innerIter := r.Engine().NewMVCCIterator(storage.MVCCKeyAndIntentsIterKind, storage.IterOptions{
+			// The range tombstones are scanned from the same snapshot as the
+			// values, so that they are consistent with each other.
+			snap := r.Engine().NewSnapshot()
+			innerIter := snap.NewMVCCIterator(storage.MVCCKeyAndIntentsIterKind, storage.IterOptions{
 				UpperBound: args.Span.EndKey,
 				// RangeFeed originally intended to use the time-bound iterator
 				// performance optimization. However, they've had correctness issues in
@@ -214,7 +226,11 @@ func (r *Replica) RangeFeed(
 			})
 			catchUpIter := iteratorWithCloser{
 				SimpleMVCCIterator: innerIter,
-				close:              iterSemRelease,
+				reader:             snap,
+				close: func() {
+					snap.Close()
+					iterSemRelease()
+				},
 			}
 			return catchUpIter
 		}
@@ -460,7 +476,8 @@ func (r *Replica) populatePrevValsInLogicalOpLogRaftMuLocked(
 		case *enginepb.MVCCWriteIntentOp,
 			*enginepb.MVCCUpdateIntentOp,
 			*enginepb.MVCCAbortIntentOp,
-			*enginepb.MVCCAbortTxnOp:
+			*enginepb.MVCCAbortTxnOp,
+			*enginepb.MVCCDeleteRangeOp:
 			// Nothing to do.
 			continue
 		default:
@@ -534,7 +551,8 @@ func (r *Replica) handleLogicalOpLogRaftMuLocked(
 		case *enginepb.MVCCWriteIntentOp,
 			*enginepb.MVCCUpdateIntentOp,
 			*enginepb.MVCCAbortIntentOp,
-			*enginepb.MVCCAbortTxnOp:
+			*enginepb.MVCCAbortTxnOp,
+			*enginepb.MVCCDeleteRangeOp:
 			// Nothing to do.
 			continue
 		default:
@@ -73,3 +73,39 @@ func TestReplicaRankings(t *testing.T) {
 		}
 	}
 }
+
+func TestReplicaRankingsByDimension(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	rr := newReplicaRankings()
+	acc := rr.newAccumulator()
+	for _, r := range []replicaWithStats{
+		{repl: &Replica{RangeID: 1}, qps: 300, evalTime: 1e6, writeBytes: 2e3},
+		{repl: &Replica{RangeID: 2}, qps: 200, evalTime: 3e6, writeBytes: 1e3},
+		{repl: &Replica{RangeID: 3}, qps: 100, evalTime: 2e6, writeBytes: 3e3},
+	} {
+		acc.addReplica(r)
+	}
+	rr.update(acc)
+
+	rangeIDs := func(repls []replicaWithStats) []roachpb.RangeID {
+		var ids []roachpb.RangeID
+		for _, r := range repls {
+			ids = append(ids, r.repl.RangeID)
+		}
+		return ids
+	}
+	for _, tc := range []struct {
+		obj  LBRebalancingObjective
+		want []roachpb.RangeID
+	}{
+		{LBRebalancingQueries, []roachpb.RangeID{1, 2, 3}},
+		{LBRebalancingEvalTime, []roachpb.RangeID{2, 3, 1}},
+		{LBRebalancingWriteBytes, []roachpb.RangeID{3, 1, 2}},
+	} {
+		if got := rangeIDs(tc.obj.hottestReplicas(rr)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("objective %d: got %v; want %v", tc.obj, got, tc.want)
+		}
+	}
+}
@@ -280,6 +280,9 @@ func (r *Replica) executeBatchWithConcurrencyRetries(
 
 			// Handle load-based splitting.
 			r.recordBatchForLoadBasedSplitting(ctx, ba, latchSpans)
+
+			// Handle hot key detection.
+			r.recordBatchForHotKeys(ba)
 		}
 
 		// Acquire latches to prevent overlapping requests from executing until
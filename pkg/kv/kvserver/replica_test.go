@@ -12884,6 +12884,18 @@ func TestPrepareChangeReplicasTrigger(t *testing.T) {
 			typOp{roachpb.VOTER_FULL, noop},
 			typOp{roachpb.LEARNER, internalChangeTypeRemove},
 		),
+		// Simple promotion of learner to witness.
+		mk(
+			"SIMPLE(v2) [(n200,s200):2WITNESS]: after=[(n100,s100):1 (n200,s200):2WITNESS] next=3",
+			typOp{roachpb.VOTER_FULL, noop},
+			typOp{roachpb.LEARNER, internalChangeTypePromoteLearnerToWitness},
+		),
+		// Simple removal of witness.
+		mk(
+			"SIMPLE(r2) [(n200,s200):2WITNESS]: after=[(n100,s100):1] next=3",
+			typOp{roachpb.VOTER_FULL, noop},
+			typOp{roachpb.WITNESS, internalChangeTypeRemove},
+		),
 
 		// All other cases below need to go through joint quorums (though some
 		// of them only due to limitations in etcd/raft).
@@ -0,0 +1,192 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"bytes"
+	"context"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/raft/v3/tracker"
+)
+
+// isWitnessKey returns whether the given key is held by witness replicas.
+// Witnesses hold the range's replicated range-ID local and range-local keys
+// (along with the locks on the latter), which is all they need to take part
+// in replication changes, splits and merges, but none of the range's user
+// data. See rditer.MakeWitnessKeyRanges.
+func isWitnessKey(key roachpb.Key) bool {
+	switch {
+	case bytes.HasPrefix(key, keys.LocalRangeIDPrefix), bytes.HasPrefix(key, keys.LocalRangePrefix):
+		return true
+	case bytes.HasPrefix(key, keys.LocalRangeLockTablePrefix):
+		lockedKey, err := keys.DecodeLockTableSingleKey(key)
+		return err == nil && keys.IsLocal(lockedKey)
+	default:
+		return false
+	}
+}
+
+// applyWitnessWriteBatch applies the mutations of the given RocksDB batch
+// representation to the writer, dropping any mutation to keys that are not
+// held by witness replicas.
+func applyWitnessWriteBatch(w storage.Writer, repr []byte) error {
+	r, err := storage.NewRocksDBBatchReader(repr)
+	if err != nil {
+		return err
+	}
+	for r.Next() {
+		if r.BatchType() == storage.BatchTypeLogData {
+			continue
+		}
+		ek, err := r.EngineKey()
+		if err != nil {
+			return err
+		}
+		if !isWitnessKey(ek.Key) {
+			continue
+		}
+		switch r.BatchType() {
+		case storage.BatchTypeValue:
+			err = w.PutEngineKey(ek, r.Value())
+		case storage.BatchTypeDeletion:
+			err = w.ClearEngineKey(ek)
+		case storage.BatchTypeSingleDeletion:
+			err = w.SingleClearEngineKey(ek)
+		case storage.BatchTypeMerge:
+			var mvccKey storage.MVCCKey
+			if mvccKey, err = r.MVCCKey(); err == nil {
+				err = w.Merge(mvccKey, r.Value())
+			}
+		case storage.BatchTypeRangeDeletion:
+			var end storage.MVCCKey
+			if end, err = r.MVCCEndKey(); err == nil {
+				err = w.ClearRawRange(ek.Key, end.Key)
+			}
+		default:
+			err = errors.Errorf("unsupported batch type: %d", r.BatchType())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return r.Error()
+}
+
+// clearWitnessExcludedData clears all of the range's replicated data that is
+// not held by witness replicas. It's used when a replica is promoted to a
+// witness, since it may have applied user writes while it was a learner.
+func clearWitnessExcludedData(w storage.Writer, desc *roachpb.RangeDescriptor) error {
+	for _, keyRange := range rditer.MakeReplicatedKeyRanges(desc) {
+		if isWitnessKey(keyRange.Start.Key) {
+			continue
+		}
+		if err := w.ClearRawRange(keyRange.Start.Key, keyRange.End.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isWitnessRLocked returns whether the replica is a witness as of its current
+// range descriptor.
+func (r *Replica) isWitnessRLocked() bool {
+	repDesc, ok := r.mu.state.Desc.GetReplicaDescriptorByID(r.mu.replicaID)
+	return ok && repDesc.GetType() == roachpb.WITNESS
+}
+
+// witnessTruncationIndexRLocked returns the index up to which the given
+// replica may truncate its raft log if it's a witness, or zero otherwise. This
+// is the lowest index that the leader knows to be in the raft log of all of
+// the range's data voters, including those of the outgoing config. It must
+// only be called on the leader, which tracks the progress of the followers.
+func (r *Replica) witnessTruncationIndexRLocked(toReplica roachpb.ReplicaDescriptor) uint64 {
+	if toReplica.GetType() != roachpb.WITNESS || r.mu.internalRaftGroup == nil {
+		return 0
+	}
+	dataVoters := make(map[uint64]struct{})
+	for _, rDesc := range r.mu.state.Desc.Replicas().Descriptors() {
+		switch rDesc.GetType() {
+		case roachpb.VOTER_FULL, roachpb.VOTER_INCOMING, roachpb.VOTER_OUTGOING, roachpb.VOTER_DEMOTING:
+			dataVoters[uint64(rDesc.ReplicaID)] = struct{}{}
+		}
+	}
+	index := uint64(math.MaxUint64)
+	var found int
+	r.mu.internalRaftGroup.WithProgress(func(id uint64, _ raft.ProgressType, pr tracker.Progress) {
+		if _, ok := dataVoters[id]; ok {
+			found++
+			if pr.Match < index {
+				index = pr.Match
+			}
+		}
+	})
+	if found == 0 || found < len(dataVoters) {
+		// Without knowing the progress of all data voters, the witness must
+		// keep its entire log.
+		return 0
+	}
+	return index
+}
+
+// maybeRecordWitnessTruncationIndex records the witness truncation index
+// carried by the given raft message. Only the current leader's view of the
+// data voters' progress is trusted.
+func (r *Replica) maybeRecordWitnessTruncationIndex(req *RaftMessageRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mu.internalRaftGroup == nil {
+		return
+	}
+	status := r.mu.internalRaftGroup.BasicStatus()
+	if req.Message.Term != status.Term || req.Message.From != status.Lead {
+		return
+	}
+	if req.WitnessTruncationIndex > r.mu.witnessTruncationIndex {
+		r.mu.witnessTruncationIndex = req.WitnessTruncationIndex
+	}
+}
+
+// maybeTransferRaftLeadershipFromWitnessLocked transfers the raft leadership
+// away from a witness to the data voter with the most up to date log. Witnesses
+// only become the leader when the data voters can't win an election, and they
+// can't hold the lease, so they only lead the range until a data voter has
+// caught up on the entries that were missing from its log. Raft completes the
+// transfer once the target has caught up.
+func (r *Replica) maybeTransferRaftLeadershipFromWitnessLocked(ctx context.Context) {
+	if !r.isWitnessRLocked() {
+		return
+	}
+	raftStatus := r.raftStatusRLocked()
+	if raftStatus == nil || raftStatus.RaftState != raft.StateLeader || raftStatus.LeadTransferee != 0 {
+		return
+	}
+	var target, targetMatch uint64
+	for _, rDesc := range r.mu.state.Desc.Replicas().DataVoterDescriptors() {
+		pr, ok := raftStatus.Progress[uint64(rDesc.ReplicaID)]
+		if ok && pr.RecentActive && (target == 0 || pr.Match > targetMatch) {
+			target, targetMatch = uint64(rDesc.ReplicaID), pr.Match
+		}
+	}
+	if target == 0 {
+		return
+	}
+	log.VEventf(ctx, 1, "witness transferring raft leadership to replica ID %v", target)
+	r.store.metrics.RangeRaftLeaderTransfers.Inc(1)
+	r.mu.internalRaftGroup.TransferLeader(target)
+}
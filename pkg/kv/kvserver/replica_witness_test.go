@@ -0,0 +1,78 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyWitnessWriteBatch verifies that only the mutations to the keys held
+// by witnesses make it through the witness write batch filter.
+func TestApplyWitnessWriteBatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	eng := storage.NewDefaultInMem()
+	defer eng.Close()
+
+	// Seed the engine with keys that the batch below deletes.
+	rangeIDKeyToDelete := keys.RangeVersionKey(1)
+	userKeyToDelete := roachpb.Key("c")
+	require.NoError(t, eng.PutUnversioned(rangeIDKeyToDelete, []byte("gc")))
+	require.NoError(t, eng.PutUnversioned(userKeyToDelete, []byte("c")))
+
+	descKey := keys.RangeDescriptorKey(roachpb.RKey("a"))
+	lockKey := func(key roachpb.Key) storage.EngineKey {
+		ek, _ := storage.LockTableKey{
+			Key: key, Strength: lock.Exclusive, TxnUUID: uuid.MakeV4().GetBytes(),
+		}.ToEngineKey(nil)
+		return ek
+	}
+	localLock, userLock := lockKey(descKey), lockKey(roachpb.Key("b"))
+
+	src := eng.NewBatch()
+	defer src.Close()
+	require.NoError(t, src.PutUnversioned(keys.RangeLeaseKey(1), []byte("lease")))
+	require.NoError(t, src.PutMVCC(storage.MVCCKey{Key: descKey, Timestamp: hlc.Timestamp{WallTime: 1}}, []byte("desc")))
+	require.NoError(t, src.PutMVCC(storage.MVCCKey{Key: roachpb.Key("b"), Timestamp: hlc.Timestamp{WallTime: 1}}, []byte("b")))
+	require.NoError(t, src.PutEngineKey(localLock, []byte("local lock")))
+	require.NoError(t, src.PutEngineKey(userLock, []byte("user lock")))
+	require.NoError(t, src.ClearUnversioned(rangeIDKeyToDelete))
+	require.NoError(t, src.ClearUnversioned(userKeyToDelete))
+	require.NoError(t, src.ClearRawRange(roachpb.Key("d"), roachpb.Key("e")))
+
+	dst := eng.NewBatch()
+	defer dst.Close()
+	require.NoError(t, applyWitnessWriteBatch(dst, src.Repr()))
+	require.NoError(t, dst.Commit(false /* sync */))
+
+	var found []string
+	it := eng.NewEngineIterator(storage.IterOptions{UpperBound: roachpb.KeyMax})
+	defer it.Close()
+	valid, err := it.SeekEngineKeyGE(storage.EngineKey{Key: roachpb.KeyMin})
+	for ; valid && err == nil; valid, err = it.NextEngineKey() {
+		found = append(found, string(it.UnsafeValue()))
+	}
+	require.NoError(t, err)
+	// The witness keys were written and deleted, whereas the user key written
+	// by the batch is absent and the one it deleted is still present.
+	require.Equal(t, []string{"lease", "desc", "local lock", "c"}, found)
+}
@@ -150,6 +150,36 @@ type replicateQueue struct {
 	lastLeaseTransfer atomic.Value // read and written by scanner & queue goroutines
 }
 
+// replicateQueueRange is the range on which the replicate queue decides on and
+// carries out replication changes and lease transfers, as seen from its
+// leaseholder. It is implemented by *Replica, and by the ranges of the
+// allocator simulator so that simulations make the same decisions as the
+// queue.
+type replicateQueueRange interface {
+	DescAndZone() (*roachpb.RangeDescriptor, *zonepb.ZoneConfig)
+	LastReplicaAdded() (roachpb.ReplicaID, time.Time)
+	RaftStatus() *raft.Status
+	// StoreID returns the store of the replica, which holds the lease.
+	StoreID() roachpb.StoreID
+	// getLeaseholderStats returns the requests received by the leaseholder.
+	getLeaseholderStats() *replicaStats
+	rangeUsageInfo() RangeUsageInfo
+	changeReplicasImpl(
+		ctx context.Context,
+		desc *roachpb.RangeDescriptor,
+		priority SnapshotRequest_Priority,
+		reason kvserverpb.RangeLogEventReason,
+		details string,
+		chgs roachpb.ReplicationChanges,
+	) (*roachpb.RangeDescriptor, error)
+	AdminTransferLease(ctx context.Context, target roachpb.StoreID) error
+	// finalizeAtomicReplicationChange leaves the joint configuration the range
+	// is in, if any, and removes its learners.
+	finalizeAtomicReplicationChange(ctx context.Context) error
+}
+
+var _ replicateQueueRange = (*Replica)(nil)
+
 // newReplicateQueue returns a new instance of replicateQueue.
 func newReplicateQueue(store *Store, g *gossip.Gossip, allocator Allocator) *replicateQueue {
 	rq := &replicateQueue{
@@ -233,7 +263,7 @@ func (rq *replicateQueue) shouldQueue(
 	}
 
 	if !rq.store.TestingKnobs().DisableReplicaRebalancing {
-		rangeUsageInfo := rangeUsageInfoForRepl(repl)
+		rangeUsageInfo := repl.rangeUsageInfo()
 		_, _, _, ok := rq.allocator.RebalanceTarget(
 			ctx, zone, repl.RaftStatus(), voterReplicas, rangeUsageInfo, storeFilterThrottled)
 		if ok {
@@ -245,9 +275,11 @@ func (rq *replicateQueue) shouldQueue(
 
 	// If the lease is valid, check to see if we should transfer it.
 	status := repl.LeaseStatusAt(ctx, now)
+	// Witnesses can't hold the lease, so only consider the other voters.
 	if status.IsValid() &&
 		rq.canTransferLease() &&
-		rq.allocator.ShouldTransferLease(ctx, zone, voterReplicas, status.Lease.Replica.StoreID, repl.leaseholderStats) {
+		rq.allocator.ShouldTransferLease(
+			ctx, zone, desc.Replicas().DataVoterDescriptors(), status.Lease.Replica.StoreID, repl.leaseholderStats) {
 
 		log.VEventf(ctx, 2, "lease transfer needed, enqueuing")
 		return true, 0
@@ -316,7 +348,14 @@ func (rq *replicateQueue) processOneChange(
 	if _, pErr := repl.redirectOnOrAcquireLease(ctx); pErr != nil {
 		return false, pErr.GoError()
 	}
+	return rq.processOneChangeImpl(ctx, repl, canTransferLease, dryRun)
+}
 
+// processOneChangeImpl decides on and carries out the next change of the
+// range, whose lease is held by the local store.
+func (rq *replicateQueue) processOneChangeImpl(
+	ctx context.Context, repl replicateQueueRange, canTransferLease func() bool, dryRun bool,
+) (requeue bool, _ error) {
 	desc, zone := repl.DescAndZone()
 
 	// Avoid taking action if the range has too many dead replicas to make
@@ -347,7 +386,10 @@ func (rq *replicateQueue) processOneChange(
 	case AllocatorAdd:
 		return rq.addOrReplace(ctx, repl, voterReplicas, liveVoterReplicas, -1 /* removeIdx */, dryRun)
 	case AllocatorRemove:
-		return rq.remove(ctx, repl, voterReplicas, dryRun)
+		// Witnesses count toward the range's replication factor, but they're
+		// only ever removed when they're dead or decommissioning, so they aren't
+		// candidates for removal when the range is over-replicated.
+		return rq.remove(ctx, repl, desc.Replicas().DataVoterDescriptors(), dryRun)
 	case AllocatorReplaceDead:
 		if len(deadVoterReplicas) == 0 {
 			// Nothing to do.
@@ -400,7 +442,7 @@ func (rq *replicateQueue) processOneChange(
 	case AllocatorConsiderRebalance:
 		return rq.considerRebalance(ctx, repl, voterReplicas, canTransferLease, dryRun)
 	case AllocatorFinalizeAtomicReplicationChange:
-		_, err := maybeLeaveAtomicChangeReplicasAndRemoveLearners(ctx, repl.store, repl.Desc())
+		err := repl.finalizeAtomicReplicationChange(ctx)
 		// Requeue because either we failed to transition out of a joint state
 		// (bad) or we did and there might be more to do for that range.
 		return true, err
@@ -420,7 +462,7 @@ func (rq *replicateQueue) processOneChange(
 // the next scanner cycle.
 func (rq *replicateQueue) addOrReplace(
 	ctx context.Context,
-	repl *Replica,
+	repl replicateQueueRange,
 	existingReplicas []roachpb.ReplicaDescriptor,
 	liveVoterReplicas []roachpb.ReplicaDescriptor,
 	removeIdx int, // -1 for no removal
@@ -518,6 +560,14 @@ func (rq *replicateQueue) addOrReplace(
 	if removeIdx < 0 {
 		log.VEventf(ctx, 1, "adding replica %+v: %s",
 			newReplica, rangeRaftProgress(repl.RaftStatus(), existingReplicas))
+	} else if removeReplica := existingReplicas[removeIdx]; removeReplica.GetType() == roachpb.WITNESS {
+		// Witnesses can't be swapped atomically since they're promoted from
+		// learners through a change of their own. Add the new witness first;
+		// the removal of the old one is a follow-up step for the next scanner
+		// cycle, which will find the range over-replicated.
+		log.VEventf(ctx, 1, "adding witness %+v to replace %s: %s",
+			newReplica, removeReplica, rangeRaftProgress(repl.RaftStatus(), existingReplicas))
+		ops = roachpb.MakeReplicationChanges(roachpb.ADD_WITNESS, newReplica)
 	} else {
 		rq.metrics.RemoveReplicaCount.Inc(1)
 		removeReplica := existingReplicas[removeIdx]
@@ -551,11 +601,7 @@ func (rq *replicateQueue) addOrReplace(
 // the progress.
 func (rq *replicateQueue) findRemoveTarget(
 	ctx context.Context,
-	repl interface {
-		DescAndZone() (*roachpb.RangeDescriptor, *zonepb.ZoneConfig)
-		LastReplicaAdded() (roachpb.ReplicaID, time.Time)
-		RaftStatus() *raft.Status
-	},
+	repl replicateQueueRange,
 	existingReplicas []roachpb.ReplicaDescriptor,
 ) (roachpb.ReplicaDescriptor, string, error) {
 	_, zone := repl.DescAndZone()
@@ -631,12 +677,12 @@ func (rq *replicateQueue) findRemoveTarget(
 // returned if it returns false.
 func (rq *replicateQueue) maybeTransferLeaseAway(
 	ctx context.Context,
-	repl *Replica,
+	repl replicateQueueRange,
 	removeStoreID roachpb.StoreID,
 	dryRun bool,
 	canTransferLease func() bool,
 ) (done bool, _ error) {
-	if removeStoreID != repl.store.StoreID() {
+	if removeStoreID != repl.StoreID() {
 		return false, nil
 	}
 	if canTransferLease != nil && !canTransferLease() {
@@ -666,7 +712,10 @@ func (rq *replicateQueue) maybeTransferLeaseAway(
 }
 
 func (rq *replicateQueue) remove(
-	ctx context.Context, repl *Replica, existingReplicas []roachpb.ReplicaDescriptor, dryRun bool,
+	ctx context.Context,
+	repl replicateQueueRange,
+	existingReplicas []roachpb.ReplicaDescriptor,
+	dryRun bool,
 ) (requeue bool, _ error) {
 	removeReplica, details, err := rq.findRemoveTarget(ctx, repl, existingReplicas)
 	if err != nil {
@@ -694,7 +743,7 @@ func (rq *replicateQueue) remove(
 	if err := rq.changeReplicas(
 		ctx,
 		repl,
-		roachpb.MakeReplicationChanges(roachpb.REMOVE_VOTER, target),
+		roachpb.MakeReplicationChanges(voterRemovalChangeType(removeReplica), target),
 		desc,
 		SnapshotRequest_UNKNOWN, // unused
 		kvserverpb.ReasonRangeOverReplicated,
@@ -706,8 +755,17 @@ func (rq *replicateQueue) remove(
 	return true, nil
 }
 
+// voterRemovalChangeType returns the type of the change removing the given
+// voting replica, which depends on whether it is a witness.
+func voterRemovalChangeType(rDesc roachpb.ReplicaDescriptor) roachpb.ReplicaChangeType {
+	if rDesc.GetType() == roachpb.WITNESS {
+		return roachpb.REMOVE_WITNESS
+	}
+	return roachpb.REMOVE_VOTER
+}
+
 func (rq *replicateQueue) removeDecommissioning(
-	ctx context.Context, repl *Replica, dryRun bool,
+	ctx context.Context, repl replicateQueueRange, dryRun bool,
 ) (requeue bool, _ error) {
 	desc, _ := repl.DescAndZone()
 	decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(desc.Replicas().Descriptors())
@@ -736,7 +794,7 @@ func (rq *replicateQueue) removeDecommissioning(
 	if err := rq.changeReplicas(
 		ctx,
 		repl,
-		roachpb.MakeReplicationChanges(roachpb.REMOVE_VOTER, target),
+		roachpb.MakeReplicationChanges(voterRemovalChangeType(decommissioningReplica), target),
 		desc,
 		SnapshotRequest_UNKNOWN, // unused
 		kvserverpb.ReasonStoreDecommissioning, "", dryRun,
@@ -748,9 +806,12 @@ func (rq *replicateQueue) removeDecommissioning(
 }
 
 func (rq *replicateQueue) removeDead(
-	ctx context.Context, repl *Replica, deadVoterReplicas []roachpb.ReplicaDescriptor, dryRun bool,
+	ctx context.Context,
+	repl replicateQueueRange,
+	deadVoterReplicas []roachpb.ReplicaDescriptor,
+	dryRun bool,
 ) (requeue bool, _ error) {
-	desc := repl.Desc()
+	desc, _ := repl.DescAndZone()
 	if len(deadVoterReplicas) == 0 {
 		log.VEventf(ctx, 1, "range of replica %s was identified as having dead replicas, but no dead replicas were found", repl)
 		return true, nil
@@ -768,7 +829,7 @@ func (rq *replicateQueue) removeDead(
 	if err := rq.changeReplicas(
 		ctx,
 		repl,
-		roachpb.MakeReplicationChanges(roachpb.REMOVE_VOTER, target),
+		roachpb.MakeReplicationChanges(voterRemovalChangeType(deadReplica), target),
 		desc,
 		SnapshotRequest_UNKNOWN, // unused
 		kvserverpb.ReasonStoreDead,
@@ -781,9 +842,9 @@ func (rq *replicateQueue) removeDead(
 }
 
 func (rq *replicateQueue) removeLearner(
-	ctx context.Context, repl *Replica, dryRun bool,
+	ctx context.Context, repl replicateQueueRange, dryRun bool,
 ) (requeue bool, _ error) {
-	desc := repl.Desc()
+	desc, _ := repl.DescAndZone()
 	learnerReplicas := desc.Replicas().LearnerDescriptors()
 	if len(learnerReplicas) == 0 {
 		log.VEventf(ctx, 1, "range of replica %s was identified as having learner replicas, "+
@@ -817,7 +878,7 @@ func (rq *replicateQueue) removeLearner(
 
 func (rq *replicateQueue) considerRebalance(
 	ctx context.Context,
-	repl *Replica,
+	repl replicateQueueRange,
 	existingReplicas []roachpb.ReplicaDescriptor,
 	canTransferLease func() bool,
 	dryRun bool,
@@ -826,12 +887,17 @@ func (rq *replicateQueue) considerRebalance(
 	// The Noop case will result if this replica was queued in order to
 	// rebalance. Attempt to find a rebalancing target.
 	if !rq.store.TestingKnobs().DisableReplicaRebalancing {
-		rangeUsageInfo := rangeUsageInfoForRepl(repl)
+		rangeUsageInfo := repl.rangeUsageInfo()
 		addTarget, removeTarget, details, ok := rq.allocator.RebalanceTarget(
 			ctx, zone, repl.RaftStatus(), existingReplicas, rangeUsageInfo,
 			storeFilterThrottled)
 		if !ok {
 			log.VEventf(ctx, 1, "no suitable rebalance target")
+		} else if rDesc, found := desc.GetReplicaDescriptor(removeTarget.StoreID); found &&
+			rDesc.GetType() == roachpb.WITNESS {
+			// Witnesses are only replaced when they're dead or decommissioning,
+			// see addOrReplace.
+			log.VEventf(ctx, 1, "not rebalancing witness %s", rDesc)
 		} else if done, err := rq.maybeTransferLeaseAway(
 			ctx, repl, removeTarget.StoreID, dryRun, canTransferLease,
 		); err != nil {
@@ -904,7 +970,7 @@ func (rq *replicateQueue) considerRebalance(
 	}
 
 	// We require the lease in order to process replicas, so
-	// repl.store.StoreID() corresponds to the lease-holder's store ID.
+	// repl.StoreID() corresponds to the lease-holder's store ID.
 	_, err := rq.shedLease(
 		ctx,
 		repl,
@@ -955,19 +1021,19 @@ func (o leaseTransferOutcome) String() string {
 // transfers the lease away.
 func (rq *replicateQueue) shedLease(
 	ctx context.Context,
-	repl *Replica,
+	repl replicateQueueRange,
 	desc *roachpb.RangeDescriptor,
 	zone *zonepb.ZoneConfig,
 	opts transferLeaseOptions,
 ) (leaseTransferOutcome, error) {
-	// Learner replicas aren't allowed to become the leaseholder or raft leader,
-	// so only consider the `VoterDescriptors` replicas.
+	// Learner and witness replicas aren't allowed to become the leaseholder, so
+	// only consider the `DataVoterDescriptors` replicas.
 	target := rq.allocator.TransferLeaseTarget(
 		ctx,
 		zone,
-		desc.Replicas().VoterDescriptors(),
-		repl.store.StoreID(),
-		repl.leaseholderStats,
+		desc.Replicas().DataVoterDescriptors(),
+		repl.StoreID(),
+		repl.getLeaseholderStats(),
 		opts.checkTransferLeaseSource,
 		opts.checkCandidateFullness,
 		false, /* alwaysAllowDecisionWithoutStats */
@@ -981,7 +1047,7 @@ func (rq *replicateQueue) shedLease(
 		return noTransferDryRun, nil
 	}
 
-	avgQPS, qpsMeasurementDur := repl.leaseholderStats.avgQPS()
+	avgQPS, qpsMeasurementDur := repl.getLeaseholderStats().avgQPS()
 	if qpsMeasurementDur < MinStatsDuration {
 		avgQPS = 0
 	}
@@ -992,7 +1058,7 @@ func (rq *replicateQueue) shedLease(
 }
 
 func (rq *replicateQueue) transferLease(
-	ctx context.Context, repl *Replica, target roachpb.ReplicaDescriptor, rangeQPS float64,
+	ctx context.Context, repl replicateQueueRange, target roachpb.ReplicaDescriptor, rangeQPS float64,
 ) error {
 	rq.metrics.TransferLeaseCount.Inc(1)
 	log.VEventf(ctx, 1, "transferring lease to s%d", target.StoreID)
@@ -1001,13 +1067,13 @@ func (rq *replicateQueue) transferLease(
 	}
 	rq.lastLeaseTransfer.Store(timeutil.Now())
 	rq.allocator.storePool.updateLocalStoresAfterLeaseTransfer(
-		repl.store.StoreID(), target.StoreID, rangeQPS)
+		repl.StoreID(), target.StoreID, rangeQPS)
 	return nil
 }
 
 func (rq *replicateQueue) changeReplicas(
 	ctx context.Context,
-	repl *Replica,
+	repl replicateQueueRange,
 	chgs roachpb.ReplicationChanges,
 	desc *roachpb.RangeDescriptor,
 	priority SnapshotRequest_Priority,
@@ -1024,7 +1090,7 @@ func (rq *replicateQueue) changeReplicas(
 	if _, err := repl.changeReplicasImpl(ctx, desc, priority, reason, details, chgs); err != nil {
 		return err
 	}
-	rangeUsageInfo := rangeUsageInfoForRepl(repl)
+	rangeUsageInfo := repl.rangeUsageInfo()
 	for _, chg := range chgs {
 		rq.allocator.storePool.updateLocalStoreAfterRebalance(
 			chg.Target.StoreID, rangeUsageInfo, chg.ChangeType)
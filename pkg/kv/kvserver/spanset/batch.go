@@ -426,6 +426,14 @@ func (s spanSetReader) ConsistentIterators() bool {
 	return s.r.ConsistentIterators()
 }
 
+func (s spanSetReader) MayHaveRangeTombstones() bool {
+	return s.r.MayHaveRangeTombstones()
+}
+
+func (s spanSetReader) MayHaveReplicatedLocks() bool {
+	return s.r.MayHaveReplicatedLocks()
+}
+
 // GetDBEngine recursively searches for the underlying rocksDB engine.
 func GetDBEngine(reader storage.Reader, span roachpb.Span) storage.Reader {
 	switch v := reader.(type) {
@@ -11,6 +11,7 @@
 package spanset
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"runtime/debug"
@@ -277,6 +278,19 @@ func (s *SpanSet) CheckAllowedAt(
 func (s *SpanSet) checkAllowed(
 	access SpanAccess, span roachpb.Span, check func(SpanAccess, Span) bool,
 ) error {
+	// Reads of MVCC range tombstones are exempt from latching, since they are
+	// performed by every MVCC read and write over the keys they cover. Writers
+	// of range tombstones declare write latches over the keys they delete.
+	if access == SpanReadOnly && isRangeTombstoneSpan(span) {
+		return nil
+	}
+	// Likewise, reads of replicated locks are performed by every MVCC write to
+	// the locked keys, and are synchronized by the latches over those keys,
+	// which acquirers and releasers of replicated locks also declare.
+	if access == SpanReadOnly && isReplicatedLockSpan(span) {
+		return nil
+	}
+
 	scope := SpanGlobal
 	if (span.Key != nil && keys.IsLocal(span.Key)) ||
 		(span.EndKey != nil && keys.IsLocal(span.EndKey)) {
@@ -294,6 +308,20 @@ func (s *SpanSet) checkAllowed(
 	return errors.Errorf("cannot %s undeclared span %s\ndeclared:\n%s\nstack:\n%s", access, span, s, debug.Stack())
 }
 
+// isRangeTombstoneSpan returns whether the span is within the MVCC range
+// tombstone keyspace.
+func isRangeTombstoneSpan(span roachpb.Span) bool {
+	return (span.Key == nil || bytes.HasPrefix(span.Key, keys.LocalMVCCRangeTombstonePrefix)) &&
+		(span.EndKey == nil || bytes.HasPrefix(span.EndKey, keys.LocalMVCCRangeTombstonePrefix))
+}
+
+// isReplicatedLockSpan returns whether the span is within the replicated lock
+// keyspace.
+func isReplicatedLockSpan(span roachpb.Span) bool {
+	return (span.Key == nil || bytes.HasPrefix(span.Key, keys.LocalReplicatedLockPrefix)) &&
+		(span.EndKey == nil || bytes.HasPrefix(span.EndKey, keys.LocalReplicatedLockPrefix))
+}
+
 // contains returns whether s1 contains s2. Unlike Span.Contains, this function
 // supports spans with a nil start key and a non-nil end key (e.g. "[nil, c)").
 // In this form, s2.Key (inclusive) is considered to be the previous key to
@@ -15,6 +15,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"math/rand"
 	"path/filepath"
 	"runtime"
 	"sort"
@@ -35,6 +36,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/container"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts/ctpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/hotkeys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/idalloc"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/intentresolver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
@@ -400,6 +403,7 @@ type Store struct {
 	tsCache            tscache.Cache  // Most recent timestamps for keys / key ranges
 	allocator          Allocator      // Makes allocation decisions
 	replRankings       *replicaRankings
+	hotKeysRand        hotKeysRand // Samples batches for hot keys
 	storeRebalancer    *StoreRebalancer
 	rangeIDAlloc       *idalloc.Allocator          // Range ID allocator
 	gcQueue            *gcQueue                    // Garbage collection queue
@@ -722,6 +726,10 @@ type StoreConfig struct {
 	// subsystem. It is queried during the GC process and in the handling of
 	// AdminVerifyProtectedTimestampRequest.
 	ProtectedTimestampCache protectedts.Cache
+
+	// ContentionEventListener, if set, is notified of the contention events of
+	// the requests which wait on conflicting locks on the store.
+	ContentionEventListener concurrency.ContentionEventListener
 }
 
 // ConsistencyTestingKnobs is a BatchEvalTestingKnobs struct used to control the
@@ -810,6 +818,7 @@ func NewStore(
 		})
 	}
 	s.replRankings = newReplicaRankings()
+	s.hotKeysRand.Rand = rand.New(rand.NewSource(rand.Int63()))
 
 	s.draining.Store(false)
 	s.scheduler = newRaftScheduler(s.metrics, s, storeSchedulerConcurrency)
@@ -2289,6 +2298,8 @@ func (s *Store) Capacity(ctx context.Context, useCached bool) (roachpb.StoreCapa
 	var logicalBytes int64
 	var totalQueriesPerSecond float64
 	var totalWritesPerSecond float64
+	var totalEvalNanosPerSecond float64
+	var totalWriteBytesPerSecond float64
 	replicaCount := s.metrics.ReplicaCount.Value()
 	bytesPerReplica := make([]float64, 0, replicaCount)
 	writesPerReplica := make([]float64, 0, replicaCount)
@@ -2315,9 +2326,21 @@ func (s *Store) Capacity(ctx context.Context, useCached bool) (roachpb.StoreCapa
 			totalWritesPerSecond += wps
 			writesPerReplica = append(writesPerReplica, wps)
 		}
+		var evalTime float64
+		if avgEvalTime, dur := r.evalStats.avgQPS(); dur >= MinStatsDuration {
+			evalTime = avgEvalTime
+			totalEvalNanosPerSecond += avgEvalTime
+		}
+		var writeBytes float64
+		if avgWriteBytes, dur := r.writeBytesStats.avgQPS(); dur >= MinStatsDuration {
+			writeBytes = avgWriteBytes
+			totalWriteBytesPerSecond += avgWriteBytes
+		}
 		rankingsAccumulator.addReplica(replicaWithStats{
-			repl: r,
-			qps:  qps,
+			repl:       r,
+			qps:        qps,
+			evalTime:   evalTime,
+			writeBytes: writeBytes,
 		})
 		return true
 	})
@@ -2326,6 +2349,8 @@ func (s *Store) Capacity(ctx context.Context, useCached bool) (roachpb.StoreCapa
 	capacity.LogicalBytes = logicalBytes
 	capacity.QueriesPerSecond = totalQueriesPerSecond
 	capacity.WritesPerSecond = totalWritesPerSecond
+	capacity.EvalNanosPerSecond = totalEvalNanosPerSecond
+	capacity.WriteBytesPerSecond = totalWriteBytesPerSecond
 	capacity.BytesPerReplica = roachpb.PercentilesFromData(bytesPerReplica)
 	capacity.WritesPerReplica = roachpb.PercentilesFromData(writesPerReplica)
 	s.recordNewPerSecondStats(totalQueriesPerSecond, totalWritesPerSecond)
@@ -2431,6 +2456,8 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		quiescentCount                int64
 		averageQueriesPerSecond       float64
 		averageWritesPerSecond        float64
+		averageEvalNanosPerSecond     float64
+		averageWriteBytesPerSecond    float64
 
 		rangeCount                int64
 		unavailableRangeCount     int64
@@ -2487,6 +2514,12 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 		if wps, dur := rep.writeStats.avgQPS(); dur >= MinStatsDuration {
 			averageWritesPerSecond += wps
 		}
+		if evalTime, dur := rep.evalStats.avgQPS(); dur >= MinStatsDuration {
+			averageEvalNanosPerSecond += evalTime
+		}
+		if wbps, dur := rep.writeBytesStats.avgQPS(); dur >= MinStatsDuration {
+			averageWriteBytesPerSecond += wbps
+		}
 		mc, ok := rep.maxClosed(ctx)
 		if ok && (minMaxClosedTS.IsEmpty() || mc.Less(minMaxClosedTS)) {
 			minMaxClosedTS = mc
@@ -2502,6 +2535,8 @@ func (s *Store) updateReplicationGauges(ctx context.Context) error {
 	s.metrics.QuiescentCount.Update(quiescentCount)
 	s.metrics.AverageQueriesPerSecond.Update(averageQueriesPerSecond)
 	s.metrics.AverageWritesPerSecond.Update(averageWritesPerSecond)
+	s.metrics.AverageEvalNanosPerSecond.Update(averageEvalNanosPerSecond)
+	s.metrics.AverageWriteBytesPerSecond.Update(averageWriteBytesPerSecond)
 	s.recordNewPerSecondStats(averageQueriesPerSecond, averageWritesPerSecond)
 
 	s.metrics.RangeCount.Update(rangeCount)
@@ -2606,6 +2641,46 @@ func (s *Store) HottestReplicas() []HotReplicaInfo {
 	return hotRepls
 }
 
+// HotKeyInfo contains a key, the range it belongs to and its QPS.
+type HotKeyInfo struct {
+	Key     roachpb.Key
+	RangeID roachpb.RangeID
+	QPS     float64
+}
+
+// HottestKeys returns up to limit of the hottest keys on a store, and up to
+// limit of the hottest SQL index prefixes, sorted by their QPS. The QPS of a
+// prefix is summed across all the replicas it spans.
+//
+// The keys are estimated from the batches sampled by each replica, so the
+// reported QPS are approximate.
+func (s *Store) HottestKeys(limit int) (hotKeys []HotKeyInfo, hotPrefixes []hotkeys.KeyQPS) {
+	now := timeutil.Now()
+	prefixQPS := make(map[string]float64)
+	newStoreReplicaVisitor(s).Visit(func(repl *Replica) bool {
+		replKeys, replPrefixes := repl.hotKeys.HottestKeys(now)
+		for _, k := range replKeys {
+			hotKeys = append(hotKeys, HotKeyInfo{Key: k.Key, RangeID: repl.RangeID, QPS: k.QPS})
+		}
+		for _, p := range replPrefixes {
+			prefixQPS[string(p.Key)] += p.QPS
+		}
+		return true
+	})
+	sort.Slice(hotKeys, func(i, j int) bool { return hotKeys[i].QPS > hotKeys[j].QPS })
+	if len(hotKeys) > limit {
+		hotKeys = hotKeys[:limit]
+	}
+	for k, qps := range prefixQPS {
+		hotPrefixes = append(hotPrefixes, hotkeys.KeyQPS{Key: roachpb.Key(k), QPS: qps})
+	}
+	sort.Slice(hotPrefixes, func(i, j int) bool { return hotPrefixes[i].QPS > hotPrefixes[j].QPS })
+	if len(hotPrefixes) > limit {
+		hotPrefixes = hotPrefixes[:limit]
+	}
+	return hotKeys, hotPrefixes
+}
+
 // StoreKeySpanStats carries the result of a stats computation over a key range.
 type StoreKeySpanStats struct {
 	ReplicaCount         int
@@ -281,7 +281,7 @@ type StorePool struct {
 }
 
 // NewStorePool creates a StorePool and registers the store updating callback
-// with gossip.
+// with gossip, if provided.
 func NewStorePool(
 	ambient log.AmbientContext,
 	st *cluster.Settings,
@@ -305,11 +305,15 @@ func NewStorePool(
 	sp.detailsMu.storeDetails = make(map[roachpb.StoreID]*storeDetail)
 	sp.localitiesMu.nodeLocalities = make(map[roachpb.NodeID]localityWithString)
 
-	// Enable redundant callbacks for the store keys because we use these
-	// callbacks as a clock to determine when a store was last updated even if it
-	// hasn't otherwise changed.
-	storeRegex := gossip.MakePrefixPattern(gossip.KeyStorePrefix)
-	g.RegisterCallback(storeRegex, sp.storeGossipUpdate, gossip.Redundant)
+	// The gossip network is nil when store descriptors are fed to the StorePool
+	// directly, as done by the allocator simulator.
+	if g != nil {
+		// Enable redundant callbacks for the store keys because we use these
+		// callbacks as a clock to determine when a store was last updated even if
+		// it hasn't otherwise changed.
+		storeRegex := gossip.MakePrefixPattern(gossip.KeyStorePrefix)
+		g.RegisterCallback(storeRegex, sp.storeGossipUpdate, gossip.Redundant)
+	}
 
 	return sp
 }
@@ -356,7 +360,12 @@ func (sp *StorePool) storeGossipUpdate(_ string, content roachpb.Value) {
 		log.Errorf(ctx, "%v", err)
 		return
 	}
+	sp.storeDescriptorUpdate(storeDesc)
+}
 
+// storeDescriptorUpdate records the latest descriptor of a store, refreshing
+// the time at which the store was last heard from.
+func (sp *StorePool) storeDescriptorUpdate(storeDesc roachpb.StoreDescriptor) {
 	sp.detailsMu.Lock()
 	detail := sp.getStoreDetailLocked(storeDesc.StoreID)
 	detail.desc = &storeDesc
@@ -388,6 +397,7 @@ func (sp *StorePool) updateLocalStoreAfterRebalance(
 		detail.desc.Capacity.RangeCount++
 		detail.desc.Capacity.LogicalBytes += rangeUsageInfo.LogicalBytes
 		detail.desc.Capacity.WritesPerSecond += rangeUsageInfo.WritesPerSecond
+		detail.desc.Capacity.WriteBytesPerSecond += rangeUsageInfo.WriteBytesPerSecond
 	case roachpb.REMOVE_VOTER:
 		detail.desc.Capacity.RangeCount--
 		if detail.desc.Capacity.LogicalBytes <= rangeUsageInfo.LogicalBytes {
@@ -400,6 +410,11 @@ func (sp *StorePool) updateLocalStoreAfterRebalance(
 		} else {
 			detail.desc.Capacity.WritesPerSecond -= rangeUsageInfo.WritesPerSecond
 		}
+		if detail.desc.Capacity.WriteBytesPerSecond <= rangeUsageInfo.WriteBytesPerSecond {
+			detail.desc.Capacity.WriteBytesPerSecond = 0
+		} else {
+			detail.desc.Capacity.WriteBytesPerSecond -= rangeUsageInfo.WriteBytesPerSecond
+		}
 	}
 	sp.detailsMu.storeDetails[storeID] = &detail
 }
@@ -584,6 +599,14 @@ type StoreList struct {
 	// candidateWritesPerSecond tracks writes-per-second stats for stores that are
 	// eligible to be rebalance targets.
 	candidateWritesPerSecond stat
+
+	// candidateEvalNanosPerSecond tracks request evaluation time stats for stores
+	// that are eligible to be rebalance targets.
+	candidateEvalNanosPerSecond stat
+
+	// candidateWriteBytesPerSecond tracks write-bytes-per-second stats for
+	// stores that are eligible to be rebalance targets.
+	candidateWriteBytesPerSecond stat
 }
 
 // Generates a new store list based on the passed in descriptors. It will
@@ -598,6 +621,8 @@ func makeStoreList(descriptors []roachpb.StoreDescriptor) StoreList {
 		sl.candidateLogicalBytes.update(float64(desc.Capacity.LogicalBytes))
 		sl.candidateQueriesPerSecond.update(desc.Capacity.QueriesPerSecond)
 		sl.candidateWritesPerSecond.update(desc.Capacity.WritesPerSecond)
+		sl.candidateEvalNanosPerSecond.update(desc.Capacity.EvalNanosPerSecond)
+		sl.candidateWriteBytesPerSecond.update(desc.Capacity.WriteBytesPerSecond)
 	}
 	return sl
 }
@@ -502,8 +502,9 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 	manual.Increment(int64(MinStatsDuration + time.Second))
 	replica.leaseholderStats = rs
 	replica.writeStats = rs
+	replica.writeBytesStats = rs
 
-	rangeUsageInfo := rangeUsageInfoForRepl(replica)
+	rangeUsageInfo := replica.rangeUsageInfo()
 
 	sp.updateLocalStoreAfterRebalance(roachpb.StoreID(1), rangeUsageInfo, roachpb.ADD_VOTER)
 	desc, ok := sp.getStoreDescriptor(roachpb.StoreID(1))
@@ -512,6 +513,7 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 	}
 	QPS, _ := replica.leaseholderStats.avgQPS()
 	WPS, _ := replica.writeStats.avgQPS()
+	WBPS, _ := replica.writeBytesStats.avgQPS()
 	if expectedRangeCount := int32(6); desc.Capacity.RangeCount != expectedRangeCount {
 		t.Errorf("expected RangeCount %d, but got %d", expectedRangeCount, desc.Capacity.RangeCount)
 	}
@@ -524,6 +526,9 @@ func TestStorePoolUpdateLocalStore(t *testing.T) {
 	if expectedWPS := 30 + WPS; desc.Capacity.WritesPerSecond != expectedWPS {
 		t.Errorf("expected WritesPerSecond %f, but got %f", expectedWPS, desc.Capacity.WritesPerSecond)
 	}
+	if expectedWBPS := WBPS; desc.Capacity.WriteBytesPerSecond != expectedWBPS {
+		t.Errorf("expected WriteBytesPerSecond %f, but got %f", expectedWBPS, desc.Capacity.WriteBytesPerSecond)
+	}
 
 	sp.updateLocalStoreAfterRebalance(roachpb.StoreID(2), rangeUsageInfo, roachpb.REMOVE_VOTER)
 	desc, ok = sp.getStoreDescriptor(roachpb.StoreID(2))
@@ -609,7 +614,7 @@ func TestStorePoolUpdateLocalStoreBeforeGossip(t *testing.T) {
 	}
 	replica.leaseholderStats = newReplicaStats(store.Clock(), nil)
 
-	rangeUsageInfo := rangeUsageInfoForRepl(replica)
+	rangeUsageInfo := replica.rangeUsageInfo()
 
 	// Update StorePool, which should be a no-op.
 	storeID := roachpb.StoreID(1)
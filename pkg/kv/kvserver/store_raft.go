@@ -123,6 +123,7 @@ func (s *Store) uncoalesceBeats(
 			Quiesce:                           beat.Quiesce,
 			LaggingFollowersOnQuiesce:         beat.LaggingFollowersOnQuiesce,
 			LaggingFollowersOnQuiesceAccurate: beat.LaggingFollowersOnQuiesceAccurate,
+			WitnessTruncationIndex:            beat.WitnessTruncationIndex,
 		}
 		if log.V(4) {
 			log.Infof(ctx, "uncoalesced beat: %+v", beatReqs[i])
@@ -233,6 +234,10 @@ func (s *Store) processRaftRequestWithReplica(
 		log.Fatalf(ctx, "unexpected snapshot: %+v", req)
 	}
 
+	if req.WitnessTruncationIndex != 0 {
+		r.maybeRecordWitnessTruncationIndex(req)
+	}
+
 	if req.Quiesce {
 		if req.Message.Type != raftpb.MsgHeartbeat {
 			log.Fatalf(ctx, "unexpected quiesce: %+v", req)
@@ -41,6 +41,15 @@ const (
 	// by less than this amount even if the amount is greater than the percentage
 	// threshold. This avoids too many lease transfers in lightly loaded clusters.
 	minQPSThresholdDifference = 100
+
+	// minEvalTimeThresholdDifference is like minQPSThresholdDifference, but
+	// for the nanoseconds per second spent evaluating requests. It corresponds
+	// to a tenth of a core kept busy evaluating requests.
+	minEvalTimeThresholdDifference = float64(100 * time.Millisecond)
+
+	// minWriteBytesThresholdDifference is like minQPSThresholdDifference, but
+	// for the bytes written per second.
+	minWriteBytesThresholdDifference = 1 << 20 // 1 MiB
 )
 
 var (
@@ -76,7 +85,7 @@ func makeStoreRebalancerMetrics() StoreRebalancerMetrics {
 // If disabled, rebalancing is done purely based on replica count.
 var LoadBasedRebalancingMode = settings.RegisterEnumSetting(
 	"kv.allocator.load_based_rebalancing",
-	"whether to rebalance based on the distribution of QPS across stores",
+	"whether to rebalance based on the distribution of load across stores",
 	"leases and replicas",
 	map[int64]string{
 		int64(LBRebalancingOff):               "off",
@@ -117,6 +126,178 @@ const (
 	LBRebalancingLeasesAndReplicas
 )
 
+// LoadBasedRebalancingObjective controls which dimension of load the
+// store-level rebalancer balances across stores.
+var LoadBasedRebalancingObjective = settings.RegisterEnumSetting(
+	"kv.allocator.load_based_rebalancing.objective",
+	"what to balance across stores when rebalancing based on load: the requests received by "+
+		"leaseholders (qps), the time spent evaluating requests (eval_time), or the bytes written by "+
+		"applied raft commands (write_bytes)",
+	"qps",
+	map[int64]string{
+		int64(LBRebalancingQueries):    "qps",
+		int64(LBRebalancingEvalTime):   "eval_time",
+		int64(LBRebalancingWriteBytes): "write_bytes",
+	},
+).WithPublic()
+
+// evalTimeRebalanceThreshold is like qpsRebalanceThreshold, but for the time spent
+// evaluating requests. It is used when the rebalancing objective is
+// eval_time.
+var evalTimeRebalanceThreshold = func() *settings.FloatSetting {
+	s := settings.RegisterFloatSetting(
+		"kv.allocator.eval_time_rebalance_threshold",
+		"minimum fraction away from the mean a store's time spent evaluating requests per second can be before it is considered overfull or underfull",
+		0.1,
+		settings.NonNegativeFloat,
+	)
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
+// writeBytesRebalanceThreshold is like qpsRebalanceThreshold, but for the
+// bytes written per second. It is used when the rebalancing objective is
+// write_bytes.
+var writeBytesRebalanceThreshold = func() *settings.FloatSetting {
+	s := settings.RegisterFloatSetting(
+		"kv.allocator.write_bytes_rebalance_threshold",
+		"minimum fraction away from the mean a store's bytes written per second can be before it is considered overfull or underfull",
+		0.25,
+		settings.NonNegativeFloat,
+	)
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
+// LBRebalancingObjective is the dimension of load that the store-level
+// rebalancer balances across stores.
+type LBRebalancingObjective int64
+
+const (
+	// LBRebalancingQueries balances the number of requests received per second
+	// by the leaseholders of each store.
+	LBRebalancingQueries LBRebalancingObjective = iota
+	// LBRebalancingEvalTime balances the wall time spent per second evaluating
+	// requests by the leaseholders of each store. This catches ranges that
+	// serve few but expensive requests, such as large scans, which QPS
+	// underestimates. It is not a measure of CPU usage; see
+	// Replica.EvalNanosPerSecond.
+	LBRebalancingEvalTime
+	// LBRebalancingWriteBytes balances the bytes written per second by the
+	// raft commands applied on each store. Since every replica of a range
+	// applies its writes, this load only moves with replicas and not with
+	// leases.
+	LBRebalancingWriteBytes
+)
+
+// storeLoad returns the load of the store in the objective's dimension.
+func (o LBRebalancingObjective) storeLoad(c roachpb.StoreCapacity) float64 {
+	switch o {
+	case LBRebalancingEvalTime:
+		return c.EvalNanosPerSecond
+	case LBRebalancingWriteBytes:
+		return c.WriteBytesPerSecond
+	default:
+		return c.QueriesPerSecond
+	}
+}
+
+// addStoreLoad adds the delta to the load of the store in the objective's
+// dimension.
+func (o LBRebalancingObjective) addStoreLoad(c *roachpb.StoreCapacity, delta float64) {
+	switch o {
+	case LBRebalancingEvalTime:
+		c.EvalNanosPerSecond += delta
+	case LBRebalancingWriteBytes:
+		c.WriteBytesPerSecond += delta
+	default:
+		c.QueriesPerSecond += delta
+	}
+}
+
+// replicaLoad returns the load of the replica in the objective's dimension.
+func (o LBRebalancingObjective) replicaLoad(r replicaWithStats) float64 {
+	switch o {
+	case LBRebalancingEvalTime:
+		return r.evalTime
+	case LBRebalancingWriteBytes:
+		return r.writeBytes
+	default:
+		return r.qps
+	}
+}
+
+// meanLoad returns the mean load of the candidate stores in the objective's
+// dimension.
+func (o LBRebalancingObjective) meanLoad(sl StoreList) float64 {
+	switch o {
+	case LBRebalancingEvalTime:
+		return sl.candidateEvalNanosPerSecond.mean
+	case LBRebalancingWriteBytes:
+		return sl.candidateWriteBytesPerSecond.mean
+	default:
+		return sl.candidateQueriesPerSecond.mean
+	}
+}
+
+// hottestReplicas returns the replicas of the store with the most load in the
+// objective's dimension, in descending order.
+func (o LBRebalancingObjective) hottestReplicas(rr *replicaRankings) []replicaWithStats {
+	switch o {
+	case LBRebalancingEvalTime:
+		return rr.topEvalTime()
+	case LBRebalancingWriteBytes:
+		return rr.topWriteBytes()
+	default:
+		return rr.topQPS()
+	}
+}
+
+// thresholdFraction returns the fraction away from the mean that a store's
+// load can be before it is considered overfull or underfull.
+func (o LBRebalancingObjective) thresholdFraction(sv *settings.Values) float64 {
+	switch o {
+	case LBRebalancingEvalTime:
+		return evalTimeRebalanceThreshold.Get(sv)
+	case LBRebalancingWriteBytes:
+		return writeBytesRebalanceThreshold.Get(sv)
+	default:
+		return qpsRebalanceThreshold.Get(sv)
+	}
+}
+
+// minThresholdDifference is the minimum difference from the cluster mean
+// that the store rebalancer should care about. See minQPSThresholdDifference.
+func (o LBRebalancingObjective) minThresholdDifference() float64 {
+	switch o {
+	case LBRebalancingEvalTime:
+		return minEvalTimeThresholdDifference
+	case LBRebalancingWriteBytes:
+		return minWriteBytesThresholdDifference
+	default:
+		return minQPSThresholdDifference
+	}
+}
+
+// followsLease returns whether the load moves with the lease of a range, in
+// which case it can be balanced by transferring leases. Otherwise, it can only
+// be balanced by moving replicas.
+func (o LBRebalancingObjective) followsLease() bool {
+	return o != LBRebalancingWriteBytes
+}
+
+// unit returns the unit of the objective's dimension, for logging.
+func (o LBRebalancingObjective) unit() redact.SafeString {
+	switch o {
+	case LBRebalancingEvalTime:
+		return "eval-ns/s"
+	case LBRebalancingWriteBytes:
+		return "write-bytes/s"
+	default:
+		return "qps"
+	}
+}
+
 // StoreRebalancer is responsible for examining how the associated store's load
 // compares to the load on other stores in the cluster and transferring leases
 // or replicas away if the local store is overloaded.
@@ -134,6 +315,19 @@ type StoreRebalancer struct {
 	rq              *replicateQueue
 	replRankings    *replicaRankings
 	getRaftStatusFn func(replica *Replica) *raft.Status
+
+	// transferLeaseFn and relocateRangeFn carry out the lease transfers and
+	// replica rebalances chosen by rebalanceStore. The allocator simulator
+	// overrides them to apply the decisions to its simulated cluster.
+	transferLeaseFn func(
+		ctx context.Context, repl *Replica, target roachpb.ReplicaDescriptor, rangeQPS float64,
+	) error
+	relocateRangeFn func(
+		ctx context.Context,
+		repl *Replica,
+		desc roachpb.RangeDescriptor,
+		voterTargets, nonVoterTargets []roachpb.ReplicationTarget,
+	) error
 }
 
 // NewStoreRebalancer creates a StoreRebalancer to work in tandem with the
@@ -154,6 +348,25 @@ func NewStoreRebalancer(
 			return replica.RaftStatus()
 		},
 	}
+	sr.transferLeaseFn = func(
+		ctx context.Context, repl *Replica, target roachpb.ReplicaDescriptor, rangeQPS float64,
+	) error {
+		timeout := sr.rq.processTimeoutFunc(sr.st, repl)
+		return contextutil.RunWithTimeout(ctx, "transfer lease", timeout, func(ctx context.Context) error {
+			return sr.rq.transferLease(ctx, repl, target, rangeQPS)
+		})
+	}
+	sr.relocateRangeFn = func(
+		ctx context.Context,
+		repl *Replica,
+		desc roachpb.RangeDescriptor,
+		voterTargets, nonVoterTargets []roachpb.ReplicationTarget,
+	) error {
+		timeout := sr.rq.processTimeoutFunc(sr.st, repl)
+		return contextutil.RunWithTimeout(ctx, "relocate range", timeout, func(ctx context.Context) error {
+			return sr.rq.store.AdminRelocateRange(ctx, desc, voterTargets, nonVoterTargets)
+		})
+	}
 	sr.AddLogTag("store-rebalancer", nil)
 	sr.rq.store.metrics.registry.AddMetricStruct(&sr.metrics)
 	return sr
@@ -198,22 +411,23 @@ func (sr *StoreRebalancer) Start(ctx context.Context, stopper *stop.Stopper) {
 				continue
 			}
 
+			obj := LBRebalancingObjective(LoadBasedRebalancingObjective.Get(&sr.st.SV))
 			storeList, _, _ := sr.rq.allocator.storePool.getStoreList(storeFilterNone)
-			sr.rebalanceStore(ctx, mode, storeList)
+			sr.rebalanceStore(ctx, mode, obj, storeList)
 		}
 	})
 }
 
 func (sr *StoreRebalancer) rebalanceStore(
-	ctx context.Context, mode LBRebalancingMode, storeList StoreList,
+	ctx context.Context, mode LBRebalancingMode, obj LBRebalancingObjective, storeList StoreList,
 ) {
-	qpsThresholdFraction := qpsRebalanceThreshold.Get(&sr.st.SV)
+	thresholdFraction := obj.thresholdFraction(&sr.st.SV)
+	meanLoad := obj.meanLoad(storeList)
+	unit := obj.unit()
 
-	// First check if we should transfer leases away to better balance QPS.
-	qpsMinThreshold := math.Min(storeList.candidateQueriesPerSecond.mean*(1-qpsThresholdFraction),
-		storeList.candidateQueriesPerSecond.mean-minQPSThresholdDifference)
-	qpsMaxThreshold := math.Max(storeList.candidateQueriesPerSecond.mean*(1+qpsThresholdFraction),
-		storeList.candidateQueriesPerSecond.mean+minQPSThresholdDifference)
+	// First check if we should transfer leases away to better balance load.
+	minLoad := math.Min(meanLoad*(1-thresholdFraction), meanLoad-obj.minThresholdDifference())
+	maxLoad := math.Max(meanLoad*(1+thresholdFraction), meanLoad+obj.minThresholdDifference())
 
 	var localDesc *roachpb.StoreDescriptor
 	for i := range storeList.stores {
@@ -226,95 +440,97 @@ func (sr *StoreRebalancer) rebalanceStore(
 		return
 	}
 
-	if !(localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold) {
-		log.VEventf(ctx, 1, "local QPS %.2f is below max threshold %.2f (mean=%.2f); no rebalancing needed",
-			localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold, storeList.candidateQueriesPerSecond.mean)
+	if !(obj.storeLoad(localDesc.Capacity) > maxLoad) {
+		log.VEventf(ctx, 1, "local load %.2f %s is below max threshold %.2f (mean=%.2f); no rebalancing needed",
+			obj.storeLoad(localDesc.Capacity), unit, maxLoad, meanLoad)
 		return
 	}
 
 	var replicasToMaybeRebalance []replicaWithStats
 	storeMap := storeListToMap(storeList)
 
-	log.Infof(ctx,
-		"considering load-based lease transfers for s%d with %.2f qps (mean=%.2f, upperThreshold=%.2f)",
-		localDesc.StoreID, localDesc.Capacity.QueriesPerSecond, storeList.candidateQueriesPerSecond.mean, qpsMaxThreshold)
-
-	hottestRanges := sr.replRankings.topQPS()
-	for localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold {
-		replWithStats, target, considerForRebalance := sr.chooseLeaseToTransfer(
-			ctx, &hottestRanges, localDesc, storeList, storeMap, qpsMinThreshold, qpsMaxThreshold)
-		replicasToMaybeRebalance = append(replicasToMaybeRebalance, considerForRebalance...)
-		if replWithStats.repl == nil {
-			break
-		}
+	hottestRanges := obj.hottestReplicas(sr.replRankings)
+	if obj.followsLease() {
+		log.Infof(ctx,
+			"considering load-based lease transfers for s%d with %.2f %s (mean=%.2f, upperThreshold=%.2f)",
+			localDesc.StoreID, obj.storeLoad(localDesc.Capacity), unit, meanLoad, maxLoad)
+
+		for obj.storeLoad(localDesc.Capacity) > maxLoad {
+			replWithStats, target, considerForRebalance := sr.chooseLeaseToTransfer(
+				ctx, obj, &hottestRanges, localDesc, storeList, storeMap, minLoad, maxLoad)
+			replicasToMaybeRebalance = append(replicasToMaybeRebalance, considerForRebalance...)
+			if replWithStats.repl == nil {
+				break
+			}
 
-		log.VEventf(ctx, 1, "transferring r%d (%.2f qps) to s%d to better balance load",
-			replWithStats.repl.RangeID, replWithStats.qps, target.StoreID)
-		timeout := sr.rq.processTimeoutFunc(sr.st, replWithStats.repl)
-		if err := contextutil.RunWithTimeout(ctx, "transfer lease", timeout, func(ctx context.Context) error {
-			return sr.rq.transferLease(ctx, replWithStats.repl, target, replWithStats.qps)
-		}); err != nil {
-			log.Errorf(ctx, "unable to transfer lease to s%d: %+v", target.StoreID, err)
-			continue
+			replLoad := obj.replicaLoad(replWithStats)
+			log.VEventf(ctx, 1, "transferring r%d (%.2f %s) to s%d to better balance load",
+				replWithStats.repl.RangeID, replLoad, unit, target.StoreID)
+			if err := sr.transferLeaseFn(ctx, replWithStats.repl, target, replWithStats.qps); err != nil {
+				log.Errorf(ctx, "unable to transfer lease to s%d: %+v", target.StoreID, err)
+				continue
+			}
+			sr.metrics.LeaseTransferCount.Inc(1)
+
+			// Finally, update our local copies of the descriptors so that if
+			// additional transfers are needed we'll be making the decisions with more
+			// up-to-date info. The StorePool copies are updated by transferLease.
+			localDesc.Capacity.LeaseCount--
+			obj.addStoreLoad(&localDesc.Capacity, -replLoad)
+			if otherDesc := storeMap[target.StoreID]; otherDesc != nil {
+				otherDesc.Capacity.LeaseCount++
+				obj.addStoreLoad(&otherDesc.Capacity, replLoad)
+			}
 		}
-		sr.metrics.LeaseTransferCount.Inc(1)
 
-		// Finally, update our local copies of the descriptors so that if
-		// additional transfers are needed we'll be making the decisions with more
-		// up-to-date info. The StorePool copies are updated by transferLease.
-		localDesc.Capacity.LeaseCount--
-		localDesc.Capacity.QueriesPerSecond -= replWithStats.qps
-		if otherDesc := storeMap[target.StoreID]; otherDesc != nil {
-			otherDesc.Capacity.LeaseCount++
-			otherDesc.Capacity.QueriesPerSecond += replWithStats.qps
+		if !(obj.storeLoad(localDesc.Capacity) > maxLoad) {
+			log.Infof(ctx,
+				"load-based lease transfers successfully brought s%d down to %.2f %s (mean=%.2f, upperThreshold=%.2f)",
+				localDesc.StoreID, obj.storeLoad(localDesc.Capacity), unit, meanLoad, maxLoad)
+			return
 		}
 	}
 
-	if !(localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold) {
-		log.Infof(ctx,
-			"load-based lease transfers successfully brought s%d down to %.2f qps (mean=%.2f, upperThreshold=%.2f)",
-			localDesc.StoreID, localDesc.Capacity.QueriesPerSecond, storeList.candidateQueriesPerSecond.mean, qpsMaxThreshold)
-		return
-	}
-
 	if mode != LBRebalancingLeasesAndReplicas {
 		log.Infof(ctx,
-			"ran out of leases worth transferring and qps (%.2f) is still above desired threshold (%.2f)",
-			localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold)
+			"ran out of leases worth transferring and load (%.2f %s) is still above desired threshold (%.2f)",
+			obj.storeLoad(localDesc.Capacity), unit, maxLoad)
 		return
 	}
 	log.Infof(ctx,
-		"ran out of leases worth transferring and qps (%.2f) is still above desired threshold (%.2f); considering load-based replica rebalances",
-		localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold)
+		"ran out of leases worth transferring and load (%.2f %s) is still above desired threshold (%.2f); considering load-based replica rebalances",
+		obj.storeLoad(localDesc.Capacity), unit, maxLoad)
 
 	// Re-combine replicasToMaybeRebalance with what remains of hottestRanges so
 	// that we'll reconsider them for replica rebalancing.
 	replicasToMaybeRebalance = append(replicasToMaybeRebalance, hottestRanges...)
 
-	for localDesc.Capacity.QueriesPerSecond > qpsMaxThreshold {
+	for obj.storeLoad(localDesc.Capacity) > maxLoad {
 		replWithStats, voterTargets := sr.chooseReplicaToRebalance(
 			ctx,
+			obj,
 			&replicasToMaybeRebalance,
 			localDesc,
 			storeList,
 			storeMap,
-			qpsMinThreshold,
-			qpsMaxThreshold)
+			minLoad,
+			maxLoad)
 		if replWithStats.repl == nil {
 			log.Infof(ctx,
-				"ran out of replicas worth transferring and qps (%.2f) is still above desired threshold (%.2f); will check again soon",
-				localDesc.Capacity.QueriesPerSecond, qpsMaxThreshold)
+				"ran out of replicas worth transferring and load (%.2f %s) is still above desired threshold (%.2f); will check again soon",
+				obj.storeLoad(localDesc.Capacity), unit, maxLoad)
 			return
 		}
 
+		replLoad := obj.replicaLoad(replWithStats)
 		descBeforeRebalance := replWithStats.repl.Desc()
-		log.VEventf(ctx, 1, "rebalancing r%d (%.2f qps) from %v to %v to better balance load",
-			replWithStats.repl.RangeID, replWithStats.qps, descBeforeRebalance.Replicas(), voterTargets)
-		timeout := sr.rq.processTimeoutFunc(sr.st, replWithStats.repl)
-		if err := contextutil.RunWithTimeout(ctx, "relocate range", timeout, func(ctx context.Context) error {
-			// TODO(aayush): Fix when we can make decisions about rebalancing non-voting replicas.
-			return sr.rq.store.AdminRelocateRange(ctx, *descBeforeRebalance, voterTargets, []roachpb.ReplicationTarget{})
-		}); err != nil {
+		log.VEventf(ctx, 1, "rebalancing r%d (%.2f %s) from %v to %v to better balance load",
+			replWithStats.repl.RangeID, replLoad, unit, descBeforeRebalance.Replicas(), voterTargets)
+		// TODO(aayush): Fix when we can make decisions about rebalancing non-voting replicas.
+		nonVoterTargets := []roachpb.ReplicationTarget{}
+		if err := sr.relocateRangeFn(
+			ctx, replWithStats.repl, *descBeforeRebalance, voterTargets, nonVoterTargets,
+		); err != nil {
 			log.Errorf(ctx, "unable to relocate range to %v: %+v", voterTargets, err)
 			continue
 		}
@@ -331,39 +547,49 @@ func (sr *StoreRebalancer) rebalanceStore(
 		for i := range replicasBeforeRebalance {
 			if storeDesc := storeMap[replicasBeforeRebalance[i].StoreID]; storeDesc != nil {
 				storeDesc.Capacity.RangeCount--
+				if !obj.followsLease() && storeDesc != localDesc {
+					// Load that doesn't follow the lease is incurred by every
+					// replica, so it is removed from all the existing replicas
+					// here and added back to all the targets below.
+					obj.addStoreLoad(&storeDesc.Capacity, -replLoad)
+				}
 			}
 		}
 		localDesc.Capacity.LeaseCount--
-		localDesc.Capacity.QueriesPerSecond -= replWithStats.qps
+		obj.addStoreLoad(&localDesc.Capacity, -replLoad)
 		for i := range voterTargets {
 			if storeDesc := storeMap[voterTargets[i].StoreID]; storeDesc != nil {
 				storeDesc.Capacity.RangeCount++
 				if i == 0 {
 					storeDesc.Capacity.LeaseCount++
-					storeDesc.Capacity.QueriesPerSecond += replWithStats.qps
+				}
+				if i == 0 || !obj.followsLease() {
+					obj.addStoreLoad(&storeDesc.Capacity, replLoad)
 				}
 			}
 		}
 	}
 
 	log.Infof(ctx,
-		"load-based replica transfers successfully brought s%d down to %.2f qps (mean=%.2f, upperThreshold=%.2f)",
-		localDesc.StoreID, localDesc.Capacity.QueriesPerSecond, storeList.candidateQueriesPerSecond.mean, qpsMaxThreshold)
+		"load-based replica transfers successfully brought s%d down to %.2f %s (mean=%.2f, upperThreshold=%.2f)",
+		localDesc.StoreID, obj.storeLoad(localDesc.Capacity), unit, meanLoad, maxLoad)
 }
 
 // TODO(a-robinson): Should we take the number of leases on each store into
 // account here or just continue to let that happen in allocator.go?
 func (sr *StoreRebalancer) chooseLeaseToTransfer(
 	ctx context.Context,
+	obj LBRebalancingObjective,
 	hottestRanges *[]replicaWithStats,
 	localDesc *roachpb.StoreDescriptor,
 	storeList StoreList,
 	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor,
-	minQPS float64,
-	maxQPS float64,
+	minLoad float64,
+	maxLoad float64,
 ) (replicaWithStats, roachpb.ReplicaDescriptor, []replicaWithStats) {
 	var considerForRebalance []replicaWithStats
 	now := sr.rq.store.Clock().NowAsClockTimestamp()
+	unit := obj.unit()
 	for {
 		if len(*hottestRanges) == 0 {
 			return replicaWithStats{}, roachpb.ReplicaDescriptor{}, considerForRebalance
@@ -376,39 +602,40 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 			return replicaWithStats{}, roachpb.ReplicaDescriptor{}, considerForRebalance
 		}
 
-		if shouldNotMoveAway(ctx, replWithStats, localDesc, now, minQPS) {
+		if shouldNotMoveAway(ctx, obj, replWithStats, localDesc, now, minLoad) {
 			continue
 		}
 
-		// Don't bother moving leases whose QPS is below some small fraction of the
-		// store's QPS (unless the store has extra leases to spare anyway). It's
-		// just unnecessary churn with no benefit to move leases responsible for,
-		// for example, 1 qps on a store with 5000 qps.
-		const minQPSFraction = .001
-		if replWithStats.qps < localDesc.Capacity.QueriesPerSecond*minQPSFraction &&
+		// Don't bother moving leases whose load is below some small fraction of
+		// the store's load (unless the store has extra leases to spare anyway).
+		// It's just unnecessary churn with no benefit to move leases responsible
+		// for, for example, 1 qps on a store with 5000 qps.
+		const minLoadFraction = .001
+		replLoad := obj.replicaLoad(replWithStats)
+		if replLoad < obj.storeLoad(localDesc.Capacity)*minLoadFraction &&
 			float64(localDesc.Capacity.LeaseCount) <= storeList.candidateLeases.mean {
-			log.VEventf(ctx, 5, "r%d's %.2f qps is too little to matter relative to s%d's %.2f total qps",
-				replWithStats.repl.RangeID, replWithStats.qps, localDesc.StoreID, localDesc.Capacity.QueriesPerSecond)
+			log.VEventf(ctx, 5, "r%d's %.2f %s is too little to matter relative to s%d's %.2f %s total",
+				replWithStats.repl.RangeID, replLoad, unit, localDesc.StoreID, obj.storeLoad(localDesc.Capacity), unit)
 			continue
 		}
 
 		desc, zone := replWithStats.repl.DescAndZone()
-		log.VEventf(ctx, 3, "considering lease transfer for r%d with %.2f qps",
-			desc.RangeID, replWithStats.qps)
+		log.VEventf(ctx, 3, "considering lease transfer for r%d with %.2f %s",
+			desc.RangeID, replLoad, unit)
 
-		// Check all the other replicas in order of increasing qps. Learner replicas
-		// aren't allowed to become the leaseholder or raft leader, so only consider
-		// the `Voters` replicas.
-		candidates := desc.Replicas().DeepCopy().VoterDescriptors()
+		// Check all the other replicas in order of increasing load. Learner and
+		// witness replicas aren't allowed to become the leaseholder, so only
+		// consider the `DataVoterDescriptors` replicas.
+		candidates := desc.Replicas().DeepCopy().DataVoterDescriptors()
 		sort.Slice(candidates, func(i, j int) bool {
-			var iQPS, jQPS float64
+			var iLoad, jLoad float64
 			if desc := storeMap[candidates[i].StoreID]; desc != nil {
-				iQPS = desc.Capacity.QueriesPerSecond
+				iLoad = obj.storeLoad(desc.Capacity)
 			}
 			if desc := storeMap[candidates[j].StoreID]; desc != nil {
-				jQPS = desc.Capacity.QueriesPerSecond
+				jLoad = obj.storeLoad(desc.Capacity)
 			}
-			return iQPS < jQPS
+			return iLoad < jLoad
 		})
 
 		var raftStatus *raft.Status
@@ -419,8 +646,8 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 				continue
 			}
 
-			meanQPS := storeList.candidateQueriesPerSecond.mean
-			if sr.shouldNotMoveTo(ctx, storeMap, replWithStats, candidate.StoreID, meanQPS, minQPS, maxQPS) {
+			meanLoad := obj.meanLoad(storeList)
+			if sr.shouldNotMoveTo(ctx, obj, storeMap, replWithStats, candidate.StoreID, meanLoad, minLoad, maxLoad) {
 				continue
 			}
 
@@ -464,14 +691,16 @@ func (sr *StoreRebalancer) chooseLeaseToTransfer(
 
 func (sr *StoreRebalancer) chooseReplicaToRebalance(
 	ctx context.Context,
+	obj LBRebalancingObjective,
 	hottestRanges *[]replicaWithStats,
 	localDesc *roachpb.StoreDescriptor,
 	storeList StoreList,
 	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor,
-	minQPS float64,
-	maxQPS float64,
+	minLoad float64,
+	maxLoad float64,
 ) (replicaWithStats, []roachpb.ReplicationTarget) {
 	now := sr.rq.store.Clock().NowAsClockTimestamp()
+	unit := obj.unit()
 	for {
 		if len(*hottestRanges) == 0 {
 			return replicaWithStats{}, nil
@@ -483,25 +712,33 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 			return replicaWithStats{}, nil
 		}
 
-		if shouldNotMoveAway(ctx, replWithStats, localDesc, now, minQPS) {
+		if shouldNotMoveAway(ctx, obj, replWithStats, localDesc, now, minLoad) {
 			continue
 		}
 
-		// Don't bother moving ranges whose QPS is below some small fraction of the
-		// store's QPS (unless the store has extra ranges to spare anyway). It's
-		// just unnecessary churn with no benefit to move ranges responsible for,
-		// for example, 1 qps on a store with 5000 qps.
-		const minQPSFraction = .001
-		if replWithStats.qps < localDesc.Capacity.QueriesPerSecond*minQPSFraction &&
+		// Don't bother moving ranges whose load is below some small fraction of
+		// the store's load (unless the store has extra ranges to spare anyway).
+		// It's just unnecessary churn with no benefit to move ranges responsible
+		// for, for example, 1 qps on a store with 5000 qps.
+		const minLoadFraction = .001
+		replLoad := obj.replicaLoad(replWithStats)
+		if replLoad < obj.storeLoad(localDesc.Capacity)*minLoadFraction &&
 			float64(localDesc.Capacity.RangeCount) <= storeList.candidateRanges.mean {
-			log.VEventf(ctx, 5, "r%d's %.2f qps is too little to matter relative to s%d's %.2f total qps",
-				replWithStats.repl.RangeID, replWithStats.qps, localDesc.StoreID, localDesc.Capacity.QueriesPerSecond)
+			log.VEventf(ctx, 5, "r%d's %.2f %s is too little to matter relative to s%d's %.2f %s total",
+				replWithStats.repl.RangeID, replLoad, unit, localDesc.StoreID, obj.storeLoad(localDesc.Capacity), unit)
 			continue
 		}
 
 		desc, zone := replWithStats.repl.DescAndZone()
-		log.VEventf(ctx, 3, "considering replica rebalance for r%d with %.2f qps",
-			desc.RangeID, replWithStats.qps)
+		log.VEventf(ctx, 3, "considering replica rebalance for r%d with %.2f %s",
+			desc.RangeID, replLoad, unit)
+
+		// Witnesses don't serve any load and AdminRelocateRange doesn't move
+		// them, so leave ranges that have any to the replicate queue.
+		if len(desc.Replicas().WitnessDescriptors()) > 0 {
+			log.VEventf(ctx, 3, "r%d has witness replicas, not rebalancing", desc.RangeID)
+			continue
+		}
 
 		clusterNodes := sr.rq.allocator.storePool.ClusterNodeCount()
 		desiredReplicas := GetNeededReplicas(*zone.NumReplicas, clusterNodes)
@@ -510,7 +747,7 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 		currentReplicas := desc.Replicas().Descriptors()
 
 		// Check the range's existing diversity score, since we want to ensure we
-		// don't hurt locality diversity just to improve QPS.
+		// don't hurt locality diversity just to improve load.
 		curDiversity := rangeDiversityScore(
 			sr.rq.allocator.storePool.getLocalitiesByStore(currentReplicas))
 
@@ -519,15 +756,15 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 			if currentReplicas[i].StoreID == localDesc.StoreID {
 				continue
 			}
-			// Keep the replica in the range if we don't know its QPS or if its QPS
-			// is below the upper threshold. Punishing stores not in our store map
-			// could cause mass evictions if the storePool gets out of sync.
+			// Keep the replica in the range if we don't know its load or if its
+			// load is below the upper threshold. Punishing stores not in our store
+			// map could cause mass evictions if the storePool gets out of sync.
 			storeDesc, ok := storeMap[currentReplicas[i].StoreID]
-			if !ok || storeDesc.Capacity.QueriesPerSecond < maxQPS {
+			if !ok || obj.storeLoad(storeDesc.Capacity) < maxLoad {
 				if log.V(3) {
 					var reason redact.RedactableString
 					if ok {
-						reason = redact.Sprintf(" (qps %.2f vs max %.2f)", storeDesc.Capacity.QueriesPerSecond, maxQPS)
+						reason = redact.Sprintf(" (%s %.2f vs max %.2f)", unit, obj.storeLoad(storeDesc.Capacity), maxLoad)
 					}
 					log.VEventf(ctx, 3, "keeping r%d/%d on s%d%s", desc.RangeID, currentReplicas[i].ReplicaID, currentReplicas[i].StoreID, reason)
 				}
@@ -544,7 +781,8 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 
 		// Then pick out which new stores to add the remaining replicas to.
 		options := sr.rq.allocator.scorerOptions()
-		options.qpsRebalanceThreshold = qpsRebalanceThreshold.Get(&sr.st.SV)
+		options.loadRebalanceThreshold = obj.thresholdFraction(&sr.st.SV)
+		options.loadObjective = obj
 		for len(targets) < desiredReplicas {
 			// Use the preexisting AllocateTarget logic to ensure that considerations
 			// such as zone constraints, locality diversity, and full disk come
@@ -562,8 +800,8 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 				break
 			}
 
-			meanQPS := storeList.candidateQueriesPerSecond.mean
-			if sr.shouldNotMoveTo(ctx, storeMap, replWithStats, target.StoreID, meanQPS, minQPS, maxQPS) {
+			meanLoad := obj.meanLoad(storeList)
+			if sr.shouldNotMoveTo(ctx, obj, storeMap, replWithStats, target.StoreID, meanLoad, minLoad, maxLoad) {
 				break
 			}
 
@@ -582,7 +820,7 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 		// TODO(a-robinson): Support more incremental improvements -- move what we
 		// can if it makes things better even if it isn't great. For example,
 		// moving one of the other existing replicas that's on a store with less
-		// qps than the max threshold but above the mean would help in certain
+		// load than the max threshold but above the mean would help in certain
 		// locality configurations.
 		if len(targets) < desiredReplicas {
 			log.VEventf(ctx, 3, "couldn't find enough rebalance targets for r%d (%d/%d)",
@@ -597,10 +835,10 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 			continue
 		}
 
-		// Pick the replica with the least QPS to be leaseholder;
+		// Pick the replica with the least load to be leaseholder;
 		// RelocateRange transfers the lease to the first provided target.
 		newLeaseIdx := 0
-		newLeaseQPS := math.MaxFloat64
+		newLeaseLoad := math.MaxFloat64
 		var raftStatus *raft.Status
 		for i := 0; i < len(targets); i++ {
 			// Ensure we don't transfer the lease to an existing replica that is behind
@@ -615,9 +853,9 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 			}
 
 			storeDesc, ok := storeMap[targets[i].StoreID]
-			if ok && storeDesc.Capacity.QueriesPerSecond < newLeaseQPS {
+			if ok && obj.storeLoad(storeDesc.Capacity) < newLeaseLoad {
 				newLeaseIdx = i
-				newLeaseQPS = storeDesc.Capacity.QueriesPerSecond
+				newLeaseLoad = obj.storeLoad(storeDesc.Capacity)
 			}
 		}
 		targets[0], targets[newLeaseIdx] = targets[newLeaseIdx], targets[0]
@@ -627,18 +865,20 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 
 func shouldNotMoveAway(
 	ctx context.Context,
+	obj LBRebalancingObjective,
 	replWithStats replicaWithStats,
 	localDesc *roachpb.StoreDescriptor,
 	now hlc.ClockTimestamp,
-	minQPS float64,
+	minLoad float64,
 ) bool {
 	if !replWithStats.repl.OwnsValidLease(ctx, now) {
 		log.VEventf(ctx, 3, "store doesn't own the lease for r%d", replWithStats.repl.RangeID)
 		return true
 	}
-	if localDesc.Capacity.QueriesPerSecond-replWithStats.qps < minQPS {
-		log.VEventf(ctx, 3, "moving r%d's %.2f qps would bring s%d below the min threshold (%.2f)",
-			replWithStats.repl.RangeID, replWithStats.qps, localDesc.StoreID, minQPS)
+	replLoad := obj.replicaLoad(replWithStats)
+	if obj.storeLoad(localDesc.Capacity)-replLoad < minLoad {
+		log.VEventf(ctx, 3, "moving r%d's %.2f %s would bring s%d below the min threshold (%.2f)",
+			replWithStats.repl.RangeID, replLoad, obj.unit(), localDesc.StoreID, minLoad)
 		return true
 	}
 	return false
@@ -646,12 +886,13 @@ func shouldNotMoveAway(
 
 func (sr *StoreRebalancer) shouldNotMoveTo(
 	ctx context.Context,
+	obj LBRebalancingObjective,
 	storeMap map[roachpb.StoreID]*roachpb.StoreDescriptor,
 	replWithStats replicaWithStats,
 	candidateStore roachpb.StoreID,
-	meanQPS float64,
-	minQPS float64,
-	maxQPS float64,
+	meanLoad float64,
+	minLoad float64,
+	maxLoad float64,
 ) bool {
 	storeDesc, ok := storeMap[candidateStore]
 	if !ok {
@@ -659,18 +900,21 @@ func (sr *StoreRebalancer) shouldNotMoveTo(
 		return true
 	}
 
-	newCandidateQPS := storeDesc.Capacity.QueriesPerSecond + replWithStats.qps
-	if storeDesc.Capacity.QueriesPerSecond < minQPS {
-		if newCandidateQPS > maxQPS {
+	unit := obj.unit()
+	replLoad := obj.replicaLoad(replWithStats)
+	candidateLoad := obj.storeLoad(storeDesc.Capacity)
+	newCandidateLoad := candidateLoad + replLoad
+	if candidateLoad < minLoad {
+		if newCandidateLoad > maxLoad {
 			log.VEventf(ctx, 3,
-				"r%d's %.2f qps would push s%d over the max threshold (%.2f) with %.2f qps afterwards",
-				replWithStats.repl.RangeID, replWithStats.qps, candidateStore, maxQPS, newCandidateQPS)
+				"r%d's %.2f %s would push s%d over the max threshold (%.2f) with %.2f %s afterwards",
+				replWithStats.repl.RangeID, replLoad, unit, candidateStore, maxLoad, newCandidateLoad, unit)
 			return true
 		}
-	} else if newCandidateQPS > meanQPS {
+	} else if newCandidateLoad > meanLoad {
 		log.VEventf(ctx, 3,
-			"r%d's %.2f qps would push s%d over the mean (%.2f) with %.2f qps afterwards",
-			replWithStats.repl.RangeID, replWithStats.qps, candidateStore, meanQPS, newCandidateQPS)
+			"r%d's %.2f %s would push s%d over the mean (%.2f) with %.2f %s afterwards",
+			replWithStats.repl.RangeID, replLoad, unit, candidateStore, meanLoad, newCandidateLoad, unit)
 		return true
 	}
 
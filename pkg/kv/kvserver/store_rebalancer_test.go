@@ -69,12 +69,75 @@ var (
 			},
 		},
 	}
+
+	// cpuImbalancedStores specifies a set of stores where QPS is balanced but
+	// s5 is under-utilized in terms of evaluation time, s2-s4 are in the middle, and s1 is
+	// over-utilized.
+	cpuImbalancedStores = []*roachpb.StoreDescriptor{
+		{
+			StoreID:  1,
+			Node:     roachpb.NodeDescriptor{NodeID: 1},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, EvalNanosPerSecond: 1.5e9},
+		},
+		{
+			StoreID:  2,
+			Node:     roachpb.NodeDescriptor{NodeID: 2},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, EvalNanosPerSecond: 1.1e9},
+		},
+		{
+			StoreID:  3,
+			Node:     roachpb.NodeDescriptor{NodeID: 3},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, EvalNanosPerSecond: 1e9},
+		},
+		{
+			StoreID:  4,
+			Node:     roachpb.NodeDescriptor{NodeID: 4},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, EvalNanosPerSecond: 0.9e9},
+		},
+		{
+			StoreID:  5,
+			Node:     roachpb.NodeDescriptor{NodeID: 5},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, EvalNanosPerSecond: 0.5e9},
+		},
+	}
+
+	// writeBytesImbalancedStores is like cpuImbalancedStores, but for the
+	// bytes written per second.
+	writeBytesImbalancedStores = []*roachpb.StoreDescriptor{
+		{
+			StoreID:  1,
+			Node:     roachpb.NodeDescriptor{NodeID: 1},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, WriteBytesPerSecond: 15 << 20},
+		},
+		{
+			StoreID:  2,
+			Node:     roachpb.NodeDescriptor{NodeID: 2},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, WriteBytesPerSecond: 11 << 20},
+		},
+		{
+			StoreID:  3,
+			Node:     roachpb.NodeDescriptor{NodeID: 3},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, WriteBytesPerSecond: 10 << 20},
+		},
+		{
+			StoreID:  4,
+			Node:     roachpb.NodeDescriptor{NodeID: 4},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, WriteBytesPerSecond: 9 << 20},
+		},
+		{
+			StoreID:  5,
+			Node:     roachpb.NodeDescriptor{NodeID: 5},
+			Capacity: roachpb.StoreCapacity{QueriesPerSecond: 1000, WriteBytesPerSecond: 5 << 20},
+		},
+	}
 )
 
 type testRange struct {
 	// The first storeID in the list will be the leaseholder.
-	storeIDs []roachpb.StoreID
-	qps      float64
+	storeIDs   []roachpb.StoreID
+	qps        float64
+	evalTime   float64
+	writeBytes float64
 }
 
 func loadRanges(rr *replicaRankings, s *Store, ranges []testRange) {
@@ -99,9 +162,12 @@ func loadRanges(rr *replicaRankings, s *Store, ranges []testRange) {
 		repl.mu.state.Stats = &enginepb.MVCCStats{}
 		repl.leaseholderStats = newReplicaStats(s.Clock(), nil)
 		repl.writeStats = newReplicaStats(s.Clock(), nil)
+		repl.writeBytesStats = newReplicaStats(s.Clock(), nil)
 		acc.addReplica(replicaWithStats{
-			repl: repl,
-			qps:  r.qps,
+			repl:       repl,
+			qps:        r.qps,
+			evalTime:   r.evalTime,
+			writeBytes: r.writeBytes,
 		})
 	}
 	rr.update(acc)
@@ -183,7 +249,7 @@ func TestChooseLeaseToTransfer(t *testing.T) {
 		loadRanges(rr, s, []testRange{{storeIDs: tc.storeIDs, qps: tc.qps}})
 		hottestRanges := rr.topQPS()
 		_, target, _ := sr.chooseLeaseToTransfer(
-			ctx, &hottestRanges, &localDesc, storeList, storeMap, minQPS, maxQPS)
+			ctx, LBRebalancingQueries, &hottestRanges, &localDesc, storeList, storeMap, minQPS, maxQPS)
 		if target.StoreID != tc.expectTarget {
 			t.Errorf("got target store %d for range with replicas %v and %f qps; want %d",
 				target.StoreID, tc.storeIDs, tc.qps, tc.expectTarget)
@@ -295,7 +361,7 @@ func TestChooseReplicaToRebalance(t *testing.T) {
 			loadRanges(rr, s, []testRange{{storeIDs: tc.storeIDs, qps: tc.qps}})
 			hottestRanges := rr.topQPS()
 			_, targets := sr.chooseReplicaToRebalance(
-				ctx, &hottestRanges, &localDesc, storeList, storeMap, minQPS, maxQPS)
+				ctx, LBRebalancingQueries, &hottestRanges, &localDesc, storeList, storeMap, minQPS, maxQPS)
 
 			if len(targets) != len(tc.expectTargets) {
 				t.Fatalf("chooseReplicaToRebalance(existing=%v, qps=%f) got %v; want %v",
@@ -380,7 +446,7 @@ func TestNoLeaseTransferToBehindReplicas(t *testing.T) {
 	}
 
 	_, target, _ := sr.chooseLeaseToTransfer(
-		ctx, &hottestRanges, &localDesc, storeList, storeMap, minQPS, maxQPS)
+		ctx, LBRebalancingQueries, &hottestRanges, &localDesc, storeList, storeMap, minQPS, maxQPS)
 	expectTarget := roachpb.StoreID(4)
 	if target.StoreID != expectTarget {
 		t.Errorf("got target store s%d for range with RaftStatus %v; want s%d",
@@ -395,7 +461,7 @@ func TestNoLeaseTransferToBehindReplicas(t *testing.T) {
 	repl = hottestRanges[0].repl
 
 	_, targets := sr.chooseReplicaToRebalance(
-		ctx, &hottestRanges, &localDesc, storeList, storeMap, minQPS, maxQPS)
+		ctx, LBRebalancingQueries, &hottestRanges, &localDesc, storeList, storeMap, minQPS, maxQPS)
 	expectTargets := []roachpb.ReplicationTarget{
 		{NodeID: 4, StoreID: 4}, {NodeID: 5, StoreID: 5}, {NodeID: 3, StoreID: 3},
 	}
@@ -404,3 +470,137 @@ func TestNoLeaseTransferToBehindReplicas(t *testing.T) {
 			targets, sr.getRaftStatusFn(repl), expectTargets)
 	}
 }
+
+// newTestStoreRebalancer sets up a StoreRebalancer for the first of the
+// provided stores, with a fake raft status that reports all replicas as up
+// to date. The returned stopper must be stopped by the caller.
+func newTestStoreRebalancer(
+	t *testing.T, stores []*roachpb.StoreDescriptor,
+) (*stop.Stopper, *StoreRebalancer, *Store, *replicaRankings, StoreList) {
+	stopper, g, _, a, _ := createTestAllocator(10, false /* deterministic */)
+	gossiputil.NewStoreGossiper(g).GossipStores(stores, t)
+	storeList, _, _ := a.storePool.getStoreList(storeFilterThrottled)
+
+	cfg := TestStoreConfig(nil)
+	s := createTestStoreWithoutStart(t, stopper, testStoreOpts{createSystemRanges: true}, &cfg)
+	s.Ident = &roachpb.StoreIdent{StoreID: stores[0].StoreID}
+	rq := newReplicateQueue(s, g, a)
+	rr := newReplicaRankings()
+
+	sr := NewStoreRebalancer(cfg.AmbientCtx, cfg.Settings, rq, rr)
+	sr.getRaftStatusFn = func(r *Replica) *raft.Status {
+		status := &raft.Status{
+			Progress: make(map[uint64]tracker.Progress),
+		}
+		status.Lead = uint64(r.ReplicaID())
+		status.Commit = 1
+		for _, replica := range r.Desc().InternalReplicas {
+			status.Progress[uint64(replica.ReplicaID)] = tracker.Progress{
+				Match: 1,
+				State: tracker.StateReplicate,
+			}
+		}
+		return status
+	}
+	return stopper, sr, s, rr, storeList
+}
+
+func TestChooseLeaseToTransferByEvalTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	stopper, sr, s, rr, storeList := newTestStoreRebalancer(t, cpuImbalancedStores)
+	defer stopper.Stop(ctx)
+	storeMap := storeListToMap(storeList)
+	localDesc := *cpuImbalancedStores[0]
+
+	const minEvalTime = 0.8e9
+	const maxEvalTime = 1.2e9
+
+	testCases := []struct {
+		storeIDs     []roachpb.StoreID
+		evalTime     float64
+		expectTarget roachpb.StoreID
+	}{
+		{[]roachpb.StoreID{1}, 0.2e9, 0},
+		{[]roachpb.StoreID{1, 5}, 0.2e9, 5},
+		{[]roachpb.StoreID{1, 4}, 0.1e9, 4},
+		// Moving the lease would push s4 over the mean.
+		{[]roachpb.StoreID{1, 4}, 0.2e9, 0},
+		{[]roachpb.StoreID{1, 2}, 0.2e9, 0},
+		// Moving the lease would bring s1 below the min threshold.
+		{[]roachpb.StoreID{1, 5}, 0.8e9, 0},
+		{[]roachpb.StoreID{5, 1}, 0.2e9, 0},
+	}
+
+	for _, tc := range testCases {
+		loadRanges(rr, s, []testRange{{storeIDs: tc.storeIDs, qps: 10, evalTime: tc.evalTime}})
+		hottestRanges := rr.topEvalTime()
+		_, target, _ := sr.chooseLeaseToTransfer(
+			ctx, LBRebalancingEvalTime, &hottestRanges, &localDesc, storeList, storeMap, minEvalTime, maxEvalTime)
+		if target.StoreID != tc.expectTarget {
+			t.Errorf("got target store %d for range with replicas %v and %f eval-ns/s; want %d",
+				target.StoreID, tc.storeIDs, tc.evalTime, tc.expectTarget)
+		}
+	}
+
+	// The stores are balanced in terms of QPS, so balancing QPS doesn't move
+	// the lease.
+	loadRanges(rr, s, []testRange{{storeIDs: []roachpb.StoreID{1, 5}, qps: 10, evalTime: 0.2e9}})
+	hottestRanges := rr.topQPS()
+	_, target, _ := sr.chooseLeaseToTransfer(
+		ctx, LBRebalancingQueries, &hottestRanges, &localDesc, storeList, storeMap, 800, 1200)
+	if target.StoreID != 0 {
+		t.Errorf("got target store %d when balancing qps; want none", target.StoreID)
+	}
+}
+
+func TestChooseReplicaToRebalanceByWriteBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	stopper, sr, s, rr, storeList := newTestStoreRebalancer(t, writeBytesImbalancedStores)
+	defer stopper.Stop(ctx)
+	storeMap := storeListToMap(storeList)
+	localDesc := *writeBytesImbalancedStores[0]
+
+	const minWriteBytes = 8 << 20
+	const maxWriteBytes = 12 << 20
+
+	testCases := []struct {
+		storeIDs      []roachpb.StoreID
+		writeBytes    float64
+		expectTargets []roachpb.StoreID
+	}{
+		{[]roachpb.StoreID{1}, 1 << 20, []roachpb.StoreID{5}},
+		{[]roachpb.StoreID{1}, 4 << 20, []roachpb.StoreID{5}},
+		// Moving the replica would bring s1 below the min threshold.
+		{[]roachpb.StoreID{1}, 8 << 20, nil},
+		{[]roachpb.StoreID{1, 3}, 1 << 20, []roachpb.StoreID{5, 3}},
+	}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			s.cfg.DefaultZoneConfig.NumReplicas = proto.Int32(int32(len(tc.storeIDs)))
+			loadRanges(rr, s, []testRange{{storeIDs: tc.storeIDs, qps: 10, writeBytes: tc.writeBytes}})
+			hottestRanges := rr.topWriteBytes()
+			_, targets := sr.chooseReplicaToRebalance(
+				ctx, LBRebalancingWriteBytes, &hottestRanges, &localDesc, storeList, storeMap, minWriteBytes, maxWriteBytes)
+
+			targetStores := make([]roachpb.StoreID, len(targets))
+			for i, target := range targets {
+				targetStores[i] = target.StoreID
+			}
+			sort.Sort(roachpb.StoreIDSlice(targetStores))
+			expectTargets := append([]roachpb.StoreID(nil), tc.expectTargets...)
+			sort.Sort(roachpb.StoreIDSlice(expectTargets))
+			if len(targetStores) != len(expectTargets) ||
+				(len(targetStores) > 0 && !reflect.DeepEqual(targetStores, expectTargets)) {
+				t.Errorf("chooseReplicaToRebalance(existing=%v, writeBytes=%f) chose targets %v; want %v",
+					tc.storeIDs, tc.writeBytes, targetStores, tc.expectTargets)
+			}
+		})
+	}
+}
@@ -322,13 +322,17 @@ func (s *Store) SplitRange(
 	// Clear the original range's request stats, since they include requests for
 	// spans that are now owned by the new range.
 	leftRepl.leaseholderStats.resetRequestCounts()
+	leftRepl.evalStats.resetRequestCounts()
 
 	if rightReplOrNil == nil {
 		throwawayRightWriteStats := new(replicaStats)
 		leftRepl.writeStats.splitRequestCounts(throwawayRightWriteStats)
+		throwawayRightWriteBytesStats := new(replicaStats)
+		leftRepl.writeBytesStats.splitRequestCounts(throwawayRightWriteBytesStats)
 	} else {
 		rightRepl := rightReplOrNil
 		leftRepl.writeStats.splitRequestCounts(rightRepl.writeStats)
+		leftRepl.writeBytesStats.splitRequestCounts(rightRepl.writeBytesStats)
 		if err := s.addReplicaInternalLocked(rightRepl); err != nil {
 			return errors.Errorf("unable to add replica %v: %s", rightRepl, err)
 		}
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -134,6 +135,28 @@ func (ls *Stores) VisitStores(visitor func(s *Store) error) error {
 	return err
 }
 
+// GetStoreMetrics implements admission.StoreMetricsProvider. Stores whose
+// metrics can't be retrieved are omitted.
+func (ls *Stores) GetStoreMetrics() []admission.StoreMetrics {
+	var storeMetrics []admission.StoreMetrics
+	_ = ls.VisitStores(func(s *Store) error {
+		m, err := s.Engine().GetMetrics()
+		if err != nil {
+			log.Warningf(s.AnnotateCtx(context.Background()), "unable to get engine metrics: %v", err)
+			return nil
+		}
+		storeMetrics = append(storeMetrics, admission.StoreMetrics{
+			StoreID:         int32(s.StoreID()),
+			L0FileCount:     m.L0FileCount,
+			L0SublevelCount: m.L0SublevelCount,
+			L0BytesAdded:    m.FlushedBytes + m.L0BytesIngested,
+			L0Size:          m.L0Size,
+		})
+		return nil
+	})
+	return storeMetrics
+}
+
 // GetReplicaForRangeID returns the replica and store which contains the
 // specified range. If the replica is not found on any store then
 // roachpb.RangeNotFoundError will be returned.
@@ -82,6 +82,17 @@ func (m *MockTransactionalSender) SetUserPriority(pri roachpb.UserPriority) erro
 	return nil
 }
 
+// SetIsolationLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) SetIsolationLevel(isoLevel enginepb.IsolationLevel) error {
+	m.txn.IsoLevel = isoLevel
+	return nil
+}
+
+// IsolationLevel is part of the TxnSender interface.
+func (m *MockTransactionalSender) IsolationLevel() enginepb.IsolationLevel {
+	return m.txn.IsoLevel
+}
+
 // SetDebugName is part of the TxnSender interface.
 func (m *MockTransactionalSender) SetDebugName(name string) {
 	m.txn.Name = name
@@ -131,6 +142,9 @@ func (m *MockTransactionalSender) ManualRestart(
 	m.txn.Restart(pri, 0 /* upgradePriority */, ts)
 }
 
+// PrepareForRetry is part of the TxnSender interface.
+func (m *MockTransactionalSender) PrepareForRetry(context.Context) {}
+
 // IsSerializablePushAndRefreshNotPossible is part of the TxnSender interface.
 func (m *MockTransactionalSender) IsSerializablePushAndRefreshNotPossible() bool {
 	return false
@@ -187,6 +201,11 @@ func (m *MockTransactionalSender) Step(_ context.Context) error {
 	return nil
 }
 
+// StepReadTimestamp is part of the TxnSender interface.
+func (m *MockTransactionalSender) StepReadTimestamp(context.Context) error {
+	return nil
+}
+
 // ConfigureStepping is part of the TxnSender interface.
 func (m *MockTransactionalSender) ConfigureStepping(context.Context, SteppingMode) SteppingMode {
 	// See Step() above.
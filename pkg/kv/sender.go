@@ -126,6 +126,13 @@ type TxnSender interface {
 	// SetUserPriority sets the txn's priority.
 	SetUserPriority(roachpb.UserPriority) error
 
+	// SetIsolationLevel sets the txn's isolation level. It returns an error
+	// if the transaction is already active and the level differs.
+	SetIsolationLevel(enginepb.IsolationLevel) error
+
+	// IsolationLevel returns the txn's isolation level.
+	IsolationLevel() enginepb.IsolationLevel
+
 	// SetDebugName sets the txn's debug name.
 	SetDebugName(name string)
 
@@ -189,6 +196,13 @@ type TxnSender interface {
 	// TxnAttempt model.
 	ManualRestart(context.Context, roachpb.UserPriority, hlc.Timestamp)
 
+	// PrepareForRetry restarts a transaction whose restart was deferred after
+	// a retryable error, bumping its epoch. The restart of transactions that
+	// read from a new snapshot in each statement is deferred so that the
+	// client can instead retry only the failed statement by rolling back to a
+	// savepoint. The method is a no-op for all other transactions.
+	PrepareForRetry(context.Context)
+
 	// UpdateStateOnRemoteRetryableErr updates the txn in response to an
 	// error encountered when running a request through the txn.
 	UpdateStateOnRemoteRetryableErr(context.Context, *roachpb.Error) *roachpb.Error
@@ -276,6 +290,14 @@ type TxnSender interface {
 	// The method is idempotent.
 	Step(context.Context) error
 
+	// StepReadTimestamp establishes a new read snapshot for transactions
+	// whose isolation level permits each statement to observe the writes of
+	// transactions committed before it began (i.e. READ COMMITTED). The
+	// transaction's read timestamp is moved to the present. The method is a
+	// no-op for SERIALIZABLE transactions and for transactions whose commit
+	// timestamp is fixed.
+	StepReadTimestamp(context.Context) error
+
 	// ConfigureStepping sets the sequencing point behavior.
 	//
 	// Note that a Sender is initially in the non-stepping mode,
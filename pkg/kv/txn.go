@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
@@ -56,6 +57,10 @@ type Txn struct {
 		debugName    string
 		userPriority roachpb.UserPriority
 
+		// routingPolicy is the policy with which the txn's batches are routed
+		// to the replicas of the ranges they address.
+		routingPolicy roachpb.RoutingPolicy
+
 		// previousIDs holds the set of all previous IDs that the Txn's Proto has
 		// had across transaction aborts. This allows us to determine if a given
 		// response was meant for any incarnation of this transaction. This is
@@ -71,6 +76,10 @@ type Txn struct {
 		// The txn has to be committed by this deadline. A nil value indicates no
 		// deadline.
 		deadline *hlc.Timestamp
+
+		// replicatedLocking is set if the locks acquired by the txn's locking
+		// reads are to be replicated. See SetLockingDurability.
+		replicatedLocking bool
 	}
 }
 
@@ -155,6 +164,7 @@ func NewLeafTxn(
 	txn := &Txn{db: db, typ: LeafTxn, gatewayNodeID: gatewayNodeID}
 	txn.mu.ID = tis.Txn.ID
 	txn.mu.userPriority = roachpb.NormalUserPriority
+	txn.mu.routingPolicy = tis.RoutingPolicy
 	txn.mu.sender = db.factory.LeafTransactionalSender(tis)
 	return txn
 }
@@ -224,6 +234,55 @@ func (txn *Txn) SetUserPriority(userPriority roachpb.UserPriority) error {
 	return txn.mu.sender.SetUserPriority(userPriority)
 }
 
+// SetIsolationLevel sets the transaction's isolation level. Transactions
+// default to SERIALIZABLE isolation. The isolation level must be set before
+// any operations are performed on the transaction.
+func (txn *Txn) SetIsolationLevel(isoLevel enginepb.IsolationLevel) error {
+	if txn.typ != RootTxn {
+		panic(errors.AssertionFailedf("SetIsolationLevel() called on leaf txn"))
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.SetIsolationLevel(isoLevel)
+}
+
+// IsolationLevel returns the transaction's isolation level.
+func (txn *Txn) IsolationLevel() enginepb.IsolationLevel {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.IsolationLevel()
+}
+
+// SetLockingDurability sets the durability of the locks acquired by the
+// transaction's locking reads. With the Unreplicated durability (the default),
+// the locks are best-effort and are only held in the memory of the leaseholders
+// of the locked keys. With the Replicated durability, Shared and Upgrade locks
+// are written through Raft, so they survive lease transfers and node restarts.
+//
+// The durability is consulted by the users of the transaction when they build
+// locking read requests; it does not affect the locks already acquired.
+func (txn *Txn) SetLockingDurability(dur lock.Durability) {
+	if txn.typ != RootTxn {
+		panic(errors.AssertionFailedf("SetLockingDurability() called on leaf txn"))
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	txn.mu.replicatedLocking = dur == lock.Replicated
+}
+
+// LockingDurability returns the durability of the locks acquired by the
+// transaction's locking reads. See SetLockingDurability.
+func (txn *Txn) LockingDurability() lock.Durability {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	if txn.mu.replicatedLocking {
+		return lock.Replicated
+	}
+	return lock.Unreplicated
+}
+
 // TestingSetPriority sets the transaction priority. It is intended for
 // internal (testing) use only.
 func (txn *Txn) TestingSetPriority(priority enginepb.TxnPriority) {
@@ -244,6 +303,27 @@ func (txn *Txn) UserPriority() roachpb.UserPriority {
 	return txn.mu.userPriority
 }
 
+// SetRoutingPolicy sets the policy with which the transaction's batches are
+// routed. With RoutingPolicy_NEAREST, reads are sent to the nearest replica
+// of each range instead of its leaseholder, which is only safe if the caller
+// knows that those replicas can serve reads at the txn's timestamp.
+func (txn *Txn) SetRoutingPolicy(policy roachpb.RoutingPolicy) {
+	if txn.typ != RootTxn {
+		panic(errors.AssertionFailedf("SetRoutingPolicy() called on leaf txn"))
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	txn.mu.routingPolicy = policy
+}
+
+// RoutingPolicy returns the transaction's routing policy.
+func (txn *Txn) RoutingPolicy() roachpb.RoutingPolicy {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.routingPolicy
+}
+
 // SetDebugName sets the debug name associated with the transaction which will
 // appear in log files and the web UI.
 func (txn *Txn) SetDebugName(name string) {
@@ -879,6 +959,23 @@ func (txn *Txn) PrepareForRetry(ctx context.Context, err error) {
 	txn.commitTriggers = nil
 	log.VEventf(ctx, 2, "automatically retrying transaction: %s because of error: %s",
 		txn.DebugName(), err)
+	txn.RestartIfDeferred(ctx)
+}
+
+// RestartIfDeferred restarts the transaction if its restart was deferred
+// after a retryable error. See TxnSender.PrepareForRetry. Clients that handle
+// retryable errors of transactions that read from a new snapshot in each
+// statement must call it before retrying the transaction from the top, unless
+// they rolled back to a savepoint instead.
+func (txn *Txn) RestartIfDeferred(ctx context.Context) {
+	if txn.typ != RootTxn {
+		panic(errors.WithContextTags(
+			errors.AssertionFailedf("RestartIfDeferred() called on leaf txn"), ctx))
+	}
+
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	txn.mu.sender.PrepareForRetry(ctx)
 }
 
 // IsRetryableErrMeantForTxn returns true if err is a retryable
@@ -926,6 +1023,7 @@ func (txn *Txn) Send(
 	txn.mu.Lock()
 	requestTxnID := txn.mu.ID
 	sender := txn.mu.sender
+	ba.Header.RoutingPolicy = txn.mu.routingPolicy
 	txn.mu.Unlock()
 	br, pErr := txn.db.sendUsingSender(ctx, ba, sender)
 	if pErr == nil {
@@ -974,6 +1072,7 @@ func (txn *Txn) GetLeafTxnInputState(ctx context.Context) roachpb.LeafTxnInputSt
 	if err != nil {
 		log.Fatalf(ctx, "unexpected error from GetLeafTxnInputState(AnyTxnStatus): %s", err)
 	}
+	ts.RoutingPolicy = txn.mu.routingPolicy
 	return ts
 }
 
@@ -998,6 +1097,7 @@ func (txn *Txn) GetLeafTxnInputStateOrRejectClient(
 		txn.handleErrIfRetryableLocked(ctx, err)
 		return roachpb.LeafTxnInputState{}, err
 	}
+	tfs.RoutingPolicy = txn.mu.routingPolicy
 	return tfs, nil
 }
 
@@ -1258,6 +1358,16 @@ func (txn *Txn) Step(ctx context.Context) error {
 	return txn.mu.sender.Step(ctx)
 }
 
+// StepReadTimestamp moves the transaction's read timestamp to the present
+// if its isolation level calls for a fresh read snapshot in each statement.
+// It is a no-op for SERIALIZABLE transactions and for leaf transactions,
+// whose read timestamp is dictated by their root.
+func (txn *Txn) StepReadTimestamp(ctx context.Context) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.StepReadTimestamp(ctx)
+}
+
 // ConfigureStepping configures step-wise execution in the
 // transaction.
 func (txn *Txn) ConfigureStepping(ctx context.Context, mode SteppingMode) (prevMode SteppingMode) {
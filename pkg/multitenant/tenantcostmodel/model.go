@@ -0,0 +1,129 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package tenantcostmodel contains the model with which the resources consumed
+// by tenants are converted into request units (RUs), the abstract unit in which
+// tenants are metered.
+package tenantcostmodel
+
+import "github.com/cockroachdb/cockroach/pkg/roachpb"
+
+// RU stands for "Request Unit(s)"; the tenant cost model maps resource usage
+// to these abstract units.
+type RU float64
+
+// Config contains the cost model parameters. The values are controlled by
+// cluster settings.
+//
+// A KV request incurs a fixed cost for the request itself, a cost per byte
+// written by it and a cost per byte read by it. The cost of the CPU time used
+// by the SQL pods of a tenant is charged separately.
+type Config struct {
+	// KVReadRequest is the baseline cost of a KV read request.
+	KVReadRequest RU
+
+	// KVReadByte is the per-byte cost of a KV read.
+	KVReadByte RU
+
+	// KVWriteRequest is the baseline cost of a KV write request.
+	KVWriteRequest RU
+
+	// KVWriteByte is the per-byte cost of a KV write.
+	KVWriteByte RU
+
+	// PodCPUSecond is the cost of using a CPU second on the SQL pod.
+	PodCPUSecond RU
+}
+
+// KVReadCost calculates the cost of a KV read request that read the given
+// number of bytes.
+func (c *Config) KVReadCost(bytes int64) RU {
+	return c.KVReadRequest + RU(bytes)*c.KVReadByte
+}
+
+// KVWriteCost calculates the cost of a KV write request that wrote the given
+// number of bytes.
+func (c *Config) KVWriteCost(bytes int64) RU {
+	return c.KVWriteRequest + RU(bytes)*c.KVWriteByte
+}
+
+// PodCPUCost calculates the cost of the given number of CPU seconds used by a
+// SQL pod.
+func (c *Config) PodCPUCost(seconds float64) RU {
+	return RU(seconds) * c.PodCPUSecond
+}
+
+// RequestCost returns the portion of the cost of a KV batch that can be
+// determined before the batch is evaluated.
+func (c *Config) RequestCost(req RequestInfo) RU {
+	if req.isWrite {
+		return c.KVWriteCost(req.writeBytes)
+	}
+	return c.KVReadRequest
+}
+
+// ResponseCost returns the portion of the cost of a KV batch that can only be
+// determined after the batch is evaluated.
+func (c *Config) ResponseCost(resp ResponseInfo) RU {
+	return RU(resp.readBytes) * c.KVReadByte
+}
+
+// RequestInfo captures the request information that is used (together with
+// the cost model) to determine the portion of the cost that can be calculated
+// upfront.
+type RequestInfo struct {
+	isWrite    bool
+	writeBytes int64
+}
+
+// MakeRequestInfo extracts the relevant information from a BatchRequest.
+func MakeRequestInfo(ba *roachpb.BatchRequest) RequestInfo {
+	if !ba.IsWrite() {
+		return RequestInfo{isWrite: false}
+	}
+	var writeBytes int64
+	for i := range ba.Requests {
+		if swr, isSizedWrite := ba.Requests[i].GetInner().(roachpb.SizedWriteRequest); isSizedWrite {
+			writeBytes += swr.WriteBytes()
+		}
+	}
+	return RequestInfo{isWrite: true, writeBytes: writeBytes}
+}
+
+// IsWrite returns whether the request is a write.
+func (r RequestInfo) IsWrite() bool {
+	return r.isWrite
+}
+
+// WriteBytes returns the number of bytes written by the request.
+func (r RequestInfo) WriteBytes() int64 {
+	return r.writeBytes
+}
+
+// ResponseInfo captures the BatchResponse information that is used (together
+// with the cost model) to determine the portion of the cost that can only be
+// calculated after-the-fact.
+type ResponseInfo struct {
+	readBytes int64
+}
+
+// MakeResponseInfo extracts the relevant information from a BatchResponse.
+func MakeResponseInfo(br *roachpb.BatchResponse) ResponseInfo {
+	var readBytes int64
+	for i := range br.Responses {
+		readBytes += br.Responses[i].GetInner().Header().NumBytes
+	}
+	return ResponseInfo{readBytes: readBytes}
+}
+
+// ReadBytes returns the number of bytes read by the request.
+func (r ResponseInfo) ReadBytes() int64 {
+	return r.readBytes
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tenantcostmodel
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostModel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	readCostPerMB.Override(&st.SV, 1024*1024)
+	writeCostPerMB.Override(&st.SV, 2*1024*1024)
+	cfg := ConfigFromSettings(&st.SV)
+	require.Equal(t, RU(1), cfg.KVReadByte)
+	require.Equal(t, RU(2), cfg.KVWriteByte)
+
+	var read roachpb.BatchRequest
+	read.Add(roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */))
+	readReq := MakeRequestInfo(&read)
+	require.False(t, readReq.IsWrite())
+	require.Equal(t, cfg.KVReadRequest, cfg.RequestCost(readReq))
+
+	var readResp roachpb.BatchResponse
+	getResp := &roachpb.GetResponse{}
+	getResp.NumBytes = 10
+	readResp.Add(getResp)
+	respInfo := MakeResponseInfo(&readResp)
+	require.Equal(t, int64(10), respInfo.ReadBytes())
+	require.Equal(t, RU(10), cfg.ResponseCost(respInfo))
+
+	var write roachpb.BatchRequest
+	write.Add(roachpb.NewPut(roachpb.Key("a"), roachpb.MakeValueFromString("value")))
+	writeReq := MakeRequestInfo(&write)
+	require.True(t, writeReq.IsWrite())
+	require.Greater(t, writeReq.WriteBytes(), int64(0))
+	require.Equal(t, cfg.KVWriteRequest+RU(2*writeReq.WriteBytes()), cfg.RequestCost(writeReq))
+
+	require.Equal(t, RU(1.5)*cfg.PodCPUSecond, cfg.PodCPUCost(1.5))
+}
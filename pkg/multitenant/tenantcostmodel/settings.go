@@ -0,0 +1,63 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tenantcostmodel
+
+import "github.com/cockroachdb/cockroach/pkg/settings"
+
+// Settings for the cost model parameters. These determine the values for a
+// Config, as returned by ConfigFromSettings.
+var (
+	readRequestCost = settings.RegisterFloatSetting(
+		"tenant_cost_model.kv_read_request_cost",
+		"base cost of a read request in request units",
+		0.7,
+		settings.NonNegativeFloat,
+	)
+
+	readCostPerMB = settings.RegisterFloatSetting(
+		"tenant_cost_model.kv_read_cost_per_megabyte",
+		"cost of a read in request units per MB",
+		10.0,
+		settings.NonNegativeFloat,
+	)
+
+	writeRequestCost = settings.RegisterFloatSetting(
+		"tenant_cost_model.kv_write_request_cost",
+		"base cost of a write request in request units",
+		1.0,
+		settings.NonNegativeFloat,
+	)
+
+	writeCostPerMB = settings.RegisterFloatSetting(
+		"tenant_cost_model.kv_write_cost_per_megabyte",
+		"cost of a write in request units per MB",
+		400.0,
+		settings.NonNegativeFloat,
+	)
+
+	podCPUSecondCost = settings.RegisterFloatSetting(
+		"tenant_cost_model.pod_cpu_second_cost",
+		"cost of a CPU second on the tenant's SQL pods in request units",
+		1000.0,
+		settings.NonNegativeFloat,
+	)
+)
+
+// ConfigFromSettings constructs a Config using the cluster setting values.
+func ConfigFromSettings(sv *settings.Values) Config {
+	return Config{
+		KVReadRequest:  RU(readRequestCost.Get(sv)),
+		KVReadByte:     RU(readCostPerMB.Get(sv) / (1024 * 1024)),
+		KVWriteRequest: RU(writeRequestCost.Get(sv)),
+		KVWriteByte:    RU(writeCostPerMB.Get(sv) / (1024 * 1024)),
+		PodCPUSecond:   RU(podCPUSecondCost.Get(sv)),
+	}
+}
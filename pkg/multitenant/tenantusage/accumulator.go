@@ -0,0 +1,168 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package tenantusage meters the resources consumed by tenants for the
+// purposes of billing.
+//
+// Each KV node accumulates the resources consumed by the KV requests of each
+// tenant that it serves, along with the CPU time reported by the tenant's SQL
+// pods, and converts them into request units using the tenant cost model. The
+// accumulated consumption is periodically rolled up into the
+// system.tenant_usage table, in which each node maintains a row per tenant and
+// aggregation interval.
+package tenantusage
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantcostmodel"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Accumulator accumulates the resources consumed by each tenant on a KV node
+// until they are flushed to the system.tenant_usage table.
+type Accumulator struct {
+	st     *cluster.Settings
+	db     *kv.DB
+	ie     sqlutil.InternalExecutor
+	nodeID *base.NodeIDContainer
+
+	mu struct {
+		syncutil.Mutex
+		// consumption contains the consumption of each tenant which has not
+		// been included in a flush yet.
+		consumption map[roachpb.TenantID]*roachpb.TenantConsumption
+		// pending is the last flush, until it succeeds. A failed flush is
+		// retried before any new consumption is flushed.
+		pending *flush
+		// lastSeq is the sequence number of the last flush.
+		lastSeq int64
+	}
+}
+
+// flush is a roll-up of accumulated consumption into the rows of an
+// aggregation interval. It is identified by a sequence number, which the rows
+// record, so that retrying a flush whose outcome is unknown (e.g. because its
+// transaction failed with an AmbiguousResultError) does not count its
+// consumption twice.
+type flush struct {
+	seq          int64
+	aggregatedTs time.Time
+	consumption  map[roachpb.TenantID]*roachpb.TenantConsumption
+}
+
+// NewAccumulator constructs an Accumulator for the node with the given ID,
+// which flushes to the system.tenant_usage table using the given internal
+// executor.
+func NewAccumulator(
+	st *cluster.Settings, db *kv.DB, ie sqlutil.InternalExecutor, nodeID *base.NodeIDContainer,
+) *Accumulator {
+	a := &Accumulator{
+		st:     st,
+		db:     db,
+		ie:     ie,
+		nodeID: nodeID,
+	}
+	a.mu.consumption = make(map[roachpb.TenantID]*roachpb.TenantConsumption)
+	return a
+}
+
+// RecordKVBatch records the resources consumed by a KV batch issued by the
+// given tenant. The response information is only available if the batch was
+// evaluated successfully.
+func (a *Accumulator) RecordKVBatch(
+	tenantID roachpb.TenantID, req tenantcostmodel.RequestInfo, resp tenantcostmodel.ResponseInfo,
+) {
+	if tenantID == roachpb.SystemTenantID {
+		return
+	}
+	costCfg := tenantcostmodel.ConfigFromSettings(&a.st.SV)
+	ru := costCfg.RequestCost(req) + costCfg.ResponseCost(resp)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c := a.getLocked(tenantID)
+	c.RU += float64(ru)
+	if req.IsWrite() {
+		c.WriteRequests++
+		c.WriteBytes += uint64(req.WriteBytes())
+	} else {
+		c.ReadRequests++
+	}
+	c.ReadBytes += uint64(resp.ReadBytes())
+}
+
+// RecordSQLPodsCPU records CPU time used by the SQL pods of the given tenant.
+func (a *Accumulator) RecordSQLPodsCPU(tenantID roachpb.TenantID, seconds float64) {
+	if tenantID == roachpb.SystemTenantID || seconds <= 0 {
+		return
+	}
+	costCfg := tenantcostmodel.ConfigFromSettings(&a.st.SV)
+	ru := costCfg.PodCPUCost(seconds)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c := a.getLocked(tenantID)
+	c.RU += float64(ru)
+	c.SQLPodsCPUSeconds += seconds
+}
+
+func (a *Accumulator) getLocked(tenantID roachpb.TenantID) *roachpb.TenantConsumption {
+	c, ok := a.mu.consumption[tenantID]
+	if !ok {
+		c = &roachpb.TenantConsumption{}
+		a.mu.consumption[tenantID] = c
+	}
+	return c
+}
+
+// nextFlush returns the pending flush if the last flush failed, in which case
+// retry is true. Otherwise, it returns a new flush of the consumption
+// accumulated since the last flush, which is reset, or nil if there is none.
+//
+// Sequence numbers are derived from the wall time, so that they increase
+// across restarts of the node.
+func (a *Accumulator) nextFlush(now time.Time) (_ *flush, retry bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.mu.pending != nil {
+		return a.mu.pending, true
+	}
+	if len(a.mu.consumption) == 0 {
+		return nil, false
+	}
+	seq := now.UnixNano()
+	if seq <= a.mu.lastSeq {
+		seq = a.mu.lastSeq + 1
+	}
+	a.mu.lastSeq = seq
+	a.mu.pending = &flush{
+		seq:          seq,
+		aggregatedTs: now.Truncate(aggregationInterval.Get(&a.st.SV)),
+		consumption:  a.mu.consumption,
+	}
+	a.mu.consumption = make(map[roachpb.TenantID]*roachpb.TenantConsumption, len(a.mu.consumption))
+	return a.mu.pending, false
+}
+
+// flushSucceeded records that the given flush succeeded, so that it is not
+// retried.
+func (a *Accumulator) flushSucceeded(f *flush) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.mu.pending == f {
+		a.mu.pending = nil
+	}
+}
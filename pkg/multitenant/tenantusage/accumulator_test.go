@@ -0,0 +1,84 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tenantusage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantcostmodel"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccumulator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	a := NewAccumulator(st, nil /* db */, nil /* ie */, &base.NodeIDContainer{})
+	cfg := tenantcostmodel.ConfigFromSettings(&st.SV)
+	tenant := roachpb.MakeTenantID(10)
+
+	var read roachpb.BatchRequest
+	read.Add(roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */))
+	var readResp roachpb.BatchResponse
+	getResp := &roachpb.GetResponse{}
+	getResp.NumBytes = 100
+	readResp.Add(getResp)
+	var write roachpb.BatchRequest
+	write.Add(roachpb.NewPut(roachpb.Key("a"), roachpb.MakeValueFromString("value")))
+
+	readInfo := tenantcostmodel.MakeRequestInfo(&read)
+	readRespInfo := tenantcostmodel.MakeResponseInfo(&readResp)
+	writeInfo := tenantcostmodel.MakeRequestInfo(&write)
+	a.RecordKVBatch(tenant, readInfo, readRespInfo)
+	a.RecordKVBatch(tenant, writeInfo, tenantcostmodel.ResponseInfo{})
+	a.RecordSQLPodsCPU(tenant, 0.5)
+	// The system tenant is not metered.
+	a.RecordKVBatch(roachpb.SystemTenantID, readInfo, readRespInfo)
+	a.RecordSQLPodsCPU(roachpb.SystemTenantID, 1)
+
+	expected := roachpb.TenantConsumption{
+		RU: float64(cfg.RequestCost(readInfo) + cfg.ResponseCost(readRespInfo) +
+			cfg.RequestCost(writeInfo) + cfg.PodCPUCost(0.5)),
+		ReadRequests:      1,
+		ReadBytes:         100,
+		WriteRequests:     1,
+		WriteBytes:        uint64(writeInfo.WriteBytes()),
+		SQLPodsCPUSeconds: 0.5,
+	}
+	now := timeutil.Now()
+	f, retry := a.nextFlush(now)
+	require.False(t, retry)
+	require.Len(t, f.consumption, 1)
+	require.Equal(t, expected, *f.consumption[tenant])
+	require.Equal(t, now.Truncate(aggregationInterval.Get(&st.SV)), f.aggregatedTs)
+
+	// A flush which failed is retried as is, and the consumption recorded in
+	// the meantime is left for the next flush.
+	a.RecordSQLPodsCPU(tenant, 0.5)
+	retried, retry := a.nextFlush(now.Add(time.Hour))
+	require.True(t, retry)
+	require.Equal(t, f, retried)
+	a.flushSucceeded(f)
+	next, retry := a.nextFlush(now)
+	require.False(t, retry)
+	require.Greater(t, next.seq, f.seq)
+	require.Len(t, next.consumption, 1)
+	require.Equal(t, 0.5, next.consumption[tenant].SQLPodsCPUSeconds)
+	a.flushSucceeded(next)
+	next, _ = a.nextFlush(now)
+	require.Nil(t, next)
+}
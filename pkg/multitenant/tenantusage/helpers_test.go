@@ -0,0 +1,28 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tenantusage
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// TestingFlushWithoutAck flushes the accumulated consumption like Flush, but
+// leaves the flush pending even if it succeeds, as if its outcome were
+// unknown.
+func (a *Accumulator) TestingFlushWithoutAck(ctx context.Context) error {
+	f, _ := a.nextFlush(timeutil.Now())
+	if f == nil {
+		return nil
+	}
+	return a.persist(ctx, f)
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tenantusage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// flushInterval is the interval at which the accumulated consumption is rolled
+// up into the system.tenant_usage table.
+var flushInterval = settings.RegisterDurationSetting(
+	"tenant_usage.flush.interval",
+	"the interval at which the resources consumed by tenants are flushed to system.tenant_usage",
+	10*time.Second,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+)
+
+// aggregationInterval is the width of the time buckets into which the
+// persisted consumption is aggregated.
+var aggregationInterval = settings.RegisterDurationSetting(
+	"tenant_usage.aggregation.interval",
+	"the width of the time buckets into which the resources consumed by tenants are aggregated",
+	time.Hour,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+)
+
+// retention is how long the persisted consumption is kept.
+var retention = settings.RegisterDurationSetting(
+	"tenant_usage.persisted_rows.retention",
+	"the amount of time for which the resources consumed by tenants are retained in system.tenant_usage",
+	90*24*time.Hour,
+	settings.NonNegativeDuration,
+)
+
+// deleteBatchSize is the number of expired rows deleted per statement.
+const deleteBatchSize = 1024
+
+// Start spawns a loop that periodically flushes the accumulated consumption
+// and deletes the expired rows of the system.tenant_usage table.
+func (a *Accumulator) Start(ctx context.Context, stopper *stop.Stopper) {
+	_ = stopper.RunAsyncTask(ctx, "tenant-usage-flusher", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(flushInterval.Get(&a.st.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if err := a.Flush(ctx); err != nil {
+				log.Warningf(ctx, "failed to flush tenant usage: %v", err)
+			}
+		}
+	})
+}
+
+// Flush adds the consumption accumulated since the last flush to this node's
+// rows of the current aggregation interval, and deletes the rows that have
+// expired. If the flush fails, it is retried by the next one, before the
+// consumption accumulated in the meantime is flushed.
+func (a *Accumulator) Flush(ctx context.Context) error {
+	if !a.st.Version.IsActive(ctx, clusterversion.TenantUsageTable) {
+		return nil
+	}
+	for {
+		f, retry := a.nextFlush(timeutil.Now())
+		if f == nil {
+			break
+		}
+		if err := a.persist(ctx, f); err != nil {
+			return err
+		}
+		a.flushSucceeded(f)
+		if !retry {
+			break
+		}
+	}
+	return a.deleteExpired(ctx)
+}
+
+// persist adds the consumption of the flush to this node's rows of its
+// aggregation interval. Rows which already reflect the flush, because it is
+// retried after its transaction committed without the node knowing, are left
+// unchanged.
+func (a *Accumulator) persist(ctx context.Context, f *flush) error {
+	nodeID := a.nodeID.Get()
+	return a.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		for tenantID, c := range f.consumption {
+			if _, err := a.ie.ExecEx(ctx, "write-tenant-usage", txn,
+				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+				`INSERT INTO system.tenant_usage (
+  tenant_id, aggregated_ts, node_id, ru, read_requests, read_bytes,
+  write_requests, write_bytes, sql_pods_cpu_seconds, flush_seq
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (tenant_id, aggregated_ts, node_id) DO UPDATE SET
+  ru = tenant_usage.ru + excluded.ru,
+  read_requests = tenant_usage.read_requests + excluded.read_requests,
+  read_bytes = tenant_usage.read_bytes + excluded.read_bytes,
+  write_requests = tenant_usage.write_requests + excluded.write_requests,
+  write_bytes = tenant_usage.write_bytes + excluded.write_bytes,
+  sql_pods_cpu_seconds = tenant_usage.sql_pods_cpu_seconds + excluded.sql_pods_cpu_seconds,
+  flush_seq = excluded.flush_seq
+WHERE tenant_usage.flush_seq < excluded.flush_seq`,
+				tenantID.ToUint64(), f.aggregatedTs, nodeID, c.RU,
+				int64(c.ReadRequests), int64(c.ReadBytes),
+				int64(c.WriteRequests), int64(c.WriteBytes), c.SQLPodsCPUSeconds, f.seq,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteExpired deletes the rows of the aggregation intervals that started
+// before the retention period.
+func (a *Accumulator) deleteExpired(ctx context.Context) error {
+	cutoff := timeutil.Now().Add(-retention.Get(&a.st.SV))
+	for {
+		n, err := a.ie.ExecEx(ctx, "delete-expired-tenant-usage", nil, /* txn */
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			fmt.Sprintf(`DELETE FROM system.tenant_usage WHERE aggregated_ts < $1 LIMIT %d`,
+				deleteBatchSize),
+			cutoff,
+		)
+		if err != nil {
+			return err
+		}
+		if n < deleteBatchSize {
+			return nil
+		}
+	}
+}
+
+// Usage is the consumption of a tenant during an aggregation interval,
+// combined across nodes.
+type Usage struct {
+	TenantID     roachpb.TenantID
+	AggregatedTs time.Time
+	Consumption  roachpb.TenantConsumption
+}
+
+// Read returns the persisted consumption of the aggregation intervals starting
+// in [start, end), ordered by tenant and aggregation interval. A zero end means
+// no upper bound. If tenantID is set, only the consumption of that tenant is
+// returned.
+func Read(
+	ctx context.Context,
+	ie sqlutil.InternalExecutor,
+	txn *kv.Txn,
+	tenantID roachpb.TenantID,
+	start, end time.Time,
+) ([]Usage, error) {
+	query := `SELECT tenant_id, aggregated_ts, sum(ru),
+  sum(read_requests)::INT8, sum(read_bytes)::INT8,
+  sum(write_requests)::INT8, sum(write_bytes)::INT8,
+  sum(sql_pods_cpu_seconds)
+FROM system.tenant_usage WHERE aggregated_ts >= $1`
+	args := []interface{}{start}
+	if !end.IsZero() {
+		args = append(args, end)
+		query += fmt.Sprintf(` AND aggregated_ts < $%d`, len(args))
+	}
+	if tenantID != (roachpb.TenantID{}) {
+		args = append(args, tenantID.ToUint64())
+		query += fmt.Sprintf(` AND tenant_id = $%d`, len(args))
+	}
+	query += ` GROUP BY tenant_id, aggregated_ts ORDER BY tenant_id, aggregated_ts`
+	rows, err := ie.QueryEx(ctx, "read-tenant-usage", txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()}, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Usage, len(rows))
+	for i, row := range rows {
+		res[i] = Usage{
+			TenantID:     roachpb.MakeTenantID(uint64(tree.MustBeDInt(row[0]))),
+			AggregatedTs: tree.MustBeDTimestampTZ(row[1]).Time,
+			Consumption: roachpb.TenantConsumption{
+				RU:                float64(tree.MustBeDFloat(row[2])),
+				ReadRequests:      uint64(tree.MustBeDInt(row[3])),
+				ReadBytes:         uint64(tree.MustBeDInt(row[4])),
+				WriteRequests:     uint64(tree.MustBeDInt(row[5])),
+				WriteBytes:        uint64(tree.MustBeDInt(row[6])),
+				SQLPodsCPUSeconds: float64(tree.MustBeDFloat(row[7])),
+			},
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tenantusage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantusage"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFlushRetryIsIdempotent verifies that retrying a flush which committed,
+// without the node knowing, does not count its consumption twice.
+func TestFlushRetryIsIdempotent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	// Use a node ID of its own, so that the server's flushes don't interfere.
+	nodeID := &base.NodeIDContainer{}
+	nodeID.Set(ctx, 100)
+	a := tenantusage.NewAccumulator(s.ClusterSettings(), kvDB,
+		s.InternalExecutor().(sqlutil.InternalExecutor), nodeID)
+	tenantID := roachpb.MakeTenantID(10)
+
+	a.RecordSQLPodsCPU(tenantID, 1)
+	require.NoError(t, a.TestingFlushWithoutAck(ctx))
+	a.RecordSQLPodsCPU(tenantID, 2)
+	// The pending flush is retried before the new consumption is flushed.
+	require.NoError(t, a.Flush(ctx))
+	require.NoError(t, a.Flush(ctx))
+
+	sqlutils.MakeSQLRunner(sqlDB).CheckQueryResults(t, `
+SELECT sum(sql_pods_cpu_seconds) FROM system.tenant_usage
+WHERE tenant_id = 10 AND node_id = 100`, [][]string{{"3"}})
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tenantusage
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// Reporter reports the resources consumed by a SQL pod of a tenant to the KV
+// nodes, which meter them.
+type Reporter interface {
+	ReportTenantConsumption(ctx context.Context, req *roachpb.ReportTenantConsumptionRequest) error
+}
+
+// reportInterval is the interval at which SQL pods report their consumption.
+// It matches the interval at which the CPU time of the process is sampled.
+const reportInterval = base.DefaultMetricsSampleInterval
+
+// StartReporter spawns a loop that periodically reports the CPU time used by
+// the SQL pod of the given tenant since its previous report. cpuSeconds returns
+// the total CPU time used by the process. CPU time that could not be reported
+// is included in the next report.
+func StartReporter(
+	ctx context.Context,
+	stopper *stop.Stopper,
+	tenantID roachpb.TenantID,
+	r Reporter,
+	cpuSeconds func() float64,
+) error {
+	return stopper.RunAsyncTask(ctx, "tenant-usage-reporter", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		var reported float64
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(reportInterval)
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			total := cpuSeconds()
+			if total <= reported {
+				continue
+			}
+			req := &roachpb.ReportTenantConsumptionRequest{
+				TenantID: tenantID.ToUint64(),
+				ConsumptionSinceLastRequest: roachpb.TenantConsumption{
+					SQLPodsCPUSeconds: total - reported,
+				},
+			}
+			reportCtx, cancel := context.WithTimeout(ctx, reportInterval)
+			err := r.ReportTenantConsumption(reportCtx, req)
+			cancel()
+			if err != nil {
+				log.Warningf(ctx, "failed to report tenant consumption: %v", err)
+				continue
+			}
+			reported = total
+		}
+	})
+}
@@ -12,6 +12,7 @@ package roachpb
 
 import (
 	"fmt"
+	"hash/fnv"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -362,6 +363,64 @@ func (avptr *AdminVerifyProtectedTimestampResponse) combine(c combinable) error
 
 var _ combinable = &AdminVerifyProtectedTimestampResponse{}
 
+// combine implements the combinable interface.
+func (r *QueryResolvedTimestampResponse) combine(c combinable) error {
+	otherR := c.(*QueryResolvedTimestampResponse)
+	if r != nil {
+		if err := r.ResponseHeader.combine(otherR.Header()); err != nil {
+			return err
+		}
+		r.ResolvedTS.Backward(otherR.ResolvedTS)
+	}
+	return nil
+}
+
+var _ combinable = &QueryResolvedTimestampResponse{}
+
+// combine implements the combinable interface.
+func (r *FingerprintResponse) combine(c combinable) error {
+	otherR := c.(*FingerprintResponse)
+	if r != nil {
+		if err := r.ResponseHeader.combine(otherR.Header()); err != nil {
+			return err
+		}
+		r.Fingerprints = append(r.Fingerprints, otherR.Fingerprints...)
+	}
+	return nil
+}
+
+var _ combinable = &FingerprintResponse{}
+
+// Add adds a live key and its value to the fingerprint.
+func (fp *SpanFingerprint) Add(key Key, value Value) {
+	fp.Fingerprint ^= fingerprintKeyValue(key, value)
+	fp.KeyCount++
+}
+
+// Remove removes a live key and its value from the fingerprint, undoing Add.
+func (fp *SpanFingerprint) Remove(key Key, value Value) {
+	fp.Fingerprint ^= fingerprintKeyValue(key, value)
+	fp.KeyCount--
+}
+
+// Combine combines the fingerprint with another one. If the other fingerprint
+// is that of an adjacent span, the result is the fingerprint of both spans; if
+// it is a change since the fingerprint was computed (see
+// ExportRequest.Fingerprint), the result reflects that change.
+func (fp *SpanFingerprint) Combine(other SpanFingerprint) {
+	fp.Fingerprint ^= other.Fingerprint
+	fp.KeyCount += other.KeyCount
+}
+
+func fingerprintKeyValue(key Key, value Value) uint64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write(key)
+	// The value's checksum covers the key, but it is not always maintained
+	// when data is copied between key spans or clusters, so leave it out.
+	_, _ = hasher.Write(value.TagAndDataBytes())
+	return hasher.Sum64()
+}
+
 // combine implements the combinable interface.
 func (sr *ReverseScanResponse) combine(c combinable) error {
 	otherSR := c.(*ReverseScanResponse)
@@ -716,6 +775,12 @@ func (*RangeStatsRequest) Method() Method { return RangeStats }
 // Method implements the Request interface.
 func (*AdminVerifyProtectedTimestampRequest) Method() Method { return AdminVerifyProtectedTimestamp }
 
+// Method implements the Request interface.
+func (*QueryResolvedTimestampRequest) Method() Method { return QueryResolvedTimestamp }
+
+// Method implements the Request interface.
+func (*FingerprintRequest) Method() Method { return Fingerprint }
+
 // ShallowCopy implements the Request interface.
 func (gr *GetRequest) ShallowCopy() Request {
 	shallowCopy := *gr
@@ -986,6 +1051,18 @@ func (r *AdminVerifyProtectedTimestampRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (r *QueryResolvedTimestampRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
+// ShallowCopy implements the Request interface.
+func (r *FingerprintRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
 // NewGet returns a Request initialized to get the value at key. If
 // forUpdate is true, an unreplicated, exclusive lock is acquired on on
 // the key, if it exists.
@@ -1168,9 +1245,20 @@ func flagForLockStrength(l lock.Strength) int {
 	return 0
 }
 
+// flagForReplicatedLocking returns isWrite for locking reads which acquire
+// their locks with the Replicated durability, since the locks are written
+// through Raft. The request remains a read, but is no longer read-only.
+func flagForReplicatedLocking(l lock.Strength, replicated bool) int {
+	if l != lock.None && replicated {
+		return isWrite
+	}
+	return 0
+}
+
 func (gr *GetRequest) flags() int {
 	maybeLocking := flagForLockStrength(gr.KeyLocking)
-	return isRead | isTxn | maybeLocking | updatesTSCache | needsRefresh
+	maybeWrite := flagForReplicatedLocking(gr.KeyLocking, gr.KeyLockingReplicated)
+	return isRead | isTxn | maybeLocking | maybeWrite | updatesTSCache | needsRefresh
 }
 
 func (*PutRequest) flags() int {
@@ -1226,6 +1314,12 @@ func (drr *DeleteRangeRequest) flags() int {
 	if drr.Inline {
 		return isRead | isWrite | isRange | isAlone
 	}
+	// Likewise, a DeleteRange writing an MVCC range tombstone cannot be
+	// transactional. It consults the timestamp cache so that it does not delete
+	// keys below a timestamp at which they were read.
+	if drr.UseRangeTombstone {
+		return isWrite | isRange | isAlone | consultsTSCache
+	}
 	// DeleteRange updates the timestamp cache as it doesn't leave intents or
 	// tombstones for keys which don't yet exist or keys that already have
 	// tombstones on them, but still wants to prevent anybody from writing under
@@ -1245,12 +1339,14 @@ func (*RevertRangeRequest) flags() int { return isWrite | isRange }
 
 func (sr *ScanRequest) flags() int {
 	maybeLocking := flagForLockStrength(sr.KeyLocking)
-	return isRead | isRange | isTxn | maybeLocking | updatesTSCache | needsRefresh
+	maybeWrite := flagForReplicatedLocking(sr.KeyLocking, sr.KeyLockingReplicated)
+	return isRead | isRange | isTxn | maybeLocking | maybeWrite | updatesTSCache | needsRefresh
 }
 
 func (rsr *ReverseScanRequest) flags() int {
 	maybeLocking := flagForLockStrength(rsr.KeyLocking)
-	return isRead | isRange | isReverse | isTxn | maybeLocking | updatesTSCache | needsRefresh
+	maybeWrite := flagForReplicatedLocking(rsr.KeyLocking, rsr.KeyLockingReplicated)
+	return isRead | isRange | isReverse | isTxn | maybeLocking | maybeWrite | updatesTSCache | needsRefresh
 }
 
 // EndTxn updates the timestamp cache to prevent replays.
@@ -1329,6 +1425,15 @@ func (r *RefreshRangeRequest) flags() int {
 func (*SubsumeRequest) flags() int    { return isRead | isAlone | updatesTSCache }
 func (*RangeStatsRequest) flags() int { return isRead }
 
+// QueryResolvedTimestampRequest is not transactional: it does not read at the
+// batch's timestamp but reports the timestamp at which the replica can serve
+// reads.
+func (*QueryResolvedTimestampRequest) flags() int { return isRead | isRange }
+
+// FingerprintRequest updates the timestamp cache, like an ExportRequest, so
+// that the data it fingerprints can't be changed below its timestamp.
+func (*FingerprintRequest) flags() int { return isRead | isRange | updatesTSCache }
+
 // IsParallelCommit returns whether the EndTxn request is attempting to perform
 // a parallel commit. See txn_interceptor_committer.go for a discussion about
 // parallel commits.
@@ -1385,6 +1490,9 @@ func (e *RangeFeedEvent) ShallowCopy() *RangeFeedEvent {
 	case *RangeFeedError:
 		cpyErr := *t
 		cpy.MustSetValue(&cpyErr)
+	case *RangeFeedDeleteRange:
+		cpyDelRng := *t
+		cpy.MustSetValue(&cpyDelRng)
 	default:
 		panic(fmt.Sprintf("unexpected RangeFeedEvent variant: %v", t))
 	}
@@ -1452,6 +1560,18 @@ func (rc ReplicationChanges) NonVoterRemovals() []ReplicationTarget {
 	return rc.byType(REMOVE_NON_VOTER)
 }
 
+// WitnessAdditions returns a slice of all contained replication changes that
+// add witnesses.
+func (rc ReplicationChanges) WitnessAdditions() []ReplicationTarget {
+	return rc.byType(ADD_WITNESS)
+}
+
+// WitnessRemovals returns a slice of all contained replication changes that
+// remove witnesses.
+func (rc ReplicationChanges) WitnessRemovals() []ReplicationTarget {
+	return rc.byType(REMOVE_WITNESS)
+}
+
 // Changes returns the changes requested by this AdminChangeReplicasRequest, taking
 // the deprecated method of doing so into account.
 func (acrr *AdminChangeReplicasRequest) Changes() []ReplicationChange {
@@ -15,6 +15,7 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/redact"
 	"github.com/stretchr/testify/require"
@@ -219,6 +220,28 @@ func TestCombinable(t *testing.T) {
 		}, v1)
 
 	})
+
+	t.Run("QueryResolvedTimestamp", func(t *testing.T) {
+		q1 := &QueryResolvedTimestampResponse{
+			ResolvedTS: hlc.Timestamp{WallTime: 3},
+		}
+		if _, ok := interface{}(q1).(combinable); !ok {
+			t.Fatal("QueryResolvedTimestampResponse unexpectedly does not implement combinable")
+		}
+		q2 := &QueryResolvedTimestampResponse{
+			ResolvedTS: hlc.Timestamp{WallTime: 2},
+		}
+		q3 := &QueryResolvedTimestampResponse{
+			ResolvedTS: hlc.Timestamp{WallTime: 4},
+		}
+		require.NoError(t, q1.combine(q2))
+		require.NoError(t, q1.combine(q3))
+		require.Equal(t, hlc.Timestamp{WallTime: 2}, q1.ResolvedTS)
+
+		// A response without a resolved timestamp holds back the combined one.
+		require.NoError(t, q1.combine(&QueryResolvedTimestampResponse{}))
+		require.Equal(t, hlc.Timestamp{}, q1.ResolvedTS)
+	})
 }
 
 // TestMustSetInner makes sure that calls to MustSetInner correctly reset the
@@ -129,6 +129,7 @@ func (s *StatementStatistics) Add(other *StatementStatistics) {
 	s.OverheadLat.Add(other.OverheadLat, s.Count, other.Count)
 	s.BytesRead.Add(other.BytesRead, s.Count, other.Count)
 	s.RowsRead.Add(other.RowsRead, s.Count, other.Count)
+	s.PeakMemUsage.Add(other.PeakMemUsage, s.Count, other.Count)
 
 	// Execution stats collected using a sampling approach.
 	execStatCollectionCount := s.ExecStatCollectionCount
@@ -141,6 +142,12 @@ func (s *StatementStatistics) Add(other *StatementStatistics) {
 	s.MaxMemUsage.Add(other.MaxMemUsage, s.ExecStatCollectionCount, execStatCollectionCount)
 	s.ContentionTime.Add(other.ContentionTime, s.ExecStatCollectionCount, execStatCollectionCount)
 
+	// CPU time estimated from CPU profiles.
+	if other.CPUTimeSampleCount > 0 {
+		s.CPUTime.Add(other.CPUTime, s.CPUTimeSampleCount, other.CPUTimeSampleCount)
+		s.CPUTimeSampleCount += other.CPUTimeSampleCount
+	}
+
 	if other.SensitiveInfo.LastErr != "" {
 		s.SensitiveInfo.LastErr = other.SensitiveInfo.LastErr
 	}
@@ -152,6 +159,26 @@ func (s *StatementStatistics) Add(other *StatementStatistics) {
 	s.Count += other.Count
 }
 
+// CPUTimeCoverage returns the fraction of the executions of the statement
+// whose CPU time was estimated.
+func (s *StatementStatistics) CPUTimeCoverage() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.CPUTimeSampleCount) / float64(s.Count)
+}
+
+// EstimatedTotalCPUTime returns an estimate of the CPU time, in seconds,
+// spent by all the executions of the statement, scaling the mean CPU time of
+// the executions whose CPU time was estimated up to all of them. It is zero if
+// the CPU time of none of the executions was estimated.
+func (s *StatementStatistics) EstimatedTotalCPUTime() float64 {
+	if s.CPUTimeSampleCount == 0 {
+		return 0
+	}
+	return s.CPUTime.Mean * float64(s.Count)
+}
+
 // AlmostEqual compares two StatementStatistics and their contained NumericStats
 // objects within an window of size eps.
 func (s *StatementStatistics) AlmostEqual(other *StatementStatistics, eps float64) bool {
@@ -169,5 +196,8 @@ func (s *StatementStatistics) AlmostEqual(other *StatementStatistics, eps float6
 		s.RowsRead.AlmostEqual(other.RowsRead, eps) &&
 		s.BytesSentOverNetwork.AlmostEqual(other.BytesSentOverNetwork, eps) &&
 		s.MaxMemUsage.AlmostEqual(other.MaxMemUsage, eps) &&
-		s.ContentionTime.AlmostEqual(other.ContentionTime, eps)
+		s.ContentionTime.AlmostEqual(other.ContentionTime, eps) &&
+		s.CPUTime.AlmostEqual(other.CPUTime, eps) &&
+		s.CPUTimeSampleCount == other.CPUTimeSampleCount &&
+		s.PeakMemUsage.AlmostEqual(other.PeakMemUsage, eps)
 }
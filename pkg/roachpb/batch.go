@@ -249,6 +249,20 @@ func (ba *BatchRequest) IsSingleAddSSTableRequest() bool {
 	return false
 }
 
+// IsAllQueryResolvedTimestamp returns true iff the BatchRequest contains only
+// QueryResolvedTimestampRequests.
+func (ba *BatchRequest) IsAllQueryResolvedTimestamp() bool {
+	if len(ba.Requests) == 0 {
+		return false
+	}
+	for _, union := range ba.Requests {
+		if _, ok := union.GetInner().(*QueryResolvedTimestampRequest); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // IsCompleteTransaction determines whether a batch contains every write in a
 // transactions.
 func (ba *BatchRequest) IsCompleteTransaction() bool {
@@ -1073,6 +1073,9 @@ func (t *Transaction) Update(o *Transaction) {
 	if len(t.Key) == 0 {
 		t.Key = o.Key
 	}
+	if t.IsoLevel == enginepb.SERIALIZABLE {
+		t.IsoLevel = o.IsoLevel
+	}
 
 	// Update epoch-scoped state, depending on the two transactions' epochs.
 	if t.Epoch < o.Epoch {
@@ -1213,6 +1216,9 @@ func (t Transaction) String() string {
 	if ni := len(t.IgnoredSeqNums); ni > 0 {
 		fmt.Fprintf(&buf, " isn=%d", ni)
 	}
+	if t.IsoLevel != enginepb.SERIALIZABLE {
+		fmt.Fprintf(&buf, " iso=%s", t.IsoLevel)
+	}
 	return buf.String()
 }
 
@@ -1236,6 +1242,9 @@ func (t Transaction) SafeMessage() string {
 	if ni := len(t.IgnoredSeqNums); ni > 0 {
 		fmt.Fprintf(&buf, " isn=%d", ni)
 	}
+	if t.IsoLevel != enginepb.SERIALIZABLE {
+		fmt.Fprintf(&buf, " iso=%s", t.IsoLevel)
+	}
 	return buf.String()
 }
 
@@ -1383,6 +1392,7 @@ func PrepareTransactionForRetry(
 		// TODO(andrei): Should we preserve the ObservedTimestamps across the
 		// restart?
 		errTxnPri := txn.Priority
+		errTxnIsoLevel := txn.IsoLevel
 		// Start the new transaction at the current time from the local clock.
 		// The local hlc should have been advanced to at least the error's
 		// timestamp already.
@@ -1398,6 +1408,8 @@ func PrepareTransactionForRetry(
 		)
 		// Use the priority communicated back by the server.
 		txn.Priority = errTxnPri
+		// Retain the isolation level of the aborted transaction.
+		txn.IsoLevel = errTxnIsoLevel
 	case *ReadWithinUncertaintyIntervalError:
 		txn.WriteTimestamp.Forward(
 			readWithinUncertaintyIntervalRetryTimestamp(ctx, &txn, tErr, pErr.OriginNode))
@@ -1601,6 +1613,12 @@ func confChangeImpl(
 			if err := checkNotExists(rDesc); err != nil {
 				return nil, err
 			}
+		case WITNESS:
+			// Witnesses are always removed through a simple change, so the
+			// target should be gone from the descriptor.
+			if err := checkNotExists(rDesc); err != nil {
+				return nil, err
+			}
 		default:
 			return nil, errors.Errorf("can't remove replica in state %v", rDesc.GetType())
 		}
@@ -1635,6 +1653,10 @@ func confChangeImpl(
 			// this learner is not a voter. Promotions of non-voters to voters and
 			// demotions vice-versa are not currently supported.
 			changeType = raftpb.ConfChangeAddLearnerNode
+		case WITNESS:
+			// We're promoting a learner to a witness, which is a voter as far
+			// as raft is concerned.
+			changeType = raftpb.ConfChangeAddNode
 		default:
 			// A voter that is demoting was just removed and re-added in the
 			// `removals` handler. We should not see it again here.
@@ -477,6 +477,7 @@ var nonZeroTxn = Transaction{
 		MinTimestamp:   makeSynTS(10, 11),
 		Priority:       957356782,
 		Sequence:       123,
+		IsoLevel:       enginepb.READ_COMMITTED,
 	},
 	Name:          "name",
 	Status:        COMMITTED,
@@ -53,6 +53,13 @@ func ReplicaTypeLearner() *ReplicaType {
 	return &t
 }
 
+// ReplicaTypeWitness returns a WITNESS pointer suitable for use in
+// a nullable proto field.
+func ReplicaTypeWitness() *ReplicaType {
+	t := WITNESS
+	return &t
+}
+
 // ReplicaSet is a set of replicas, usually the nodes/stores on which
 // replicas of a range are stored.
 type ReplicaSet struct {
@@ -91,7 +98,7 @@ func (d ReplicaSet) Descriptors() []ReplicaDescriptor {
 
 func predVoterFull(rDesc ReplicaDescriptor) bool {
 	switch rDesc.GetType() {
-	case VOTER_FULL:
+	case VOTER_FULL, WITNESS:
 		return true
 	default:
 	}
@@ -100,7 +107,7 @@ func predVoterFull(rDesc ReplicaDescriptor) bool {
 
 func predVoterFullOrIncoming(rDesc ReplicaDescriptor) bool {
 	switch rDesc.GetType() {
-	case VOTER_FULL, VOTER_INCOMING:
+	case VOTER_FULL, VOTER_INCOMING, WITNESS:
 		return true
 	default:
 	}
@@ -115,6 +122,14 @@ func predNonVoter(rDesc ReplicaDescriptor) bool {
 	return rDesc.GetType() == NON_VOTER
 }
 
+func predWitness(rDesc ReplicaDescriptor) bool {
+	return rDesc.GetType() == WITNESS
+}
+
+func predDataVoter(rDesc ReplicaDescriptor) bool {
+	return predVoterFullOrIncoming(rDesc) && !predWitness(rDesc)
+}
+
 func predVoterOrNonVoter(rDesc ReplicaDescriptor) bool {
 	return predVoterFullOrIncoming(rDesc) || predNonVoter(rDesc)
 }
@@ -127,7 +142,7 @@ func predVoterFullOrNonVoter(rDesc ReplicaDescriptor) bool {
 // means that during an atomic replication change, only the replicas that will
 // be voters once the change completes will be returned; "outgoing" voters will
 // not be returned even though they do in the current state retain their voting
-// rights.
+// rights. Witnesses are voters and are returned.
 //
 // This may allocate, but it also may return the underlying slice as a
 // performance optimization, so it's not safe to modify the returned value.
@@ -140,11 +155,24 @@ func (d ReplicaSet) Voters() ReplicaSet {
 }
 
 // VoterDescriptors returns the descriptors of current and future voter replicas
-// in the set.
+// in the set, including witnesses.
 func (d ReplicaSet) VoterDescriptors() []ReplicaDescriptor {
 	return d.FilterToDescriptors(predVoterFullOrIncoming)
 }
 
+// DataVoterDescriptors returns the descriptors of current and future voter
+// replicas in the set that hold the range's data, that is, VoterDescriptors
+// without the witnesses. Only these replicas can become leaseholders.
+func (d ReplicaSet) DataVoterDescriptors() []ReplicaDescriptor {
+	return d.FilterToDescriptors(predDataVoter)
+}
+
+// WitnessDescriptors returns the descriptors of the witness replicas in the
+// set. See the comment on the WITNESS replica type for details.
+func (d ReplicaSet) WitnessDescriptors() []ReplicaDescriptor {
+	return d.FilterToDescriptors(predWitness)
+}
+
 // LearnerDescriptors returns a slice of ReplicaDescriptors corresponding to
 // learner replicas in `d`. This may allocate, but it also may return the
 // underlying slice as a performance optimization, so it's not safe to modify
@@ -265,7 +293,7 @@ func (d ReplicaSet) NonVoterDescriptors() []ReplicaDescriptor {
 }
 
 // VoterFullAndNonVoterDescriptors returns the descriptors of
-// VOTER_FULL/NON_VOTER replicas in the set. This set will not contain learners
+// VOTER_FULL/WITNESS/NON_VOTER replicas in the set. This set will not contain learners
 // or, during an atomic replication change, incoming or outgoing voters.
 // Notably, this set must encapsulate all replicas of a range for a range merge
 // to proceed.
@@ -371,7 +399,7 @@ func (d ReplicaSet) InAtomicReplicationChange() bool {
 		switch rDesc.GetType() {
 		case VOTER_INCOMING, VOTER_OUTGOING, VOTER_DEMOTING:
 			return true
-		case VOTER_FULL, LEARNER, NON_VOTER:
+		case VOTER_FULL, LEARNER, NON_VOTER, WITNESS:
 		default:
 			panic(fmt.Sprintf("unknown replica type %d", rDesc.GetType()))
 		}
@@ -390,7 +418,7 @@ func (d ReplicaSet) ConfState() raftpb.ConfState {
 		id := uint64(rep.ReplicaID)
 		typ := rep.GetType()
 		switch typ {
-		case VOTER_FULL:
+		case VOTER_FULL, WITNESS:
 			cs.Voters = append(cs.Voters, id)
 			if joint {
 				cs.VotersOutgoing = append(cs.VotersOutgoing, id)
@@ -419,7 +447,7 @@ func (d ReplicaSet) ConfState() raftpb.ConfState {
 func (d ReplicaSet) CanMakeProgress(liveFunc func(descriptor ReplicaDescriptor) bool) bool {
 	isVoterOldConfig := func(rDesc ReplicaDescriptor) bool {
 		switch rDesc.GetType() {
-		case VOTER_FULL, VOTER_OUTGOING, VOTER_DEMOTING:
+		case VOTER_FULL, VOTER_OUTGOING, VOTER_DEMOTING, WITNESS:
 			return true
 		default:
 			return false
@@ -427,7 +455,7 @@ func (d ReplicaSet) CanMakeProgress(liveFunc func(descriptor ReplicaDescriptor)
 	}
 	isVoterNewConfig := func(rDesc ReplicaDescriptor) bool {
 		switch rDesc.GetType() {
-		case VOTER_FULL, VOTER_INCOMING:
+		case VOTER_FULL, VOTER_INCOMING, WITNESS:
 			return true
 		default:
 			return false
@@ -473,9 +501,9 @@ func (d ReplicaSet) ReplicationTargets() (out []ReplicationTarget) {
 // IsAddition returns true if `c` refers to a replica addition operation.
 func (c ReplicaChangeType) IsAddition() bool {
 	switch c {
-	case ADD_NON_VOTER, ADD_VOTER:
+	case ADD_NON_VOTER, ADD_VOTER, ADD_WITNESS:
 		return true
-	case REMOVE_NON_VOTER, REMOVE_VOTER:
+	case REMOVE_NON_VOTER, REMOVE_VOTER, REMOVE_WITNESS:
 		return false
 	default:
 		panic(fmt.Sprintf("unexpected ReplicaChangeType %s", c))
@@ -485,9 +513,9 @@ func (c ReplicaChangeType) IsAddition() bool {
 // IsRemoval returns true if `c` refers a replica removal operation.
 func (c ReplicaChangeType) IsRemoval() bool {
 	switch c {
-	case ADD_NON_VOTER, ADD_VOTER:
+	case ADD_NON_VOTER, ADD_VOTER, ADD_WITNESS:
 		return false
-	case REMOVE_NON_VOTER, REMOVE_VOTER:
+	case REMOVE_NON_VOTER, REMOVE_VOTER, REMOVE_WITNESS:
 		return true
 	default:
 		panic(fmt.Sprintf("unexpected ReplicaChangeType %s", c))
@@ -509,6 +537,9 @@ var errReplicaCannotHoldLease = errors.Errorf("replica cannot hold lease")
 // latencies. Additionally, as of the time of writing, learner replicas are
 // only used for a short time in replica addition, so it's not worth working
 // out the edge cases.
+//
+// Replicas of type WITNESS can't hold the lease either, since they don't have
+// the range's data to serve requests from.
 func CheckCanReceiveLease(wouldbeLeaseholder ReplicaDescriptor, rngDesc *RangeDescriptor) error {
 	repDesc, ok := rngDesc.GetReplicaDescriptorByID(wouldbeLeaseholder.ReplicaID)
 	if !ok {
@@ -43,6 +43,7 @@ var vi = ReplicaTypeVoterIncoming()
 var vo = ReplicaTypeVoterOutgoing()
 var vd = ReplicaTypeVoterDemoting()
 var l = ReplicaTypeLearner()
+var w = ReplicaTypeWitness()
 
 func TestVotersLearnersAll(t *testing.T) {
 
@@ -58,6 +59,7 @@ func TestVotersLearnersAll(t *testing.T) {
 		{rd(vi, 1)},
 		{rd(vo, 1)},
 		{rd(l, 1), rd(vo, 2), rd(vi, 3), rd(vi, 4)},
+		{rd(v, 1), rd(v, 2), rd(w, 3)},
 	}
 	for _, test := range tests {
 		t.Run("", func(t *testing.T) {
@@ -66,7 +68,7 @@ func TestVotersLearnersAll(t *testing.T) {
 			for _, voter := range r.VoterDescriptors() {
 				typ := voter.GetType()
 				switch typ {
-				case VOTER_FULL, VOTER_INCOMING:
+				case VOTER_FULL, VOTER_INCOMING, WITNESS:
 					seen[voter] = struct{}{}
 				default:
 					assert.FailNow(t, "unexpectedly got a %s as Voter()", typ)
@@ -93,6 +95,15 @@ func TestVotersLearnersAll(t *testing.T) {
 	}
 }
 
+func TestDataVoterAndWitnessDescriptors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := MakeReplicaSet([]ReplicaDescriptor{rd(v, 1), rd(w, 2), rd(l, 3), rd(vi, 4), rd(w, 5)})
+	require.Equal(t, []ReplicaDescriptor{rd(v, 1), rd(w, 2), rd(vi, 4), rd(w, 5)}, r.VoterDescriptors())
+	require.Equal(t, []ReplicaDescriptor{rd(v, 1), rd(vi, 4)}, r.DataVoterDescriptors())
+	require.Equal(t, []ReplicaDescriptor{rd(w, 2), rd(w, 5)}, r.WitnessDescriptors())
+}
+
 func TestReplicaDescriptorsRemove(t *testing.T) {
 	tests := []struct {
 		replicas []ReplicaDescriptor
@@ -198,6 +209,15 @@ func TestReplicaDescriptorsConfState(t *testing.T) {
 			[]ReplicaDescriptor{rd(vo, 1), rd(vd, 2), rd(vi, 3), rd(vi, 4), rd(l, 5)},
 			"Voters:[3 4] VotersOutgoing:[1 2] Learners:[5] LearnersNext:[2] AutoLeave:false",
 		},
+		// Witnesses are voters as far as raft is concerned.
+		{
+			[]ReplicaDescriptor{rd(v, 1), rd(v, 2), rd(w, 3)},
+			"Voters:[1 2 3] VotersOutgoing:[] Learners:[] LearnersNext:[] AutoLeave:false",
+		},
+		{
+			[]ReplicaDescriptor{rd(v, 1), rd(w, 2), rd(l, 3)},
+			"Voters:[1 2] VotersOutgoing:[] Learners:[3] LearnersNext:[] AutoLeave:false",
+		},
 	}
 
 	for _, test := range tests {
@@ -241,6 +261,18 @@ func TestReplicaDescriptorsCanMakeProgress(t *testing.T) {
 			{false, rd(v, 2)},
 			{true, rd(v, 3)},
 		}, true},
+		// Two out of three voters alive, one of which is a witness.
+		{[]descWithLiveness{
+			{true, rd(v, 1)},
+			{false, rd(v, 2)},
+			{true, rd(w, 3)},
+		}, true},
+		// Two out of three voters dead, the live one being a witness.
+		{[]descWithLiveness{
+			{false, rd(v, 1)},
+			{false, rd(v, 2)},
+			{true, rd(w, 3)},
+		}, false},
 		// Two out of three voters alive, but one is an incoming voter. The outgoing
 		// group doesn't have quorum.
 		{[]descWithLiveness{
@@ -161,6 +161,12 @@ const (
 	// VerifyProtectedTimestamp determines whether the specified protection record
 	// will be respected by this Range.
 	AdminVerifyProtectedTimestamp
+	// QueryResolvedTimestamp requests the resolved timestamp of the key span it
+	// is issued over.
+	QueryResolvedTimestamp
+	// Fingerprint computes a fingerprint of the MVCC data in a key span as of
+	// a timestamp.
+	Fingerprint
 	// NumMethods represents the total number of API methods.
 	NumMethods
 )
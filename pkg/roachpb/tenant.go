@@ -78,6 +78,16 @@ func IsSystemTenantID(id uint64) bool {
 	return id == SystemTenantID.ToUint64()
 }
 
+// Add adds the resources in other to the consumption.
+func (c *TenantConsumption) Add(other *TenantConsumption) {
+	c.RU += other.RU
+	c.ReadRequests += other.ReadRequests
+	c.ReadBytes += other.ReadBytes
+	c.WriteRequests += other.WriteRequests
+	c.WriteBytes += other.WriteBytes
+	c.SQLPodsCPUSeconds += other.SQLPodsCPUSeconds
+}
+
 type tenantKey struct{}
 
 // NewContextForTenant creates a new context with tenant information attached.
@@ -52,6 +52,9 @@ func (a tenantAuthorizer) authorize(
 	case "/cockroach.roachpb.Internal/GossipSubscription":
 		return a.authGossipSubscription(tenID, req.(*roachpb.GossipSubscriptionRequest))
 
+	case "/cockroach.roachpb.Internal/ReportTenantConsumption":
+		return a.authReportTenantConsumption(tenID, req.(*roachpb.ReportTenantConsumptionRequest))
+
 	case "/cockroach.rpc.Heartbeat/Ping":
 		return nil // no authorization
 
@@ -84,24 +87,26 @@ func (a tenantAuthorizer) authBatch(tenID roachpb.TenantID, args *roachpb.BatchR
 }
 
 var reqMethodAllowlist = [...]bool{
-	roachpb.Get:            true,
-	roachpb.Put:            true,
-	roachpb.ConditionalPut: true,
-	roachpb.Increment:      true,
-	roachpb.Delete:         true,
-	roachpb.DeleteRange:    true,
-	roachpb.ClearRange:     true,
-	roachpb.Scan:           true,
-	roachpb.ReverseScan:    true,
-	roachpb.EndTxn:         true,
-	roachpb.HeartbeatTxn:   true,
-	roachpb.QueryTxn:       true,
-	roachpb.QueryIntent:    true,
-	roachpb.InitPut:        true,
-	roachpb.AddSSTable:     true,
-	roachpb.Export:         true,
-	roachpb.Refresh:        true,
-	roachpb.RefreshRange:   true,
+	roachpb.Get:                    true,
+	roachpb.Put:                    true,
+	roachpb.ConditionalPut:         true,
+	roachpb.Increment:              true,
+	roachpb.Delete:                 true,
+	roachpb.DeleteRange:            true,
+	roachpb.ClearRange:             true,
+	roachpb.Scan:                   true,
+	roachpb.ReverseScan:            true,
+	roachpb.EndTxn:                 true,
+	roachpb.HeartbeatTxn:           true,
+	roachpb.QueryTxn:               true,
+	roachpb.QueryIntent:            true,
+	roachpb.InitPut:                true,
+	roachpb.AddSSTable:             true,
+	roachpb.Export:                 true,
+	roachpb.Refresh:                true,
+	roachpb.RefreshRange:           true,
+	roachpb.QueryResolvedTimestamp: true,
+	roachpb.Fingerprint:            true,
 }
 
 func reqAllowed(r roachpb.Request) bool {
@@ -157,6 +162,17 @@ func (a tenantAuthorizer) authGossipSubscription(
 	return nil
 }
 
+// authReportTenantConsumption authorizes the provided tenant to invoke the
+// ReportTenantConsumption RPC with the provided args.
+func (a tenantAuthorizer) authReportTenantConsumption(
+	tenID roachpb.TenantID, args *roachpb.ReportTenantConsumptionRequest,
+) error {
+	if args.TenantID != tenID.ToUint64() {
+		return authErrorf("requested tenant %d does not match tenant %s", args.TenantID, tenID)
+	}
+	return nil
+}
+
 // gossipSubscriptionPatternAllowlist contains keys outside of a tenant's
 // keyspace that GossipSubscription RPC invocations are allowed to touch.
 // WIP: can't import gossip directly.
@@ -356,6 +356,16 @@ func TestTenantAuthRequest(t *testing.T) {
 				expErr: `requested pattern "table-stat-added" not permitted`,
 			},
 		},
+		"/cockroach.roachpb.Internal/ReportTenantConsumption": {
+			{
+				req:    &roachpb.ReportTenantConsumptionRequest{TenantID: 10},
+				expErr: noError,
+			},
+			{
+				req:    &roachpb.ReportTenantConsumptionRequest{TenantID: 20},
+				expErr: `requested tenant 20 does not match tenant 10`,
+			},
+		},
 		"/cockroach.rpc.Heartbeat/Ping": {
 			{req: &PingRequest{}, expErr: noError},
 		},
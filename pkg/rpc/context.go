@@ -485,6 +485,13 @@ func (a internalClientAdapter) ResetQuorum(
 	return a.InternalServer.ResetQuorum(ctx, req)
 }
 
+// ReportTenantConsumption is part of the roachpb.InternalClient interface.
+func (a internalClientAdapter) ReportTenantConsumption(
+	ctx context.Context, req *roachpb.ReportTenantConsumptionRequest, _ ...grpc.CallOption,
+) (*roachpb.ReportTenantConsumptionResponse, error) {
+	return a.InternalServer.ReportTenantConsumption(ctx, req)
+}
+
 type respStreamClientAdapter struct {
 	ctx   context.Context
 	respC chan interface{}
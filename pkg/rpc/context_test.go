@@ -250,6 +250,12 @@ func (*internalServer) ResetQuorum(
 	panic("unimplemented")
 }
 
+func (*internalServer) ReportTenantConsumption(
+	context.Context, *roachpb.ReportTenantConsumptionRequest,
+) (*roachpb.ReportTenantConsumptionResponse, error) {
+	panic("unimplemented")
+}
+
 func (*internalServer) Join(
 	context.Context, *roachpb.JoinNodeRequest,
 ) (*roachpb.JoinNodeResponse, error) {
@@ -1557,6 +1557,63 @@ func TestAdminAPILocations(t *testing.T) {
 	}
 }
 
+func TestAdminAPIAlerts(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	s, conn, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(conn)
+
+	sqlDB.Exec(t, `INSERT INTO system.alert_rules (name, metric, rate, threshold, duration)
+VALUES ('sql_errors', 'cr.node.sql.failure.count', true, 1, '5m')`)
+	sqlDB.Exec(t, `INSERT INTO system.alert_rules (name, metric, threshold, severity, state, active_since, last_value)
+VALUES ('high_conns', 'cr.node.sql.conns', 100, 'fatal', 'firing', '2021-01-01', 200)`)
+
+	// Non-admin users are not allowed to see the alerts.
+	var res serverpb.AlertsResponse
+	if err := getAdminJSONProtoWithAdminOption(s, "alerts", &res, false /* isAdmin */); !testutils.IsError(err, "status: 403") {
+		t.Fatalf("expected 403 error, got %v", err)
+	}
+
+	if err := getAdminJSONProto(s, "alerts", &res); err != nil {
+		t.Fatal(err)
+	}
+	activeSince := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	expected := []serverpb.AlertsResponse_Alert{{
+		Name:        "high_conns",
+		Metric:      "cr.node.sql.conns",
+		Condition:   "avg(cr.node.sql.conns) > 100",
+		Severity:    "fatal",
+		State:       "firing",
+		ActiveSince: &activeSince,
+		LastValue:   200,
+		Error:       `unknown severity "fatal"`,
+	}, {
+		Name:      "sql_errors",
+		Metric:    "cr.node.sql.failure.count",
+		Condition: "avg(rate(cr.node.sql.failure.count)) > 1",
+		Duration:  5 * time.Minute,
+		Severity:  "warning",
+		State:     "inactive",
+	}}
+	require.Len(t, res.Alerts, 2)
+	for i := range expected {
+		if res.Alerts[i].ActiveSince != nil {
+			require.True(t, expected[i].ActiveSince.Equal(*res.Alerts[i].ActiveSince))
+			res.Alerts[i].ActiveSince = expected[i].ActiveSince
+		}
+		require.Equal(t, expected[i], res.Alerts[i])
+	}
+
+	var inactive serverpb.AlertsResponse
+	if err := getAdminJSONProto(s, "alerts?state=inactive", &inactive); err != nil {
+		t.Fatal(err)
+	}
+	require.Len(t, inactive.Alerts, 1)
+	require.Equal(t, "sql_errors", inactive.Alerts[0].Name)
+}
+
 func TestAdminAPIQueryPlan(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -0,0 +1,63 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/ts/alerting"
+)
+
+// Alerts returns the alert rules defined in system.alert_rules, along with
+// their evaluation state.
+func (s *adminServer) Alerts(
+	ctx context.Context, req *serverpb.AlertsRequest,
+) (*serverpb.AlertsResponse, error) {
+	ctx = s.server.AnnotateCtx(ctx)
+
+	if _, err := s.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	resp := &serverpb.AlertsResponse{}
+	if !s.server.st.Version.IsActive(ctx, clusterversion.AlertRulesTable) {
+		return resp, nil
+	}
+	rules, err := alerting.LoadRules(ctx, s.server.sqlServer.internalExecutor)
+	if err != nil {
+		return nil, s.serverError(err)
+	}
+	for i := range rules {
+		r := &rules[i]
+		if req.State != "" && string(r.State) != req.State {
+			continue
+		}
+		alert := serverpb.AlertsResponse_Alert{
+			Name:      r.Name,
+			Metric:    r.Metric,
+			Condition: r.Condition(),
+			Duration:  r.Duration,
+			Severity:  r.Severity,
+			State:     string(r.State),
+			LastValue: r.LastValue,
+		}
+		if !r.ActiveSince.IsZero() {
+			alert.ActiveSince = &r.ActiveSince
+		}
+		if err := r.Validate(); err != nil {
+			alert.Error = err.Error()
+		}
+		resp.Alerts = append(resp.Alerts, alert)
+	}
+	return resp, nil
+}
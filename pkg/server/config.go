@@ -133,6 +133,11 @@ type BaseConfig struct {
 	// CPUProfileDirName is the directory name for CPU profile dumps.
 	CPUProfileDirName string
 
+	// ContinuousProfileDirName is the directory name for the profiles
+	// collected by the continuous profiler. If empty, no continuous profiles
+	// will be collected.
+	ContinuousProfileDirName string
+
 	// DefaultZoneConfig is used to set the default zone config inside the server.
 	// It can be overridden during tests by setting the DefaultZoneConfigOverride
 	// server testing knob.
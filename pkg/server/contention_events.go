@@ -0,0 +1,170 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/debug"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultContentionEventsLimit is the number of events returned by
+// ContentionEvents if the request does not specify a limit.
+const defaultContentionEventsLimit = 1000
+
+// ContentionEvents returns the persisted history of the contention events
+// between transactions, the most recent first.
+func (s *statusServer) ContentionEvents(
+	ctx context.Context, req *serverpb.ContentionEventsRequest,
+) (*serverpb.ContentionEventsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	resp := &serverpb.ContentionEventsResponse{}
+	if !s.st.Version.IsActive(ctx, clusterversion.TransactionContentionEventsTable) {
+		return resp, nil
+	}
+
+	start := timeutil.Unix(req.Start, 0)
+	var end time.Time
+	if req.End != 0 {
+		end = timeutil.Unix(req.End, 0)
+	}
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultContentionEventsLimit
+	}
+	events, err := contention.Read(ctx, s.internalExecutor, nil /* txn */, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	includeRawKeys := debug.GatewayRemoteAllowed(ctx, s.st)
+	resp.Events = make([]serverpb.ContentionEventsResponse_Event, len(events))
+	for i := range events {
+		e := &events[i]
+		resp.Events[i] = serverpb.ContentionEventsResponse_Event{
+			Timestamp:               e.Timestamp,
+			NodeID:                  e.NodeID,
+			Duration:                e.Duration,
+			WaitingTxnID:            e.WaitingTxnID,
+			WaitingTxnFingerprints:  e.WaitingTxnFingerprints,
+			BlockingTxnID:           e.BlockingTxnID,
+			BlockingTxnFingerprints: e.BlockingTxnFingerprints,
+		}
+		if includeRawKeys {
+			resp.Events[i].Key = e.Key
+			resp.Events[i].PrettyKey = e.Key.String()
+		}
+	}
+	return resp, nil
+}
+
+// TransactionFingerprints returns the statement fingerprints of those of the
+// requested transactions which recently executed on the requested node(s).
+func (s *statusServer) TransactionFingerprints(
+	ctx context.Context, req *serverpb.TransactionFingerprintsRequest,
+) (*serverpb.TransactionFingerprintsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &serverpb.TransactionFingerprintsResponse{}
+
+	if len(req.NodeID) > 0 {
+		requestedNodeID, local, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
+		// Only the transactions of the local node.
+		if local {
+			for id, fingerprints := range s.contentionHistory.TxnFingerprints(req.TxnIDs) {
+				response.Transactions = append(response.Transactions,
+					serverpb.TransactionFingerprintsResponse_Transaction{
+						TxnID:                 id,
+						StatementFingerprints: fingerprints,
+					})
+			}
+			return response, nil
+		}
+
+		// Only the transactions of one non-local node.
+		status, err := s.dialNode(ctx, requestedNodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.TransactionFingerprints(ctx, req)
+	}
+
+	// The transactions of all nodes. A transaction executes all of its
+	// statements on its gateway, so it is found on at most one node.
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	remoteRequest := serverpb.TransactionFingerprintsRequest{NodeID: "local", TxnIDs: req.TxnIDs}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.TransactionFingerprints(ctx, &remoteRequest)
+	}
+	responseFn := func(_ roachpb.NodeID, resp interface{}) {
+		nodeResp := resp.(*serverpb.TransactionFingerprintsResponse)
+		response.Transactions = append(response.Transactions, nodeResp.Transactions...)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		// The fingerprints of the transactions which executed on an unavailable
+		// node are simply not resolved.
+		log.Warningf(ctx, "failed to retrieve the transactions of n%d: %v", nodeID, err)
+	}
+
+	if err := s.iterateNodes(ctx, "transaction fingerprints", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ResolveTxnFingerprints implements the contention.TxnResolver interface.
+func (s *statusServer) ResolveTxnFingerprints(
+	ctx context.Context, txnIDs []uuid.UUID,
+) (map[uuid.UUID][]string, error) {
+	res := make(map[uuid.UUID][]string)
+	if len(txnIDs) == 0 {
+		return res, nil
+	}
+	resp, err := s.TransactionFingerprints(ctx, &serverpb.TransactionFingerprintsRequest{TxnIDs: txnIDs})
+	if err != nil {
+		return nil, err
+	}
+	for _, txn := range resp.Transactions {
+		res[txn.TxnID] = append(res[txn.TxnID], txn.StatementFingerprints...)
+	}
+	return res, nil
+}
+
+var _ contention.TxnResolver = &statusServer{}
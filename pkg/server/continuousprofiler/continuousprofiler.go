@@ -0,0 +1,183 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package continuousprofiler
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"runtime/pprof"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/debug"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/google/pprof/profile"
+)
+
+var (
+	enabled = settings.RegisterBoolSetting(
+		"server.continuous_profiling.enabled",
+		"if set, CPU and heap profiles are periodically collected and retained on disk; "+
+			"requests for CPU profiles, e.g. to /debug/pprof/profile, fail while a continuous one is being collected",
+		false,
+	).WithPublic()
+
+	interval = settings.RegisterDurationSetting(
+		"server.continuous_profiling.interval",
+		"the interval at which the continuous profiler collects a CPU profile and a heap profile",
+		5*time.Minute,
+		settings.PositiveDuration,
+	).WithPublic()
+
+	cpuProfileDuration = settings.RegisterDurationSetting(
+		"server.continuous_profiling.cpu_profile_duration",
+		"the duration of each CPU profile collected by the continuous profiler",
+		10*time.Second,
+		settings.PositiveDuration,
+	).WithPublic()
+
+	maxCombinedFileSize = settings.RegisterByteSizeSetting(
+		"server.continuous_profiling.total_dump_size_limit",
+		"maximum combined disk size of the profiles retained by the continuous profiler",
+		64<<20, // 64MiB
+	).WithPublic()
+)
+
+// ContinuousProfiler periodically collects a short CPU profile, followed by a
+// heap profile, and stores them in a directory whose combined size is bounded.
+// The CPU profiles are collected with profiler labels enabled, which tags the
+// samples with e.g. the fingerprint of the SQL statements or the ID of the jobs
+// being executed.
+//
+// Unlike the HeapProfiler, which only takes profiles when the heap grows, it
+// provides a history of the resource usage of the process during normal
+// operation. Since the Go runtime only collects one CPU profile at a time,
+// on-demand CPU profiles can't be collected while a continuous one is, which is
+// why the profiler is disabled by default.
+type ContinuousProfiler struct {
+	st       *cluster.Settings
+	store    *ProfileStore
+	listener CPUProfileListener
+}
+
+// CPUProfileListener is notified of the CPU profiles collected by the
+// ContinuousProfiler, e.g. to attribute the sampled CPU time to the work
+// identified by the profiler labels.
+type CPUProfileListener interface {
+	// CPUProfileStarted is called when the collection of a CPU profile starts.
+	CPUProfileStarted(ctx context.Context)
+	// CPUProfileFinished is called after each CPUProfileStarted call, with the
+	// collected CPU profile, or nil if it could not be collected.
+	CPUProfileFinished(ctx context.Context, p *profile.Profile)
+}
+
+// NewContinuousProfiler creates a ContinuousProfiler. dir is the directory in
+// which profiles are to be stored. listener, if not nil, is notified of the
+// CPU profiles collected.
+func NewContinuousProfiler(
+	ctx context.Context, dir string, st *cluster.Settings, listener CPUProfileListener,
+) (*ContinuousProfiler, error) {
+	if dir == "" {
+		return nil, errors.AssertionFailedf("need to specify dir for NewContinuousProfiler")
+	}
+
+	log.Infof(ctx, "writing continuous CPU and heap profiles to %s", dir)
+
+	return &ContinuousProfiler{st: st, store: NewProfileStore(dir, st), listener: listener}, nil
+}
+
+// Start runs the profiler in an async task, until the stopper quiesces.
+func (p *ContinuousProfiler) Start(ctx context.Context, stopper *stop.Stopper) error {
+	return stopper.RunAsyncTask(ctx, "continuous-profiler", func(ctx context.Context) {
+		timer := timeutil.NewTimer()
+		defer timer.Stop()
+		for {
+			timer.Reset(interval.Get(&p.st.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if !enabled.Get(&p.st.SV) {
+				continue
+			}
+			p.takeProfiles(ctx, stopper.ShouldQuiesce())
+		}
+	})
+}
+
+// takeProfiles collects a CPU profile and a heap profile, then garbage
+// collects the oldest profiles. The CPU profile is cut short if quiesce is
+// closed.
+func (p *ContinuousProfiler) takeProfiles(ctx context.Context, quiesce <-chan struct{}) {
+	var buf bytes.Buffer
+	var notified bool
+	// CPUProfileDo fails if another CPU profile is being collected, in which
+	// case this one is skipped.
+	if err := debug.CPUProfileDo(p.st, cluster.CPUProfileWithLabels, func() error {
+		if p.listener != nil {
+			p.listener.CPUProfileStarted(ctx)
+			notified = true
+		}
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return err
+		}
+		select {
+		case <-time.After(cpuProfileDuration.Get(&p.st.SV)):
+		case <-quiesce:
+		}
+		pprof.StopCPUProfile()
+		return nil
+	}); err != nil {
+		log.Infof(ctx, "skipping continuous CPU profile: %v", err)
+		if notified {
+			p.listener.CPUProfileFinished(ctx, nil /* p */)
+		}
+	} else {
+		p.writeProfile(ctx, cpuProfileKind, buf.Bytes())
+		p.notifyCPUProfile(ctx, buf.Bytes())
+	}
+
+	buf.Reset()
+	if err := pprof.WriteHeapProfile(&buf); err != nil {
+		log.Warningf(ctx, "error collecting continuous heap profile: %v", err)
+	} else {
+		p.writeProfile(ctx, heapProfileKind, buf.Bytes())
+	}
+
+	p.store.GC(ctx, timeutil.Now())
+}
+
+func (p *ContinuousProfiler) writeProfile(ctx context.Context, kind string, data []byte) {
+	path := p.store.GetFullPath(makeFileName(timeutil.Now(), kind))
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		log.Warningf(ctx, "error writing continuous profile %s: %v", path, err)
+	}
+}
+
+// notifyCPUProfile passes the collected CPU profile to the listener, if any.
+func (p *ContinuousProfiler) notifyCPUProfile(ctx context.Context, data []byte) {
+	if p.listener == nil {
+		return
+	}
+	prof, err := profile.ParseData(data)
+	if err != nil {
+		log.Warningf(ctx, "error parsing continuous CPU profile: %v", err)
+		prof = nil
+	}
+	p.listener.CPUProfileFinished(ctx, prof)
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package continuousprofiler
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/google/pprof/profile"
+)
+
+type recordingListener struct {
+	started  int
+	profiles []*profile.Profile
+}
+
+func (l *recordingListener) CPUProfileStarted(context.Context) {
+	l.started++
+}
+
+func (l *recordingListener) CPUProfileFinished(_ context.Context, p *profile.Profile) {
+	l.profiles = append(l.profiles, p)
+}
+
+func TestContinuousProfiler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	st := cluster.MakeTestingClusterSettings()
+	cpuProfileDuration.Override(&st.SV, 10*time.Millisecond)
+	var listener recordingListener
+	p, err := NewContinuousProfiler(ctx, dir, st, &listener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.takeProfiles(ctx, nil /* quiesce */)
+
+	// The listener is notified of the CPU profile.
+	if listener.started != 1 || len(listener.profiles) != 1 {
+		t.Fatalf("expected the listener to be notified of one CPU profile, got %d started and %d finished",
+			listener.started, len(listener.profiles))
+	}
+	if st := listener.profiles[0].SampleType; len(st) != 2 || st[1].Type != "cpu" {
+		t.Errorf("expected a CPU profile, got sample types %v", st)
+	}
+
+	names, err := p.store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected a CPU and a heap profile, got %v", names)
+	}
+	// The most recent profile is listed first.
+	for i, expectedKind := range []string{heapProfileKind, cpuProfileKind} {
+		if _, kind, ok := parseFileName(names[i]); !ok || kind != expectedKind {
+			t.Errorf("expected a %s profile, got %s", expectedKind, names[i])
+		}
+		if err := p.store.Get(names[i], func(r io.Reader) error {
+			_, err := profile.Parse(r)
+			return err
+		}); err != nil {
+			t.Errorf("unable to parse %s: %v", names[i], err)
+		}
+	}
+	if err := p.store.Get("../"+names[0], func(io.Reader) error { return nil }); !testutils.IsError(err, "invalid profile name") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// Profiles in excess of the maximum combined size are removed.
+	maxCombinedFileSize.Override(&st.SV, 1)
+	p.store.GC(ctx, time.Now())
+	if names, err := p.store.List(); err != nil || len(names) != 0 {
+		t.Errorf("expected the profiles to be removed, got %v (%v)", names, err)
+	}
+}
+
+func TestParseFileName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 8e6, time.UTC)
+	name := makeFileName(ts, cpuProfileKind)
+	if e := "profile.2021-03-04T05_06_07.008.cpu.pprof"; name != e {
+		t.Fatalf("expected %s, got %s", e, name)
+	}
+	if actualTS, kind, ok := parseFileName(name); !ok || !actualTS.Equal(ts) || kind != cpuProfileKind {
+		t.Errorf("unexpected parse result %s %s %t", actualTS, kind, ok)
+	}
+	for _, name := range []string{
+		"memprof.2021-03-04T05_06_07.008.123.pprof",
+		"profile.2021-03-04T05_06_07.008.goroutine.pprof",
+		"profile.bogus.cpu.pprof",
+		"profile.2021-03-04T05_06_07.008.cpu",
+	} {
+		if _, _, ok := parseFileName(name); ok {
+			t.Errorf("expected %s not to be parsed", name)
+		}
+	}
+}
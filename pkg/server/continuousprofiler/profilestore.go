@@ -0,0 +1,132 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package continuousprofiler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/dumpstore"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	fileNamePrefix  = "profile."
+	fileNameSuffix  = ".pprof"
+	timestampFormat = "2006-01-02T15_04_05.000"
+
+	cpuProfileKind  = "cpu"
+	heapProfileKind = "heap"
+)
+
+// makeFileName returns the name of the file storing a profile of the given
+// kind taken at the given time. The timestamp is placed immediately after the
+// prefix so that the lexical order of the files is their chronological order,
+// as required by the dumpstore.
+func makeFileName(timestamp time.Time, kind string) string {
+	return fmt.Sprintf("%s%s.%s%s", fileNamePrefix, timestamp.Format(timestampFormat), kind, fileNameSuffix)
+}
+
+// parseFileName returns the time at which a profile was taken and its kind,
+// or false if the file name was not generated by makeFileName.
+func parseFileName(name string) (timestamp time.Time, kind string, ok bool) {
+	if !strings.HasPrefix(name, fileNamePrefix) || !strings.HasSuffix(name, fileNameSuffix) {
+		return time.Time{}, "", false
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, fileNamePrefix), fileNameSuffix)
+	i := strings.LastIndexByte(name, '.')
+	if i < 0 {
+		return time.Time{}, "", false
+	}
+	kind = name[i+1:]
+	if kind != cpuProfileKind && kind != heapProfileKind {
+		return time.Time{}, "", false
+	}
+	timestamp, err := time.Parse(timestampFormat, name[:i])
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return timestamp, kind, true
+}
+
+// ProfileStore represents the directory where the continuous profiler stores
+// its profiles. Its size is bounded by removing the oldest profiles. It also
+// serves the profiles to the pprof UI.
+type ProfileStore struct {
+	*dumpstore.DumpStore
+	dir string
+}
+
+var _ dumpstore.Dumper = (*ProfileStore)(nil)
+
+// NewProfileStore creates a ProfileStore for the given directory.
+func NewProfileStore(dir string, st *cluster.Settings) *ProfileStore {
+	return &ProfileStore{
+		DumpStore: dumpstore.NewStore(dir, maxCombinedFileSize, st),
+		dir:       dir,
+	}
+}
+
+// GC removes the oldest profiles in excess of the maximum combined size.
+func (s *ProfileStore) GC(ctx context.Context, now time.Time) {
+	s.DumpStore.GC(ctx, now, s)
+}
+
+// PreFilter is part of the dumpstore.Dumper interface.
+func (s *ProfileStore) PreFilter(
+	ctx context.Context, files []os.FileInfo, cleanupFn func(fileName string) error,
+) (preserved map[int]bool, _ error) {
+	// Only the size-based GC policy of the dumpstore applies.
+	return nil, nil
+}
+
+// CheckOwnsFile is part of the dumpstore.Dumper interface.
+func (s *ProfileStore) CheckOwnsFile(ctx context.Context, fi os.FileInfo) bool {
+	_, _, ok := parseFileName(fi.Name())
+	return ok
+}
+
+// List returns the names of the stored profiles, the most recent first.
+func (s *ProfileStore) List() ([]string, error) {
+	// NB: ioutil.ReadDir sorts the file names in ascending order, which is
+	// the chronological order of the profiles.
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for i := len(files) - 1; i >= 0; i-- {
+		if _, _, ok := parseFileName(files[i].Name()); ok {
+			names = append(names, files[i].Name())
+		}
+	}
+	return names, nil
+}
+
+// Get invokes the passed-in closure with a reader for the stored profile with
+// the given name.
+func (s *ProfileStore) Get(name string, read func(io.Reader) error) error {
+	if _, _, ok := parseFileName(name); !ok {
+		return errors.Errorf("invalid profile name %q", name)
+	}
+	f, err := os.Open(s.GetFullPath(name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return read(f)
+}
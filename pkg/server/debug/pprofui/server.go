@@ -13,6 +13,7 @@ package pprofui
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
 	"net/http/pprof"
@@ -35,14 +36,19 @@ import (
 // generates a profile of the desired type and redirects to the UI for
 // it at /<profiletype>/<id>. Valid profile types at the time of
 // writing include `profile` (cpu), `goroutine`, `threadcreate`,
-// `heap`, `block`, and `mutex`.
+// `heap`, `block`, and `mutex`. If StoredProfiles are set, they are
+// listed at /stored and their UI is served at /stored/<name>.
 type Server struct {
 	storage      Storage
+	stored       StoredProfiles
 	profileSem   syncutil.Mutex
 	profileTypes map[string]http.HandlerFunc
 	hook         func(profile string, labels bool, do func())
 }
 
+// storedProfilesPath is the path under which StoredProfiles are served.
+const storedProfilesPath = "stored"
+
 // NewServer creates a new Server backed by the supplied Storage and optionally
 // a hook which is called when a new profile is created. The closure passed to
 // the hook will carry out the work involved in creating the profile and must
@@ -93,6 +99,13 @@ func NewServer(storage Storage, hook func(profile string, labels bool, do func()
 	return s
 }
 
+// SetStoredProfiles makes the supplied profiles, which are collected outside
+// of the Server, available alongside those it creates. It must be called
+// before the Server serves requests.
+func (s *Server) SetStoredProfiles(stored StoredProfiles) {
+	s.stored = stored
+}
+
 // parsePath turns /profile/123/flamegraph/banana into (profile, 123, /flamegraph/banana).
 func (s *Server) parsePath(reqPath string) (profType string, id string, remainingPath string) {
 	parts := strings.Split(path.Clean(reqPath), "/")
@@ -120,16 +133,28 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		for name := range s.profileTypes {
 			names = append(names, name)
 		}
+		if s.stored != nil {
+			names = append(names, storedProfilesPath)
+		}
 		sort.Strings(names)
 		msg := fmt.Sprintf("Try %s for one of %s", path.Join(r.RequestURI, "<profileName>"), strings.Join(names, ", "))
 		http.Error(w, msg, http.StatusNotFound)
 		return
 	}
 
+	get := s.storage.Get
+	if profileName == storedProfilesPath && s.stored != nil {
+		if id == "" {
+			s.serveStoredProfilesList(w, r)
+			return
+		}
+		get = s.stored.Get
+	}
+
 	if id != "" {
 		// Catch nonexistent IDs early or pprof will do a worse job at
 		// giving an informative error.
-		if err := s.storage.Get(id, func(io.Reader) error { return nil }); err != nil {
+		if err := get(id, func(io.Reader) error { return nil }); err != nil {
 			msg := fmt.Sprintf("profile for id %s not found: %s", id, err)
 			http.Error(w, msg, http.StatusNotFound)
 			return
@@ -139,7 +164,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// TODO(tbg): this has zero discoverability.
 			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s_%s.pb.gz", profileName, id))
 			w.Header().Set("Content-Type", "application/octet-stream")
-			if err := s.storage.Get(id, func(r io.Reader) error {
+			if err := get(id, func(r io.Reader) error {
 				_, err := io.Copy(w, r)
 				return err
 			}); err != nil {
@@ -159,7 +184,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		storageFetcher := func(_ string, _, _ time.Duration) (*profile.Profile, string, error) {
 			var p *profile.Profile
-			if err := s.storage.Get(id, func(reader io.Reader) error {
+			if err := get(id, func(reader io.Reader) error {
 				var err error
 				p, err = profile.Parse(reader)
 				return err
@@ -254,6 +279,31 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// serveStoredProfilesList serves a page linking to the UI of each of the
+// StoredProfiles, and to their download.
+func (s *Server) serveStoredProfilesList(w http.ResponseWriter, r *http.Request) {
+	names, err := s.stored.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	origURL, err := url.Parse(r.RequestURI)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	var buf bytes.Buffer
+	buf.WriteString("<html><body><h1>Stored profiles</h1><ul>\n")
+	for _, name := range names {
+		p := path.Join(origURL.Path, url.PathEscape(name))
+		fmt.Fprintf(&buf, "<li>%s: <a href=\"%s\">flamegraph</a>, <a href=\"%s?download=true\">download</a></li>\n",
+			html.EscapeString(name), html.EscapeString(p+"/flamegraph"), html.EscapeString(p))
+	}
+	buf.WriteString("</ul></body></html>\n")
+	_, _ = w.Write(buf.Bytes())
+}
+
 type fetcherFn func(_ string, _, _ time.Duration) (*profile.Profile, string, error)
 
 func (f fetcherFn) Fetch(s string, d, t time.Duration) (*profile.Profile, string, error) {
@@ -11,11 +11,16 @@
 package pprofui
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"runtime/pprof"
 	"strings"
 	"testing"
+
+	"github.com/cockroachdb/errors"
 )
 
 func TestServer(t *testing.T) {
@@ -56,3 +61,64 @@ func TestServer(t *testing.T) {
 		}
 	}
 }
+
+// fakeStoredProfiles is a StoredProfiles backed by a map.
+type fakeStoredProfiles map[string][]byte
+
+func (f fakeStoredProfiles) List() ([]string, error) {
+	var names []string
+	for name := range f {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f fakeStoredProfiles) Get(name string, read func(io.Reader) error) error {
+	b, ok := f[name]
+	if !ok {
+		return errors.New("no such profile")
+	}
+	return read(bytes.NewReader(b))
+}
+
+func TestServerStoredProfiles(t *testing.T) {
+	var buf bytes.Buffer
+	if err := pprof.WriteHeapProfile(&buf); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(NewMemStorage(1, 0), nil)
+	s.SetStoredProfiles(fakeStoredProfiles{"some.heap": buf.Bytes()})
+
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		return w
+	}
+
+	w := get("/stored")
+	if a, e := w.Code, http.StatusOK; a != e {
+		t.Fatalf("expected status code %d, got %d", e, a)
+	}
+	for _, e := range []string{`href="/stored/some.heap/flamegraph"`, `href="/stored/some.heap?download=true"`} {
+		if a := w.Body.String(); !strings.Contains(a, e) {
+			t.Fatalf("body does not contain %q: %v", e, a)
+		}
+	}
+
+	w = get("/stored/some.heap/flamegraph")
+	if a, e := w.Code, http.StatusOK; a != e {
+		t.Fatalf("expected status code %d, got %d", e, a)
+	}
+	if a, e := w.Body.String(), "pprof</a></h1>"; !strings.Contains(a, e) {
+		t.Fatalf("body does not contain %q: %v", e, a)
+	}
+
+	w = get("/stored/some.heap?download=true")
+	if !bytes.Equal(w.Body.Bytes(), buf.Bytes()) {
+		t.Fatalf("downloaded profile does not match the stored one")
+	}
+
+	if a, e := get("/stored/other.heap/flamegraph").Code, http.StatusNotFound; a != e {
+		t.Fatalf("expected status code %d, got %d", e, a)
+	}
+}
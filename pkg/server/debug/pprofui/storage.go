@@ -22,3 +22,13 @@ type Storage interface {
 	// An error is returned when no data is found.
 	Get(id string, read func(io.Reader) error) error
 }
+
+// StoredProfiles exposes profiles which are collected outside of the Server,
+// such as those collected periodically in the background.
+type StoredProfiles interface {
+	// List returns the names of the available profiles.
+	List() ([]string, error)
+	// Get invokes the passed-in closure with a reader for the profile with the
+	// given name. An error is returned when no profile is found.
+	Get(name string, read func(io.Reader) error) error
+}
@@ -87,9 +87,10 @@ var DebugRemote = func() *settings.StringSetting {
 
 // Server serves the /debug/* family of tools.
 type Server struct {
-	st  *cluster.Settings
-	mux *http.ServeMux
-	spy logSpy
+	st      *cluster.Settings
+	mux     *http.ServeMux
+	spy     logSpy
+	pprofUI *pprofui.Server
 }
 
 // NewServer sets up a debug server.
@@ -186,9 +187,10 @@ func NewServer(st *cluster.Settings, hbaConfDebugFn http.HandlerFunc) *Server {
 	})
 
 	return &Server{
-		st:  st,
-		mux: mux,
-		spy: spy,
+		st:      st,
+		mux:     mux,
+		spy:     spy,
+		pprofUI: ps,
 	}
 }
 
@@ -218,6 +220,14 @@ func analyzeLSM(dir string, writer io.Writer) error {
 	return nil
 }
 
+// RegisterStoredProfiles makes the supplied profiles, such as those collected
+// by the continuous profiler, available in the pprof UI at
+// /debug/pprof/ui/stored. It must be called before the Server serves
+// requests.
+func (ds *Server) RegisterStoredProfiles(stored pprofui.StoredProfiles) {
+	ds.pprofUI.SetStoredProfiles(stored)
+}
+
 // RegisterEngines setups up debug engine endpoints for the known storage engines.
 func (ds *Server) RegisterEngines(specs []base.StoreSpec, engines []storage.Engine) error {
 	if len(specs) != len(engines) {
@@ -0,0 +1,642 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/gogo/protobuf/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// eventLogStreamPath is the HTTP endpoint serving the events of
+	// system.eventlog as server-sent events.
+	eventLogStreamPath = statusPrefix + "eventlog/stream"
+	// eventLogScanBatchSize is the number of events read per query of
+	// system.eventlog.
+	eventLogScanBatchSize = 100
+	// eventLogCheckpointKeyPrefix is the prefix of the keys of system.ui
+	// under which the checkpoints of the consumers of the event log are
+	// stored.
+	eventLogCheckpointKeyPrefix = "eventlog.checkpoint."
+)
+
+// maxPendingEventLogEvents is the number of events received from the
+// rangefeed over system.eventlog, but not yet delivered, beyond which the
+// latest ones are dropped. It is a variable for testing.
+var maxPendingEventLogEvents = 10000
+
+// errEventLogFeedOverflow is returned by feedEventLog once it delivered the
+// events it retained after dropping others, which are delivered by feeding
+// the event log again from there.
+var errEventLogFeedOverflow = errors.New("too many pending events")
+
+// eventLogPosition is a position in the stream of the events of
+// system.eventlog. The events are delivered in the order of the timestamps at
+// which they were committed, then of their primary key.
+//
+// The timestamp column of system.eventlog cannot order the stream, since it
+// is the read timestamp of the transaction which logged the event: events
+// committed later can have an earlier timestamp.
+type eventLogPosition struct {
+	// ts is the commit timestamp of the event or, for the events delivered by
+	// the initial scan of system.eventlog, the timestamp of the scan.
+	ts hlc.Timestamp
+	// scan is set for the events delivered by the initial scan, which are
+	// delivered in the order of their primary key only.
+	scan bool
+	// eventTS and uniqueID are the primary key of the event.
+	eventTS  time.Time
+	uniqueID []byte
+}
+
+// less returns whether p precedes o.
+func (p eventLogPosition) less(o eventLogPosition) bool {
+	if p.ts != o.ts {
+		return p.ts.Less(o.ts)
+	}
+	if p.scan != o.scan {
+		return p.scan
+	}
+	if !p.eventTS.Equal(o.eventTS) {
+		return p.eventTS.Before(o.eventTS)
+	}
+	return bytes.Compare(p.uniqueID, o.uniqueID) < 0
+}
+
+// encode returns the cursor of the position, as delivered to the consumers.
+func (p eventLogPosition) encode() string {
+	if p.ts.IsEmpty() {
+		return ""
+	}
+	phase := "c"
+	if p.scan {
+		phase = "s"
+	}
+	return fmt.Sprintf("%s/%s/%d/%s",
+		phase, p.ts, p.eventTS.UnixNano(), hex.EncodeToString(p.uniqueID))
+}
+
+// decodeEventLogCursor is the inverse of eventLogPosition.encode.
+func decodeEventLogCursor(cursor string) (eventLogPosition, error) {
+	if cursor == "" {
+		return eventLogPosition{}, nil
+	}
+	parts := strings.SplitN(cursor, "/", 4)
+	if len(parts) != 4 || (parts[0] != "c" && parts[0] != "s") {
+		return eventLogPosition{}, errors.Newf("invalid event log cursor %q", cursor)
+	}
+	ts, err := hlc.ParseTimestamp(parts[1])
+	if err != nil {
+		return eventLogPosition{}, errors.Wrapf(err, "invalid event log cursor %q", cursor)
+	}
+	nanos, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return eventLogPosition{}, errors.Wrapf(err, "invalid event log cursor %q", cursor)
+	}
+	uniqueID, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return eventLogPosition{}, errors.Wrapf(err, "invalid event log cursor %q", cursor)
+	}
+	return eventLogPosition{
+		ts:       ts,
+		scan:     parts[0] == "s",
+		eventTS:  timeutil.Unix(0, nanos),
+		uniqueID: uniqueID,
+	}, nil
+}
+
+// decodeEventLogKey returns the position of the event stored under the given
+// key of system.eventlog, without its timestamp.
+func decodeEventLogKey(key roachpb.Key) (eventLogPosition, error) {
+	rem, _, _, err := keys.SystemSQLCodec.DecodeIndexPrefix(key)
+	if err != nil {
+		return eventLogPosition{}, err
+	}
+	rem, eventTS, err := encoding.DecodeTimeAscending(rem)
+	if err != nil {
+		return eventLogPosition{}, err
+	}
+	_, uniqueID, err := encoding.DecodeBytesAscending(rem, nil)
+	if err != nil {
+		return eventLogPosition{}, err
+	}
+	return eventLogPosition{eventTS: eventTS, uniqueID: uniqueID}, nil
+}
+
+// StreamEventLog delivers the events of system.eventlog from the requested
+// position onwards, as they are logged.
+func (s *statusServer) StreamEventLog(
+	req *serverpb.EventLogStreamRequest, stream serverpb.Status_StreamEventLogServer,
+) error {
+	return s.streamEventLog(stream.Context(), req, stream.Send)
+}
+
+// streamEventLog delivers the events of system.eventlog to the given function
+// until it returns an error or the context is canceled.
+//
+// Without a cursor, the events committed so far are first scanned in the
+// order of their primary key. The events committed afterwards are then
+// delivered by a rangefeed, in the order of their commit timestamps.
+//
+// If the MVCC history of system.eventlog since the position of the cursor was
+// garbage collected, e.g. because the consumer was disconnected for longer
+// than the GC TTL of the table, the events are scanned again in the order of
+// their primary key, from that of the event of the cursor. The events
+// committed after it with an earlier timestamp are then not delivered, and
+// those committed before it with a later timestamp are delivered again.
+func (s *statusServer) streamEventLog(
+	ctx context.Context, req *serverpb.EventLogStreamRequest, send func(*serverpb.EventLogEvent) error,
+) error {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	userName, err := s.privilegeChecker.requireAdminUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	cursor := req.Cursor
+	if cursor == "" && req.Consumer != "" {
+		if cursor, err = s.getEventLogCheckpoint(ctx, userName, req.Consumer); err != nil {
+			return err
+		}
+	}
+	pos, err := decodeEventLogCursor(cursor)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if pos.ts.IsEmpty() {
+		pos = eventLogPosition{ts: s.db.Clock().Now(), scan: true, uniqueID: []byte{}}
+	}
+	for {
+		if pos.scan {
+			err = s.scanEventLog(ctx, userName, req, &pos, send)
+		}
+		if err == nil {
+			err = s.feedEventLog(ctx, userName, req, &pos, send)
+		}
+		switch {
+		case errors.Is(err, errEventLogFeedOverflow):
+			// Feed the events which were dropped.
+		case errors.HasType(err, (*roachpb.BatchTimestampBeforeGCError)(nil)):
+			log.Infof(ctx, "event log garbage collected since %s, scanning it again from %s: %v",
+				pos.ts, pos.eventTS, err)
+			pos = eventLogPosition{
+				ts: s.db.Clock().Now(), scan: true, eventTS: pos.eventTS, uniqueID: pos.uniqueID,
+			}
+		default:
+			return err
+		}
+		err = nil
+	}
+}
+
+// scanEventLog delivers the events which follow the given position of a scan
+// of system.eventlog, in the order of their primary key and as of the
+// timestamp of the scan, and advances the position past them.
+func (s *statusServer) scanEventLog(
+	ctx context.Context,
+	userName security.SQLUsername,
+	req *serverpb.EventLogStreamRequest,
+	pos *eventLogPosition,
+	send func(*serverpb.EventLogEvent) error,
+) error {
+	for {
+		events, err := s.queryEventLog(ctx, userName, req, pos.ts, func(q *sqlQuery) {
+			q.Append(`(timestamp, "uniqueID") > ($::TIMESTAMP, $::BYTES) `, pos.eventTS, pos.uniqueID)
+		})
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			ev := &events[i]
+			pos.eventTS, pos.uniqueID = ev.Timestamp, ev.UniqueID
+			ev.Cursor = pos.encode()
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+		if len(events) < eventLogScanBatchSize {
+			return nil
+		}
+	}
+}
+
+// feedEventLog delivers the events which follow the given position using a
+// rangefeed over system.eventlog, and advances the position past them. The
+// events are delivered once the resolved timestamp of the rangefeed reaches
+// their commit timestamp, after which no event can be committed before them.
+//
+// At most about maxPendingEventLogEvents events are retained until then:
+// beyond it, the events committed at or after some timestamp are dropped. The
+// rangefeed then stops once the resolved timestamp reaches it, and
+// errEventLogFeedOverflow is returned so that the events which follow the
+// position are fed again.
+func (s *statusServer) feedEventLog(
+	ctx context.Context,
+	userName security.SQLUsername,
+	req *serverpb.EventLogStreamRequest,
+	pos *eventLogPosition,
+	send func(*serverpb.EventLogEvent) error,
+) error {
+	// The rangefeed emits the values written after its start timestamp. The
+	// position of a scan is at the timestamp of the scan, all of whose events
+	// were delivered, while other events committed at the timestamp of the
+	// position may still follow it.
+	startTS := pos.ts
+	if !pos.scan {
+		startTS = pos.ts.Prev()
+	}
+	sp := systemschema.EventLogTable.PrimaryIndexSpan(keys.SystemSQLCodec)
+	frontier := span.MakeFrontier(sp)
+	frontier.Forward(sp, startTS)
+
+	eventCh := make(chan *roachpb.RangeFeedEvent, 128)
+	g := ctxgroup.WithContext(ctx)
+	g.GoCtx(func(ctx context.Context) error {
+		return s.distSender.RangeFeed(ctx, sp, startTS, false /* withDiff */, eventCh)
+	})
+	g.GoCtx(func(ctx context.Context) error {
+		var pending []eventLogPosition
+		// dropTS is set once events were dropped: all the events committed
+		// before it are pending or delivered, and those committed at or after it
+		// were dropped.
+		var dropTS hlc.Timestamp
+		for {
+			select {
+			case ev := <-eventCh:
+				switch t := ev.GetValue().(type) {
+				case *roachpb.RangeFeedValue:
+					// Deletions are those of the events past server.eventlog.ttl.
+					if !t.Value.IsPresent() {
+						continue
+					}
+					evPos, err := decodeEventLogKey(t.Key)
+					if err != nil {
+						return err
+					}
+					evPos.ts = t.Value.Timestamp
+					if !pos.less(evPos) || (!dropTS.IsEmpty() && !evPos.ts.Less(dropTS)) {
+						continue
+					}
+					pending = append(pending, evPos)
+					if len(pending) > maxPendingEventLogEvents {
+						var ts hlc.Timestamp
+						if pending, ts = dropLatestEventLogEvents(pending); !ts.IsEmpty() {
+							dropTS = ts
+						}
+					}
+				case *roachpb.RangeFeedCheckpoint:
+					if !frontier.Forward(t.Span, t.ResolvedTS) {
+						continue
+					}
+					resolved := frontier.Frontier()
+					var err error
+					pending, err = s.flushEventLog(ctx, userName, req, resolved, pending, pos, send)
+					if err != nil {
+						return err
+					}
+					if !dropTS.IsEmpty() && !resolved.Less(dropTS.Prev()) {
+						return errEventLogFeedOverflow
+					}
+				case *roachpb.RangeFeedError:
+					return t.Error.GoError()
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-s.stopper.ShouldQuiesce():
+				return status.Errorf(codes.Unavailable, "server is shutting down")
+			}
+		}
+	})
+	return g.Wait()
+}
+
+// dropLatestEventLogEvents drops about half of the given pending events, the
+// latest ones, and returns the others as well as the timestamp at and after
+// which the events were dropped. The events committed at the same timestamp
+// are all either dropped or retained, so no events are dropped if they were
+// all committed at the same timestamp.
+func dropLatestEventLogEvents(pending []eventLogPosition) ([]eventLogPosition, hlc.Timestamp) {
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].less(pending[j])
+	})
+	cutoff := pending[len(pending)/2].ts
+	n := sort.Search(len(pending), func(i int) bool {
+		return !pending[i].ts.Less(cutoff)
+	})
+	if n == 0 {
+		n = sort.Search(len(pending), func(i int) bool {
+			return cutoff.Less(pending[i].ts)
+		})
+	}
+	if n == len(pending) {
+		return pending, hlc.Timestamp{}
+	}
+	return pending[:n], pending[n].ts
+}
+
+// flushEventLog delivers the pending events committed up to the resolved
+// timestamp, advances the position past them and returns the other pending
+// events.
+func (s *statusServer) flushEventLog(
+	ctx context.Context,
+	userName security.SQLUsername,
+	req *serverpb.EventLogStreamRequest,
+	resolved hlc.Timestamp,
+	pending []eventLogPosition,
+	pos *eventLogPosition,
+	send func(*serverpb.EventLogEvent) error,
+) ([]eventLogPosition, error) {
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].less(pending[j])
+	})
+	n := sort.Search(len(pending), func(i int) bool {
+		return resolved.Less(pending[i].ts)
+	})
+	for i := 0; i < n; i += eventLogScanBatchSize {
+		batch := pending[i:n]
+		if len(batch) > eventLogScanBatchSize {
+			batch = batch[:eventLogScanBatchSize]
+		}
+		events, err := s.queryEventLog(ctx, userName, req, resolved, func(q *sqlQuery) {
+			q.Append(`(timestamp, "uniqueID") IN (`)
+			for j, evPos := range batch {
+				if j > 0 {
+					q.Append(", ")
+				}
+				q.Append("($::TIMESTAMP, $::BYTES)", evPos.eventTS, evPos.uniqueID)
+			}
+			q.Append(") ")
+		})
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]*serverpb.EventLogEvent, len(events))
+		for j := range events {
+			byID[string(events[j].UniqueID)] = &events[j]
+		}
+		for _, evPos := range batch {
+			// A rangefeed can emit the same value more than once.
+			if !pos.less(evPos) {
+				continue
+			}
+			*pos = evPos
+			// The events which are not found are not of the requested types.
+			ev, ok := byID[string(evPos.uniqueID)]
+			if !ok {
+				continue
+			}
+			ev.Cursor = pos.encode()
+			if err := send(ev); err != nil {
+				return nil, err
+			}
+		}
+	}
+	tail := copy(pending, pending[n:])
+	return pending[:tail], nil
+}
+
+// queryEventLog returns the events of system.eventlog as of the given
+// timestamp which satisfy the condition appended by the given function, in
+// the order of their primary key and up to eventLogScanBatchSize of them.
+func (s *statusServer) queryEventLog(
+	ctx context.Context,
+	userName security.SQLUsername,
+	req *serverpb.EventLogStreamRequest,
+	asOf hlc.Timestamp,
+	appendCond func(q *sqlQuery),
+) ([]serverpb.EventLogEvent, error) {
+	q := makeSQLQuery()
+	q.Append(`SELECT timestamp, "eventType", "targetID", "reportingID", info, "uniqueID" `)
+	q.Append("FROM system.eventlog AS OF SYSTEM TIME " + asOf.AsOfSystemTime() + " ")
+	q.Append("WHERE ")
+	appendCond(q)
+	if len(req.EventTypes) > 0 {
+		q.Append(`AND "eventType" = ANY ($::STRING[]) `, req.EventTypes)
+	}
+	q.Append(`ORDER BY timestamp, "uniqueID" `)
+	q.Append("LIMIT $", eventLogScanBatchSize)
+	if len(q.Errors()) > 0 {
+		return nil, q.Errors()[0]
+	}
+	rows, cols, err := s.internalExecutor.QueryWithCols(
+		ctx, "export-events", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: userName},
+		q.String(), q.QueryArguments()...)
+	if err != nil {
+		return nil, err
+	}
+
+	redactEvents := !req.UnredactedEvents
+	events := make([]serverpb.EventLogEvent, len(rows))
+	scanner := makeResultScanner(cols)
+	for i, row := range rows {
+		event := &events[i]
+		if err := scanner.ScanIndex(row, 0, &event.Timestamp); err != nil {
+			return nil, err
+		}
+		if err := scanner.ScanIndex(row, 1, &event.EventType); err != nil {
+			return nil, err
+		}
+		if err := scanner.ScanIndex(row, 2, &event.TargetID); err != nil {
+			return nil, err
+		}
+		if err := scanner.ScanIndex(row, 3, &event.ReportingID); err != nil {
+			return nil, err
+		}
+		if err := scanner.ScanIndex(row, 4, &event.Info); err != nil {
+			return nil, err
+		}
+		if err := scanner.ScanIndex(row, 5, &event.UniqueID); err != nil {
+			return nil, err
+		}
+		if redactEvents {
+			if event.EventType == eventSetClusterSettingName {
+				event.Info = redactSettingsChange(event.Info)
+			}
+			event.Info = redactStatement(event.Info)
+		}
+		event.Payload = eventLogPayload(ctx, event.EventType, event.Info)
+	}
+	return events, nil
+}
+
+// eventLogPayload returns the payload of an event of the given type as a
+// message of the eventpb package, or nil if the type of the event is not known
+// or its payload cannot be decoded.
+func eventLogPayload(ctx context.Context, eventType string, info string) *types.Any {
+	payload, ok := eventpb.NewEventPayload(eventType).(protoutil.Message)
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(info), payload); err != nil {
+		log.VEventf(ctx, 2, "failed to decode the payload of a %s event: %v", eventType, err)
+		return nil
+	}
+	res, err := types.MarshalAny(payload)
+	if err != nil {
+		log.VEventf(ctx, 2, "failed to encode the payload of a %s event: %v", eventType, err)
+		return nil
+	}
+	return res
+}
+
+// EventLogCheckpoint records and/or retrieves the position in the event log up
+// to which a consumer has processed the events. The checkpoints are stored in
+// system.ui, separately for each user.
+func (s *statusServer) EventLogCheckpoint(
+	ctx context.Context, req *serverpb.EventLogCheckpointRequest,
+) (*serverpb.EventLogCheckpointResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	userName, err := s.privilegeChecker.requireAdminUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Consumer == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "consumer cannot be empty")
+	}
+
+	if req.Cursor != "" {
+		if _, err := decodeEventLogCursor(req.Cursor); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		if _, err := s.internalExecutor.ExecEx(
+			ctx, "set-eventlog-checkpoint", nil, /* txn */
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`UPSERT INTO system.ui (key, value, "lastUpdated") VALUES ($1, $2, now())`,
+			makeUIKey(userName, eventLogCheckpointKeyPrefix+req.Consumer), []byte(req.Cursor),
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	cursor, err := s.getEventLogCheckpoint(ctx, userName, req.Consumer)
+	if err != nil {
+		return nil, err
+	}
+	return &serverpb.EventLogCheckpointResponse{Cursor: cursor}, nil
+}
+
+// getEventLogCheckpoint returns the checkpoint of the given consumer of the
+// event log, or an empty string if none was recorded.
+func (s *statusServer) getEventLogCheckpoint(
+	ctx context.Context, userName security.SQLUsername, consumer string,
+) (string, error) {
+	key := eventLogCheckpointKeyPrefix + consumer
+	resp, err := s.admin.getUIData(ctx, userName, []string{key})
+	if err != nil {
+		return "", err
+	}
+	return string(resp.KeyValues[key].Value), nil
+}
+
+// handleEventLogStream serves the events of system.eventlog as server-sent
+// events. The request is specified by the URL parameters cursor, consumer,
+// event_types (comma-separated) and unredacted_events. The cursor of each
+// event is sent as its ID, so that a client reconnecting with the
+// Last-Event-ID header resumes the delivery after the last event it received.
+func (s *statusServer) handleEventLogStream(w http.ResponseWriter, r *http.Request) {
+	// The privileges of the user are checked by streamEventLog, based on the
+	// gRPC metadata.
+	md := forwardAuthenticationMetadata(r.Context(), r)
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+
+	query := r.URL.Query()
+	req := serverpb.EventLogStreamRequest{
+		Cursor:   query.Get("cursor"),
+		Consumer: query.Get("consumer"),
+	}
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		req.Cursor = lastEventID
+	}
+	if eventTypes := query.Get("event_types"); eventTypes != "" {
+		req.EventTypes = strings.Split(eventTypes, ",")
+	}
+	if unredacted := query.Get("unredacted_events"); unredacted != "" {
+		var err error
+		if req.UnredactedEvents, err = strconv.ParseBool(unredacted); err != nil {
+			http.Error(w, "invalid unredacted_events parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+		if errors.Is(err, errRequiresAdmin) {
+			http.Error(w, "admin privilege required", http.StatusUnauthorized)
+		} else {
+			log.Ops.Infof(ctx, "web session error: %s", err)
+			http.Error(w, "error checking authentication", http.StatusInternalServerError)
+		}
+		return
+	}
+	if _, err := decodeEventLogCursor(req.Cursor); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	marshaler := protoutil.JSONPb{}
+	err := s.streamEventLog(ctx, &req, func(ev *serverpb.EventLogEvent) error {
+		data, err := marshaler.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.Cursor, ev.EventType, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Ops.Warningf(ctx, "event log stream error: %v", err)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/url"
 	"sort"
 	"time"
 
@@ -30,6 +31,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvtenant"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantcostmodel"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantusage"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
@@ -38,7 +41,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/bootstrap"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -65,6 +70,10 @@ const (
 	FirstNodeID         = 1
 	graphiteIntervalKey = "external.graphite.interval"
 	maxGraphiteInterval = 15 * time.Minute
+	// maxRemoteWriteInterval is the maximum interval at which metrics are
+	// pushed to a Prometheus remote write endpoint. Prometheus considers
+	// series without samples for 5 minutes to be stale.
+	maxRemoteWriteInterval = 5 * time.Minute
 )
 
 // Metric names.
@@ -111,6 +120,34 @@ var (
 		10*time.Second,
 		settings.NonNegativeDurationWithMaximum(maxGraphiteInterval),
 	).WithPublic()
+	// remoteWriteEndpoint is the URL, if any, of the Prometheus remote write
+	// endpoint to push metrics to.
+	remoteWriteEndpoint = settings.RegisterValidatedStringSetting(
+		"external.prometheus.remote_write.endpoint",
+		"if nonempty, push server metrics to the Prometheus remote write endpoint at the specified http or https URL",
+		"",
+		func(_ *settings.Values, s string) error {
+			if s == "" {
+				return nil
+			}
+			u, err := url.Parse(s)
+			if err != nil {
+				return err
+			}
+			if u.Scheme != "http" && u.Scheme != "https" {
+				return errors.Newf("unsupported scheme %q, expected http or https", u.Scheme)
+			}
+			return nil
+		},
+	).WithPublic()
+	// remoteWriteInterval is how often metrics are pushed to the Prometheus
+	// remote write endpoint, if enabled.
+	remoteWriteInterval = settings.RegisterDurationSetting(
+		"external.prometheus.remote_write.interval",
+		"the interval at which metrics are pushed to the Prometheus remote write endpoint (if enabled)",
+		10*time.Second,
+		settings.NonNegativeDurationWithMaximum(maxRemoteWriteInterval),
+	).WithPublic()
 )
 
 type nodeMetrics struct {
@@ -165,11 +202,16 @@ type Node struct {
 	lastUp       int64
 	initialStart bool // True if this is the first time this node has started.
 	txnMetrics   kvcoord.TxnMetrics
+	kvAdmissionQ *admission.WorkQueue // Admission control queue for KV work
 
 	// Used to signal when additional stores, if any, have been initialized.
 	additionalStoreInitCh chan struct{}
 
 	perReplicaServer kvserver.Server
+
+	// tenantUsage meters the resources consumed by the KV requests of tenants
+	// and by their SQL pods.
+	tenantUsage *tenantusage.Accumulator
 }
 
 var _ roachpb.InternalServer = &Node{}
@@ -289,20 +331,24 @@ func NewNode(
 	txnMetrics kvcoord.TxnMetrics,
 	execCfg *sql.ExecutorConfig,
 	clusterID *base.ClusterIDContainer,
+	kvAdmissionQ *admission.WorkQueue,
+	tenantUsage *tenantusage.Accumulator,
 ) *Node {
 	var sqlExec *sql.InternalExecutor
 	if execCfg != nil {
 		sqlExec = execCfg.InternalExecutor
 	}
 	n := &Node{
-		storeCfg:   cfg,
-		stopper:    stopper,
-		recorder:   recorder,
-		metrics:    makeNodeMetrics(reg, cfg.HistogramWindowInterval),
-		stores:     kvserver.NewStores(cfg.AmbientCtx, cfg.Clock),
-		txnMetrics: txnMetrics,
-		sqlExec:    sqlExec,
-		clusterID:  clusterID,
+		storeCfg:     cfg,
+		stopper:      stopper,
+		recorder:     recorder,
+		metrics:      makeNodeMetrics(reg, cfg.HistogramWindowInterval),
+		stores:       kvserver.NewStores(cfg.AmbientCtx, cfg.Clock),
+		txnMetrics:   txnMetrics,
+		sqlExec:      sqlExec,
+		clusterID:    clusterID,
+		kvAdmissionQ: kvAdmissionQ,
+		tenantUsage:  tenantUsage,
 	}
 	n.perReplicaServer = kvserver.MakeServer(&n.Descriptor, n.stores)
 	return n
@@ -704,6 +750,31 @@ func (n *Node) startGraphiteStatsExporter(st *cluster.Settings) {
 	})
 }
 
+func (n *Node) startPrometheusRemoteWriteExporter(st *cluster.Settings) {
+	ctx := logtags.AddTag(n.AnnotateCtx(context.Background()), "prometheus remote write exporter", nil)
+	pm := metric.MakePrometheusExporter()
+
+	_ = n.stopper.RunAsyncTask(ctx, "prometheus-remote-write-exporter", func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(remoteWriteInterval.Get(&st.SV))
+			select {
+			case <-n.stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+				endpoint := remoteWriteEndpoint.Get(&st.SV)
+				if endpoint != "" {
+					if err := n.recorder.ExportToPrometheusRemoteWrite(ctx, endpoint, &pm); err != nil {
+						log.Infof(ctx, "error pushing metrics to prometheus remote write endpoint: %s", err)
+					}
+				}
+			}
+		}
+	})
+}
+
 // startWriteNodeStatus begins periodically persisting status summaries for the
 // node and its stores.
 func (n *Node) startWriteNodeStatus(frequency time.Duration) error {
@@ -870,6 +941,20 @@ func (n *Node) batchInternal(
 			log.Eventf(ctx, "node received request: %s", args.Summary())
 		}
 
+		tenantID, ok := roachpb.TenantFromContext(ctx)
+		if !ok {
+			tenantID = roachpb.SystemTenantID
+		}
+		if n.kvAdmissionQ != nil {
+			enabled, err := n.kvAdmissionQ.Admit(ctx, kvAdmissionInfo(args, tenantID))
+			if err != nil {
+				return err
+			}
+			if enabled {
+				defer n.kvAdmissionQ.AdmittedWorkDone(tenantID)
+			}
+		}
+
 		tStart := timeutil.Now()
 		var pErr *roachpb.Error
 		br, pErr = n.stores.Send(ctx, *args)
@@ -881,6 +966,13 @@ func (n *Node) batchInternal(
 			panic(roachpb.ErrorUnexpectedlySet(n.stores, br))
 		}
 		n.metrics.callComplete(timeutil.Since(tStart), pErr)
+		if n.tenantUsage != nil {
+			var respInfo tenantcostmodel.ResponseInfo
+			if pErr == nil {
+				respInfo = tenantcostmodel.MakeResponseInfo(br)
+			}
+			n.tenantUsage.RecordKVBatch(tenantID, tenantcostmodel.MakeRequestInfo(args), respInfo)
+		}
 		br.Error = pErr
 		return nil
 	}); err != nil {
@@ -889,6 +981,85 @@ func (n *Node) batchInternal(
 	return br, nil
 }
 
+// kvAdmissionInfo returns the information used to order the batch in the
+// admission control queue for KV work.
+//
+// Work is prioritized according to the user priority of the batch, which
+// reflects the HIGH and LOW transaction priorities of SQL. Bulk and background
+// work, such as AddSSTable requests issued by imports and index backfills, is
+// given low priority so that it yields to foreground traffic. Requests which
+// coordinate transactions and leases, and requests to the meta and system
+// ranges, bypass admission: they are cheap, and delaying them could hold up
+// admitted work that is waiting on them, or the health of the cluster.
+//
+// Requests of transactions which hold locks bypass admission as well. Admitted
+// work holds its slot until it is done, including while it waits for latches
+// and locks in the concurrency manager, so if all slots were taken by such
+// waiters, the lock holders they are waiting for could otherwise never be
+// admitted to release them.
+func kvAdmissionInfo(ba *roachpb.BatchRequest, tenantID roachpb.TenantID) admission.WorkInfo {
+	info := admission.WorkInfo{
+		TenantID:   tenantID,
+		Priority:   admission.NormalPri,
+		CreateTime: timeutil.Now().UnixNano(),
+	}
+	switch {
+	case ba.UserPriority >= roachpb.MaxUserPriority:
+		info.Priority = admission.HighPri
+	case ba.UserPriority > 0 && ba.UserPriority <= roachpb.MinUserPriority:
+		info.Priority = admission.LowPri
+	case ba.UserPriority == 0 && ba.Txn != nil:
+		// Senders which don't set the user priority of transactional batches
+		// are only recognized by the priority of the transaction.
+		switch ba.Txn.Priority {
+		case enginepb.MaxTxnPriority:
+			info.Priority = admission.HighPri
+		case enginepb.MinTxnPriority:
+			info.Priority = admission.LowPri
+		}
+	}
+	if ba.Txn != nil {
+		// Order the work of a transaction by its start time, so that older
+		// transactions are admitted first.
+		info.CreateTime = ba.Txn.MinTimestamp.WallTime
+		if holdsLocks(ba) {
+			info.BypassAdmission = true
+		}
+	}
+	if len(ba.Requests) > 0 && ba.Requests[0].GetInner().Header().Key.Compare(keys.TableDataMin) < 0 {
+		info.BypassAdmission = true
+	}
+	for _, ru := range ba.Requests {
+		switch ru.GetInner().Method() {
+		case roachpb.AddSSTable, roachpb.GC, roachpb.Export, roachpb.RevertRange, roachpb.Fingerprint:
+			info.Priority = admission.LowPri
+		case roachpb.HeartbeatTxn, roachpb.PushTxn, roachpb.QueryTxn, roachpb.RecoverTxn,
+			roachpb.ResolveIntent, roachpb.ResolveIntentRange, roachpb.QueryIntent,
+			roachpb.RequestLease, roachpb.TransferLease, roachpb.LeaseInfo:
+			info.BypassAdmission = true
+		}
+	}
+	return info
+}
+
+// holdsLocks returns whether the transaction of the batch holds locks acquired
+// by earlier batches. The anchor key of a transaction is set to the key of the
+// first locking request of the batch which begins acquiring its locks, so a
+// batch whose first locking request is on the anchor key is not considered to
+// hold locks. This also errs on the side of admission for later batches which
+// lock the anchor key first.
+func holdsLocks(ba *roachpb.BatchRequest) bool {
+	if ba.Txn == nil || !ba.Txn.IsLocking() {
+		return false
+	}
+	for _, ru := range ba.Requests {
+		if req := ru.GetInner(); roachpb.IsLocking(req) {
+			return !req.Header().Key.Equal(ba.Txn.Key)
+		}
+	}
+	return true
+}
+
 // Batch implements the roachpb.InternalServer interface.
 func (n *Node) Batch(
 	ctx context.Context, args *roachpb.BatchRequest,
@@ -1126,6 +1297,20 @@ func (n *Node) ResetQuorum(
 	return &roachpb.ResetQuorumResponse{}, nil
 }
 
+// ReportTenantConsumption implements the roachpb.InternalServer interface.
+func (n *Node) ReportTenantConsumption(
+	ctx context.Context, req *roachpb.ReportTenantConsumptionRequest,
+) (*roachpb.ReportTenantConsumptionResponse, error) {
+	if req.TenantID == 0 || roachpb.IsSystemTenantID(req.TenantID) {
+		return nil, errors.Errorf("invalid tenant ID %d", req.TenantID)
+	}
+	if n.tenantUsage != nil {
+		n.tenantUsage.RecordSQLPodsCPU(
+			roachpb.MakeTenantID(req.TenantID), req.ConsumptionSinceLastRequest.SQLPodsCPUSeconds)
+	}
+	return &roachpb.ReportTenantConsumptionResponse{}, nil
+}
+
 // GossipSubscription implements the roachpb.InternalServer interface.
 func (n *Node) GossipSubscription(
 	args *roachpb.GossipSubscriptionRequest, stream roachpb.Internal_GossipSubscriptionServer,
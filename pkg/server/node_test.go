@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -649,3 +650,154 @@ func TestNodeSendUnknownBatchRequest(t *testing.T) {
 		t.Fatalf("expected unsupported request, not %v", br.Error)
 	}
 }
+
+func TestKVAdmissionInfo(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tableKey := keys.SystemSQLCodec.TablePrefix(100)
+	txn := roachpb.MakeTransaction("test", tableKey, roachpb.NormalUserPriority,
+		hlc.Timestamp{WallTime: 123}, 0)
+	highPriTxn := roachpb.MakeTransaction("test", tableKey, roachpb.MaxUserPriority,
+		hlc.Timestamp{WallTime: 123}, 0)
+	tenantID := roachpb.MakeTenantID(10)
+
+	lockingTxn := txn.Clone()
+	lockingTxn.Key = tableKey.Next()
+	testCases := []struct {
+		name         string
+		txn          *roachpb.Transaction
+		userPriority roachpb.UserPriority
+		req          roachpb.Request
+		tenantID     roachpb.TenantID
+		priority     admission.WorkPriority
+		bypass       bool
+	}{
+		{
+			name:     "get",
+			req:      &roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID: roachpb.SystemTenantID,
+			priority: admission.NormalPri,
+		},
+		{
+			name:     "txn",
+			txn:      &txn,
+			req:      &roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID: roachpb.SystemTenantID,
+			priority: admission.NormalPri,
+		},
+		{
+			name:     "high priority txn",
+			txn:      &highPriTxn,
+			req:      &roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID: roachpb.SystemTenantID,
+			priority: admission.HighPri,
+		},
+		{
+			name:         "high user priority txn",
+			txn:          &txn,
+			userPriority: roachpb.MaxUserPriority,
+			req:          &roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID:     roachpb.SystemTenantID,
+			priority:     admission.HighPri,
+		},
+		{
+			name:         "low user priority txn",
+			txn:          &txn,
+			userPriority: roachpb.MinUserPriority,
+			req:          &roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID:     roachpb.SystemTenantID,
+			priority:     admission.LowPri,
+		},
+		{
+			name:         "high user priority",
+			userPriority: roachpb.MaxUserPriority,
+			req:          &roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID:     tenantID,
+			priority:     admission.HighPri,
+		},
+		{
+			name:     "txn holding locks",
+			txn:      lockingTxn,
+			req:      &roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID: roachpb.SystemTenantID,
+			priority: admission.NormalPri,
+			bypass:   true,
+		},
+		{
+			name:     "bulk ingestion",
+			req:      &roachpb.AddSSTableRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID: tenantID,
+			priority: admission.LowPri,
+		},
+		{
+			name:     "gc",
+			req:      &roachpb.GCRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID: roachpb.SystemTenantID,
+			priority: admission.LowPri,
+		},
+		{
+			name:     "system range",
+			req:      &roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: keys.NodeLivenessKey(1)}},
+			tenantID: roachpb.SystemTenantID,
+			priority: admission.NormalPri,
+			bypass:   true,
+		},
+		{
+			name:     "push",
+			req:      &roachpb.PushTxnRequest{RequestHeader: roachpb.RequestHeader{Key: tableKey}},
+			tenantID: roachpb.SystemTenantID,
+			priority: admission.NormalPri,
+			bypass:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ba roachpb.BatchRequest
+			ba.Txn = tc.txn
+			ba.UserPriority = tc.userPriority
+			ba.Add(tc.req)
+			info := kvAdmissionInfo(&ba, tc.tenantID)
+			require.Equal(t, tc.tenantID, info.TenantID)
+			require.Equal(t, tc.priority, info.Priority)
+			require.Equal(t, tc.bypass, info.BypassAdmission)
+			if tc.txn != nil {
+				require.Equal(t, tc.txn.MinTimestamp.WallTime, info.CreateTime)
+			}
+		})
+	}
+}
+
+// TestNodeBatchAdmission verifies that KV and SQL work is subject to admission
+// control once it is enabled.
+func TestNodeBatchAdmission(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	gcoord := s.(*TestServer).gcoord
+	kvMetrics := gcoord.GetWorkQueue(admission.KVWork).Metrics()
+	sqlMetrics := gcoord.GetWorkQueue(admission.SQLKVResponseWork).Metrics()
+
+	// Admission control is disabled by default.
+	require.NoError(t, kvDB.Put(ctx, "a", "b"))
+	require.Equal(t, int64(0), kvMetrics.Requested.Count())
+
+	_, err := sqlDB.Exec(`SET CLUSTER SETTING admission.kv.enabled = true`)
+	require.NoError(t, err)
+	_, err = sqlDB.Exec(`SET CLUSTER SETTING admission.sql_kv_response.enabled = true`)
+	require.NoError(t, err)
+	_, err = sqlDB.Exec(`CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	require.NoError(t, err)
+	_, err = sqlDB.Exec(`INSERT INTO t VALUES (1, 1), (2, 2)`)
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, sqlDB.QueryRow(`SELECT count(*) FROM t`).Scan(&count))
+	require.Equal(t, 2, count)
+
+	require.Greater(t, kvMetrics.Admitted.Count(), int64(0))
+	require.Greater(t, sqlMetrics.Admitted.Count(), int64(0))
+	require.Equal(t, int64(0), kvMetrics.Errored.Count())
+}
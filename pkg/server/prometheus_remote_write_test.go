@@ -0,0 +1,99 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/golang/snappy"
+)
+
+// TestPrometheusRemoteWrite tests that a server pushes its metrics to a
+// Prometheus remote write endpoint, if configured.
+func TestPrometheusRemoteWrite(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	s, rawDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.Background())
+	db := sqlutils.MakeSQLRunner(rawDB)
+
+	var mu struct {
+		syncutil.Mutex
+		// values maps the names of the received series labeled with the ID of
+		// the node to their last value.
+		values map[string]float64
+	}
+	mu.values = make(map[string]float64)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		compressed, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var wr metric.RemoteWriteRequest
+		if err := wr.Unmarshal(data); err != nil {
+			t.Error(err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, ts := range wr.Timeseries {
+			var name, nodeID string
+			for _, l := range ts.Labels {
+				switch l.Name {
+				case "__name__":
+					name = l.Value
+				case "node_id":
+					nodeID = l.Value
+				}
+			}
+			if nodeID == "1" && len(ts.Samples) > 0 {
+				mu.values[name] = ts.Samples[len(ts.Samples)-1].Value
+			}
+		}
+	}))
+	defer srv.Close()
+
+	db.ExpectErr(t, `unsupported scheme "ftp"`,
+		`SET CLUSTER SETTING external.prometheus.remote_write.endpoint = 'ftp://localhost'`)
+	db.Exec(t, `SET CLUSTER SETTING external.prometheus.remote_write.interval = '10ms'`)
+	db.Exec(t, `SET CLUSTER SETTING external.prometheus.remote_write.endpoint = $1`, srv.URL)
+
+	testutils.SucceedsSoon(t, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		// Check a node-level gauge, and the series of a histogram.
+		for _, name := range []string{"sys_uptime", "exec_latency_bucket", "exec_latency_count"} {
+			if _, ok := mu.values[name]; !ok {
+				return errors.Errorf("series %s not received yet", name)
+			}
+		}
+		return nil
+	})
+}
@@ -47,10 +47,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptprovider"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptreconcile"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/reports"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantusage"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/rpc/nodedialer"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/continuousprofiler"
 	"github.com/cockroachdb/cockroach/pkg/server/debug"
 	"github.com/cockroachdb/cockroach/pkg/server/diagnostics"
 	"github.com/cockroachdb/cockroach/pkg/server/goroutinedumper"
@@ -61,6 +63,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
 	_ "github.com/cockroachdb/cockroach/pkg/sql/gcjob" // register jobs declared outside of pkg/sql
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
@@ -70,8 +73,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/ts"
+	"github.com/cockroachdb/cockroach/pkg/ts/alerting"
 	"github.com/cockroachdb/cockroach/pkg/ui"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
@@ -156,8 +161,12 @@ type Server struct {
 	debug *debug.Server
 
 	replicationReporter   *reports.Reporter
+	gcoord                *admission.GrantCoordinator
 	protectedtsProvider   protectedts.Provider
 	protectedtsReconciler *ptreconcile.Reconciler
+	tenantUsage           *tenantusage.Accumulator
+	contentionHistory     *contention.History
+	alertEvaluator        *alerting.Evaluator
 
 	sqlServer *SQLServer
 
@@ -414,6 +423,13 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	dbCtx.Stopper = stopper
 	db := kv.NewDBWithContext(cfg.AmbientCtx, tcsFactory, clock, dbCtx)
 
+	// The GrantCoordinator queues KV and SQL work when the node is overloaded.
+	gcoord := admission.NewGrantCoordinator(st)
+	for _, m := range gcoord.MetricStructs() {
+		registry.AddMetricStruct(m)
+	}
+	db.SQLKVResponseAdmissionQ = gcoord.GetWorkQueue(admission.SQLKVResponseWork)
+
 	nlActive, nlRenewal := cfg.NodeLivenessDurations()
 	if knobs := cfg.TestingKnobs.NodeLiveness; knobs != nil {
 		nlKnobs := knobs.(kvserver.NodeLivenessTestingKnobs)
@@ -496,6 +512,11 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		return nil, err
 	}
 
+	// The contention events observed by the stores of this node are recorded
+	// in the contention history, and flushed by it to
+	// system.transaction_contention_events.
+	contentionHistory := contention.NewHistory(st, db, internalExecutor, nodeIDContainer)
+
 	// Break a circular dependency: we need a Node to make a StoreConfig (for
 	// ClosedTimestamp), but the Node needs a StoreConfig to be made.
 	var lateBoundNode *Node
@@ -546,6 +567,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		ExternalStorage:         externalStorage,
 		ExternalStorageFromURI:  externalStorageFromURI,
 		ProtectedTimestampCache: protectedtsProvider,
+		ContentionEventListener: contentionHistory,
 	}
 	if storeTestingKnobs := cfg.TestingKnobs.Store; storeTestingKnobs != nil {
 		storeCfg.TestingKnobs = *storeTestingKnobs.(*kvserver.StoreTestingKnobs)
@@ -567,9 +589,12 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		updates.TestingKnobs = &cfg.TestingKnobs.Server.(*TestingKnobs).DiagnosticsTestingKnobs
 	}
 
+	tenantUsage := tenantusage.NewAccumulator(st, db, internalExecutor, nodeIDContainer)
+
 	node := NewNode(
 		storeCfg, recorder, registry, stopper,
-		txnMetrics, nil /* execCfg */, &rpcContext.ClusterID)
+		txnMetrics, nil /* execCfg */, &rpcContext.ClusterID,
+		gcoord.GetWorkQueue(admission.KVWork), tenantUsage)
 	lateBoundNode = node
 	roachpb.RegisterInternalServer(grpcServer.Server, node)
 	kvserver.RegisterPerReplicaServer(grpcServer.Server, node.perReplicaServer)
@@ -578,6 +603,17 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	replicationReporter := reports.NewReporter(
 		db, node.stores, storePool, st, nodeLiveness, internalExecutor)
 
+	// Alert rules are evaluated by the leaseholder of the first range, so that
+	// their state changes are reported once.
+	alertEvaluator := alerting.NewEvaluator(st, internalExecutor, &sTS, func(ctx context.Context) bool {
+		isLeaseholder, err := node.stores.IsMeta1Leaseholder(ctx, clock.NowAsClockTimestamp())
+		if err != nil {
+			log.Warningf(ctx, "failed to determine whether the local store contains the meta1 lease: %v", err)
+			return false
+		}
+		return isLeaseholder
+	})
+
 	protectedtsReconciler := ptreconcile.NewReconciler(ptreconcile.Config{
 		Settings: st,
 		Stores:   node.stores,
@@ -601,6 +637,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		cfg.Config,
 		sAdmin,
 		db,
+		distSender,
 		g,
 		recorder,
 		nodeLiveness,
@@ -610,6 +647,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		stopper,
 		sessionRegistry,
 		internalExecutor,
+		contentionHistory,
 	)
 	// TODO(tbg): don't pass all of Server into this to avoid this hack.
 	sAuth := newAuthenticationServer(lateBoundServer)
@@ -638,6 +676,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 			externalStorage:        externalStorage,
 			externalStorageFromURI: externalStorageFromURI,
 			isMeta1Leaseholder:     node.stores.IsMeta1Leaseholder,
+			contentionHistory:      contentionHistory,
 		},
 		SQLConfig:                &cfg.SQLConfig,
 		BaseConfig:               &cfg.BaseConfig,
@@ -664,6 +703,10 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	}
 	sStatus.setStmtDiagnosticsRequester(sqlServer.execCfg.StmtDiagnosticsRecorder)
 	debugServer := debug.NewServer(st, sqlServer.pgServer.HBADebugFn())
+	if cfg.ContinuousProfileDirName != "" {
+		debugServer.RegisterStoredProfiles(
+			continuousprofiler.NewProfileStore(cfg.ContinuousProfileDirName, st))
+	}
 	node.InitLogger(sqlServer.execCfg)
 
 	*lateBoundServer = Server{
@@ -695,8 +738,12 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		stopper:                stopper,
 		debug:                  debugServer,
 		replicationReporter:    replicationReporter,
+		gcoord:                 gcoord,
 		protectedtsProvider:    protectedtsProvider,
 		protectedtsReconciler:  protectedtsReconciler,
+		tenantUsage:            tenantUsage,
+		contentionHistory:      contentionHistory,
+		alertEvaluator:         alertEvaluator,
 		sqlServer:              sqlServer,
 		externalStorageBuilder: externalStorageBuilder,
 	}
@@ -1565,6 +1612,9 @@ func (s *Server) PreStart(ctx context.Context) error {
 		return err
 	}
 	s.replicationReporter.Start(ctx, s.stopper)
+	if err := s.gcoord.Start(ctx, s.stopper, s.node.stores); err != nil {
+		return err
+	}
 
 	sentry.ConfigureScope(func(scope *sentry.Scope) {
 		scope.SetTags(map[string]string{
@@ -1588,12 +1638,14 @@ func (s *Server) PreStart(ctx context.Context) error {
 
 	// Begin recording runtime statistics.
 	if err := startSampleEnvironment(s.AnnotateCtx(ctx), sampleEnvironmentCfg{
-		st:                   s.ClusterSettings(),
-		stopper:              s.stopper,
-		minSampleInterval:    base.DefaultMetricsSampleInterval,
-		goroutineDumpDirName: s.cfg.GoroutineDumpDirName,
-		heapProfileDirName:   s.cfg.HeapProfileDirName,
-		runtime:              s.runtime,
+		st:                       s.ClusterSettings(),
+		stopper:                  s.stopper,
+		minSampleInterval:        base.DefaultMetricsSampleInterval,
+		goroutineDumpDirName:     s.cfg.GoroutineDumpDirName,
+		heapProfileDirName:       s.cfg.HeapProfileDirName,
+		continuousProfileDirName: s.cfg.ContinuousProfileDirName,
+		cpuProfileListener:       s.sqlServer.pgServer.SQLServer,
+		runtime:                  s.runtime,
 	}); err != nil {
 		return err
 	}
@@ -1612,6 +1664,15 @@ func (s *Server) PreStart(ctx context.Context) error {
 		}
 	})
 
+	var remoteWriteOnce sync.Once
+	remoteWriteEndpoint.SetOnChange(&s.st.SV, func() {
+		if remoteWriteEndpoint.Get(&s.st.SV) != "" {
+			remoteWriteOnce.Do(func() {
+				s.node.startPrometheusRemoteWriteExporter(s.st)
+			})
+		}
+	})
+
 	// After setting modeOperational, we can block until all stores are fully
 	// initialized.
 	s.grpc.setMode(modeOperational)
@@ -1731,6 +1792,14 @@ func (s *Server) PreStart(ctx context.Context) error {
 	// The /_status/vars endpoint is not authenticated either. Useful for monitoring.
 	s.mux.Handle(statusVars, http.HandlerFunc(s.status.handleVars))
 
+	// The event log is streamed as server-sent events outside of gRPC-gateway,
+	// which does not support them.
+	var eventLogStreamHandler http.Handler = http.HandlerFunc(s.status.handleEventLogStream)
+	if s.cfg.RequireWebSession() {
+		eventLogStreamHandler = newAuthenticationMux(s.authentication, eventLogStreamHandler)
+	}
+	s.mux.Handle(eventLogStreamPath, eventLogStreamHandler)
+
 	// Register debugging endpoints.
 	var debugHandler http.Handler = s.debug
 	if s.cfg.RequireWebSession() {
@@ -1793,6 +1862,20 @@ func (s *Server) PreStart(ctx context.Context) error {
 		return err
 	}
 
+	// Start rolling up the resources consumed by tenants into
+	// system.tenant_usage, which requires the SQL layer to be ready.
+	s.tenantUsage.Start(workersCtx, s.stopper)
+
+	// Likewise, start flushing the contention history to
+	// system.transaction_contention_events. The fingerprints of the
+	// transactions involved are resolved across the cluster by the status
+	// server.
+	s.contentionHistory.Start(workersCtx, s.stopper, s.status)
+
+	// Start evaluating the alert rules in system.alert_rules, which likewise
+	// requires the SQL layer to be ready.
+	s.alertEvaluator.Start(workersCtx, s.stopper)
+
 	if err := s.debug.RegisterEngines(s.cfg.Stores.Specs, s.engines); err != nil {
 		return errors.Wrapf(err, "failed to register engines with debug server")
 	}
@@ -2167,12 +2250,14 @@ func (s *Server) Decommission(
 }
 
 type sampleEnvironmentCfg struct {
-	st                   *cluster.Settings
-	stopper              *stop.Stopper
-	minSampleInterval    time.Duration
-	goroutineDumpDirName string
-	heapProfileDirName   string
-	runtime              *status.RuntimeStatSampler
+	st                       *cluster.Settings
+	stopper                  *stop.Stopper
+	minSampleInterval        time.Duration
+	goroutineDumpDirName     string
+	heapProfileDirName       string
+	continuousProfileDirName string
+	cpuProfileListener       continuousprofiler.CPUProfileListener
+	runtime                  *status.RuntimeStatSampler
 }
 
 // startSampleEnvironment starts a periodic loop that samples the environment and,
@@ -2237,6 +2322,24 @@ func startSampleEnvironment(ctx context.Context, cfg sampleEnvironmentCfg) error
 		}
 	}
 
+	// Start a continuous profiler if we have an output directory specified.
+	if cfg.continuousProfileDirName != "" {
+		if err := os.MkdirAll(cfg.continuousProfileDirName, 0755); err != nil {
+			// See the comment about the heap profiler's directory above.
+			log.Warningf(ctx, "cannot create continuous profile dir -- continuous profiles will be disabled: %v", err)
+		} else {
+			profiler, err := continuousprofiler.NewContinuousProfiler(
+				ctx, cfg.continuousProfileDirName, cfg.st, cfg.cpuProfileListener,
+			)
+			if err != nil {
+				return errors.Wrap(err, "creating continuous profiler")
+			}
+			if err := profiler.Start(ctx, cfg.stopper); err != nil {
+				return errors.Wrap(err, "starting continuous profiler worker")
+			}
+		}
+	}
+
 	return cfg.stopper.RunAsyncTask(ctx, "mem-logger", func(ctx context.Context) {
 		var goMemStats atomic.Value // *status.GoMemStats
 		goMemStats.Store(&status.GoMemStats{})
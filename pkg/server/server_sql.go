@@ -145,6 +145,10 @@ type sqlServerOptionalKVArgs struct {
 	// Used by backup/restore.
 	externalStorage        cloud.ExternalStorageFactory
 	externalStorageFromURI cloud.ExternalStorageFromURIFactory
+
+	// The contention history, which remembers the statements executed by
+	// transactions.
+	contentionHistory *contention.History
 }
 
 // sqlServerOptionalTenantArgs are the arguments supplied to newSQLServer which
@@ -517,11 +521,13 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		),
 
 		QueryCache:                 querycache.New(cfg.QueryCacheSize),
+		PlanHintsCache:             sql.NewPlanHintsCache(),
 		ProtectedTimestampProvider: cfg.protectedtsProvider,
 		ExternalIODirConfig:        cfg.ExternalIODirConfig,
 		HydratedTables:             hydratedTablesCache,
 		GCJobNotifier:              gcJobNotifier,
 		ContentionRegistry:         contention.NewRegistry(),
+		ContentionHistory:          cfg.contentionHistory,
 	}
 
 	if sqlSchemaChangerTestingKnobs := cfg.TestingKnobs.SQLSchemaChanger; sqlSchemaChangerTestingKnobs != nil {
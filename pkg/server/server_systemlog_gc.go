@@ -70,18 +70,10 @@ func (s *Server) gcSystemLog(
 	ctx context.Context, table string, timestampLowerBound, timestampUpperBound time.Time,
 ) (time.Time, int64, error) {
 	var totalRowsAffected int64
-	repl, _, err := s.node.stores.GetReplicaForRangeID(roachpb.RangeID(1))
-	if roachpb.IsRangeNotFoundError(err) {
-		return timestampLowerBound, 0, nil
-	}
-	if err != nil {
+	if ok, err := s.ownsFirstRangeLease(ctx); err != nil || !ok {
 		return timestampLowerBound, 0, err
 	}
 
-	if !repl.IsFirstRange() || !repl.OwnsValidLease(ctx, s.clock.NowAsClockTimestamp()) {
-		return timestampLowerBound, 0, nil
-	}
-
 	deleteStmt := fmt.Sprintf(
 		`SELECT count(1), max(timestamp) FROM
 [DELETE FROM system.%s WHERE timestamp >= $1 AND timestamp <= $2 LIMIT 1000 RETURNING timestamp]`,
@@ -139,6 +131,29 @@ func (s *Server) gcSystemLog(
 	}
 }
 
+// gcContentionEvents deletes the expired rows of
+// system.transaction_contention_events if the server is the lease holder for
+// range 1, so that only one node in the cluster performs gc.
+func (s *Server) gcContentionEvents(ctx context.Context) error {
+	if ok, err := s.ownsFirstRangeLease(ctx); err != nil || !ok {
+		return err
+	}
+	return s.contentionHistory.DeleteExpired(ctx)
+}
+
+// ownsFirstRangeLease returns whether the server is the lease holder for
+// range 1.
+func (s *Server) ownsFirstRangeLease(ctx context.Context) (bool, error) {
+	repl, _, err := s.node.stores.GetReplicaForRangeID(roachpb.RangeID(1))
+	if roachpb.IsRangeNotFoundError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return repl.IsFirstRange() && repl.OwnsValidLease(ctx, s.clock.NowAsClockTimestamp()), nil
+}
+
 // systemLogGCConfig has configurations for gc of systemlog.
 type systemLogGCConfig struct {
 	// ttl is the time to live for rows in systemlog table.
@@ -149,8 +164,8 @@ type systemLogGCConfig struct {
 	timestampLowerBound time.Time
 }
 
-// startSystemLogsGC starts a worker which periodically GCs system.rangelog
-// and system.eventlog.
+// startSystemLogsGC starts a worker which periodically GCs system.rangelog,
+// system.eventlog and system.transaction_contention_events.
 // The TTLs for each of these logs is retrieved from cluster settings.
 func (s *Server) startSystemLogsGC(ctx context.Context) {
 	systemLogsToGC := map[string]*systemLogGCConfig{
@@ -201,6 +216,9 @@ func (s *Server) startSystemLogsGC(ctx context.Context) {
 						}
 					}
 				}
+				if err := s.gcContentionEvents(ctx); err != nil {
+					log.Warningf(ctx, "error garbage collecting contention events: %v", err)
+				}
 
 				if storeKnobs, ok := s.cfg.TestingKnobs.Store.(*kvserver.StoreTestingKnobs); ok && storeKnobs.SystemLogsGCGCDone != nil {
 					select {
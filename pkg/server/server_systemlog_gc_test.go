@@ -20,13 +20,20 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLogGC(t *testing.T) {
@@ -239,3 +246,53 @@ func TestLogGCTrigger(t *testing.T) {
 		})
 	}
 }
+
+func TestContentionEventsGC(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	ts := s.(*TestServer)
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	// Record events which started before and within the retention period.
+	blockingTxnID := uuid.MakeV4()
+	for i, d := range []time.Duration{
+		8 * 24 * time.Hour, 9 * 24 * time.Hour, time.Second, 2 * time.Second, 3 * time.Second,
+	} {
+		ts.contentionHistory.OnContentionEvent(uuid.MakeV4(), roachpb.ContentionEvent{
+			Key:      roachpb.Key(fmt.Sprintf("key%d", i)),
+			TxnMeta:  enginepb.TxnMeta{ID: blockingTxnID},
+			Duration: d,
+		})
+	}
+	require.NoError(t, ts.contentionHistory.Flush(ctx))
+	eventCount := func() int {
+		var count int
+		sqlDB.QueryRow(t, `
+SELECT count(*) FROM system.transaction_contention_events WHERE blocking_txn_id = $1`,
+			blockingTxnID,
+		).Scan(&count)
+		return count
+	}
+	require.Equal(t, 5, eventCount())
+
+	// The expired events are deleted once the server holds the lease for
+	// range 1.
+	testutils.SucceedsSoon(t, func() error {
+		require.NoError(t, ts.gcContentionEvents(ctx))
+		if n := eventCount(); n != 3 {
+			return errors.Errorf("expected 3 events, found %d", n)
+		}
+		return nil
+	})
+
+	// So are the oldest events beyond the maximum number of rows.
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.contention.history.max_rows = 2`)
+	require.NoError(t, ts.gcContentionEvents(ctx))
+	var count int
+	sqlDB.QueryRow(t, `SELECT count(*) FROM system.transaction_contention_events`).Scan(&count)
+	require.LessOrEqual(t, count, 2)
+}
@@ -13,6 +13,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
@@ -119,3 +120,55 @@ func (s *statusServer) StatementsLocal() (*serverpb.StatementsResponse, error) {
 
 	return resp, nil
 }
+
+func (s *statusServer) CombinedStatementStats(
+	ctx context.Context, req *serverpb.CombinedStatementsStatsRequest,
+) (*serverpb.StatementsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if _, err := s.privilegeChecker.requireViewActivityPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	start := timeutil.Unix(req.Start, 0)
+	var end time.Time
+	if req.End != 0 {
+		end = timeutil.Unix(req.End, 0)
+	}
+	sqlServer := s.admin.server.sqlServer.pgServer.SQLServer
+	stmtStats, err := sqlServer.GetPersistedStmtStats(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	txnStats, err := sqlServer.GetPersistedTxnStats(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &serverpb.StatementsResponse{
+		Statements:            make([]serverpb.StatementsResponse_CollectedStatementStatistics, len(stmtStats)),
+		LastReset:             start,
+		InternalAppNamePrefix: catconstants.InternalAppNamePrefix,
+		Transactions:          make([]serverpb.StatementsResponse_ExtendedCollectedTransactionStatistics, len(txnStats)),
+	}
+
+	// The statistics are combined across nodes, so no node ID is reported.
+	for i, txn := range txnStats {
+		resp.Transactions[i] = serverpb.StatementsResponse_ExtendedCollectedTransactionStatistics{
+			StatsData: txn,
+		}
+	}
+
+	for i, stmt := range stmtStats {
+		resp.Statements[i] = serverpb.StatementsResponse_CollectedStatementStatistics{
+			Key: serverpb.StatementsResponse_ExtendedStatementStatisticsKey{
+				KeyData: stmt.Key,
+			},
+			ID:    stmt.ID,
+			Stats: stmt.Stats,
+		}
+	}
+
+	return resp, nil
+}
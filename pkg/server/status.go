@@ -40,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
@@ -53,7 +54,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
@@ -302,6 +306,7 @@ type statusServer struct {
 	cfg                      *base.Config
 	admin                    *adminServer
 	db                       *kv.DB
+	distSender               *kvcoord.DistSender
 	gossip                   *gossip.Gossip
 	metricSource             metricMarshaler
 	nodeLiveness             *liveness.NodeLiveness
@@ -312,6 +317,7 @@ type statusServer struct {
 	si                       systemInfoOnce
 	stmtDiagnosticsRequester StmtDiagnosticsRequester
 	internalExecutor         *sql.InternalExecutor
+	contentionHistory        *contention.History
 }
 
 // StmtDiagnosticsRequester is the interface into *stmtdiagnostics.Registry
@@ -332,6 +338,7 @@ func newStatusServer(
 	cfg *base.Config,
 	adminServer *adminServer,
 	db *kv.DB,
+	distSender *kvcoord.DistSender,
 	gossip *gossip.Gossip,
 	metricSource metricMarshaler,
 	nodeLiveness *liveness.NodeLiveness,
@@ -341,6 +348,7 @@ func newStatusServer(
 	stopper *stop.Stopper,
 	sessionRegistry *sql.SessionRegistry,
 	internalExecutor *sql.InternalExecutor,
+	contentionHistory *contention.History,
 ) *statusServer {
 	ambient.AddLogTag("status", nil)
 	server := &statusServer{
@@ -350,17 +358,19 @@ func newStatusServer(
 			sessionRegistry:  sessionRegistry,
 			st:               st,
 		},
-		cfg:              cfg,
-		admin:            adminServer,
-		db:               db,
-		gossip:           gossip,
-		metricSource:     metricSource,
-		nodeLiveness:     nodeLiveness,
-		storePool:        storePool,
-		rpcCtx:           rpcCtx,
-		stores:           stores,
-		stopper:          stopper,
-		internalExecutor: internalExecutor,
+		cfg:               cfg,
+		admin:             adminServer,
+		db:                db,
+		distSender:        distSender,
+		gossip:            gossip,
+		metricSource:      metricSource,
+		nodeLiveness:      nodeLiveness,
+		storePool:         storePool,
+		rpcCtx:            rpcCtx,
+		stores:            stores,
+		stopper:           stopper,
+		internalExecutor:  internalExecutor,
+		contentionHistory: contentionHistory,
 	}
 
 	return server
@@ -890,6 +900,8 @@ func (s *statusServer) GetFiles(
 		dir = s.admin.server.cfg.HeapProfileDirName
 	case serverpb.FileType_GOROUTINES: // Requesting for saved Goroutine dumps.
 		dir = s.admin.server.cfg.GoroutineDumpDirName
+	case serverpb.FileType_CONTINUOUS_PROFILES: // Requesting for continuous profiles.
+		dir = s.admin.server.cfg.ContinuousProfileDirName
 	default:
 		return nil, status.Errorf(codes.InvalidArgument, "unknown file type: %s", req.Type)
 	}
@@ -1746,6 +1758,187 @@ func (s *statusServer) localHotRanges(ctx context.Context) serverpb.HotRangesRes
 	return resp
 }
 
+// defaultHotKeysLimit is the number of keys, and of key prefixes, reported for
+// each store by HotKeys if the request does not specify a limit.
+const defaultHotKeysLimit = 20
+
+// HotKeys returns the hottest keys and SQL indexes on each store on the
+// requested node(s).
+func (s *statusServer) HotKeys(
+	ctx context.Context, req *serverpb.HotKeysRequest,
+) (*serverpb.HotKeysResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &serverpb.HotKeysResponse{
+		NodeID:          s.gossip.NodeID.Get(),
+		HotKeysByNodeID: make(map[roachpb.NodeID]serverpb.HotKeysResponse_NodeResponse),
+	}
+
+	if len(req.NodeID) > 0 {
+		requestedNodeID, local, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
+		// Only hot keys from the local node.
+		if local {
+			response.HotKeysByNodeID[requestedNodeID] = s.localHotKeys(ctx, req.Limit)
+			return response, nil
+		}
+
+		// Only hot keys from one non-local node.
+		status, err := s.dialNode(ctx, requestedNodeID)
+		if err != nil {
+			return nil, err
+		}
+		return status.HotKeys(ctx, req)
+	}
+
+	// Hot keys from all nodes.
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	remoteRequest := serverpb.HotKeysRequest{NodeID: "local", Limit: req.Limit}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.HotKeys(ctx, &remoteRequest)
+	}
+	responseFn := func(nodeID roachpb.NodeID, resp interface{}) {
+		hotKeysResp := resp.(*serverpb.HotKeysResponse)
+		response.HotKeysByNodeID[nodeID] = hotKeysResp.HotKeysByNodeID[nodeID]
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		response.HotKeysByNodeID[nodeID] = serverpb.HotKeysResponse_NodeResponse{
+			ErrorMessage: err.Error(),
+		}
+	}
+
+	if err := s.iterateNodes(ctx, "hot keys", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *statusServer) localHotKeys(
+	ctx context.Context, limit int32,
+) serverpb.HotKeysResponse_NodeResponse {
+	if limit <= 0 {
+		limit = defaultHotKeysLimit
+	}
+	var resp serverpb.HotKeysResponse_NodeResponse
+	includeRawKeys := debug.GatewayRemoteAllowed(ctx, s.st)
+	err := s.stores.VisitStores(func(store *kvserver.Store) error {
+		hotKeys, hotPrefixes := store.HottestKeys(int(limit))
+		storeResp := &serverpb.HotKeysResponse_StoreResponse{
+			StoreID:     store.StoreID(),
+			HotKeys:     make([]serverpb.HotKeysResponse_HotKey, len(hotKeys)),
+			HotPrefixes: make([]serverpb.HotKeysResponse_HotKey, len(hotPrefixes)),
+		}
+		for i, k := range hotKeys {
+			storeResp.HotKeys[i] = makeHotKey(k.Key, k.QPS, includeRawKeys)
+			storeResp.HotKeys[i].RangeID = k.RangeID
+		}
+		for i, p := range hotPrefixes {
+			// Index prefixes only identify SQL indexes, so they are always
+			// included.
+			storeResp.HotPrefixes[i] = makeHotKey(p.Key, p.QPS, true /* includeRawKey */)
+		}
+		resp.Stores = append(resp.Stores, storeResp)
+		return nil
+	})
+	if err == nil {
+		err = s.resolveHotKeyNames(ctx, resp.Stores)
+	}
+	if err != nil {
+		return serverpb.HotKeysResponse_NodeResponse{ErrorMessage: err.Error()}
+	}
+	return resp
+}
+
+// makeHotKey returns the report for the given hot key or key prefix. The
+// SQL index the key belongs to is decoded if it is a key of the system
+// tenant.
+func makeHotKey(key roachpb.Key, qps float64, includeRawKey bool) serverpb.HotKeysResponse_HotKey {
+	hk := serverpb.HotKeysResponse_HotKey{QueriesPerSecond: qps}
+	if includeRawKey {
+		hk.Key = key
+		hk.PrettyKey = key.String()
+	}
+	if _, tenID, err := keys.DecodeTenantPrefix(key); err != nil || tenID != roachpb.SystemTenantID {
+		return hk
+	}
+	if _, tableID, indexID, err := keys.SystemSQLCodec.DecodeIndexPrefix(key); err == nil {
+		hk.TableID, hk.IndexID = tableID, indexID
+	}
+	return hk
+}
+
+// resolveHotKeyNames fills in the names of the SQL indexes the hot keys and
+// key prefixes of the given reports belong to.
+func (s *statusServer) resolveHotKeyNames(
+	ctx context.Context, stores []*serverpb.HotKeysResponse_StoreResponse,
+) error {
+	var hotKeys []*serverpb.HotKeysResponse_HotKey
+	tableIDs := make(map[uint32]struct{})
+	for _, storeResp := range stores {
+		for _, list := range [][]serverpb.HotKeysResponse_HotKey{storeResp.HotKeys, storeResp.HotPrefixes} {
+			for i := range list {
+				if list[i].TableID != 0 {
+					hotKeys = append(hotKeys, &list[i])
+					tableIDs[list[i].TableID] = struct{}{}
+				}
+			}
+		}
+	}
+	if len(tableIDs) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(tableIDs))
+	for id := range tableIDs {
+		ids = append(ids, strconv.FormatUint(uint64(id), 10))
+	}
+	rows, err := s.internalExecutor.QueryEx(
+		ctx, "hot-keys-names", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		fmt.Sprintf(`
+SELECT t.table_id, i.index_id, t.database_name, t.name, i.index_name
+  FROM crdb_internal.tables AS t
+  JOIN crdb_internal.table_indexes AS i ON i.descriptor_id = t.table_id
+ WHERE t.table_id IN (%s)`, strings.Join(ids, ", ")),
+	)
+	if err != nil {
+		return err
+	}
+	type indexKey struct{ tableID, indexID uint32 }
+	type indexNames struct{ database, table, index string }
+	names := make(map[indexKey]indexNames, len(rows))
+	for _, row := range rows {
+		var n indexNames
+		if db, ok := tree.AsDString(row[2]); ok {
+			n.database = string(db)
+		}
+		n.table = string(tree.MustBeDString(row[3]))
+		n.index = string(tree.MustBeDString(row[4]))
+		names[indexKey{
+			tableID: uint32(tree.MustBeDInt(row[0])),
+			indexID: uint32(tree.MustBeDInt(row[1])),
+		}] = n
+	}
+	for _, hk := range hotKeys {
+		if n, ok := names[indexKey{tableID: hk.TableID, indexID: hk.IndexID}]; ok {
+			hk.DatabaseName, hk.TableName, hk.IndexName = n.database, n.table, n.index
+		}
+	}
+	return nil
+}
+
 // Range returns rangeInfos for all nodes in the cluster about a specific
 // range. It also returns the range history for that range as well.
 func (s *statusServer) Range(
@@ -58,6 +58,10 @@ const (
 	advertiseAddrLabelKey = "advertise-addr"
 	httpAddrLabelKey      = "http-addr"
 	sqlAddrLabelKey       = "sql-addr"
+
+	// nodeIDLabelKey is the label identifying the node of the time series
+	// pushed to a Prometheus remote write endpoint.
+	nodeIDLabelKey = "node_id"
 )
 
 type quantile struct {
@@ -89,6 +93,13 @@ var childMetricsEnabled = settings.RegisterBoolSetting("server.child_metrics.ena
 	"enables the exporting of child metrics, additional prometheus time series with extra labels",
 	false)
 
+var histogramBucketsEnabled = settings.RegisterBoolSetting(
+	"timeseries.storage.histogram_buckets.enabled",
+	"if set, the bucket counts of histograms are recorded in the internal time series database, "+
+		"allowing arbitrary percentiles of them to be queried over any time window and set of nodes",
+	false,
+).WithPublic()
+
 // MetricsRecorder is used to periodically record the information in a number of
 // metric registries.
 //
@@ -293,6 +304,24 @@ func (mr *MetricsRecorder) ExportToGraphite(
 	return graphiteExporter.Push(ctx, endpoint)
 }
 
+// ExportToPrometheusRemoteWrite sends the current metric values to an
+// endpoint implementing the Prometheus remote write protocol. The time series
+// are labeled with the ID of this node, which Prometheus would otherwise
+// derive from the address it scrapes. As with ExportToGraphite, it uses the
+// passed-in PrometheusExporter to avoid races with
+// mr.promMu.prometheusExporter.
+func (mr *MetricsRecorder) ExportToPrometheusRemoteWrite(
+	ctx context.Context, endpoint string, pm *metric.PrometheusExporter,
+) error {
+	mr.scrapeIntoPrometheus(pm)
+	mr.mu.RLock()
+	nodeID := mr.mu.desc.NodeID
+	mr.mu.RUnlock()
+	labels := []metric.RemoteWriteLabel{{Name: nodeIDLabelKey, Value: nodeID.String()}}
+	remoteWriteExporter := metric.MakeRemoteWriteExporter(pm)
+	return remoteWriteExporter.Push(ctx, endpoint, labels, mr.clock.PhysicalTime())
+}
+
 // GetTimeSeriesData serializes registered metrics for consumption by
 // CockroachDB's time series system.
 func (mr *MetricsRecorder) GetTimeSeriesData() []tspb.TimeSeriesData {
@@ -312,21 +341,24 @@ func (mr *MetricsRecorder) GetTimeSeriesData() []tspb.TimeSeriesData {
 
 	// Record time series from node-level registries.
 	now := mr.clock.PhysicalNow()
+	recordHistogramBuckets := histogramBucketsEnabled.Get(&mr.settings.SV)
 	recorder := registryRecorder{
-		registry:       mr.mu.nodeRegistry,
-		format:         nodeTimeSeriesPrefix,
-		source:         strconv.FormatInt(int64(mr.mu.desc.NodeID), 10),
-		timestampNanos: now,
+		registry:               mr.mu.nodeRegistry,
+		format:                 nodeTimeSeriesPrefix,
+		source:                 strconv.FormatInt(int64(mr.mu.desc.NodeID), 10),
+		timestampNanos:         now,
+		recordHistogramBuckets: recordHistogramBuckets,
 	}
 	recorder.record(&data)
 
 	// Record time series from store-level registries.
 	for storeID, r := range mr.mu.storeRegistries {
 		storeRecorder := registryRecorder{
-			registry:       r,
-			format:         storeTimeSeriesPrefix,
-			source:         strconv.FormatInt(int64(storeID), 10),
-			timestampNanos: now,
+			registry:               r,
+			format:                 storeTimeSeriesPrefix,
+			source:                 strconv.FormatInt(int64(storeID), 10),
+			timestampNanos:         now,
+			recordHistogramBuckets: recordHistogramBuckets,
 		}
 		storeRecorder.record(&data)
 	}
@@ -541,6 +573,9 @@ type registryRecorder struct {
 	format         string
 	source         string
 	timestampNanos int64
+	// recordHistogramBuckets is set if the bucket counts of histograms are
+	// recorded in addition to their quantiles.
+	recordHistogramBuckets bool
 }
 
 func extractValue(mtr interface{}) (float64, error) {
@@ -571,7 +606,7 @@ func extractValue(mtr interface{}) (float64, error) {
 // recordable values.
 func eachRecordableValue(reg *metric.Registry, fn func(string, float64)) {
 	reg.Each(func(name string, mtr interface{}) {
-		if histogram, ok := mtr.(*metric.Histogram); ok {
+		if histogram, ok := mtr.(metric.WindowedHistogram); ok {
 			// TODO(mrtracy): Where should this comment go for better
 			// visibility?
 			//
@@ -601,6 +636,37 @@ func eachRecordableValue(reg *metric.Registry, fn func(string, float64)) {
 	})
 }
 
+// eachHistogramBucket visits each histogram in the registry, calling the
+// supplied function once for each non-empty bucket, as laid out by the time
+// series database, with the number of values recorded in it since the
+// histogram was created.
+func eachHistogramBucket(reg *metric.Registry, fn func(string, int, float64)) {
+	reg.Each(func(name string, mtr interface{}) {
+		histogram, ok := mtr.(metric.CumulativeHistogram)
+		if !ok {
+			return
+		}
+		// The bars of the distribution are sorted, so the values of each bucket
+		// are visited consecutively.
+		bucket, count := -1, int64(0)
+		for _, bar := range histogram.Snapshot().Distribution() {
+			if bar.Count == 0 {
+				continue
+			}
+			if b := tspb.HistogramBucket(bar.To); b != bucket {
+				if count > 0 {
+					fn(name, bucket, float64(count))
+				}
+				bucket, count = b, 0
+			}
+			count += bar.Count
+		}
+		if count > 0 {
+			fn(name, bucket, float64(count))
+		}
+	})
+}
+
 func (rr registryRecorder) record(dest *[]tspb.TimeSeriesData) {
 	eachRecordableValue(rr.registry, func(name string, val float64) {
 		*dest = append(*dest, tspb.TimeSeriesData{
@@ -614,6 +680,21 @@ func (rr registryRecorder) record(dest *[]tspb.TimeSeriesData) {
 			},
 		})
 	})
+	if !rr.recordHistogramBuckets {
+		return
+	}
+	eachHistogramBucket(rr.registry, func(name string, bucket int, count float64) {
+		*dest = append(*dest, tspb.TimeSeriesData{
+			Name:   tspb.HistogramBucketsName(fmt.Sprintf(rr.format, name)),
+			Source: tspb.MakeHistogramBucketSource(rr.source, bucket),
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{
+					TimestampNanos: rr.timestampNanos,
+					Value:          count,
+				},
+			},
+		})
+	})
 }
 
 // GetTotalMemory returns either the total system memory (in bytes) or if
@@ -205,6 +205,7 @@ func TestMetricsRecorder(t *testing.T) {
 		{"testLatency", "latency", 10},
 		{"testAggGauge", "agggauge", 4},
 		{"testAggCounter", "aggcounter", 7},
+		{"testAggHistogram", "agghistogram", 10},
 
 		// Stats needed for store summaries.
 		{"ranges", "counter", 1},
@@ -252,6 +253,28 @@ func TestMetricsRecorder(t *testing.T) {
 		}
 	}
 
+	// addExpectedBucket generates the expected bucket count of a histogram
+	// holding a single value, recorded if histogramBucketsEnabled is set.
+	var expectedBuckets []tspb.TimeSeriesData
+	addExpectedBucket := func(prefix, name string, source, time, val int64, isNode bool) {
+		tsPrefix := "cr.node."
+		if !isNode {
+			tsPrefix = "cr.store."
+		}
+		expectedBuckets = append(expectedBuckets, tspb.TimeSeriesData{
+			Name: tspb.HistogramBucketsName(tsPrefix + prefix + name),
+			Source: tspb.MakeHistogramBucketSource(
+				strconv.FormatInt(source, 10), tspb.HistogramBucket(val),
+			),
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{
+					TimestampNanos: time,
+					Value:          1,
+				},
+			},
+		})
+	}
+
 	// Add metric for node ID.
 	g := metric.NewGauge(metric.Metadata{Name: "node-id"})
 	g.Update(int64(nodeDesc.NodeID))
@@ -287,6 +310,15 @@ func TestMetricsRecorder(t *testing.T) {
 				c := ac.AddChild("bar")
 				c.Inc((data.val))
 				addExpected(reg.prefix, data.name, reg.source, 100, data.val, reg.isNode)
+			case "agghistogram":
+				ah := aggmetric.NewHistogram(metric.Metadata{Name: reg.prefix + data.name}, time.Second, 1000, 2, "foo")
+				reg.reg.AddMetric(ah)
+				h := ah.AddChild("bar")
+				h.RecordValue(data.val)
+				for _, q := range recordHistogramQuantiles {
+					addExpected(reg.prefix, data.name+q.suffix, reg.source, 100, data.val, reg.isNode)
+				}
+				addExpectedBucket(reg.prefix, data.name, reg.source, 100, data.val, reg.isNode)
 			case "histogram":
 				h := metric.NewHistogram(metric.Metadata{Name: reg.prefix + data.name}, time.Second, 1000, 2)
 				reg.reg.AddMetric(h)
@@ -294,6 +326,7 @@ func TestMetricsRecorder(t *testing.T) {
 				for _, q := range recordHistogramQuantiles {
 					addExpected(reg.prefix, data.name+q.suffix, reg.source, 100, data.val, reg.isNode)
 				}
+				addExpectedBucket(reg.prefix, data.name, reg.source, 100, data.val, reg.isNode)
 			case "latency":
 				l := metric.NewLatency(metric.Metadata{Name: reg.prefix + data.name}, time.Hour)
 				reg.reg.AddMetric(l)
@@ -303,6 +336,7 @@ func TestMetricsRecorder(t *testing.T) {
 				for _, q := range recordHistogramQuantiles {
 					addExpected(reg.prefix, data.name+q.suffix, reg.source, 100, data.val, reg.isNode)
 				}
+				addExpectedBucket(reg.prefix, data.name, reg.source, 100, data.val, reg.isNode)
 			default:
 				t.Fatalf("unexpected: %+v", data)
 			}
@@ -322,6 +356,16 @@ func TestMetricsRecorder(t *testing.T) {
 		t.Errorf("recorder did not yield expected time series collection; diff:\n %v", pretty.Diff(e, a))
 	}
 
+	// The bucket counts of histograms are additionally recorded if enabled.
+	histogramBucketsEnabled.Override(&st.SV, true)
+	actual = recorder.GetTimeSeriesData()
+	expected = append(expected, expectedBuckets...)
+	sort.Sort(byTimeAndName(actual))
+	sort.Sort(byTimeAndName(expected))
+	if a, e := actual, expected; !reflect.DeepEqual(a, e) {
+		t.Errorf("recorder did not yield expected histogram buckets; diff:\n %v", pretty.Diff(e, a))
+	}
+
 	totalMemory, err := GetTotalMemory(context.Background())
 	if err != nil {
 		t.Error("couldn't get total memory", err)
@@ -11,6 +11,7 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	gosql "database/sql"
@@ -36,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
@@ -48,6 +50,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
@@ -58,12 +61,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
+	"github.com/gogo/protobuf/types"
 	"github.com/kr/pretty"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1050,6 +1055,355 @@ func TestHotRangesResponse(t *testing.T) {
 	}
 }
 
+func TestHotKeysResponse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ts, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer ts.Stopper().Stop(context.Background())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.hot_keys.sample_rate = 1`)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT, INDEX (v))`)
+	for i := 0; i < 100; i++ {
+		sqlDB.Exec(t, `UPSERT INTO t VALUES (1, $1)`, i)
+	}
+
+	var hotKeysResp serverpb.HotKeysResponse
+	if err := getStatusJSONProto(ts, "hotkeys?limit=5", &hotKeysResp); err != nil {
+		t.Fatal(err)
+	}
+	nodeResp, ok := hotKeysResp.HotKeysByNodeID[ts.NodeID()]
+	require.True(t, ok, "didn't get a hot key response from n%d", ts.NodeID())
+	require.Empty(t, nodeResp.ErrorMessage)
+	require.Len(t, nodeResp.Stores, 1)
+	storeResp := nodeResp.Stores[0]
+	require.LessOrEqual(t, len(storeResp.HotKeys), 5)
+	require.LessOrEqual(t, len(storeResp.HotPrefixes), 5)
+
+	// The row which was updated repeatedly is among the hot keys, and its
+	// primary index among the hot prefixes, along with their names.
+	isHotRow := func(k serverpb.HotKeysResponse_HotKey) bool {
+		return k.TableName == "t" && k.IndexName == "primary" && k.DatabaseName == "defaultdb"
+	}
+	var foundKey, foundPrefix bool
+	lastQPS := math.MaxFloat64
+	for _, k := range storeResp.HotKeys {
+		require.LessOrEqual(t, k.QueriesPerSecond, lastQPS)
+		lastQPS = k.QueriesPerSecond
+		if isHotRow(k) {
+			foundKey = true
+			require.NotZero(t, k.RangeID)
+			require.Regexp(t, `^/Table/\d+/1/1$`, k.PrettyKey)
+		}
+	}
+	for _, k := range storeResp.HotPrefixes {
+		if isHotRow(k) {
+			foundPrefix = true
+			require.Regexp(t, `^/Table/\d+/1$`, k.PrettyKey)
+		}
+	}
+	require.True(t, foundKey, "hot row not found in %+v", storeResp.HotKeys)
+	require.True(t, foundPrefix, "hot index not found in %+v", storeResp.HotPrefixes)
+
+	// They are also reported by SHOW HOT KEYS.
+	var count int
+	sqlDB.QueryRow(t, `
+SELECT count(*) FROM [SHOW HOT KEYS]
+ WHERE table_name = 't' AND index_name = 'primary' AND qps > 0`,
+	).Scan(&count)
+	require.Equal(t, 2, count)
+}
+
+func TestContentionEventsResponse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	ts := s.(*TestServer)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES (1, 1)`)
+
+	// Make an UPDATE wait on the lock held by an explicit transaction.
+	blockingTxn, err := db.Begin()
+	require.NoError(t, err)
+	_, err = blockingTxn.Exec(`UPDATE t SET v = 2 WHERE k = 1`)
+	require.NoError(t, err)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := db.Exec(`DELETE FROM t WHERE k = 1`)
+		errCh <- err
+	}()
+	testutils.SucceedsSoon(t, func() error {
+		var waiting bool
+		sqlDB.QueryRow(t, `
+SELECT count(*) > 0 FROM crdb_internal.node_queries WHERE query LIKE 'DELETE FROM t%'`,
+		).Scan(&waiting)
+		if !waiting {
+			return errors.New("DELETE not started yet")
+		}
+		return nil
+	})
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, blockingTxn.Commit())
+	require.NoError(t, <-errCh)
+
+	require.NoError(t, ts.contentionHistory.Flush(ctx))
+
+	var resp serverpb.ContentionEventsResponse
+	if err := getStatusJSONProto(ts, "contention_events", &resp); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, e := range resp.Events {
+		if len(e.BlockingTxnFingerprints) == 0 ||
+			e.BlockingTxnFingerprints[0] != "UPDATE t SET v = _ WHERE k = _" {
+			continue
+		}
+		found = true
+		require.Equal(t, ts.NodeID(), e.NodeID)
+		require.Regexp(t, `^/Table/\d+/1/1/0$`, e.PrettyKey)
+		require.NotZero(t, e.Duration)
+		require.Equal(t, []string{"DELETE FROM t WHERE k = _"}, e.WaitingTxnFingerprints)
+		require.NotEqual(t, e.WaitingTxnID, e.BlockingTxnID)
+	}
+	require.True(t, found, "contention event not found in %+v", resp.Events)
+
+	// The event is also exposed by crdb_internal.
+	var count int
+	sqlDB.QueryRow(t, `
+SELECT count(*) FROM crdb_internal.transaction_contention_events
+ WHERE blocking_txn_fingerprints = ARRAY['UPDATE t SET v = _ WHERE k = _']
+   AND waiting_txn_fingerprints = ARRAY['DELETE FROM t WHERE k = _']
+   AND duration > '0s'`,
+	).Scan(&count)
+	require.Equal(t, 1, count)
+}
+
+func TestStreamEventLog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	ts := s.(*TestServer)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	// Speed up the delivery of the events, which waits for the rangefeed over
+	// system.eventlog to resolve their timestamps.
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+
+	rpcContext := newRPCTestContext(ts, ts.RPCContext().Config)
+	conn, err := rpcContext.GRPCDialNode(ts.ServingRPCAddr(), ts.NodeID(), rpc.DefaultClass).Connect(ctx)
+	require.NoError(t, err)
+	client := serverpb.NewStatusClient(conn)
+
+	// receiveCreateDatabase returns the first create_database event of the
+	// given database received from the stream.
+	receiveCreateDatabase := func(
+		stream serverpb.Status_StreamEventLogClient, dbName string,
+	) (*serverpb.EventLogEvent, *eventpb.CreateDatabase) {
+		for {
+			ev, err := stream.Recv()
+			require.NoError(t, err)
+			require.Equal(t, "create_database", ev.EventType)
+			var payload eventpb.CreateDatabase
+			require.NoError(t, types.UnmarshalAny(ev.Payload, &payload))
+			if payload.DatabaseName == dbName {
+				return ev, &payload
+			}
+		}
+	}
+	req := &serverpb.EventLogStreamRequest{EventTypes: []string{"create_database"}}
+
+	sqlDB.Exec(t, `CREATE DATABASE export1`)
+	streamCtx, cancel := context.WithCancel(ctx)
+	stream, err := client.StreamEventLog(streamCtx, req)
+	require.NoError(t, err)
+	ev, payload := receiveCreateDatabase(stream, "export1")
+	require.Equal(t, "<hidden>", payload.Statement)
+	cancel()
+
+	// Checkpoint the consumer after the event, and resume from the
+	// checkpoint.
+	checkpoint, err := client.EventLogCheckpoint(ctx,
+		&serverpb.EventLogCheckpointRequest{Consumer: "test", Cursor: ev.Cursor})
+	require.NoError(t, err)
+	require.Equal(t, ev.Cursor, checkpoint.Cursor)
+	checkpoint, err = client.EventLogCheckpoint(ctx, &serverpb.EventLogCheckpointRequest{Consumer: "test"})
+	require.NoError(t, err)
+	require.Equal(t, ev.Cursor, checkpoint.Cursor)
+	// The checkpoints are recorded separately for each user.
+	var httpCheckpoint serverpb.EventLogCheckpointResponse
+	require.NoError(t, postStatusJSONProto(ts, "eventlog/checkpoint",
+		&serverpb.EventLogCheckpointRequest{Consumer: "test"}, &httpCheckpoint))
+	require.Empty(t, httpCheckpoint.Cursor)
+
+	sqlDB.Exec(t, `CREATE DATABASE export2`)
+	streamCtx, cancel = context.WithCancel(ctx)
+	defer cancel()
+	req.Consumer = "test"
+	req.UnredactedEvents = true
+	stream, err = client.StreamEventLog(streamCtx, req)
+	require.NoError(t, err)
+	ev, payload = receiveCreateDatabase(stream, "export2")
+	require.Equal(t, `CREATE DATABASE export2`, payload.Statement)
+
+	// The timestamp of an event is the read timestamp of its transaction, so
+	// an event can be committed after events with later timestamps. None of
+	// them are delivered while it can still be committed before them, and
+	// resuming from the cursor of the last event delivered delivers none of
+	// them again.
+	sqlDB.Exec(t, `CREATE DATABASE comment1; CREATE DATABASE comment2`)
+	commentReq := &serverpb.EventLogStreamRequest{
+		Cursor: ev.Cursor, EventTypes: []string{"comment_on_database"},
+	}
+	commentStream, err := client.StreamEventLog(streamCtx, commentReq)
+	require.NoError(t, err)
+	comment := func(ev *serverpb.EventLogEvent) string {
+		var payload eventpb.CommentOnDatabase
+		require.NoError(t, types.UnmarshalAny(ev.Payload, &payload))
+		return payload.Comment
+	}
+	commentCh := make(chan *serverpb.EventLogEvent)
+	go func() {
+		for {
+			ev, err := commentStream.Recv()
+			if err != nil {
+				return
+			}
+			select {
+			case commentCh <- ev:
+			case <-streamCtx.Done():
+				return
+			}
+		}
+	}()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(`COMMENT ON DATABASE comment1 IS 'first'`)
+	require.NoError(t, err)
+	sqlDB.Exec(t, `COMMENT ON DATABASE comment2 IS 'second'`)
+	select {
+	case ev := <-commentCh:
+		t.Fatalf("event delivered before the events preceding it were committed: %s", ev.Info)
+	case <-time.After(time.Second):
+	}
+	require.NoError(t, tx.Commit())
+	first, second := <-commentCh, <-commentCh
+	require.ElementsMatch(t, []string{"first", "second"},
+		[]string{comment(first), comment(second)})
+	sqlDB.Exec(t, `COMMENT ON DATABASE comment1 IS 'third'`)
+	commentReq.Cursor = second.Cursor
+	commentStream, err = client.StreamEventLog(streamCtx, commentReq)
+	require.NoError(t, err)
+	third, err := commentStream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "third", comment(third))
+
+	// The events are also served as server-sent events.
+	httpClient, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	httpReq, err := http.NewRequestWithContext(streamCtx, "GET",
+		ts.AdminURL()+eventLogStreamPath+"?event_types=create_database", nil)
+	require.NoError(t, err)
+	httpReq.Header.Set("Last-Event-ID", ev.Cursor)
+	sqlDB.Exec(t, `CREATE DATABASE export3`)
+	httpResp, err := httpClient.Do(httpReq)
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+	require.Equal(t, http.StatusOK, httpResp.StatusCode)
+	require.Equal(t, "text/event-stream", httpResp.Header.Get("Content-Type"))
+	scanner := bufio.NewScanner(httpResp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data: ") && strings.Contains(line, `"databaseName":"export3"`) {
+			return
+		}
+	}
+	t.Fatalf("event not found in the stream: %v", scanner.Err())
+}
+
+func TestStreamEventLogResumption(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	ts := s.(*TestServer)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+
+	rpcContext := newRPCTestContext(ts, ts.RPCContext().Config)
+	conn, err := rpcContext.GRPCDialNode(ts.ServingRPCAddr(), ts.NodeID(), rpc.DefaultClass).Connect(ctx)
+	require.NoError(t, err)
+	client := serverpb.NewStatusClient(conn)
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	streamFrom := func(cursor string) serverpb.Status_StreamEventLogClient {
+		stream, err := client.StreamEventLog(streamCtx, &serverpb.EventLogStreamRequest{
+			Cursor: cursor, EventTypes: []string{"create_database"},
+		})
+		require.NoError(t, err)
+		return stream
+	}
+	// receiveDatabases returns the names of the databases whose creation is
+	// received from the stream, up to the given one, and the cursor of the
+	// latter.
+	receiveDatabases := func(
+		stream serverpb.Status_StreamEventLogClient, last string,
+	) (names []string, cursor string) {
+		for {
+			ev, err := stream.Recv()
+			require.NoError(t, err)
+			var payload eventpb.CreateDatabase
+			require.NoError(t, types.UnmarshalAny(ev.Payload, &payload))
+			names = append(names, payload.DatabaseName)
+			if payload.DatabaseName == last {
+				return names, ev.Cursor
+			}
+		}
+	}
+
+	sqlDB.Exec(t, `CREATE DATABASE scan`)
+	_, scanCursor := receiveDatabases(streamFrom(""), "scan")
+	require.True(t, strings.HasPrefix(scanCursor, "s/"), scanCursor)
+
+	// The events are delivered in order even if more of them are pending than
+	// the rangefeed retains.
+	defer func(prev int) { maxPendingEventLogEvents = prev }(maxPendingEventLogEvents)
+	maxPendingEventLogEvents = 4
+	var created []string
+	for i := 0; i < 20; i++ {
+		created = append(created, fmt.Sprintf("feed%d", i))
+		sqlDB.Exec(t, `CREATE DATABASE `+created[i])
+	}
+	names, feedCursor := receiveDatabases(streamFrom(scanCursor), "feed19")
+	require.Equal(t, created, names)
+	require.True(t, strings.HasPrefix(feedCursor, "c/"), feedCursor)
+
+	// Once the history of system.eventlog since the position of a cursor is
+	// garbage collected, the events which follow it are scanned again.
+	sqlDB.Exec(t, `CREATE DATABASE gc1`)
+	sp := systemschema.EventLogTable.PrimaryIndexSpan(keys.SystemSQLCodec)
+	_, pErr := kv.SendWrapped(ctx, ts.DistSenderI().(kv.Sender), &roachpb.GCRequest{
+		RequestHeader: roachpb.RequestHeaderFromSpan(sp),
+		Threshold:     ts.Clock().Now(),
+	})
+	require.NoError(t, pErr.GoError())
+	names, _ = receiveDatabases(streamFrom(feedCursor), "gc1")
+	require.Equal(t, []string{"gc1"}, names)
+	// The events which follow are then delivered by a rangefeed again.
+	stream := streamFrom(scanCursor)
+	names, _ = receiveDatabases(stream, "gc1")
+	require.Equal(t, append(created, "gc1"), names)
+	sqlDB.Exec(t, `CREATE DATABASE gc2`)
+	names, _ = receiveDatabases(stream, "gc2")
+	require.Equal(t, []string{"gc2"}, names)
+}
+
 func TestRangesResponse(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -1808,6 +2162,102 @@ func TestStatusAPIStatements(t *testing.T) {
 	}
 }
 
+func TestStatusAPICombinedStatements(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCluster := serverutils.StartNewTestCluster(t, 3, base.TestClusterArgs{})
+	ctx := context.Background()
+	defer testCluster.Stopper().Stop(ctx)
+
+	firstServerProto := testCluster.Server(0)
+	thirdServerSQL := sqlutils.MakeSQLRunner(testCluster.ServerConn(2))
+
+	statements := []struct {
+		stmt          string
+		fingerprinted string
+	}{
+		{stmt: `CREATE DATABASE roachblog`},
+		{stmt: `SET database = roachblog`},
+		{stmt: `CREATE TABLE posts (id INT8 PRIMARY KEY, body STRING)`},
+		{
+			stmt:          `INSERT INTO posts VALUES (1, 'foo')`,
+			fingerprinted: `INSERT INTO posts VALUES (_, _)`,
+		},
+		{stmt: `SELECT * FROM posts`},
+	}
+
+	for _, stmt := range statements {
+		thirdServerSQL.Exec(t, stmt.stmt)
+	}
+
+	// Persist the statistics of all the nodes.
+	for i := 0; i < testCluster.NumServers(); i++ {
+		sqlServer := testCluster.Server(i).SQLServer().(*sql.Server)
+		if err := sqlServer.FlushSQLStats(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Test that non-admin without VIEWACTIVITY privileges cannot access.
+	var resp serverpb.StatementsResponse
+	err := getStatusJSONProtoWithAdminOption(firstServerProto, "combinedstmts", &resp, false)
+	if !testutils.IsError(err, "status: 403") {
+		t.Fatalf("expected privilege error, got %v", err)
+	}
+
+	thirdServerSQL.Exec(t, "ALTER USER $1 VIEWACTIVITY", authenticatedUserNameNoAdmin().Normalized())
+
+	// The persisted statistics are served by any node.
+	if err := getStatusJSONProtoWithAdminOption(firstServerProto, "combinedstmts", &resp, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedStatements []string
+	for _, stmt := range statements {
+		var expectedStmt = stmt.stmt
+		if stmt.fingerprinted != "" {
+			expectedStmt = stmt.fingerprinted
+		}
+		expectedStatements = append(expectedStatements, expectedStmt)
+	}
+
+	var statementsInResponse []string
+	for _, respStatement := range resp.Statements {
+		if respStatement.Key.KeyData.Failed {
+			// We ignore failed statements here as the INSERT statement can fail and
+			// be automatically retried, confusing the test success check.
+			continue
+		}
+		if strings.HasPrefix(respStatement.Key.KeyData.App, catconstants.InternalAppNamePrefix) {
+			continue
+		}
+		statementsInResponse = append(statementsInResponse, respStatement.Key.KeyData.Query)
+	}
+
+	sort.Strings(expectedStatements)
+	sort.Strings(statementsInResponse)
+
+	if !reflect.DeepEqual(expectedStatements, statementsInResponse) {
+		t.Fatalf("expected queries\n\n%v\n\ngot queries\n\n%v\n%s",
+			expectedStatements, statementsInResponse, pretty.Sprint(resp))
+	}
+	if len(resp.Transactions) == 0 {
+		t.Fatal("expected persisted transaction statistics")
+	}
+
+	// Requesting a time range before the statements were run returns nothing.
+	var emptyResp serverpb.StatementsResponse
+	if err := getStatusJSONProtoWithAdminOption(
+		firstServerProto, "combinedstmts?start=1&end=2", &emptyResp, false,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(emptyResp.Statements) != 0 {
+		t.Fatalf("expected no statements, got %s", pretty.Sprint(emptyResp.Statements))
+	}
+}
+
 func TestListSessionsSecurity(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -0,0 +1,64 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantusage"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// TenantUsage returns the persisted consumption of each tenant, combined across
+// nodes and aggregation intervals.
+func (s *statusServer) TenantUsage(
+	ctx context.Context, req *serverpb.TenantUsageRequest,
+) (*serverpb.TenantUsageResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if _, err := s.privilegeChecker.requireAdminUser(ctx); err != nil {
+		return nil, err
+	}
+
+	resp := &serverpb.TenantUsageResponse{}
+	if !s.st.Version.IsActive(ctx, clusterversion.TenantUsageTable) {
+		return resp, nil
+	}
+
+	var tenantID roachpb.TenantID
+	if req.TenantID != 0 {
+		tenantID = roachpb.MakeTenantID(req.TenantID)
+	}
+	start := timeutil.Unix(req.Start, 0)
+	var end time.Time
+	if req.End != 0 {
+		end = timeutil.Unix(req.End, 0)
+	}
+	usage, err := tenantusage.Read(ctx, s.internalExecutor, nil /* txn */, tenantID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	// The rows are ordered by tenant, so the intervals of each tenant are
+	// adjacent.
+	for i := range usage {
+		id := usage[i].TenantID.ToUint64()
+		if n := len(resp.Tenants); n == 0 || resp.Tenants[n-1].TenantID != id {
+			resp.Tenants = append(resp.Tenants, serverpb.TenantUsageResponse_TenantUsage{TenantID: id})
+		}
+		resp.Tenants[len(resp.Tenants)-1].Consumption.Add(&usage[i].Consumption)
+	}
+	return resp, nil
+}
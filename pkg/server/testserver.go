@@ -37,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptprovider"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantusage"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/rpc/nodedialer"
@@ -240,14 +241,18 @@ func makeTestConfigFromParams(params base.TestServerArgs) Config {
 			// the dir (and the test is then responsible for cleaning it up, not
 			// TestServer).
 
-			// HeapProfileDirName and GoroutineDumpDirName are normally set by the
-			// cli, once, to the path of the first store.
+			// HeapProfileDirName, GoroutineDumpDirName and
+			// ContinuousProfileDirName are normally set by the cli, once, to the
+			// path of the first store.
 			if cfg.HeapProfileDirName == "" {
 				cfg.HeapProfileDirName = filepath.Join(storeSpec.Path, "logs", base.HeapProfileDir)
 			}
 			if cfg.GoroutineDumpDirName == "" {
 				cfg.GoroutineDumpDirName = filepath.Join(storeSpec.Path, "logs", base.GoroutineDumpDir)
 			}
+			if cfg.ContinuousProfileDirName == "" {
+				cfg.ContinuousProfileDirName = filepath.Join(storeSpec.Path, "logs", base.ContinuousProfileDir)
+			}
 		}
 	}
 	cfg.Stores = base.StoreSpecList{Specs: params.StoreSpecs}
@@ -813,6 +818,16 @@ func StartTenant(
 		return nil, "", "", err
 	}
 
+	// Report the CPU time used by this SQL server to the KV nodes, which meter
+	// it on behalf of the tenant.
+	if err := tenantusage.StartReporter(ctx, args.stopper, args.TenantID, args.tenantConnect,
+		func() float64 {
+			return float64(args.runtime.CPUUserNS.Value()+args.runtime.CPUSysNS.Value()) / 1e9
+		},
+	); err != nil {
+		return nil, "", "", err
+	}
+
 	return s, pgLAddr, httpLAddr, nil
 }
 
@@ -94,6 +94,12 @@ func (f *FloatSetting) setToDefault(sv *Values) {
 	}
 }
 
+// WithPublic sets public visibility and can be chained.
+func (f *FloatSetting) WithPublic() *FloatSetting {
+	f.SetVisibility(Public)
+	return f
+}
+
 // RegisterFloatSetting defines a new setting with type float.
 func RegisterFloatSetting(
 	key, desc string, defaultValue float64, validateFns ...func(float64) error,
@@ -88,6 +88,15 @@ func (p *planner) AlterTable(ctx context.Context, n *tree.AlterTable) (planNode,
 			tree.Name(tableDesc.GetName()), tree.Name(tableDesc.GetName()))
 	}
 
+	if tableDesc.IsForeignTable() {
+		for _, cmd := range n.Cmds {
+			if _, ok := cmd.(*tree.AlterTableInjectStats); !ok {
+				return nil, pgerror.Newf(pgcode.WrongObjectType,
+					"cannot alter foreign table %q", tableDesc.Name)
+			}
+		}
+	}
+
 	n.HoistAddColumnConstraints()
 
 	// See if there's any "inject statistics" in the query and type check the
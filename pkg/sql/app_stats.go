@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
+	"github.com/cockroachdb/cockroach/pkg/sql/execstats"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -60,6 +61,50 @@ type appStats struct {
 	stmts     map[stmtKey]*stmtStats
 	txnCounts transactionCounts
 	txns      map[txnKey]*txnStats
+
+	// cpuProfiling is shared with the sqlStats containing a. See
+	// sqlStats.cpuProfiling.
+	cpuProfiling *syncutil.AtomicBool
+
+	// unflushedStmts and unflushedTxns hold the statistics which have not
+	// been persisted yet of the statements and transactions which are no
+	// longer in stmts and txns, because they were reset, or which failed to
+	// be persisted.
+	unflushedStmts []unflushedStmtStats
+	unflushedTxns  []unflushedTxnStats
+}
+
+// unflushedInterval returns the start and the width of the aggregation
+// interval into which the statistics of an execution finishing now are
+// persisted, or false if they aren't persisted.
+func (a *appStats) unflushedInterval() (time.Time, time.Duration, bool) {
+	if !sqlStatsFlushEnabled.Get(&a.st.SV) {
+		return time.Time{}, 0, false
+	}
+	aggInterval := sqlStatsAggregationInterval.Get(&a.st.SV)
+	return timeutil.Now().Truncate(aggInterval), aggInterval, true
+}
+
+// unflushedStmtStats are the statistics of the executions of a statement in
+// an aggregation interval which have not been persisted yet.
+type unflushedStmtStats struct {
+	key          stmtKey
+	id           roachpb.StmtID
+	distSQLUsed  bool
+	vectorized   bool
+	aggregatedTs time.Time
+	aggInterval  time.Duration
+	data         roachpb.StatementStatistics
+}
+
+// unflushedTxnStats are the statistics of the executions of a transaction in
+// an aggregation interval which have not been persisted yet.
+type unflushedTxnStats struct {
+	key          txnKey
+	statementIDs []roachpb.StmtID
+	aggregatedTs time.Time
+	aggInterval  time.Duration
+	data         roachpb.TransactionStatistics
 }
 
 type txnStats struct {
@@ -69,7 +114,26 @@ type txnStats struct {
 		syncutil.Mutex
 
 		data roachpb.TransactionStatistics
+
+		// unflushed holds the statistics of the executions recorded since they
+		// were last persisted, by aggregation interval, in the order of the
+		// intervals. Only the data, aggregatedTs and aggInterval fields of its
+		// elements are set.
+		unflushed []unflushedTxnStats
+	}
+}
+
+// unflushedLocked returns the statistics not persisted yet of the
+// aggregation interval starting at aggregatedTs. s.mu must be held.
+func (s *txnStats) unflushedLocked(
+	aggregatedTs time.Time, aggInterval time.Duration,
+) *roachpb.TransactionStatistics {
+	if n := len(s.mu.unflushed); n == 0 || !s.mu.unflushed[n-1].aggregatedTs.Equal(aggregatedTs) {
+		s.mu.unflushed = append(s.mu.unflushed, unflushedTxnStats{
+			aggregatedTs: aggregatedTs, aggInterval: aggInterval,
+		})
 	}
+	return &s.mu.unflushed[len(s.mu.unflushed)-1].data
 }
 
 // stmtStats holds per-statement statistics.
@@ -90,10 +154,34 @@ type stmtStats struct {
 		// vectorization.
 		vectorized bool
 
+		// cpuProfiledCount is the number of executions of this statement
+		// recorded since the start of the current CPU profile collected by the
+		// continuous profiler.
+		cpuProfiledCount int64
+
 		data roachpb.StatementStatistics
+
+		// unflushed holds the statistics of the executions recorded since they
+		// were last persisted, by aggregation interval, in the order of the
+		// intervals. Only the data, aggregatedTs and aggInterval fields of its
+		// elements are set.
+		unflushed []unflushedStmtStats
 	}
 }
 
+// unflushedLocked returns the statistics not persisted yet of the
+// aggregation interval starting at aggregatedTs. s.mu must be held.
+func (s *stmtStats) unflushedLocked(
+	aggregatedTs time.Time, aggInterval time.Duration,
+) *roachpb.StatementStatistics {
+	if n := len(s.mu.unflushed); n == 0 || !s.mu.unflushed[n-1].aggregatedTs.Equal(aggregatedTs) {
+		s.mu.unflushed = append(s.mu.unflushed, unflushedStmtStats{
+			aggregatedTs: aggregatedTs, aggInterval: aggInterval,
+		})
+	}
+	return &s.mu.unflushed[len(s.mu.unflushed)-1].data
+}
+
 type transactionCounts struct {
 	mu struct {
 		syncutil.Mutex
@@ -201,29 +289,43 @@ func (a *appStats) recordStatement(
 	}
 
 	// Collect the per-statement statistics.
+	record := func(data *roachpb.StatementStatistics) {
+		data.Count++
+		if err != nil {
+			data.SensitiveInfo.LastErr = err.Error()
+		}
+		// Only update MostRecentPlanDescription if we sampled a new PlanDescription.
+		if samplePlanDescription != nil {
+			data.SensitiveInfo.MostRecentPlanDescription = *samplePlanDescription
+			data.SensitiveInfo.MostRecentPlanTimestamp = timeutil.Now()
+		}
+		if automaticRetryCount == 0 {
+			data.FirstAttemptCount++
+		} else if int64(automaticRetryCount) > data.MaxRetries {
+			data.MaxRetries = int64(automaticRetryCount)
+		}
+		data.NumRows.Record(data.Count, float64(numRows))
+		data.ParseLat.Record(data.Count, parseLat)
+		data.PlanLat.Record(data.Count, planLat)
+		data.RunLat.Record(data.Count, runLat)
+		data.ServiceLat.Record(data.Count, svcLat)
+		data.OverheadLat.Record(data.Count, ovhLat)
+		data.BytesRead.Record(data.Count, float64(stats.bytesRead))
+		data.RowsRead.Record(data.Count, float64(stats.rowsRead))
+		data.PeakMemUsage.Record(data.Count, float64(stats.peakMemUsage))
+	}
+	aggregatedTs, aggInterval, flushed := a.unflushedInterval()
 	s.mu.Lock()
-	s.mu.data.Count++
-	if err != nil {
-		s.mu.data.SensitiveInfo.LastErr = err.Error()
-	}
-	// Only update MostRecentPlanDescription if we sampled a new PlanDescription.
-	if samplePlanDescription != nil {
-		s.mu.data.SensitiveInfo.MostRecentPlanDescription = *samplePlanDescription
-		s.mu.data.SensitiveInfo.MostRecentPlanTimestamp = timeutil.Now()
-	}
-	if automaticRetryCount == 0 {
-		s.mu.data.FirstAttemptCount++
-	} else if int64(automaticRetryCount) > s.mu.data.MaxRetries {
-		s.mu.data.MaxRetries = int64(automaticRetryCount)
-	}
-	s.mu.data.NumRows.Record(s.mu.data.Count, float64(numRows))
-	s.mu.data.ParseLat.Record(s.mu.data.Count, parseLat)
-	s.mu.data.PlanLat.Record(s.mu.data.Count, planLat)
-	s.mu.data.RunLat.Record(s.mu.data.Count, runLat)
-	s.mu.data.ServiceLat.Record(s.mu.data.Count, svcLat)
-	s.mu.data.OverheadLat.Record(s.mu.data.Count, ovhLat)
-	s.mu.data.BytesRead.Record(s.mu.data.Count, float64(stats.bytesRead))
-	s.mu.data.RowsRead.Record(s.mu.data.Count, float64(stats.rowsRead))
+	record(&s.mu.data)
+	if flushed {
+		record(s.unflushedLocked(aggregatedTs, aggInterval))
+	}
+	// Statements executed while the continuous profiler collects a CPU profile
+	// get their share of the CPU time attributed to their fingerprint once
+	// the profile is complete. See attributeCPUProfile.
+	if a.cpuProfiling != nil && a.cpuProfiling.Get() {
+		s.mu.cpuProfiledCount++
+	}
 	// Note that some fields derived from tracing statements (such as
 	// BytesSentOverNetwork) are not updated here because they are collected
 	// on-demand.
@@ -232,10 +334,40 @@ func (a *appStats) recordStatement(
 	s.mu.vectorized = vectorized
 	s.mu.distSQLUsed = distSQLUsed
 	s.mu.Unlock()
-
 	return s.ID
 }
 
+// recordExecStats saves the statistics derived from the trace of a statement
+// whose execution statistics were collected.
+func (a *appStats) recordExecStats(
+	anonymizedStmt string, implicitTxn bool, err error, queryLevelStats *execstats.QueryLevelStats,
+) {
+	s, _ := a.getStatsForStmt(anonymizedStmt, implicitTxn, err, false /* createIfNonexistent */)
+	if s == nil {
+		return
+	}
+	record := func(data *roachpb.StatementStatistics) {
+		data.ExecStatCollectionCount++
+		// Record trace-related statistics.
+		data.BytesSentOverNetwork.Record(
+			data.ExecStatCollectionCount, float64(queryLevelStats.NetworkBytesSent),
+		)
+		data.MaxMemUsage.Record(
+			data.ExecStatCollectionCount, float64(queryLevelStats.MaxMemUsage),
+		)
+		data.ContentionTime.Record(
+			data.ExecStatCollectionCount, queryLevelStats.ContentionTime.Seconds(),
+		)
+	}
+	aggregatedTs, aggInterval, flushed := a.unflushedInterval()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record(&s.mu.data)
+	if flushed {
+		record(s.unflushedLocked(aggregatedTs, aggInterval))
+	}
+}
+
 // getStatsForStmt retrieves the per-stmt stat object. Regardless of if a valid
 // stat object is returned or not, we always return the correct stmtID
 // for the given stmt.
@@ -308,6 +440,8 @@ func (a *appStats) Add(other *appStats) {
 		v.mu.Lock()
 		statCopy := &stmtStats{}
 		statCopy.mu.data = v.mu.data
+		statCopy.mu.distSQLUsed = v.mu.distSQLUsed
+		statCopy.mu.vectorized = v.mu.vectorized
 		v.mu.Unlock()
 		statCopy.ID = v.ID
 		statMap[k] = statCopy
@@ -320,6 +454,8 @@ func (a *appStats) Add(other *appStats) {
 		// Note that we don't need to take a lock on v because
 		// no other thread knows about v yet.
 		s.mu.data.Add(&v.mu.data)
+		s.mu.distSQLUsed = v.mu.distSQLUsed
+		s.mu.vectorized = v.mu.vectorized
 		s.mu.Unlock()
 	}
 
@@ -431,17 +567,23 @@ func (a *appStats) recordTransaction(
 	s := a.getStatsForTxnWithKey(key, statementIDs, true /* createIfNonexistent */)
 
 	// Collect the per-transaction statistics.
+	record := func(data *roachpb.TransactionStatistics) {
+		data.Count++
+
+		data.NumRows.Record(data.Count, float64(numRows))
+		data.ServiceLat.Record(data.Count, serviceLat.Seconds())
+		data.RetryLat.Record(data.Count, retryLat.Seconds())
+		data.CommitLat.Record(data.Count, commitLat.Seconds())
+		if retryCount > data.MaxRetries {
+			data.MaxRetries = retryCount
+		}
+	}
+	aggregatedTs, aggInterval, flushed := a.unflushedInterval()
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
-	s.mu.data.Count++
-
-	s.mu.data.NumRows.Record(s.mu.data.Count, float64(numRows))
-	s.mu.data.ServiceLat.Record(s.mu.data.Count, serviceLat.Seconds())
-	s.mu.data.RetryLat.Record(s.mu.data.Count, retryLat.Seconds())
-	s.mu.data.CommitLat.Record(s.mu.data.Count, commitLat.Seconds())
-	if retryCount > s.mu.data.MaxRetries {
-		s.mu.data.MaxRetries = retryCount
+	record(&s.mu.data)
+	if flushed {
+		record(s.unflushedLocked(aggregatedTs, aggInterval))
 	}
 }
 
@@ -478,6 +620,12 @@ type sqlStats struct {
 	lastReset time.Time
 	// apps is the container for all the per-application statistics objects.
 	apps map[string]*appStats
+	// cpuProfiling, if set, is set while the continuous profiler collects a
+	// CPU profile, whose samples are attributed to the statements recorded
+	// in the meantime. Profiles collected by other means, such as through the
+	// debug pages, are not attributed, and neither are the statements
+	// executed during them.
+	cpuProfiling *syncutil.AtomicBool
 }
 
 func (s *sqlStats) getStatsForApplication(appName string) *appStats {
@@ -487,9 +635,10 @@ func (s *sqlStats) getStatsForApplication(appName string) *appStats {
 		return a
 	}
 	a := &appStats{
-		st:    s.st,
-		stmts: make(map[stmtKey]*stmtStats),
-		txns:  make(map[txnKey]*txnStats),
+		st:           s.st,
+		stmts:        make(map[stmtKey]*stmtStats),
+		txns:         make(map[txnKey]*txnStats),
+		cpuProfiling: s.cpuProfiling,
 	}
 	s.apps[appName] = a
 	return a
@@ -542,6 +691,10 @@ func (s *sqlStats) resetAndMaybeDumpStats(ctx context.Context, target *sqlStats)
 			appStatsCopy[appName] = aCopy
 		}
 
+		// The statistics which have not been persisted yet are kept until they
+		// are.
+		a.retireUnflushedLocked()
+
 		// Clear the map, to release the memory; make the new map somewhat already
 		// large for the likely future workload.
 		a.stmts = make(map[stmtKey]*stmtStats, len(a.stmts)/2)
@@ -561,6 +714,99 @@ func (s *sqlStats) resetAndMaybeDumpStats(ctx context.Context, target *sqlStats)
 	}
 }
 
+// retireUnflushedLocked moves the statistics which have not been persisted
+// yet out of a.stmts and a.txns, before they are reset. a must be locked.
+func (a *appStats) retireUnflushedLocked() {
+	for key, stats := range a.stmts {
+		stats.mu.Lock()
+		for _, u := range stats.mu.unflushed {
+			u.key, u.id = key, stats.ID
+			u.distSQLUsed, u.vectorized = stats.mu.distSQLUsed, stats.mu.vectorized
+			a.unflushedStmts = append(a.unflushedStmts, u)
+		}
+		stats.mu.unflushed = nil
+		stats.mu.Unlock()
+	}
+	for key, stats := range a.txns {
+		stats.mu.Lock()
+		for _, u := range stats.mu.unflushed {
+			u.key, u.statementIDs = key, stats.statementIDs
+			a.unflushedTxns = append(a.unflushedTxns, u)
+		}
+		stats.mu.unflushed = nil
+		stats.mu.Unlock()
+	}
+}
+
+// takeUnflushed removes the statistics which have not been persisted yet
+// from a and returns them, combined by fingerprint and aggregation interval.
+func (a *appStats) takeUnflushed() ([]unflushedStmtStats, []unflushedTxnStats) {
+	a.Lock()
+	a.retireUnflushedLocked()
+	stmts, txns := a.unflushedStmts, a.unflushedTxns
+	a.unflushedStmts, a.unflushedTxns = nil, nil
+	a.Unlock()
+
+	type stmtInterval struct {
+		key          stmtKey
+		aggregatedTs time.Time
+	}
+	stmtIdx := make(map[stmtInterval]int, len(stmts))
+	combinedStmts := stmts[:0]
+	for _, u := range stmts {
+		k := stmtInterval{key: u.key, aggregatedTs: u.aggregatedTs}
+		if i, ok := stmtIdx[k]; ok {
+			combinedStmts[i].data.Add(&u.data)
+			continue
+		}
+		stmtIdx[k] = len(combinedStmts)
+		combinedStmts = append(combinedStmts, u)
+	}
+	type txnInterval struct {
+		key          txnKey
+		aggregatedTs time.Time
+	}
+	txnIdx := make(map[txnInterval]int, len(txns))
+	combinedTxns := txns[:0]
+	for _, u := range txns {
+		k := txnInterval{key: u.key, aggregatedTs: u.aggregatedTs}
+		if i, ok := txnIdx[k]; ok {
+			combinedTxns[i].data.Add(&u.data)
+			continue
+		}
+		txnIdx[k] = len(combinedTxns)
+		combinedTxns = append(combinedTxns, u)
+	}
+	return combinedStmts, combinedTxns
+}
+
+// restoreUnflushedStmt puts back statistics returned by takeUnflushed which
+// failed to be persisted, so that they are persisted by the next flush.
+func (a *appStats) restoreUnflushedStmt(u unflushedStmtStats) {
+	a.Lock()
+	defer a.Unlock()
+	a.unflushedStmts = append(a.unflushedStmts, u)
+}
+
+// restoreUnflushedTxn is like restoreUnflushedStmt, for transaction
+// statistics.
+func (a *appStats) restoreUnflushedTxn(u unflushedTxnStats) {
+	a.Lock()
+	defer a.Unlock()
+	a.unflushedTxns = append(a.unflushedTxns, u)
+}
+
+// getApps returns the statistics of each application, by application name.
+func (s *sqlStats) getApps() map[string]*appStats {
+	s.Lock()
+	defer s.Unlock()
+	apps := make(map[string]*appStats, len(s.apps))
+	for appName, a := range s.apps {
+		apps[appName] = a
+	}
+	return apps
+}
+
 func (s *sqlStats) getLastReset() time.Time {
 	s.Lock()
 	defer s.Unlock()
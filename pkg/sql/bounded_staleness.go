@@ -0,0 +1,176 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// negotiateBoundedStalenessTimestamp picks the timestamp at which a planned
+// bounded staleness query is evaluated and applies it to the query's
+// transaction.
+//
+// The timestamp is negotiated with KV: the nearest replica of each range that
+// the query reads from reports its resolved timestamp, below which it can
+// serve reads without blocking. The newest timestamp within the query's
+// staleness bounds that all of these replicas can serve locally is chosen, in
+// which case the query's transaction is configured to route its reads to the
+// nearest replicas. If no such timestamp exists, the query is evaluated at its
+// maximum timestamp bound by the leaseholders, unless it specified nearest_only,
+// in which case an error is returned.
+func (ex *connExecutor) negotiateBoundedStalenessTimestamp(
+	ctx context.Context, p *planner,
+) error {
+	asOf := p.semaCtx.AsOfSystemTime
+	if !ex.server.cfg.Settings.Version.IsActive(ctx, clusterversion.BoundedStaleness) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`bounded staleness reads require all nodes to be upgraded to %s`,
+			clusterversion.ByKey(clusterversion.BoundedStaleness))
+	}
+
+	if !p.curPlan.hasPlanNodes() {
+		// The spans read by plans produced by the experimental DistSQL spec
+		// planner cannot be determined, so fall back to evaluating the query at
+		// its maximum timestamp bound.
+		if asOf.NearestOnly {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"nearest_only bounded staleness reads are not supported with experimental distsql planning")
+		}
+		return nil
+	}
+	spans, err := collectBoundedStalenessSpans(ctx, p)
+	if err != nil {
+		return err
+	}
+	if len(spans) == 0 {
+		// The query does not read from any table, so there is nothing to
+		// negotiate.
+		return nil
+	}
+
+	resolved, err := ex.server.cfg.DB.QueryResolvedTimestamp(ctx, spans...)
+	if err != nil {
+		return err
+	}
+
+	ts := asOf.Timestamp
+	ts.Backward(resolved)
+	if ts.Less(asOf.MinTimestampBound) {
+		if asOf.NearestOnly {
+			return pgerror.Newf(pgcode.UnsatisfiableBoundedStaleness,
+				"could not satisfy bounded staleness as resolved timestamp %s is below "+
+					"minimum timestamp bound %s", resolved, asOf.MinTimestampBound)
+		}
+		ts = asOf.Timestamp
+	}
+	// The query was planned with descriptors leased at its maximum timestamp
+	// bound. Make sure that they are still valid at the negotiated timestamp,
+	// which avoids the need to plan the query again.
+	ts.Forward(p.Descriptors().MaxLeasedModificationTime())
+
+	nearest := ts.LessEq(resolved)
+	if asOf.NearestOnly && !nearest {
+		return pgerror.Newf(pgcode.UnsatisfiableBoundedStaleness,
+			"could not satisfy bounded staleness as resolved timestamp %s is below "+
+				"the modification time %s of the descriptors used by the query", resolved, ts)
+	}
+	log.VEventf(ctx, 2, "negotiated bounded staleness timestamp %s (resolved: %s, nearest: %t)",
+		ts, resolved, nearest)
+
+	if ts != asOf.Timestamp {
+		ex.state.setHistoricalTimestamp(ctx, ts)
+		p.extendedEvalCtx.SetTxnTimestamp(ts.GoTime())
+	}
+	// Set the routing policy unconditionally, as the transaction may be an
+	// automatic retry of one that negotiated a different timestamp.
+	routingPolicy := roachpb.RoutingPolicy_LEASEHOLDER
+	if nearest {
+		routingPolicy = roachpb.RoutingPolicy_NEAREST
+	}
+	p.txn.SetRoutingPolicy(routingPolicy)
+	return nil
+}
+
+// collectBoundedStalenessSpans returns the key spans that the planned bounded
+// staleness query reads from.
+func collectBoundedStalenessSpans(ctx context.Context, p *planner) ([]roachpb.Span, error) {
+	var spans []roachpb.Span
+	codec := p.ExecCfg().Codec
+	addScan := func(n *scanNode, full bool) {
+		if n.desc.IsVirtualTable() {
+			return
+		}
+		if full {
+			spans = append(spans, n.desc.IndexSpan(codec, n.index.ID))
+			return
+		}
+		for _, sp := range n.spans {
+			if len(sp.EndKey) == 0 {
+				sp.EndKey = sp.Key.Next()
+			}
+			spans = append(spans, sp)
+		}
+	}
+	observer := planObserver{
+		enterNode: func(ctx context.Context, nodeName string, plan planNode) (bool, error) {
+			switch n := plan.(type) {
+			case *scanNode:
+				addScan(n, false /* full */)
+			case *indexJoinNode:
+				addScan(n.table, true /* full */)
+			case *lookupJoinNode:
+				addScan(n.table, true /* full */)
+			case *invertedJoinNode:
+				addScan(n.table, true /* full */)
+			case *zigzagJoinNode:
+				for i := range n.sides {
+					addScan(n.sides[i].scan, true /* full */)
+				}
+			case *applyJoinNode, *recursiveCTENode:
+				// The right side of an apply join and the recursive side of a
+				// recursive CTE are planned during execution, so the spans that
+				// they read from are not known up front.
+				return false, pgerror.Newf(pgcode.FeatureNotSupported,
+					"%s is not supported with bounded staleness reads", nodeName)
+			}
+			return true, nil
+		},
+	}
+	for i := range p.curPlan.subqueryPlans {
+		if err := walkPlan(ctx, p.curPlan.subqueryPlans[i].plan.planNode, observer); err != nil {
+			return nil, err
+		}
+	}
+	if err := walkPlan(ctx, p.curPlan.main.planNode, observer); err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
+
+// hasPlanNodes returns whether the main query and all subqueries were planned
+// as planNode trees, as opposed to physical plans.
+func (p *planComponents) hasPlanNodes() bool {
+	if p.main.planNode == nil {
+		return false
+	}
+	for i := range p.subqueryPlans {
+		if p.subqueryPlans[i].plan.planNode == nil {
+			return false
+		}
+	}
+	return true
+}
@@ -342,6 +342,24 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 
 	target.AddDescriptor(keys.SystemDatabaseID, systemschema.ScheduledJobsTable)
 	target.AddDescriptor(keys.SystemDatabaseID, systemschema.SqllivenessTable)
+
+	// Tables introduced in 21.1.
+
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.PublicationsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.ReplicationSlotsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.StatementStatisticsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.TransactionStatisticsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.StatementHintsTable)
+	if target.codec.ForSystemTenant() {
+		// Only add the tenant usage table if this is the system tenant.
+		target.AddDescriptor(keys.SystemDatabaseID, systemschema.TenantUsageTable)
+		// Likewise for the alert rules table, as only the system tenant has
+		// access to the time series database.
+		target.AddDescriptor(keys.SystemDatabaseID, systemschema.AlertRulesTable)
+		// Likewise for the transaction contention events table, as contention
+		// events are recorded by the KV layer.
+		target.AddDescriptor(keys.SystemDatabaseID, systemschema.TransactionContentionEventsTable)
+	}
 }
 
 // addSplitIDs adds a split point for each of the PseudoTableIDs to the supplied
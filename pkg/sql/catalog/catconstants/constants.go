@@ -77,6 +77,11 @@ const (
 	CrdbInternalZonesTableID
 	CrdbInternalInvalidDescriptorsTableID
 	CrdbInternalClusterDatabasePrivilegesTableID
+	CrdbInternalPersistedStmtStatsTableID
+	CrdbInternalPersistedTxnStatsTableID
+	CrdbInternalTenantUsageTableID
+	CrdbInternalHotKeysTableID
+	CrdbInternalTransactionContentionEventsTableID
 	InformationSchemaID
 	InformationSchemaAdministrableRoleAuthorizationsID
 	InformationSchemaApplicableRolesID
@@ -158,6 +163,10 @@ const (
 	PgCatalogStatActivityTableID
 	PgCatalogSecurityLabelTableID
 	PgCatalogSharedSecurityLabelTableID
+	PgCatalogPublicationTableID
+	PgCatalogPublicationRelTableID
+	PgCatalogPublicationTablesTableID
+	PgCatalogReplicationSlotsTableID
 	PgExtensionSchemaID
 	PgExtensionGeographyColumnsTableID
 	PgExtensionGeometryColumnsTableID
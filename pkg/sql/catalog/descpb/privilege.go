@@ -435,6 +435,14 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.StatementDiagnosticsTableID:          privilege.ReadWriteData,
 	keys.ScheduledJobsTableID:                 privilege.ReadWriteData,
 	keys.SqllivenessID:                        privilege.ReadWriteData,
+	keys.PublicationsTableID:                  privilege.ReadWriteData,
+	keys.ReplicationSlotsTableID:              privilege.ReadWriteData,
+	keys.StatementStatisticsTableID:           privilege.ReadWriteData,
+	keys.TransactionStatisticsTableID:         privilege.ReadWriteData,
+	keys.StatementHintsTableID:                privilege.ReadWriteData,
+	keys.TenantUsageTableID:                   privilege.ReadWriteData,
+	keys.AlertRulesTableID:                    privilege.ReadWriteData,
+	keys.TransactionContentionEventsTableID:   privilege.ReadWriteData,
 }
 
 // SetOwner sets the owner of the privilege descriptor to the provided string.
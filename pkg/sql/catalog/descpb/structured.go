@@ -279,6 +279,12 @@ func (desc *TableDescriptor) IsSequence() bool {
 	return desc.SequenceOpts != nil
 }
 
+// IsForeignTable returns true if the TableDescriptor describes a read-only
+// table whose rows are stored in external files rather than in the KV store.
+func (desc *TableDescriptor) IsForeignTable() bool {
+	return desc.ForeignTableOpts != nil
+}
+
 // IsVirtualTable returns true if the TableDescriptor describes a
 // virtual Table (like the information_schema tables) and thus doesn't
 // need to be physically stored.
@@ -198,6 +198,7 @@ type TableDescriptor interface {
 	GetNextIndexID() descpb.IndexID
 
 	HasPrimaryKey() bool
+	IsPrimaryIndexDefaultRowID() bool
 	PrimaryKeyString() string
 
 	GetPublicColumns() []descpb.ColumnDescriptor
@@ -240,6 +241,7 @@ type TableDescriptor interface {
 	IsSequence() bool
 	IsTemporary() bool
 	IsVirtualTable() bool
+	IsForeignTable() bool
 	IsPhysicalTable() bool
 	IsInterleaved() bool
 	MaterializedView() bool
@@ -42,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
@@ -1271,6 +1272,18 @@ func (tc *Collection) ReleaseLeases(ctx context.Context) {
 	tc.leasedDescriptors.descs = tc.leasedDescriptors.descs[:0]
 }
 
+// MaxLeasedModificationTime returns the maximum modification time of any
+// descriptor currently leased by the Collection. Reads at or above this
+// timestamp are guaranteed to observe the leased versions of all of these
+// descriptors.
+func (tc *Collection) MaxLeasedModificationTime() hlc.Timestamp {
+	var maxTS hlc.Timestamp
+	for _, desc := range tc.leasedDescriptors.descs {
+		maxTS.Forward(desc.GetModificationTime())
+	}
+	return maxTS
+}
+
 // ReleaseAll releases all state currently held by the Collection.
 // ReleaseAll calls ReleaseLeases.
 func (tc *Collection) ReleaseAll(ctx context.Context) {
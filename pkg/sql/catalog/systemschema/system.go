@@ -345,6 +345,129 @@ CREATE TABLE system.sqlliveness (
     expiration       DECIMAL NOT NULL,
   	FAMILY fam0_session_id_expiration (session_id, expiration)
 )`
+
+	// publications stores the publications created with CREATE PUBLICATION,
+	// which define the sets of tables streamed to logical replication clients.
+	PublicationsTableSchema = `
+CREATE TABLE system.publications (
+    database_id INT8        NOT NULL,
+    name        STRING      NOT NULL,
+    owner       STRING      NOT NULL,
+    all_tables  BOOL        NOT NULL,
+    table_ids   INT8[],
+    created     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (database_id, name),
+    FAMILY "primary" (database_id, name, owner, all_tables, table_ids, created)
+)`
+
+	// replication_slots stores the logical replication slots, which track the
+	// position up to which a logical replication client has consumed changes.
+	ReplicationSlotsTableSchema = `
+CREATE TABLE system.replication_slots (
+    name                STRING      NOT NULL PRIMARY KEY,
+    database_id         INT8        NOT NULL,
+    plugin              STRING      NOT NULL,
+    owner               STRING      NOT NULL,
+    confirmed_flush_lsn INT8        NOT NULL,
+    created             TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FAMILY "primary" (name, database_id, plugin, owner, confirmed_flush_lsn, created)
+)`
+
+	// statement_statistics stores the statement statistics periodically flushed
+	// by each node, bucketed by aggregation interval.
+	StatementStatisticsTableSchema = `
+CREATE TABLE system.statement_statistics (
+    aggregated_ts  TIMESTAMPTZ NOT NULL,
+    fingerprint_id BYTES       NOT NULL,
+    app_name       STRING      NOT NULL,
+    node_id        INT8        NOT NULL,
+    agg_interval   INTERVAL    NOT NULL,
+    statistics     BYTES       NOT NULL,
+    PRIMARY KEY (aggregated_ts, fingerprint_id, app_name, node_id),
+    FAMILY "primary" (aggregated_ts, fingerprint_id, app_name, node_id, agg_interval, statistics)
+)`
+
+	// transaction_statistics stores the transaction statistics periodically
+	// flushed by each node, bucketed by aggregation interval.
+	TransactionStatisticsTableSchema = `
+CREATE TABLE system.transaction_statistics (
+    aggregated_ts  TIMESTAMPTZ NOT NULL,
+    fingerprint_id BYTES       NOT NULL,
+    app_name       STRING      NOT NULL,
+    node_id        INT8        NOT NULL,
+    agg_interval   INTERVAL    NOT NULL,
+    statistics     BYTES       NOT NULL,
+    PRIMARY KEY (aggregated_ts, fingerprint_id, app_name, node_id),
+    FAMILY "primary" (aggregated_ts, fingerprint_id, app_name, node_id, agg_interval, statistics)
+)`
+
+	// statement_hints stores the plan hints pinned for statement fingerprints.
+	StatementHintsTableSchema = `
+CREATE TABLE system.statement_hints (
+    fingerprint STRING      NOT NULL PRIMARY KEY,
+    hints       BYTES       NOT NULL,
+    created     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FAMILY "primary" (fingerprint, hints, created)
+)`
+
+	// tenant_usage stores the resources consumed by each tenant, as
+	// periodically rolled up by each node, bucketed by aggregation interval.
+	// The flush_seq column identifies the last flush of the node applied to
+	// the row, which makes retried flushes idempotent.
+	TenantUsageTableSchema = `
+CREATE TABLE system.tenant_usage (
+    tenant_id            INT8        NOT NULL,
+    aggregated_ts        TIMESTAMPTZ NOT NULL,
+    node_id              INT8        NOT NULL,
+    ru                   FLOAT8      NOT NULL,
+    read_requests        INT8        NOT NULL,
+    read_bytes           INT8        NOT NULL,
+    write_requests       INT8        NOT NULL,
+    write_bytes          INT8        NOT NULL,
+    sql_pods_cpu_seconds FLOAT8      NOT NULL,
+    flush_seq            INT8        NOT NULL,
+    PRIMARY KEY (tenant_id, aggregated_ts, node_id),
+    FAMILY "primary" (tenant_id, aggregated_ts, node_id, ru, read_requests, read_bytes, write_requests, write_bytes, sql_pods_cpu_seconds, flush_seq)
+)`
+
+	// alert_rules stores the user-defined alert rules evaluated against the
+	// internal time series database. The state, active_since and last_value
+	// columns are maintained by the rule evaluator.
+	AlertRulesTableSchema = `
+CREATE TABLE system.alert_rules (
+    name         STRING      NOT NULL PRIMARY KEY,
+    metric       STRING      NOT NULL,
+    aggregator   STRING      NOT NULL DEFAULT 'avg',
+    rate         BOOL        NOT NULL DEFAULT false,
+    operator     STRING      NOT NULL DEFAULT '>',
+    threshold    FLOAT8      NOT NULL,
+    duration     INTERVAL    NOT NULL DEFAULT '0s',
+    severity     STRING      NOT NULL DEFAULT 'warning',
+    state        STRING      NOT NULL DEFAULT 'inactive',
+    active_since TIMESTAMPTZ,
+    last_value   FLOAT8,
+    created      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FAMILY "primary" (name, metric, aggregator, rate, operator, threshold, duration, severity, state, active_since, last_value, created)
+)`
+
+	// transaction_contention_events stores the history of the contention events
+	// between transactions: a transaction waited for duration on a lock on key
+	// held by another one, starting at ts. The statement fingerprints of the
+	// transactions are NULL if they could not be resolved.
+	TransactionContentionEventsTableSchema = `
+CREATE TABLE system.transaction_contention_events (
+    ts                        TIMESTAMPTZ NOT NULL,
+    event_id                  INT8        NOT NULL DEFAULT unique_rowid(),
+    node_id                   INT8        NOT NULL,
+    key                       BYTES       NOT NULL,
+    duration                  INTERVAL    NOT NULL,
+    waiting_txn_id            UUID,
+    waiting_txn_fingerprints  STRING[],
+    blocking_txn_id           UUID        NOT NULL,
+    blocking_txn_fingerprints STRING[],
+    PRIMARY KEY (ts, event_id),
+    FAMILY "primary" (ts, event_id, node_id, key, duration, waiting_txn_id, waiting_txn_fingerprints, blocking_txn_id, blocking_txn_fingerprints)
+)`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1690,6 +1813,354 @@ var (
 		FormatVersion:  descpb.InterleavedFormatVersion,
 		NextMutationID: 1,
 	})
+
+	// PublicationsTable is the descriptor for the publications table.
+	PublicationsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "publications",
+		ID:                      keys.PublicationsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "database_id", ID: 1, Type: types.Int},
+			{Name: "name", ID: 2, Type: types.String},
+			{Name: "owner", ID: 3, Type: types.String},
+			{Name: "all_tables", ID: 4, Type: types.Bool},
+			{Name: "table_ids", ID: 5, Type: types.IntArray, Nullable: true},
+			{Name: "created", ID: 6, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+		},
+		NextColumnID: 7,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"database_id", "name", "owner", "all_tables", "table_ids", "created"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: descpb.IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"database_id", "name"},
+			ColumnDirections: []descpb.IndexDescriptor_Direction{descpb.IndexDescriptor_ASC, descpb.IndexDescriptor_ASC},
+			ColumnIDs:        []descpb.ColumnID{1, 2},
+			Version:          descpb.EmptyArraysInInvertedIndexesVersion,
+		},
+		NextIndexID: 2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.PublicationsTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// ReplicationSlotsTable is the descriptor for the replication slots table.
+	ReplicationSlotsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "replication_slots",
+		ID:                      keys.ReplicationSlotsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "name", ID: 1, Type: types.String},
+			{Name: "database_id", ID: 2, Type: types.Int},
+			{Name: "plugin", ID: 3, Type: types.String},
+			{Name: "owner", ID: 4, Type: types.String},
+			{Name: "confirmed_flush_lsn", ID: 5, Type: types.Int},
+			{Name: "created", ID: 6, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+		},
+		NextColumnID: 7,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"name", "database_id", "plugin", "owner", "confirmed_flush_lsn", "created"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("name"),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.ReplicationSlotsTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// StatementStatisticsTable is the descriptor for the statement statistics table.
+	StatementStatisticsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "statement_statistics",
+		ID:                      keys.StatementStatisticsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "aggregated_ts", ID: 1, Type: types.TimestampTZ},
+			{Name: "fingerprint_id", ID: 2, Type: types.Bytes},
+			{Name: "app_name", ID: 3, Type: types.String},
+			{Name: "node_id", ID: 4, Type: types.Int},
+			{Name: "agg_interval", ID: 5, Type: types.Interval},
+			{Name: "statistics", ID: 6, Type: types.Bytes},
+		},
+		NextColumnID: 7,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"aggregated_ts", "fingerprint_id", "app_name", "node_id", "agg_interval", "statistics"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: descpb.IndexDescriptor{
+			Name:        "primary",
+			ID:          1,
+			Unique:      true,
+			ColumnNames: []string{"aggregated_ts", "fingerprint_id", "app_name", "node_id"},
+			ColumnDirections: []descpb.IndexDescriptor_Direction{
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+			},
+			ColumnIDs: []descpb.ColumnID{1, 2, 3, 4},
+			Version:   descpb.EmptyArraysInInvertedIndexesVersion,
+		},
+		NextIndexID: 2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.StatementStatisticsTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// TransactionStatisticsTable is the descriptor for the transaction statistics
+	// table.
+	TransactionStatisticsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "transaction_statistics",
+		ID:                      keys.TransactionStatisticsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "aggregated_ts", ID: 1, Type: types.TimestampTZ},
+			{Name: "fingerprint_id", ID: 2, Type: types.Bytes},
+			{Name: "app_name", ID: 3, Type: types.String},
+			{Name: "node_id", ID: 4, Type: types.Int},
+			{Name: "agg_interval", ID: 5, Type: types.Interval},
+			{Name: "statistics", ID: 6, Type: types.Bytes},
+		},
+		NextColumnID: 7,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"aggregated_ts", "fingerprint_id", "app_name", "node_id", "agg_interval", "statistics"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: descpb.IndexDescriptor{
+			Name:        "primary",
+			ID:          1,
+			Unique:      true,
+			ColumnNames: []string{"aggregated_ts", "fingerprint_id", "app_name", "node_id"},
+			ColumnDirections: []descpb.IndexDescriptor_Direction{
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+			},
+			ColumnIDs: []descpb.ColumnID{1, 2, 3, 4},
+			Version:   descpb.EmptyArraysInInvertedIndexesVersion,
+		},
+		NextIndexID: 2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.TransactionStatisticsTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// StatementHintsTable is the descriptor for the statement hints table.
+	StatementHintsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "statement_hints",
+		ID:                      keys.StatementHintsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "fingerprint", ID: 1, Type: types.String},
+			{Name: "hints", ID: 2, Type: types.Bytes},
+			{Name: "created", ID: 3, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+		},
+		NextColumnID: 4,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"fingerprint", "hints", "created"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("fingerprint"),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.StatementHintsTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// TenantUsageTable is the descriptor for the tenant usage table.
+	TenantUsageTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "tenant_usage",
+		ID:                      keys.TenantUsageTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "tenant_id", ID: 1, Type: types.Int},
+			{Name: "aggregated_ts", ID: 2, Type: types.TimestampTZ},
+			{Name: "node_id", ID: 3, Type: types.Int},
+			{Name: "ru", ID: 4, Type: types.Float},
+			{Name: "read_requests", ID: 5, Type: types.Int},
+			{Name: "read_bytes", ID: 6, Type: types.Int},
+			{Name: "write_requests", ID: 7, Type: types.Int},
+			{Name: "write_bytes", ID: 8, Type: types.Int},
+			{Name: "sql_pods_cpu_seconds", ID: 9, Type: types.Float},
+			{Name: "flush_seq", ID: 10, Type: types.Int},
+		},
+		NextColumnID: 11,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ID:   0,
+				ColumnNames: []string{
+					"tenant_id", "aggregated_ts", "node_id", "ru", "read_requests", "read_bytes",
+					"write_requests", "write_bytes", "sql_pods_cpu_seconds", "flush_seq",
+				},
+				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: descpb.IndexDescriptor{
+			Name:        "primary",
+			ID:          1,
+			Unique:      true,
+			ColumnNames: []string{"tenant_id", "aggregated_ts", "node_id"},
+			ColumnDirections: []descpb.IndexDescriptor_Direction{
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+			},
+			ColumnIDs: []descpb.ColumnID{1, 2, 3},
+			Version:   descpb.EmptyArraysInInvertedIndexesVersion,
+		},
+		NextIndexID: 2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.TenantUsageTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	alertAggregatorDefault = "'avg':::STRING"
+	alertOperatorDefault   = "'>':::STRING"
+	alertDurationDefault   = "'00:00:00':::INTERVAL"
+	alertSeverityDefault   = "'warning':::STRING"
+	alertStateDefault      = "'inactive':::STRING"
+
+	// AlertRulesTable is the descriptor for the alert rules table.
+	AlertRulesTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "alert_rules",
+		ID:                      keys.AlertRulesTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "name", ID: 1, Type: types.String},
+			{Name: "metric", ID: 2, Type: types.String},
+			{Name: "aggregator", ID: 3, Type: types.String, DefaultExpr: &alertAggregatorDefault},
+			{Name: "rate", ID: 4, Type: types.Bool, DefaultExpr: &falseBoolString},
+			{Name: "operator", ID: 5, Type: types.String, DefaultExpr: &alertOperatorDefault},
+			{Name: "threshold", ID: 6, Type: types.Float},
+			{Name: "duration", ID: 7, Type: types.Interval, DefaultExpr: &alertDurationDefault},
+			{Name: "severity", ID: 8, Type: types.String, DefaultExpr: &alertSeverityDefault},
+			{Name: "state", ID: 9, Type: types.String, DefaultExpr: &alertStateDefault},
+			{Name: "active_since", ID: 10, Type: types.TimestampTZ, Nullable: true},
+			{Name: "last_value", ID: 11, Type: types.Float, Nullable: true},
+			{Name: "created", ID: 12, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+		},
+		NextColumnID: 13,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ID:   0,
+				ColumnNames: []string{
+					"name", "metric", "aggregator", "rate", "operator", "threshold", "duration",
+					"severity", "state", "active_since", "last_value", "created",
+				},
+				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("name"),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.AlertRulesTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// TransactionContentionEventsTable is the descriptor for the transaction
+	// contention events table.
+	TransactionContentionEventsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "transaction_contention_events",
+		ID:                      keys.TransactionContentionEventsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "ts", ID: 1, Type: types.TimestampTZ},
+			{Name: "event_id", ID: 2, Type: types.Int, DefaultExpr: &uniqueRowIDString},
+			{Name: "node_id", ID: 3, Type: types.Int},
+			{Name: "key", ID: 4, Type: types.Bytes},
+			{Name: "duration", ID: 5, Type: types.Interval},
+			{Name: "waiting_txn_id", ID: 6, Type: types.Uuid, Nullable: true},
+			{Name: "waiting_txn_fingerprints", ID: 7, Type: types.StringArray, Nullable: true},
+			{Name: "blocking_txn_id", ID: 8, Type: types.Uuid},
+			{Name: "blocking_txn_fingerprints", ID: 9, Type: types.StringArray, Nullable: true},
+		},
+		NextColumnID: 10,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ID:   0,
+				ColumnNames: []string{
+					"ts", "event_id", "node_id", "key", "duration", "waiting_txn_id",
+					"waiting_txn_fingerprints", "blocking_txn_id", "blocking_txn_fingerprints",
+				},
+				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: descpb.IndexDescriptor{
+			Name:        "primary",
+			ID:          1,
+			Unique:      true,
+			ColumnNames: []string{"ts", "event_id"},
+			ColumnDirections: []descpb.IndexDescriptor_Direction{
+				descpb.IndexDescriptor_ASC,
+				descpb.IndexDescriptor_ASC,
+			},
+			ColumnIDs: []descpb.ColumnID{1, 2},
+			Version:   descpb.EmptyArraysInInvertedIndexesVersion,
+		},
+		NextIndexID: 2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.TransactionContentionEventsTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
 )
 
 // newCommentPrivilegeDescriptor returns a privilege descriptor for comment table
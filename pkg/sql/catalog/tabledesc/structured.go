@@ -1812,6 +1812,28 @@ func (desc *wrapper) ValidateIndexNameIsUnique(indexName string) error {
 	return nil
 }
 
+// validateForeignTable validates that a foreign table has at least one
+// location to read from, and that it has no secondary indexes or mutations,
+// which could never be maintained since the data is not stored in the cluster.
+func (desc *wrapper) validateForeignTable() error {
+	if !desc.IsForeignTable() {
+		return nil
+	}
+	if desc.IsView() {
+		return errors.AssertionFailedf("foreign table %q cannot be a view", desc.Name)
+	}
+	if len(desc.ForeignTableOpts.Locations) == 0 {
+		return errors.AssertionFailedf("foreign table %q has no locations", desc.Name)
+	}
+	if len(desc.Indexes) > 0 {
+		return errors.AssertionFailedf("foreign table %q has secondary indexes", desc.Name)
+	}
+	if len(desc.Mutations) > 0 {
+		return errors.AssertionFailedf("foreign table %q has mutations", desc.Name)
+	}
+	return nil
+}
+
 // ValidateTable validates that the table descriptor is well formed. Checks
 // include validating the table, column and index names, verifying that column
 // names and index names are unique and verifying that column IDs and index IDs
@@ -1862,6 +1884,10 @@ func (desc *wrapper) ValidateTable(ctx context.Context) error {
 		return ErrMissingColumns
 	}
 
+	if err := desc.validateForeignTable(); err != nil {
+		return err
+	}
+
 	if err := desc.CheckUniqueConstraints(); err != nil {
 		return err
 	}
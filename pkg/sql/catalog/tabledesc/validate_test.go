@@ -112,6 +112,7 @@ var validationMap = []struct {
 			"Temporary":                     {status: thisFieldReferencesNoObjects},
 			"LocalityConfig":                {status: iSolemnlySwearThisFieldIsValidated},
 			"PartitionAllBy":                {status: iSolemnlySwearThisFieldIsValidated},
+			"ForeignTableOpts":              {status: iSolemnlySwearThisFieldIsValidated},
 		},
 	},
 	{
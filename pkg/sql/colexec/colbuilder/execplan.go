@@ -335,6 +335,7 @@ func canWrap(mode sessiondatapb.VectorizeExecMode, spec *execinfrapb.ProcessorSp
 	case spec.Core.SplitAndScatter != nil:
 		return errSplitAndScatterWrap
 	case spec.Core.RestoreData != nil:
+	case spec.Core.ForeignTableScan != nil:
 	default:
 		return errors.AssertionFailedf("unexpected processor core %q", spec.Core)
 	}
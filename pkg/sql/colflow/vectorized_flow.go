@@ -939,37 +939,49 @@ func (s *vectorizedFlowCreator) setupOutput(
 		}
 	case execinfrapb.StreamEndpointSpec_REMOTE:
 		// Set up an Outbox.
+		var vscs []colexec.VectorizedStatsCollector
 		if s.recordingStats {
-			// If recording stats, we add a metadata source that will generate all
-			// stats data as metadata for the stats collectors created so far.
-			vscs := append([]colexec.VectorizedStatsCollector(nil), s.vectorizedStatsCollectorsQueue...)
+			// If recording stats, the metadata source below generates all stats
+			// data as metadata for the stats collectors created so far.
+			vscs = append([]colexec.VectorizedStatsCollector(nil), s.vectorizedStatsCollectorsQueue...)
 			s.vectorizedStatsCollectorsQueue = s.vectorizedStatsCollectorsQueue[:0]
-			metadataSourcesQueue = append(
-				metadataSourcesQueue,
-				execinfrapb.CallbackMetadataSource{
-					DrainMetaCb: func(ctx context.Context) []execinfrapb.ProducerMetadata {
-						// Start a separate recording so that GetRecording will return
-						// the recordings for only the child spans containing stats.
-						ctx, span := tracing.ChildSpanRemote(ctx, "")
-						if atomic.AddInt32(&s.numOutboxesDrained, 1) == atomic.LoadInt32(&s.numOutboxes) && !s.isGatewayNode {
-							// At the last outbox, we can accurately retrieve stats for the
-							// whole flow from parent monitors. These stats are added to a
-							// flow-level span.
-							span.SetTag(execinfrapb.FlowIDTagKey, flowCtx.ID)
-							span.SetSpanStats(&execinfrapb.ComponentStats{
-								Component: execinfrapb.FlowComponentID(outputStream.OriginNodeID, flowCtx.ID),
-								FlowStats: execinfrapb.FlowStats{
-									MaxMemUsage: optional.MakeUint(uint64(flowCtx.EvalCtx.Mon.MaximumBytes())),
-								},
-							})
-						}
-						finishVectorizedStatsCollectors(ctx, vscs)
-						span.Finish()
-						return []execinfrapb.ProducerMetadata{{TraceData: span.GetRecording()}}
-					},
-				},
-			)
 		}
+		metadataSourcesQueue = append(
+			metadataSourcesQueue,
+			execinfrapb.CallbackMetadataSource{
+				DrainMetaCb: func(ctx context.Context) []execinfrapb.ProducerMetadata {
+					// At the last outbox, we can accurately retrieve stats for the
+					// whole flow from parent monitors.
+					lastOutbox := atomic.AddInt32(&s.numOutboxesDrained, 1) == atomic.LoadInt32(&s.numOutboxes) && !s.isGatewayNode
+					var meta []execinfrapb.ProducerMetadata
+					if lastOutbox {
+						// The peak memory usage of the flow is reported to the gateway.
+						metricsMeta := execinfrapb.GetMetricsMeta()
+						metricsMeta.PeakMemUsage = flowCtx.EvalCtx.Mon.MaximumBytes()
+						meta = append(meta, execinfrapb.ProducerMetadata{Metrics: metricsMeta})
+					}
+					if !s.recordingStats {
+						return meta
+					}
+					// Start a separate recording so that GetRecording will return
+					// the recordings for only the child spans containing stats.
+					ctx, span := tracing.ChildSpanRemote(ctx, "")
+					if lastOutbox {
+						// The stats of the whole flow are added to a flow-level span.
+						span.SetTag(execinfrapb.FlowIDTagKey, flowCtx.ID)
+						span.SetSpanStats(&execinfrapb.ComponentStats{
+							Component: execinfrapb.FlowComponentID(outputStream.OriginNodeID, flowCtx.ID),
+							FlowStats: execinfrapb.FlowStats{
+								MaxMemUsage: optional.MakeUint(uint64(flowCtx.EvalCtx.Mon.MaximumBytes())),
+							},
+						})
+					}
+					finishVectorizedStatsCollectors(ctx, vscs)
+					span.Finish()
+					return append(meta, execinfrapb.ProducerMetadata{TraceData: span.GetRecording()})
+				},
+			},
+		)
 		outbox, err := s.setupRemoteOutputStream(
 			ctx, flowCtx, op, opOutputTypes, outputStream, metadataSourcesQueue, toClose, factory,
 		)
@@ -196,11 +196,13 @@ func TestDrainOnlyInputDAG(t *testing.T) {
 		) (*colrpc.Outbox, error) {
 			require.False(t, outboxCreated)
 			outboxCreated = true
-			// Verify that there is only one metadata source: the inbox that is the
-			// input to the noop operator. This is verified by first checking the
-			// number of metadata sources and then that the input types are what we
-			// expect from the input DAG.
-			require.Len(t, sources, 1)
+			// Verify that there are only two metadata sources: the inbox that is
+			// the input to the noop operator, and the source of the metadata about
+			// the flow itself. This is verified by first checking the number of
+			// metadata sources and then that the input types are what we expect
+			// from the input DAG.
+			require.Len(t, sources, 2)
+			require.IsType(t, execinfrapb.CallbackMetadataSource{}, sources[1])
 			require.Len(t, inboxToNumInputTypes[sources[0].(*colrpc.Inbox)], numInputTypesToOutbox)
 			return colrpc.NewOutbox(allocator, op, typs, sources, nil /* toClose */)
 		},
@@ -48,6 +48,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/cancelchecker"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -257,6 +258,9 @@ type Server struct {
 	// cleared on a lower interval than sqlStats. Stats from sqlStats flow
 	// into reported stats when sqlStats is cleared.
 	reportedStats sqlStats
+	// cpuProfiling is set while the continuous profiler collects a CPU
+	// profile. See CPUProfileStarted.
+	cpuProfiling syncutil.AtomicBool
 
 	reCache *tree.RegexpCache
 
@@ -290,7 +294,7 @@ type Metrics struct {
 // NewServer creates a new Server. Start() needs to be called before the Server
 // is used.
 func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
-	return &Server{
+	s := &Server{
 		cfg:             cfg,
 		Metrics:         makeMetrics(false /*internal*/),
 		InternalMetrics: makeMetrics(true /*internal*/),
@@ -299,6 +303,8 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 		reportedStats:   sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
 		reCache:         tree.NewRegexpCache(512),
 	}
+	s.sqlStats.cpuProfiling = &s.cpuProfiling
+	return s
 }
 
 func makeMetrics(internal bool) Metrics {
@@ -341,6 +347,10 @@ func (s *Server) Start(ctx context.Context, stopper *stop.Stopper) {
 	s.PeriodicallyClearSQLStats(ctx, stopper, MaxSQLStatReset, &s.reportedStats, s.ResetReportedStats)
 	// Start a second loop to clear SQL stats at the requested interval.
 	s.PeriodicallyClearSQLStats(ctx, stopper, SQLStatReset, &s.sqlStats, s.ResetSQLStats)
+	// Start a loop to persist the SQL stats.
+	s.periodicallyFlushSQLStats(ctx, stopper)
+	// Start a loop to reload the pinned plans.
+	s.periodicallyReloadPlanHints(ctx, stopper)
 }
 
 // ResetSQLStats resets the executor's collected sql statistics.
@@ -699,8 +709,10 @@ func (s *Server) newConnExecutorWithTxn(
 		txn.ReadTimestamp().GoTime(),
 		nil, /* historicalTimestamp */
 		roachpb.UnspecifiedUserPriority,
+		txn.IsolationLevel(),
 		tree.ReadWrite,
 		txn,
+		nil, /* remoteParent */
 		ex.transitionCtx)
 
 	// Modify the Collection to match the parent executor's Collection.
@@ -1583,6 +1595,13 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+	case StartReplication:
+		res = ex.clientComm.CreateStartReplicationResult(pos)
+		var err error
+		ev, payload, err = ex.execStartReplication(ctx, tcmd)
+		if err != nil {
+			return err
+		}
 	case DrainRequest:
 		// We received a drain request. We terminate immediately if we're not in a
 		// transaction. If we are in a transaction, we'll finish as soon as a Sync
@@ -1771,6 +1790,8 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 				canAdvance = true
 			case CopyIn:
 				// Can't advance.
+			case StartReplication:
+				// Can't advance.
 			case DrainRequest:
 				canAdvance = true
 			case Flush:
@@ -1993,11 +2014,24 @@ func errIsRetriable(err error) bool {
 	return errors.HasType(err, (*roachpb.TransactionRetryWithProtoRefreshError)(nil))
 }
 
+// restartTxnIfDeferred restarts the KV transaction if its restart was
+// deferred after a retryable error, which happens for transactions that read
+// from a new snapshot in each statement (see
+// dispatchToExecutionEngineWithStmtRetries). It must be called before a
+// retryable error is handed to the state machine, which expects the
+// transaction to have been prepared for the next attempt.
+func (ex *connExecutor) restartTxnIfDeferred() {
+	if txn := ex.state.mu.txn; txn != nil {
+		txn.RestartIfDeferred(ex.Ctx())
+	}
+}
+
 // makeErrEvent takes an error and returns either an eventRetriableErr or an
 // eventNonRetriableErr, depending on the error type.
 func (ex *connExecutor) makeErrEvent(err error, stmt tree.Statement) (fsm.Event, fsm.EventPayload) {
 	retriable := errIsRetriable(err)
 	if retriable {
+		ex.restartTxnIfDeferred()
 		rc, canAutoRetry := ex.getRewindTxnCapability()
 		ev := eventRetriableErr{
 			IsCommit:     fsm.FromBool(isCommit(stmt)),
@@ -2032,9 +2066,11 @@ func (ex *connExecutor) setTransactionModes(
 			return err
 		}
 	}
-	if modes.Isolation != tree.UnspecifiedIsolation && modes.Isolation != tree.SerializableIsolation {
-		return errors.AssertionFailedf(
-			"unknown isolation level: %s", errors.Safe(modes.Isolation))
+	if modes.Isolation != tree.UnspecifiedIsolation {
+		isoLevel := ex.txnIsolationLevelToProto(ex.Ctx(), modes.Isolation)
+		if err := ex.state.setIsolationLevel(isoLevel); err != nil {
+			return err
+		}
 	}
 	rwMode := modes.ReadWriteMode
 	if modes.AsOf.Expr != nil && asOfTs.IsEmpty() {
@@ -2074,6 +2110,49 @@ func (ex *connExecutor) txnPriorityWithSessionDefault(mode tree.UserPriority) ro
 	return txnPriorityToProto(mode)
 }
 
+// txnRemoteParent returns the client span under which the span of a new
+// transaction is created, as identified by the traceparent session
+// variable, if any.
+func (ex *connExecutor) txnRemoteParent() *tracing.SpanMeta {
+	if ex.sessionData.TraceParent == "" {
+		return nil
+	}
+	return ex.server.cfg.AmbientCtx.Tracer.RemoteParentFromTraceParent(ex.sessionData.TraceParent)
+}
+
+// txnIsolationLevelToProto returns the KV isolation level that transactions
+// run at when the given SQL isolation level is requested. READ COMMITTED is
+// only honored once it is enabled through the cluster setting and all nodes
+// in the cluster understand it; until then, it is upgraded to SERIALIZABLE,
+// which is permitted by the SQL standard.
+func (ex *connExecutor) txnIsolationLevelToProto(
+	ctx context.Context, level tree.IsolationLevel,
+) enginepb.IsolationLevel {
+	switch level {
+	case tree.UnspecifiedIsolation, tree.SerializableIsolation:
+		return enginepb.SERIALIZABLE
+	case tree.ReadCommittedIsolation:
+		st := ex.server.cfg.Settings
+		if allowReadCommittedIsolation.Get(&st.SV) &&
+			st.Version.IsActive(ctx, clusterversion.ReadCommittedIsolation) {
+			return enginepb.READ_COMMITTED
+		}
+		return enginepb.SERIALIZABLE
+	default:
+		log.Fatalf(ctx, "unknown isolation level: %s", level)
+		return enginepb.SERIALIZABLE
+	}
+}
+
+func (ex *connExecutor) txnIsolationLevelWithSessionDefault(
+	ctx context.Context, level tree.IsolationLevel,
+) enginepb.IsolationLevel {
+	if level == tree.UnspecifiedIsolation {
+		level = tree.IsolationLevel(ex.sessionData.DefaultTxnIsolationLevel)
+	}
+	return ex.txnIsolationLevelToProto(ctx, level)
+}
+
 func (ex *connExecutor) readWriteModeWithSessionDefault(
 	mode tree.ReadWriteMode,
 ) tree.ReadWriteMode {
@@ -2224,7 +2303,7 @@ func (ex *connExecutor) resetPlanner(
 
 	p.semaCtx = tree.MakeSemaContext()
 	p.semaCtx.SearchPath = ex.sessionData.SearchPath
-	p.semaCtx.AsOfTimestamp = nil
+	p.semaCtx.AsOfSystemTime = nil
 	p.semaCtx.Annotations = nil
 	p.semaCtx.TypeResolver = p
 
@@ -2525,7 +2604,7 @@ func (ex *connExecutor) runPreCommitStages(ctx context.Context) error {
 	}
 	executor := scexec.NewExecutor(
 		ex.planner.txn, &ex.extraTxnState.descCollection, ex.server.cfg.Codec,
-		nil /* backfiller */, nil, /* jobTracker */
+		nil /* backfiller */, nil /* jobTracker */, nil /* validator */, nil, /* jobCreator */
 	)
 	after, err := runNewSchemaChanger(
 		ctx, scplan.PreCommitPhase,
@@ -17,7 +17,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -108,10 +110,10 @@ func (ex *connExecutor) execStmt(
 				remoteAddr = rAddr.String()
 			}
 			labels := pprof.Labels(
-				"appname", ex.sessionData.ApplicationName,
+				appNameProfilerLabel, ex.sessionData.ApplicationName,
 				"addr", remoteAddr,
 				"stmt.tag", ast.StatementTag(),
-				"stmt.anonymized", anonymizeStmt(ast),
+				stmtProfilerLabel, anonymizeStmt(ast),
 			)
 			pprof.Do(ctx, labels, func(ctx context.Context) {
 				ev, payload, err = ex.execStmtInOpenState(ctx, parserStmt, prepared, pinfo, res)
@@ -541,32 +543,36 @@ func (ex *connExecutor) execStmtInOpenState(
 	// don't return any event unless an error happens.
 
 	if os.ImplicitTxn.Get() {
-		asOfTs, err := p.isAsOf(ctx, ast)
+		asOf, err := p.isAsOf(ctx, ast)
 		if err != nil {
 			return makeErrEvent(err)
 		}
-		if asOfTs != nil {
-			p.semaCtx.AsOfTimestamp = asOfTs
-			p.extendedEvalCtx.SetTxnTimestamp(asOfTs.GoTime())
-			ex.state.setHistoricalTimestamp(ctx, *asOfTs)
+		if asOf != nil {
+			p.semaCtx.AsOfSystemTime = asOf
+			p.extendedEvalCtx.SetTxnTimestamp(asOf.Timestamp.GoTime())
+			ex.state.setHistoricalTimestamp(ctx, asOf.Timestamp)
 		}
 	} else {
 		// If we're in an explicit txn, we allow AOST but only if it matches with
 		// the transaction's timestamp. This is useful for running AOST statements
 		// using the InternalExecutor inside an external transaction; one might want
 		// to do that to force p.avoidCachedDescriptors to be set below.
-		ts, err := p.isAsOf(ctx, ast)
+		asOf, err := p.isAsOf(ctx, ast)
 		if err != nil {
 			return makeErrEvent(err)
 		}
-		if ts != nil {
-			if readTs := ex.state.getReadTimestamp(); *ts != readTs {
+		if asOf != nil {
+			if asOf.BoundedStaleness {
+				return makeErrEvent(pgerror.Newf(pgcode.FeatureNotSupported,
+					"cannot use a bounded staleness query in an explicit transaction"))
+			}
+			if readTs := ex.state.getReadTimestamp(); asOf.Timestamp != readTs {
 				err = pgerror.Newf(pgcode.Syntax,
 					"inconsistent AS OF SYSTEM TIME timestamp; expected: %s", readTs)
 				err = errors.WithHint(err, "try SET TRANSACTION AS OF SYSTEM TIME")
 				return makeErrEvent(err)
 			}
-			p.semaCtx.AsOfTimestamp = ts
+			p.semaCtx.AsOfSystemTime = asOf
 		}
 	}
 
@@ -622,6 +628,25 @@ func (ex *connExecutor) execStmtInOpenState(
 		return makeErrEvent(err)
 	}
 
+	// Transactions running under READ COMMITTED isolation read from a new
+	// snapshot in each statement, so that they observe all writes committed
+	// before the statement began.
+	if err := ex.state.mu.txn.StepReadTimestamp(ctx); err != nil {
+		return makeErrEvent(err)
+	}
+
+	// Locking reads acquire replicated locks if the session asks for them and
+	// all nodes know about the replicated lock keyspace. Internal executors
+	// share the txn of their caller, so they leave the durability alone.
+	if ex.executorType != executorTypeInternal {
+		lockDurability := lock.Unreplicated
+		if ex.sessionData.ReplicatedLocking &&
+			ex.server.cfg.Settings.Version.IsActive(ctx, clusterversion.ReplicatedLocks) {
+			lockDurability = lock.Replicated
+		}
+		ex.state.mu.txn.SetLockingDurability(lockDurability)
+	}
+
 	if err := p.semaCtx.Placeholders.Assign(pinfo, stmt.NumPlaceholders); err != nil {
 		return makeErrEvent(err)
 	}
@@ -639,7 +664,9 @@ func (ex *connExecutor) execStmtInOpenState(
 		stmtThresholdSpan.SetVerbose(true)
 	}
 
-	if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
+	if err := ex.dispatchToExecutionEngineWithStmtRetries(
+		ctx, p, res, os.ImplicitTxn.Get(),
+	); err != nil {
 		stmtThresholdSpan.Finish()
 		return nil, nil, err
 	}
@@ -688,6 +715,95 @@ func (ex *connExecutor) execStmtInOpenState(
 	return nil, nil, nil
 }
 
+// maxReadCommittedStmtRetries is the number of times a statement of a READ
+// COMMITTED transaction is retried at a new read snapshot after conflicting
+// with a concurrent write, before the conflict is surfaced to the client as a
+// transaction retry error.
+const maxReadCommittedStmtRetries = 10
+
+// dispatchToExecutionEngineWithStmtRetries is like dispatchToExecutionEngine,
+// but it also retries the statement if it belongs to an explicit transaction
+// that reads from a new snapshot in each statement (i.e. READ COMMITTED) and
+// fails with a retryable error. Such an error means that the statement
+// conflicted with a concurrent write; for example, it wrote over a value that
+// was committed after its read snapshot was established, which would lose
+// that update. The KV layer defers the restart of such transactions, so the
+// statement's writes can be discarded by rolling back to a savepoint taken
+// before it ran and the statement can be executed again at a new read
+// snapshot. This is only possible as long as none of the statement's results
+// have been delivered to the client. If the statement isn't retried, the
+// retryable error is handled like in any other transaction.
+func (ex *connExecutor) dispatchToExecutionEngineWithStmtRetries(
+	ctx context.Context, p *planner, res RestrictedCommandResult, implicitTxn bool,
+) error {
+	txn := ex.state.mu.txn
+	if implicitTxn || ex.executorType == executorTypeInternal ||
+		!txn.IsolationLevel().ToleratesWriteSkew() {
+		// Implicit transactions are retried in their entirety.
+		return ex.dispatchToExecutionEngine(ctx, p, res)
+	}
+	_, pos, err := ex.stmtBuf.CurCmd()
+	if err != nil {
+		return err
+	}
+	savepoint, err := txn.CreateSavepoint(ctx)
+	if err != nil {
+		res.SetError(err)
+		return nil
+	}
+	for retries := 0; ; retries++ {
+		if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
+			return err
+		}
+		if !errIsRetriable(res.Err()) || retries == maxReadCommittedStmtRetries {
+			return nil
+		}
+		if !ex.prepareStmtForRetry(ctx, p, pos, savepoint, res) {
+			return nil
+		}
+	}
+}
+
+// prepareStmtForRetry discards the effects of a statement that failed with a
+// retryable error and establishes a new read snapshot for it. It returns false
+// if the statement cannot be retried, in which case the result is left
+// untouched.
+func (ex *connExecutor) prepareStmtForRetry(
+	ctx context.Context,
+	p *planner,
+	pos CmdPos,
+	savepoint kv.SavepointToken,
+	res RestrictedCommandResult,
+) bool {
+	cl := ex.clientComm.LockCommunication()
+	defer cl.Close()
+	if cl.ClientPos() >= pos {
+		// Some of the statement's results were already delivered to the client.
+		return false
+	}
+	txn := ex.state.mu.txn
+	if err := txn.RollbackToSavepoint(ctx, savepoint); err != nil {
+		log.VEventf(ctx, 2, "cannot retry statement: %v", err)
+		return false
+	}
+	log.VEventf(ctx, 2, "retrying statement at a new read snapshot after %v", res.Err())
+	cl.RTrim(ctx, pos)
+	res.ResetForStmtRetry()
+	// The execution of the statement points the evaluation context at state
+	// owned by its flow (see SetupLocalSyncFlow), which has been cleaned up.
+	p.extendedEvalCtx.Mon = ex.state.mon
+	p.extendedEvalCtx.IVarContainer = nil
+	if err := txn.Step(ctx); err != nil {
+		res.SetError(err)
+		return false
+	}
+	if err := txn.StepReadTimestamp(ctx); err != nil {
+		res.SetError(err)
+		return false
+	}
+	return true
+}
+
 func (ex *connExecutor) checkDescriptorTwoVersionInvariant(ctx context.Context) error {
 	var inRetryBackoff func()
 	if knobs := ex.server.cfg.SchemaChangerTestingKnobs; knobs != nil {
@@ -920,6 +1036,12 @@ func (ex *connExecutor) makeExecPlan(ctx context.Context, planner *planner) erro
 		return err
 	}
 
+	if asOf := planner.semaCtx.AsOfSystemTime; asOf != nil && asOf.BoundedStaleness {
+		if err := ex.negotiateBoundedStalenessTimestamp(ctx, planner); err != nil {
+			return err
+		}
+	}
+
 	flags := planner.curPlan.flags
 
 	if flags.IsSet(planFlagContainsFullIndexScan) || flags.IsSet(planFlagContainsFullTableScan) {
@@ -952,6 +1074,9 @@ type topLevelQueryStats struct {
 	bytesRead int64
 	// rowsRead is the number of rows read from disk.
 	rowsRead int64
+	// peakMemUsage is the sum of the peak memory usage of the flows on all
+	// nodes.
+	peakMemUsage int64
 }
 
 // execWithDistSQLEngine converts a plan to a distributed SQL physical plan and
@@ -1069,10 +1194,11 @@ func (ex *connExecutor) beginTransactionTimestampsAndReadMode(
 	ex.statsCollector.reset(&ex.server.sqlStats, ex.appStats, &ex.phaseTimes)
 	p := &ex.planner
 	ex.resetPlanner(ctx, p, nil /* txn */, now)
-	ts, err := p.EvalAsOfTimestamp(ctx, asOf)
+	asOfRet, err := p.EvalAsOfTimestamp(ctx, asOf)
 	if err != nil {
 		return 0, time.Time{}, nil, err
 	}
+	ts := asOfRet.Timestamp
 	// NB: This check should never return an error because the parser should
 	// disallow the creation of a TransactionModes struct which both has an
 	// AOST clause and is ReadWrite but performing a check decouples this code
@@ -1111,9 +1237,11 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventTxnStart{ImplicitTxn: fsm.False},
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(s.Modes.UserPriority),
+				ex.txnIsolationLevelWithSessionDefault(ctx, s.Modes.Isolation),
 				mode,
 				sqlTs,
 				historicalTs,
+				ex.txnRemoteParent(),
 				ex.transitionCtx)
 	case *tree.CommitTransaction, *tree.ReleaseSavepoint,
 		*tree.RollbackTransaction, *tree.SetTransaction, *tree.Savepoint:
@@ -1131,9 +1259,11 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventTxnStart{ImplicitTxn: fsm.True},
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(tree.UnspecifiedUserPriority),
+				ex.txnIsolationLevelWithSessionDefault(ctx, tree.UnspecifiedIsolation),
 				mode,
 				sqlTs,
 				historicalTs,
+				ex.txnRemoteParent(),
 				ex.transitionCtx)
 	}
 }
@@ -141,6 +141,7 @@ func (ex *connExecutor) execRelease(
 			// cockroach_restart (that's the whole point of commitOnRelease).
 			env.push(*entry)
 
+			ex.restartTxnIfDeferred()
 			rc, canAutoRetry := ex.getRewindTxnCapability()
 			ev := eventRetriableErr{
 				IsCommit:     fsm.FromBool(isCommit(s)),
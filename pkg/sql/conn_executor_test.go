@@ -38,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/rowexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
@@ -925,6 +926,70 @@ func TestShowLastQueryStatistics(t *testing.T) {
 	}
 }
 
+// TestReadCommittedStmtRetry verifies that a statement of a READ COMMITTED
+// transaction that conflicts with a write committed after the statement's read
+// snapshot was established is retried at a new read snapshot, without losing
+// the concurrent update and without restarting the transaction.
+func TestReadCommittedStmtRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	filter := newDynamicRequestFilter()
+	s, rawDB, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Store: &kvserver.StoreTestingKnobs{
+				TestingRequestFilter: filter.filter,
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(rawDB)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.txn.read_committed_isolation.enabled = true`)
+	sqlDB.Exec(t, `CREATE DATABASE t`)
+	sqlDB.Exec(t, `CREATE TABLE t.kv (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `CREATE TABLE t.log (k INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `INSERT INTO t.kv VALUES (1, 0)`)
+	var tableID uint32
+	sqlDB.QueryRow(t, `SELECT 't.kv'::REGCLASS::OID`).Scan(&tableID)
+	tableKey := keys.SystemSQLCodec.TablePrefix(tableID)
+	tableSpan := roachpb.Span{Key: tableKey, EndKey: tableKey.PrefixEnd()}
+
+	// The first time the READ COMMITTED transaction scans the table, commit a
+	// concurrent update to the row it is about to update.
+	var injected int32
+	injectErrCh := make(chan error, 1)
+	filter.setFilter(func(ctx context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+		if ba.Txn == nil || ba.Txn.IsoLevel != enginepb.READ_COMMITTED {
+			return nil
+		}
+		for _, ru := range ba.Requests {
+			scan, ok := ru.GetInner().(*roachpb.ScanRequest)
+			if ok && tableSpan.ContainsKey(scan.Key) && atomic.CompareAndSwapInt32(&injected, 0, 1) {
+				_, err := rawDB.Exec(`UPDATE t.kv SET v = v + 1 WHERE k = 1`)
+				injectErrCh <- err
+			}
+		}
+		return nil
+	})
+	defer filter.setFilter(nil)
+
+	tx, err := rawDB.BeginTx(ctx, &gosql.TxOptions{Isolation: gosql.LevelReadCommitted})
+	require.NoError(t, err)
+	_, err = tx.Exec(`INSERT INTO t.log VALUES (1)`)
+	require.NoError(t, err)
+	_, err = tx.Exec(`UPDATE t.kv SET v = v + 10 WHERE k = 1`)
+	require.NoError(t, err)
+	require.NoError(t, <-injectErrCh)
+	require.NoError(t, tx.Commit())
+
+	// Both updates were applied, and so was the write performed by the
+	// transaction before the retried statement.
+	sqlDB.CheckQueryResults(t, `SELECT v FROM t.kv`, [][]string{{"11"}})
+	sqlDB.CheckQueryResults(t, `SELECT k FROM t.log`, [][]string{{"1"}})
+}
+
 // dynamicRequestFilter exposes a filter method which is a
 // kvserverbase.ReplicaRequestFilter but can be set dynamically.
 type dynamicRequestFilter struct {
@@ -19,6 +19,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgrepl"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -331,6 +332,28 @@ func (CopyIn) String() string {
 
 var _ Command = CopyIn{}
 
+// StartReplication is the command for execution of the START_REPLICATION
+// replication command, which streams the changes to the tables of a set of
+// publications to the client using the CopyBoth pgwire subprotocol.
+type StartReplication struct {
+	Stmt *tree.StartReplication
+	// Conn is the network connection. Execution of the StartReplication
+	// command takes control of the connection for writing, while the network
+	// routine keeps reading the client's status updates and passes them on
+	// through Feedback.
+	Conn     pgwirebase.ReplicationConn
+	Feedback *pgrepl.Feedback
+}
+
+// command implements the Command interface.
+func (StartReplication) command() string { return "start replication" }
+
+func (StartReplication) String() string {
+	return "StartReplication"
+}
+
+var _ Command = StartReplication{}
+
 // DrainRequest represents a notice that the server is draining and command
 // processing should stop soon.
 //
@@ -616,6 +639,9 @@ type ClientComm interface {
 	CreateEmptyQueryResult(pos CmdPos) EmptyQueryResult
 	// CreateCopyInResult creates a result for a Copy-in command.
 	CreateCopyInResult(pos CmdPos) CopyInResult
+	// CreateStartReplicationResult creates a result for a StartReplication
+	// command.
+	CreateStartReplicationResult(pos CmdPos) StartReplicationResult
 	// CreateDrainResult creates a result for a Drain command.
 	CreateDrainResult(pos CmdPos) DrainResult
 
@@ -709,6 +735,12 @@ type RestrictedCommandResult interface {
 	// ClientComm.createStatementResult.
 	ResetStmtType(stmt tree.Statement)
 
+	// ResetForStmtRetry discards the rows, the rows affected count, the error
+	// and the buffered notices accumulated so far, so that the statement can be
+	// executed again. Output that was already handed to the ClientComm must be
+	// trimmed separately through ClientLock.RTrim.
+	ResetForStmtRetry()
+
 	// AddRow accumulates a result row.
 	//
 	// The implementation cannot hold on to the row slice; it needs to make a
@@ -799,6 +831,12 @@ type CopyInResult interface {
 	ResultBase
 }
 
+// StartReplicationResult represents the result of a StartReplication command.
+// Closing this result produces no output for the client.
+type StartReplicationResult interface {
+	ResultBase
+}
+
 // ClientLock is an interface returned by ClientComm.lockCommunication(). It
 // represents a lock on the delivery of results to a SQL client. While such a
 // lock is used, no more results are delivered. The lock itself can be used to
@@ -900,6 +938,13 @@ func (r *bufferedCommandResult) ResetStmtType(stmt tree.Statement) {
 	panic("unimplemented")
 }
 
+// ResetForStmtRetry is part of the RestrictedCommandResult interface.
+func (r *bufferedCommandResult) ResetForStmtRetry() {
+	r.err = nil
+	r.rows = nil
+	r.rowsAffected = 0
+}
+
 // AddRow is part of the RestrictedCommandResult interface.
 func (r *bufferedCommandResult) AddRow(ctx context.Context, row tree.Datums) error {
 	if r.errOnly {
@@ -0,0 +1,256 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package contention
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// historyEnabled determines whether the contention events between
+// transactions are recorded in the contention history.
+var historyEnabled = settings.RegisterBoolSetting(
+	"sql.contention.history.enabled",
+	"if set, the contention events between transactions are recorded in system.transaction_contention_events",
+	true,
+).WithPublic()
+
+const (
+	// maxBufferedEvents is the maximum number of contention events a History
+	// buffers until they are flushed. The oldest events are dropped beyond it.
+	maxBufferedEvents = 1024
+	// numTxnFingerprintsShards is the number of shards of the statement
+	// fingerprints of recent transactions remembered by a History, which lets
+	// concurrent statements record theirs without contending with each other.
+	numTxnFingerprintsShards = 16
+	// txnFingerprintsRetention is the minimum amount of time for which the
+	// statement fingerprints of a transaction are remembered after its last
+	// statement, unless more than maxTxnFingerprintsPerShard transactions
+	// executed in the meantime.
+	txnFingerprintsRetention = 5 * time.Minute
+	// maxTxnFingerprintsPerShard bounds the number of transactions whose
+	// statement fingerprints each generation of a shard holds.
+	maxTxnFingerprintsPerShard = 8192
+	// maxFingerprintsPerTxn is the maximum number of distinct statement
+	// fingerprints remembered for a transaction.
+	maxFingerprintsPerTxn = 16
+	// remoteResolutionInterval is the minimum interval between two
+	// resolutions of the fingerprints of transactions which executed on other
+	// nodes, since each of them fans out to all the nodes of the cluster. It
+	// is well below txnFingerprintsRetention.
+	remoteResolutionInterval = time.Minute
+	// maxTxnIDsPerResolution is the maximum number of transactions whose
+	// fingerprints are resolved by a single call to the TxnResolver.
+	maxTxnIDsPerResolution = 512
+)
+
+// An Event is a contention event recorded by a History: a transaction waited
+// on a lock held by another, blocking, transaction.
+type Event struct {
+	// Timestamp is the time at which the waiting transaction started waiting.
+	Timestamp time.Time
+	// WaitingTxnID is the ID of the transaction which waited. It is the zero
+	// UUID if the request which waited was not transactional.
+	WaitingTxnID uuid.UUID
+	// BlockingTxnID is the ID of the transaction which held the lock.
+	BlockingTxnID uuid.UUID
+	// Key is the key of the lock.
+	Key roachpb.Key
+	// Duration is the amount of time the waiting transaction waited.
+	Duration time.Duration
+}
+
+// A TxnResolver resolves the statement fingerprints of transactions which
+// executed anywhere in the cluster.
+type TxnResolver interface {
+	// ResolveTxnFingerprints returns the statement fingerprints of those of
+	// the given transactions which are known.
+	ResolveTxnFingerprints(ctx context.Context, txnIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+}
+
+// History maintains the history of the contention events between
+// transactions, unlike the Registry which only aggregates them by key.
+//
+// Contention events are observed on the KV nodes which hold the contended
+// locks, whereas the statements of the transactions involved are known to
+// the gateway nodes which execute them. Each node thus both records the
+// contention events of the requests which waited on its stores, and remembers
+// the statement fingerprints of the transactions it recently executed. The
+// recorded events are periodically flushed to the
+// system.transaction_contention_events table, after resolving the
+// fingerprints of the waiting and blocking transactions. Those which executed
+// on this node are resolved locally. Those which did not are resolved across
+// the cluster at most every remoteResolutionInterval, until which the events
+// involving them are held back.
+type History struct {
+	st       *cluster.Settings
+	db       *kv.DB
+	ie       sqlutil.InternalExecutor
+	nodeID   *base.NodeIDContainer
+	resolver TxnResolver
+
+	mu struct {
+		syncutil.Mutex
+		// events are the contention events recorded since the last successful
+		// flush, oldest first.
+		events []Event
+		// lastRemoteResolution is the time at which the fingerprints of
+		// transactions were last resolved across the cluster.
+		lastRemoteResolution time.Time
+	}
+
+	// txnFingerprints holds the statement fingerprints of the transactions
+	// recently executed on this node, sharded by transaction ID.
+	txnFingerprints [numTxnFingerprintsShards]txnFingerprintsShard
+}
+
+// txnFingerprintsShard holds the statement fingerprints of a subset of the
+// transactions recently executed on a node, in two generations: transactions
+// are recorded in the current generation, which replaces the previous one
+// once it is older than txnFingerprintsRetention or holds
+// maxTxnFingerprintsPerShard transactions.
+type txnFingerprintsShard struct {
+	syncutil.Mutex
+	cur, prev map[uuid.UUID][]string
+	// curStart is the time at which the current generation started.
+	curStart time.Time
+}
+
+func (s *txnFingerprintsShard) record(txnID uuid.UUID, fingerprint string, now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	if s.cur == nil || len(s.cur) >= maxTxnFingerprintsPerShard ||
+		now.Sub(s.curStart) > txnFingerprintsRetention {
+		s.prev, s.cur, s.curStart = s.cur, make(map[uuid.UUID][]string), now
+	}
+	fingerprints, ok := s.cur[txnID]
+	if !ok {
+		// Move the transaction to the current generation.
+		fingerprints = s.prev[txnID]
+		delete(s.prev, txnID)
+		s.cur[txnID] = fingerprints
+	}
+	if len(fingerprints) >= maxFingerprintsPerTxn {
+		return
+	}
+	for _, f := range fingerprints {
+		if f == fingerprint {
+			return
+		}
+	}
+	s.cur[txnID] = append(fingerprints, fingerprint)
+}
+
+func (s *txnFingerprintsShard) get(txnID uuid.UUID) ([]string, bool) {
+	s.Lock()
+	defer s.Unlock()
+	if fingerprints, ok := s.cur[txnID]; ok {
+		return fingerprints, true
+	}
+	fingerprints, ok := s.prev[txnID]
+	return fingerprints, ok
+}
+
+func (h *History) txnFingerprintsShard(txnID uuid.UUID) *txnFingerprintsShard {
+	return &h.txnFingerprints[txnID[0]%numTxnFingerprintsShards]
+}
+
+// NewHistory constructs a History for the node with the given ID, which
+// flushes to the system.transaction_contention_events table using the given
+// internal executor.
+func NewHistory(
+	st *cluster.Settings, db *kv.DB, ie sqlutil.InternalExecutor, nodeID *base.NodeIDContainer,
+) *History {
+	return &History{
+		st:     st,
+		db:     db,
+		ie:     ie,
+		nodeID: nodeID,
+	}
+}
+
+// OnContentionEvent records a contention event observed on a store of this
+// node. It implements the concurrency.ContentionEventListener interface.
+func (h *History) OnContentionEvent(waitingTxnID uuid.UUID, ev roachpb.ContentionEvent) {
+	if !historyEnabled.Get(&h.st.SV) {
+		return
+	}
+	e := Event{
+		Timestamp:     timeutil.Now().Add(-ev.Duration),
+		WaitingTxnID:  waitingTxnID,
+		BlockingTxnID: ev.TxnMeta.ID,
+		Key:           ev.Key,
+		Duration:      ev.Duration,
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.addEventsLocked(e)
+}
+
+// addEventsLocked appends the given events to the buffered ones, dropping the
+// oldest ones beyond maxBufferedEvents.
+func (h *History) addEventsLocked(events ...Event) {
+	h.mu.events = append(h.mu.events, events...)
+	if n := len(h.mu.events) - maxBufferedEvents; n > 0 {
+		h.mu.events = append(h.mu.events[:0], h.mu.events[n:]...)
+	}
+}
+
+// takeEvents returns the buffered events and clears them.
+func (h *History) takeEvents() []Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	events := h.mu.events
+	h.mu.events = nil
+	return events
+}
+
+// restoreEvents adds back the given events, taken by a flush which failed,
+// before the events recorded since.
+func (h *History) restoreEvents(events []Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recorded := h.mu.events
+	h.mu.events = events
+	h.addEventsLocked(recorded...)
+}
+
+// RecordStatement remembers that the statement with the given fingerprint was
+// executed by the given transaction on this node. It is a no-op on a nil
+// History.
+func (h *History) RecordStatement(txnID uuid.UUID, fingerprint string) {
+	if h == nil || !historyEnabled.Get(&h.st.SV) {
+		return
+	}
+	h.txnFingerprintsShard(txnID).record(txnID, fingerprint, timeutil.Now())
+}
+
+// TxnFingerprints returns the statement fingerprints of those of the given
+// transactions which recently executed on this node.
+func (h *History) TxnFingerprints(txnIDs []uuid.UUID) map[uuid.UUID][]string {
+	res := make(map[uuid.UUID][]string)
+	for _, id := range txnIDs {
+		if fingerprints, ok := h.txnFingerprintsShard(id).get(id); ok {
+			res[id] = fingerprints
+		}
+	}
+	return res
+}
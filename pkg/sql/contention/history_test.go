@@ -0,0 +1,204 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package contention
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryBuffersEvents(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	h := NewHistory(cluster.MakeTestingClusterSettings(), nil, nil, nil)
+	waitingTxnID := uuid.MakeV4()
+	record := func(i int) {
+		h.OnContentionEvent(waitingTxnID, roachpb.ContentionEvent{
+			Key:      roachpb.Key(fmt.Sprintf("key%d", i)),
+			TxnMeta:  enginepb.TxnMeta{ID: uuid.MakeV4()},
+			Duration: time.Duration(i+1) * time.Millisecond,
+		})
+	}
+
+	// The oldest events are dropped beyond maxBufferedEvents.
+	for i := 0; i < maxBufferedEvents+10; i++ {
+		record(i)
+	}
+	events := h.takeEvents()
+	require.Len(t, events, maxBufferedEvents)
+	require.Equal(t, roachpb.Key("key10"), events[0].Key)
+	require.Equal(t, waitingTxnID, events[0].WaitingTxnID)
+	require.Equal(t, 11*time.Millisecond, events[0].Duration)
+	require.Empty(t, h.takeEvents())
+
+	// The events of a failed flush are restored before those recorded since,
+	// and are the first to be dropped.
+	record(-1)
+	h.restoreEvents(events)
+	restored := h.takeEvents()
+	require.Len(t, restored, maxBufferedEvents)
+	require.Equal(t, roachpb.Key("key11"), restored[0].Key)
+	require.Equal(t, roachpb.Key("key-1"), restored[len(restored)-1].Key)
+
+	// No events are recorded if the history is disabled.
+	historyEnabled.Override(&h.st.SV, false)
+	record(0)
+	require.Empty(t, h.takeEvents())
+}
+
+func TestHistoryTxnFingerprints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// RecordStatement is a no-op on a nil History, as on tenants.
+	var nilHistory *History
+	nilHistory.RecordStatement(uuid.MakeV4(), "SELECT _")
+
+	h := NewHistory(cluster.MakeTestingClusterSettings(), nil, nil, nil)
+	txn1, txn2, txn3 := uuid.MakeV4(), uuid.MakeV4(), uuid.MakeV4()
+	h.RecordStatement(txn1, "SELECT _")
+	h.RecordStatement(txn1, "UPDATE t SET v = _")
+	h.RecordStatement(txn1, "SELECT _")
+	for i := 0; i < 2*maxFingerprintsPerTxn; i++ {
+		h.RecordStatement(txn2, fmt.Sprintf("SELECT %d", i))
+	}
+
+	res := h.TxnFingerprints([]uuid.UUID{txn1, txn2, txn3})
+	require.Len(t, res, 2)
+	require.Equal(t, []string{"SELECT _", "UPDATE t SET v = _"}, res[txn1])
+	require.Len(t, res[txn2], maxFingerprintsPerTxn)
+
+	// The fingerprints of the transactions involved in events are resolved
+	// locally, and those of the others are reported missing.
+	h.RecordStatement(txn3, "DELETE FROM t")
+	txn4 := uuid.MakeV4()
+	res, missing := h.resolveLocalFingerprints([]Event{
+		{WaitingTxnID: txn3, BlockingTxnID: txn1},
+		{BlockingTxnID: txn3},
+		{WaitingTxnID: txn4, BlockingTxnID: txn1},
+	})
+	require.Equal(t, map[uuid.UUID][]string{
+		txn1: {"SELECT _", "UPDATE t SET v = _"},
+		txn3: {"DELETE FROM t"},
+	}, res)
+	require.Equal(t, []uuid.UUID{txn4}, missing)
+}
+
+// fakeTxnResolver is a TxnResolver which knows the fingerprints of a fixed
+// set of transactions, and records the batches of transactions it is asked to
+// resolve.
+type fakeTxnResolver struct {
+	fingerprints map[uuid.UUID][]string
+	batches      [][]uuid.UUID
+}
+
+func (r *fakeTxnResolver) ResolveTxnFingerprints(
+	_ context.Context, txnIDs []uuid.UUID,
+) (map[uuid.UUID][]string, error) {
+	r.batches = append(r.batches, txnIDs)
+	res := make(map[uuid.UUID][]string)
+	for _, id := range txnIDs {
+		if f, ok := r.fingerprints[id]; ok {
+			res[id] = f
+		}
+	}
+	return res, nil
+}
+
+func TestHistoryRemoteFingerprints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	h := NewHistory(cluster.MakeTestingClusterSettings(), nil, nil, nil)
+	localTxn, remoteTxn := uuid.MakeV4(), uuid.MakeV4()
+	h.RecordStatement(localTxn, "SELECT _")
+	resolver := &fakeTxnResolver{fingerprints: map[uuid.UUID][]string{remoteTxn: {"UPDATE t SET v = _"}}}
+	h.resolver = resolver
+
+	// The transactions are resolved across the cluster in batches.
+	txnIDs := make([]uuid.UUID, maxTxnIDsPerResolution+1)
+	for i := range txnIDs {
+		txnIDs[i] = uuid.MakeV4()
+	}
+	txnIDs[len(txnIDs)-1] = remoteTxn
+	fingerprints := make(map[uuid.UUID][]string)
+	h.resolveRemoteFingerprints(ctx, txnIDs, fingerprints)
+	require.Equal(t, map[uuid.UUID][]string{remoteTxn: {"UPDATE t SET v = _"}}, fingerprints)
+	require.Len(t, resolver.batches, 2)
+	require.Len(t, resolver.batches[0], maxTxnIDsPerResolution)
+	require.Equal(t, []uuid.UUID{remoteTxn}, resolver.batches[1])
+
+	// They are resolved at most every remoteResolutionInterval, unless the
+	// events take up half of the buffer.
+	require.True(t, h.remoteResolutionDue(1))
+	require.False(t, h.remoteResolutionDue(1))
+	require.True(t, h.remoteResolutionDue(maxBufferedEvents/2))
+	h.mu.lastRemoteResolution = h.mu.lastRemoteResolution.Add(-remoteResolutionInterval)
+	require.True(t, h.remoteResolutionDue(1))
+
+	// Until then, the events which involve transactions which did not execute
+	// on this node are held back.
+	events := []Event{
+		{Key: roachpb.Key("a"), WaitingTxnID: localTxn, BlockingTxnID: remoteTxn},
+		{Key: roachpb.Key("b"), BlockingTxnID: localTxn},
+		{Key: roachpb.Key("c"), WaitingTxnID: remoteTxn, BlockingTxnID: localTxn},
+	}
+	fingerprints, missing := h.resolveLocalFingerprints(events)
+	require.Equal(t, []uuid.UUID{remoteTxn}, missing)
+	resolved, unresolved := splitResolvedEvents(events, fingerprints)
+	require.Equal(t, []Event{events[1]}, resolved)
+	require.Equal(t, []Event{events[0], events[2]}, unresolved)
+}
+
+func TestTxnFingerprintsShardRetention(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var s txnFingerprintsShard
+	now := timeutil.Unix(0, 0)
+	txn1, txn2 := uuid.MakeV4(), uuid.MakeV4()
+	s.record(txn1, "SELECT _", now)
+	s.record(txn2, "SELECT _", now)
+
+	// Transactions are remembered for at least txnFingerprintsRetention, and
+	// those which execute statements in the meantime for longer.
+	now = now.Add(txnFingerprintsRetention + time.Second)
+	s.record(txn1, "UPDATE t SET v = _", now)
+	fingerprints, ok := s.get(txn1)
+	require.True(t, ok)
+	require.Equal(t, []string{"SELECT _", "UPDATE t SET v = _"}, fingerprints)
+	_, ok = s.get(txn2)
+	require.True(t, ok)
+
+	now = now.Add(txnFingerprintsRetention + time.Second)
+	s.record(uuid.MakeV4(), "SELECT _", now)
+	_, ok = s.get(txn1)
+	require.True(t, ok)
+	_, ok = s.get(txn2)
+	require.False(t, ok)
+
+	// Transactions are forgotten sooner if more than
+	// maxTxnFingerprintsPerShard execute in the meantime.
+	for i := 0; i < 2*maxTxnFingerprintsPerShard; i++ {
+		s.record(uuid.MakeV4(), "SELECT _", now)
+	}
+	_, ok = s.get(txn1)
+	require.False(t, ok)
+	require.LessOrEqual(t, len(s.cur)+len(s.prev), 2*maxTxnFingerprintsPerShard)
+}
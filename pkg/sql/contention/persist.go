@@ -0,0 +1,394 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package contention
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// flushInterval is the interval at which the recorded contention events are
+// flushed to the system.transaction_contention_events table.
+var flushInterval = settings.RegisterDurationSetting(
+	"sql.contention.history.flush_interval",
+	"the interval at which the contention events between transactions are flushed to system.transaction_contention_events",
+	10*time.Second,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+)
+
+// retention is how long the persisted contention events are kept.
+var retention = settings.RegisterDurationSetting(
+	"sql.contention.history.retention",
+	"the amount of time for which the contention events between transactions are retained in system.transaction_contention_events",
+	7*24*time.Hour,
+	settings.NonNegativeDuration,
+).WithPublic()
+
+// maxRows is the number of persisted contention events beyond which the
+// oldest ones are deleted.
+var maxRows = settings.RegisterIntSetting(
+	"sql.contention.history.max_rows",
+	"the maximum number of contention events between transactions retained in system.transaction_contention_events",
+	100000,
+	settings.PositiveInt,
+).WithPublic()
+
+const (
+	// insertBatchSize is the number of rows inserted per statement.
+	insertBatchSize = 128
+	// deleteBatchSize is the number of expired rows deleted per statement.
+	deleteBatchSize = 1024
+)
+
+// Start spawns a loop that periodically flushes the recorded contention
+// events. The fingerprints of the transactions involved in the events are
+// resolved using the given TxnResolver.
+func (h *History) Start(ctx context.Context, stopper *stop.Stopper, resolver TxnResolver) {
+	h.resolver = resolver
+	_ = stopper.RunAsyncTask(ctx, "contention-history-flusher", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(flushInterval.Get(&h.st.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if err := h.Flush(ctx); err != nil {
+				log.Warningf(ctx, "failed to flush contention history: %v", err)
+			}
+		}
+	})
+}
+
+// Flush persists the contention events recorded since the last flush. If the
+// events cannot be persisted, they are retained for the next flush. The
+// fingerprints of transactions which cannot be resolved are left NULL.
+//
+// The events involving transactions which did not execute on this node are
+// held back until their fingerprints are resolved across the cluster, which
+// happens at most every remoteResolutionInterval, unless they take up half of
+// the buffered events.
+func (h *History) Flush(ctx context.Context) error {
+	if !h.st.Version.IsActive(ctx, clusterversion.TransactionContentionEventsTable) {
+		return nil
+	}
+	events := h.takeEvents()
+	if len(events) == 0 {
+		return nil
+	}
+	toPersist := events
+	fingerprints, missing := h.resolveLocalFingerprints(events)
+	var heldBack []Event
+	if len(missing) > 0 && h.resolver != nil {
+		if h.remoteResolutionDue(len(events)) {
+			h.resolveRemoteFingerprints(ctx, missing, fingerprints)
+		} else {
+			toPersist, heldBack = splitResolvedEvents(events, fingerprints)
+		}
+	}
+	if len(toPersist) > 0 {
+		if err := h.persist(ctx, toPersist, fingerprints); err != nil {
+			h.restoreEvents(events)
+			return err
+		}
+	}
+	if len(heldBack) > 0 {
+		h.restoreEvents(heldBack)
+	}
+	return nil
+}
+
+// resolveLocalFingerprints returns the statement fingerprints of the
+// transactions involved in the given events which executed on this node, as
+// well as the IDs of those which did not.
+func (h *History) resolveLocalFingerprints(
+	events []Event,
+) (fingerprints map[uuid.UUID][]string, missing []uuid.UUID) {
+	seen := make(map[uuid.UUID]struct{})
+	var txnIDs []uuid.UUID
+	for i := range events {
+		for _, id := range []uuid.UUID{events[i].WaitingTxnID, events[i].BlockingTxnID} {
+			if _, ok := seen[id]; ok || id == (uuid.UUID{}) {
+				continue
+			}
+			seen[id] = struct{}{}
+			txnIDs = append(txnIDs, id)
+		}
+	}
+	fingerprints = h.TxnFingerprints(txnIDs)
+	for _, id := range txnIDs {
+		if _, ok := fingerprints[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return fingerprints, missing
+}
+
+// remoteResolutionDue returns whether the fingerprints of transactions should
+// be resolved across the cluster for a flush of the given number of events,
+// and if so, records that they are.
+func (h *History) remoteResolutionDue(numEvents int) bool {
+	now := timeutil.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if numEvents < maxBufferedEvents/2 &&
+		now.Sub(h.mu.lastRemoteResolution) < remoteResolutionInterval {
+		return false
+	}
+	h.mu.lastRemoteResolution = now
+	return true
+}
+
+// resolveRemoteFingerprints resolves the statement fingerprints of the given
+// transactions across the cluster, in batches of at most
+// maxTxnIDsPerResolution transactions, and adds them to fingerprints.
+func (h *History) resolveRemoteFingerprints(
+	ctx context.Context, txnIDs []uuid.UUID, fingerprints map[uuid.UUID][]string,
+) {
+	for start := 0; start < len(txnIDs); start += maxTxnIDsPerResolution {
+		batch := txnIDs[start:]
+		if len(batch) > maxTxnIDsPerResolution {
+			batch = batch[:maxTxnIDsPerResolution]
+		}
+		res, err := h.resolver.ResolveTxnFingerprints(ctx, batch)
+		if err != nil {
+			log.Warningf(ctx, "failed to resolve the transactions involved in contention events: %v", err)
+			return
+		}
+		for id, f := range res {
+			fingerprints[id] = f
+		}
+	}
+}
+
+// splitResolvedEvents splits the given events into those whose transactions
+// all have resolved fingerprints, and the others.
+func splitResolvedEvents(
+	events []Event, fingerprints map[uuid.UUID][]string,
+) (resolved, unresolved []Event) {
+	isResolved := func(id uuid.UUID) bool {
+		_, ok := fingerprints[id]
+		return ok || id == (uuid.UUID{})
+	}
+	for _, e := range events {
+		if isResolved(e.WaitingTxnID) && isResolved(e.BlockingTxnID) {
+			resolved = append(resolved, e)
+		} else {
+			unresolved = append(unresolved, e)
+		}
+	}
+	return resolved, unresolved
+}
+
+func (h *History) persist(
+	ctx context.Context, events []Event, fingerprints map[uuid.UUID][]string,
+) error {
+	nodeID := h.nodeID.Get()
+	fingerprintsDatum := func(txnID uuid.UUID) (tree.Datum, error) {
+		f, ok := fingerprints[txnID]
+		if !ok {
+			return tree.DNull, nil
+		}
+		arr := tree.NewDArray(types.String)
+		for _, s := range f {
+			if err := arr.Append(tree.NewDString(s)); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	}
+	const numColumns = 8
+	var query strings.Builder
+	args := make([]interface{}, 0, numColumns*insertBatchSize)
+	return h.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		for start := 0; start < len(events); start += insertBatchSize {
+			batch := events[start:]
+			if len(batch) > insertBatchSize {
+				batch = batch[:insertBatchSize]
+			}
+			query.Reset()
+			query.WriteString(`INSERT INTO system.transaction_contention_events (
+  ts, node_id, key, duration, waiting_txn_id, waiting_txn_fingerprints,
+  blocking_txn_id, blocking_txn_fingerprints
+) VALUES `)
+			args = args[:0]
+			for i := range batch {
+				e := &batch[i]
+				var waitingTxnID tree.Datum = tree.DNull
+				if e.WaitingTxnID != (uuid.UUID{}) {
+					waitingTxnID = tree.NewDUuid(tree.DUuid{UUID: e.WaitingTxnID})
+				}
+				waitingFingerprints, err := fingerprintsDatum(e.WaitingTxnID)
+				if err != nil {
+					return err
+				}
+				blockingFingerprints, err := fingerprintsDatum(e.BlockingTxnID)
+				if err != nil {
+					return err
+				}
+				if i > 0 {
+					query.WriteString(", ")
+				}
+				query.WriteString("(")
+				for j := 1; j <= numColumns; j++ {
+					if j > 1 {
+						query.WriteString(", ")
+					}
+					fmt.Fprintf(&query, "$%d", len(args)+j)
+				}
+				query.WriteString(")")
+				args = append(args,
+					e.Timestamp, nodeID, []byte(e.Key), e.Duration, waitingTxnID, waitingFingerprints,
+					tree.NewDUuid(tree.DUuid{UUID: e.BlockingTxnID}), blockingFingerprints,
+				)
+			}
+			if _, err := h.ie.ExecEx(ctx, "write-contention-events", txn,
+				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+				query.String(), args...,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteExpired deletes the persisted contention events which started before
+// the retention period, as well as the oldest ones beyond the maximum number
+// of rows. As it scans the table, it is meant to be called infrequently, and
+// by a single node of the cluster.
+func (h *History) DeleteExpired(ctx context.Context) error {
+	if !h.st.Version.IsActive(ctx, clusterversion.TransactionContentionEventsTable) {
+		return nil
+	}
+	cutoff := timeutil.Now().Add(-retention.Get(&h.st.SV))
+	row, err := h.ie.QueryRowEx(ctx, "read-contention-events-cutoff", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT ts FROM system.transaction_contention_events ORDER BY ts DESC LIMIT 1 OFFSET $1`,
+		maxRows.Get(&h.st.SV)-1,
+	)
+	if err != nil {
+		return err
+	}
+	if row != nil {
+		if ts := tree.MustBeDTimestampTZ(row[0]).Time; ts.After(cutoff) {
+			cutoff = ts
+		}
+	}
+	for {
+		n, err := h.ie.ExecEx(ctx, "delete-expired-contention-events", nil, /* txn */
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			fmt.Sprintf(`DELETE FROM system.transaction_contention_events WHERE ts < $1 LIMIT %d`,
+				deleteBatchSize),
+			cutoff,
+		)
+		if err != nil {
+			return err
+		}
+		if n < deleteBatchSize {
+			return nil
+		}
+	}
+}
+
+// PersistedEvent is a contention event read from the
+// system.transaction_contention_events table.
+type PersistedEvent struct {
+	Event
+	// NodeID is the node on which the event was recorded.
+	NodeID roachpb.NodeID
+	// WaitingTxnFingerprints and BlockingTxnFingerprints are the statement
+	// fingerprints of the waiting and blocking transactions, if they were
+	// resolved.
+	WaitingTxnFingerprints  []string
+	BlockingTxnFingerprints []string
+}
+
+// Read returns the most recent persisted contention events which started in
+// [start, end), ordered from the most recent. A zero end means no upper bound,
+// and a non-positive limit means no limit.
+func Read(
+	ctx context.Context, ie sqlutil.InternalExecutor, txn *kv.Txn, start, end time.Time, limit int,
+) ([]PersistedEvent, error) {
+	query := `SELECT ts, node_id, key, duration, waiting_txn_id, waiting_txn_fingerprints,
+  blocking_txn_id, blocking_txn_fingerprints
+FROM system.transaction_contention_events WHERE ts >= $1`
+	args := []interface{}{start}
+	if !end.IsZero() {
+		args = append(args, end)
+		query += fmt.Sprintf(` AND ts < $%d`, len(args))
+	}
+	query += ` ORDER BY ts DESC, event_id DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, limit)
+	}
+	rows, err := ie.QueryEx(ctx, "read-contention-events", txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()}, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]PersistedEvent, len(rows))
+	for i, row := range rows {
+		e := &res[i]
+		e.Timestamp = tree.MustBeDTimestampTZ(row[0]).Time
+		e.NodeID = roachpb.NodeID(tree.MustBeDInt(row[1]))
+		e.Key = roachpb.Key(tree.MustBeDBytes(row[2]))
+		e.Duration = time.Duration(tree.MustBeDInterval(row[3]).Duration.Nanos())
+		if row[4] != tree.DNull {
+			e.WaitingTxnID = row[4].(*tree.DUuid).UUID
+		}
+		e.WaitingTxnFingerprints = stringsFromDatum(row[5])
+		e.BlockingTxnID = row[6].(*tree.DUuid).UUID
+		e.BlockingTxnFingerprints = stringsFromDatum(row[7])
+	}
+	return res, nil
+}
+
+// stringsFromDatum returns the strings of the given STRING[] datum, or nil if
+// it is NULL.
+func stringsFromDatum(d tree.Datum) []string {
+	if d == tree.DNull {
+		return nil
+	}
+	arr := tree.MustBeDArray(d)
+	res := make([]string, len(arr.Array))
+	for i, s := range arr.Array {
+		res[i] = string(tree.MustBeDString(s))
+	}
+	return res
+}
@@ -13,6 +13,7 @@ package sql
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/url"
@@ -32,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/liveness/livenesspb"
+	"github.com/cockroachdb/cockroach/pkg/multitenant/tenantusage"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
@@ -49,9 +51,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/protoreflect"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
@@ -65,6 +69,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 )
 
@@ -85,50 +90,55 @@ const CrdbInternalName = sessiondata.CRDBInternalSchemaName
 var crdbInternal = virtualSchema{
 	name: CrdbInternalName,
 	tableDefs: map[descpb.ID]virtualSchemaDef{
-		catconstants.CrdbInternalBackwardDependenciesTableID:      crdbInternalBackwardDependenciesTable,
-		catconstants.CrdbInternalBuildInfoTableID:                 crdbInternalBuildInfoTable,
-		catconstants.CrdbInternalBuiltinFunctionsTableID:          crdbInternalBuiltinFunctionsTable,
-		catconstants.CrdbInternalClusterQueriesTableID:            crdbInternalClusterQueriesTable,
-		catconstants.CrdbInternalClusterTransactionsTableID:       crdbInternalClusterTxnsTable,
-		catconstants.CrdbInternalClusterSessionsTableID:           crdbInternalClusterSessionsTable,
-		catconstants.CrdbInternalClusterSettingsTableID:           crdbInternalClusterSettingsTable,
-		catconstants.CrdbInternalCreateStmtsTableID:               crdbInternalCreateStmtsTable,
-		catconstants.CrdbInternalCreateTypeStmtsTableID:           crdbInternalCreateTypeStmtsTable,
-		catconstants.CrdbInternalDatabasesTableID:                 crdbInternalDatabasesTable,
-		catconstants.CrdbInternalFeatureUsageID:                   crdbInternalFeatureUsage,
-		catconstants.CrdbInternalForwardDependenciesTableID:       crdbInternalForwardDependenciesTable,
-		catconstants.CrdbInternalGossipNodesTableID:               crdbInternalGossipNodesTable,
-		catconstants.CrdbInternalGossipAlertsTableID:              crdbInternalGossipAlertsTable,
-		catconstants.CrdbInternalGossipLivenessTableID:            crdbInternalGossipLivenessTable,
-		catconstants.CrdbInternalGossipNetworkTableID:             crdbInternalGossipNetworkTable,
-		catconstants.CrdbInternalIndexColumnsTableID:              crdbInternalIndexColumnsTable,
-		catconstants.CrdbInternalInflightTraceSpanTableID:         crdbInternalInflightTraceSpanTable,
-		catconstants.CrdbInternalJobsTableID:                      crdbInternalJobsTable,
-		catconstants.CrdbInternalKVNodeStatusTableID:              crdbInternalKVNodeStatusTable,
-		catconstants.CrdbInternalKVStoreStatusTableID:             crdbInternalKVStoreStatusTable,
-		catconstants.CrdbInternalLeasesTableID:                    crdbInternalLeasesTable,
-		catconstants.CrdbInternalLocalQueriesTableID:              crdbInternalLocalQueriesTable,
-		catconstants.CrdbInternalLocalTransactionsTableID:         crdbInternalLocalTxnsTable,
-		catconstants.CrdbInternalLocalSessionsTableID:             crdbInternalLocalSessionsTable,
-		catconstants.CrdbInternalLocalMetricsTableID:              crdbInternalLocalMetricsTable,
-		catconstants.CrdbInternalPartitionsTableID:                crdbInternalPartitionsTable,
-		catconstants.CrdbInternalPredefinedCommentsTableID:        crdbInternalPredefinedCommentsTable,
-		catconstants.CrdbInternalRangesNoLeasesTableID:            crdbInternalRangesNoLeasesTable,
-		catconstants.CrdbInternalRangesViewID:                     crdbInternalRangesView,
-		catconstants.CrdbInternalRuntimeInfoTableID:               crdbInternalRuntimeInfoTable,
-		catconstants.CrdbInternalSchemaChangesTableID:             crdbInternalSchemaChangesTable,
-		catconstants.CrdbInternalSessionTraceTableID:              crdbInternalSessionTraceTable,
-		catconstants.CrdbInternalSessionVariablesTableID:          crdbInternalSessionVariablesTable,
-		catconstants.CrdbInternalStmtStatsTableID:                 crdbInternalStmtStatsTable,
-		catconstants.CrdbInternalTableColumnsTableID:              crdbInternalTableColumnsTable,
-		catconstants.CrdbInternalTableIndexesTableID:              crdbInternalTableIndexesTable,
-		catconstants.CrdbInternalTablesTableLastStatsID:           crdbInternalTablesTableLastStats,
-		catconstants.CrdbInternalTablesTableID:                    crdbInternalTablesTable,
-		catconstants.CrdbInternalTransactionStatsTableID:          crdbInternalTransactionStatisticsTable,
-		catconstants.CrdbInternalTxnStatsTableID:                  crdbInternalTxnStatsTable,
-		catconstants.CrdbInternalZonesTableID:                     crdbInternalZonesTable,
-		catconstants.CrdbInternalInvalidDescriptorsTableID:        crdbInternalInvalidDescriptorsTable,
-		catconstants.CrdbInternalClusterDatabasePrivilegesTableID: crdbInternalClusterDatabasePrivilegesTable,
+		catconstants.CrdbInternalBackwardDependenciesTableID:        crdbInternalBackwardDependenciesTable,
+		catconstants.CrdbInternalBuildInfoTableID:                   crdbInternalBuildInfoTable,
+		catconstants.CrdbInternalBuiltinFunctionsTableID:            crdbInternalBuiltinFunctionsTable,
+		catconstants.CrdbInternalClusterQueriesTableID:              crdbInternalClusterQueriesTable,
+		catconstants.CrdbInternalClusterTransactionsTableID:         crdbInternalClusterTxnsTable,
+		catconstants.CrdbInternalClusterSessionsTableID:             crdbInternalClusterSessionsTable,
+		catconstants.CrdbInternalClusterSettingsTableID:             crdbInternalClusterSettingsTable,
+		catconstants.CrdbInternalCreateStmtsTableID:                 crdbInternalCreateStmtsTable,
+		catconstants.CrdbInternalCreateTypeStmtsTableID:             crdbInternalCreateTypeStmtsTable,
+		catconstants.CrdbInternalDatabasesTableID:                   crdbInternalDatabasesTable,
+		catconstants.CrdbInternalFeatureUsageID:                     crdbInternalFeatureUsage,
+		catconstants.CrdbInternalForwardDependenciesTableID:         crdbInternalForwardDependenciesTable,
+		catconstants.CrdbInternalGossipNodesTableID:                 crdbInternalGossipNodesTable,
+		catconstants.CrdbInternalGossipAlertsTableID:                crdbInternalGossipAlertsTable,
+		catconstants.CrdbInternalGossipLivenessTableID:              crdbInternalGossipLivenessTable,
+		catconstants.CrdbInternalGossipNetworkTableID:               crdbInternalGossipNetworkTable,
+		catconstants.CrdbInternalIndexColumnsTableID:                crdbInternalIndexColumnsTable,
+		catconstants.CrdbInternalInflightTraceSpanTableID:           crdbInternalInflightTraceSpanTable,
+		catconstants.CrdbInternalJobsTableID:                        crdbInternalJobsTable,
+		catconstants.CrdbInternalKVNodeStatusTableID:                crdbInternalKVNodeStatusTable,
+		catconstants.CrdbInternalKVStoreStatusTableID:               crdbInternalKVStoreStatusTable,
+		catconstants.CrdbInternalLeasesTableID:                      crdbInternalLeasesTable,
+		catconstants.CrdbInternalLocalQueriesTableID:                crdbInternalLocalQueriesTable,
+		catconstants.CrdbInternalLocalTransactionsTableID:           crdbInternalLocalTxnsTable,
+		catconstants.CrdbInternalLocalSessionsTableID:               crdbInternalLocalSessionsTable,
+		catconstants.CrdbInternalLocalMetricsTableID:                crdbInternalLocalMetricsTable,
+		catconstants.CrdbInternalPartitionsTableID:                  crdbInternalPartitionsTable,
+		catconstants.CrdbInternalPredefinedCommentsTableID:          crdbInternalPredefinedCommentsTable,
+		catconstants.CrdbInternalRangesNoLeasesTableID:              crdbInternalRangesNoLeasesTable,
+		catconstants.CrdbInternalRangesViewID:                       crdbInternalRangesView,
+		catconstants.CrdbInternalRuntimeInfoTableID:                 crdbInternalRuntimeInfoTable,
+		catconstants.CrdbInternalSchemaChangesTableID:               crdbInternalSchemaChangesTable,
+		catconstants.CrdbInternalSessionTraceTableID:                crdbInternalSessionTraceTable,
+		catconstants.CrdbInternalSessionVariablesTableID:            crdbInternalSessionVariablesTable,
+		catconstants.CrdbInternalStmtStatsTableID:                   crdbInternalStmtStatsTable,
+		catconstants.CrdbInternalTableColumnsTableID:                crdbInternalTableColumnsTable,
+		catconstants.CrdbInternalTableIndexesTableID:                crdbInternalTableIndexesTable,
+		catconstants.CrdbInternalTablesTableLastStatsID:             crdbInternalTablesTableLastStats,
+		catconstants.CrdbInternalTablesTableID:                      crdbInternalTablesTable,
+		catconstants.CrdbInternalTransactionStatsTableID:            crdbInternalTransactionStatisticsTable,
+		catconstants.CrdbInternalTxnStatsTableID:                    crdbInternalTxnStatsTable,
+		catconstants.CrdbInternalZonesTableID:                       crdbInternalZonesTable,
+		catconstants.CrdbInternalInvalidDescriptorsTableID:          crdbInternalInvalidDescriptorsTable,
+		catconstants.CrdbInternalClusterDatabasePrivilegesTableID:   crdbInternalClusterDatabasePrivilegesTable,
+		catconstants.CrdbInternalPersistedStmtStatsTableID:          crdbInternalPersistedStmtStatsTable,
+		catconstants.CrdbInternalPersistedTxnStatsTableID:           crdbInternalPersistedTxnStatsTable,
+		catconstants.CrdbInternalTenantUsageTableID:                 crdbInternalTenantUsageTable,
+		catconstants.CrdbInternalHotKeysTableID:                     crdbInternalHotKeysTable,
+		catconstants.CrdbInternalTransactionContentionEventsTableID: crdbInternalTransactionContentionEventsTable,
 	},
 	validWithNoDatabaseContext: true,
 }
@@ -799,32 +809,39 @@ var crdbInternalStmtStatsTable = virtualSchemaTable{
 		`This table is wiped periodically (by default, at least every two hours)`,
 	schema: `
 CREATE TABLE crdb_internal.node_statement_statistics (
-  node_id             INT NOT NULL,
-  application_name    STRING NOT NULL,
-  flags               STRING NOT NULL,
-  key                 STRING NOT NULL,
-  anonymized          STRING,
-  count               INT NOT NULL,
-  first_attempt_count INT NOT NULL,
-  max_retries         INT NOT NULL,
-  last_error          STRING,
-  rows_avg            FLOAT NOT NULL,
-  rows_var            FLOAT NOT NULL,
-  parse_lat_avg       FLOAT NOT NULL,
-  parse_lat_var       FLOAT NOT NULL,
-  plan_lat_avg        FLOAT NOT NULL,
-  plan_lat_var        FLOAT NOT NULL,
-  run_lat_avg         FLOAT NOT NULL,
-  run_lat_var         FLOAT NOT NULL,
-  service_lat_avg     FLOAT NOT NULL,
-  service_lat_var     FLOAT NOT NULL,
-  overhead_lat_avg    FLOAT NOT NULL,
-  overhead_lat_var    FLOAT NOT NULL,
-  bytes_read_avg      FLOAT NOT NULL,
-  bytes_read_var      FLOAT NOT NULL,
-  rows_read_avg       FLOAT NOT NULL,
-  rows_read_var       FLOAT NOT NULL,
-  implicit_txn        BOOL NOT NULL
+  node_id               INT NOT NULL,
+  application_name      STRING NOT NULL,
+  flags                 STRING NOT NULL,
+  key                   STRING NOT NULL,
+  anonymized            STRING,
+  count                 INT NOT NULL,
+  first_attempt_count   INT NOT NULL,
+  max_retries           INT NOT NULL,
+  last_error            STRING,
+  rows_avg              FLOAT NOT NULL,
+  rows_var              FLOAT NOT NULL,
+  parse_lat_avg         FLOAT NOT NULL,
+  parse_lat_var         FLOAT NOT NULL,
+  plan_lat_avg          FLOAT NOT NULL,
+  plan_lat_var          FLOAT NOT NULL,
+  run_lat_avg           FLOAT NOT NULL,
+  run_lat_var           FLOAT NOT NULL,
+  service_lat_avg       FLOAT NOT NULL,
+  service_lat_var       FLOAT NOT NULL,
+  overhead_lat_avg      FLOAT NOT NULL,
+  overhead_lat_var      FLOAT NOT NULL,
+  bytes_read_avg        FLOAT NOT NULL,
+  bytes_read_var        FLOAT NOT NULL,
+  rows_read_avg         FLOAT NOT NULL,
+  rows_read_var         FLOAT NOT NULL,
+  implicit_txn          BOOL NOT NULL,
+  cpu_time_avg          FLOAT NOT NULL,
+  cpu_time_var          FLOAT NOT NULL,
+  cpu_time_sample_count INT NOT NULL,
+  cpu_time_coverage     FLOAT NOT NULL,
+  cpu_time_total        FLOAT NOT NULL,
+  peak_mem_usage_avg    FLOAT NOT NULL,
+  peak_mem_usage_var    FLOAT NOT NULL
 )`,
 	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
 		hasViewActivity, err := p.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
@@ -918,6 +935,13 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 					tree.NewDFloat(tree.DFloat(s.mu.data.RowsRead.Mean)),
 					tree.NewDFloat(tree.DFloat(s.mu.data.RowsRead.GetVariance(s.mu.data.Count))),
 					tree.MakeDBool(tree.DBool(stmtKey.implicitTxn)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.CPUTime.Mean)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.CPUTime.GetVariance(s.mu.data.CPUTimeSampleCount))),
+					tree.NewDInt(tree.DInt(s.mu.data.CPUTimeSampleCount)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.CPUTimeCoverage())),
+					tree.NewDFloat(tree.DFloat(s.mu.data.EstimatedTotalCPUTime())),
+					tree.NewDFloat(tree.DFloat(s.mu.data.PeakMemUsage.Mean)),
+					tree.NewDFloat(tree.DFloat(s.mu.data.PeakMemUsage.GetVariance(s.mu.data.Count))),
 				)
 				s.mu.Unlock()
 				if err != nil {
@@ -1043,6 +1067,163 @@ CREATE TABLE crdb_internal.node_transaction_statistics (
 	},
 }
 
+var crdbInternalPersistedStmtStatsTable = virtualSchemaTable{
+	comment: `statement statistics persisted by all nodes, combined across nodes ` +
+		`for each aggregation interval`,
+	schema: `
+CREATE TABLE crdb_internal.statement_statistics (
+  aggregated_ts        TIMESTAMPTZ NOT NULL,
+  fingerprint_id       BYTES NOT NULL,
+  app_name             STRING NOT NULL,
+  aggregation_interval INTERVAL NOT NULL,
+  metadata             JSONB NOT NULL,
+  statistics           JSONB NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		hasViewActivity, err := p.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
+		if err != nil {
+			return err
+		}
+		if !hasViewActivity {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"user %s does not have %s privilege", p.User(), roleoption.VIEWACTIVITY)
+		}
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.SQLStatsTables) {
+			return nil
+		}
+		return forEachPersistedStmtStats(ctx, p.ExecCfg().InternalExecutor, p.txn,
+			time.Time{} /* start */, time.Time{}, /* end */
+			func(key *persistedStatsKey, stats *roachpb.CollectedStatementStatistics) error {
+				aggregatedTs, err := tree.MakeDTimestampTZ(key.aggregatedTs, time.Microsecond)
+				if err != nil {
+					return err
+				}
+				metadata, err := protoreflect.MessageToJSON(&stats.Key, true /* emitDefaults */)
+				if err != nil {
+					return err
+				}
+				statistics, err := protoreflect.MessageToJSON(&stats.Stats, true /* emitDefaults */)
+				if err != nil {
+					return err
+				}
+				return addRow(
+					aggregatedTs,
+					tree.NewDBytes(tree.DBytes(key.fingerprintID)),
+					tree.NewDString(key.appName),
+					tree.NewDInterval(duration.MakeDuration(key.aggInterval.Nanoseconds(), 0, 0), types.DefaultIntervalTypeMetadata),
+					tree.NewDJSON(metadata),
+					tree.NewDJSON(statistics),
+				)
+			})
+	},
+}
+
+var crdbInternalPersistedTxnStatsTable = virtualSchemaTable{
+	comment: `transaction statistics persisted by all nodes, combined across nodes ` +
+		`for each aggregation interval`,
+	schema: `
+CREATE TABLE crdb_internal.transaction_statistics (
+  aggregated_ts        TIMESTAMPTZ NOT NULL,
+  fingerprint_id       BYTES NOT NULL,
+  app_name             STRING NOT NULL,
+  aggregation_interval INTERVAL NOT NULL,
+  metadata             JSONB NOT NULL,
+  statistics           JSONB NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		hasViewActivity, err := p.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
+		if err != nil {
+			return err
+		}
+		if !hasViewActivity {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"user %s does not have %s privilege", p.User(), roleoption.VIEWACTIVITY)
+		}
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.SQLStatsTables) {
+			return nil
+		}
+		return forEachPersistedTxnStats(ctx, p.ExecCfg().InternalExecutor, p.txn,
+			time.Time{} /* start */, time.Time{}, /* end */
+			func(key *persistedStatsKey, stats *roachpb.CollectedTransactionStatistics) error {
+				aggregatedTs, err := tree.MakeDTimestampTZ(key.aggregatedTs, time.Microsecond)
+				if err != nil {
+					return err
+				}
+				// The statement fingerprints are rendered in the same way as the
+				// fingerprint_id column of crdb_internal.statement_statistics.
+				stmtIDs := json.NewArrayBuilder(len(stats.StatementIDs))
+				for _, id := range stats.StatementIDs {
+					stmtIDs.Add(json.FromString(hex.EncodeToString(encodeFingerprintID(uint64(id)))))
+				}
+				metadata := json.NewObjectBuilder(1)
+				metadata.Add("stmtFingerprintIDs", stmtIDs.Build())
+				statistics, err := protoreflect.MessageToJSON(&stats.Stats, true /* emitDefaults */)
+				if err != nil {
+					return err
+				}
+				return addRow(
+					aggregatedTs,
+					tree.NewDBytes(tree.DBytes(key.fingerprintID)),
+					tree.NewDString(key.appName),
+					tree.NewDInterval(duration.MakeDuration(key.aggInterval.Nanoseconds(), 0, 0), types.DefaultIntervalTypeMetadata),
+					tree.NewDJSON(metadata.Build()),
+					tree.NewDJSON(statistics),
+				)
+			})
+	},
+}
+
+var crdbInternalTenantUsageTable = virtualSchemaTable{
+	comment: `resources consumed by tenants and the request units that they are ` +
+		`metered as, combined across nodes for each aggregation interval`,
+	schema: `
+CREATE TABLE crdb_internal.tenant_usage (
+  tenant_id            INT NOT NULL,
+  aggregated_ts        TIMESTAMPTZ NOT NULL,
+  ru                   FLOAT NOT NULL,
+  read_requests        INT NOT NULL,
+  read_bytes           INT NOT NULL,
+  write_requests       INT NOT NULL,
+  write_bytes          INT NOT NULL,
+  sql_pods_cpu_seconds FLOAT NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.tenant_usage"); err != nil {
+			return err
+		}
+		if !p.ExecCfg().Codec.ForSystemTenant() ||
+			!p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.TenantUsageTable) {
+			// Tenants are only metered by the system tenant.
+			return nil
+		}
+		usage, err := tenantusage.Read(ctx, p.ExecCfg().InternalExecutor, p.txn,
+			roachpb.TenantID{}, time.Time{} /* start */, time.Time{} /* end */)
+		if err != nil {
+			return err
+		}
+		for i := range usage {
+			u := &usage[i]
+			aggregatedTs, err := tree.MakeDTimestampTZ(u.AggregatedTs, time.Microsecond)
+			if err != nil {
+				return err
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(u.TenantID.ToUint64())),
+				aggregatedTs,
+				tree.NewDFloat(tree.DFloat(u.Consumption.RU)),
+				tree.NewDInt(tree.DInt(u.Consumption.ReadRequests)),
+				tree.NewDInt(tree.DInt(u.Consumption.ReadBytes)),
+				tree.NewDInt(tree.DInt(u.Consumption.WriteRequests)),
+				tree.NewDInt(tree.DInt(u.Consumption.WriteBytes)),
+				tree.NewDFloat(tree.DFloat(u.Consumption.SQLPodsCPUSeconds)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 var crdbInternalTxnStatsTable = virtualSchemaTable{
 	comment: `per-application transaction statistics (in-memory, not durable; local node only). ` +
 		`This table is wiped periodically (by default, at least every two hours)`,
@@ -3723,6 +3904,173 @@ CREATE TABLE crdb_internal.kv_store_status (
 	},
 }
 
+// crdbInternalHotKeysTable exposes the hottest keys and SQL indexes on each
+// store of the cluster.
+var crdbInternalHotKeysTable = virtualSchemaTable{
+	comment: `keys and SQL index prefixes with the highest estimated QPS on each store ` +
+		`(sampled; cluster RPC; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.hot_keys (
+  node_id       INT NOT NULL,
+  store_id      INT NOT NULL,
+  range_id      INT,
+  kind          STRING NOT NULL,
+  key           STRING,
+  table_id      INT,
+  index_id      INT,
+  database_name STRING,
+  table_name    STRING,
+  index_name    STRING,
+  qps           FLOAT NOT NULL
+)
+	`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.hot_keys"); err != nil {
+			return err
+		}
+		ss, err := p.ExecCfg().NodesStatusServer.OptionalNodesStatusServer(
+			errorutil.FeatureNotAvailableToNonSystemTenantsIssue)
+		if err != nil {
+			return err
+		}
+		response, err := ss.HotKeys(ctx, &serverpb.HotKeysRequest{})
+		if err != nil {
+			return err
+		}
+		nodeIDs := make([]roachpb.NodeID, 0, len(response.HotKeysByNodeID))
+		for nodeID := range response.HotKeysByNodeID {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+		sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+
+		strOrNull := func(s string) tree.Datum {
+			if s == "" {
+				return tree.DNull
+			}
+			return tree.NewDString(s)
+		}
+		idOrNull := func(id int64) tree.Datum {
+			if id == 0 {
+				return tree.DNull
+			}
+			return tree.NewDInt(tree.DInt(id))
+		}
+		keyKind, prefixKind := tree.NewDString("key"), tree.NewDString("prefix")
+		for _, nodeID := range nodeIDs {
+			// Nodes which failed to produce a report, for instance because they
+			// are unavailable, are omitted.
+			for _, storeResp := range response.HotKeysByNodeID[nodeID].Stores {
+				for _, hotKeys := range []struct {
+					kind tree.Datum
+					keys []serverpb.HotKeysResponse_HotKey
+				}{
+					{kind: keyKind, keys: storeResp.HotKeys},
+					{kind: prefixKind, keys: storeResp.HotPrefixes},
+				} {
+					for i := range hotKeys.keys {
+						k := &hotKeys.keys[i]
+						if err := addRow(
+							tree.NewDInt(tree.DInt(nodeID)),
+							tree.NewDInt(tree.DInt(storeResp.StoreID)),
+							idOrNull(int64(k.RangeID)),
+							hotKeys.kind,
+							strOrNull(k.PrettyKey),
+							idOrNull(int64(k.TableID)),
+							idOrNull(int64(k.IndexID)),
+							strOrNull(k.DatabaseName),
+							strOrNull(k.TableName),
+							strOrNull(k.IndexName),
+							tree.NewDFloat(tree.DFloat(k.QueriesPerSecond)),
+						); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalTransactionContentionEventsTable exposes the persisted history
+// of the contention events between transactions.
+var crdbInternalTransactionContentionEventsTable = virtualSchemaTable{
+	comment: `contention events between transactions recorded on each node, with ` +
+		`the statement fingerprints of the waiting and blocking transactions`,
+	schema: `
+CREATE TABLE crdb_internal.transaction_contention_events (
+  ts                        TIMESTAMPTZ NOT NULL,
+  node_id                   INT NOT NULL,
+  key                       STRING NOT NULL,
+  duration                  INTERVAL NOT NULL,
+  waiting_txn_id            UUID,
+  waiting_txn_fingerprints  STRING[],
+  blocking_txn_id           UUID NOT NULL,
+  blocking_txn_fingerprints STRING[]
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.transaction_contention_events"); err != nil {
+			return err
+		}
+		if !p.ExecCfg().Codec.ForSystemTenant() ||
+			!p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.TransactionContentionEventsTable) {
+			// Contention events are only recorded by the KV layer.
+			return nil
+		}
+		events, err := contention.Read(ctx, p.ExecCfg().InternalExecutor, p.txn,
+			time.Time{} /* start */, time.Time{} /* end */, 0 /* limit */)
+		if err != nil {
+			return err
+		}
+		uuidOrNull := func(id uuid.UUID) tree.Datum {
+			if id == (uuid.UUID{}) {
+				return tree.DNull
+			}
+			return tree.NewDUuid(tree.DUuid{UUID: id})
+		}
+		stringsOrNull := func(strs []string) (tree.Datum, error) {
+			if strs == nil {
+				return tree.DNull, nil
+			}
+			arr := tree.NewDArray(types.String)
+			for _, s := range strs {
+				if err := arr.Append(tree.NewDString(s)); err != nil {
+					return nil, err
+				}
+			}
+			return arr, nil
+		}
+		for i := range events {
+			e := &events[i]
+			ts, err := tree.MakeDTimestampTZ(e.Timestamp, time.Microsecond)
+			if err != nil {
+				return err
+			}
+			waitingFingerprints, err := stringsOrNull(e.WaitingTxnFingerprints)
+			if err != nil {
+				return err
+			}
+			blockingFingerprints, err := stringsOrNull(e.BlockingTxnFingerprints)
+			if err != nil {
+				return err
+			}
+			if err := addRow(
+				ts,
+				tree.NewDInt(tree.DInt(e.NodeID)),
+				tree.NewDString(e.Key.String()),
+				tree.NewDInterval(duration.MakeDuration(e.Duration.Nanoseconds(), 0, 0), types.DefaultIntervalTypeMetadata),
+				uuidOrNull(e.WaitingTxnID),
+				waitingFingerprints,
+				uuidOrNull(e.BlockingTxnID),
+				blockingFingerprints,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalPredefinedComments exposes the predefined
 // comments for virtual tables. This is used by SHOW TABLES WITH COMMENT
 // as fall-back when system.comments is silent.
@@ -0,0 +1,205 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+type createForeignTableNode struct {
+	n      *tree.CreateForeignTable
+	dbDesc catalog.DatabaseDescriptor
+	opts   func() (map[string]string, error)
+}
+
+// CreateForeignTableOptsCCL is the hook used to validate the OPTIONS of a
+// CREATE FOREIGN TABLE statement and to translate them into the locations and
+// file format stored on the table descriptor. It is set by importccl, which
+// owns the readers used to scan foreign tables.
+var CreateForeignTableOptsCCL = func(
+	ctx context.Context, p PlanHookState, opts map[string]string,
+) (*descpb.TableDescriptor_ForeignTableOpts, error) {
+	return nil, sqlerrors.NewCCLRequiredError(errors.New(
+		"creating foreign tables requires a CCL binary"))
+}
+
+// CreateForeignTable creates a read-only table backed by external files.
+// Privileges: CREATE on database.
+func (p *planner) CreateForeignTable(
+	ctx context.Context, n *tree.CreateForeignTable,
+) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"CREATE FOREIGN TABLE",
+	); err != nil {
+		return nil, err
+	}
+
+	un := n.Table.ToUnresolvedObjectName()
+	dbDesc, _, prefix, err := p.ResolveTargetObject(ctx, un)
+	if err != nil {
+		return nil, err
+	}
+	n.Table.ObjectNamePrefix = prefix
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	if err := validateForeignTableDefs(n.Defs); err != nil {
+		return nil, err
+	}
+
+	// The set of valid options depends on the file format and is validated by
+	// the CCL hook, so every option is typed here as an optional string.
+	optValidate := make(map[string]KVStringOptValidate, len(n.Options))
+	for _, opt := range n.Options {
+		optValidate[string(opt.Key)] = KVStringOptAny
+	}
+	opts, err := p.TypeAsStringOpts(ctx, n.Options, optValidate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createForeignTableNode{
+		n:      n,
+		dbDesc: dbDesc,
+		opts:   opts,
+	}, nil
+}
+
+// validateForeignTableDefs checks that the table definition of a foreign table
+// only contains plain column definitions. Foreign tables do not store any data
+// themselves, so they cannot have indexes, constraints or expressions that
+// would need to be maintained or evaluated on write.
+func validateForeignTableDefs(defs tree.TableDefs) error {
+	if len(defs) == 0 {
+		return pgerror.New(pgcode.InvalidTableDefinition,
+			"foreign tables must have at least one column")
+	}
+	for _, def := range defs {
+		d, ok := def.(*tree.ColumnTableDef)
+		if !ok {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"%s is not supported on foreign tables", tree.AsString(def))
+		}
+		if d.IsSerial || d.Hidden || d.PrimaryKey.IsPrimaryKey || d.Unique.IsUnique ||
+			d.HasDefaultExpr() || d.HasFKConstraint() || d.IsComputed() ||
+			d.HasColumnFamily() || len(d.CheckExprs) > 0 {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"column %q: foreign table columns only support a type and nullability", d.Name)
+		}
+	}
+	return nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE FOREIGN TABLE performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createForeignTableNode) ReadingOwnWrites() {}
+
+func (n *createForeignTableNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("foreign_table"))
+
+	tKey, schemaID, err := getTableCreateParams(
+		params, n.dbDesc.GetID(), tree.PersistencePermanent, &n.n.Table,
+	)
+	if err != nil {
+		if sqlerrors.IsRelationAlreadyExistsError(err) && n.n.IfNotExists {
+			return nil
+		}
+		return err
+	}
+
+	opts, err := n.opts()
+	if err != nil {
+		return err
+	}
+	foreignOpts, err := CreateForeignTableOptsCCL(params.ctx, params.p, opts)
+	if err != nil {
+		return err
+	}
+
+	id, err := catalogkv.GenerateUniqueDescID(params.ctx, params.p.ExecCfg().DB, params.p.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+
+	privs := CreateInheritedPrivilegesFromDBDesc(n.dbDesc, params.SessionData().User())
+
+	// The table is described to the rest of the system as a regular table with
+	// a hidden rowid primary key. The rowid values are synthesized by the scan
+	// from the position of each record in the backing files.
+	createTable := &tree.CreateTable{
+		Table: n.n.Table,
+		Defs:  n.n.Defs,
+	}
+	// creationTime is initialized to a zero value and populated at read time.
+	// See the comment in desc.MaybeIncrementVersion.
+	var creationTime hlc.Timestamp
+	affected := make(map[descpb.ID]*tabledesc.Mutable)
+	desc, err := newTableDesc(
+		params, createTable, n.dbDesc.GetID(), schemaID, id, creationTime, privs, affected,
+	)
+	if err != nil {
+		return err
+	}
+	if len(affected) > 0 {
+		return errors.AssertionFailedf("foreign table %q unexpectedly references other tables", desc.Name)
+	}
+	desc.ForeignTableOpts = foreignOpts
+	desc.State = descpb.DescriptorState_PUBLIC
+
+	if err := params.p.createDescriptorWithID(
+		params.ctx, tKey.Key(params.ExecCfg().Codec), id, desc, params.EvalContext().Settings,
+		tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+
+	// Install back references to types used by this table.
+	if err := params.p.addBackRefsFromAllTypesInTable(params.ctx, desc); err != nil {
+		return err
+	}
+
+	dg := catalogkv.NewOneLevelUncachedDescGetter(params.p.txn, params.ExecCfg().Codec)
+	if err := desc.Validate(params.ctx, dg); err != nil {
+		return err
+	}
+
+	// Log Create Table event. This is an auditable log event and is
+	// recorded in the same transaction as the table descriptor update.
+	return params.p.logEvent(params.ctx,
+		desc.ID,
+		&eventpb.CreateTable{
+			TableName: n.n.Table.FQString(),
+		})
+}
+
+func (*createForeignTableNode) Next(runParams) (bool, error) { return false, nil }
+func (*createForeignTableNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createForeignTableNode) Close(context.Context)        {}
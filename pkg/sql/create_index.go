@@ -63,6 +63,10 @@ func (p *planner) CreateIndex(ctx context.Context, n *tree.CreateIndex) (planNod
 		return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not a table or materialized view", tableDesc.Name)
 	}
 
+	if tableDesc.IsForeignTable() {
+		return nil, pgerror.Newf(pgcode.WrongObjectType, "cannot create index on foreign table %q", tableDesc.Name)
+	}
+
 	if tableDesc.MaterializedView() {
 		if n.Interleave != nil {
 			return nil, pgerror.New(pgcode.InvalidObjectDefinition,
@@ -70,6 +70,9 @@ func TryDelegate(
 	case *tree.ShowGrants:
 		return d.delegateShowGrants(t)
 
+	case *tree.ShowHotKeys:
+		return d.delegateShowHotKeys()
+
 	case *tree.ShowJobs:
 		return d.delegateShowJobs(t)
 
@@ -0,0 +1,26 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package delegate
+
+import "github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+
+// delegateShowHotKeys implements SHOW HOT KEYS which returns the keys and SQL
+// index prefixes with the highest QPS on each store of the cluster.
+// Privileges: admin.
+func (d *delegator) delegateShowHotKeys() (tree.Statement, error) {
+	return parse(`
+SELECT
+	node_id, store_id, range_id, kind, key, database_name, table_name, index_name, qps
+FROM
+	crdb_internal.hot_keys
+ORDER BY
+	qps DESC, node_id, store_id, kind, key`)
+}
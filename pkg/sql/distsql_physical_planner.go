@@ -1251,6 +1251,71 @@ type tableReaderPlanningInfo struct {
 func (dsp *DistSQLPlanner) planTableReaders(
 	planCtx *PlanningCtx, p *PhysicalPlan, info *tableReaderPlanningInfo,
 ) error {
+	var corePlacement []physicalplan.ProcessorCorePlacement
+	var err error
+	if info.desc.IsForeignTable() {
+		corePlacement, err = dsp.foreignTableScanCorePlacement(planCtx, info)
+	} else {
+		corePlacement, err = dsp.tableReaderCorePlacement(planCtx, p, info)
+	}
+	if err != nil {
+		return err
+	}
+
+	returnMutations := info.scanVisibility == execinfra.ScanVisibilityPublicAndNotPublic
+	typs := info.desc.ColumnTypesWithMutationsAndVirtualCol(returnMutations, info.spec.VirtualColumn)
+	if info.containsSystemColumns {
+		for i := range colinfo.AllSystemColumnDescs {
+			typs = append(typs, colinfo.AllSystemColumnDescs[i].Type)
+		}
+	}
+
+	p.AddNoInputStage(
+		corePlacement, info.post, typs, dsp.convertOrdering(info.reqOrdering, info.colsToTableOrdinalMap),
+	)
+
+	outCols := getOutputColumnsFromColsForScan(info.cols, info.colsToTableOrdinalMap)
+	planToStreamColMap := make([]int, len(info.cols))
+	var descColumnIDs util.FastIntMap
+	colID := 0
+	for i := range info.desc.GetPublicColumns() {
+		descColumnIDs.Set(colID, int(info.desc.GetPublicColumns()[i].ID))
+		colID++
+	}
+	if returnMutations {
+		mutationColumns := info.desc.MutationColumns()
+		for i := range mutationColumns {
+			descColumnIDs.Set(colID, int(mutationColumns[i].ID))
+			colID++
+		}
+	}
+	if info.containsSystemColumns {
+		for i := range colinfo.AllSystemColumnDescs {
+			descColumnIDs.Set(colID, int(colinfo.AllSystemColumnDescs[i].ID))
+			colID++
+		}
+	}
+
+	for i := range planToStreamColMap {
+		planToStreamColMap[i] = -1
+		for j, c := range outCols {
+			if descColumnIDs.GetDefault(int(c)) == int(info.cols[i].ID) {
+				planToStreamColMap[i] = j
+				break
+			}
+		}
+	}
+	p.AddProjection(outCols)
+
+	p.PlanToStreamColMap = planToStreamColMap
+	return nil
+}
+
+// tableReaderCorePlacement plans table readers on the nodes that hold the
+// spans being scanned.
+func (dsp *DistSQLPlanner) tableReaderCorePlacement(
+	planCtx *PlanningCtx, p *PhysicalPlan, info *tableReaderPlanningInfo,
+) ([]physicalplan.ProcessorCorePlacement, error) {
 	var (
 		spanPartitions []SpanPartition
 		err            error
@@ -1265,14 +1330,14 @@ func (dsp *DistSQLPlanner) planTableReaders(
 		// TODO(yuzefovich): add that mechanism.
 		spanPartitions, err = dsp.PartitionSpans(planCtx, info.spans)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	} else {
 		// If the scan has a hard limit, use a single TableReader to avoid
 		// reading more rows than necessary.
 		nodeID, err := dsp.getNodeIDForScan(planCtx, info.spans, info.reverse)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		spanPartitions = []SpanPartition{{nodeID, info.spans}}
 	}
@@ -1306,54 +1371,37 @@ func (dsp *DistSQLPlanner) planTableReaders(
 		corePlacement[i].NodeID = sp.Node
 		corePlacement[i].Core.TableReader = tr
 	}
+	return corePlacement, nil
+}
 
-	returnMutations := info.scanVisibility == execinfra.ScanVisibilityPublicAndNotPublic
-	typs := info.desc.ColumnTypesWithMutationsAndVirtualCol(returnMutations, info.spec.VirtualColumn)
-	if info.containsSystemColumns {
-		for i := range colinfo.AllSystemColumnDescs {
-			typs = append(typs, colinfo.AllSystemColumnDescs[i].Type)
-		}
-	}
-
-	p.AddNoInputStage(
-		corePlacement, info.post, typs, dsp.convertOrdering(info.reqOrdering, info.colsToTableOrdinalMap),
-	)
-
-	outCols := getOutputColumnsFromColsForScan(info.cols, info.colsToTableOrdinalMap)
-	planToStreamColMap := make([]int, len(info.cols))
-	var descColumnIDs util.FastIntMap
-	colID := 0
-	for i := range info.desc.GetPublicColumns() {
-		descColumnIDs.Set(colID, int(info.desc.GetPublicColumns()[i].ID))
-		colID++
-	}
-	if returnMutations {
-		mutationColumns := info.desc.MutationColumns()
-		for i := range mutationColumns {
-			descColumnIDs.Set(colID, int(mutationColumns[i].ID))
-			colID++
-		}
+// foreignTableScanCorePlacement plans a single processor on the gateway that
+// reads all of the files backing a foreign table. The processor produces
+// rows in the same shape as a table reader, so the rest of the plan is
+// oblivious to where the rows come from. Foreign tables have no KV data to
+// constrain, and the optimizer only ever plans full, forward scans of their
+// primary index.
+func (dsp *DistSQLPlanner) foreignTableScanCorePlacement(
+	planCtx *PlanningCtx, info *tableReaderPlanningInfo,
+) ([]physicalplan.ProcessorCorePlacement, error) {
+	fullSpan := info.desc.PrimaryIndexSpan(planCtx.ExtendedEvalCtx.Codec)
+	if info.reverse || info.spec.IndexIdx != 0 || len(info.spans) != 1 ||
+		!info.spans[0].Equal(fullSpan) {
+		return nil, errors.AssertionFailedf(
+			"unexpected partial scan of foreign table %q", info.desc.GetName())
 	}
 	if info.containsSystemColumns {
-		for i := range colinfo.AllSystemColumnDescs {
-			descColumnIDs.Set(colID, int(colinfo.AllSystemColumnDescs[i].ID))
-			colID++
-		}
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"system columns are not supported on foreign table %q", info.desc.GetName())
 	}
-
-	for i := range planToStreamColMap {
-		planToStreamColMap[i] = -1
-		for j, c := range outCols {
-			if descColumnIDs.GetDefault(int(c)) == int(info.cols[i].ID) {
-				planToStreamColMap[i] = j
-				break
-			}
-		}
-	}
-	p.AddProjection(outCols)
-
-	p.PlanToStreamColMap = planToStreamColMap
-	return nil
+	return []physicalplan.ProcessorCorePlacement{{
+		NodeID: dsp.gatewayNodeID,
+		Core: execinfrapb.ProcessorCoreUnion{
+			ForeignTableScan: &execinfrapb.ForeignTableScanSpec{
+				Table:     *info.desc.TableDesc(),
+				UserProto: planCtx.EvalContext().SessionData.User().EncodeProto(),
+			},
+		},
+	}}, nil
 }
 
 // selectRenders takes a PhysicalPlan that produces the results corresponding to
@@ -2040,6 +2088,11 @@ func (dsp *DistSQLPlanner) createPlanForIndexJoin(
 func (dsp *DistSQLPlanner) createPlanForLookupJoin(
 	planCtx *PlanningCtx, n *lookupJoinNode,
 ) (*PhysicalPlan, error) {
+	if n.table.desc.IsForeignTable() {
+		return nil, errors.AssertionFailedf(
+			"unexpected lookup join into foreign table %q", n.table.desc.GetName())
+	}
+
 	plan, err := dsp.createPhysPlanForPlanNode(planCtx, n.input)
 	if err != nil {
 		return nil, err
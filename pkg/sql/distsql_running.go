@@ -368,6 +368,11 @@ func (dsp *DistSQLPlanner) Run(
 			"The error should have gone to the consumer.", err)
 	}
 
+	// The gateway flow has run to completion, so the peak memory usage
+	// accounted for by its monitor is final. The remote flows have reported
+	// theirs already.
+	recv.stats.peakMemUsage += flow.GetFlowCtx().EvalCtx.Mon.MaximumBytes()
+
 	// TODO(yuzefovich): it feels like this closing should happen after
 	// PlanAndRun. We should refactor this and get rid off ignoreClose field.
 	if planCtx.planner != nil && !planCtx.ignoreClose {
@@ -657,6 +662,7 @@ func (r *DistSQLReceiver) Push(
 		if meta.Metrics != nil {
 			r.stats.bytesRead += meta.Metrics.BytesRead
 			r.stats.rowsRead += meta.Metrics.RowsRead
+			r.stats.peakMemUsage += meta.Metrics.PeakMemUsage
 			if r.progressAtomic != nil && r.expectedRowsRead != 0 {
 				progress := float64(r.stats.rowsRead) / float64(r.expectedRowsRead)
 				atomic.StoreUint64(r.progressAtomic, math.Float64bits(progress))
@@ -696,14 +696,16 @@ func removeMatchingReferences(
 	return updatedRefs
 }
 
-func (p *planner) removeTableComments(ctx context.Context, tableDesc *tabledesc.Mutable) error {
+func (p *planner) removeTableComments(
+	ctx context.Context, tableDesc catalog.TableDescriptor,
+) error {
 	_, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.ExecEx(
 		ctx,
 		"delete-table-comments",
 		p.txn,
 		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
 		"DELETE FROM system.comments WHERE object_id=$1",
-		tableDesc.ID)
+		tableDesc.GetID())
 	if err != nil {
 		return err
 	}
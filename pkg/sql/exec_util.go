@@ -186,6 +186,13 @@ var traceSessionEventLogEnabled = settings.RegisterBoolSetting(
 	false,
 ).WithPublic()
 
+var allowReadCommittedIsolation = settings.RegisterBoolSetting(
+	"sql.txn.read_committed_isolation.enabled",
+	"set to true to allow transactions to use the READ COMMITTED isolation level; "+
+		"if false, READ COMMITTED transactions are run with SERIALIZABLE isolation",
+	false,
+).WithPublic()
+
 // ReorderJoinsLimitClusterSettingName is the name of the cluster setting for
 // the maximum number of joins to reorder.
 const ReorderJoinsLimitClusterSettingName = "sql.defaults.reorder_joins_limit"
@@ -289,6 +296,12 @@ var implicitSelectForUpdateClusterMode = settings.RegisterBoolSetting(
 	true,
 )
 
+var replicatedLockingClusterMode = settings.RegisterBoolSetting(
+	"sql.defaults.replicated_locking.enabled",
+	"default value for enable_replicated_locking session setting; makes the locks acquired by SELECT FOR UPDATE and FOR SHARE replicated",
+	false,
+)
+
 var insertFastPathClusterMode = settings.RegisterBoolSetting(
 	"sql.defaults.insert_fast_path.enabled",
 	"default value for enable_insert_fast_path session setting; enables a specialized insert path",
@@ -755,6 +768,7 @@ type ExecutorConfig struct {
 	StatsRefresher    *stats.Refresher
 	InternalExecutor  *InternalExecutor
 	QueryCache        *querycache.C
+	PlanHintsCache    *PlanHintsCache
 
 	SchemaChangerMetrics *SchemaChangerMetrics
 	FeatureFlagMetrics   *featureflag.DenialMetrics
@@ -804,6 +818,11 @@ type ExecutorConfig struct {
 	// ContentionRegistry is a node-level registry of contention events used for
 	// contention observability.
 	ContentionRegistry *contention.Registry
+
+	// ContentionHistory is the node-level history of the contention events
+	// between transactions, which remembers the statements executed by the
+	// transactions on this node. It is nil for tenants.
+	ContentionHistory *contention.History
 }
 
 // Organization returns the value of cluster.organization.
@@ -1171,17 +1190,18 @@ func checkResultType(typ *types.T) error {
 // EvalAsOfTimestamp evaluates and returns the timestamp from an AS OF SYSTEM
 // TIME clause.
 func (p *planner) EvalAsOfTimestamp(
-	ctx context.Context, asOf tree.AsOfClause,
-) (_ hlc.Timestamp, err error) {
-	ts, err := tree.EvalAsOfTimestamp(ctx, asOf, &p.semaCtx, p.EvalContext())
+	ctx context.Context, asOf tree.AsOfClause, opts ...tree.EvalAsOfTimestampOption,
+) (tree.AsOfSystemTime, error) {
+	asOfRet, err := tree.EvalAsOfTimestamp(ctx, asOf, &p.semaCtx, p.EvalContext(), opts...)
 	if err != nil {
-		return hlc.Timestamp{}, err
+		return tree.AsOfSystemTime{}, err
 	}
+	ts := asOfRet.Timestamp
 	if now := p.execCfg.Clock.Now(); now.Less(ts) {
-		return hlc.Timestamp{}, errors.Errorf(
+		return tree.AsOfSystemTime{}, errors.Errorf(
 			"AS OF SYSTEM TIME: cannot specify timestamp in the future (%s > %s)", ts, now)
 	}
-	return ts, nil
+	return asOfRet, nil
 }
 
 // ParseHLC parses a string representation of an `hlc.Timestamp`.
@@ -1207,11 +1227,14 @@ func ParseHLC(s string) (hlc.Timestamp, error) {
 
 // isAsOf analyzes a statement to bypass the logic in newPlan(), since
 // that requires the transaction to be started already. If the returned
-// timestamp is not nil, it is the timestamp to which a transaction
-// should be set. The statements that will be checked are Select,
-// ShowTrace (of a Select statement), Scrub, Export, and CreateStats.
-func (p *planner) isAsOf(ctx context.Context, stmt tree.Statement) (*hlc.Timestamp, error) {
+// AS OF SYSTEM TIME clause is not nil, its timestamp is the one to which
+// a transaction should be set. The statements that will be checked are
+// Select, ShowTrace (of a Select statement), Scrub, Export, and
+// CreateStats. Only Select statements (possibly wrapped in an Explain)
+// may perform bounded staleness reads.
+func (p *planner) isAsOf(ctx context.Context, stmt tree.Statement) (*tree.AsOfSystemTime, error) {
 	var asOf tree.AsOfClause
+	var opts []tree.EvalAsOfTimestampOption
 	switch s := stmt.(type) {
 	case *tree.Select:
 		selStmt := s.Select
@@ -1230,13 +1253,19 @@ func (p *planner) isAsOf(ctx context.Context, stmt tree.Statement) (*hlc.Timesta
 		}
 
 		asOf = sc.From.AsOf
+		opts = append(opts, tree.EvalAsOfTimestampOptionAllowBoundedStaleness)
 	case *tree.Scrub:
 		if s.AsOf.Expr == nil {
 			return nil, nil
 		}
 		asOf = s.AsOf
 	case *tree.Export:
-		return p.isAsOf(ctx, s.Query)
+		asOfRet, err := p.isAsOf(ctx, s.Query)
+		if err == nil && asOfRet != nil && asOfRet.BoundedStaleness {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"AS OF SYSTEM TIME: bounded staleness reads are not supported by EXPORT")
+		}
+		return asOfRet, err
 	case *tree.CreateStats:
 		if s.Options.AsOf.Expr == nil {
 			return nil, nil
@@ -1247,8 +1276,11 @@ func (p *planner) isAsOf(ctx context.Context, stmt tree.Statement) (*hlc.Timesta
 	default:
 		return nil, nil
 	}
-	ts, err := p.EvalAsOfTimestamp(ctx, asOf)
-	return &ts, err
+	asOfRet, err := p.EvalAsOfTimestamp(ctx, asOf, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &asOfRet, nil
 }
 
 // isSavepoint returns true if ast is a SAVEPOINT statement.
@@ -1336,6 +1368,10 @@ type SessionArgs struct {
 	// client.
 	RemoteAddr            net.Addr
 	ConnResultsBufferSize int64
+	// LogicalReplication is set for connections opened with the
+	// replication=database parameter, which accept the commands of the
+	// streaming replication protocol in addition to SQL statements.
+	LogicalReplication bool
 }
 
 // SessionRegistry stores a set of all sessions on this node.
@@ -2095,6 +2131,10 @@ func (m *sessionDataMutator) SetDefaultTransactionPriority(val tree.UserPriority
 	m.data.DefaultTxnPriority = int(val)
 }
 
+func (m *sessionDataMutator) SetDefaultTransactionIsolationLevel(val tree.IsolationLevel) {
+	m.data.DefaultTxnIsolationLevel = int(val)
+}
+
 func (m *sessionDataMutator) SetDefaultTransactionReadOnly(val bool) {
 	m.data.DefaultTxnReadOnly = val
 }
@@ -2169,6 +2209,10 @@ func (m *sessionDataMutator) SetImplicitSelectForUpdate(val bool) {
 	m.data.ImplicitSelectForUpdate = val
 }
 
+func (m *sessionDataMutator) SetReplicatedLocking(val bool) {
+	m.data.ReplicatedLocking = val
+}
+
 func (m *sessionDataMutator) SetInsertFastPath(val bool) {
 	m.data.InsertFastPath = val
 }
@@ -2226,6 +2270,10 @@ func (m *sessionDataMutator) SetSaveTablesPrefix(prefix string) {
 	m.data.SaveTablesPrefix = prefix
 }
 
+func (m *sessionDataMutator) SetTraceParent(traceParent string) {
+	m.data.TraceParent = traceParent
+}
+
 func (m *sessionDataMutator) SetTempTablesEnabled(val bool) {
 	m.data.TempTablesEnabled = val
 }
@@ -61,6 +61,11 @@ func (m *ReadImportDataSpec) User() security.SQLUsername {
 	return m.UserProto.Decode()
 }
 
+// User accesses the user field.
+func (m *ForeignTableScanSpec) User() security.SQLUsername {
+	return m.UserProto.Decode()
+}
+
 // User accesses the user field.
 func (m *ChangeAggregatorSpec) User() security.SQLUsername {
 	return m.UserProto.Decode()
@@ -483,6 +483,13 @@ func (c *ReadImportDataSpec) summary() (string, []string) {
 	return "ReadImportData", ss
 }
 
+// summary implements the diagramCellType interface.
+func (s *ForeignTableScanSpec) summary() (string, []string) {
+	// The locations of the files are not shown since they may contain
+	// credentials.
+	return "ForeignTableScan", []string{s.Table.Name}
+}
+
 // summary implements the diagramCellType interface.
 func (s *CSVWriterSpec) summary() (string, []string) {
 	return "CSVWriter", []string{s.Destination}
@@ -223,6 +223,11 @@ func (ex *connExecutor) recordStatementSummary(
 		ex.extraTxnState.transactionStatementsHash.Add(uint64(stmtID))
 	}
 	ex.extraTxnState.numRows += rowsAffected
+	// Remember the fingerprint of the statement, so that the contention events
+	// in which the transaction is involved can be attributed to it.
+	if planner.txn != nil {
+		ex.server.cfg.ContentionHistory.RecordStatement(planner.txn.ID(), stmt.AnonymizedStr)
+	}
 
 	if log.V(2) {
 		// ages since significant epochs
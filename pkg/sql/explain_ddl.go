@@ -20,7 +20,6 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scbuild"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scgraphviz"
-	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scplan"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 )
@@ -51,15 +50,12 @@ func (n *explainDDLNode) Close(ctx context.Context) {
 var _ planNode = (*explainDDLNode)(nil)
 
 func (n *explainDDLNode) startExec(params runParams) error {
-	b := scbuild.NewBuilder(params.p, params.p.SemaCtx(), params.p.EvalContext())
-	var ts []*scpb.Node
-	var err error
-	switch n := params.p.stmt.AST.(*tree.Explain).Statement.(type) {
-	case *tree.AlterTable:
-		ts, err = b.AlterTable(params.ctx, params.extendedEvalCtx.SchemaChangerState.nodes, n)
-	default:
-
-	}
+	b := scbuild.NewBuilder(params.p, params.p.SemaCtx(), params.p.EvalContext(), params.p)
+	ts, err := b.Build(
+		params.ctx,
+		params.extendedEvalCtx.SchemaChangerState.nodes,
+		params.p.stmt.AST.(*tree.Explain).Statement,
+	)
 	if err != nil {
 		return err
 	}
@@ -14,6 +14,7 @@ package faketreeeval
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -23,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
 )
@@ -130,6 +132,23 @@ func (ep *DummyEvalPlanner) MemberOfWithAdminOption(
 	return nil, errors.WithStack(errEvalPlanner)
 }
 
+// PinPlan is part of the EvalPlanner interface.
+func (ep *DummyEvalPlanner) PinPlan(ctx context.Context, sql string) (string, error) {
+	return "", errors.WithStack(errEvalPlanner)
+}
+
+// UnpinPlan is part of the EvalPlanner interface.
+func (ep *DummyEvalPlanner) UnpinPlan(ctx context.Context, fingerprint string) (bool, error) {
+	return false, errors.WithStack(errEvalPlanner)
+}
+
+// StartFingerprintCheck is part of the EvalPlanner interface.
+func (ep *DummyEvalPlanner) StartFingerprintCheck(
+	ctx context.Context, span roachpb.Span, source string, asOf hlc.Timestamp,
+) (int64, error) {
+	return 0, errors.WithStack(errEvalPlanner)
+}
+
 var _ tree.EvalPlanner = &DummyEvalPlanner{}
 
 var errEvalPlanner = pgerror.New(pgcode.ScalarOperationCannotRunWithoutFullSessionContext,
@@ -0,0 +1,96 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// IsPeerClusterURI returns whether the source of a fingerprint check is the
+// connection string of a peer cluster, rather than the URI of a backup.
+func IsPeerClusterURI(source string) bool {
+	u, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "postgres" || u.Scheme == "postgresql"
+}
+
+// StartFingerprintCheck is part of the tree.EvalPlanner interface.
+//
+// The job compares the fingerprints of the live data in the span with either
+// those recorded in the most recent backup in the backup collection at source,
+// as of the end time of that backup, or those computed by the peer cluster
+// that source connects to, as of asOf. The latter defaults to the timestamp
+// of the current transaction. The job itself is implemented in CCL code.
+func (p *planner) StartFingerprintCheck(
+	ctx context.Context, span roachpb.Span, source string, asOf hlc.Timestamp,
+) (int64, error) {
+	if err := p.RequireAdminRole(ctx, "check fingerprints"); err != nil {
+		return 0, err
+	}
+	if _, err := url.Parse(source); err != nil {
+		return 0, pgerror.Wrap(err, pgcode.InvalidParameterValue, "invalid source URI")
+	}
+	if IsPeerClusterURI(source) {
+		if asOf.IsEmpty() {
+			asOf = p.txn.ReadTimestamp()
+		}
+	} else if !asOf.IsEmpty() {
+		return 0, pgerror.New(pgcode.InvalidParameterValue,
+			"as_of can only be specified when checking against a peer cluster")
+	}
+
+	record := jobs.Record{
+		Description: fmt.Sprintf("CHECK FINGERPRINTS %s AGAINST '%s'",
+			span, redactFingerprintSource(source)),
+		Username: p.User(),
+		Details: jobspb.FingerprintCheckDetails{
+			Span:   span,
+			Source: source,
+			AsOf:   asOf,
+		},
+		Progress: jobspb.FingerprintCheckProgress{},
+	}
+	job, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(ctx, record, p.txn)
+	if err != nil {
+		return 0, err
+	}
+	return *job.ID(), nil
+}
+
+// redactFingerprintSource removes the password and the values of all query
+// parameters, which may hold credentials, from the source of a fingerprint
+// check so that it can be used in the job description.
+func redactFingerprintSource(source string) string {
+	u, err := url.Parse(source)
+	if err != nil {
+		return "<invalid>"
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "redacted")
+	}
+	params := u.Query()
+	for param := range params {
+		params.Set(param, "redacted")
+	}
+	u.RawQuery = params.Encode()
+	return u.String()
+}
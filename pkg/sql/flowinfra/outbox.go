@@ -293,12 +293,22 @@ func (m *Outbox) mainLoop(ctx context.Context) error {
 		case msg, ok := <-m.RowChannel.C:
 			if !ok {
 				// No more data.
+				lastOutbox := !m.isGatewayNode && m.numOutboxes != nil && atomic.AddInt32(m.numOutboxes, -1) == 0
+				if lastOutbox {
+					// The peak memory usage of the flow is reported to the gateway
+					// by the last outbox, once the rest of the flow is done.
+					meta := execinfrapb.ProducerMetadata{Metrics: execinfrapb.GetMetricsMeta()}
+					meta.Metrics.PeakMemUsage = m.flowCtx.EvalCtx.Mon.MaximumBytes()
+					if err := m.addRow(ctx, nil, &meta); err != nil {
+						return err
+					}
+				}
 				if m.statsCollectionEnabled {
 					err := m.flush(ctx)
 					if err != nil {
 						return err
 					}
-					if !m.isGatewayNode && m.numOutboxes != nil && atomic.AddInt32(m.numOutboxes, -1) == 0 {
+					if lastOutbox {
 						// TODO(cathymw): maxMemUsage shouldn't be attached to span stats that are associated with streams,
 						// since it's a flow level stat. However, due to the row exec engine infrastructure, it is too
 						// complicated to attach this to a flow level span. If the row exec engine gets removed, getting
@@ -258,6 +258,77 @@ func TestOutboxInitializesStreamBeforeReceivingAnyRows(t *testing.T) {
 	outboxWG.Wait()
 }
 
+// Test that the last outbox of a remote flow reports the peak memory usage of
+// the flow to the gateway.
+func TestOutboxReportsPeakMemUsage(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	clock := hlc.NewClock(hlc.UnixNano, time.Nanosecond)
+	clusterID, mockServer, addr, err := execinfrapb.StartMockDistSQLServer(clock, stopper, execinfra.StaticNodeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	acc := evalCtx.Mon.MakeBoundAccount()
+	if err := acc.Grow(ctx, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	acc.Close(ctx)
+
+	clientRPC := rpc.NewInsecureTestingContextWithClusterID(clock, stopper, clusterID)
+	flowCtx := execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		ID:      execinfrapb.FlowID{UUID: uuid.MakeV4()},
+		Cfg: &execinfra.ServerConfig{
+			Settings:   st,
+			Stopper:    stopper,
+			NodeDialer: nodedialer.New(clientRPC, staticAddressResolver(addr)),
+		},
+	}
+	numOutboxes := int32(1)
+	outbox := NewOutbox(&flowCtx, execinfra.StaticNodeID, execinfrapb.StreamID(42), &numOutboxes, false /* isGatewayNode */)
+	outbox.Init(rowenc.OneIntCol)
+	var outboxWG sync.WaitGroup
+	outbox.Start(ctx, &outboxWG, cancel)
+	outbox.ProducerDone()
+
+	streamNotification := <-mockServer.InboundStreams
+	serverStream := streamNotification.Stream
+	var decoder StreamDecoder
+	var rows rowenc.EncDatumRows
+	var metas []execinfrapb.ProducerMetadata
+	for {
+		msg, err := serverStream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatal(err)
+		}
+		if err := decoder.AddMessage(context.Background(), msg); err != nil {
+			t.Fatal(err)
+		}
+		rows, metas = testGetDecodedRows(t, &decoder, rows, metas)
+	}
+	outboxWG.Wait()
+	streamNotification.Donec <- nil
+
+	if len(metas) != 1 || metas[0].Metrics == nil {
+		t.Fatalf("expected one metrics metadata record, got: %+v", metas)
+	}
+	if peak := metas[0].Metrics.PeakMemUsage; peak < 1<<20 {
+		t.Fatalf("expected a peak memory usage of at least %d, got: %d", 1<<20, peak)
+	}
+}
+
 // Test that the outbox responds to the consumer shutting down in an unexpected
 // way by closing.
 func TestOutboxClosesWhenConsumerCloses(t *testing.T) {
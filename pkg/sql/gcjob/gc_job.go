@@ -112,6 +112,13 @@ func (r schemaChangeGCResumer) Resume(ctx context.Context, execCtx interface{})
 		}
 	}
 
+	if details.Tables != nil {
+		if err := deleteTablesWithRangeTombstones(ctx, execCfg, progress); err != nil {
+			return err
+		}
+		persistProgress(ctx, execCfg, r.jobID, progress)
+	}
+
 	tableDropTimes, indexDropTimes := getDropTimes(details)
 
 	timer := timeutil.NewTimer()
@@ -14,6 +14,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -80,6 +81,74 @@ func gcTables(
 	return nil
 }
 
+// deleteTablesWithRangeTombstones deletes the data of the dropped tables which
+// are waiting for their GC TTL to expire with MVCC range tombstones. This
+// deletes the data at the KV level right away, while preserving its history
+// for reads below the deletion, such as AS OF SYSTEM TIME queries and
+// backups, until the data is cleared by gcTables. The job progress is updated
+// in place, but needs to be persisted to the job.
+func deleteTablesWithRangeTombstones(
+	ctx context.Context, execCfg *sql.ExecutorConfig, progress *jobspb.SchemaChangeGCProgress,
+) error {
+	if !execCfg.Settings.Version.IsActive(ctx, clusterversion.MVCCRangeTombstones) {
+		return nil
+	}
+	for i := range progress.Tables {
+		droppedTable := &progress.Tables[i]
+		if droppedTable.Status != jobspb.SchemaChangeGCProgress_WAITING_FOR_GC ||
+			droppedTable.DeletedWithRangeTombstones {
+			continue
+		}
+
+		var table catalog.TableDescriptor
+		if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+			var err error
+			table, err = catalogkv.MustGetTableDescByID(ctx, txn, execCfg.Codec, droppedTable.ID)
+			return err
+		}); err != nil {
+			if errors.Is(err, catalog.ErrDescriptorNotFound) {
+				// The table was already GC'ed, which gcTables accounts for.
+				continue
+			}
+			return errors.Wrapf(err, "fetching table %d", droppedTable.ID)
+		}
+		// The span of an interleaved table contains the data of other tables.
+		if !table.Dropped() || table.IsInterleaved() {
+			continue
+		}
+
+		if err := DeleteTableDataWithRangeTombstones(ctx, execCfg.DB, execCfg.Codec, table); err != nil {
+			return errors.Wrapf(err, "deleting data for table %d", table.GetID())
+		}
+		droppedTable.DeletedWithRangeTombstones = true
+	}
+	return nil
+}
+
+// DeleteTableDataWithRangeTombstones deletes all of the data in the specified
+// table at the current time by writing MVCC range tombstones. Unlike
+// ClearTableData, this preserves the history of the data below the deletion.
+// The table must not be interleaved, and the cluster version
+// MVCCRangeTombstones must be active.
+func DeleteTableDataWithRangeTombstones(
+	ctx context.Context, db *kv.DB, codec keys.SQLCodec, table catalog.TableDescriptor,
+) error {
+	log.Infof(ctx, "deleting data for table %d with range tombstones", table.GetID())
+	tableKey := codec.TablePrefix(uint32(table.GetID()))
+
+	// The request is not transactional, so DistSender sends it to every range
+	// in the table's span.
+	var b kv.Batch
+	b.AddRawRequest(&roachpb.DeleteRangeRequest{
+		RequestHeader: roachpb.RequestHeader{
+			Key:    tableKey,
+			EndKey: tableKey.PrefixEnd(),
+		},
+		UseRangeTombstone: true,
+	})
+	return db.Run(ctx, &b)
+}
+
 // ClearTableData deletes all of the data in the specified table.
 func ClearTableData(
 	ctx context.Context,
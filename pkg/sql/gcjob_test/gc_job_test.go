@@ -17,6 +17,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/apd/v2"
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -411,3 +412,52 @@ func TestGCResumer(t *testing.T) {
 		require.Error(t, sj.AwaitCompletion(ctx))
 	})
 }
+
+// TestSchemaChangeGCJobDeletesTableWithRangeTombstones tests that the data of
+// a dropped table is deleted with MVCC range tombstones while the GC job waits
+// for the GC TTL, preserving the data's history.
+func TestSchemaChangeGCJobDeletesTableWithRangeTombstones(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, "CREATE DATABASE db")
+	sqlDB.Exec(t, "CREATE TABLE db.foo (k INT PRIMARY KEY)")
+	sqlDB.Exec(t, "INSERT INTO db.foo SELECT generate_series(1, 10)")
+	var tableID descpb.ID
+	sqlDB.QueryRow(t, `SELECT 'db.foo'::REGCLASS::INT`).Scan(&tableID)
+	var beforeDrop string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&beforeDrop)
+	sqlDB.Exec(t, "DROP TABLE db.foo")
+
+	tablePrefix := keys.SystemSQLCodec.TablePrefix(uint32(tableID))
+	testutils.SucceedsSoon(t, func() error {
+		kvs, err := kvDB.Scan(ctx, tablePrefix, tablePrefix.PrefixEnd(), 0 /* maxRows */)
+		if err != nil {
+			return err
+		}
+		if len(kvs) != 0 {
+			return errors.Errorf("expected table data to be deleted, found %d keys", len(kvs))
+		}
+		return nil
+	})
+
+	// The data is still there for reads below the deletion.
+	dec, _, err := apd.NewFromString(beforeDrop)
+	require.NoError(t, err)
+	ts, err := tree.DecimalToHLC(dec)
+	require.NoError(t, err)
+	require.NoError(t, kvDB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		txn.SetFixedTimestamp(ctx, ts)
+		kvs, err := txn.Scan(ctx, tablePrefix, tablePrefix.PrefixEnd(), 0 /* maxRows */)
+		if err != nil {
+			return err
+		}
+		require.Len(t, kvs, 10)
+		return nil
+	}))
+}
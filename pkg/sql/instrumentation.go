@@ -235,19 +235,7 @@ func (ih *instrumentationHelper) Finish(
 		if err != nil {
 			log.VInfof(ctx, 1, "error getting query level stats for statement %s: %+v", ast, err)
 		} else {
-			stmtStats.mu.Lock()
-			stmtStats.mu.data.ExecStatCollectionCount++
-			// Record trace-related statistics.
-			stmtStats.mu.data.BytesSentOverNetwork.Record(
-				stmtStats.mu.data.ExecStatCollectionCount, float64(queryLevelStats.NetworkBytesSent),
-			)
-			stmtStats.mu.data.MaxMemUsage.Record(
-				stmtStats.mu.data.ExecStatCollectionCount, float64(queryLevelStats.MaxMemUsage),
-			)
-			stmtStats.mu.data.ContentionTime.Record(
-				stmtStats.mu.data.ExecStatCollectionCount, queryLevelStats.ContentionTime.Seconds(),
-			)
-			stmtStats.mu.Unlock()
+			appStats.recordExecStats(ih.fingerprint, ih.implicitTxn, retErr, &queryLevelStats)
 		}
 	}
 
@@ -0,0 +1,754 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sort"
+	"time"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgrepl"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/fsm"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/lib/pq/oid"
+)
+
+// This file implements START_REPLICATION, which streams the changes made to
+// the tables of a set of publications using the pgoutput logical replication
+// protocol.
+//
+// Changes are read from rangefeeds over the primary indexes of the published
+// tables. The position in the stream (the LSN) is the wall time of the MVCC
+// timestamp of the changes, and all the changes sharing a wall time are
+// streamed as a single transaction. A transaction is only streamed once the
+// rangefeeds have resolved its timestamp, so transactions are streamed in
+// order and a client acknowledging a position has seen all the changes up to
+// it. The position acknowledged by the client is persisted in the replication
+// slot, from which the stream resumes on the next START_REPLICATION.
+
+// replicationKeepaliveInterval is the interval at which keepalive messages are
+// sent to the client when the stream is idle.
+const replicationKeepaliveInterval = 10 * time.Second
+
+// replicationSlotPersistInterval is the minimum interval between two updates
+// of the position of a replication slot.
+const replicationSlotPersistInterval = time.Second
+
+// replicationBufferSize is the maximum size of the changes buffered by a
+// replication stream while waiting for their timestamp to be resolved.
+var replicationBufferSize = settings.RegisterByteSizeSetting(
+	"sql.logical_replication.buffer_size",
+	"the maximum size of the changes buffered by a logical replication stream "+
+		"until they can be sent; a stream exceeding it fails and has to be restarted",
+	64<<20, /* 64 MiB */
+).WithPublic()
+
+// errReplicationStopped is returned from the stream loop when the client ends
+// the stream.
+var errReplicationStopped = errors.New("replication stopped")
+
+// execStartReplication runs the START_REPLICATION command. Errors occurring
+// before the stream starts are reported to the client as query errors, after
+// which the connection remains usable. Once the stream has started, errors
+// are sent to the client directly and end the session, like in Postgres.
+func (ex *connExecutor) execStartReplication(
+	ctx context.Context, cmd StartReplication,
+) (fsm.Event, fsm.EventPayload, error) {
+	// When we're done, hand the connection back to the network goroutine.
+	defer cmd.Feedback.Finish()
+
+	if _, isNoTxn := ex.machine.CurState().(stateNoTxn); !isNoTxn {
+		ev := eventNonRetriableErr{IsCommit: fsm.False}
+		payload := eventNonRetriableErrPayload{
+			err: pgerror.New(pgcode.ActiveSQLTransaction,
+				"START_REPLICATION cannot run inside a transaction block")}
+		return ev, payload, nil
+	}
+
+	s, err := newLogicalReplicationStream(
+		ctx, ex.server.cfg, ex.sessionData, &ex.memMetrics, ex.sessionMon, cmd,
+	)
+	if err != nil {
+		ev := eventNonRetriableErr{IsCommit: fsm.False}
+		payload := eventNonRetriableErrPayload{err: err}
+		return ev, payload, nil
+	}
+	if err := s.run(ctx); err != nil {
+		log.Warningf(ctx, "replication from slot %s failed: %v", s.slot, err)
+		_ = cmd.Conn.SendError(ctx, err)
+		return nil, nil, err
+	}
+	return nil, nil, nil
+}
+
+// replicatedTable is a table streamed by a logicalReplicationStream.
+type replicatedTable struct {
+	schemaName string
+	// version is the version of the descriptor last described to the client
+	// with a Relation message, or 0 if none was sent.
+	version descpb.DescriptorVersion
+}
+
+// replicatedChange is a change which has been received from a rangefeed but
+// not yet streamed.
+type replicatedChange struct {
+	kv      roachpb.KeyValue
+	prevVal roachpb.Value
+}
+
+const replicatedChangeOverhead = int64(unsafe.Sizeof(replicatedChange{}))
+
+// memUsage returns the memory accounted for a buffered change.
+func (c *replicatedChange) memUsage() int64 {
+	return replicatedChangeOverhead +
+		int64(len(c.kv.Key)+len(c.kv.Value.RawBytes)+len(c.prevVal.RawBytes))
+}
+
+type fetcherKey struct {
+	id      descpb.ID
+	version descpb.DescriptorVersion
+}
+
+type logicalReplicationStream struct {
+	execCfg  *ExecutorConfig
+	conn     pgwirebase.ReplicationConn
+	feedback *pgrepl.Feedback
+
+	slot   string
+	tables map[descpb.ID]*replicatedTable
+	spans  []roachpb.Span
+	// start is the position from which changes are streamed: only changes
+	// after it are sent.
+	start pgrepl.LSN
+
+	// sent is the position up to which all changes have been sent.
+	sent pgrepl.LSN
+	// persisted is the position last persisted in the replication slot.
+	persisted     pgrepl.LSN
+	lastPersisted time.Time
+	// xid identifies the transactions sent to the client.
+	xid uint32
+
+	// pending are the changes received but not sent yet. They are accounted
+	// for in pendingAcc, against a monitor bounded by the buffer size setting
+	// which inherits from parentMon.
+	pending    []replicatedChange
+	parentMon  *mon.BytesMonitor
+	pendingAcc mon.BoundAccount
+
+	enc      *pgrepl.Encoder
+	buf      []byte
+	alloc    rowenc.DatumAlloc
+	kvs      row.SpanKVFetcher
+	fetchers map[fetcherKey]*row.Fetcher
+	// collection is used to retrieve descriptors with hydrated types.
+	collection *descs.Collection
+}
+
+// newLogicalReplicationStream validates the START_REPLICATION command and
+// resolves the tables to stream.
+func newLogicalReplicationStream(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	sd *sessiondata.SessionData,
+	memMetrics *MemoryMetrics,
+	parentMon *mon.BytesMonitor,
+	cmd StartReplication,
+) (*logicalReplicationStream, error) {
+	if err := checkPublicationsSupported(ctx, execCfg); err != nil {
+		return nil, err
+	}
+	if execCfg.DistSender == nil {
+		return nil, pgerror.New(pgcode.FeatureNotSupported,
+			"logical replication is not supported by this server")
+	}
+	// Logical replication is based on rangefeeds, like changefeeds.
+	if !kvserver.RangefeedEnabled.Get(&execCfg.Settings.SV) {
+		return nil, pgerror.New(pgcode.ObjectNotInPrerequisiteState,
+			"logical replication requires the kv.rangefeed.enabled setting")
+	}
+	opts, err := pgrepl.ParseOptions(cmd.Stmt.Options)
+	if err != nil {
+		return nil, err
+	}
+	s := &logicalReplicationStream{
+		execCfg:    execCfg,
+		conn:       cmd.Conn,
+		feedback:   cmd.Feedback,
+		slot:       string(cmd.Stmt.Slot),
+		tables:     make(map[descpb.ID]*replicatedTable),
+		enc:        pgrepl.NewEncoder(),
+		fetchers:   make(map[fetcherKey]*row.Fetcher),
+		collection: descs.NewCollection(execCfg.Settings, execCfg.LeaseManager, execCfg.HydratedTables),
+		parentMon:  parentMon,
+	}
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		p, cleanup := newInternalPlanner(
+			"start-replication", txn, sd.User(), memMetrics, execCfg, sd.SessionData,
+		)
+		defer cleanup()
+		return s.resolve(ctx, p, sd.Database, cmd.Stmt, opts)
+	}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// resolve loads the replication slot and the tables of the publications of
+// the given database.
+func (s *logicalReplicationStream) resolve(
+	ctx context.Context,
+	p *planner,
+	dbName string,
+	n *tree.StartReplication,
+	opts pgrepl.Options,
+) error {
+	if err := checkReplicationPrivilege(ctx, p); err != nil {
+		return err
+	}
+	// The internal planner doesn't use the session's database.
+	if dbName == "" {
+		return pgerror.New(pgcode.UndefinedDatabase,
+			"logical replication requires a current database")
+	}
+	_, dbDesc, err := p.Descriptors().GetImmutableDatabaseByName(
+		ctx, p.txn, dbName, tree.DatabaseLookupFlags{Required: true})
+	if err != nil {
+		return err
+	}
+
+	ie := p.ExecCfg().InternalExecutor
+	row, err := ie.QueryRowEx(ctx, "get-replication-slot", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT database_id, confirmed_flush_lsn FROM system.replication_slots WHERE name = $1`,
+		s.slot,
+	)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return pgerror.Newf(pgcode.UndefinedObject, "replication slot %q does not exist", s.slot)
+	}
+	if descpb.ID(tree.MustBeDInt(row[0])) != dbDesc.GetID() {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"replication slot %q was not created in this database", s.slot)
+	}
+	// Like in Postgres, a position before the one confirmed by the client is
+	// ignored.
+	s.start = pgrepl.LSN(tree.MustBeDInt(row[1]))
+	if n.LSN > uint64(s.start) {
+		s.start = pgrepl.LSN(n.LSN)
+	}
+	s.sent, s.persisted = s.start, s.start
+
+	var tableIDs []descpb.ID
+	var allTables bool
+	for _, pub := range opts.Publications {
+		row, err := ie.QueryRowEx(ctx, "get-publication", p.txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`SELECT all_tables, table_ids FROM system.publications WHERE database_id = $1 AND name = $2`,
+			dbDesc.GetID(), pub,
+		)
+		if err != nil {
+			return err
+		}
+		if row == nil {
+			return pgerror.Newf(pgcode.UndefinedObject, "publication %q does not exist", pub)
+		}
+		if tree.MustBeDBool(row[0]) {
+			allTables = true
+			continue
+		}
+		if row[1] != tree.DNull {
+			for _, id := range tree.MustBeDArray(row[1]).Array {
+				tableIDs = append(tableIDs, descpb.ID(tree.MustBeDInt(id)))
+			}
+		}
+	}
+
+	var tables []catalog.TableDescriptor
+	if allTables {
+		all, err := catalogkv.GetAllDescriptors(ctx, p.txn, p.ExecCfg().Codec)
+		if err != nil {
+			return err
+		}
+		for _, desc := range all {
+			table, ok := desc.(catalog.TableDescriptor)
+			if !ok || table.GetParentID() != dbDesc.GetID() || table.Dropped() || table.Offline() {
+				continue
+			}
+			// Publications for all tables skip the tables which can't be streamed.
+			if checkPublicationTable(table) != nil {
+				continue
+			}
+			tables = append(tables, table)
+		}
+	} else {
+		for _, id := range tableIDs {
+			table, err := p.Descriptors().GetImmutableTableByID(ctx, p.txn, id, tree.ObjectLookupFlags{})
+			if err != nil {
+				if errors.Is(err, catalog.ErrDescriptorNotFound) ||
+					errors.Is(err, catalog.ErrDescriptorDropped) ||
+					pgerror.GetPGCode(err) == pgcode.UndefinedTable {
+					// The table has been dropped since it was published.
+					continue
+				}
+				return err
+			}
+			if err := checkPublicationTable(table); err != nil {
+				return err
+			}
+			tables = append(tables, table)
+		}
+	}
+
+	for _, table := range tables {
+		if _, ok := s.tables[table.GetID()]; ok {
+			continue
+		}
+		if err := p.CheckPrivilege(ctx, table, privilege.SELECT); err != nil {
+			return err
+		}
+		schemaName, err := resolver.ResolveSchemaNameByID(
+			ctx, p.txn, p.ExecCfg().Codec, dbDesc.GetID(), table.GetParentSchemaID())
+		if err != nil {
+			return err
+		}
+		s.tables[table.GetID()] = &replicatedTable{schemaName: schemaName}
+		s.spans = append(s.spans, table.PrimaryIndexSpan(p.ExecCfg().Codec))
+	}
+	return nil
+}
+
+// run streams changes until the client ends the stream.
+func (s *logicalReplicationStream) run(ctx context.Context) error {
+	if err := s.conn.BeginCopyBoth(); err != nil {
+		return err
+	}
+	s.feedback.Start()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer s.collection.ReleaseAll(ctx)
+	pendingMon := mon.NewMonitorInheritWithLimit(
+		"logical-replication", replicationBufferSize.Get(&s.execCfg.Settings.SV), s.parentMon)
+	pendingMon.Start(ctx, s.parentMon, mon.BoundAccount{})
+	defer pendingMon.Stop(ctx)
+	s.pendingAcc = pendingMon.MakeBoundAccount()
+	defer s.pendingAcc.Close(ctx)
+
+	eventCh := make(chan *roachpb.RangeFeedEvent, 128)
+	g := ctxgroup.WithContext(ctx)
+	for _, sp := range s.spans {
+		sp := sp
+		g.GoCtx(func(ctx context.Context) error {
+			return s.execCfg.DistSender.RangeFeed(
+				ctx, sp, s.start.Timestamp(), true /* withDiff */, eventCh)
+		})
+	}
+	g.GoCtx(func(ctx context.Context) error {
+		return s.loop(ctx, eventCh)
+	})
+	err := g.Wait()
+	if !errors.Is(err, errReplicationStopped) {
+		return err
+	}
+	if err := s.conn.SendCopyDone(); err != nil {
+		return err
+	}
+	return s.conn.SendCommandComplete([]byte("START_REPLICATION"))
+}
+
+func (s *logicalReplicationStream) loop(
+	ctx context.Context, eventCh <-chan *roachpb.RangeFeedEvent,
+) error {
+	frontier := span.MakeFrontier(s.spans...)
+	for _, sp := range s.spans {
+		frontier.Forward(sp, s.start.Timestamp())
+	}
+	keepalive := time.NewTicker(replicationKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case ev := <-eventCh:
+			switch t := ev.GetValue().(type) {
+			case *roachpb.RangeFeedValue:
+				// Changes in the transactions up to the sent position have been
+				// streamed already. Besides the transaction at the start position,
+				// this covers the changes emitted again when a rangefeed is
+				// restarted from an earlier resolved timestamp.
+				if pgrepl.LSNFromTimestamp(t.Value.Timestamp) <= s.sent {
+					continue
+				}
+				if err := s.buffer(ctx, replicatedChange{
+					kv:      roachpb.KeyValue{Key: t.Key, Value: t.Value},
+					prevVal: t.PrevValue,
+				}); err != nil {
+					return err
+				}
+			case *roachpb.RangeFeedCheckpoint:
+				if !frontier.Forward(t.Span, t.ResolvedTS) {
+					continue
+				}
+				if err := s.flush(ctx, frontier.Frontier()); err != nil {
+					return err
+				}
+			case *roachpb.RangeFeedError:
+				return t.Error.GoError()
+			case *roachpb.RangeFeedDeleteRange:
+				// Range deletions only delete the data of dropped tables, which,
+				// as in PostgreSQL, isn't replicated, and the data ingested by a
+				// rolled back IMPORT into an empty table, which rangefeeds don't
+				// emit either.
+				continue
+			}
+		case <-keepalive.C:
+			if err := s.conn.SendCopyData(pgrepl.Keepalive(s.buf[:0], s.sent, false)); err != nil {
+				return err
+			}
+		case <-s.feedback.Updated():
+			flushed, replyRequested := s.feedback.Status()
+			if replyRequested {
+				if err := s.conn.SendCopyData(pgrepl.Keepalive(s.buf[:0], s.sent, false)); err != nil {
+					return err
+				}
+			}
+			if timeutil.Since(s.lastPersisted) >= replicationSlotPersistInterval {
+				if err := s.persist(ctx, flushed); err != nil {
+					return err
+				}
+			}
+		case <-s.feedback.Stopped():
+			flushed, _ := s.feedback.Status()
+			if err := s.persist(ctx, flushed); err != nil {
+				return err
+			}
+			return errReplicationStopped
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// buffer adds a change to the pending changes. Changes can only be sent once
+// their timestamp is resolved, which requires all the rangefeeds to make
+// progress, so the stream fails rather than waits if the changes outgrow the
+// buffer. The client can then restart the stream from its replication slot.
+// Note that the size of eventCh bounds the changes that are received but not
+// buffered, which pushes back on the rangefeeds while changes are sent.
+func (s *logicalReplicationStream) buffer(ctx context.Context, c replicatedChange) error {
+	if err := s.pendingAcc.Grow(ctx, c.memUsage()); err != nil {
+		return errors.WithHint(
+			errors.Wrapf(err, "buffering changes for replication slot %s", s.slot),
+			"The changes of a transaction cannot be sent until its timestamp is resolved. "+
+				"Consider increasing the sql.logical_replication.buffer_size cluster setting.")
+	}
+	s.pending = append(s.pending, c)
+	return nil
+}
+
+// flush streams the pending changes which can no longer be followed by other
+// changes in the same transaction, given the resolved timestamp.
+func (s *logicalReplicationStream) flush(ctx context.Context, resolved hlc.Timestamp) error {
+	// A change at the resolved wall time may still be followed by changes with
+	// a higher logical timestamp.
+	if resolved.WallTime <= int64(s.sent)+1 {
+		return nil
+	}
+	end := pgrepl.LSN(resolved.WallTime - 1)
+	sort.Slice(s.pending, func(i, j int) bool {
+		a, b := s.pending[i].kv, s.pending[j].kv
+		if a.Value.Timestamp != b.Value.Timestamp {
+			return a.Value.Timestamp.Less(b.Value.Timestamp)
+		}
+		return a.Key.Compare(b.Key) < 0
+	})
+	n := sort.Search(len(s.pending), func(i int) bool {
+		return pgrepl.LSNFromTimestamp(s.pending[i].kv.Value.Timestamp) > end
+	})
+	var sentBytes int64
+	for i := 0; i < n; {
+		lsn := pgrepl.LSNFromTimestamp(s.pending[i].kv.Value.Timestamp)
+		j := i + 1
+		for j < n && pgrepl.LSNFromTimestamp(s.pending[j].kv.Value.Timestamp) == lsn {
+			j++
+		}
+		if err := s.sendTxn(ctx, lsn, s.pending[i:j]); err != nil {
+			return err
+		}
+		for ; i < j; i++ {
+			sentBytes += s.pending[i].memUsage()
+		}
+	}
+	// Clear the sent changes so that their memory can be reclaimed.
+	tail := copy(s.pending, s.pending[n:])
+	for i := tail; i < len(s.pending); i++ {
+		s.pending[i] = replicatedChange{}
+	}
+	s.pending = s.pending[:tail]
+	s.pendingAcc.Shrink(ctx, sentBytes)
+	s.sent = end
+	return nil
+}
+
+// sendTxn streams the changes of a transaction.
+func (s *logicalReplicationStream) sendTxn(
+	ctx context.Context, lsn pgrepl.LSN, changes []replicatedChange,
+) error {
+	s.xid++
+	commitTime := timeutil.Unix(0, int64(lsn))
+	if err := s.send(lsn, s.enc.Begin(lsn, commitTime, s.xid)); err != nil {
+		return err
+	}
+	for i := range changes {
+		if err := s.sendChange(ctx, lsn, &changes[i]); err != nil {
+			return err
+		}
+	}
+	return s.send(lsn, s.enc.Commit(lsn, lsn, commitTime))
+}
+
+func (s *logicalReplicationStream) sendChange(
+	ctx context.Context, lsn pgrepl.LSN, c *replicatedChange,
+) error {
+	ts := c.kv.Value.Timestamp
+	desc, datums, err := s.decode(ctx, c.kv, ts)
+	if err != nil {
+		return err
+	}
+	if desc == nil {
+		return nil
+	}
+	var prev tree.Datums
+	if c.prevVal.IsPresent() {
+		// The previous row is decoded with the schema of the change so that it
+		// matches the relation described to the client.
+		prevKV := roachpb.KeyValue{Key: c.kv.Key, Value: c.prevVal}
+		if _, prev, err = s.decode(ctx, prevKV, ts); err != nil {
+			return err
+		}
+	}
+	if datums == nil && prev == nil {
+		// The row didn't exist before; there is nothing to delete.
+		return nil
+	}
+
+	table := s.tables[desc.GetID()]
+	relID := oid.Oid(desc.GetID())
+	if table.version != desc.GetVersion() {
+		cols := desc.GetPublicColumns()
+		pgCols := make([]pgrepl.Column, len(cols))
+		primaryIndex := desc.GetPrimaryIndex()
+		for i := range cols {
+			pgCols[i] = pgrepl.Column{
+				Name:    cols[i].Name,
+				TypeOID: cols[i].Type.Oid(),
+				TypeMod: cols[i].Type.TypeModifier(),
+			}
+			for j := 0; j < primaryIndex.NumColumns(); j++ {
+				if primaryIndex.GetColumnID(j) == cols[i].ID {
+					pgCols[i].Key = true
+				}
+			}
+		}
+		if err := s.send(lsn, s.enc.Relation(relID, table.schemaName, desc.GetName(), pgCols)); err != nil {
+			return err
+		}
+		table.version = desc.GetVersion()
+	}
+
+	switch {
+	case datums == nil:
+		return s.send(lsn, s.enc.Delete(relID, prev))
+	case prev == nil:
+		return s.send(lsn, s.enc.Insert(relID, datums))
+	default:
+		return s.send(lsn, s.enc.Update(relID, prev, datums))
+	}
+}
+
+// send sends a message of the pgoutput protocol.
+func (s *logicalReplicationStream) send(lsn pgrepl.LSN, msg []byte) error {
+	s.buf = pgrepl.XLogData(s.buf[:0], lsn, lsn, msg)
+	return s.conn.SendCopyData(s.buf)
+}
+
+// decode decodes the row stored in a key-value pair, using the schema in
+// effect at the given timestamp. It returns a nil descriptor if the key
+// doesn't belong to a streamed table, and nil datums if the row is deleted.
+func (s *logicalReplicationStream) decode(
+	ctx context.Context, kv roachpb.KeyValue, ts hlc.Timestamp,
+) (catalog.TableDescriptor, tree.Datums, error) {
+	codec := s.execCfg.Codec
+	key, err := codec.StripTenantPrefix(kv.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, tableID, _, err := rowenc.DecodePartialTableIDIndexID(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := s.tables[tableID]; !ok {
+		return nil, nil, nil
+	}
+	desc, err := s.tableDescAt(ctx, tableID, ts)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The table was checked when the stream started, but its schema may have
+	// changed since in ways that prevent decoding its changes, for instance by
+	// adding a column family.
+	if err := checkPublicationTable(desc); err != nil {
+		return nil, nil, err
+	}
+
+	rf, err := s.fetcher(desc)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.kvs.KVs = append(s.kvs.KVs[:0], kv)
+	if err := rf.StartScanFrom(ctx, &s.kvs); err != nil {
+		return nil, nil, err
+	}
+	datums, _, _, err := rf.NextRowDecoded(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if datums == nil {
+		return nil, nil, errors.AssertionFailedf("unexpected empty datums")
+	}
+	if rf.RowIsDeleted() {
+		return desc, nil, nil
+	}
+	return desc, append(tree.Datums(nil), datums...), nil
+}
+
+// tableDescAt retrieves the descriptor of a table at a timestamp.
+func (s *logicalReplicationStream) tableDescAt(
+	ctx context.Context, id descpb.ID, ts hlc.Timestamp,
+) (catalog.TableDescriptor, error) {
+	leaseMgr := s.execCfg.LeaseManager
+	desc, _, err := leaseMgr.Acquire(ctx, ts, id)
+	if err != nil {
+		return nil, err
+	}
+	// Immediately release the lease, since we only need it for the exact
+	// timestamp requested.
+	if err := leaseMgr.Release(desc); err != nil {
+		return nil, err
+	}
+	tableDesc := desc.(catalog.TableDescriptor)
+	if !tableDesc.ContainsUserDefinedTypes() {
+		return tableDesc, nil
+	}
+	// Tables with user defined types are retrieved from a descs.Collection
+	// to hydrate the types. The transaction only provides the read timestamp.
+	if err := s.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		txn.SetFixedTimestamp(ctx, ts)
+		var err error
+		tableDesc, err = s.collection.GetImmutableTableByID(ctx, txn, id, tree.ObjectLookupFlags{})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	s.collection.ReleaseAll(ctx)
+	return tableDesc, nil
+}
+
+// fetcher returns a row.Fetcher decoding the primary index of a table.
+func (s *logicalReplicationStream) fetcher(desc catalog.TableDescriptor) (*row.Fetcher, error) {
+	key := fetcherKey{id: desc.GetID(), version: desc.GetVersion()}
+	if rf, ok := s.fetchers[key]; ok &&
+		desc.UserDefinedTypeColsHaveSameVersion(rf.GetTables()[0].(catalog.TableDescriptor)) {
+		return rf, nil
+	}
+	var colIdxMap catalog.TableColMap
+	var valNeededForCol util.FastIntSet
+	cols := desc.GetPublicColumns()
+	for colIdx := range cols {
+		colIdxMap.Set(cols[colIdx].ID, colIdx)
+		valNeededForCol.Add(colIdx)
+	}
+	var rf row.Fetcher
+	if err := rf.Init(
+		context.TODO(),
+		s.execCfg.Codec,
+		false, /* reverse */
+		descpb.ScanLockingStrength_FOR_NONE,
+		descpb.ScanLockingWaitPolicy_BLOCK,
+		false, /* isCheck */
+		&s.alloc,
+		nil, /* memMonitor */
+		row.FetcherTableArgs{
+			Spans:            desc.AllIndexSpans(s.execCfg.Codec),
+			Desc:             desc,
+			Index:            desc.GetPrimaryIndex().IndexDesc(),
+			ColIdxMap:        colIdxMap,
+			IsSecondaryIndex: false,
+			Cols:             cols,
+			ValNeededForCol:  valNeededForCol,
+		},
+	); err != nil {
+		return nil, err
+	}
+	s.fetchers[key] = &rf
+	return &rf, nil
+}
+
+// persist records the position acknowledged by the client in the replication
+// slot. Positions beyond what has been sent are not persisted: clients may
+// acknowledge positions past the ones they received.
+func (s *logicalReplicationStream) persist(ctx context.Context, flushed pgrepl.LSN) error {
+	if flushed > s.sent {
+		flushed = s.sent
+	}
+	if flushed <= s.persisted {
+		return nil
+	}
+	if _, err := s.execCfg.InternalExecutor.ExecEx(ctx, "advance-replication-slot", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`UPDATE system.replication_slots SET confirmed_flush_lsn = $2
+		 WHERE name = $1 AND confirmed_flush_lsn < $2`,
+		s.slot, int64(flushed),
+	); err != nil {
+		return err
+	}
+	s.persisted = flushed
+	s.lastPersisted = timeutil.Now()
+	return nil
+}
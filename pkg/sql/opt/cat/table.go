@@ -46,6 +46,10 @@ type Table interface {
 	// that they cannot be mutated.
 	IsMaterializedView() bool
 
+	// IsForeignTable returns true if this table is a read-only table whose rows
+	// are read from external files rather than stored in the database.
+	IsForeignTable() bool
+
 	// ColumnCount returns the number of columns in the table. This includes
 	// public columns, write-only columns, etc.
 	ColumnCount() int
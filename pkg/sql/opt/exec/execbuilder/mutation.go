@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/errors"
 )
@@ -1044,7 +1045,17 @@ var forUpdateLocking = &tree.LockingItem{Strength: tree.ForUpdate}
 // shouldApplyImplicitLockingToMutationInput determines whether or not the
 // builder should apply a FOR UPDATE row-level locking mode to the initial row
 // scan of a mutation expression.
+//
+// In READ COMMITTED transactions, the input rows of all UPDATE, UPSERT and
+// DELETE statements are locked, whatever the shape of the input. Such
+// transactions read from a new snapshot in each statement and don't validate
+// their reads when they commit, so locking the rows a statement reads before
+// writing them is what prevents concurrent transactions from modifying those
+// rows in between, which would otherwise force the statement to be retried.
 func (b *Builder) shouldApplyImplicitLockingToMutationInput(mutExpr memo.RelExpr) bool {
+	if _, ok := mutExpr.(*memo.InsertExpr); !ok && b.readCommitted() {
+		return true
+	}
 	switch t := mutExpr.(type) {
 	case *memo.InsertExpr:
 		// Unlike with the other three mutation expressions, it never makes
@@ -1090,7 +1101,7 @@ func (b *Builder) shouldApplyImplicitLockingToMutationInput(mutExpr memo.RelExpr
 // not worth risking the transformation being a pessimization, so it is only
 // applied when doing so does not risk creating artificial contention.
 func (b *Builder) shouldApplyImplicitLockingToUpdateInput(upd *memo.UpdateExpr) bool {
-	if !b.evalCtx.SessionData.ImplicitSelectForUpdate {
+	if !b.implicitLockingEnabled() {
 		return false
 	}
 
@@ -1109,14 +1120,11 @@ func (b *Builder) shouldApplyImplicitLockingToUpdateInput(upd *memo.UpdateExpr)
 	return ok
 }
 
-// tryApplyImplicitLockingToUpsertInput determines whether or not the builder
+// shouldApplyImplicitLockingToUpsertInput determines whether or not the builder
 // should apply a FOR UPDATE row-level locking mode to the initial row scan of
-// an UPSERT statement.
-//
-// TODO(nvanbenschoten): implement this method to match on appropriate Upsert
-// expression trees and apply a row-level locking mode.
+// an UPSERT statement. The same considerations as for UPDATE statements apply.
 func (b *Builder) shouldApplyImplicitLockingToUpsertInput(ups *memo.UpsertExpr) bool {
-	if !b.evalCtx.SessionData.ImplicitSelectForUpdate {
+	if !b.implicitLockingEnabled() {
 		return false
 	}
 
@@ -1148,12 +1156,25 @@ func (b *Builder) shouldApplyImplicitLockingToUpsertInput(ups *memo.UpsertExpr)
 	return ok
 }
 
-// tryApplyImplicitLockingToDeleteInput determines whether or not the builder
+// shouldApplyImplicitLockingToDeleteInput determines whether or not the builder
 // should apply a FOR UPDATE row-level locking mode to the initial row scan of
-// an DELETE statement.
+// a DELETE statement outside of READ COMMITTED transactions.
 //
 // TODO(nvanbenschoten): implement this method to match on appropriate Delete
 // expression trees and apply a row-level locking mode.
 func (b *Builder) shouldApplyImplicitLockingToDeleteInput(del *memo.DeleteExpr) bool {
 	return false
 }
+
+// implicitLockingEnabled returns whether mutations should apply a FOR UPDATE
+// row-level locking mode to their initial row scan when its shape makes it
+// worthwhile as a performance optimization.
+func (b *Builder) implicitLockingEnabled() bool {
+	return b.evalCtx.SessionData.ImplicitSelectForUpdate
+}
+
+// readCommitted returns whether the statement runs in a READ COMMITTED
+// transaction.
+func (b *Builder) readCommitted() bool {
+	return b.evalCtx.Txn != nil && b.evalCtx.Txn.IsolationLevel() == enginepb.READ_COMMITTED
+}
@@ -458,6 +458,25 @@ func (jf JoinFlags) Has(flag JoinFlags) bool {
 	return jf&flag != 0
 }
 
+// Commute returns the flags for the commuted join (where the left and right
+// sides are swapped). Flags that are specific to one side are swapped.
+func (jf JoinFlags) Commute() JoinFlags {
+	// swap is a helper function which swaps the values of two (single-bit) flags.
+	swap := func(f, a, b JoinFlags) JoinFlags {
+		// If the bits are different, flip them both.
+		if f.Has(a) != f.Has(b) {
+			f ^= (a | b)
+		}
+		return f
+	}
+	f := jf
+	f = swap(f, DisallowLookupJoinIntoLeft, DisallowLookupJoinIntoRight)
+	f = swap(f, DisallowInvertedJoinIntoLeft, DisallowInvertedJoinIntoRight)
+	f = swap(f, DisallowHashJoinStoreLeft, DisallowHashJoinStoreRight)
+	f = swap(f, PreferLookupJoinIntoLeft, PreferLookupJoinIntoRight)
+	return f
+}
+
 func (jf JoinFlags) String() string {
 	if jf.Empty() {
 		return "no flags"
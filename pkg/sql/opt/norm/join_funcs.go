@@ -570,19 +570,7 @@ func (c *CustomFuncs) CommuteJoinFlags(p *memo.JoinPrivate) *memo.JoinPrivate {
 		return p
 	}
 
-	// swap is a helper function which swaps the values of two (single-bit) flags.
-	swap := func(f, a, b memo.JoinFlags) memo.JoinFlags {
-		// If the bits are different, flip them both.
-		if f.Has(a) != f.Has(b) {
-			f ^= (a | b)
-		}
-		return f
-	}
-	f := p.Flags
-	f = swap(f, memo.DisallowLookupJoinIntoLeft, memo.DisallowLookupJoinIntoRight)
-	f = swap(f, memo.DisallowInvertedJoinIntoLeft, memo.DisallowInvertedJoinIntoRight)
-	f = swap(f, memo.DisallowHashJoinStoreLeft, memo.DisallowHashJoinStoreRight)
-	f = swap(f, memo.PreferLookupJoinIntoLeft, memo.PreferLookupJoinIntoRight)
+	f := p.Flags.Commute()
 	if p.Flags == f {
 		return p
 	}
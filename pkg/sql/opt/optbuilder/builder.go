@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/optgen/exprgen"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/planhints"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -66,6 +67,16 @@ type Builder struct {
 	// This is used when re-preparing invalidated queries.
 	KeepPlaceholders bool
 
+	// PlanHints is a control knob: if set, the index and join hints of a pinned
+	// plan are applied to the tables and joins of the statement which don't
+	// have inline hints.
+	PlanHints *planhints.StatementHints
+
+	// CaptureJoins is a control knob: if set, the tables on either side of each
+	// join in the statement are recorded in Joins, so that the plan can be
+	// captured with planhints.Capture.
+	CaptureJoins bool
+
 	// -- Results --
 	//
 	// These fields are set during the building process and can be used after
@@ -80,6 +91,10 @@ type Builder struct {
 	// statements.
 	DisableMemoReuse bool
 
+	// Joins contains the sides of the joins in the statement, in the order in
+	// which they were built. It is only populated if CaptureJoins is set.
+	Joins []planhints.JoinSides
+
 	factory *norm.Factory
 	stmt    tree.Statement
 
@@ -127,6 +142,10 @@ type Builder struct {
 	// isCorrelated is set to true if we already reported to telemetry that the
 	// query contains a correlated subquery.
 	isCorrelated bool
+
+	// numJoins is the number of joins built so far. It is used to identify the
+	// joins of the statement in the plan hints.
+	numJoins int
 }
 
 // New creates a new Builder structure initialized with the given
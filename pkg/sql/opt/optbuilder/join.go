@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/planhints"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -34,6 +35,9 @@ import (
 func (b *Builder) buildJoin(
 	join *tree.JoinTableExpr, locking lockingSpec, inScope *scope,
 ) (outScope *scope) {
+	joinOrdinal := b.numJoins
+	b.numJoins++
+
 	leftScope := b.buildDataSource(join.Left, nil /* indexFlags */, locking, inScope)
 
 	isLateral := false
@@ -92,6 +96,20 @@ func (b *Builder) buildJoin(
 			pgcode.FeatureNotSupported, "join hint %s not supported", join.Hint,
 		))
 	}
+	if join.Hint == "" && b.PlanHints != nil {
+		if hintFlags, ok := b.PlanHints.JoinFlags(joinOrdinal); ok {
+			flags = hintFlags
+		}
+	}
+	if b.CaptureJoins {
+		if len(b.Joins) <= joinOrdinal {
+			b.Joins = append(b.Joins, make([]planhints.JoinSides, joinOrdinal+1-len(b.Joins))...)
+		}
+		b.Joins[joinOrdinal] = planhints.JoinSides{
+			Left:  planhints.Tables(leftScope.expr),
+			Right: planhints.Tables(rightScope.expr),
+		}
+	}
 
 	switch cond := join.Cond.(type) {
 	case tree.NaturalJoinCond, *tree.UsingJoinCond:
@@ -419,6 +419,23 @@ func (b *Builder) addTable(tab cat.Table, alias *tree.TableName) *opt.TableMeta
 	return md.TableMeta(tabID)
 }
 
+// applyScanHint forces the index of the pinned plan for the table, if there is
+// a hint for it. The hint is ignored if it no longer matches the table.
+func (b *Builder) applyScanHint(tabMeta *opt.TableMeta, flags *memo.ScanFlags) {
+	hint, ok := b.PlanHints.ScanHint(tabMeta.MetaID)
+	if !ok || tabMeta.Table.ID() != cat.StableID(hint.TableDescID) {
+		return
+	}
+	tab := tabMeta.Table
+	for i := 0; i < tab.IndexCount(); i++ {
+		if tab.Index(i).ID() == cat.StableID(hint.IndexID) {
+			flags.ForceIndex = true
+			flags.Index = i
+			return
+		}
+	}
+}
+
 // buildScan builds a memo group for a ScanOp expression on the given table. If
 // the ordinals list contains any VirtualComputed columns, a ProjectOp is built
 // on top.
@@ -500,6 +517,18 @@ func (b *Builder) buildScan(
 		return outScope
 	}
 
+	if tab.IsForeignTable() {
+		// Foreign tables can only be read in full, in primary key order.
+		if indexFlags != nil {
+			panic(pgerror.Newf(pgcode.Syntax,
+				"index flags not allowed with foreign tables"))
+		}
+		if locking.isSet() {
+			panic(pgerror.Newf(pgcode.Syntax,
+				"%s not allowed with foreign tables", locking.get().Strength))
+		}
+	}
+
 	private := memo.ScanPrivate{Table: tabID, Cols: scanColIDs}
 	if indexFlags != nil {
 		private.Flags.NoIndexJoin = indexFlags.NoIndexJoin
@@ -526,6 +555,9 @@ func (b *Builder) buildScan(
 			private.Flags.Direction = indexFlags.Direction
 		}
 	}
+	if indexFlags == nil && b.PlanHints != nil {
+		b.applyScanHint(tabMeta, &private.Flags)
+	}
 	if locking.isSet() {
 		private.Locking = locking.get()
 	}
@@ -1256,17 +1288,21 @@ func (b *Builder) buildFromWithLateral(
 // validateAsOf ensures that any AS OF SYSTEM TIME timestamp is consistent with
 // that of the root statement.
 func (b *Builder) validateAsOf(asOf tree.AsOfClause) {
-	ts, err := tree.EvalAsOfTimestamp(b.ctx, asOf, b.semaCtx, b.evalCtx)
+	var opts []tree.EvalAsOfTimestampOption
+	if b.semaCtx.AsOfSystemTime != nil && b.semaCtx.AsOfSystemTime.BoundedStaleness {
+		opts = append(opts, tree.EvalAsOfTimestampOptionAllowBoundedStaleness)
+	}
+	asOfRet, err := tree.EvalAsOfTimestamp(b.ctx, asOf, b.semaCtx, b.evalCtx, opts...)
 	if err != nil {
 		panic(err)
 	}
 
-	if b.semaCtx.AsOfTimestamp == nil {
+	if b.semaCtx.AsOfSystemTime == nil {
 		panic(pgerror.Newf(pgcode.Syntax,
 			"AS OF SYSTEM TIME must be provided on a top-level statement"))
 	}
 
-	if *b.semaCtx.AsOfTimestamp != ts {
+	if *b.semaCtx.AsOfSystemTime != asOfRet {
 		panic(unimplementedWithIssueDetailf(35712, "",
 			"cannot specify AS OF SYSTEM TIME with different timestamps"))
 	}
@@ -527,6 +527,11 @@ func (b *Builder) resolveTableForMutation(
 		panic(pgerror.Newf(pgcode.WrongObjectType, "cannot mutate materialized view %q", tab.Name()))
 	}
 
+	// Foreign tables are read-only.
+	if tab.IsForeignTable() {
+		panic(pgerror.Newf(pgcode.WrongObjectType, "cannot mutate foreign table %q", tab.Name()))
+	}
+
 	return tab, depName, alias, columns
 }
 
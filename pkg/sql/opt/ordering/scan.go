@@ -79,6 +79,10 @@ func ScanPrivateCanProvide(
 			direction = rev
 		}
 	}
+	if md.Table(s.Table).IsForeignTable() {
+		// Foreign tables can only be scanned in the forward direction.
+		direction = fwd
+	}
 	index := md.Table(s.Table).Index(s.Index)
 	for left, right := 0, 0; right < len(required.Columns); {
 		if left >= index.KeyColumnCount() {
@@ -0,0 +1,227 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package planhints captures the plan chosen by the optimizer for a statement
+// as a set of index and join hints. When the hints are applied to later
+// executions of the statement, the optimizer keeps the index used to read each
+// table and the algorithm and order of the hinted joins, even if the table
+// statistics have changed in the meantime. The order of the joins which the
+// optimizer reordered is not pinned, so it can still change with the table
+// statistics, and so can the algorithms of those joins.
+package planhints
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// JoinSides contains the tables referenced by either side of a join in the
+// statement, as built by optbuilder.
+type JoinSides struct {
+	Left, Right util.FastIntSet
+}
+
+// ScanHint returns the hint for the given table, if there is one.
+func (h *StatementHints) ScanHint(tab opt.TableID) (_ ScanHint, ok bool) {
+	for i := range h.Scans {
+		if h.Scans[i].Table == tab {
+			return h.Scans[i], true
+		}
+	}
+	return ScanHint{}, false
+}
+
+// JoinFlags returns the flags for the join with the given ordinal, if there
+// is a hint for it.
+func (h *StatementHints) JoinFlags(join int) (_ memo.JoinFlags, ok bool) {
+	for i := range h.Joins {
+		if int(h.Joins[i].Join) == join {
+			return memo.JoinFlags(h.Joins[i].Flags), true
+		}
+	}
+	return 0, false
+}
+
+// Tables returns the tables referenced by the expression and its descendants.
+func Tables(e opt.Expr) util.FastIntSet {
+	var res util.FastIntSet
+	var walk func(e opt.Expr)
+	walk = func(e opt.Expr) {
+		switch t := e.(type) {
+		case *memo.ScanExpr:
+			res.Add(int(t.Table))
+		case *memo.IndexJoinExpr:
+			res.Add(int(t.Table))
+		case *memo.LookupJoinExpr:
+			res.Add(int(t.Table))
+		case *memo.InvertedJoinExpr:
+			res.Add(int(t.Table))
+		case *memo.ZigzagJoinExpr:
+			res.Add(int(t.LeftTable))
+			res.Add(int(t.RightTable))
+		}
+		for i, n := 0, e.ChildCount(); i < n; i++ {
+			walk(e.Child(i))
+		}
+	}
+	walk(e)
+	return res
+}
+
+// Capture returns the hints that reproduce the plan in the given optimized
+// memo. The joins are the sides of the joins in the statement, as recorded by
+// optbuilder.
+//
+// An index hint is captured for every table that is read from a single index.
+// An algorithm hint is captured for every join of the statement whose sides
+// are the inputs of a join in the plan. As the optimizer doesn't reorder
+// hinted joins, this also pins their order. Joins which were reordered with
+// other joins are not hinted, and are still reordered based on the table
+// statistics when the hints are applied.
+func Capture(mem *memo.Memo, joins []JoinSides) *StatementHints {
+	c := capturer{md: mem.Metadata(), joins: joins, indexes: make(map[opt.TableID]cat.IndexOrdinal)}
+	c.walk(mem.RootExpr())
+
+	var res StatementHints
+	for _, tabMeta := range c.md.AllTables() {
+		idx, ok := c.indexes[tabMeta.MetaID]
+		if !ok || idx < 0 {
+			continue
+		}
+		res.Scans = append(res.Scans, ScanHint{
+			Table:       tabMeta.MetaID,
+			TableDescID: uint32(tabMeta.Table.ID()),
+			IndexID:     uint32(tabMeta.Table.Index(idx).ID()),
+		})
+	}
+	for i := range joins {
+		if flags, ok := c.joinFlags[i]; ok {
+			res.Joins = append(res.Joins, JoinHint{Join: int32(i), Flags: uint32(flags)})
+		}
+	}
+	return &res
+}
+
+// Conforms returns whether the optimized expression conforms to the join
+// hints it was built with. When a hinted join algorithm cannot be used, the
+// optimizer falls back to a hash join which is disallowed by the join's flags,
+// and which fails to execbuild.
+func Conforms(e opt.Expr) bool {
+	switch e.(type) {
+	case *memo.InnerJoinExpr, *memo.LeftJoinExpr, *memo.RightJoinExpr, *memo.FullJoinExpr,
+		*memo.SemiJoinExpr, *memo.AntiJoinExpr:
+		if e.Private().(*memo.JoinPrivate).Flags.Has(memo.DisallowHashJoinStoreRight) {
+			return false
+		}
+	}
+	for i, n := 0, e.ChildCount(); i < n; i++ {
+		if !Conforms(e.Child(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+type capturer struct {
+	md    *opt.Metadata
+	joins []JoinSides
+
+	// indexes contains the index used for each table. It is -1 for tables that
+	// are read from multiple indexes.
+	indexes map[opt.TableID]cat.IndexOrdinal
+
+	// joinFlags contains the captured flags for each join ordinal.
+	joinFlags map[int]memo.JoinFlags
+}
+
+func (c *capturer) walk(e opt.Expr) {
+	switch t := e.(type) {
+	case *memo.ScanExpr:
+		c.addIndex(t.Table, t.Index)
+
+	case *memo.LookupJoinExpr:
+		c.addIndex(t.Table, t.Index)
+		c.addJoin(t.Input, t.Table, memo.AllowOnlyLookupJoinIntoRight)
+
+	case *memo.InvertedJoinExpr:
+		c.addIndex(t.Table, t.Index)
+		c.addJoin(t.Input, t.Table, memo.AllowOnlyInvertedJoinIntoRight)
+
+	case *memo.ZigzagJoinExpr:
+		// Forcing either index would prevent the zigzag join.
+		c.indexes[t.LeftTable] = -1
+		c.indexes[t.RightTable] = -1
+
+	case *memo.MergeJoinExpr:
+		c.addJoinSides(Tables(t.Left), Tables(t.Right), memo.AllowOnlyMergeJoin)
+
+	case *memo.InnerJoinExpr, *memo.LeftJoinExpr, *memo.RightJoinExpr, *memo.FullJoinExpr,
+		*memo.SemiJoinExpr, *memo.AntiJoinExpr:
+		c.addJoinSides(Tables(t.Child(0)), Tables(t.Child(1)), memo.AllowOnlyHashJoinStoreRight)
+	}
+	for i, n := 0, e.ChildCount(); i < n; i++ {
+		c.walk(e.Child(i))
+	}
+}
+
+// addIndex records that the given index of the table is used by the plan.
+func (c *capturer) addIndex(tab opt.TableID, idx cat.IndexOrdinal) {
+	if c.md.Table(tab).IsVirtualTable() {
+		return
+	}
+	prev, ok := c.indexes[tab]
+	switch {
+	case !ok:
+		c.indexes[tab] = idx
+	case prev == idx || prev == -1:
+	case prev == cat.PrimaryIndex:
+		// The primary index is used to fetch the remaining columns after a
+		// lookup join into a secondary index, which is also what happens when
+		// the secondary index is forced.
+		c.indexes[tab] = idx
+	case idx != cat.PrimaryIndex:
+		c.indexes[tab] = -1
+	}
+}
+
+// addJoin records a lookup or inverted join from the given input into the
+// given table.
+func (c *capturer) addJoin(input memo.RelExpr, tab opt.TableID, flags memo.JoinFlags) {
+	var right util.FastIntSet
+	right.Add(int(tab))
+	c.addJoinSides(Tables(input), right, flags)
+}
+
+// addJoinSides records the flags for the join of the statement with the given
+// sides. The flags are commuted if the sides are swapped in the plan.
+func (c *capturer) addJoinSides(left, right util.FastIntSet, flags memo.JoinFlags) {
+	if left.Empty() || right.Empty() {
+		return
+	}
+	for i := range c.joins {
+		j := &c.joins[i]
+		var f memo.JoinFlags
+		switch {
+		case j.Left.Equals(left) && j.Right.Equals(right):
+			f = flags
+		case j.Left.Equals(right) && j.Right.Equals(left):
+			f = flags.Commute()
+		default:
+			continue
+		}
+		if c.joinFlags == nil {
+			c.joinFlags = make(map[int]memo.JoinFlags)
+		}
+		c.joinFlags[i] = f
+		return
+	}
+}
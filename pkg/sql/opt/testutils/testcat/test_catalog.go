@@ -646,6 +646,11 @@ func (tt *Table) IsMaterializedView() bool {
 	return false
 }
 
+// IsForeignTable is part of the cat.Table interface.
+func (tt *Table) IsForeignTable() bool {
+	return false
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (tt *Table) ColumnCount() int {
 	return len(tt.Columns)
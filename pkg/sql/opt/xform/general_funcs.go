@@ -44,7 +44,14 @@ func (c *CustomFuncs) Init(e *explorer) {
 
 // IsCanonicalScan returns true if the given ScanPrivate is an original
 // unaltered primary index Scan operator (i.e. unconstrained and not limited).
+//
+// Scans of foreign tables are never considered canonical: a foreign table can
+// only be read in full, so none of the alternative scans and joins generated
+// from a canonical scan can be executed.
 func (c *CustomFuncs) IsCanonicalScan(scan *memo.ScanPrivate) bool {
+	if c.e.mem.Metadata().Table(scan.Table).IsForeignTable() {
+		return false
+	}
 	return scan.IsCanonical()
 }
 
@@ -981,6 +981,11 @@ func (ot *optTable) IsMaterializedView() bool {
 	return ot.desc.MaterializedView()
 }
 
+// IsForeignTable implements the cat.Table interface.
+func (ot *optTable) IsForeignTable() bool {
+	return ot.desc.IsForeignTable()
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (ot *optTable) ColumnCount() int {
 	return len(ot.columns)
@@ -1815,6 +1820,11 @@ func (ot *optVirtualTable) IsMaterializedView() bool {
 	return false
 }
 
+// IsForeignTable implements the cat.Table interface.
+func (ot *optVirtualTable) IsForeignTable() bool {
+	return false
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (ot *optVirtualTable) ColumnCount() int {
 	return len(ot.columns)
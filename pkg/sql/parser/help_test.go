@@ -131,6 +131,12 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE VIEW blah AS SELECT c FROM x ??`, `SELECT`},
 		{`CREATE VIEW blah AS (??`, `<SELECTCLAUSE>`},
 
+		{`CREATE FOREIGN TABLE ??`, `CREATE FOREIGN TABLE`},
+		{`CREATE FOREIGN TABLE blah (??`, `CREATE FOREIGN TABLE`},
+
+		{`CREATE PUBLICATION ??`, `CREATE PUBLICATION`},
+		{`CREATE PUBLICATION a FOR ??`, `CREATE PUBLICATION`},
+
 		{`CREATE SEQUENCE ??`, `CREATE SEQUENCE`},
 
 		{`CREATE STATISTICS ??`, `CREATE STATISTICS`},
@@ -146,6 +152,8 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE TYPE blah AS ENUM ??`, `CREATE TYPE`},
 		{`DROP TYPE ??`, `DROP TYPE`},
 
+		{`DROP PUBLICATION ??`, `DROP PUBLICATION`},
+
 		{`CREATE SCHEMA IF ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA IF NOT ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA bli ??`, `CREATE SCHEMA`},
@@ -285,6 +293,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW HISTOGRAM ??`, `SHOW HISTOGRAM`},
 
+		{`SHOW HOT ??`, `SHOW HOT KEYS`},
+		{`SHOW HOT KEYS ??`, `SHOW HOT KEYS`},
+
 		{`SHOW QUERIES ??`, `SHOW STATEMENTS`},
 		{`SHOW LOCAL QUERIES ??`, `SHOW STATEMENTS`},
 
@@ -44,6 +44,7 @@ func TestParse(t *testing.T) {
 		{`BEGIN TRANSACTION READ ONLY`},
 		{`BEGIN TRANSACTION READ WRITE`},
 		{`BEGIN TRANSACTION ISOLATION LEVEL SERIALIZABLE`},
+		{`BEGIN TRANSACTION ISOLATION LEVEL READ COMMITTED`},
 		{`BEGIN TRANSACTION PRIORITY LOW`},
 		{`BEGIN TRANSACTION PRIORITY NORMAL`},
 		{`BEGIN TRANSACTION PRIORITY HIGH`},
@@ -370,6 +371,8 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
 		{`CREATE TEMPORARY VIEW a AS SELECT b`},
+		{`CREATE FOREIGN TABLE a (b INT8, c STRING NOT NULL) OPTIONS (location = 'nodelocal://1/a.csv', format = 'csv')`},
+		{`CREATE FOREIGN TABLE IF NOT EXISTS a (b INT8) OPTIONS (location = 'nodelocal://1/*.avro', format = 'avro', row_limit = '10')`},
 		{`CREATE MATERIALIZED VIEW a AS SELECT * FROM b`},
 		{`CREATE MATERIALIZED VIEW IF NOT EXISTS a AS SELECT * FROM b`},
 		{`REFRESH MATERIALIZED VIEW a.b`},
@@ -450,6 +453,12 @@ func TestParse(t *testing.T) {
 		{`DROP TYPE IF EXISTS db.sc.a, sc.a CASCADE`},
 		{`DROP TYPE IF EXISTS db.sc.a, sc.a RESTRICT`},
 
+		{`CREATE PUBLICATION a`},
+		{`CREATE PUBLICATION a FOR ALL TABLES`},
+		{`CREATE PUBLICATION a FOR TABLE b, db.sc.c`},
+		{`DROP PUBLICATION a`},
+		{`DROP PUBLICATION IF EXISTS a`},
+
 		{`DELETE FROM a`},
 		{`EXPLAIN DELETE FROM a`},
 		{`DELETE FROM a.b`},
@@ -651,6 +660,8 @@ func TestParse(t *testing.T) {
 		{`SHOW STATISTICS FOR TABLE d.t`},
 		{`SHOW HISTOGRAM 123`},
 		{`EXPLAIN SHOW HISTOGRAM 123`},
+		{`SHOW HOT KEYS`},
+		{`EXPLAIN SHOW HOT KEYS`},
 		{`SHOW RANGE FROM TABLE t FOR ROW (1, 2)`},
 		{`SHOW RANGE FROM TABLE d.t FOR ROW (1, 2)`},
 		{`SHOW RANGE FROM INDEX d.t@i FOR ROW (1, 2)`},
@@ -1284,6 +1295,7 @@ func TestParse(t *testing.T) {
 		{`SET TRANSACTION READ ONLY`},
 		{`SET TRANSACTION READ WRITE`},
 		{`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE`},
+		{`SET TRANSACTION ISOLATION LEVEL READ COMMITTED`},
 		{`SET TRANSACTION PRIORITY LOW`},
 		{`SET TRANSACTION PRIORITY NORMAL`},
 		{`SET TRANSACTION PRIORITY HIGH`},
@@ -2303,6 +2315,10 @@ $function$`,
 			`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, READ WRITE`},
 		{`SET TRANSACTION ISOLATION LEVEL SNAPSHOT READ ONLY`,
 			`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, READ ONLY`},
+		{`BEGIN TRANSACTION ISOLATION LEVEL READ UNCOMMITTED`,
+			`BEGIN TRANSACTION ISOLATION LEVEL READ COMMITTED`},
+		{`SET SESSION CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL READ UNCOMMITTED`,
+			`SET SESSION CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL READ COMMITTED`},
 		{"SET CLUSTER SETTING a TO 1", "SET CLUSTER SETTING a = 1"},
 		{"SET TRACING TO off", "SET TRACING = off"},
 		{"RELEASE foo", "RELEASE SAVEPOINT foo"},
@@ -3201,14 +3217,13 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE CONVERSION a`, 0, `create conversion`, ``},
 		{`CREATE DEFAULT CONVERSION a`, 0, `create def conv`, ``},
 		{`CREATE FOREIGN DATA WRAPPER a`, 0, `create fdw`, ``},
-		{`CREATE FOREIGN TABLE a`, 0, `create foreign table`, ``},
 		{`CREATE FUNCTION a`, 17511, `create`, ``},
 		{`CREATE OR REPLACE FUNCTION a`, 17511, `create`, ``},
 		{`CREATE LANGUAGE a`, 17511, `create language a`, ``},
 		{`CREATE OPERATOR a`, 0, `create operator`, ``},
-		{`CREATE PUBLICATION a`, 0, `create publication`, ``},
 		{`CREATE RULE a`, 0, `create rule`, ``},
-		{`CREATE SERVER a`, 0, `create server`, ``},
+		{`CREATE SERVER a`, 0, `create server`, `Foreign servers are not supported. Specify the files backing a foreign table with its location option instead.`},
+		{`CREATE FOREIGN TABLE a (b INT8) SERVER s`, 0, `create foreign table server`, `Foreign servers are not supported. Specify the files backing a foreign table with its location option instead.`},
 		{`CREATE SUBSCRIPTION a`, 0, `create subscription`, ``},
 		{`CREATE TABLESPACE a`, 54113, `create tablespace`, ``},
 		{`CREATE TEXT SEARCH a`, 7821, `create text`, ``},
@@ -3226,9 +3241,8 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DROP FUNCTION a`, 17511, `drop `, ``},
 		{`DROP LANGUAGE a`, 17511, `drop language a`, ``},
 		{`DROP OPERATOR a`, 0, `drop operator`, ``},
-		{`DROP PUBLICATION a`, 0, `drop publication`, ``},
 		{`DROP RULE a`, 0, `drop rule`, ``},
-		{`DROP SERVER a`, 0, `drop server`, ``},
+		{`DROP SERVER a`, 0, `drop server`, `Foreign servers are not supported. Specify the files backing a foreign table with its location option instead.`},
 		{`DROP SUBSCRIPTION a`, 0, `drop subscription`, ``},
 		{`DROP TEXT SEARCH a`, 7821, `drop text`, ``},
 		{`DROP TRIGGER a`, 28296, `drop`, ``},
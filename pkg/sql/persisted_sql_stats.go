@@ -0,0 +1,577 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// sqlStatsFlushEnabled determines whether the collected statement and
+// transaction statistics are periodically persisted.
+var sqlStatsFlushEnabled = settings.RegisterBoolSetting(
+	"sql.stats.flush.enabled",
+	"if set, SQL execution statistics are periodically flushed to system tables",
+	true,
+).WithPublic()
+
+// sqlStatsFlushInterval is the interval at which the collected statistics are
+// persisted. Only the statistics collected since the previous flush are
+// persisted, so that the in-memory statistics need not be reset.
+var sqlStatsFlushInterval = settings.RegisterDurationSetting(
+	"sql.stats.flush.interval",
+	"the interval at which SQL execution statistics are flushed to system tables",
+	10*time.Minute,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+).WithPublic()
+
+// sqlStatsAggregationInterval is the width of the time buckets into which the
+// persisted statistics are aggregated.
+var sqlStatsAggregationInterval = settings.RegisterDurationSetting(
+	"sql.stats.aggregation.interval",
+	"the width of the time buckets into which persisted SQL execution statistics are aggregated",
+	time.Hour,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+)
+
+// sqlStatsRetention is how long the persisted statistics are kept.
+var sqlStatsRetention = settings.RegisterDurationSetting(
+	"sql.stats.persisted_rows.retention",
+	"the amount of time for which persisted SQL execution statistics are retained",
+	7*24*time.Hour,
+	settings.NonNegativeDuration,
+).WithPublic()
+
+// sqlStatsDeleteBatchSize is the number of expired rows deleted per statement.
+const sqlStatsDeleteBatchSize = 1024
+
+// sqlStatsFlushBatchSize is the number of fingerprints whose statistics are
+// persisted per transaction.
+const sqlStatsFlushBatchSize = 100
+
+// sqlStatsReadBatchSize is the number of persisted rows read per query.
+const sqlStatsReadBatchSize = 1000
+
+const (
+	stmtStatsTableName = "statement_statistics"
+	txnStatsTableName  = "transaction_statistics"
+)
+
+// persistedStatsKey identifies a row of the persisted statistics tables.
+type persistedStatsKey struct {
+	aggregatedTs  time.Time
+	aggInterval   time.Duration
+	fingerprintID []byte
+	appName       string
+	nodeID        base.SQLInstanceID
+}
+
+// sameAggregate returns whether the rows for k and other describe the same
+// fingerprint and aggregation interval, possibly for different nodes.
+func (k *persistedStatsKey) sameAggregate(other *persistedStatsKey) bool {
+	return k.aggregatedTs.Equal(other.aggregatedTs) &&
+		string(k.fingerprintID) == string(other.fingerprintID) &&
+		k.appName == other.appName
+}
+
+// encodeFingerprintID encodes a statement or transaction fingerprint for
+// storage in the fingerprint_id column.
+func encodeFingerprintID(id uint64) []byte {
+	return encoding.EncodeUint64Ascending(nil, id)
+}
+
+// periodicallyFlushSQLStats spawns a loop that persists the collected SQL
+// statistics and deletes the expired ones.
+func (s *Server) periodicallyFlushSQLStats(ctx context.Context, stopper *stop.Stopper) {
+	_ = stopper.RunAsyncTask(ctx, "sql-stats-flusher", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(sqlStatsFlushInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if err := s.FlushSQLStats(ctx); err != nil {
+				log.Warningf(ctx, "failed to flush SQL statistics: %v", err)
+			}
+		}
+	})
+}
+
+// FlushSQLStats persists the statistics collected since the last flush, and
+// deletes the persisted statistics that have expired. The in-memory statistics
+// are left untouched. It is a no-op if flushing is disabled. The statistics
+// which fail to be persisted are persisted by the next flush.
+func (s *Server) FlushSQLStats(ctx context.Context) error {
+	if !sqlStatsFlushEnabled.Get(&s.cfg.Settings.SV) ||
+		!s.cfg.Settings.Version.IsActive(ctx, clusterversion.SQLStatsTables) {
+		return nil
+	}
+	if err := s.persistSQLStats(ctx); err != nil {
+		return err
+	}
+	return s.deleteExpiredSQLStats(ctx)
+}
+
+// persistedStatsRow is a row to be merged into a persisted statistics table.
+type persistedStatsRow struct {
+	aggregatedTs  time.Time
+	aggInterval   time.Duration
+	fingerprintID []byte
+	appName       string
+	// merge returns the statistics to write to the row, given the statistics
+	// currently stored in it, or nil if there are none.
+	merge func(prev []byte) (protoutil.Message, error)
+	// restore puts the statistics of the row back into the in-memory
+	// statistics, to be persisted by the next flush.
+	restore func()
+}
+
+// persistSQLStats merges the statistics which have not been persisted yet
+// into the rows of their aggregation interval for this node.
+func (s *Server) persistSQLStats(ctx context.Context) error {
+	var stmtRows, txnRows []persistedStatsRow
+	for appName, a := range s.sqlStats.getApps() {
+		appName, a := appName, a
+		stmts, txns := a.takeUnflushed()
+		for _, u := range stmts {
+			u := u
+			collected := roachpb.CollectedStatementStatistics{
+				ID: u.id,
+				Key: roachpb.StatementStatisticsKey{
+					Query:       u.key.anonymizedStmt,
+					App:         appName,
+					DistSQL:     u.distSQLUsed,
+					Failed:      u.key.failed,
+					Opt:         true,
+					ImplicitTxn: u.key.implicitTxn,
+					Vec:         u.vectorized,
+				},
+				Stats: u.data,
+			}
+			stmtRows = append(stmtRows, persistedStatsRow{
+				aggregatedTs:  u.aggregatedTs,
+				aggInterval:   u.aggInterval,
+				fingerprintID: encodeFingerprintID(uint64(u.id)),
+				appName:       appName,
+				merge: func(prev []byte) (protoutil.Message, error) {
+					res := collected
+					if prev != nil {
+						var existing roachpb.CollectedStatementStatistics
+						if err := protoutil.Unmarshal(prev, &existing); err != nil {
+							return nil, err
+						}
+						existing.Stats.Add(&collected.Stats)
+						res.Stats = existing.Stats
+					}
+					return &res, nil
+				},
+				restore: func() { a.restoreUnflushedStmt(u) },
+			})
+		}
+		for _, u := range txns {
+			u := u
+			collected := roachpb.CollectedTransactionStatistics{
+				StatementIDs: u.statementIDs,
+				App:          appName,
+				Stats:        u.data,
+			}
+			txnRows = append(txnRows, persistedStatsRow{
+				aggregatedTs:  u.aggregatedTs,
+				aggInterval:   u.aggInterval,
+				fingerprintID: encodeFingerprintID(uint64(u.key)),
+				appName:       appName,
+				merge: func(prev []byte) (protoutil.Message, error) {
+					res := collected
+					if prev != nil {
+						var existing roachpb.CollectedTransactionStatistics
+						if err := protoutil.Unmarshal(prev, &existing); err != nil {
+							return nil, err
+						}
+						existing.Stats.Add(&collected.Stats)
+						res.Stats = existing.Stats
+					}
+					return &res, nil
+				},
+				restore: func() { a.restoreUnflushedTxn(u) },
+			})
+		}
+	}
+
+	// Each batch is written in its own transaction, and only holds rows of a
+	// single aggregation interval.
+	type batch struct {
+		table string
+		key   persistedStatsKey
+		rows  []persistedStatsRow
+	}
+	var batches []batch
+	for _, t := range []struct {
+		table string
+		rows  []persistedStatsRow
+	}{
+		{table: stmtStatsTableName, rows: stmtRows},
+		{table: txnStatsTableName, rows: txnRows},
+	} {
+		rows := t.rows
+		sort.Slice(rows, func(i, j int) bool {
+			return rows[i].aggregatedTs.Before(rows[j].aggregatedTs)
+		})
+		for len(rows) > 0 {
+			n := 1
+			for n < len(rows) && n < sqlStatsFlushBatchSize &&
+				rows[n].aggregatedTs.Equal(rows[0].aggregatedTs) {
+				n++
+			}
+			batches = append(batches, batch{
+				table: t.table,
+				key: persistedStatsKey{
+					aggregatedTs: rows[0].aggregatedTs,
+					aggInterval:  rows[0].aggInterval,
+					nodeID:       s.cfg.NodeID.SQLInstanceID(),
+				},
+				rows: rows[:n],
+			})
+			rows = rows[n:]
+		}
+	}
+	for i := range batches {
+		if err := s.mergePersistedStats(ctx, batches[i].table, &batches[i].key, batches[i].rows); err != nil {
+			// The batches which have not been written are persisted by the next
+			// flush.
+			for _, b := range batches[i:] {
+				for _, row := range b.rows {
+					row.restore()
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// mergePersistedStats merges the given rows into the rows of the given table
+// for the aggregation interval and node of key, in a single transaction.
+func (s *Server) mergePersistedStats(
+	ctx context.Context, table string, key *persistedStatsKey, rows []persistedStatsRow,
+) error {
+	ie := s.cfg.InternalExecutor
+	return s.cfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		var buf strings.Builder
+		args := []interface{}{key.aggregatedTs, key.nodeID}
+		for i := range rows {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "($%d, $%d)", len(args)+1, len(args)+2)
+			args = append(args, rows[i].fingerprintID, rows[i].appName)
+		}
+		existing, err := ie.QueryEx(ctx, "read-sql-stats", txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			fmt.Sprintf(`SELECT fingerprint_id, app_name, statistics FROM system.%s
+WHERE aggregated_ts = $1 AND node_id = $2 AND (fingerprint_id, app_name) IN (%s)`,
+				table, buf.String()),
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+		type fingerprint struct {
+			id  string
+			app string
+		}
+		prev := make(map[fingerprint][]byte, len(existing))
+		for _, row := range existing {
+			prev[fingerprint{
+				id:  string(tree.MustBeDBytes(row[0])),
+				app: string(tree.MustBeDString(row[1])),
+			}] = []byte(tree.MustBeDBytes(row[2]))
+		}
+
+		buf.Reset()
+		args = append(args[:2], key.aggInterval)
+		for i := range rows {
+			stats, err := rows[i].merge(prev[fingerprint{id: string(rows[i].fingerprintID), app: rows[i].appName}])
+			if err != nil {
+				return err
+			}
+			statsBytes, err := protoutil.Marshal(stats)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "($1, $%d, $%d, $2, $3, $%d)", len(args)+1, len(args)+2, len(args)+3)
+			args = append(args, rows[i].fingerprintID, rows[i].appName, statsBytes)
+		}
+		_, err = ie.ExecEx(ctx, "write-sql-stats", txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			fmt.Sprintf(`UPSERT INTO system.%s
+(aggregated_ts, fingerprint_id, app_name, node_id, agg_interval, statistics)
+VALUES %s`, table, buf.String()),
+			args...,
+		)
+		return err
+	})
+}
+
+// deleteExpiredSQLStats deletes the persisted statistics of the aggregation
+// intervals that started before the retention period.
+func (s *Server) deleteExpiredSQLStats(ctx context.Context) error {
+	cutoff := timeutil.Now().Add(-sqlStatsRetention.Get(&s.cfg.Settings.SV))
+	for _, table := range []string{stmtStatsTableName, txnStatsTableName} {
+		for {
+			n, err := s.cfg.InternalExecutor.ExecEx(ctx, "delete-expired-sql-stats", nil, /* txn */
+				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+				fmt.Sprintf(`DELETE FROM system.%s WHERE aggregated_ts < $1 LIMIT %d`,
+					table, sqlStatsDeleteBatchSize),
+				cutoff,
+			)
+			if err != nil {
+				return err
+			}
+			if n < sqlStatsDeleteBatchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// readPersistedSQLStats calls fn for each row of the given persisted
+// statistics table whose aggregation interval starts in [start, end), ordered
+// by aggregation interval, fingerprint and application. A zero end means no
+// upper bound. The rows are read in pages of sqlStatsReadBatchSize rows.
+func readPersistedSQLStats(
+	ctx context.Context,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+	table string,
+	start, end time.Time,
+	fn func(key *persistedStatsKey, statistics []byte) error,
+) error {
+	query := fmt.Sprintf(`SELECT aggregated_ts, agg_interval, fingerprint_id, app_name, node_id, statistics
+FROM system.%s WHERE aggregated_ts >= $1`, table)
+	args := []interface{}{start}
+	if !end.IsZero() {
+		query += ` AND aggregated_ts < $2`
+		args = append(args, end)
+	}
+	order := fmt.Sprintf(` ORDER BY aggregated_ts, fingerprint_id, app_name, node_id LIMIT %d`,
+		sqlStatsReadBatchSize)
+	// Every page but the first resumes after the last row of the previous one.
+	resumeQuery := query + fmt.Sprintf(
+		` AND (aggregated_ts, fingerprint_id, app_name, node_id) > ($%d, $%d, $%d, $%d)`,
+		len(args)+1, len(args)+2, len(args)+3, len(args)+4,
+	) + order
+	query += order
+	for {
+		rows, err := ie.QueryEx(ctx, "read-persisted-sql-stats", txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()}, query, args...)
+		if err != nil {
+			return err
+		}
+		var last *persistedStatsKey
+		for _, row := range rows {
+			key := persistedStatsKey{
+				aggregatedTs:  tree.MustBeDTimestampTZ(row[0]).Time,
+				aggInterval:   time.Duration(tree.MustBeDInterval(row[1]).Duration.Nanos()),
+				fingerprintID: []byte(tree.MustBeDBytes(row[2])),
+				appName:       string(tree.MustBeDString(row[3])),
+				nodeID:        base.SQLInstanceID(tree.MustBeDInt(row[4])),
+			}
+			if err := fn(&key, []byte(tree.MustBeDBytes(row[5]))); err != nil {
+				return err
+			}
+			last = &key
+		}
+		if len(rows) < sqlStatsReadBatchSize {
+			return nil
+		}
+		if query != resumeQuery {
+			query = resumeQuery
+			args = append(args, nil, nil, nil, nil)
+		}
+		n := len(args)
+		args[n-4], args[n-3], args[n-2], args[n-1] =
+			last.aggregatedTs, last.fingerprintID, last.appName, last.nodeID
+	}
+}
+
+// forEachPersistedStmtStats calls fn with the persisted statistics of each
+// statement fingerprint, combined across nodes, for each aggregation interval
+// starting in [start, end). A zero end means no upper bound.
+func forEachPersistedStmtStats(
+	ctx context.Context,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+	start, end time.Time,
+	fn func(key *persistedStatsKey, stats *roachpb.CollectedStatementStatistics) error,
+) error {
+	var cur *persistedStatsKey
+	var combined roachpb.CollectedStatementStatistics
+	if err := readPersistedSQLStats(ctx, ie, txn, stmtStatsTableName, start, end,
+		func(key *persistedStatsKey, statistics []byte) error {
+			var stats roachpb.CollectedStatementStatistics
+			if err := protoutil.Unmarshal(statistics, &stats); err != nil {
+				return err
+			}
+			if cur != nil && cur.sameAggregate(key) {
+				combined.Stats.Add(&stats.Stats)
+				return nil
+			}
+			if cur != nil {
+				if err := fn(cur, &combined); err != nil {
+					return err
+				}
+			}
+			cur, combined = key, stats
+			return nil
+		}); err != nil {
+		return err
+	}
+	if cur == nil {
+		return nil
+	}
+	return fn(cur, &combined)
+}
+
+// forEachPersistedTxnStats is like forEachPersistedStmtStats, for transaction
+// fingerprints.
+func forEachPersistedTxnStats(
+	ctx context.Context,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+	start, end time.Time,
+	fn func(key *persistedStatsKey, stats *roachpb.CollectedTransactionStatistics) error,
+) error {
+	var cur *persistedStatsKey
+	var combined roachpb.CollectedTransactionStatistics
+	if err := readPersistedSQLStats(ctx, ie, txn, txnStatsTableName, start, end,
+		func(key *persistedStatsKey, statistics []byte) error {
+			var stats roachpb.CollectedTransactionStatistics
+			if err := protoutil.Unmarshal(statistics, &stats); err != nil {
+				return err
+			}
+			if cur != nil && cur.sameAggregate(key) {
+				combined.Stats.Add(&stats.Stats)
+				return nil
+			}
+			if cur != nil {
+				if err := fn(cur, &combined); err != nil {
+					return err
+				}
+			}
+			cur, combined = key, stats
+			return nil
+		}); err != nil {
+		return err
+	}
+	if cur == nil {
+		return nil
+	}
+	return fn(cur, &combined)
+}
+
+// GetPersistedStmtStats returns the persisted statement statistics of the
+// aggregation intervals starting in [start, end), combined across nodes and
+// aggregation intervals. A zero end means no upper bound.
+func (s *Server) GetPersistedStmtStats(
+	ctx context.Context, start, end time.Time,
+) ([]roachpb.CollectedStatementStatistics, error) {
+	if !s.cfg.Settings.Version.IsActive(ctx, clusterversion.SQLStatsTables) {
+		return nil, nil
+	}
+	type fingerprint struct {
+		id  roachpb.StmtID
+		app string
+	}
+	var res []roachpb.CollectedStatementStatistics
+	idx := make(map[fingerprint]int)
+	if err := forEachPersistedStmtStats(ctx, s.cfg.InternalExecutor, nil /* txn */, start, end,
+		func(_ *persistedStatsKey, stats *roachpb.CollectedStatementStatistics) error {
+			f := fingerprint{id: stats.ID, app: stats.Key.App}
+			if i, ok := idx[f]; ok {
+				res[i].Stats.Add(&stats.Stats)
+				return nil
+			}
+			idx[f] = len(res)
+			res = append(res, *stats)
+			return nil
+		}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetPersistedTxnStats is like GetPersistedStmtStats, for transaction
+// statistics.
+func (s *Server) GetPersistedTxnStats(
+	ctx context.Context, start, end time.Time,
+) ([]roachpb.CollectedTransactionStatistics, error) {
+	if !s.cfg.Settings.Version.IsActive(ctx, clusterversion.SQLStatsTables) {
+		return nil, nil
+	}
+	type fingerprint struct {
+		id  string
+		app string
+	}
+	var res []roachpb.CollectedTransactionStatistics
+	idx := make(map[fingerprint]int)
+	if err := forEachPersistedTxnStats(ctx, s.cfg.InternalExecutor, nil /* txn */, start, end,
+		func(key *persistedStatsKey, stats *roachpb.CollectedTransactionStatistics) error {
+			f := fingerprint{id: string(key.fingerprintID), app: key.appName}
+			if i, ok := idx[f]; ok {
+				res[i].Stats.Add(&stats.Stats)
+				return nil
+			}
+			idx[f] = len(res)
+			res = append(res, *stats)
+			return nil
+		}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
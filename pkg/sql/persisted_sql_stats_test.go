@@ -0,0 +1,140 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersistedSQLStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(ctx)
+	sqlServer := s.SQLServer().(*sql.Server)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY)`)
+
+	// Run the statements under test with a custom application name.
+	pgURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(security.RootUser),
+		Host:     s.ServingSQLAddr(),
+		RawQuery: "sslmode=disable&application_name=persisted",
+	}
+	rawAppDB, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer rawAppDB.Close()
+	appDB := sqlutils.MakeSQLRunner(rawAppDB)
+
+	for i := 0; i < 3; i++ {
+		appDB.Exec(t, `SELECT * FROM t WHERE k = $1`, i)
+	}
+	appDB.Exec(t, `INSERT INTO t VALUES (1)`)
+	require.NoError(t, sqlServer.FlushSQLStats(ctx))
+
+	// The flushed statistics are still held in memory.
+	const inMemoryCount = `
+SELECT count FROM crdb_internal.node_statement_statistics
+WHERE application_name = 'persisted' AND key = 'SELECT * FROM t WHERE k = $1'`
+	sqlDB.CheckQueryResults(t, inMemoryCount, [][]string{{"3"}})
+
+	// Only the statistics collected since the previous flush are combined with
+	// the persisted ones. They may land in a different aggregation interval, so
+	// sum over all of them.
+	for i := 0; i < 2; i++ {
+		appDB.Exec(t, `SELECT * FROM t WHERE k = $1`, i)
+	}
+	require.NoError(t, sqlServer.FlushSQLStats(ctx))
+
+	const stmtCounts = `
+SELECT metadata->>'query', sum((statistics->>'count')::INT)
+FROM crdb_internal.statement_statistics
+WHERE app_name = 'persisted'
+GROUP BY 1 ORDER BY 1`
+	sqlDB.CheckQueryResults(t, stmtCounts, [][]string{
+		{"INSERT INTO t VALUES (_)", "1"},
+		{"SELECT * FROM t WHERE k = $1", "5"},
+	})
+	sqlDB.CheckQueryResults(t, inMemoryCount, [][]string{{"5"}})
+
+	// The transactions reference the fingerprints of their statements.
+	sqlDB.CheckQueryResults(t, `
+SELECT sum((t.statistics->>'count')::INT)
+FROM crdb_internal.transaction_statistics t, crdb_internal.statement_statistics s
+WHERE t.app_name = 'persisted' AND s.app_name = 'persisted'
+AND t.metadata->'stmtFingerprintIDs' = json_build_array(encode(s.fingerprint_id, 'hex'))
+AND s.metadata->>'query' = 'SELECT * FROM t WHERE k = $1'`,
+		[][]string{{"5"}})
+
+	// The statistics which fail to be persisted are persisted by the next
+	// flush.
+	appDB.Exec(t, `SELECT * FROM t WHERE k = $1`, 1)
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.Error(t, sqlServer.FlushSQLStats(canceledCtx))
+	require.NoError(t, sqlServer.FlushSQLStats(ctx))
+	sqlDB.CheckQueryResults(t, stmtCounts, [][]string{
+		{"INSERT INTO t VALUES (_)", "1"},
+		{"SELECT * FROM t WHERE k = $1", "6"},
+	})
+
+	// Nothing is persisted while flushing is disabled, but the statistics
+	// collected before are persisted once it is enabled again.
+	appDB.Exec(t, `SELECT * FROM t WHERE k = $1`, 1)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.stats.flush.enabled = false`)
+	appDB.Exec(t, `SELECT * FROM t WHERE k = $1`, 1)
+	require.NoError(t, sqlServer.FlushSQLStats(ctx))
+	sqlDB.CheckQueryResults(t, stmtCounts, [][]string{
+		{"INSERT INTO t VALUES (_)", "1"},
+		{"SELECT * FROM t WHERE k = $1", "6"},
+	})
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.stats.flush.enabled = true`)
+	require.NoError(t, sqlServer.FlushSQLStats(ctx))
+	sqlDB.CheckQueryResults(t, stmtCounts, [][]string{
+		{"INSERT INTO t VALUES (_)", "1"},
+		{"SELECT * FROM t WHERE k = $1", "7"},
+	})
+
+	// The statistics which have not been persisted yet survive a reset of the
+	// in-memory statistics.
+	appDB.Exec(t, `SELECT * FROM t WHERE k = $1`, 1)
+	sqlServer.ResetSQLStats(ctx)
+	require.NoError(t, sqlServer.FlushSQLStats(ctx))
+	sqlDB.CheckQueryResults(t, stmtCounts, [][]string{
+		{"INSERT INTO t VALUES (_)", "1"},
+		{"SELECT * FROM t WHERE k = $1", "8"},
+	})
+
+	// Statistics older than the retention period are deleted.
+	sqlDB.Exec(t, `
+INSERT INTO system.statement_statistics
+VALUES ('2020-01-01', b'\x00', 'persisted', 1, '1h', b'')`)
+	require.NoError(t, sqlServer.FlushSQLStats(ctx))
+	sqlDB.CheckQueryResults(t, `
+SELECT count(*) FROM system.statement_statistics WHERE aggregated_ts < '2021-01-01'`,
+		[][]string{{"0"}})
+}
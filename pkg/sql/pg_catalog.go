@@ -21,6 +21,7 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
@@ -34,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/oidext"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgrepl"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
@@ -258,6 +260,10 @@ var pgCatalog = virtualSchema{
 		catconstants.PgCatalogStatActivityTableID:        pgCatalogStatActivityTable,
 		catconstants.PgCatalogSecurityLabelTableID:       pgCatalogSecurityLabelTable,
 		catconstants.PgCatalogSharedSecurityLabelTableID: pgCatalogSharedSecurityLabelTable,
+		catconstants.PgCatalogPublicationTableID:         pgCatalogPublicationTable,
+		catconstants.PgCatalogPublicationRelTableID:      pgCatalogPublicationRelTable,
+		catconstants.PgCatalogPublicationTablesTableID:   pgCatalogPublicationTablesTable,
+		catconstants.PgCatalogReplicationSlotsTableID:    pgCatalogReplicationSlotsTable,
 	},
 	// Postgres's catalogs are ill-defined when there is no current
 	// database set. Simply reject any attempts to use them in that
@@ -2527,6 +2533,127 @@ https://www.postgresql.org/docs/9.5/catalog-pg-shseclabel.html`,
 	},
 }
 
+var pgCatalogPublicationTable = virtualSchemaTable{
+	comment: `publications
+https://www.postgresql.org/docs/13/catalog-pg-publication.html`,
+	schema: vtable.PGCatalogPublication,
+	populate: func(ctx context.Context, p *planner, dbContext *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		h := makeOidHasher()
+		return forEachPublication(ctx, p, dbContext, func(pub publicationInfo) error {
+			return addRow(
+				h.PublicationOid(pub.dbID, pub.name),      // oid
+				tree.NewDName(pub.name),                   // pubname
+				h.UserOid(pub.owner),                      // pubowner
+				tree.MakeDBool(tree.DBool(pub.allTables)), // puballtables
+				tree.DBoolTrue,                            // pubinsert
+				tree.DBoolTrue,                            // pubupdate
+				tree.DBoolTrue,                            // pubdelete
+				tree.DBoolFalse,                           // pubtruncate
+				tree.DBoolFalse,                           // pubviaroot
+			)
+		})
+	},
+}
+
+var pgCatalogPublicationRelTable = virtualSchemaTable{
+	comment: `tables of publications, except those FOR ALL TABLES
+https://www.postgresql.org/docs/13/catalog-pg-publication-rel.html`,
+	schema: vtable.PGCatalogPublicationRel,
+	populate: func(ctx context.Context, p *planner, dbContext *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		h := makeOidHasher()
+		return forEachPublication(ctx, p, dbContext, func(pub publicationInfo) error {
+			pubOid := h.PublicationOid(pub.dbID, pub.name)
+			for _, id := range pub.tableIDs {
+				if err := addRow(
+					h.PublicationRelOid(pubOid, id), // oid
+					pubOid,                          // prpubid
+					tableOid(id),                    // prrelid
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var pgCatalogPublicationTablesTable = virtualSchemaTable{
+	comment: `tables of publications
+https://www.postgresql.org/docs/13/view-pg-publication-tables.html`,
+	schema: vtable.PGCatalogPublicationTables,
+	populate: func(ctx context.Context, p *planner, dbContext *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		var pubs []publicationInfo
+		if err := forEachPublication(ctx, p, dbContext, func(pub publicationInfo) error {
+			pubs = append(pubs, pub)
+			return nil
+		}); err != nil || len(pubs) == 0 {
+			return err
+		}
+		return forEachTableDesc(ctx, p, dbContext, hideVirtual,
+			func(db *dbdesc.Immutable, scName string, table catalog.TableDescriptor) error {
+				for _, pub := range pubs {
+					if !pub.includes(db.GetID(), table) {
+						continue
+					}
+					if err := addRow(
+						tree.NewDName(pub.name),        // pubname
+						tree.NewDName(scName),          // schemaname
+						tree.NewDName(table.GetName()), // tablename
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+	},
+}
+
+var pgCatalogReplicationSlotsTable = virtualSchemaTable{
+	comment: `replication slots
+https://www.postgresql.org/docs/13/view-pg-replication-slots.html`,
+	schema: vtable.PGCatalogReplicationSlots,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.PublicationsAndReplicationSlots) {
+			return nil
+		}
+		rows, err := p.ExecCfg().InternalExecutor.Query(ctx, "select-replication-slots", p.txn,
+			`SELECT name, plugin, database_id, confirmed_flush_lsn FROM system.replication_slots`)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			dbID := descpb.ID(tree.MustBeDInt(row[2]))
+			var dbName tree.Datum = tree.DNull
+			if db, err := p.Descriptors().GetImmutableDatabaseByID(
+				ctx, p.txn, dbID, tree.DatabaseLookupFlags{}); err != nil {
+				return err
+			} else if db != nil {
+				dbName = tree.NewDName(db.GetName())
+			}
+			lsn := tree.NewDString(pgrepl.LSN(tree.MustBeDInt(row[3])).String())
+			if err := addRow(
+				tree.NewDName(string(tree.MustBeDString(row[0]))), // slot_name
+				tree.NewDName(string(tree.MustBeDString(row[1]))), // plugin
+				tree.NewDString("logical"),                        // slot_type
+				dbOid(dbID),                                       // datoid
+				dbName,                                            // database
+				tree.DBoolFalse,                                   // temporary
+				tree.DBoolFalse,                                   // active
+				tree.DNull,                                        // active_pid
+				tree.DNull,                                        // xmin
+				tree.DNull,                                        // catalog_xmin
+				lsn,                                               // restart_lsn
+				lsn,                                               // confirmed_flush_lsn
+				tree.NewDString("reserved"),                       // wal_status
+				tree.DNull,                                        // safe_wal_size
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // typOid is the only OID generation approach that does not use oidHasher, because
 // object identifiers for types are not arbitrary, but instead need to be kept in
 // sync with Postgres.
@@ -2786,6 +2913,8 @@ const (
 	collationTypeTag
 	operatorTypeTag
 	enumEntryTypeTag
+	publicationTypeTag
+	publicationRelTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -2927,6 +3056,20 @@ func (h oidHasher) UserOid(username security.SQLUsername) *tree.DOid {
 	return h.getOid()
 }
 
+func (h oidHasher) PublicationOid(dbID descpb.ID, name string) *tree.DOid {
+	h.writeTypeTag(publicationTypeTag)
+	h.writeDB(dbID)
+	h.writeStr(name)
+	return h.getOid()
+}
+
+func (h oidHasher) PublicationRelOid(pubOid *tree.DOid, tableID descpb.ID) *tree.DOid {
+	h.writeTypeTag(publicationRelTypeTag)
+	h.writeOID(pubOid)
+	h.writeTable(tableID)
+	return h.getOid()
+}
+
 func (h oidHasher) CollationOid(collation string) *tree.DOid {
 	h.writeTypeTag(collationTypeTag)
 	h.writeStr(collation)
@@ -0,0 +1,27 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+/*
+Package pgrepl contains the pieces of the Postgres streaming replication
+protocol that are shared between the pgwire package, which owns the network
+connection, and the sql package, which produces the stream of changes.
+
+Connections opened with the replication=database startup parameter accept a
+small set of replication commands (IDENTIFY_SYSTEM, CREATE_REPLICATION_SLOT,
+DROP_REPLICATION_SLOT and START_REPLICATION) in addition to regular SQL. The
+commands are parsed by Parse. START_REPLICATION switches the connection to
+the CopyBoth sub-protocol, over which changes to the tables of a publication
+are sent as messages in the format of the pgoutput output plugin and the
+client periodically reports the position up to which it has durably
+processed them.
+
+See: https://www.postgresql.org/docs/current/protocol-replication.html
+*/
+package pgrepl
@@ -0,0 +1,114 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Feedback coordinates the two goroutines serving a replication stream. The
+// goroutine producing the stream writes to the connection, while the network
+// goroutine keeps reading the messages sent by the client and passes the
+// positions it acknowledges along through the Feedback.
+//
+// The lifecycle is:
+//  1. The producer calls Started once it has switched the connection to the
+//     CopyBoth sub-protocol. Until then, the network goroutine must not read
+//     from the connection; if the producer fails before starting, it calls
+//     Finish directly.
+//  2. The network goroutine calls Stop when the client ends the stream.
+//  3. The producer calls Finish once it no longer uses the connection, after
+//     which the network goroutine takes it back.
+type Feedback struct {
+	mu struct {
+		syncutil.Mutex
+		flushed        LSN
+		replyRequested bool
+	}
+	// updated is signaled, without blocking, whenever a status update is
+	// received.
+	updated chan struct{}
+
+	startOnce, stopOnce, finishOnce sync.Once
+	started, stopped, finished      chan struct{}
+}
+
+// NewFeedback creates a Feedback.
+func NewFeedback() *Feedback {
+	return &Feedback{
+		updated:  make(chan struct{}, 1),
+		started:  make(chan struct{}),
+		stopped:  make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+}
+
+// Update records a standby status update sent by the client.
+func (f *Feedback) Update(s StandbyStatus) {
+	f.mu.Lock()
+	if s.Flushed > f.mu.flushed {
+		f.mu.flushed = s.Flushed
+	}
+	f.mu.replyRequested = f.mu.replyRequested || s.ReplyRequested
+	f.mu.Unlock()
+	select {
+	case f.updated <- struct{}{}:
+	default:
+	}
+}
+
+// Updated returns a channel that receives a value after status updates.
+func (f *Feedback) Updated() <-chan struct{} {
+	return f.updated
+}
+
+// Status returns the highest position acknowledged as flushed by the client
+// and whether the client requested a reply since the last call.
+func (f *Feedback) Status() (flushed LSN, replyRequested bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	flushed, replyRequested = f.mu.flushed, f.mu.replyRequested
+	f.mu.replyRequested = false
+	return flushed, replyRequested
+}
+
+// Start signals that the stream has started.
+func (f *Feedback) Start() {
+	f.startOnce.Do(func() { close(f.started) })
+}
+
+// Started returns a channel that is closed once the stream has started.
+func (f *Feedback) Started() <-chan struct{} {
+	return f.started
+}
+
+// Stop asks the producer to end the stream.
+func (f *Feedback) Stop() {
+	f.stopOnce.Do(func() { close(f.stopped) })
+}
+
+// Stopped returns a channel that is closed once the stream should end.
+func (f *Feedback) Stopped() <-chan struct{} {
+	return f.stopped
+}
+
+// Finish signals that the producer no longer uses the connection.
+func (f *Feedback) Finish() {
+	f.finishOnce.Do(func() { close(f.finished) })
+}
+
+// Finished returns a channel that is closed once the producer no longer uses
+// the connection.
+func (f *Feedback) Finished() <-chan struct{} {
+	return f.finished
+}
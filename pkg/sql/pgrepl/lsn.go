@@ -0,0 +1,63 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// LSN is a position in the replication stream, the equivalent of a Postgres
+// log sequence number. There is no write-ahead log shared by all ranges, so
+// positions are derived from MVCC timestamps instead: the LSN of a change is
+// the wall time of its commit timestamp. All changes committed at the same
+// wall time are streamed as a single transaction, which keeps LSNs strictly
+// increasing across transactions.
+type LSN uint64
+
+// String formats the LSN the way Postgres does, as two hexadecimal 32-bit
+// halves separated by a slash.
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
+
+// Timestamp returns the MVCC timestamp corresponding to the LSN. All changes
+// with a timestamp above it are after the LSN in the replication stream.
+func (l LSN) Timestamp() hlc.Timestamp {
+	return hlc.Timestamp{WallTime: int64(l)}
+}
+
+// LSNFromTimestamp returns the LSN of a change committed at ts.
+func LSNFromTimestamp(ts hlc.Timestamp) LSN {
+	return LSN(ts.WallTime)
+}
+
+// ParseLSN parses an LSN in the format produced by LSN.String.
+func ParseLSN(s string) (LSN, error) {
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return 0, pgerror.Newf(pgcode.InvalidTextRepresentation, "invalid LSN: %q", s)
+	}
+	hi, err := strconv.ParseUint(s[:i], 16, 32)
+	if err != nil {
+		return 0, pgerror.Newf(pgcode.InvalidTextRepresentation, "invalid LSN: %q", s)
+	}
+	lo, err := strconv.ParseUint(s[i+1:], 16, 32)
+	if err != nil {
+		return 0, pgerror.Newf(pgcode.InvalidTextRepresentation, "invalid LSN: %q", s)
+	}
+	return LSN(hi<<32 | lo), nil
+}
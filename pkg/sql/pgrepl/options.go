@@ -0,0 +1,134 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// Options are the pgoutput plugin options passed to START_REPLICATION.
+type Options struct {
+	// Publications lists the names of the publications whose tables are
+	// streamed.
+	Publications []string
+}
+
+// ParseOptions parses the pgoutput plugin options. Options enabling protocol
+// features that are not supported result in an error.
+func ParseOptions(opts tree.KVOptions) (Options, error) {
+	var res Options
+	var sawVersion bool
+	for _, opt := range opts {
+		key := string(opt.Key)
+		var val string
+		if opt.Value != nil {
+			val = opt.Value.(*tree.StrVal).RawString()
+		}
+		switch key {
+		case "proto_version":
+			v, err := strconv.Atoi(val)
+			if err != nil {
+				return Options{}, pgerror.Newf(pgcode.InvalidParameterValue,
+					"invalid proto_version %q", val)
+			}
+			if v != ProtocolVersion {
+				return Options{}, pgerror.Newf(pgcode.FeatureNotSupported,
+					"client sent proto_version=%d but we only support protocol %d", v, ProtocolVersion)
+			}
+			sawVersion = true
+		case "publication_names":
+			names, err := splitIdentifiers(val)
+			if err != nil {
+				return Options{}, err
+			}
+			res.Publications = names
+		case "binary", "messages", "streaming":
+			// These options request protocol extensions which are not supported.
+			// Turning them off is allowed.
+			b, err := strconv.ParseBool(val)
+			if err != nil && val != "off" {
+				return Options{}, pgerror.Newf(pgcode.InvalidParameterValue,
+					"invalid value for %s: %q", key, val)
+			}
+			if b {
+				return Options{}, pgerror.Newf(pgcode.FeatureNotSupported,
+					"option %s is not supported", key)
+			}
+		default:
+			return Options{}, pgerror.Newf(pgcode.InvalidParameterValue,
+				"unrecognized pgoutput option: %s", key)
+		}
+	}
+	if !sawVersion {
+		return Options{}, pgerror.New(pgcode.InvalidParameterValue,
+			"proto_version option missing")
+	}
+	if len(res.Publications) == 0 {
+		return Options{}, pgerror.New(pgcode.InvalidParameterValue,
+			"publication_names parameter missing")
+	}
+	return res, nil
+}
+
+// splitIdentifiers splits a comma-separated list of identifiers. Like in
+// Postgres, quoted identifiers are used verbatim while unquoted ones are
+// folded to lower case.
+func splitIdentifiers(s string) ([]string, error) {
+	var res []string
+	invalid := pgerror.Newf(pgcode.InvalidName, "invalid publication_names syntax: %q", s)
+	for {
+		s = strings.TrimLeft(s, " \t")
+		var name string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for {
+				if i >= len(s) {
+					return nil, invalid
+				}
+				if s[i] == '"' {
+					if i+1 < len(s) && s[i+1] == '"' {
+						b.WriteByte('"')
+						i += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[i])
+				i++
+			}
+			name, s = b.String(), s[i+1:]
+		} else {
+			end := strings.IndexAny(s, ", \t")
+			if end < 0 {
+				end = len(s)
+			}
+			name, s = strings.ToLower(s[:end]), s[end:]
+		}
+		if name == "" {
+			return nil, invalid
+		}
+		res = append(res, name)
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return res, nil
+		}
+		if s[0] != ',' {
+			return nil, invalid
+		}
+		s = s[1:]
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+// Parse parses a replication command. The boolean return value is false if
+// the query is not a replication command, in which case it should be parsed
+// as regular SQL.
+//
+// The replication commands have their own grammar in Postgres, which is much
+// simpler than SQL and has a few keywords that clash with it, so they are not
+// part of the SQL parser.
+func Parse(query string) (tree.Statement, bool, error) {
+	toks, err := tokenize(query)
+	if err != nil || len(toks) == 0 || toks[0].kind != tokWord {
+		// Let the SQL parser report syntax errors for anything that doesn't
+		// start like a replication command.
+		return nil, false, nil //nolint:returnerrcheck
+	}
+	p := parser{toks: toks}
+	var stmt tree.Statement
+	switch cmd := p.next().val; cmd {
+	case "identify_system":
+		stmt = &tree.IdentifySystem{}
+	case "create_replication_slot":
+		stmt, err = p.createReplicationSlot()
+	case "drop_replication_slot":
+		stmt, err = p.dropReplicationSlot()
+	case "start_replication":
+		stmt, err = p.startReplication()
+	case "base_backup", "timeline_history", "read_replication_slot":
+		return nil, true, unimplemented.Newf(
+			"replication."+cmd, "replication command %s is not supported", strings.ToUpper(cmd))
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	if p.peek(tokPunct, ";") {
+		p.next()
+	}
+	if !p.done() {
+		return nil, true, p.syntaxError()
+	}
+	return stmt, true, nil
+}
+
+func (p *parser) createReplicationSlot() (tree.Statement, error) {
+	n := &tree.CreateReplicationSlot{}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	n.Name = name
+	if p.peek(tokWord, "temporary") {
+		p.next()
+		n.Temporary = true
+	}
+	if p.peek(tokWord, "physical") {
+		return nil, unimplemented.New(
+			"replication.physical", "physical replication slots are not supported")
+	}
+	if err := p.expect("logical"); err != nil {
+		return nil, err
+	}
+	if n.Plugin, err = p.ident(); err != nil {
+		return nil, err
+	}
+	for _, action := range []string{"export_snapshot", "noexport_snapshot", "use_snapshot"} {
+		if p.peek(tokWord, action) {
+			p.next()
+			n.Snapshot = strings.ToUpper(action)
+			break
+		}
+	}
+	return n, nil
+}
+
+func (p *parser) dropReplicationSlot() (tree.Statement, error) {
+	n := &tree.DropReplicationSlot{}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	n.Name = name
+	if p.peek(tokWord, "wait") {
+		p.next()
+		n.Wait = true
+	}
+	return n, nil
+}
+
+func (p *parser) startReplication() (tree.Statement, error) {
+	n := &tree.StartReplication{}
+	if err := p.expect("slot"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	n.Slot = name
+	if p.peek(tokWord, "physical") {
+		return nil, unimplemented.New(
+			"replication.physical", "physical replication is not supported")
+	}
+	if err := p.expect("logical"); err != nil {
+		return nil, err
+	}
+	t := p.next()
+	if t.kind != tokWord {
+		return nil, p.syntaxError()
+	}
+	lsn, err := ParseLSN(t.val)
+	if err != nil {
+		return nil, err
+	}
+	n.LSN = uint64(lsn)
+
+	if !p.peek(tokPunct, "(") {
+		return n, nil
+	}
+	p.next()
+	for {
+		key, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		opt := tree.KVOption{Key: tree.Name(key)}
+		if p.peek(tokString, "") {
+			opt.Value = tree.NewStrVal(p.next().val)
+		}
+		n.Options = append(n.Options, opt)
+		if p.peek(tokPunct, ",") {
+			p.next()
+			continue
+		}
+		if p.peek(tokPunct, ")") {
+			p.next()
+			return n, nil
+		}
+		return nil, p.syntaxError()
+	}
+}
+
+type tokenKind int
+
+const (
+	// tokWord is an unquoted identifier or keyword, folded to lower case. LSNs
+	// are also lexed as words.
+	tokWord tokenKind = iota
+	// tokIdent is a double-quoted identifier.
+	tokIdent
+	// tokString is a single-quoted string literal.
+	tokString
+	// tokPunct is one of ( ) , ;
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+}
+
+// tokenize splits a replication command into tokens.
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(' || c == ')' || c == ',' || c == ';':
+			toks = append(toks, token{kind: tokPunct, val: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; ; j++ {
+				if j >= len(s) {
+					return nil, pgerror.New(pgcode.Syntax, "unterminated quoted string")
+				}
+				if s[j] == c {
+					if j+1 < len(s) && s[j+1] == c {
+						j++
+					} else {
+						break
+					}
+				}
+				b.WriteByte(s[j])
+			}
+			kind := tokIdent
+			if c == '\'' {
+				kind = tokString
+			}
+			toks = append(toks, token{kind: kind, val: b.String()})
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && isWordChar(s[j]) {
+				j++
+			}
+			if j == i {
+				return nil, pgerror.Newf(pgcode.Syntax, "syntax error at or near %q", s[i:])
+			}
+			toks = append(toks, token{kind: tokWord, val: strings.ToLower(s[i:j])})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '/' || c == '$' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *parser) next() token {
+	if p.done() {
+		return token{kind: tokPunct}
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	return t
+}
+
+// peek returns whether the next token has the given kind and, if val is not
+// empty, the given value.
+func (p *parser) peek(kind tokenKind, val string) bool {
+	if p.done() {
+		return false
+	}
+	t := p.toks[p.pos]
+	return t.kind == kind && (val == "" || t.val == val)
+}
+
+func (p *parser) expect(keyword string) error {
+	if !p.peek(tokWord, keyword) {
+		return p.syntaxError()
+	}
+	p.next()
+	return nil
+}
+
+// ident consumes a quoted or unquoted identifier.
+func (p *parser) ident() (string, error) {
+	if !p.peek(tokWord, "") && !p.peek(tokIdent, "") {
+		return "", p.syntaxError()
+	}
+	return p.next().val, nil
+}
+
+func (p *parser) name() (tree.Name, error) {
+	s, err := p.ident()
+	return tree.Name(s), err
+}
+
+func (p *parser) syntaxError() error {
+	if p.done() {
+		return pgerror.New(pgcode.Syntax, "syntax error at end of input")
+	}
+	return pgerror.Newf(pgcode.Syntax, "syntax error at or near %q", p.toks[p.pos].val)
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		in  string
+		out string
+	}{
+		{`IDENTIFY_SYSTEM`, `IDENTIFY_SYSTEM`},
+		{`identify_system;`, `IDENTIFY_SYSTEM`},
+		{`CREATE_REPLICATION_SLOT s LOGICAL pgoutput`, `CREATE_REPLICATION_SLOT s LOGICAL pgoutput`},
+		{`CREATE_REPLICATION_SLOT "My Slot" TEMPORARY LOGICAL pgoutput NOEXPORT_SNAPSHOT`,
+			`CREATE_REPLICATION_SLOT "My Slot" TEMPORARY LOGICAL pgoutput NOEXPORT_SNAPSHOT`},
+		{`DROP_REPLICATION_SLOT s WAIT`, `DROP_REPLICATION_SLOT s WAIT`},
+		{`START_REPLICATION SLOT s LOGICAL 0/0`, `START_REPLICATION SLOT s LOGICAL 0/0`},
+		{`START_REPLICATION SLOT "s" LOGICAL 16/B374D848 ("proto_version" '1', publication_names '"a","b"')`,
+			`START_REPLICATION SLOT s LOGICAL 16/B374D848 (proto_version '1', publication_names '"a","b"')`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			stmt, ok, err := Parse(tc.in)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, tc.out, stmt.String())
+		})
+	}
+
+	for _, sql := range []string{
+		`SELECT 1`,
+		`SHOW server_version`,
+		`select 'START_REPLICATION'`,
+		``,
+	} {
+		_, ok, err := Parse(sql)
+		require.NoError(t, err)
+		require.False(t, ok, sql)
+	}
+
+	errCases := []struct {
+		in  string
+		err string
+	}{
+		{`IDENTIFY_SYSTEM foo`, `syntax error at or near "foo"`},
+		{`CREATE_REPLICATION_SLOT s`, `syntax error at end of input`},
+		{`CREATE_REPLICATION_SLOT s PHYSICAL`, `unimplemented: physical replication slots are not supported`},
+		{`START_REPLICATION SLOT s LOGICAL 0/0 (proto_version '1'`, `syntax error at end of input`},
+		{`START_REPLICATION SLOT s LOGICAL x`, `invalid LSN: "x"`},
+		{`START_REPLICATION 0/0`, `syntax error at or near "0/0"`},
+		{`BASE_BACKUP`, `unimplemented: replication command BASE_BACKUP is not supported`},
+	}
+	for _, tc := range errCases {
+		t.Run(tc.in, func(t *testing.T) {
+			_, ok, err := Parse(tc.in)
+			require.True(t, ok)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestLSN(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, s := range []string{"0/0", "16/B374D848", "FFFFFFFF/FFFFFFFF"} {
+		lsn, err := ParseLSN(s)
+		require.NoError(t, err)
+		require.Equal(t, s, lsn.String())
+		require.Equal(t, lsn, LSNFromTimestamp(lsn.Timestamp()))
+	}
+	lsn, err := ParseLSN("16/b374d848")
+	require.NoError(t, err)
+	require.Equal(t, LSN(0x16B374D848), lsn)
+
+	for _, s := range []string{"", "0", "0/", "/0", "1/2/3", "100000000/0"} {
+		_, err := ParseLSN(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	parseOptions := func(opts string) (Options, error) {
+		stmt, _, err := Parse(`START_REPLICATION SLOT s LOGICAL 0/0 ` + opts)
+		require.NoError(t, err)
+		return ParseOptions(stmt.(*tree.StartReplication).Options)
+	}
+
+	testCases := []struct {
+		opts string
+		pubs []string
+	}{
+		{`(proto_version '1', publication_names 'a')`, []string{"a"}},
+		{`(proto_version '1', publication_names '"A" , b,"c""d"', binary 'false')`, []string{"A", "b", `c"d`}},
+		{`(publication_names 'Pub', proto_version '1', messages 'off')`, []string{"pub"}},
+	}
+	for _, tc := range testCases {
+		opts, err := parseOptions(tc.opts)
+		require.NoError(t, err, tc.opts)
+		require.Equal(t, tc.pubs, opts.Publications)
+	}
+
+	errCases := []struct {
+		opts string
+		err  string
+	}{
+		{``, `proto_version option missing`},
+		{`(proto_version '1')`, `publication_names parameter missing`},
+		{`(proto_version '2', publication_names 'a')`, `client sent proto_version=2 but we only support protocol 1`},
+		{`(proto_version '1', publication_names 'a', binary 'true')`, `option binary is not supported`},
+		{`(proto_version '1', publication_names 'a,')`, `invalid publication_names syntax: "a,"`},
+		{`(proto_version '1', publication_names '"a')`, `invalid publication_names syntax: "\"a"`},
+		{`(proto_version '1', publication_names 'a', foo 'bar')`, `unrecognized pgoutput option: foo`},
+	}
+	for _, tc := range errCases {
+		_, err := parseOptions(tc.opts)
+		require.EqualError(t, err, tc.err, tc.opts)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/lib/pq/oid"
+)
+
+// PluginName is the name of the only supported output plugin. Its messages
+// are described in:
+// https://www.postgresql.org/docs/current/protocol-logicalrep-message-formats.html
+const PluginName = "pgoutput"
+
+// ProtocolVersion is the version of the pgoutput protocol that is spoken.
+const ProtocolVersion = 1
+
+// Message types of the pgoutput protocol.
+const (
+	msgBegin    = 'B'
+	msgCommit   = 'C'
+	msgRelation = 'R'
+	msgInsert   = 'I'
+	msgUpdate   = 'U'
+	msgDelete   = 'D'
+
+	tupleNew  = 'N'
+	tupleOld  = 'O'
+	tupleNull = 'n'
+	tupleText = 't'
+
+	// replicaIdentityFull indicates that the old tuple of updates and deletes
+	// contains the values of all the columns.
+	replicaIdentityFull = 'f'
+)
+
+// Message types of the streaming replication protocol that are embedded in
+// CopyData messages.
+const (
+	msgXLogData      = 'w'
+	msgKeepalive     = 'k'
+	msgStandbyStatus = 'r'
+	// MsgHotStandbyFeedback is sent by physical standbys. It is accepted and
+	// ignored.
+	MsgHotStandbyFeedback = 'h'
+)
+
+// pgEpoch is the epoch of Postgres timestamps in the replication protocol.
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func pgTimestamp(t time.Time) int64 {
+	return t.Sub(pgEpoch).Microseconds()
+}
+
+// Column describes a column of a relation to the client.
+type Column struct {
+	Name    string
+	TypeOID oid.Oid
+	TypeMod int32
+	// Key is set for the columns of the primary key.
+	Key bool
+}
+
+// Encoder builds pgoutput and replication protocol messages. The returned
+// slices are only valid until the next call.
+type Encoder struct {
+	buf    []byte
+	fmtCtx *tree.FmtCtx
+}
+
+// NewEncoder creates an Encoder.
+func NewEncoder() *Encoder {
+	return &Encoder{fmtCtx: tree.NewFmtCtx(tree.FmtPgwireText)}
+}
+
+func (e *Encoder) reset(typ byte) {
+	e.buf = append(e.buf[:0], typ)
+}
+
+func (e *Encoder) putInt8(v byte) {
+	e.buf = append(e.buf, v)
+}
+
+func (e *Encoder) putInt16(v int16) {
+	e.buf = append(e.buf, 0, 0)
+	binary.BigEndian.PutUint16(e.buf[len(e.buf)-2:], uint16(v))
+}
+
+func (e *Encoder) putInt32(v int32) {
+	e.buf = append(e.buf, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(e.buf[len(e.buf)-4:], uint32(v))
+}
+
+func (e *Encoder) putInt64(v int64) {
+	e.buf = append(e.buf, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(e.buf[len(e.buf)-8:], uint64(v))
+}
+
+func (e *Encoder) putString(s string) {
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+// Begin encodes the message starting a transaction.
+func (e *Encoder) Begin(finalLSN LSN, commitTime time.Time, xid uint32) []byte {
+	e.reset(msgBegin)
+	e.putInt64(int64(finalLSN))
+	e.putInt64(pgTimestamp(commitTime))
+	e.putInt32(int32(xid))
+	return e.buf
+}
+
+// Commit encodes the message ending a transaction.
+func (e *Encoder) Commit(commitLSN, endLSN LSN, commitTime time.Time) []byte {
+	e.reset(msgCommit)
+	e.putInt8(0 /* flags */)
+	e.putInt64(int64(commitLSN))
+	e.putInt64(int64(endLSN))
+	e.putInt64(pgTimestamp(commitTime))
+	return e.buf
+}
+
+// Relation encodes the message describing the columns of a relation. It must
+// be sent before the first change to the relation in the stream and whenever
+// its schema changes.
+func (e *Encoder) Relation(relID oid.Oid, namespace, name string, cols []Column) []byte {
+	e.reset(msgRelation)
+	e.putInt32(int32(relID))
+	e.putString(namespace)
+	e.putString(name)
+	e.putInt8(replicaIdentityFull)
+	e.putInt16(int16(len(cols)))
+	for _, c := range cols {
+		var flags byte
+		if c.Key {
+			flags = 1
+		}
+		e.putInt8(flags)
+		e.putString(c.Name)
+		e.putInt32(int32(c.TypeOID))
+		e.putInt32(c.TypeMod)
+	}
+	return e.buf
+}
+
+// Insert encodes an inserted row.
+func (e *Encoder) Insert(relID oid.Oid, row tree.Datums) []byte {
+	e.reset(msgInsert)
+	e.putInt32(int32(relID))
+	e.putInt8(tupleNew)
+	e.putTuple(row)
+	return e.buf
+}
+
+// Update encodes an updated row. The previous values of the row are included
+// if prev is not nil.
+func (e *Encoder) Update(relID oid.Oid, prev, row tree.Datums) []byte {
+	e.reset(msgUpdate)
+	e.putInt32(int32(relID))
+	if prev != nil {
+		e.putInt8(tupleOld)
+		e.putTuple(prev)
+	}
+	e.putInt8(tupleNew)
+	e.putTuple(row)
+	return e.buf
+}
+
+// Delete encodes a deleted row, given its previous values.
+func (e *Encoder) Delete(relID oid.Oid, prev tree.Datums) []byte {
+	e.reset(msgDelete)
+	e.putInt32(int32(relID))
+	e.putInt8(tupleOld)
+	e.putTuple(prev)
+	return e.buf
+}
+
+func (e *Encoder) putTuple(row tree.Datums) {
+	e.putInt16(int16(len(row)))
+	for _, d := range row {
+		if d == tree.DNull {
+			e.putInt8(tupleNull)
+			continue
+		}
+		e.putInt8(tupleText)
+		s := e.formatText(d)
+		e.putInt32(int32(len(s)))
+		e.buf = append(e.buf, s...)
+	}
+}
+
+// formatText formats a non-NULL datum in the text format of Postgres.
+func (e *Encoder) formatText(d tree.Datum) string {
+	switch v := tree.UnwrapDatum(nil, d).(type) {
+	case *tree.DString:
+		return string(*v)
+	case *tree.DCollatedString:
+		return v.Contents
+	case *tree.DBytes:
+		return lex.EncodeByteArrayToRawBytes(string(*v), sessiondatapb.BytesEncodeHex, false /* skipHexPrefix */)
+	case *tree.DJSON:
+		return v.JSON.String()
+	case *tree.DIPAddr:
+		return v.IPAddr.String()
+	case *tree.DGeography:
+		return v.Geography.EWKBHex()
+	case *tree.DGeometry:
+		return v.Geometry.EWKBHex()
+	}
+	e.fmtCtx.Reset()
+	e.fmtCtx.FormatNode(d)
+	return e.fmtCtx.String()
+}
+
+// XLogData wraps a pgoutput message in the message used to send it to the
+// client inside a CopyData message. walEnd is the current end of the stream
+// on the server.
+func XLogData(buf []byte, start, walEnd LSN, data []byte) []byte {
+	buf = append(buf[:0], msgXLogData)
+	buf = appendInt64(buf, int64(start))
+	buf = appendInt64(buf, int64(walEnd))
+	buf = appendInt64(buf, pgTimestamp(timeutil.Now()))
+	return append(buf, data...)
+}
+
+// Keepalive encodes a primary keepalive message. If replyRequested is set,
+// the client should reply with a standby status update immediately.
+func Keepalive(buf []byte, walEnd LSN, replyRequested bool) []byte {
+	buf = append(buf[:0], msgKeepalive)
+	buf = appendInt64(buf, int64(walEnd))
+	buf = appendInt64(buf, pgTimestamp(timeutil.Now()))
+	if replyRequested {
+		return append(buf, 1)
+	}
+	return append(buf, 0)
+}
+
+func appendInt64(buf []byte, v int64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	return append(buf, b[:]...)
+}
+
+// StandbyStatus is the status update periodically sent by the client.
+type StandbyStatus struct {
+	// Written, Flushed and Applied are the positions up to which the client
+	// has received, durably stored and applied changes. Only Flushed is used
+	// by the server: changes before it are never sent again.
+	Written, Flushed, Applied LSN
+	// ReplyRequested is set if the client wants a keepalive in response.
+	ReplyRequested bool
+}
+
+// IsStandbyStatus returns whether the payload of a CopyData message sent by
+// the client is a standby status update.
+func IsStandbyStatus(data []byte) bool {
+	return len(data) > 0 && data[0] == msgStandbyStatus
+}
+
+// ParseStandbyStatus decodes the payload of a CopyData message containing a
+// standby status update.
+func ParseStandbyStatus(data []byte) (StandbyStatus, error) {
+	// Type, three positions, the client's clock and the reply flag.
+	const size = 1 + 8*4 + 1
+	if len(data) < size || data[0] != msgStandbyStatus {
+		return StandbyStatus{}, pgerror.New(pgcode.ProtocolViolation,
+			"invalid standby status update message")
+	}
+	return StandbyStatus{
+		Written:        LSN(binary.BigEndian.Uint64(data[1:])),
+		Flushed:        LSN(binary.BigEndian.Uint64(data[9:])),
+		Applied:        LSN(binary.BigEndian.Uint64(data[17:])),
+		ReplyRequested: data[33] != 0,
+	}, nil
+}
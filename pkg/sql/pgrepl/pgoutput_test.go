@@ -0,0 +1,122 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgrepl
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/lib/pq/oid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	e := NewEncoder()
+	commitTime := time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC)
+
+	require.Equal(t, []byte{
+		'B',
+		0, 0, 0, 0, 0, 0, 0, 0x10, // final LSN
+		0, 0, 0, 0, 0, 0x0f, 0x42, 0x40, // commit timestamp
+		0, 0, 0, 7, // xid
+	}, e.Begin(0x10, commitTime, 7))
+
+	require.Equal(t, []byte{
+		'C',
+		0,                         // flags
+		0, 0, 0, 0, 0, 0, 0, 0x10, // commit LSN
+		0, 0, 0, 0, 0, 0, 0, 0x11, // end LSN
+		0, 0, 0, 0, 0, 0x0f, 0x42, 0x40, // commit timestamp
+	}, e.Commit(0x10, 0x11, commitTime))
+
+	require.Equal(t, append(append([]byte{
+		'R',
+		0, 0, 0, 53, // relation ID
+	}, "public\x00t\x00"...),
+		'f',  // replica identity
+		0, 2, // columns
+		1, 'k', 0, 0, 0, 0, 20, 0xff, 0xff, 0xff, 0xff,
+		0, 'v', 0, 0, 0, 0, 25, 0xff, 0xff, 0xff, 0xff,
+	), e.Relation(53, "public", "t", []Column{
+		{Name: "k", TypeOID: oid.T_int8, TypeMod: -1, Key: true},
+		{Name: "v", TypeOID: oid.T_text, TypeMod: -1},
+	}))
+
+	row := tree.Datums{tree.NewDInt(12), tree.NewDString("a'b")}
+	require.Equal(t, []byte{
+		'I', 0, 0, 0, 53, 'N',
+		0, 2,
+		't', 0, 0, 0, 2, '1', '2',
+		't', 0, 0, 0, 3, 'a', '\'', 'b',
+	}, e.Insert(53, row))
+
+	require.Equal(t, []byte{
+		'U', 0, 0, 0, 53,
+		'O', 0, 2,
+		't', 0, 0, 0, 2, '1', '2',
+		'n',
+		'N', 0, 2,
+		't', 0, 0, 0, 2, '1', '2',
+		't', 0, 0, 0, 3, 'a', '\'', 'b',
+	}, e.Update(53, tree.Datums{tree.NewDInt(12), tree.DNull}, row))
+
+	require.Equal(t, []byte{
+		'D', 0, 0, 0, 53, 'O',
+		0, 2,
+		't', 0, 0, 0, 1, 't',
+		't', 0, 0, 0, 4, '\\', 'x', '0', '1',
+	}, e.Delete(53, tree.Datums{tree.DBoolTrue, tree.NewDBytes("\x01")}))
+}
+
+func TestStandbyStatus(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	msg := make([]byte, 34)
+	msg[0] = 'r'
+	binary.BigEndian.PutUint64(msg[1:], 3)
+	binary.BigEndian.PutUint64(msg[9:], 2)
+	binary.BigEndian.PutUint64(msg[17:], 1)
+	msg[33] = 1
+	require.True(t, IsStandbyStatus(msg))
+	s, err := ParseStandbyStatus(msg)
+	require.NoError(t, err)
+	require.Equal(t, StandbyStatus{Written: 3, Flushed: 2, Applied: 1, ReplyRequested: true}, s)
+
+	_, err = ParseStandbyStatus(msg[:20])
+	require.EqualError(t, err, "invalid standby status update message")
+
+	f := NewFeedback()
+	f.Update(s)
+	f.Update(StandbyStatus{Flushed: 1})
+	<-f.Updated()
+	flushed, reply := f.Status()
+	require.Equal(t, LSN(2), flushed)
+	require.True(t, reply)
+	_, reply = f.Status()
+	require.False(t, reply)
+
+	keepalive := Keepalive(nil, 0x10, true)
+	require.Len(t, keepalive, 18)
+	require.Equal(t, byte('k'), keepalive[0])
+	require.Equal(t, uint64(0x10), binary.BigEndian.Uint64(keepalive[1:]))
+	require.Equal(t, byte(1), keepalive[17])
+
+	xlog := XLogData(nil, 0x10, 0x20, []byte("data"))
+	require.Equal(t, byte('w'), xlog[0])
+	require.Equal(t, uint64(0x10), binary.BigEndian.Uint64(xlog[1:]))
+	require.Equal(t, uint64(0x20), binary.BigEndian.Uint64(xlog[9:]))
+	require.Equal(t, "data", string(xlog[25:]))
+}
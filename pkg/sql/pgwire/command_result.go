@@ -294,6 +294,15 @@ func (r *commandResult) ResetStmtType(stmt tree.Statement) {
 	r.cmdCompleteTag = stmt.StatementTag()
 }
 
+// ResetForStmtRetry is part of the CommandResult interface.
+func (r *commandResult) ResetForStmtRetry() {
+	r.assertNotReleased()
+	r.err = nil
+	r.rowsAffected = 0
+	r.buffer.notices = r.buffer.notices[:0]
+	r.buffer.paramStatusUpdates = r.buffer.paramStatusUpdates[:0]
+}
+
 // release frees the commandResult and allows its memory to be reused.
 func (r *commandResult) release() {
 	*r = commandResult{released: true}
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgrepl"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
@@ -711,6 +712,15 @@ func (c *conn) handleSimpleQuery(
 		return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
 	}
 
+	if c.sessionArgs.LogicalReplication {
+		if stmt, ok, err := pgrepl.Parse(query); ok {
+			if err != nil {
+				return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
+			}
+			return c.handleReplicationCommand(ctx, query, stmt, timeReceived)
+		}
+	}
+
 	startParse := timeutil.Now()
 	stmts, err := c.parser.ParseWithInt(query, unqualifiedIntSize)
 	if err != nil {
@@ -768,6 +778,77 @@ func (c *conn) handleSimpleQuery(
 	return nil
 }
 
+// handleReplicationCommand handles a command of the streaming replication
+// protocol. START_REPLICATION is special: like COPY, it takes control of the
+// connection. While the stream is running, this network routine reads the
+// messages sent by the client and passes them along to the stream.
+//
+// An error is returned iff the statement buffer has been closed or the
+// connection failed while streaming. In that case, the connection should be
+// considered toast.
+func (c *conn) handleReplicationCommand(
+	ctx context.Context, query string, stmt tree.Statement, timeReceived time.Time,
+) error {
+	sr, ok := stmt.(*tree.StartReplication)
+	if !ok {
+		return c.stmtBuf.Push(ctx, sql.ExecStmt{
+			Statement:    parser.Statement{AST: stmt, SQL: query},
+			TimeReceived: timeReceived,
+			ParseStart:   timeReceived,
+			ParseEnd:     timeReceived,
+		})
+	}
+
+	feedback := pgrepl.NewFeedback()
+	if err := c.stmtBuf.Push(ctx, sql.StartReplication{
+		Stmt: sr, Conn: c, Feedback: feedback,
+	}); err != nil {
+		return err
+	}
+	select {
+	case <-feedback.Started():
+	case <-feedback.Finished():
+		// The stream failed to start; the error is reported as the command's
+		// result.
+		return nil
+	}
+
+	stop := func() {
+		feedback.Stop()
+		<-feedback.Finished()
+	}
+	readBuf := &c.readBuf
+	for {
+		typ, _, err := readBuf.ReadTypedMsg(c.Rd())
+		if err != nil {
+			stop()
+			return err
+		}
+		switch typ {
+		case pgwirebase.ClientMsgCopyData:
+			if pgrepl.IsStandbyStatus(readBuf.Msg) {
+				status, err := pgrepl.ParseStandbyStatus(readBuf.Msg)
+				if err != nil {
+					stop()
+					return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
+				}
+				feedback.Update(status)
+			}
+			// Other messages, like hot standby feedback, don't apply to logical
+			// replication and are ignored.
+		case pgwirebase.ClientMsgCopyDone, pgwirebase.ClientMsgCopyFail, pgwirebase.ClientMsgTerminate:
+			stop()
+			return nil
+		default:
+			stop()
+			return c.stmtBuf.Push(ctx, sql.SendError{
+				Err: pgwirebase.NewProtocolViolationErrorf(
+					"unexpected message type %s during replication", typ),
+			})
+		}
+	}
+}
+
 // An error is returned iff the statement buffer has been closed. In that case,
 // the connection should be considered toast.
 func (c *conn) handleParse(
@@ -1079,6 +1160,36 @@ func (c *conn) Rd() pgwirebase.BufferedReader {
 	return &pgwireReader{conn: c}
 }
 
+// BeginCopyBoth is part of the pgwirebase.ReplicationConn interface.
+func (c *conn) BeginCopyBoth() error {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyBothResponse)
+	// Like Postgres, we don't describe any columns: the contents of the
+	// CopyData messages are defined by the replication protocol.
+	c.msgBuilder.writeByte(byte(pgwirebase.FormatText))
+	c.msgBuilder.putInt16(0)
+	return c.msgBuilder.finishMsg(c.conn)
+}
+
+// SendCopyData is part of the pgwirebase.ReplicationConn interface.
+func (c *conn) SendCopyData(data []byte) error {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyData)
+	c.msgBuilder.write(data)
+	return c.msgBuilder.finishMsg(c.conn)
+}
+
+// SendCopyDone is part of the pgwirebase.ReplicationConn interface.
+func (c *conn) SendCopyDone() error {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyDone)
+	return c.msgBuilder.finishMsg(c.conn)
+}
+
+var _ pgwirebase.ReplicationConn = &conn{}
+
+// SendError is part of the pgwirebase.ReplicationConn interface.
+func (c *conn) SendError(ctx context.Context, err error) error {
+	return writeErr(ctx, c.sv, err, &c.msgBuilder, c.conn)
+}
+
 // flushInfo encapsulates information about what results have been flushed to
 // the network.
 type flushInfo struct {
@@ -1548,6 +1659,11 @@ func (c *conn) CreateCopyInResult(pos sql.CmdPos) sql.CopyInResult {
 	return c.newMiscResult(pos, noCompletionMsg)
 }
 
+// CreateStartReplicationResult is part of the sql.ClientComm interface.
+func (c *conn) CreateStartReplicationResult(pos sql.CmdPos) sql.StartReplicationResult {
+	return c.newMiscResult(pos, noCompletionMsg)
+}
+
 // pgwireReader is an io.Reader that wraps a conn, maintaining its metrics as
 // it is consumed.
 type pgwireReader struct {
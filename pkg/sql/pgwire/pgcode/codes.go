@@ -387,4 +387,11 @@ var (
 	// 20.1 needs to recognize it coming from 19.2 nodes.
 	// TODO(andrei): remove in 20.2.
 	DeprecatedInternalConnectionFailure = ConnectionFailure
+
+	// Class XC - cockroach extension.
+	// CockroachDB distributed system related errors.
+
+	// UnsatisfiableBoundedStaleness signals that the bounded staleness query
+	// cannot be satisfied.
+	UnsatisfiableBoundedStaleness = MakeCode("XCUBS")
 )
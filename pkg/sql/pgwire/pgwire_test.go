@@ -14,11 +14,14 @@ import (
 	"context"
 	gosql "database/sql"
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -40,6 +43,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/otlppb"
 	"github.com/cockroachdb/errors"
 	"github.com/jackc/pgproto3/v2"
 	"github.com/jackc/pgx"
@@ -1809,11 +1814,14 @@ func TestSessionParameters(t *testing.T) {
 		{"TimeZone", "Europe/Amsterdam", true, true, ``},
 		{"datestyle", "ISO, MDY", false, true, ``},
 		{"DateStyle", "ISO, MDY", true, true, ``},
+		// Clients can provide their W3C trace context.
+		{"traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", false, true, ``},
 		// Known parameters that definitely cannot be set will cause an error.
 		{"server_version", "bar", false, false, `parameter "server_version" cannot be changed.*55P02`},
 		// Erroneous values are also rejected.
 		{"extra_float_digits", "42", false, false, `42 is outside the valid range for parameter "extra_float_digits".*22023`},
 		{"datestyle", "woo", false, false, `invalid value for parameter "DateStyle".*22023`},
+		{"traceparent", "woo", false, false, `invalid traceparent.*22023`},
 	}
 
 	for _, test := range testData {
@@ -1877,6 +1885,78 @@ func TestSessionParameters(t *testing.T) {
 	}
 }
 
+// TestTraceParent verifies that the spans of the transactions of a session
+// are connected to the client span identified by the traceparent
+// connection parameter, when traces are sent to an OpenTelemetry
+// collector.
+func TestTraceParent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	spans := make(chan otlppb.Span, 1000)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var req otlppb.ExportTraceServiceRequest
+		if err := protoutil.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+			return
+		}
+		for _, rs := range req.ResourceSpans {
+			for _, ils := range rs.InstrumentationLibrarySpans {
+				for _, sp := range ils.Spans {
+					select {
+					case spans <- sp:
+					default:
+					}
+				}
+			}
+		}
+	}))
+	defer collector.Close()
+
+	ctx := context.Background()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlutils.MakeSQLRunner(sqlDB).Exec(t,
+		`SET CLUSTER SETTING trace.opentelemetry.collector = $1`, collector.URL)
+
+	const traceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanup()
+	q := pgURL.Query()
+	q.Add("traceparent", traceParent)
+	pgURL.RawQuery = q.Encode()
+	db, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The cluster setting is applied asynchronously: retry until the span
+	// of a transaction is exported.
+	testutils.SucceedsSoon(t, func() error {
+		if _, err := db.Exec("SELECT 1"); err != nil {
+			t.Fatal(err)
+		}
+		for {
+			select {
+			case sp := <-spans:
+				if sp.Name == "sql txn" &&
+					hex.EncodeToString(sp.TraceID) == "0af7651916cd43dd8448eb211c80319c" &&
+					hex.EncodeToString(sp.ParentSpanID) == "b7ad6b7169203331" {
+					return nil
+				}
+			case <-time.After(2 * time.Second):
+				return errors.New("transaction span not exported yet")
+			}
+		}
+	})
+}
+
 type pgxTestLogger struct{}
 
 func (l pgxTestLogger) Log(level pgx.LogLevel, msg string, data map[string]interface{}) {
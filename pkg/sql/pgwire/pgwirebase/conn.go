@@ -38,3 +38,30 @@ type Conn interface {
 	// payload.
 	SendCommandComplete(tag []byte) error
 }
+
+// ReplicationConn exposes the functionality of a pgwire network connection
+// used by the streaming replication sub-protocol (START_REPLICATION)
+// implemented in the sql package. Messages are written to the network
+// directly, without going through the connection's result buffer.
+//
+// See: https://www.postgresql.org/docs/current/protocol-replication.html
+type ReplicationConn interface {
+	// BeginCopyBoth sends the message initiating the CopyBoth sub-protocol,
+	// after which both sides exchange CopyData messages.
+	BeginCopyBoth() error
+
+	// SendCopyData sends a CopyData message with the given payload.
+	SendCopyData(data []byte) error
+
+	// SendCopyDone sends a CopyDone message, ending the server's side of the
+	// CopyBoth sub-protocol.
+	SendCopyDone() error
+
+	// SendCommandComplete sends a serverMsgCommandComplete with the given
+	// payload.
+	SendCommandComplete(tag []byte) error
+
+	// SendError sends an error to the client. It is used to report errors that
+	// occur while streaming, after which the connection is closed.
+	SendError(ctx context.Context, err error) error
+}
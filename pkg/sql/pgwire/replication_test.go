@@ -0,0 +1,293 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgrepl"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// replicationClient is a minimal client of the streaming replication
+// protocol.
+type replicationClient struct {
+	t  *testing.T
+	fe *pgproto3.Frontend
+}
+
+func newReplicationClient(t *testing.T, addr string, params map[string]string) *replicationClient {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	c := &replicationClient{t: t, fe: pgproto3.NewFrontend(pgproto3.NewChunkReader(conn), conn)}
+	require.NoError(t, c.fe.Send(&pgproto3.StartupMessage{
+		ProtocolVersion: version30,
+		Parameters:      params,
+	}))
+	for {
+		msg, err := c.fe.Receive()
+		require.NoError(t, err)
+		switch m := msg.(type) {
+		case *pgproto3.ErrorResponse:
+			t.Fatalf("connection failed: %s", m.Message)
+		case *pgproto3.ReadyForQuery:
+			return c
+		}
+	}
+}
+
+// query runs a query and returns the rows, or the error message.
+func (c *replicationClient) query(q string) ([][]string, string) {
+	require.NoError(c.t, c.fe.Send(&pgproto3.Query{String: q}))
+	var rows [][]string
+	var errMsg string
+	for {
+		msg, err := c.fe.Receive()
+		require.NoError(c.t, err)
+		switch m := msg.(type) {
+		case *pgproto3.DataRow:
+			row := make([]string, len(m.Values))
+			for i, v := range m.Values {
+				row[i] = string(v)
+			}
+			rows = append(rows, row)
+		case *pgproto3.ErrorResponse:
+			errMsg = m.Message
+		case *pgproto3.ReadyForQuery:
+			return rows, errMsg
+		}
+	}
+}
+
+// startReplication starts streaming and returns the pgoutput messages of the
+// first n transactions, along with the LSN of the last commit.
+func (c *replicationClient) startReplication(q string, n int) ([][]byte, pgrepl.LSN) {
+	require.NoError(c.t, c.fe.Send(&pgproto3.Query{String: q}))
+	msg, err := c.fe.Receive()
+	require.NoError(c.t, err)
+	if e, ok := msg.(*pgproto3.ErrorResponse); ok {
+		c.t.Fatalf("START_REPLICATION failed: %s", e.Message)
+	}
+	require.IsType(c.t, &pgproto3.CopyBothResponse{}, msg)
+	return c.readTransactions(n)
+}
+
+// readTransactions returns the pgoutput messages of the next n transactions
+// of a started stream, along with the LSN of the last commit.
+func (c *replicationClient) readTransactions(n int) ([][]byte, pgrepl.LSN) {
+	var msgs [][]byte
+	var commitLSN pgrepl.LSN
+	for n > 0 {
+		msg, err := c.fe.Receive()
+		require.NoError(c.t, err)
+		data := msg.(*pgproto3.CopyData).Data
+		if data[0] != 'w' {
+			// Keepalive.
+			continue
+		}
+		payload := append([]byte(nil), data[25:]...)
+		msgs = append(msgs, payload)
+		if payload[0] == 'C' {
+			commitLSN = pgrepl.LSN(binary.BigEndian.Uint64(payload[2:]))
+			n--
+		}
+	}
+	return msgs, commitLSN
+}
+
+// expectStreamError reads the stream until it ends with an error.
+func (c *replicationClient) expectStreamError(errRE string) {
+	for {
+		msg, err := c.fe.Receive()
+		require.NoError(c.t, err)
+		switch m := msg.(type) {
+		case *pgproto3.CopyData:
+		case *pgproto3.ErrorResponse:
+			require.Regexp(c.t, errRE, m.Message)
+			return
+		default:
+			c.t.Fatalf("unexpected message %T", msg)
+		}
+	}
+}
+
+// stopReplication acknowledges a position and ends the stream.
+func (c *replicationClient) stopReplication(flushed pgrepl.LSN) {
+	status := make([]byte, 34)
+	status[0] = 'r'
+	binary.BigEndian.PutUint64(status[1:], uint64(flushed))
+	binary.BigEndian.PutUint64(status[9:], uint64(flushed))
+	binary.BigEndian.PutUint64(status[17:], uint64(flushed))
+	require.NoError(c.t, c.fe.Send(&pgproto3.CopyData{Data: status}))
+	require.NoError(c.t, c.fe.Send(&pgproto3.CopyDone{}))
+	sawCopyDone := false
+	for {
+		msg, err := c.fe.Receive()
+		require.NoError(c.t, err)
+		switch m := msg.(type) {
+		case *pgproto3.CopyDone:
+			sawCopyDone = true
+		case *pgproto3.CommandComplete:
+			require.True(c.t, sawCopyDone)
+			require.Equal(c.t, "START_REPLICATION", string(m.CommandTag))
+		case *pgproto3.ErrorResponse:
+			c.t.Fatalf("unexpected error: %s", m.Message)
+		case *pgproto3.ReadyForQuery:
+			return
+		}
+	}
+}
+
+func TestLogicalReplication(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.closed_timestamp.target_duration = '100ms'`)
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.t (k INT PRIMARY KEY, v STRING, FAMILY (k, v))`)
+	sqlDB.Exec(t, `CREATE TABLE d.u (k INT PRIMARY KEY, FAMILY (k))`)
+	sqlDB.Exec(t, `SET DATABASE = d`)
+	sqlDB.Exec(t, `CREATE PUBLICATION p FOR TABLE t`)
+
+	c := newReplicationClient(t, s.ServingSQLAddr(), map[string]string{
+		"user": "root", "database": "d", "replication": "database",
+	})
+
+	rows, errMsg := c.query(`IDENTIFY_SYSTEM`)
+	require.Empty(t, errMsg)
+	require.Len(t, rows, 1)
+	require.Equal(t, []string{"1", "d"}, []string{rows[0][1], rows[0][3]})
+
+	rows, errMsg = c.query(`CREATE_REPLICATION_SLOT s LOGICAL pgoutput`)
+	require.Empty(t, errMsg)
+	require.Equal(t, "s", rows[0][0])
+	require.Equal(t, "pgoutput", rows[0][3])
+
+	_, errMsg = c.query(`CREATE_REPLICATION_SLOT s LOGICAL pgoutput`)
+	require.Equal(t, `replication slot "s" already exists`, errMsg)
+
+	// Errors before the stream starts leave the connection usable.
+	_, errMsg = c.query(`START_REPLICATION SLOT s LOGICAL 0/0 (proto_version '1', publication_names 'missing')`)
+	require.Equal(t, `publication "missing" does not exist`, errMsg)
+	_, errMsg = c.query(`START_REPLICATION SLOT s LOGICAL 0/0 (proto_version '1')`)
+	require.Equal(t, `publication_names parameter missing`, errMsg)
+	_, errMsg = c.query(`START_REPLICATION SLOT missing LOGICAL 0/0 (proto_version '1', publication_names 'p')`)
+	require.Equal(t, `replication slot "missing" does not exist`, errMsg)
+
+	// Regular statements can be run on replication connections.
+	rows, errMsg = c.query(`SELECT count(*) FROM t`)
+	require.Empty(t, errMsg)
+	require.Equal(t, [][]string{{"0"}}, rows)
+
+	sqlDB.Exec(t, `INSERT INTO t VALUES (1, 'a')`)
+	sqlDB.Exec(t, `INSERT INTO u VALUES (1)`)
+	sqlDB.Exec(t, `UPDATE t SET v = 'b' WHERE k = 1`)
+	sqlDB.Exec(t, `DELETE FROM t WHERE k = 1`)
+
+	const start = `START_REPLICATION SLOT s LOGICAL 0/0 (proto_version '1', publication_names '"p"')`
+	msgs, commitLSN := c.startReplication(start, 3)
+	var types []byte
+	for _, m := range msgs {
+		types = append(types, m[0])
+	}
+	require.Equal(t, "BRICBUCBDC", string(types))
+	// The relation describes the table.
+	require.Contains(t, string(msgs[1]), "public\x00t\x00")
+	// The inserted row.
+	require.Contains(t, string(msgs[2]), "\x00\x00\x00\x01a")
+	c.stopReplication(commitLSN)
+
+	// The acknowledged position is persisted.
+	sqlDB.CheckQueryResults(t,
+		`SELECT confirmed_flush_lsn FROM pg_catalog.pg_replication_slots WHERE slot_name = 's'`,
+		[][]string{{commitLSN.String()}})
+
+	// Streaming resumes after the acknowledged position.
+	sqlDB.Exec(t, `INSERT INTO t VALUES (2, 'c')`)
+	msgs, _ = c.startReplication(start, 1)
+	require.Equal(t, byte('I'), msgs[2][0])
+	require.Contains(t, string(msgs[2]), "\x00\x00\x00\x01c")
+	c.stopReplication(0)
+
+	// Dropping a published table deletes its data with a range tombstone,
+	// which isn't replicated, and restarting the stream skips the table.
+	sqlDB.Exec(t, `CREATE TABLE d.x (k INT PRIMARY KEY, FAMILY (k))`)
+	sqlDB.Exec(t, `INSERT INTO x VALUES (1)`)
+	sqlDB.Exec(t, `CREATE PUBLICATION q FOR TABLE t, x`)
+	_, errMsg = c.query(`CREATE_REPLICATION_SLOT drops LOGICAL pgoutput`)
+	require.Empty(t, errMsg)
+	const startDrops = `START_REPLICATION SLOT drops LOGICAL 0/0 (proto_version '1', publication_names 'q')`
+	c.startReplication(startDrops, 0)
+	var xID uint32
+	sqlDB.QueryRow(t, `SELECT 'x'::REGCLASS::INT`).Scan(&xID)
+	sqlDB.Exec(t, `DROP TABLE x`)
+	xPrefix := keys.SystemSQLCodec.TablePrefix(xID)
+	testutils.SucceedsSoon(t, func() error {
+		kvs, err := s.DB().Scan(ctx, xPrefix, xPrefix.PrefixEnd(), 0 /* maxRows */)
+		if err != nil {
+			return err
+		}
+		if len(kvs) != 0 {
+			return errors.Errorf("expected the data of x to be deleted, found %d keys", len(kvs))
+		}
+		return nil
+	})
+	sqlDB.Exec(t, `INSERT INTO t VALUES (3, 'd')`)
+	msgs, commitLSN = c.readTransactions(1)
+	require.Equal(t, byte('I'), msgs[2][0])
+	require.Contains(t, string(msgs[2]), "\x00\x00\x00\x01d")
+	c.stopReplication(commitLSN)
+	c.startReplication(startDrops, 0)
+	c.stopReplication(commitLSN)
+
+	params := map[string]string{"user": "root", "database": "d", "replication": "database"}
+
+	// The stream fails when the changes buffered until their timestamp is
+	// resolved outgrow the buffer.
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.logical_replication.buffer_size = '1B'`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES (4, 'e')`)
+	c.startReplication(start, 0)
+	c.expectStreamError(`buffering changes for replication slot s: .*memory budget exceeded`)
+	sqlDB.Exec(t, `RESET CLUSTER SETTING sql.logical_replication.buffer_size`)
+
+	// Tables are checked again when their schema changes while streaming.
+	c = newReplicationClient(t, s.ServingSQLAddr(), params)
+	c.startReplication(start, 0)
+	sqlDB.Exec(t, `ALTER TABLE t ADD COLUMN w INT CREATE FAMILY f2`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES (5, 'f', 5)`)
+	c.expectStreamError(`cannot publish table "t" with multiple column families`)
+
+	c = newReplicationClient(t, s.ServingSQLAddr(), params)
+	rows, errMsg = c.query(`DROP_REPLICATION_SLOT s`)
+	require.Empty(t, errMsg)
+	require.Empty(t, rows)
+	_, errMsg = c.query(`DROP_REPLICATION_SLOT drops`)
+	require.Empty(t, errMsg)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM system.replication_slots`, [][]string{{"0"}})
+}
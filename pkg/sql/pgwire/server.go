@@ -31,9 +31,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
@@ -755,6 +757,22 @@ func parseClientProvidedSessionParameters(
 			}
 			args.RemoteAddr = &net.TCPAddr{IP: ip, Port: port}
 
+		case "replication":
+			// See: https://www.postgresql.org/docs/current/protocol-replication.html
+			if strings.ToLower(value) == "database" {
+				args.LogicalReplication = true
+				break
+			}
+			physical, err := tree.ParseDBool(value)
+			if err != nil {
+				return sql.SessionArgs{}, pgerror.Newf(pgcode.ProtocolViolation,
+					"invalid value for parameter \"replication\": %q", value)
+			}
+			if *physical {
+				return sql.SessionArgs{}, unimplemented.New("replication.physical",
+					"physical replication is not supported")
+			}
+
 		default:
 			exists, configurable := sql.IsSessionVariableConfigurable(key)
 
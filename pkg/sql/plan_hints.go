@@ -0,0 +1,303 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/planhints"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// planHintsEnabled determines whether the plans pinned for statement
+// fingerprints are used by the optimizer.
+var planHintsEnabled = settings.RegisterBoolSetting(
+	"sql.plan_hints.enabled",
+	"if set, the plans pinned with crdb_internal.pin_plan are used when planning matching statements",
+	true,
+).WithPublic()
+
+// planHintsPollInterval is the interval at which each node reloads the pinned
+// plans, which picks up the plans pinned or unpinned on other nodes.
+var planHintsPollInterval = settings.RegisterDurationSetting(
+	"sql.plan_hints.poll_interval",
+	"the interval at which the plans pinned for statement fingerprints are reloaded",
+	30*time.Second,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+)
+
+// PlanHintsCache is a node-level cache of the contents of
+// system.statement_hints, keyed by statement fingerprint.
+type PlanHintsCache struct {
+	mu struct {
+		syncutil.RWMutex
+		hints map[string]*planhints.StatementHints
+		// generation is incremented by every local update, so that a reload
+		// which raced with an update doesn't overwrite it.
+		generation int64
+	}
+}
+
+// NewPlanHintsCache creates an empty PlanHintsCache. The cache is populated by
+// Server.Start.
+func NewPlanHintsCache() *PlanHintsCache {
+	c := &PlanHintsCache{}
+	c.mu.hints = make(map[string]*planhints.StatementHints)
+	return c
+}
+
+// find returns the hints for the given statement, or nil if there are none.
+func (c *PlanHintsCache) find(stmt *Statement) *planhints.StatementHints {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.mu.hints) == 0 {
+		return nil
+	}
+	ast, fingerprint := stmt.AST, stmt.AnonymizedStr
+	if e, ok := ast.(*tree.Explain); ok {
+		// The hints of the explained statement apply. Its fingerprint is only
+		// computed if some plan is pinned.
+		ast = e.Statement
+		fingerprint = ""
+	}
+	switch ast.(type) {
+	case *tree.ParenSelect, *tree.Select, *tree.SelectClause, *tree.UnionClause, *tree.ValuesClause,
+		*tree.Insert, *tree.Update, *tree.Delete:
+	default:
+		return nil
+	}
+	if fingerprint == "" {
+		fingerprint = anonymizeStmt(ast)
+	}
+	return c.mu.hints[fingerprint]
+}
+
+// set updates the hints for the given fingerprint. Nil hints remove the entry.
+func (c *PlanHintsCache) set(fingerprint string, hints *planhints.StatementHints) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.generation++
+	if hints == nil {
+		delete(c.mu.hints, fingerprint)
+	} else {
+		c.mu.hints[fingerprint] = hints
+	}
+}
+
+// planHintsForStmt returns the hints pinned for the planner's statement, or nil
+// if there are none or plan hints are disabled.
+func (p *planner) planHintsForStmt() *planhints.StatementHints {
+	if !planHintsEnabled.Get(&p.execCfg.Settings.SV) {
+		return nil
+	}
+	if _, isCanned := p.stmt.AST.(*tree.CannedOptPlan); isCanned {
+		return nil
+	}
+	return p.execCfg.PlanHintsCache.find(&p.stmt)
+}
+
+// periodicallyReloadPlanHints spawns a loop that reloads the pinned plans into
+// the PlanHintsCache.
+func (s *Server) periodicallyReloadPlanHints(ctx context.Context, stopper *stop.Stopper) {
+	if s.cfg.PlanHintsCache == nil {
+		return
+	}
+	_ = stopper.RunAsyncTask(ctx, "plan-hints-poller", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			if err := s.reloadPlanHints(ctx); err != nil && ctx.Err() == nil {
+				log.Warningf(ctx, "failed to reload plan hints: %v", err)
+			}
+			timer.Reset(planHintsPollInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+		}
+	})
+}
+
+// reloadPlanHints replaces the contents of the PlanHintsCache with the
+// contents of system.statement_hints.
+func (s *Server) reloadPlanHints(ctx context.Context) error {
+	if !s.cfg.Settings.Version.IsActive(ctx, clusterversion.StatementHintsTable) {
+		return nil
+	}
+	c := s.cfg.PlanHintsCache
+	c.mu.RLock()
+	generation := c.mu.generation
+	c.mu.RUnlock()
+	rows, err := s.cfg.InternalExecutor.QueryEx(ctx, "load-plan-hints", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT fingerprint, hints FROM system.statement_hints`,
+	)
+	if err != nil {
+		return err
+	}
+	hints := make(map[string]*planhints.StatementHints, len(rows))
+	for _, row := range rows {
+		fingerprint := string(tree.MustBeDString(row[0]))
+		var h planhints.StatementHints
+		if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(row[1])), &h); err != nil {
+			log.Warningf(ctx, "ignoring invalid plan hints for %q: %v", fingerprint, err)
+			continue
+		}
+		hints[fingerprint] = &h
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mu.generation != generation {
+		// The rows may predate a local update; the next reload picks it up.
+		return nil
+	}
+	c.mu.hints = hints
+	return nil
+}
+
+// checkPlanHintsPrivilege checks that the user can pin and unpin plans.
+func checkPlanHintsPrivilege(ctx context.Context, p *planner, op string) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.StatementHintsTable) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s requires all nodes to be upgraded to %s",
+			op, clusterversion.ByKey(clusterversion.StatementHintsTable))
+	}
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if !hasAdmin {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"only users with the admin role are allowed to use %s", op)
+	}
+	return nil
+}
+
+// PinPlan is part of the tree.EvalPlanner interface.
+func (p *planner) PinPlan(ctx context.Context, sql string) (string, error) {
+	if err := checkPlanHintsPrivilege(ctx, p, "crdb_internal.pin_plan"); err != nil {
+		return "", err
+	}
+	stmt, err := parser.ParseOne(sql)
+	if err != nil {
+		return "", err
+	}
+	switch stmt.AST.(type) {
+	case *tree.ParenSelect, *tree.Select, *tree.SelectClause, *tree.UnionClause, *tree.ValuesClause,
+		*tree.Insert, *tree.Update, *tree.Delete:
+	default:
+		return "", pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot pin the plan of a %s statement", stmt.AST.StatementTag())
+	}
+	if stmt.NumPlaceholders > 0 {
+		return "", pgerror.New(pgcode.FeatureNotSupported,
+			"cannot pin the plan of a statement with placeholders")
+	}
+
+	hints, err := p.capturePlanHints(ctx, stmt)
+	if err != nil {
+		return "", err
+	}
+	fingerprint := anonymizeStmt(stmt.AST)
+	hintsBytes, err := protoutil.Marshal(hints)
+	if err != nil {
+		return "", err
+	}
+	if _, err := p.ExecCfg().InternalExecutor.ExecEx(ctx, "pin-plan", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`UPSERT INTO system.statement_hints (fingerprint, hints) VALUES ($1, $2)`,
+		fingerprint, tree.NewDBytes(tree.DBytes(hintsBytes)),
+	); err != nil {
+		return "", err
+	}
+	p.ExecCfg().PlanHintsCache.set(fingerprint, hints)
+	return fingerprint, nil
+}
+
+// capturePlanHints optimizes the given statement, ignoring any plan pinned for
+// it, and returns the hints that reproduce the resulting plan. The statement
+// is planned by a separate planner, so that the planning state of the current
+// statement is left untouched.
+func (p *planner) capturePlanHints(
+	ctx context.Context, stmt parser.Statement,
+) (*planhints.StatementHints, error) {
+	ip, cleanup := newInternalPlanner(
+		"pin-plan", p.txn, p.User(), &MemoryMetrics{}, p.ExecCfg(), p.SessionData().SessionData,
+	)
+	defer cleanup()
+	// Resolve names like the current session, so that the captured plan is the
+	// one the session would get.
+	ip.SessionData().Database = p.SessionData().Database
+	ip.SessionData().SearchPath = p.SessionData().SearchPath
+	ip.semaCtx.SearchPath = p.SessionData().SearchPath
+	ip.stmt = makeStatement(stmt, ClusterWideID{})
+
+	opc := &ip.optPlanningCtx
+	opc.reset()
+	f := opc.optimizer.Factory()
+	f.FoldingControl().AllowStableFolds()
+	bld := optbuilder.New(ctx, &ip.semaCtx, ip.EvalContext(), &opc.catalog, f, stmt.AST)
+	bld.CaptureJoins = true
+	if err := bld.Build(); err != nil {
+		return nil, err
+	}
+	if _, err := opc.optimizer.Optimize(); err != nil {
+		return nil, err
+	}
+	return planhints.Capture(f.Memo(), bld.Joins), nil
+}
+
+// UnpinPlan is part of the tree.EvalPlanner interface.
+func (p *planner) UnpinPlan(ctx context.Context, fingerprint string) (bool, error) {
+	if err := checkPlanHintsPrivilege(ctx, p, "crdb_internal.unpin_plan"); err != nil {
+		return false, err
+	}
+	n, err := p.ExecCfg().InternalExecutor.ExecEx(ctx, "unpin-plan", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`DELETE FROM system.statement_hints WHERE fingerprint = $1`,
+		fingerprint,
+	)
+	if err != nil {
+		return false, err
+	}
+	p.ExecCfg().PlanHintsCache.set(fingerprint, nil)
+	return n > 0, nil
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/explain"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/planhints"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -56,7 +57,7 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 		*tree.BeginTransaction,
 		*tree.CommentOnColumn, *tree.CommentOnDatabase, *tree.CommentOnIndex, *tree.CommentOnTable,
 		*tree.CommitTransaction,
-		*tree.CopyFrom, *tree.CreateDatabase, *tree.CreateIndex, *tree.CreateView,
+		*tree.CopyFrom, *tree.CreateDatabase, *tree.CreateForeignTable, *tree.CreateIndex, *tree.CreateView,
 		*tree.CreateSequence,
 		*tree.CreateStats,
 		*tree.Deallocate, *tree.Discard, *tree.DropDatabase, *tree.DropIndex,
@@ -286,6 +287,11 @@ type optPlanningCtx struct {
 	// allowMemoReuse is false.
 	useCache bool
 
+	// planHints contains the hints pinned for the statement's fingerprint, if
+	// any. They are cleared if the optimizer cannot produce a plan conforming
+	// to them.
+	planHints *planhints.StatementHints
+
 	flags planFlags
 }
 
@@ -329,6 +335,25 @@ func (opc *optPlanningCtx) reset() {
 		opc.allowMemoReuse = false
 		opc.useCache = false
 	}
+
+	// Memos are cached by statement text and would not reflect plans pinned or
+	// unpinned after they were built, so memo reuse is disabled for statements
+	// with pinned plans.
+	opc.planHints = p.planHintsForStmt()
+	if opc.planHints != nil {
+		opc.allowMemoReuse = false
+		opc.useCache = false
+	}
+}
+
+// ignorePlanHints re-initializes the optimizer so that the statement can be
+// planned again without its pinned plan, after the optimizer failed to produce
+// a plan conforming to it.
+func (opc *optPlanningCtx) ignorePlanHints(ctx context.Context) {
+	log.Warningf(ctx, "could not produce a plan conforming to the plan pinned for %s",
+		opc.p.stmt.AnonymizedStr)
+	opc.planHints = nil
+	opc.optimizer.Init(opc.p.EvalContext(), &opc.catalog)
 }
 
 func (opc *optPlanningCtx) log(ctx context.Context, msg string) {
@@ -376,6 +401,7 @@ func (opc *optPlanningCtx) buildReusableMemo(ctx context.Context) (_ *memo.Memo,
 	f := opc.optimizer.Factory()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
 	bld.KeepPlaceholders = true
+	bld.PlanHints = opc.planHints
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
@@ -404,6 +430,10 @@ func (opc *optPlanningCtx) buildReusableMemo(ctx context.Context) (_ *memo.Memo,
 			if _, err := opc.optimizer.Optimize(); err != nil {
 				return nil, err
 			}
+			if opc.planHints != nil && !planhints.Conforms(f.Memo().RootExpr()) {
+				opc.ignorePlanHints(ctx)
+				return opc.buildReusableMemo(ctx)
+			}
 		}
 	}
 
@@ -504,6 +534,7 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 	f := opc.optimizer.Factory()
 	f.FoldingControl().AllowStableFolds()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
+	bld.PlanHints = opc.planHints
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
@@ -511,6 +542,10 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 		if _, err := opc.optimizer.Optimize(); err != nil {
 			return nil, err
 		}
+		if opc.planHints != nil && !planhints.Conforms(f.Memo().RootExpr()) {
+			opc.ignorePlanHints(ctx)
+			return opc.buildExecMemo(ctx)
+		}
 	}
 
 	// If this statement doesn't have placeholders and we have not constant-folded
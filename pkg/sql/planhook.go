@@ -24,7 +24,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
-	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
 // planHookFn is a function that can intercept a statement being planned and
@@ -86,7 +85,9 @@ type PlanHookState interface {
 	// TODO(mberhault): it would be easier to just pass a planner to plan hooks.
 	GetAllRoles(ctx context.Context) (map[security.SQLUsername]bool, error)
 	BumpRoleMembershipTableVersion(ctx context.Context) error
-	EvalAsOfTimestamp(ctx context.Context, asOf tree.AsOfClause) (hlc.Timestamp, error)
+	EvalAsOfTimestamp(
+		ctx context.Context, asOf tree.AsOfClause, opts ...tree.EvalAsOfTimestampOption,
+	) (tree.AsOfSystemTime, error)
 	ResolveUncachedDatabaseByName(
 		ctx context.Context, dbName string, required bool) (*dbdesc.Immutable, error)
 	ResolveMutableTableDescriptor(
@@ -0,0 +1,284 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+type createPublicationNode struct {
+	n *tree.CreatePublication
+}
+
+// CreatePublication creates a publication, a set of tables whose changes can
+// be streamed to logical replication clients.
+// Privileges: CREATE on the current database, and ownership of the tables.
+// Creating a publication FOR ALL TABLES requires the admin role.
+func (p *planner) CreatePublication(
+	ctx context.Context, n *tree.CreatePublication,
+) (planNode, error) {
+	if err := checkPublicationsSupported(ctx, p.ExecCfg()); err != nil {
+		return nil, err
+	}
+	return &createPublicationNode{n: n}, nil
+}
+
+func checkPublicationsSupported(ctx context.Context, execCfg *ExecutorConfig) error {
+	if !execCfg.Settings.Version.IsActive(ctx, clusterversion.PublicationsAndReplicationSlots) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"logical replication requires all nodes to be upgraded to %s",
+			clusterversion.ByKey(clusterversion.PublicationsAndReplicationSlots))
+	}
+	return nil
+}
+
+func (n *createPublicationNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("publication"))
+	ctx, p := params.ctx, params.p
+
+	_, dbDesc, err := p.Descriptors().GetImmutableDatabaseByName(
+		ctx, p.txn, p.CurrentDatabase(), tree.DatabaseLookupFlags{Required: true})
+	if err != nil {
+		return err
+	}
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return err
+	}
+	if n.n.AllTables {
+		if err := p.RequireAdminRole(ctx, "CREATE PUBLICATION ... FOR ALL TABLES"); err != nil {
+			return err
+		}
+	}
+
+	tableIDs := tree.NewDArray(types.Int)
+	seen := make(map[descpb.ID]struct{}, len(n.n.Tables))
+	for i := range n.n.Tables {
+		tn := &n.n.Tables[i]
+		desc, err := p.ResolveUncachedTableDescriptor(
+			ctx, tn, true /* required */, tree.ResolveRequireTableDesc)
+		if err != nil {
+			return err
+		}
+		if desc.GetParentID() != dbDesc.GetID() {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"table %q is not in the current database %q", tn.Table(), dbDesc.GetName())
+		}
+		hasOwnership, err := p.HasOwnership(ctx, desc)
+		if err != nil {
+			return err
+		}
+		if !hasOwnership {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"must be owner of table %s", tn.Table())
+		}
+		if err := checkPublicationTable(desc); err != nil {
+			return err
+		}
+		if _, ok := seen[desc.GetID()]; ok {
+			continue
+		}
+		seen[desc.GetID()] = struct{}{}
+		if err := tableIDs.Append(tree.NewDInt(tree.DInt(desc.GetID()))); err != nil {
+			return err
+		}
+	}
+
+	ie := p.ExecCfg().InternalExecutor
+	row, err := ie.QueryRowEx(ctx, "check-publication", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT 1 FROM system.publications WHERE database_id = $1 AND name = $2`,
+		dbDesc.GetID(), string(n.n.Name),
+	)
+	if err != nil {
+		return err
+	}
+	if row != nil {
+		return pgerror.Newf(pgcode.DuplicateObject,
+			"publication %q already exists", string(n.n.Name))
+	}
+
+	var tables tree.Datum = tree.DNull
+	if !n.n.AllTables {
+		tables = tableIDs
+	}
+	_, err = ie.ExecEx(ctx, "create-publication", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`INSERT INTO system.publications (database_id, name, owner, all_tables, table_ids)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		dbDesc.GetID(), string(n.n.Name), p.User().Normalized(), n.n.AllTables, tables,
+	)
+	return err
+}
+
+// checkPublicationTable checks whether a table can be part of a publication.
+// Only tables that store their data in the KV layer can be streamed, and each
+// change must be decodable from a single key.
+func checkPublicationTable(desc catalog.TableDescriptor) error {
+	if !desc.IsTable() || desc.IsVirtualTable() || desc.IsForeignTable() {
+		return pgerror.Newf(pgcode.WrongObjectType,
+			"%q is not a table that can be published", desc.GetName())
+	}
+	if desc.IsTemporary() {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"cannot publish temporary table %q", desc.GetName())
+	}
+	if desc.IsInterleaved() || desc.GetPrimaryIndex().NumInterleavedBy() > 0 {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot publish interleaved table %q", desc.GetName())
+	}
+	if len(desc.GetFamilies()) != 1 {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot publish table %q with multiple column families", desc.GetName())
+	}
+	return nil
+}
+
+func (*createPublicationNode) Next(runParams) (bool, error) { return false, nil }
+func (*createPublicationNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createPublicationNode) Close(context.Context)        {}
+
+type dropPublicationNode struct {
+	n *tree.DropPublication
+}
+
+// DropPublication removes a publication.
+// Privileges: ownership of the publication.
+func (p *planner) DropPublication(ctx context.Context, n *tree.DropPublication) (planNode, error) {
+	if err := checkPublicationsSupported(ctx, p.ExecCfg()); err != nil {
+		return nil, err
+	}
+	return &dropPublicationNode{n: n}, nil
+}
+
+func (n *dropPublicationNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("publication"))
+	ctx, p := params.ctx, params.p
+
+	_, dbDesc, err := p.Descriptors().GetImmutableDatabaseByName(
+		ctx, p.txn, p.CurrentDatabase(), tree.DatabaseLookupFlags{Required: true})
+	if err != nil {
+		return err
+	}
+
+	ie := p.ExecCfg().InternalExecutor
+	row, err := ie.QueryRowEx(ctx, "check-publication", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT owner FROM system.publications WHERE database_id = $1 AND name = $2`,
+		dbDesc.GetID(), string(n.n.Name),
+	)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		if n.n.IfExists {
+			return nil
+		}
+		return pgerror.Newf(pgcode.UndefinedObject,
+			"publication %q does not exist", string(n.n.Name))
+	}
+	if owner := string(tree.MustBeDString(row[0])); owner != p.User().Normalized() {
+		isAdmin, err := p.HasAdminRole(ctx)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"must be owner of publication %s", string(n.n.Name))
+		}
+	}
+
+	_, err = ie.ExecEx(ctx, "drop-publication", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`DELETE FROM system.publications WHERE database_id = $1 AND name = $2`,
+		dbDesc.GetID(), string(n.n.Name),
+	)
+	return err
+}
+
+// publicationInfo describes a publication.
+type publicationInfo struct {
+	dbID      descpb.ID
+	name      string
+	owner     security.SQLUsername
+	allTables bool
+	tableIDs  []descpb.ID
+}
+
+// includes returns whether the publication includes a table.
+func (pub *publicationInfo) includes(dbID descpb.ID, table catalog.TableDescriptor) bool {
+	if dbID != pub.dbID {
+		return false
+	}
+	if pub.allTables {
+		return checkPublicationTable(table) == nil
+	}
+	for _, id := range pub.tableIDs {
+		if id == table.GetID() {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachPublication calls fn on the publications of a database, or of all
+// databases if dbContext is nil.
+func forEachPublication(
+	ctx context.Context, p *planner, dbContext *dbdesc.Immutable, fn func(publicationInfo) error,
+) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.PublicationsAndReplicationSlots) {
+		return nil
+	}
+	query := `SELECT database_id, name, owner, all_tables, table_ids FROM system.publications`
+	var args []interface{}
+	if dbContext != nil {
+		query += ` WHERE database_id = $1`
+		args = append(args, dbContext.GetID())
+	}
+	rows, err := p.ExecCfg().InternalExecutor.Query(ctx, "select-publications", p.txn, query, args...)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		pub := publicationInfo{
+			dbID:      descpb.ID(tree.MustBeDInt(row[0])),
+			name:      string(tree.MustBeDString(row[1])),
+			owner:     security.MakeSQLUsernameFromPreNormalizedString(string(tree.MustBeDString(row[2]))),
+			allTables: bool(tree.MustBeDBool(row[3])),
+		}
+		if row[4] != tree.DNull {
+			for _, id := range tree.MustBeDArray(row[4]).Array {
+				pub.tableIDs = append(pub.tableIDs, descpb.ID(tree.MustBeDInt(id)))
+			}
+		}
+		if err := fn(pub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropPublicationNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropPublicationNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropPublicationNode) Close(context.Context)        {}
@@ -98,7 +98,7 @@ func (p *planner) getTimestamp(
 		// table readers at arbitrary timestamps, and each FROM clause
 		// can have its own timestamp. In that case, the timestamp
 		// would not be set globally for the entire txn.
-		if p.semaCtx.AsOfTimestamp == nil {
+		if p.semaCtx.AsOfSystemTime == nil {
 			return hlc.MaxTimestamp, false,
 				pgerror.Newf(pgcode.Syntax,
 					"AS OF SYSTEM TIME must be provided on a top-level statement")
@@ -108,16 +108,20 @@ func (p *planner) getTimestamp(
 		// level. We accept AS OF SYSTEM TIME in multiple places (e.g. in
 		// subqueries or view queries) but they must all point to the same
 		// timestamp.
-		ts, err := p.EvalAsOfTimestamp(ctx, asOf)
+		var opts []tree.EvalAsOfTimestampOption
+		if p.semaCtx.AsOfSystemTime.BoundedStaleness {
+			opts = append(opts, tree.EvalAsOfTimestampOptionAllowBoundedStaleness)
+		}
+		asOfRet, err := p.EvalAsOfTimestamp(ctx, asOf, opts...)
 		if err != nil {
 			return hlc.MaxTimestamp, false, err
 		}
-		if ts != *p.semaCtx.AsOfTimestamp {
+		if asOfRet != *p.semaCtx.AsOfSystemTime {
 			return hlc.MaxTimestamp, false,
 				unimplemented.NewWithIssue(35712,
 					"cannot specify AS OF SYSTEM TIME with different timestamps")
 		}
-		return ts, true, nil
+		return asOfRet.Timestamp, true, nil
 	}
 	return hlc.MaxTimestamp, false, nil
 }
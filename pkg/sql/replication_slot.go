@@ -0,0 +1,203 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgrepl"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+// This file implements the replication commands that are executed like
+// regular statements. START_REPLICATION takes over the connection and is
+// implemented in logical_replication.go.
+
+var identifySystemColumns = colinfo.ResultColumns{
+	{Name: "systemid", Typ: types.String},
+	{Name: "timeline", Typ: types.Int4},
+	{Name: "xlogpos", Typ: types.String},
+	{Name: "dbname", Typ: types.String},
+}
+
+// IdentifySystem returns the identity of the cluster and the current position
+// in the replication stream.
+func (p *planner) IdentifySystem(ctx context.Context, n *tree.IdentifySystem) (planNode, error) {
+	return &delayedNode{
+		name:    n.StatementTag(),
+		columns: identifySystemColumns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			// Postgres identifies a database cluster with a 64-bit number.
+			systemID := strconv.FormatUint(p.ExecCfg().ClusterID().ToUint128().Lo, 10)
+			var dbName tree.Datum = tree.DNull
+			if db := p.CurrentDatabase(); db != "" {
+				dbName = tree.NewDString(db)
+			}
+			v := p.newContainerValuesNode(identifySystemColumns, 1)
+			if _, err := v.rows.AddRow(ctx, tree.Datums{
+				tree.NewDString(systemID),
+				tree.NewDInt(1),
+				tree.NewDString(pgrepl.LSNFromTimestamp(p.ExecCfg().Clock.Now()).String()),
+				dbName,
+			}); err != nil {
+				v.Close(ctx)
+				return nil, err
+			}
+			return v, nil
+		},
+	}, nil
+}
+
+// checkReplicationPrivilege checks whether the current user can use
+// replication slots. Logical replication is an alternative to changefeeds,
+// so it is governed by the same role option.
+func checkReplicationPrivilege(ctx context.Context, p *planner) error {
+	ok, err := p.HasRoleOption(ctx, roleoption.CONTROLCHANGEFEED)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"must have the CONTROLCHANGEFEED role option to use replication slots")
+	}
+	return nil
+}
+
+var createReplicationSlotColumns = colinfo.ResultColumns{
+	{Name: "slot_name", Typ: types.String},
+	{Name: "consistent_point", Typ: types.String},
+	{Name: "snapshot_name", Typ: types.String},
+	{Name: "output_plugin", Typ: types.String},
+}
+
+// CreateReplicationSlot creates a logical replication slot in the current
+// database. The slot starts at the current position in the replication
+// stream; changes committed before are not streamed.
+// Privileges: CONTROLCHANGEFEED role option.
+func (p *planner) CreateReplicationSlot(
+	ctx context.Context, n *tree.CreateReplicationSlot,
+) (planNode, error) {
+	if err := checkPublicationsSupported(ctx, p.ExecCfg()); err != nil {
+		return nil, err
+	}
+	if n.Plugin != pgrepl.PluginName {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"unsupported output plugin %q, only %q is supported", n.Plugin, pgrepl.PluginName)
+	}
+	if n.Temporary {
+		return nil, unimplemented.New("replication.temporary",
+			"temporary replication slots are not supported")
+	}
+	if n.Snapshot == "USE_SNAPSHOT" || n.Snapshot == "EXPORT_SNAPSHOT" {
+		// The initial contents of the tables can be read with AS OF SYSTEM TIME
+		// at the consistent point instead.
+		return nil, unimplemented.Newf("replication.snapshot",
+			"%s is not supported", n.Snapshot)
+	}
+	return &delayedNode{
+		name:    n.StatementTag(),
+		columns: createReplicationSlotColumns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			if err := checkReplicationPrivilege(ctx, p); err != nil {
+				return nil, err
+			}
+			_, dbDesc, err := p.Descriptors().GetImmutableDatabaseByName(
+				ctx, p.txn, p.CurrentDatabase(), tree.DatabaseLookupFlags{Required: true})
+			if err != nil {
+				return nil, err
+			}
+
+			ie := p.ExecCfg().InternalExecutor
+			row, err := ie.QueryRowEx(ctx, "check-replication-slot", p.txn,
+				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+				`SELECT 1 FROM system.replication_slots WHERE name = $1`, string(n.Name),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if row != nil {
+				return nil, pgerror.Newf(pgcode.DuplicateObject,
+					"replication slot %q already exists", string(n.Name))
+			}
+
+			// Changes are streamed from after the transaction's timestamp, so any
+			// change committed after the slot is created is streamed.
+			lsn := pgrepl.LSNFromTimestamp(p.txn.ReadTimestamp())
+			if _, err := ie.ExecEx(ctx, "create-replication-slot", p.txn,
+				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+				`INSERT INTO system.replication_slots (name, database_id, plugin, owner, confirmed_flush_lsn)
+				 VALUES ($1, $2, $3, $4, $5)`,
+				string(n.Name), dbDesc.GetID(), n.Plugin, p.User().Normalized(), int64(lsn),
+			); err != nil {
+				return nil, err
+			}
+
+			v := p.newContainerValuesNode(createReplicationSlotColumns, 1)
+			if _, err := v.rows.AddRow(ctx, tree.Datums{
+				tree.NewDString(string(n.Name)),
+				tree.NewDString(lsn.String()),
+				tree.DNull,
+				tree.NewDString(n.Plugin),
+			}); err != nil {
+				v.Close(ctx)
+				return nil, err
+			}
+			return v, nil
+		},
+	}, nil
+}
+
+type dropReplicationSlotNode struct {
+	n *tree.DropReplicationSlot
+}
+
+// DropReplicationSlot removes a replication slot.
+// Privileges: CONTROLCHANGEFEED role option.
+func (p *planner) DropReplicationSlot(
+	ctx context.Context, n *tree.DropReplicationSlot,
+) (planNode, error) {
+	if err := checkPublicationsSupported(ctx, p.ExecCfg()); err != nil {
+		return nil, err
+	}
+	return &dropReplicationSlotNode{n: n}, nil
+}
+
+func (n *dropReplicationSlotNode) startExec(params runParams) error {
+	if err := checkReplicationPrivilege(params.ctx, params.p); err != nil {
+		return err
+	}
+	deleted, err := params.ExecCfg().InternalExecutor.ExecEx(
+		params.ctx, "drop-replication-slot", params.p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`DELETE FROM system.replication_slots WHERE name = $1`, string(n.n.Name),
+	)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgerror.Newf(pgcode.UndefinedObject,
+			"replication slot %q does not exist", string(n.n.Name))
+	}
+	return nil
+}
+
+func (*dropReplicationSlotNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropReplicationSlotNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropReplicationSlotNode) Close(context.Context)        {}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
@@ -666,6 +667,7 @@ func (rf *Fetcher) StartInconsistentScan(
 		rf.firstBatchLimit(limitHint),
 		rf.lockStrength,
 		rf.lockWaitPolicy,
+		lock.Unreplicated,
 		rf.mon,
 		forceProductionKVBatchSize,
 	)
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/errors"
@@ -79,6 +80,9 @@ type txnKVFetcher struct {
 	// lockWaitPolicy represents the policy to be used for handling conflicting
 	// locks held by other active transactions.
 	lockWaitPolicy descpb.ScanLockingWaitPolicy
+	// lockDurability represents the durability of the locks acquired when
+	// fetching KVs with a locking strength.
+	lockDurability lock.Durability
 
 	fetchEnd bool
 	batchIdx int
@@ -159,16 +163,24 @@ func (f *txnKVFetcher) getKeyLockingStrength() lock.Strength {
 		// Promote to FOR_SHARE.
 		fallthrough
 	case descpb.ScanLockingStrength_FOR_SHARE:
-		// We currently perform no per-key locking when FOR_SHARE is used
-		// because Shared locks have not yet been implemented.
+		// Shared locks are only implemented with the Replicated durability, so
+		// we perform no per-key locking when FOR_SHARE is used with the
+		// Unreplicated durability.
+		if f.lockDurability == lock.Replicated {
+			return lock.Shared
+		}
 		return lock.None
 
 	case descpb.ScanLockingStrength_FOR_NO_KEY_UPDATE:
 		// Promote to FOR_UPDATE.
 		fallthrough
 	case descpb.ScanLockingStrength_FOR_UPDATE:
-		// We currently perform exclusive per-key locking when FOR_UPDATE is
-		// used because Upgrade locks have not yet been implemented.
+		// Upgrade locks are only implemented with the Replicated durability, so
+		// we perform exclusive per-key locking when FOR_UPDATE is used with the
+		// Unreplicated durability.
+		if f.lockDurability == lock.Replicated {
+			return lock.Upgrade
+		}
 		return lock.Exclusive
 
 	default:
@@ -218,16 +230,32 @@ func makeKVBatchFetcher(
 		if err != nil {
 			return nil, err.GoError()
 		}
+		// Queue the processing of the response while the node is overloaded.
+		// The queue is only set up on KV nodes, where SQL runs as the system
+		// tenant.
+		if q := txn.DB().SQLKVResponseAdmissionQ; q != nil {
+			if _, err := q.Admit(ctx, admission.WorkInfo{
+				TenantID:   roachpb.SystemTenantID,
+				Priority:   admission.NormalPri,
+				CreateTime: txn.ReadTimestamp().WallTime,
+			}); err != nil {
+				return nil, err
+			}
+		}
 		return res, nil
 	}
+	lockDurability := lock.Unreplicated
+	if lockStrength != descpb.ScanLockingStrength_FOR_NONE {
+		lockDurability = txn.LockingDurability()
+	}
 	return makeKVBatchFetcherWithSendFunc(
 		sendFn, spans, reverse, useBatchLimit, firstBatchLimit, lockStrength,
-		lockWaitPolicy, mon, forceProductionKVBatchSize,
+		lockWaitPolicy, lockDurability, mon, forceProductionKVBatchSize,
 	)
 }
 
 // makeKVBatchFetcherWithSendFunc is like makeKVBatchFetcher but uses a custom
-// send function.
+// send function, and acquires locks with the given durability.
 func makeKVBatchFetcherWithSendFunc(
 	sendFn sendFunc,
 	spans roachpb.Spans,
@@ -236,6 +264,7 @@ func makeKVBatchFetcherWithSendFunc(
 	firstBatchLimit int64,
 	lockStrength descpb.ScanLockingStrength,
 	lockWaitPolicy descpb.ScanLockingWaitPolicy,
+	lockDurability lock.Durability,
 	mon *mon.BytesMonitor,
 	forceProductionKVBatchSize bool,
 ) (txnKVFetcher, error) {
@@ -290,6 +319,7 @@ func makeKVBatchFetcherWithSendFunc(
 		firstBatchLimit:            firstBatchLimit,
 		lockStrength:               lockStrength,
 		lockWaitPolicy:             lockWaitPolicy,
+		lockDurability:             lockDurability,
 		mon:                        mon,
 		acc:                        mon.MakeBoundAccount(),
 		forceProductionKVBatchSize: forceProductionKVBatchSize,
@@ -317,6 +347,7 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	}
 	ba.Requests = make([]roachpb.RequestUnion, len(f.spans))
 	keyLocking := f.getKeyLockingStrength()
+	replicatedLocking := keyLocking != lock.None && f.lockDurability == lock.Replicated
 	if f.reverse {
 		scans := make([]struct {
 			req   roachpb.ReverseScanRequest
@@ -326,6 +357,7 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 			scans[i].req.SetSpan(f.spans[i])
 			scans[i].req.ScanFormat = roachpb.BATCH_RESPONSE
 			scans[i].req.KeyLocking = keyLocking
+			scans[i].req.KeyLockingReplicated = replicatedLocking
 			scans[i].union.ReverseScan = &scans[i].req
 			ba.Requests[i].Value = &scans[i].union
 		}
@@ -338,6 +370,7 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 			scans[i].req.SetSpan(f.spans[i])
 			scans[i].req.ScanFormat = roachpb.BATCH_RESPONSE
 			scans[i].req.KeyLocking = keyLocking
+			scans[i].req.KeyLockingReplicated = replicatedLocking
 			scans[i].union.Scan = &scans[i].req
 			ba.Requests[i].Value = &scans[i].union
 		}
@@ -448,9 +448,9 @@ func NewDatumRowConverter(
 
 const rowIDBits = 64 - builtins.NodeIDBits
 
-// Row inserts kv operations into the current kv batch, and triggers a SendBatch
-// if necessary.
-func (c *DatumRowConverter) Row(ctx context.Context, sourceID int32, rowIndex int64) error {
+// generateInsertRow evaluates the default and computed expressions of the
+// current row and returns the datums to insert, ordered like c.cols.
+func (c *DatumRowConverter) generateInsertRow(sourceID int32, rowIndex int64) (tree.Datums, error) {
 	getCellInfoAnnotation(c.EvalCtx.Annotations).reset(sourceID, rowIndex)
 	for i := range c.cols {
 		col := &c.cols[i]
@@ -464,7 +464,7 @@ func (c *DatumRowConverter) Row(ctx context.Context, sourceID int32, rowIndex in
 			datum, err := c.defaultCache[i].Eval(c.EvalCtx)
 			if !c.TargetColOrds.Contains(i) {
 				if err != nil {
-					return errors.Wrapf(
+					return nil, errors.Wrapf(
 						err, "error evaluating default expression %q", *col.DefaultExpr)
 				}
 				c.Datums[i] = datum
@@ -481,7 +481,33 @@ func (c *DatumRowConverter) Row(ctx context.Context, sourceID int32, rowIndex in
 		c.defaultCache, c.computedExprs, c.cols, computedColsLookup, c.EvalCtx,
 		c.tableDesc, c.Datums, &c.computedIVarContainer)
 	if err != nil {
-		return errors.Wrap(err, "generate insert row")
+		return nil, errors.Wrap(err, "generate insert row")
+	}
+	return insertRow, nil
+}
+
+// GenerateRow evaluates the default and computed expressions of the current
+// row and stores the resulting datums in dst, which must have one entry per
+// public column of the table, in the same order. Unlike Row, no KVs are
+// produced; this is used to read rows from external files without importing
+// them.
+func (c *DatumRowConverter) GenerateRow(sourceID int32, rowIndex int64, dst tree.Datums) error {
+	insertRow, err := c.generateInsertRow(sourceID, rowIndex)
+	if err != nil {
+		return err
+	}
+	for i, col := range c.tableDesc.GetPublicColumns() {
+		dst[i] = insertRow[c.ri.InsertColIDtoRowIndex.GetDefault(col.ID)]
+	}
+	return nil
+}
+
+// Row inserts kv operations into the current kv batch, and triggers a SendBatch
+// if necessary.
+func (c *DatumRowConverter) Row(ctx context.Context, sourceID int32, rowIndex int64) error {
+	insertRow, err := c.generateInsertRow(sourceID, rowIndex)
+	if err != nil {
+		return err
 	}
 	// TODO(mgartner): Add partial index IDs to ignoreIndexes that we should
 	// not delete entries from.
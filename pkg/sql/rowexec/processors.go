@@ -365,6 +365,15 @@ func NewProcessor(
 		}
 		return NewStreamIngestionFrontierProcessor(flowCtx, processorID, *core.StreamIngestionFrontier, inputs[0], post, outputs[0])
 	}
+	if core.ForeignTableScan != nil {
+		if err := checkNumInOut(inputs, outputs, 0, 1); err != nil {
+			return nil, err
+		}
+		if NewForeignTableScanProcessor == nil {
+			return nil, errors.New("ForeignTableScan processor unimplemented")
+		}
+		return NewForeignTableScanProcessor(flowCtx, processorID, *core.ForeignTableScan, post, outputs[0])
+	}
 	return nil, errors.Errorf("unsupported processor core %q", core)
 }
 
@@ -394,3 +403,6 @@ var NewChangeFrontierProcessor func(*execinfra.FlowCtx, int32, execinfrapb.Chang
 
 // NewStreamIngestionFrontierProcessor is implemented in the non-free (CCL) codebase and then injected here via runtime initialization.
 var NewStreamIngestionFrontierProcessor func(*execinfra.FlowCtx, int32, execinfrapb.StreamIngestionFrontierSpec, execinfra.RowSource, *execinfrapb.PostProcessSpec, execinfra.RowReceiver) (execinfra.Processor, error)
+
+// NewForeignTableScanProcessor is implemented in the non-free (CCL) codebase and then injected here via runtime initialization.
+var NewForeignTableScanProcessor func(*execinfra.FlowCtx, int32, execinfrapb.ForeignTableScanSpec, *execinfrapb.PostProcessSpec, execinfra.RowReceiver) (execinfra.Processor, error)
@@ -13,12 +13,14 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scbuild"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scplan"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 // SchemaChange provides the planNode for the new schema changer.
@@ -29,7 +31,7 @@ func (p *planner) SchemaChange(ctx context.Context, stmt tree.Statement) (planNo
 		(mode == sessiondata.UseNewSchemaChangerOn && !p.extendedEvalCtx.TxnImplicit) {
 		return nil, false, nil
 	}
-	b := scbuild.NewBuilder(p, p.SemaCtx(), p.EvalContext())
+	b := scbuild.NewBuilder(p, p.SemaCtx(), p.EvalContext(), p)
 	updated, err := b.Build(ctx, p.extendedEvalCtx.SchemaChangerState.nodes, stmt)
 	if scbuild.HasNotImplemented(err) && mode == sessiondata.UseNewSchemaChangerOn {
 		return nil, false, nil
@@ -54,19 +56,69 @@ type schemaChangePlanNode struct {
 }
 
 func (s *schemaChangePlanNode) startExec(params runParams) error {
+	scs := params.p.extendedEvalCtx.SchemaChangerState
+	droppedTables, err := s.droppedTables(params, scs.nodes)
+	if err != nil {
+		return err
+	}
 	executor := scexec.NewExecutor(params.p.txn, params.p.Descriptors(), params.p.EvalContext().Codec,
-		nil /* backfiller */, nil /* jobTracker */)
+		nil /* backfiller */, nil /* jobTracker */, nil /* validator */, nil /* jobCreator */)
 	after, err := runNewSchemaChanger(
 		params.ctx, scplan.StatementPhase, s.plannedState, executor,
 	)
 	if err != nil {
 		return err
 	}
-	scs := params.p.extendedEvalCtx.SchemaChangerState
 	scs.nodes = after
+
+	// Like the legacy schema changer, remove the comments of the dropped tables
+	// and log an event for each of them in the same transaction.
+	for _, table := range droppedTables {
+		if err := params.p.removeTableComments(params.ctx, table.desc); err != nil {
+			return err
+		}
+		if err := params.p.logEvent(params.ctx,
+			table.desc.GetID(),
+			&eventpb.DropTable{
+				TableName: table.name.FQString(),
+			}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+type droppedTable struct {
+	desc catalog.TableDescriptor
+	name *tree.TableName
+}
+
+// droppedTables returns the tables which the current statement drops, that is
+// the tables targeted by the drop targets which were not in the nodes which
+// preceded it.
+func (s *schemaChangePlanNode) droppedTables(
+	params runParams, before []*scpb.Node,
+) ([]droppedTable, error) {
+	var dropped []droppedTable
+	for _, n := range s.plannedState[len(before):] {
+		if _, ok := n.Element().(*scpb.Table); !ok || n.Target.Direction != scpb.Target_DROP {
+			continue
+		}
+		desc, err := params.p.Descriptors().GetImmutableTableByID(
+			params.ctx, params.p.txn, n.Element().DescriptorID(), tree.ObjectLookupFlagsWithRequired(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		name, err := params.p.getQualifiedTableName(params.ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+		dropped = append(dropped, droppedTable{desc: desc, name: name})
+	}
+	return dropped, nil
+}
+
 func (s schemaChangePlanNode) Next(params runParams) (bool, error) { return false, nil }
 func (s schemaChangePlanNode) Values() tree.Datums                 { return tree.Datums{} }
 func (s schemaChangePlanNode) Close(ctx context.Context)           {}
@@ -0,0 +1,94 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// SchemaChangeValidator validates unique indexes and constraints on
+// behalf of the declarative schema changer.
+type SchemaChangeValidator struct {
+	execCfg *ExecutorConfig
+}
+
+// NewSchemaChangeValidator creates a new SchemaChangeValidator.
+func NewSchemaChangeValidator(execCfg *ExecutorConfig) *SchemaChangeValidator {
+	return &SchemaChangeValidator{execCfg: execCfg}
+}
+
+var _ scexec.Validator = (*SchemaChangeValidator)(nil)
+
+// ValidateUniqueIndex is part of the scexec.Validator interface.
+func (v *SchemaChangeValidator) ValidateUniqueIndex(
+	ctx context.Context, txn *kv.Txn, table catalog.TableDescriptor, indexID descpb.IndexID,
+) error {
+	idx, err := table.FindIndexWithID(indexID)
+	if err != nil {
+		return err
+	}
+	if idx.IsPartial() {
+		return errors.AssertionFailedf("cannot validate partial unique index %q", idx.GetName())
+	}
+	uc := &descpb.UniqueWithoutIndexConstraint{
+		TableID:   table.GetID(),
+		Name:      idx.GetName(),
+		ColumnIDs: idx.IndexDesc().ColumnIDs,
+	}
+	return validateUniqueConstraint(ctx, mutableCopy(table), uc, v.execCfg.InternalExecutor, txn)
+}
+
+// ValidateCheckConstraint is part of the scexec.Validator interface.
+func (v *SchemaChangeValidator) ValidateCheckConstraint(
+	ctx context.Context, txn *kv.Txn, table catalog.TableDescriptor, name string,
+) error {
+	for _, ck := range table.GetChecks() {
+		if ck.Name != name {
+			continue
+		}
+		semaCtx := tree.MakeSemaContext()
+		return validateCheckExpr(
+			ctx, &semaCtx, ck.Expr, mutableCopy(table), v.execCfg.InternalExecutor, txn,
+		)
+	}
+	return errors.AssertionFailedf("failed to find check constraint %q in table %d", name, table.GetID())
+}
+
+// ValidateForeignKey is part of the scexec.Validator interface.
+func (v *SchemaChangeValidator) ValidateForeignKey(
+	ctx context.Context, txn *kv.Txn, table catalog.TableDescriptor, name string,
+) error {
+	for i := range table.GetOutboundFKs() {
+		fk := &table.GetOutboundFKs()[i]
+		if fk.Name != name {
+			continue
+		}
+		return validateForeignKey(
+			ctx, mutableCopy(table), fk, v.execCfg.InternalExecutor, txn, v.execCfg.Codec,
+		)
+	}
+	return errors.AssertionFailedf("failed to find foreign key %q in table %d", name, table.GetID())
+}
+
+func mutableCopy(table catalog.TableDescriptor) *tabledesc.Mutable {
+	return tabledesc.NewExistingMutable(
+		*protoutil.Clone(table.TableDesc()).(*descpb.TableDescriptor),
+	)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
@@ -35,7 +36,6 @@ import (
 )
 
 // TODO(ajwerner): Eliminate all panics or add principled recovery.
-// TODO(ajwerner): Add privilege checking.
 
 // The Builder is the entry point for planning schema changes. From AST nodes
 // for DDL statements, it constructs targets which represent schema changes to
@@ -51,6 +51,7 @@ type Builder struct {
 	res     resolver.SchemaResolver
 	semaCtx *tree.SemaContext
 	evalCtx *tree.EvalContext
+	auth    AuthorizationAccessor
 
 	// nodes contains the internal state when building targets for an individual
 	// statement.
@@ -81,14 +82,25 @@ func (e *notImplementedError) Error() string {
 	return buf.String()
 }
 
+// AuthorizationAccessor is used by the Builder to check the privileges of the
+// current user on the descriptors targeted by a schema change.
+type AuthorizationAccessor interface {
+	// CheckPrivilege verifies that the user has `privilege` on `descriptor`.
+	CheckPrivilege(ctx context.Context, descriptor catalog.Descriptor, privilege privilege.Kind) error
+}
+
 // NewBuilder creates a new Builder.
 func NewBuilder(
-	res resolver.SchemaResolver, semaCtx *tree.SemaContext, evalCtx *tree.EvalContext,
+	res resolver.SchemaResolver,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	auth AuthorizationAccessor,
 ) *Builder {
 	return &Builder{
 		res:     res,
 		semaCtx: semaCtx,
 		evalCtx: evalCtx,
+		auth:    auth,
 	}
 }
 
@@ -105,6 +117,12 @@ func (b *Builder) Build(
 	switch n := n.(type) {
 	case *tree.AlterTable:
 		return b.AlterTable(ctx, nodes, n)
+	case *tree.CreateIndex:
+		return b.CreateIndex(ctx, nodes, n)
+	case *tree.DropIndex:
+		return b.DropIndex(ctx, nodes, n)
+	case *tree.DropTable:
+		return b.DropTable(ctx, nodes, n)
 	default:
 		return nil, &notImplementedError{n: n}
 	}
@@ -138,17 +156,16 @@ func (b *Builder) AlterTable(
 		}
 		return nil, err
 	}
+	if err := b.auth.CheckPrivilege(ctx, table, privilege.CREATE); err != nil {
+		return nil, err
+	}
 	for _, cmd := range n.Cmds {
 		if err := b.alterTableCmd(ctx, table, cmd, &tn); err != nil {
 			return nil, err
 		}
 	}
 
-	result := make([]*scpb.Node, len(b.nodes))
-	for i := range b.nodes {
-		result[i] = b.nodes[i]
-	}
-	return result, nil
+	return b.builtNodes(), nil
 }
 
 func (b *Builder) alterTableCmd(
@@ -157,6 +174,10 @@ func (b *Builder) alterTableCmd(
 	switch t := cmd.(type) {
 	case *tree.AlterTableAddColumn:
 		return b.alterTableAddColumn(ctx, table, t, tn)
+	case *tree.AlterTableAddConstraint:
+		return b.alterTableAddConstraint(ctx, table, t, tn)
+	case *tree.AlterTableAlterPrimaryKey:
+		return b.alterTableAlterPrimaryKey(ctx, table, t)
 	default:
 		return &notImplementedError{n: cmd}
 	}
@@ -409,6 +430,514 @@ func (b *Builder) alterTableDropColumn(
 // yet.
 var _ = (*Builder)(nil).alterTableDropColumn
 
+func (b *Builder) alterTableAddConstraint(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	t *tree.AlterTableAddConstraint,
+	tn *tree.TableName,
+) error {
+	switch d := t.ConstraintDef.(type) {
+	case *tree.CheckConstraintTableDef:
+		return b.alterTableAddCheckConstraint(ctx, table, t, d, tn)
+	case *tree.UniqueConstraintTableDef:
+		return b.alterTableAddUniqueConstraint(ctx, table, t, d, tn)
+	case *tree.ForeignKeyConstraintTableDef:
+		return b.alterTableAddForeignKey(ctx, table, t, d, tn)
+	default:
+		return &notImplementedError{n: t}
+	}
+}
+
+func (b *Builder) alterTableAddUniqueConstraint(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	t *tree.AlterTableAddConstraint,
+	d *tree.UniqueConstraintTableDef,
+	tn *tree.TableName,
+) error {
+	switch {
+	case d.WithoutIndex:
+		return &notImplementedError{n: t, detail: "unique without index"}
+	case d.PrimaryKey:
+		// Adding a primary key is only supported when the table uses the default
+		// rowid primary index, in which case it is an ALTER PRIMARY KEY.
+		if !table.IsPrimaryIndexDefaultRowID() {
+			return pgerror.Newf(pgcode.InvalidTableDefinition,
+				"multiple primary keys for table %q are not allowed", table.GetName())
+		}
+		return b.alterTableAlterPrimaryKey(ctx, table, &tree.AlterTableAlterPrimaryKey{
+			Columns:    d.Columns,
+			Sharded:    d.Sharded,
+			Interleave: d.Interleave,
+			Name:       d.Name,
+		})
+	}
+	return b.createIndexOnTable(ctx, table, t, &tree.CreateIndex{
+		Name:             d.Name,
+		Table:            *tn,
+		Unique:           true,
+		Columns:          d.Columns,
+		Sharded:          d.Sharded,
+		Storing:          d.Storing,
+		Interleave:       d.Interleave,
+		PartitionByIndex: d.PartitionByIndex,
+		StorageParams:    d.StorageParams,
+		Predicate:        d.Predicate,
+	})
+}
+
+func (b *Builder) alterTableAddForeignKey(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	t *tree.AlterTableAddConstraint,
+	d *tree.ForeignKeyConstraintTableDef,
+	tn *tree.TableName,
+) error {
+	if !b.evalCtx.Settings.Version.IsActive(ctx, clusterversion.NoOriginFKIndexes) {
+		return &notImplementedError{n: t, detail: "foreign key requiring an origin index"}
+	}
+	if b.hasPendingNodes(table.GetID(), func(n *scpb.Node) bool {
+		_, ok := n.Element().(*scpb.Column)
+		return !ok
+	}) {
+		return &notImplementedError{n: t, detail: "table has pending column changes"}
+	}
+	var originColSet catalog.TableColSet
+	originCols := make([]*descpb.ColumnDescriptor, len(d.FromCols))
+	for i, colName := range d.FromCols {
+		col, err := table.FindActiveColumnByName(string(colName))
+		if err != nil {
+			return err
+		}
+		if err := col.CheckCanBeOutboundFKRef(); err != nil {
+			return err
+		}
+		// Ensure that the origin columns don't have duplicates.
+		if originColSet.Contains(col.ID) {
+			return pgerror.Newf(pgcode.InvalidForeignKey,
+				"foreign key contains duplicate column %q", col.Name)
+		}
+		originColSet.Add(col.ID)
+		originCols[i] = col
+	}
+
+	referencedName := d.Table
+	referenced, err := resolver.ResolveExistingTableObject(ctx, b.res, &referencedName,
+		tree.ObjectLookupFlagsWithRequired())
+	if err != nil {
+		return err
+	}
+	if referenced.GetParentID() != table.GetParentID() {
+		// Cross-database references are subject to a cluster setting which the
+		// legacy schema changer checks.
+		return &notImplementedError{n: t, detail: "cross-database foreign key"}
+	}
+	if table.IsTemporary() != referenced.IsTemporary() {
+		persistenceType := "permanent"
+		if table.IsTemporary() {
+			persistenceType = "temporary"
+		}
+		return pgerror.Newf(
+			pgcode.InvalidTableDefinition,
+			"constraints on %s tables may reference only %s tables",
+			persistenceType,
+			persistenceType,
+		)
+	}
+	if referenced.GetID() != table.GetID() && b.hasPendingNodes(referenced.GetID(), func(
+		n *scpb.Node,
+	) bool {
+		return false
+	}) {
+		return &notImplementedError{n: t, detail: "referenced table has pending schema changes"}
+	}
+
+	referencedColNames := d.ToCols
+	// If no columns are specified, attempt to default to PK, ignoring implicit
+	// columns.
+	if len(referencedColNames) == 0 {
+		referencedPK := referenced.GetPrimaryIndex()
+		numImplicitCols := int(referencedPK.GetPartitioning().NumImplicitColumns)
+		for i := numImplicitCols; i < referencedPK.NumColumns(); i++ {
+			referencedColNames = append(referencedColNames, tree.Name(referencedPK.GetColumnName(i)))
+		}
+	}
+	referencedCols, err := referenced.FindActiveColumnsByNames(referencedColNames)
+	if err != nil {
+		return err
+	}
+	for i := range referencedCols {
+		if err := referencedCols[i].CheckCanBeInboundFKRef(); err != nil {
+			return err
+		}
+	}
+	if len(referencedCols) != len(originCols) {
+		return pgerror.Newf(pgcode.Syntax,
+			"%d columns must reference exactly %d columns in referenced table (found %d)",
+			len(originCols), len(originCols), len(referencedCols))
+	}
+	for i := range originCols {
+		if s, t := originCols[i], referencedCols[i]; !s.Type.Equivalent(t.Type) {
+			return pgerror.Newf(pgcode.DatatypeMismatch,
+				"type of %q (%s) does not match foreign key %q.%q (%s)",
+				s.Name, s.Type.String(), referenced.GetName(), t.Name, t.Type.String())
+		}
+	}
+
+	info, err := table.GetConstraintInfo(ctx, nil /* dg */)
+	if err != nil {
+		return err
+	}
+	nameInUse := func(name string) bool {
+		if _, ok := info[name]; ok {
+			return true
+		}
+		for _, n := range b.nodes {
+			switch e := n.Element().(type) {
+			case *scpb.CheckConstraint:
+				if e.TableID == table.GetID() && e.Name == name {
+					return true
+				}
+			case *scpb.ForeignKey:
+				if e.TableID == table.GetID() && e.FK.Name == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	name := string(d.Name)
+	if name == "" {
+		name = tabledesc.GenerateUniqueConstraintName(
+			fmt.Sprintf("fk_%s_ref_%s", string(d.FromCols[0]), referenced.GetName()), nameInUse,
+		)
+	} else if nameInUse(name) {
+		return pgerror.Newf(pgcode.DuplicateObject, "duplicate constraint name: %q", name)
+	}
+
+	// Don't add a SET NULL action on an index that has any column that is NOT
+	// NULL, nor a SET DEFAULT action on an index that has any column that has
+	// a DEFAULT expression of NULL and a NOT NULL constraint.
+	for _, col := range originCols {
+		qualifiedName := func() string {
+			return tree.ErrString(tree.NewUnresolvedName(tn.Catalog(), tn.Schema(), tn.Table(), col.Name))
+		}
+		if (d.Actions.Delete == tree.SetNull || d.Actions.Update == tree.SetNull) && !col.Nullable {
+			return pgerror.Newf(pgcode.InvalidForeignKey,
+				"cannot add a SET NULL cascading action on column %q which has a NOT NULL constraint",
+				qualifiedName(),
+			)
+		}
+		if (d.Actions.Delete == tree.SetDefault || d.Actions.Update == tree.SetDefault) &&
+			col.DefaultExpr == nil && !col.Nullable {
+			return pgerror.Newf(pgcode.InvalidForeignKey,
+				"cannot add a SET DEFAULT cascading action on column %q which has a "+
+					"NOT NULL constraint and a NULL default expression", qualifiedName(),
+			)
+		}
+	}
+
+	originColumnIDs := make(descpb.ColumnIDs, len(originCols))
+	for i, col := range originCols {
+		originColumnIDs[i] = col.ID
+	}
+	referencedColumnIDs := make(descpb.ColumnIDs, len(referencedCols))
+	for i := range referencedCols {
+		referencedColumnIDs[i] = referencedCols[i].ID
+	}
+	// Ensure that there is a unique constraint on the referenced side to use.
+	if _, err := tabledesc.FindFKReferencedUniqueConstraint(referenced, referencedColumnIDs); err != nil {
+		return err
+	}
+
+	b.addNode(scpb.Target_ADD, &scpb.ForeignKey{
+		TableID: table.GetID(),
+		FK: descpb.ForeignKeyConstraint{
+			OriginTableID:       table.GetID(),
+			OriginColumnIDs:     originColumnIDs,
+			ReferencedColumnIDs: referencedColumnIDs,
+			ReferencedTableID:   referenced.GetID(),
+			Name:                name,
+			OnDelete:            descpb.ForeignKeyReferenceActionValue[d.Actions.Delete],
+			OnUpdate:            descpb.ForeignKeyReferenceActionValue[d.Actions.Update],
+			Match:               descpb.CompositeKeyMatchMethodValue[d.Match],
+		},
+		Validated: t.ValidationBehavior == tree.ValidationDefault,
+	})
+	return nil
+}
+
+func (b *Builder) alterTableAddCheckConstraint(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	t *tree.AlterTableAddConstraint,
+	d *tree.CheckConstraintTableDef,
+	tn *tree.TableName,
+) error {
+	// The check expression is resolved against the table descriptor, which does
+	// not contain the columns added by pending targets, so checks on a table
+	// with pending column changes are left to the legacy schema changer.
+	if b.hasPendingNodes(table.GetID(), func(n *scpb.Node) bool {
+		_, ok := n.Element().(*scpb.Column)
+		return !ok
+	}) {
+		return &notImplementedError{n: t, detail: "table has pending column changes"}
+	}
+	info, err := table.GetConstraintInfo(ctx, nil /* dg */)
+	if err != nil {
+		return err
+	}
+	ckBuilder := schemaexpr.MakeCheckConstraintBuilder(ctx, *tn, table, b.semaCtx)
+	for k := range info {
+		ckBuilder.MarkNameInUse(k)
+	}
+	for _, n := range b.nodes {
+		if ck, ok := n.Element().(*scpb.CheckConstraint); ok && ck.TableID == table.GetID() {
+			ckBuilder.MarkNameInUse(ck.Name)
+		}
+	}
+	ck, err := ckBuilder.Build(d)
+	if err != nil {
+		return err
+	}
+	b.addNode(scpb.Target_ADD, &scpb.CheckConstraint{
+		TableID:   table.GetID(),
+		Name:      ck.Name,
+		Expr:      ck.Expr,
+		ColumnIDs: ck.ColumnIDs,
+		Validated: t.ValidationBehavior == tree.ValidationDefault,
+	})
+	return nil
+}
+
+func (b *Builder) alterTableAlterPrimaryKey(
+	ctx context.Context, table catalog.TableDescriptor, t *tree.AlterTableAlterPrimaryKey,
+) error {
+	if reason := alterPrimaryKeyUnsupportedReason(table, t); reason != "" {
+		return &notImplementedError{n: t, detail: reason}
+	}
+	if len(b.nodes) > 0 {
+		return &notImplementedError{n: t, detail: "other pending schema changes"}
+	}
+	if len(table.GetMutations()) > 0 {
+		return unimplemented.NewWithIssuef(
+			45510, "table %s is currently undergoing a schema change", table.GetName())
+	}
+
+	newPK := &descpb.IndexDescriptor{
+		Unique:            true,
+		CreatedExplicitly: true,
+		EncodingType:      descpb.PrimaryIndexEncoding,
+		Type:              descpb.IndexDescriptor_FORWARD,
+		Version:           descpb.EmptyArraysInInvertedIndexesVersion,
+	}
+	if err := newPK.FillColumns(t.Columns); err != nil {
+		return err
+	}
+	oldPK := table.GetPrimaryIndex()
+	sameColumns := oldPK.NumColumns() == len(t.Columns)
+	for i, colName := range newPK.ColumnNames {
+		col, err := table.FindActiveColumnByName(colName)
+		if err != nil {
+			return err
+		}
+		if col.Nullable {
+			return pgerror.Newf(pgcode.InvalidSchemaDefinition,
+				"cannot use nullable column %q in primary key", col.Name)
+		}
+		sameColumns = sameColumns && oldPK.GetColumnID(i) == col.ID &&
+			oldPK.GetColumnDirection(i) == newPK.ColumnDirections[i]
+	}
+	if sameColumns && (t.Name == "" || string(t.Name) == oldPK.GetName()) {
+		return nil
+	}
+
+	nameExists := func(name string) bool {
+		_, err := table.FindIndexWithName(name)
+		return err == nil
+	}
+	name := tabledesc.GenerateUniqueConstraintName("new_primary_key", nameExists)
+	if t.Name != "" && string(t.Name) != oldPK.GetName() {
+		if nameExists(string(t.Name)) {
+			return pgerror.Newf(pgcode.DuplicateObject,
+				"constraint with name %s already exists", t.Name)
+		}
+		name = string(t.Name)
+	}
+
+	// Allocate the IDs of the new primary index and, if needed, of a unique
+	// secondary index which preserves the uniqueness of the old primary key
+	// using a scratch copy of the table.
+	mut := tabledesc.NewExistingMutable(
+		*protoutil.Clone(table.TableDesc()).(*descpb.TableDescriptor),
+	)
+	mut.NextIndexID = b.nextIndexID(table)
+	newPK.Name = name
+	if err := mut.AddIndexMutation(newPK, descpb.DescriptorMutation_ADD); err != nil {
+		return err
+	}
+	var oldPKCopy *descpb.IndexDescriptor
+	if !mut.IsPrimaryIndexDefaultRowID() {
+		oldPKCopy = protoutil.Clone(oldPK.IndexDesc()).(*descpb.IndexDescriptor)
+		oldPKCopy.ID = 0
+		oldPKCopy.Name = ""
+		oldPKCopy.StoreColumnIDs = nil
+		oldPKCopy.StoreColumnNames = nil
+		oldPKCopy.EncodingType = descpb.SecondaryIndexEncoding
+		if err := mut.AddIndexMutation(oldPKCopy, descpb.DescriptorMutation_ADD); err != nil {
+			return err
+		}
+	}
+	// Secondary indexes which are keyed on the old primary key are rewritten in
+	// terms of the new one. Each rewritten index is added under a temporary name
+	// and takes the name of the index it replaces once it becomes public.
+	var oldIndexes, newIndexes []*descpb.IndexDescriptor
+	for _, idx := range table.PublicNonPrimaryIndexes() {
+		rewrite, err := shouldRewriteIndex(table, newPK, idx)
+		if err != nil {
+			return err
+		}
+		if !rewrite {
+			continue
+		}
+		newIndex := idx.IndexDescDeepCopy()
+		newIndex.ID = 0
+		newIndex.Name = tabledesc.GenerateUniqueConstraintName(
+			idx.GetName()+"_rewrite_for_primary_key_change", nameExists,
+		)
+		if err := mut.AddIndexMutation(&newIndex, descpb.DescriptorMutation_ADD); err != nil {
+			return err
+		}
+		oldIndexes = append(oldIndexes, idx.IndexDesc())
+		newIndexes = append(newIndexes, &newIndex)
+	}
+	if err := mut.AllocateIDs(ctx); err != nil {
+		return err
+	}
+
+	var newStoreColIDs, oldStoreColIDs []descpb.ColumnID
+	var newStoreColNames, oldStoreColNames []string
+	for _, col := range table.GetPublicColumns() {
+		if !newPK.ContainsColumnID(col.ID) {
+			newStoreColIDs = append(newStoreColIDs, col.ID)
+			newStoreColNames = append(newStoreColNames, col.Name)
+		}
+		if !oldPK.ContainsColumnID(col.ID) {
+			oldStoreColIDs = append(oldStoreColIDs, col.ID)
+			oldStoreColNames = append(oldStoreColNames, col.Name)
+		}
+	}
+	b.addNode(scpb.Target_ADD, &scpb.PrimaryIndex{
+		TableID:             table.GetID(),
+		Index:               *newPK,
+		OtherPrimaryIndexID: oldPK.GetID(),
+		StoreColumnIDs:      newStoreColIDs,
+		StoreColumnNames:    newStoreColNames,
+	})
+	b.addNode(scpb.Target_DROP, &scpb.PrimaryIndex{
+		TableID:             table.GetID(),
+		Index:               oldPK.IndexDescDeepCopy(),
+		OtherPrimaryIndexID: newPK.ID,
+		StoreColumnIDs:      oldStoreColIDs,
+		StoreColumnNames:    oldStoreColNames,
+	})
+	if oldPKCopy != nil {
+		// The copy is keyed on the columns of the new primary key rather than
+		// those of the old one.
+		setExtraColumnIDs(oldPKCopy, newPK)
+		b.addNode(scpb.Target_ADD, &scpb.SecondaryIndex{
+			TableID:      table.GetID(),
+			Index:        *oldPKCopy,
+			PrimaryIndex: newPK.ID,
+		})
+	}
+	for i, newIndex := range newIndexes {
+		setExtraColumnIDs(newIndex, newPK)
+		b.addNode(scpb.Target_ADD, &scpb.SecondaryIndex{
+			TableID:      table.GetID(),
+			Index:        *newIndex,
+			PrimaryIndex: newPK.ID,
+			FinalName:    oldIndexes[i].Name,
+		})
+		b.addNode(scpb.Target_DROP, &scpb.SecondaryIndex{
+			TableID:      table.GetID(),
+			Index:        *protoutil.Clone(oldIndexes[i]).(*descpb.IndexDescriptor),
+			PrimaryIndex: oldPK.GetID(),
+		})
+	}
+	return nil
+}
+
+// shouldRewriteIndex returns true if the secondary index needs to be rebuilt
+// when the primary key of the table changes to newPK. This mirrors the logic
+// of the legacy schema changer: only unique indexes on non-nullable columns
+// which contain all of the columns of the new primary key do not depend on
+// the primary key columns for their encoding.
+func shouldRewriteIndex(
+	table catalog.TableDescriptor, newPK *descpb.IndexDescriptor, idx catalog.Index,
+) (bool, error) {
+	for _, colID := range newPK.ColumnIDs {
+		if !idx.ContainsColumnID(colID) {
+			return true, nil
+		}
+	}
+	if !idx.IsUnique() || idx.GetType() == descpb.IndexDescriptor_INVERTED {
+		return true, nil
+	}
+	for i := 0; i < idx.NumColumns(); i++ {
+		col, err := table.FindColumnByID(idx.GetColumnID(i))
+		if err != nil {
+			return false, err
+		}
+		if col.Nullable {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// setExtraColumnIDs sets the extra columns of a secondary index to the columns
+// of the given primary index which it does not already contain.
+func setExtraColumnIDs(idx, primary *descpb.IndexDescriptor) {
+	idx.ExtraColumnIDs = nil
+	for _, colID := range primary.ColumnIDs {
+		if !idx.ContainsColumnID(colID) {
+			idx.ExtraColumnIDs = append(idx.ExtraColumnIDs, colID)
+		}
+	}
+}
+
+// alterPrimaryKeyUnsupportedReason returns a non-empty string if changing the
+// primary key of the table requires work which the new schema changer cannot
+// yet perform.
+func alterPrimaryKeyUnsupportedReason(
+	table catalog.TableDescriptor, t *tree.AlterTableAlterPrimaryKey,
+) string {
+	switch {
+	case t.Sharded != nil || table.GetPrimaryIndex().IsSharded():
+		return "hash sharded primary key"
+	case t.Interleave != nil || table.IsInterleaved():
+		return "interleaved primary key"
+	case table.IsPartitionAllBy() || table.GetLocalityConfig() != nil ||
+		table.GetPrimaryIndex().GetPartitioning().NumColumns > 0:
+		return "partitioned table"
+	}
+	if catalog.FindIndex(table, catalog.IndexOpts{}, func(idx catalog.Index) bool {
+		return idx.NumInterleavedBy() > 0
+	}) != nil {
+		return "interleaved primary key"
+	}
+	for _, idx := range table.PublicNonPrimaryIndexes() {
+		switch {
+		case idx.IsInterleaved():
+			return "interleaved secondary index"
+		case idx.GetPartitioning().NumColumns > 0:
+			return "partitioned secondary index"
+		}
+	}
+	return ""
+}
+
 func (b *Builder) maybeAddSequenceReferenceDependencies(
 	ctx context.Context, tableID descpb.ID, col *descpb.ColumnDescriptor, defaultExpr tree.TypedExpr,
 ) error {
@@ -627,6 +1156,26 @@ func (b *Builder) nextIndexID(table catalog.TableDescriptor) descpb.IndexID {
 	return nextMaxID
 }
 
+// hasPendingNodes returns true if any of the nodes built so far targets the
+// table with the given ID and is not ignored by the provided function.
+func (b *Builder) hasPendingNodes(tableID descpb.ID, ignore func(n *scpb.Node) bool) bool {
+	for _, n := range b.nodes {
+		if n.Element().DescriptorID() == tableID && !ignore(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// builtNodes returns a copy of the nodes built for the current statement.
+func (b *Builder) builtNodes() []*scpb.Node {
+	result := make([]*scpb.Node, len(b.nodes))
+	for i := range b.nodes {
+		result[i] = b.nodes[i]
+	}
+	return result
+}
+
 func (b *Builder) addNode(dir scpb.Target_Direction, elem scpb.Element) {
 	var s scpb.State
 	switch dir {
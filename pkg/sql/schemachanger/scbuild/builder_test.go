@@ -94,10 +94,7 @@ func TestBuilderAlterTable(t *testing.T) {
 				require.NoError(t, err)
 				require.Len(t, stmts, 1)
 
-				stmt := stmts[0]
-				alter, ok := stmt.AST.(*tree.AlterTable)
-				require.Truef(t, ok, "not an ALTER TABLE statement: %s", stmt.SQL)
-				_, err = b.AlterTable(ctx, nil, alter)
+				_, err = b.Build(ctx, nil, stmts[0].AST)
 				require.Truef(t, scbuild.HasNotImplemented(err), "expected unimplemented, got %v", err)
 				return ""
 
@@ -150,6 +147,7 @@ func newTestingBuilder(s serverutils.TestServerInterface) (*scbuild.Builder, fun
 		resolver.SchemaResolver
 		SemaCtx() *tree.SemaContext
 		EvalContext() *tree.EvalContext
+		scbuild.AuthorizationAccessor
 	})
-	return scbuild.NewBuilder(planner, planner.SemaCtx(), planner.EvalContext()), cleanup
+	return scbuild.NewBuilder(planner, planner.SemaCtx(), planner.EvalContext(), planner), cleanup
 }
@@ -0,0 +1,175 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scbuild
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// CreateIndex builds targets and transforms the provided schema change nodes
+// accordingly, given a CREATE INDEX statement.
+func (b *Builder) CreateIndex(
+	ctx context.Context, nodes []*scpb.Node, n *tree.CreateIndex,
+) ([]*scpb.Node, error) {
+	b.nodes = nodes
+	defer func() {
+		b.nodes = nil
+	}()
+	if err := b.createIndex(ctx, n); err != nil {
+		return nil, err
+	}
+	return b.builtNodes(), nil
+}
+
+func (b *Builder) createIndex(ctx context.Context, n *tree.CreateIndex) error {
+	tn := n.Table
+	table, err := resolver.ResolveExistingTableObject(ctx, b.res, &tn,
+		tree.ObjectLookupFlagsWithRequired())
+	if err != nil {
+		return err
+	}
+	if !table.IsTable() || table.IsForeignTable() {
+		return &notImplementedError{n: n, detail: "only supported on tables"}
+	}
+	if err := b.auth.CheckPrivilege(ctx, table, privilege.CREATE); err != nil {
+		return err
+	}
+	return b.createIndexOnTable(ctx, table, n, n)
+}
+
+// createIndexOnTable adds a target for the index described by n, which is
+// either a CREATE INDEX statement or the translation of the statement stmt
+// which adds a unique constraint.
+func (b *Builder) createIndexOnTable(
+	ctx context.Context, table catalog.TableDescriptor, stmt tree.NodeFormatter, n *tree.CreateIndex,
+) error {
+	// The flavors of indexes below depend on partitioning or zone config
+	// plumbing which the new schema changer does not have, so they are left to
+	// the legacy schema changer.
+	switch {
+	case n.Inverted:
+		return &notImplementedError{n: stmt, detail: "inverted index"}
+	case n.Sharded != nil:
+		return &notImplementedError{n: stmt, detail: "hash sharded index"}
+	case n.Interleave != nil:
+		return &notImplementedError{n: stmt, detail: "interleaved index"}
+	case n.PartitionByIndex.ContainsPartitions() || table.IsPartitionAllBy() ||
+		table.GetLocalityConfig() != nil:
+		return &notImplementedError{n: stmt, detail: "partitioned index"}
+	case len(n.StorageParams) > 0:
+		return &notImplementedError{n: stmt, detail: "storage parameters"}
+	case n.Unique && n.Predicate != nil:
+		return &notImplementedError{n: stmt, detail: "partial unique index"}
+	}
+	for _, elem := range n.Columns {
+		if elem.Expr != nil {
+			return &notImplementedError{n: stmt, detail: "expression index element"}
+		}
+	}
+	if b.hasPendingNodes(table.GetID(), func(n *scpb.Node) bool {
+		_, ok := n.Element().(*scpb.SecondaryIndex)
+		return ok && n.Target.Direction == scpb.Target_ADD
+	}) {
+		return &notImplementedError{n: stmt, detail: "table has other pending schema changes"}
+	}
+
+	// Build a scratch copy of the table which includes the indexes being added
+	// in this transaction so that name validation and ID allocation see them.
+	mut := tabledesc.NewExistingMutable(
+		*protoutil.Clone(table.TableDesc()).(*descpb.TableDescriptor),
+	)
+	for _, node := range b.nodes {
+		if idx, ok := node.Element().(*scpb.SecondaryIndex); ok && idx.TableID == table.GetID() {
+			if err := mut.AddIndexMutation(
+				protoutil.Clone(&idx.Index).(*descpb.IndexDescriptor), descpb.DescriptorMutation_ADD,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	mut.NextIndexID = b.nextIndexID(table)
+
+	if n.Name != "" {
+		if idx, err := mut.FindIndexWithName(string(n.Name)); err == nil {
+			if idx.Dropped() {
+				return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"index %q being dropped, try again later", n.Name)
+			}
+			if n.IfNotExists {
+				return nil
+			}
+		}
+		if err := mut.ValidateIndexNameIsUnique(string(n.Name)); err != nil {
+			return err
+		}
+	}
+	for _, elem := range n.Columns {
+		col, dropped, err := mut.FindColumnByName(elem.Column)
+		if err != nil {
+			return err
+		}
+		if dropped {
+			return colinfo.NewUndefinedColumnError(col.Name)
+		}
+	}
+
+	// Unique constraints do not count as explicitly created indexes.
+	_, createdExplicitly := stmt.(*tree.CreateIndex)
+	idx := descpb.IndexDescriptor{
+		Name:              string(n.Name),
+		Unique:            n.Unique,
+		StoreColumnNames:  n.Storing.ToStrings(),
+		CreatedExplicitly: createdExplicitly,
+		Version:           descpb.SecondaryIndexFamilyFormatVersion,
+	}
+	if b.evalCtx.Settings.Version.IsActive(ctx, clusterversion.EmptyArraysInInvertedIndexes) {
+		idx.Version = descpb.EmptyArraysInInvertedIndexesVersion
+	}
+	if err := idx.FillColumns(n.Columns); err != nil {
+		return err
+	}
+	if n.Predicate != nil {
+		idxValidator := schemaexpr.MakeIndexPredicateValidator(ctx, n.Table, table, b.semaCtx)
+		expr, err := idxValidator.Validate(n.Predicate)
+		if err != nil {
+			return err
+		}
+		idx.Predicate = expr
+	}
+	if err := mut.AddIndexMutation(&idx, descpb.DescriptorMutation_ADD); err != nil {
+		return err
+	}
+	if err := mut.AllocateIDs(ctx); err != nil {
+		return err
+	}
+	added := mut.Mutations[len(mut.Mutations)-1].GetIndex()
+
+	b.addNode(scpb.Target_ADD, &scpb.SecondaryIndex{
+		TableID:      table.GetID(),
+		Index:        *protoutil.Clone(added).(*descpb.IndexDescriptor),
+		PrimaryIndex: table.GetPrimaryIndexID(),
+	})
+	return nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scbuild
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// DropIndex builds targets and transforms the provided schema change nodes
+// accordingly, given a DROP INDEX statement.
+func (b *Builder) DropIndex(
+	ctx context.Context, nodes []*scpb.Node, n *tree.DropIndex,
+) ([]*scpb.Node, error) {
+	b.nodes = nodes
+	defer func() {
+		b.nodes = nil
+	}()
+	for _, index := range n.IndexList {
+		if err := b.dropIndex(ctx, n, index); err != nil {
+			return nil, err
+		}
+	}
+	return b.builtNodes(), nil
+}
+
+func (b *Builder) dropIndex(
+	ctx context.Context, n *tree.DropIndex, index *tree.TableIndexName,
+) error {
+	tn := index.Table
+	var table catalog.TableDescriptor
+	var err error
+	if tn.ObjectName == "" {
+		table, err = b.findTableContainingIndex(ctx, &tn, index.Index, !n.IfExists)
+	} else {
+		table, err = resolver.ResolveExistingTableObject(ctx, b.res, &tn, tree.ObjectLookupFlags{
+			CommonLookupFlags: tree.CommonLookupFlags{Required: !n.IfExists},
+		})
+	}
+	if err != nil || table == nil {
+		return err
+	}
+	if !table.IsTable() || table.IsForeignTable() {
+		return &notImplementedError{n: n, detail: "only supported on tables"}
+	}
+	if err := b.auth.CheckPrivilege(ctx, table, privilege.CREATE); err != nil {
+		return err
+	}
+
+	idx, err := table.FindIndexWithName(string(index.Index))
+	if err != nil {
+		if n.IfExists {
+			return nil
+		}
+		return pgerror.WithCandidateCode(err, pgcode.UndefinedObject)
+	}
+	// Index drops are idempotent while the index is being dropped.
+	if idx.Dropped() {
+		return nil
+	}
+	if idx.Primary() {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.FeatureNotSupported, "cannot drop the primary index of a table using DROP INDEX"),
+			"instead, use ALTER TABLE ... ALTER PRIMARY KEY or"+
+				"use DROP CONSTRAINT ... PRIMARY KEY followed by ADD CONSTRAINT ... PRIMARY KEY in a transaction",
+		)
+	}
+	if idx.IsUnique() && !idx.IsCreatedExplicitly() && n.DropBehavior != tree.DropCascade {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"index %q is in use as unique constraint", idx.GetName()),
+			"use CASCADE if you really want to drop it.",
+		)
+	}
+	if reason := dropIndexUnsupportedReason(ctx, b.evalCtx.Settings, table, idx); reason != "" {
+		return &notImplementedError{n: n, detail: reason}
+	}
+	if b.hasPendingNodes(table.GetID(), func(n *scpb.Node) bool {
+		_, ok := n.Element().(*scpb.SecondaryIndex)
+		return ok
+	}) {
+		return &notImplementedError{n: n, detail: "table has other pending schema changes"}
+	}
+
+	b.addNode(scpb.Target_DROP, &scpb.SecondaryIndex{
+		TableID:      table.GetID(),
+		Index:        *protoutil.Clone(idx.IndexDesc()).(*descpb.IndexDescriptor),
+		PrimaryIndex: table.GetPrimaryIndexID(),
+	})
+	return nil
+}
+
+// findTableContainingIndex searches the schema which the prefix of tn resolves
+// to for the table containing the index with the given name, like the legacy
+// schema changer does for index names which are not qualified with a table
+// name. The table name is filled into tn.
+func (b *Builder) findTableContainingIndex(
+	ctx context.Context, tn *tree.TableName, idxName tree.UnrestrictedName, required bool,
+) (catalog.TableDescriptor, error) {
+	found, _, err := tn.ObjectNamePrefix.Resolve(
+		ctx, b.res, b.res.CurrentDatabase(), b.res.CurrentSearchPath(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if !required {
+			return nil, nil
+		}
+		err = pgerror.Newf(pgcode.UndefinedObject,
+			"schema or database was not found while searching index: %q",
+			tree.ErrString(&idxName))
+		return nil, errors.WithHint(err, "check the current database and search_path are valid")
+	}
+
+	lookupFlags := b.res.CommonLookupFlags(required)
+	sa := b.res.LogicalSchemaAccessor()
+	db, err := sa.GetDatabaseDesc(ctx, b.res.Txn(), b.evalCtx.Codec, tn.Catalog(), lookupFlags)
+	if db == nil || err != nil {
+		return nil, err
+	}
+	tns, err := sa.GetObjectNames(ctx, b.res.Txn(), b.evalCtx.Codec, db, tn.Schema(),
+		tree.DatabaseListFlags{CommonLookupFlags: lookupFlags, ExplicitPrefix: true})
+	if err != nil {
+		return nil, err
+	}
+	var result catalog.TableDescriptor
+	for i := range tns {
+		candidateName := tns[i]
+		candidate, err := resolver.ResolveExistingTableObject(ctx, b.res, &candidateName,
+			tree.ObjectLookupFlags{})
+		if err != nil {
+			return nil, err
+		}
+		if candidate == nil || !(candidate.IsTable() || candidate.MaterializedView()) {
+			continue
+		}
+		idx, err := candidate.FindIndexWithName(string(idxName))
+		if err != nil || idx.Dropped() {
+			// err is nil if the index does not exist on the table.
+			continue
+		}
+		if result != nil {
+			return nil, pgerror.Newf(pgcode.AmbiguousParameter,
+				"index name %q is ambiguous (found in %s and %s)",
+				idxName, candidateName.String(), tn.String())
+		}
+		result = candidate
+		*tn = candidateName
+	}
+	if result == nil && required {
+		return nil, pgerror.Newf(pgcode.UndefinedObject, "index %q does not exist", idxName)
+	}
+	return result, nil
+}
+
+// dropIndexUnsupportedReason returns a non-empty string if dropping the index
+// requires work which the new schema changer cannot yet perform.
+func dropIndexUnsupportedReason(
+	ctx context.Context, settings *cluster.Settings, table catalog.TableDescriptor, idx catalog.Index,
+) string {
+	switch {
+	case idx.Adding():
+		return "index is being added"
+	case idx.IsSharded():
+		return "hash sharded index"
+	case idx.IsInterleaved() || idx.NumInterleavedBy() > 0:
+		return "interleaved index"
+	case idx.GetPartitioning().NumColumns > 0 || table.IsPartitionAllBy():
+		return "partitioned index"
+	case len(table.GetOutboundFKs()) > 0 &&
+		!settings.Version.IsActive(ctx, clusterversion.NoOriginFKIndexes):
+		// Before this version, the origin columns of foreign keys need an index.
+		return "table has outbound foreign keys"
+	case indexIsOnlyReferencedUniqueConstraint(table, idx):
+		// The legacy schema changer drops the foreign keys with CASCADE, or
+		// reports the foreign keys which depend on the index.
+		return "index is used by a foreign key"
+	}
+	var dependedOn bool
+	_ = table.ForeachDependedOnBy(func(dep *descpb.TableDescriptor_Reference) error {
+		dependedOn = dependedOn || dep.IndexID == idx.GetID()
+		return nil
+	})
+	if dependedOn {
+		return "index is referenced by a view"
+	}
+	return ""
+}
+
+// indexIsOnlyReferencedUniqueConstraint returns true if the index is the only
+// unique constraint which can serve one of the foreign keys which reference the
+// table.
+func indexIsOnlyReferencedUniqueConstraint(table catalog.TableDescriptor, idx catalog.Index) bool {
+	if !idx.IsUnique() || idx.IsPartial() {
+		return false
+	}
+	for i := range table.GetInboundFKs() {
+		referencedColIDs := table.GetInboundFKs()[i].ReferencedColumnIDs
+		if !idx.IsValidReferencedUniqueConstraint(referencedColIDs) {
+			continue
+		}
+		if catalog.FindActiveIndex(table, func(other catalog.Index) bool {
+			return other.GetID() != idx.GetID() && !other.IsPartial() &&
+				other.IsValidReferencedUniqueConstraint(referencedColIDs)
+		}) != nil {
+			continue
+		}
+		served := false
+		for j := range table.GetUniqueWithoutIndexConstraints() {
+			uc := &table.GetUniqueWithoutIndexConstraints()[j]
+			served = served || uc.IsValidReferencedUniqueConstraint(referencedColIDs)
+		}
+		if !served {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scbuild
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// DropTable builds targets and transforms the provided schema change nodes
+// accordingly, given a DROP TABLE statement.
+func (b *Builder) DropTable(
+	ctx context.Context, nodes []*scpb.Node, n *tree.DropTable,
+) ([]*scpb.Node, error) {
+	b.nodes = nodes
+	defer func() {
+		b.nodes = nil
+	}()
+	for i := range n.Names {
+		if err := b.dropTable(ctx, n, &n.Names[i]); err != nil {
+			return nil, err
+		}
+	}
+	return b.builtNodes(), nil
+}
+
+func (b *Builder) dropTable(ctx context.Context, n *tree.DropTable, name *tree.TableName) error {
+	tn := *name
+	table, err := resolver.ResolveExistingTableObject(ctx, b.res, &tn, tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{Required: !n.IfExists},
+	})
+	if err != nil || table == nil {
+		return err
+	}
+	if !table.IsTable() || table.IsForeignTable() {
+		return &notImplementedError{n: n, detail: "only supported on tables"}
+	}
+	if err := b.auth.CheckPrivilege(ctx, table, privilege.DROP); err != nil {
+		return err
+	}
+	if reason := dropTableUnsupportedReason(table); reason != "" {
+		return &notImplementedError{n: n, detail: reason}
+	}
+	if b.hasPendingNodes(table.GetID(), func(*scpb.Node) bool { return false }) {
+		return &notImplementedError{n: n, detail: "table has other pending schema changes"}
+	}
+	b.addNode(scpb.Target_DROP, &scpb.Table{TableID: table.GetID()})
+	return nil
+}
+
+// dropTableUnsupportedReason returns a non-empty string if dropping the table
+// requires work on other descriptors which the new schema changer cannot yet
+// perform.
+func dropTableUnsupportedReason(table catalog.TableDescriptor) string {
+	switch {
+	case len(table.GetMutations()) > 0:
+		return "table is undergoing a schema change"
+	case len(table.GetOutboundFKs()) > 0 || len(table.GetInboundFKs()) > 0:
+		return "table has foreign keys"
+	case table.ContainsUserDefinedTypes():
+		return "table references user defined types"
+	}
+	if catalog.FindIndex(table, catalog.IndexOpts{}, func(idx catalog.Index) bool {
+		return idx.IsInterleaved() || idx.NumInterleavedBy() > 0
+	}) != nil {
+		return "table is interleaved"
+	}
+	var dependedOn bool
+	_ = table.ForeachDependedOnBy(func(*descpb.TableDescriptor_Reference) error {
+		dependedOn = true
+		return nil
+	})
+	if dependedOn {
+		return "table is referenced by a view"
+	}
+	for _, col := range table.GetPublicColumns() {
+		if len(col.UsesSequenceIds) > 0 || len(col.OwnsSequenceIds) > 0 {
+			return "table references sequences"
+		}
+	}
+	return ""
+}
@@ -14,10 +14,14 @@ import (
 	"context"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scexec/descriptorutils"
@@ -36,6 +40,8 @@ type Executor struct {
 	codec           keys.SQLCodec
 	indexBackfiller IndexBackfiller
 	jobTracker      JobProgressTracker
+	validator       Validator
+	jobCreator      TransactionalJobCreator
 }
 
 // NewExecutor creates a new Executor.
@@ -45,6 +51,8 @@ func NewExecutor(
 	codec keys.SQLCodec,
 	backfiller IndexBackfiller,
 	tracker JobProgressTracker,
+	validator Validator,
+	jobCreator TransactionalJobCreator,
 ) *Executor {
 	return &Executor{
 		txn:             txn,
@@ -52,6 +60,8 @@ func NewExecutor(
 		codec:           codec,
 		indexBackfiller: backfiller,
 		jobTracker:      tracker,
+		validator:       validator,
+		jobCreator:      jobCreator,
 	}
 }
 
@@ -70,10 +80,72 @@ func (ex *Executor) ExecuteOps(ctx context.Context, toExecute scop.Ops) error {
 }
 
 func (ex *Executor) executeValidationOps(ctx context.Context, execute []scop.Op) error {
-	log.Errorf(ctx, "not implemented")
+	if ex.validator == nil {
+		return errors.AssertionFailedf("cannot execute validation ops without a validator")
+	}
+	for _, op := range execute {
+		var err error
+		switch op := op.(type) {
+		case scop.ValidateUniqueIndex:
+			err = ex.executeValidateUniqueIndex(ctx, op)
+		case scop.ValidateCheckConstraint:
+			err = ex.executeValidateCheckConstraint(ctx, op)
+		case scop.ValidateForeignKey:
+			err = ex.executeValidateForeignKey(ctx, op)
+		default:
+			return errors.AssertionFailedf("unknown validation op %T", op)
+		}
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (ex *Executor) executeValidateUniqueIndex(
+	ctx context.Context, op scop.ValidateUniqueIndex,
+) error {
+	table, err := ex.getTableAvoidingCache(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	return ex.validator.ValidateUniqueIndex(ctx, ex.txn, table, op.IndexID)
+}
+
+func (ex *Executor) executeValidateCheckConstraint(
+	ctx context.Context, op scop.ValidateCheckConstraint,
+) error {
+	table, err := ex.getTableAvoidingCache(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	return ex.validator.ValidateCheckConstraint(ctx, ex.txn, table, op.Name)
+}
+
+func (ex *Executor) executeValidateForeignKey(
+	ctx context.Context, op scop.ValidateForeignKey,
+) error {
+	table, err := ex.getTableAvoidingCache(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	return ex.validator.ValidateForeignKey(ctx, ex.txn, table, op.Name)
+}
+
+// getTableAvoidingCache reads the table descriptor from the store, bypassing
+// the lease manager. See executeIndexBackfillOp.
+func (ex *Executor) getTableAvoidingCache(
+	ctx context.Context, id descpb.ID,
+) (catalog.TableDescriptor, error) {
+	return ex.descsCollection.GetImmutableTableByID(ctx, ex.txn, id, tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{
+			Required:       true,
+			RequireMutable: false,
+			AvoidCached:    true,
+		},
+	})
+}
+
 func (ex *Executor) executeBackfillOps(ctx context.Context, execute []scop.Op) error {
 	// TODO(ajwerner): Run backfills in parallel. Will require some plumbing for
 	// checkpointing at the very least.
@@ -98,13 +170,7 @@ func (ex *Executor) executeIndexBackfillOp(ctx context.Context, op scop.Backfill
 	// the descriptor is read from the store. That means it will not be leased.
 	// This relies on changed to the descriptor not messing with this index
 	// backfill.
-	table, err := ex.descsCollection.GetImmutableTableByID(ctx, ex.txn, op.TableID, tree.ObjectLookupFlags{
-		CommonLookupFlags: tree.CommonLookupFlags{
-			Required:       true,
-			RequireMutable: false,
-			AvoidCached:    true,
-		},
-	})
+	table, err := ex.getTableAvoidingCache(ctx, op.TableID)
 	if err != nil {
 		return err
 	}
@@ -136,6 +202,32 @@ type IndexBackfiller interface {
 	) error
 }
 
+// Validator performs the validation of constraints and unique indexes against
+// the data in a table.
+type Validator interface {
+	// ValidateUniqueIndex checks that the rows of the table do not contain
+	// duplicate values for the key columns of the given index.
+	ValidateUniqueIndex(
+		ctx context.Context, txn *kv.Txn, table catalog.TableDescriptor, indexID descpb.IndexID,
+	) error
+	// ValidateCheckConstraint checks that all rows of the table satisfy the
+	// check constraint with the given name.
+	ValidateCheckConstraint(
+		ctx context.Context, txn *kv.Txn, table catalog.TableDescriptor, name string,
+	) error
+	// ValidateForeignKey checks that all rows of the table reference existing
+	// rows of the table referenced by the foreign key with the given name.
+	ValidateForeignKey(
+		ctx context.Context, txn *kv.Txn, table catalog.TableDescriptor, name string,
+	) error
+}
+
+// TransactionalJobCreator creates jobs in the provided transaction. It is
+// implemented by *jobs.Registry.
+type TransactionalJobCreator interface {
+	CreateJobWithTxn(ctx context.Context, record jobs.Record, txn *kv.Txn) (*jobs.Job, error)
+}
+
 // JobProgressTracker abstracts the infrastructure to read and write backfill
 // progress to job state.
 type JobProgressTracker interface {
@@ -178,13 +270,20 @@ func (ex *Executor) executeDescriptorMutationOps(ctx context.Context, ops []scop
 		descs: ex.descsCollection,
 		txn:   ex.txn,
 	}
-	v := scmutationexec.NewMutationVisitor(dg)
+	v := scmutationexec.NewMutationVisitor(dg, dg)
 	for _, op := range ops {
 		if err := op.(scop.MutationOp).Visit(ctx, v); err != nil {
 			return err
 		}
 	}
 	ba := ex.txn.NewBatch()
+	for _, nameInfos := range dg.drainedNames {
+		for _, ni := range nameInfos {
+			catalogkv.WriteObjectNamespaceEntryRemovalToBatch(
+				ctx, ba, ex.codec, ni.ParentID, ni.ParentSchemaID, ni.Name, false, /* kvTrace */
+			)
+		}
+	}
 	for _, id := range dg.retrieved.Ordered() {
 		desc, err := ex.descsCollection.GetMutableDescriptorByID(ctx, id, ex.txn)
 		if err != nil {
@@ -197,5 +296,42 @@ func (ex *Executor) executeDescriptorMutationOps(ctx context.Context, ops []scop
 	if err := ex.txn.Run(ctx, ba); err != nil {
 		return errors.Wrap(err, "writing descriptors")
 	}
+	return ex.createGCJobs(ctx, dg)
+}
+
+func (ex *Executor) createGCJobs(ctx context.Context, dg *mutationDescGetter) error {
+	if len(dg.gcJobs) == 0 {
+		return nil
+	}
+	if ex.jobCreator == nil {
+		return errors.AssertionFailedf("cannot create GC jobs without a job creator")
+	}
+	for i, details := range dg.gcJobs {
+		descriptorIDs := []descpb.ID{details.ParentID}
+		if len(details.Tables) > 0 {
+			descriptorIDs = descriptorIDs[:0]
+			for _, t := range details.Tables {
+				descriptorIDs = append(descriptorIDs, t.ID)
+			}
+		}
+		record := jobs.Record{
+			Description:   dg.gcJobDescriptions[i],
+			Username:      security.NodeUserName(),
+			DescriptorIDs: descriptorIDs,
+			Details:       details,
+			Progress:      jobspb.SchemaChangeGCProgress{},
+			RunningStatus: runningStatusWaitingGC,
+			NonCancelable: true,
+		}
+		j, err := ex.jobCreator.CreateJobWithTxn(ctx, record, ex.txn)
+		if err != nil {
+			return err
+		}
+		log.VEventf(ctx, 2, "created GC job %d", *j.ID())
+	}
 	return nil
 }
+
+// runningStatusWaitingGC mirrors the running status used by the legacy schema
+// changer for GC jobs.
+const runningStatusWaitingGC = jobs.RunningStatus("waiting for GC TTL")
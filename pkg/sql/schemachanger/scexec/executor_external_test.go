@@ -15,6 +15,7 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -63,6 +64,10 @@ func setupTestInfra(t testing.TB) *testInfra {
 	}
 }
 
+func (ti *testInfra) jobRegistry() *jobs.Registry {
+	return ti.tc.Server(0).JobRegistry().(*jobs.Registry)
+}
+
 func (ti *testInfra) txn(
 	ctx context.Context,
 	f func(ctx context.Context, txn *kv.Txn, descriptors *descs.Collection) error,
@@ -128,7 +133,7 @@ CREATE TABLE db.t (
 		require.NoError(t, ti.txn(ctx, func(
 			ctx context.Context, txn *kv.Txn, descriptors *descs.Collection,
 		) error {
-			ex := scexec.NewExecutor(txn, descriptors, ti.lm.Codec(), nil, nil)
+			ex := scexec.NewExecutor(txn, descriptors, ti.lm.Codec(), nil, nil, nil, nil)
 			_, orig, err := descriptors.GetImmutableTableByName(ctx, txn, &tn, immFlags)
 			require.NoError(t, err)
 			require.Equal(t, c.orig(), orig)
@@ -312,6 +317,8 @@ func TestSchemaChanger(t *testing.T) {
 						ti.lm.Codec(),
 						noopBackfiller{},
 						nil,
+						noopValidator{},
+						nil,
 					)
 					require.NoError(t, exec.ExecuteOps(ctx, s.Ops))
 					ts = s.After
@@ -328,7 +335,9 @@ func TestSchemaChanger(t *testing.T) {
 			})
 			require.NoError(t, err)
 			for _, s := range sc.Stages {
-				exec := scexec.NewExecutor(txn, descriptors, ti.lm.Codec(), noopBackfiller{}, nil)
+				exec := scexec.NewExecutor(
+					txn, descriptors, ti.lm.Codec(), noopBackfiller{}, nil, noopValidator{}, ti.jobRegistry(),
+				)
 				require.NoError(t, exec.ExecuteOps(ctx, s.Ops))
 				after = s.After
 			}
@@ -381,9 +390,10 @@ func TestSchemaChanger(t *testing.T) {
 				resolver.SchemaResolver
 				SemaCtx() *tree.SemaContext
 				EvalContext() *tree.EvalContext
+				scbuild.AuthorizationAccessor
 			})
 			defer cleanup()
-			b := scbuild.NewBuilder(planner, planner.SemaCtx(), planner.EvalContext())
+			b := scbuild.NewBuilder(planner, planner.SemaCtx(), planner.EvalContext(), planner)
 			parsed, err := parser.Parse("ALTER TABLE db.foo ADD COLUMN j INT")
 			require.NoError(t, err)
 			require.Len(t, parsed, 1)
@@ -399,7 +409,9 @@ func TestSchemaChanger(t *testing.T) {
 				})
 				require.NoError(t, err)
 				for _, s := range sc.Stages {
-					require.NoError(t, scexec.NewExecutor(txn, descriptors, ti.lm.Codec(), noopBackfiller{}, nil).ExecuteOps(ctx, s.Ops))
+					require.NoError(t, scexec.NewExecutor(
+						txn, descriptors, ti.lm.Codec(), noopBackfiller{}, nil, noopValidator{}, nil,
+					).ExecuteOps(ctx, s.Ops))
 					ts = s.After
 				}
 			}
@@ -413,7 +425,9 @@ func TestSchemaChanger(t *testing.T) {
 			})
 			require.NoError(t, err)
 			for _, s := range sc.Stages {
-				exec := scexec.NewExecutor(txn, descriptors, ti.lm.Codec(), noopBackfiller{}, nil)
+				exec := scexec.NewExecutor(
+					txn, descriptors, ti.lm.Codec(), noopBackfiller{}, nil, noopValidator{}, ti.jobRegistry(),
+				)
 				require.NoError(t, exec.ExecuteOps(ctx, s.Ops))
 			}
 			return nil
@@ -437,3 +451,25 @@ func (n noopBackfiller) BackfillIndex(
 }
 
 var _ scexec.IndexBackfiller = noopBackfiller{}
+
+type noopValidator struct{}
+
+func (noopValidator) ValidateUniqueIndex(
+	ctx context.Context, txn *kv.Txn, table catalog.TableDescriptor, indexID descpb.IndexID,
+) error {
+	return nil
+}
+
+func (noopValidator) ValidateCheckConstraint(
+	ctx context.Context, txn *kv.Txn, table catalog.TableDescriptor, name string,
+) error {
+	return nil
+}
+
+func (noopValidator) ValidateForeignKey(
+	ctx context.Context, txn *kv.Txn, table catalog.TableDescriptor, name string,
+) error {
+	return nil
+}
+
+var _ scexec.Validator = noopValidator{}
@@ -12,19 +12,30 @@ package scexec
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scexec/scmutationexec"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 type mutationDescGetter struct {
 	descs     *descs.Collection
 	txn       *kv.Txn
 	retrieved catalog.DescriptorIDSet
+
+	// drainedNames holds the namespace entries to remove, keyed by the ID of
+	// the descriptor which owned them.
+	drainedNames map[descpb.ID][]descpb.NameInfo
+	// gcJobs holds the GC jobs to create once the descriptors are written.
+	gcJobs []jobspb.SchemaChangeGCDetails
+	// gcJobDescriptions holds the descriptions for the jobs in gcJobs.
+	gcJobDescriptions []string
 }
 
 func (m *mutationDescGetter) GetMutableTableByID(
@@ -39,4 +50,41 @@ func (m *mutationDescGetter) GetMutableTableByID(
 	return table, nil
 }
 
+func (m *mutationDescGetter) AddDrainedName(id descpb.ID, nameInfo descpb.NameInfo) {
+	if m.drainedNames == nil {
+		m.drainedNames = make(map[descpb.ID][]descpb.NameInfo)
+	}
+	m.drainedNames[id] = append(m.drainedNames[id], nameInfo)
+}
+
+func (m *mutationDescGetter) AddNewGCJobForTable(table catalog.TableDescriptor) {
+	m.gcJobs = append(m.gcJobs, jobspb.SchemaChangeGCDetails{
+		Tables: []jobspb.SchemaChangeGCDetails_DroppedID{
+			{
+				ID:       table.GetID(),
+				DropTime: table.GetDropTime(),
+			},
+		},
+	})
+	m.gcJobDescriptions = append(m.gcJobDescriptions,
+		fmt.Sprintf("GC for dropped table %q", table.GetName()))
+}
+
+func (m *mutationDescGetter) AddNewGCJobForIndex(
+	table catalog.TableDescriptor, index *descpb.IndexDescriptor,
+) {
+	m.gcJobs = append(m.gcJobs, jobspb.SchemaChangeGCDetails{
+		Indexes: []jobspb.SchemaChangeGCDetails_DroppedIndex{
+			{
+				IndexID:  index.ID,
+				DropTime: timeutil.Now().UnixNano(),
+			},
+		},
+		ParentID: table.GetID(),
+	})
+	m.gcJobDescriptions = append(m.gcJobDescriptions,
+		fmt.Sprintf("GC for dropped index %q on table %q", index.Name, table.GetName()))
+}
+
 var _ scmutationexec.MutableDescGetter = (*mutationDescGetter)(nil)
+var _ scmutationexec.MutationVisitorStateUpdater = (*mutationDescGetter)(nil)
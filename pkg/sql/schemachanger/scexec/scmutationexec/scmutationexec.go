@@ -14,10 +14,12 @@ import (
 	"context"
 	"sort"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scop"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
 
@@ -27,13 +29,30 @@ type MutableDescGetter interface {
 	GetMutableTableByID(ctx context.Context, id descpb.ID) (*tabledesc.Mutable, error)
 }
 
+// MutationVisitorStateUpdater is the interface through which the visitor
+// records side effects of the mutations which do not consist of changes to
+// descriptors.
+type MutationVisitorStateUpdater interface {
+	// AddDrainedName marks a namespace entry for deletion.
+	AddDrainedName(id descpb.ID, nameInfo descpb.NameInfo)
+
+	// AddNewGCJobForTable enqueues a GC job for the given dropped table.
+	AddNewGCJobForTable(table catalog.TableDescriptor)
+
+	// AddNewGCJobForIndex enqueues a GC job for the given dropped index.
+	AddNewGCJobForIndex(table catalog.TableDescriptor, index *descpb.IndexDescriptor)
+}
+
 // NewMutationVisitor creates a new scop.MutationVisitor.
-func NewMutationVisitor(descs MutableDescGetter) scop.MutationVisitor {
-	return &visitor{descs: descs}
+func NewMutationVisitor(
+	descs MutableDescGetter, s MutationVisitorStateUpdater,
+) scop.MutationVisitor {
+	return &visitor{descs: descs, s: s}
 }
 
 type visitor struct {
 	descs MutableDescGetter
+	s     MutationVisitorStateUpdater
 }
 
 func (m *visitor) MakeAddedColumnDeleteAndWriteOnly(
@@ -309,8 +328,204 @@ func (m *visitor) MakeIndexAbsent(ctx context.Context, op scop.MakeIndexAbsent)
 	if err != nil {
 		return err
 	}
-	_, err = removeMutation(ctx, table, getIndexMutation(op.IndexID), descpb.DescriptorMutation_DELETE_ONLY)
-	return err
+	mut, err := removeMutation(ctx, table, getIndexMutation(op.IndexID), descpb.DescriptorMutation_DELETE_ONLY)
+	if err != nil {
+		return err
+	}
+	m.s.AddNewGCJobForIndex(table, mut.GetIndex())
+	return nil
+}
+
+func (m *visitor) MakeAddedSecondaryIndexPublic(
+	ctx context.Context, op scop.MakeAddedSecondaryIndexPublic,
+) error {
+	table, err := m.descs.GetMutableTableByID(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	mut, err := removeMutation(
+		ctx,
+		table,
+		getIndexMutation(op.IndexID),
+		descpb.DescriptorMutation_DELETE_AND_WRITE_ONLY,
+	)
+	if err != nil {
+		return err
+	}
+	idx := protoutil.Clone(mut.GetIndex()).(*descpb.IndexDescriptor)
+	if op.Name != "" {
+		idx.Name = op.Name
+	}
+	table.Indexes = append(table.Indexes, *idx)
+	return nil
+}
+
+func (m *visitor) MakeAddedCheckConstraintPublic(
+	ctx context.Context, op scop.MakeAddedCheckConstraintPublic,
+) error {
+	table, err := m.descs.GetMutableTableByID(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	for _, ck := range table.Checks {
+		if ck.Name != op.Name {
+			continue
+		}
+		if ck.Validity != descpb.ConstraintValidity_Validating {
+			return errors.AssertionFailedf(
+				"check constraint %q in table %d: unexpected validity %v", op.Name, op.TableID, ck.Validity)
+		}
+		ck.Validity = descpb.ConstraintValidity_Validated
+		return nil
+	}
+	return errors.AssertionFailedf("failed to find check constraint %q in table %d", op.Name, op.TableID)
+}
+
+func (m *visitor) RemoveCheckConstraint(ctx context.Context, op scop.RemoveCheckConstraint) error {
+	table, err := m.descs.GetMutableTableByID(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	for i, ck := range table.Checks {
+		if ck.Name == op.Name {
+			table.Checks = append(table.Checks[:i], table.Checks[i+1:]...)
+			return nil
+		}
+	}
+	return errors.AssertionFailedf("failed to find check constraint %q in table %d", op.Name, op.TableID)
+}
+
+func (m *visitor) AddForeignKey(ctx context.Context, op scop.AddForeignKey) error {
+	table, err := m.descs.GetMutableTableByID(ctx, op.FK.OriginTableID)
+	if err != nil {
+		return err
+	}
+	referenced, err := m.descs.GetMutableTableByID(ctx, op.FK.ReferencedTableID)
+	if err != nil {
+		return err
+	}
+	fk := op.FK
+	if op.Unvalidated {
+		fk.Validity = descpb.ConstraintValidity_Unvalidated
+	} else {
+		fk.Validity = descpb.ConstraintValidity_Validating
+	}
+	table.OutboundFKs = append(table.OutboundFKs, fk)
+	referenced.InboundFKs = append(referenced.InboundFKs, fk)
+	return nil
+}
+
+func (m *visitor) MakeAddedForeignKeyPublic(
+	ctx context.Context, op scop.MakeAddedForeignKeyPublic,
+) error {
+	table, err := m.descs.GetMutableTableByID(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	referenced, err := m.descs.GetMutableTableByID(ctx, op.ReferencedTableID)
+	if err != nil {
+		return err
+	}
+	fk, err := findOutboundFK(table, op.Name)
+	if err != nil {
+		return err
+	}
+	if fk.Validity != descpb.ConstraintValidity_Validating {
+		return errors.AssertionFailedf(
+			"foreign key %q in table %d: unexpected validity %v", op.Name, op.TableID, fk.Validity)
+	}
+	fk.Validity = descpb.ConstraintValidity_Validated
+	if backref := findInboundFK(referenced, op.TableID, op.Name); backref != nil {
+		backref.Validity = descpb.ConstraintValidity_Validated
+	}
+	return nil
+}
+
+func (m *visitor) RemoveForeignKey(ctx context.Context, op scop.RemoveForeignKey) error {
+	table, err := m.descs.GetMutableTableByID(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	referenced, err := m.descs.GetMutableTableByID(ctx, op.ReferencedTableID)
+	if err != nil {
+		return err
+	}
+	for i := range table.OutboundFKs {
+		if table.OutboundFKs[i].Name == op.Name {
+			table.OutboundFKs = append(table.OutboundFKs[:i], table.OutboundFKs[i+1:]...)
+			break
+		}
+	}
+	for i := range referenced.InboundFKs {
+		backref := &referenced.InboundFKs[i]
+		if backref.OriginTableID == op.TableID && backref.Name == op.Name {
+			referenced.InboundFKs = append(referenced.InboundFKs[:i], referenced.InboundFKs[i+1:]...)
+			return nil
+		}
+	}
+	return errors.AssertionFailedf("failed to find foreign key %q of table %d in table %d",
+		op.Name, op.TableID, op.ReferencedTableID)
+}
+
+func findOutboundFK(
+	table *tabledesc.Mutable, name string,
+) (*descpb.ForeignKeyConstraint, error) {
+	for i := range table.OutboundFKs {
+		if table.OutboundFKs[i].Name == name {
+			return &table.OutboundFKs[i], nil
+		}
+	}
+	return nil, errors.AssertionFailedf("failed to find foreign key %q in table %d", name, table.ID)
+}
+
+func findInboundFK(
+	table *tabledesc.Mutable, originTableID descpb.ID, name string,
+) *descpb.ForeignKeyConstraint {
+	for i := range table.InboundFKs {
+		if fk := &table.InboundFKs[i]; fk.OriginTableID == originTableID && fk.Name == name {
+			return fk
+		}
+	}
+	return nil
+}
+
+func (m *visitor) MarkTableAsDropped(ctx context.Context, op scop.MarkTableAsDropped) error {
+	table, err := m.descs.GetMutableTableByID(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	table.State = descpb.DescriptorState_DROP
+	// Interleaved tables share their data with their parent and are cleaned up
+	// along with it, so they don't get a drop time.
+	if !table.IsInterleaved() {
+		table.DropTime = timeutil.Now().UnixNano()
+	}
+	return nil
+}
+
+func (m *visitor) DrainTableName(ctx context.Context, op scop.DrainTableName) error {
+	table, err := m.descs.GetMutableTableByID(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	if !table.Dropped() {
+		return errors.AssertionFailedf("draining name of table %d which is not dropped", op.TableID)
+	}
+	m.s.AddDrainedName(table.GetID(), descpb.NameInfo{
+		ParentID:       table.GetParentID(),
+		ParentSchemaID: table.GetParentSchemaID(),
+		Name:           table.GetName(),
+	})
+	return nil
+}
+
+func (m *visitor) CreateGcJobForTable(ctx context.Context, op scop.CreateGcJobForTable) error {
+	table, err := m.descs.GetMutableTableByID(ctx, op.TableID)
+	if err != nil {
+		return err
+	}
+	m.s.AddNewGCJobForTable(table)
+	return nil
 }
 
 func (m *visitor) AddColumnFamily(ctx context.Context, op scop.AddColumnFamily) error {
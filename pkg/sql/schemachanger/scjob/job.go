@@ -29,6 +29,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logcrash"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
 )
 
 func init() {
@@ -89,6 +91,14 @@ func (n *newSchemaChangeResumer) Resume(ctx context.Context, execCtxI interface{
 	// TODO(ajwerner): Wait for leases on all descriptors before starting to
 	// avoid restarts.
 
+	return n.run(ctx, execCtx, n.targets)
+}
+
+// run plans and executes the post-commit stages of the schema change for the
+// given targets, starting from the states recorded in the job progress.
+func (n *newSchemaChangeResumer) run(
+	ctx context.Context, execCtx sql.JobExecContext, targets []*scpb.Target,
+) error {
 	progress := n.job.Progress()
 	states := progress.GetNewSchemaChange().States
 
@@ -96,22 +106,26 @@ func (n *newSchemaChangeResumer) Resume(ctx context.Context, execCtxI interface{
 	lm := execCtx.LeaseMgr()
 	db := lm.DB()
 	ie := execCtx.ExtendedEvalContext().InternalExecutor.(sqlutil.InternalExecutor)
-	sc, err := scplan.MakePlan(makeTargetStates(ctx, settings, n.targets, states), scplan.Params{
+	sc, err := scplan.MakePlan(makeTargetStates(ctx, settings, targets, states), scplan.Params{
 		ExecutionPhase: scplan.PostCommitPhase,
 	})
 	if err != nil {
 		return err
 	}
 
+	execCfg := execCtx.ExecCfg()
+	validator := sql.NewSchemaChangeValidator(execCfg)
 	for _, s := range sc.Stages {
 		var descriptorsWithUpdatedVersions []lease.IDVersion
 		if err := descs.Txn(ctx, settings, lm, ie, db, func(ctx context.Context, txn *kv.Txn, descriptors *descs.Collection) error {
 			jt := badJobTracker{
 				txn:         txn,
 				descriptors: descriptors,
-				codec:       execCtx.ExecCfg().Codec,
+				codec:       execCfg.Codec,
 			}
-			if err := scexec.NewExecutor(txn, descriptors, execCtx.ExecCfg().Codec, execCtx.ExecCfg().IndexBackfiller, jt).ExecuteOps(ctx, s.Ops); err != nil {
+			if err := scexec.NewExecutor(
+				txn, descriptors, execCfg.Codec, execCfg.IndexBackfiller, jt, validator, execCfg.JobRegistry,
+			).ExecuteOps(ctx, s.Ops); err != nil {
 				return err
 			}
 			descriptorsWithUpdatedVersions = descriptors.GetDescriptorsWithNewVersion()
@@ -163,6 +177,32 @@ func makeTargetStates(
 	return ts
 }
 
-func (n *newSchemaChangeResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
-	panic("unimplemented")
+// OnFailOrCancel reverts the schema change by running the post-commit stages
+// for the targets with their directions flipped. Targets which have already
+// been dropped cannot be restored and are left as they are.
+func (n *newSchemaChangeResumer) OnFailOrCancel(ctx context.Context, execCtxI interface{}) error {
+	execCtx := execCtxI.(sql.JobExecContext)
+	progress := n.job.Progress()
+	states := progress.GetNewSchemaChange().States
+	if len(states) != len(n.targets) {
+		return errors.AssertionFailedf("unexpected slice size mismatch %d and %d",
+			len(n.targets), len(states))
+	}
+	return n.run(ctx, execCtx, makeRollbackTargets(n.targets, states))
+}
+
+func makeRollbackTargets(targets []*scpb.Target, states []scpb.State) []*scpb.Target {
+	rollback := make([]*scpb.Target, len(targets))
+	for i, t := range targets {
+		rollback[i] = protoutil.Clone(t).(*scpb.Target)
+		switch t.Direction {
+		case scpb.Target_ADD:
+			rollback[i].Direction = scpb.Target_DROP
+		case scpb.Target_DROP:
+			if states[i] != scpb.State_ABSENT {
+				rollback[i].Direction = scpb.Target_ADD
+			}
+		}
+	}
+	return rollback
 }
@@ -44,6 +44,16 @@ type MakeAddedPrimaryIndexPublic struct {
 	Index   descpb.IndexDescriptor
 }
 
+// MakeAddedSecondaryIndexPublic moves a new secondary index from its mutation
+// to public.
+type MakeAddedSecondaryIndexPublic struct {
+	mutationOp
+	TableID descpb.ID
+	IndexID descpb.IndexID
+	// Name, if set, is the name the index is renamed to as it becomes public.
+	Name string
+}
+
 // MakeDroppedPrimaryIndexDeleteAndWriteOnly moves a dropped primary index from
 // public to DELETE_AND_WRITE_ONLY.
 type MakeDroppedPrimaryIndexDeleteAndWriteOnly struct {
@@ -140,6 +150,48 @@ type AddCheckConstraint struct {
 	Hidden      bool
 }
 
+// MakeAddedCheckConstraintPublic marks a check constraint which was added in
+// the validating state as validated.
+type MakeAddedCheckConstraintPublic struct {
+	mutationOp
+	TableID descpb.ID
+	Name    string
+}
+
+// RemoveCheckConstraint removes a check constraint from the table, regardless
+// of its validity.
+type RemoveCheckConstraint struct {
+	mutationOp
+	TableID descpb.ID
+	Name    string
+}
+
+// AddForeignKey adds a foreign key constraint to its origin table, and its
+// back-reference to the referenced table, in the unvalidated state.
+type AddForeignKey struct {
+	mutationOp
+	FK          descpb.ForeignKeyConstraint
+	Unvalidated bool
+}
+
+// MakeAddedForeignKeyPublic marks a foreign key constraint which was added in
+// the validating state as validated.
+type MakeAddedForeignKeyPublic struct {
+	mutationOp
+	TableID           descpb.ID
+	ReferencedTableID descpb.ID
+	Name              string
+}
+
+// RemoveForeignKey removes a foreign key constraint from its origin table, and
+// its back-reference from the referenced table, regardless of its validity.
+type RemoveForeignKey struct {
+	mutationOp
+	TableID           descpb.ID
+	ReferencedTableID descpb.ID
+	Name              string
+}
+
 // AddColumnFamily adds a column family with the provided descriptor.
 //
 // TODO(ajwerner): Decide whether this should happen explicitly or should be a
@@ -149,3 +201,24 @@ type AddColumnFamily struct {
 	TableID descpb.ID
 	Family  descpb.ColumnFamilyDescriptor
 }
+
+// MarkTableAsDropped marks a table as dropped so that it is no longer
+// accessible to new transactions. Its name remains in the namespace table
+// until DrainTableName is executed.
+type MarkTableAsDropped struct {
+	mutationOp
+	TableID descpb.ID
+}
+
+// DrainTableName removes the namespace entry of a dropped table.
+type DrainTableName struct {
+	mutationOp
+	TableID descpb.ID
+}
+
+// CreateGcJobForTable creates a GC job which will clear the data of a dropped
+// table and remove its descriptor once the GC TTL has elapsed.
+type CreateGcJobForTable struct {
+	mutationOp
+	TableID descpb.ID
+}
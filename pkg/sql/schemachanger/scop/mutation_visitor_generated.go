@@ -25,6 +25,7 @@ type MutationVisitor interface {
 	MakeAddedIndexDeleteOnly(context.Context, MakeAddedIndexDeleteOnly) error
 	MakeAddedIndexDeleteAndWriteOnly(context.Context, MakeAddedIndexDeleteAndWriteOnly) error
 	MakeAddedPrimaryIndexPublic(context.Context, MakeAddedPrimaryIndexPublic) error
+	MakeAddedSecondaryIndexPublic(context.Context, MakeAddedSecondaryIndexPublic) error
 	MakeDroppedPrimaryIndexDeleteAndWriteOnly(context.Context, MakeDroppedPrimaryIndexDeleteAndWriteOnly) error
 	MakeAddedColumnDeleteAndWriteOnly(context.Context, MakeAddedColumnDeleteAndWriteOnly) error
 	MakeDroppedNonPrimaryIndexDeleteAndWriteOnly(context.Context, MakeDroppedNonPrimaryIndexDeleteAndWriteOnly) error
@@ -36,7 +37,15 @@ type MutationVisitor interface {
 	MakeDroppedColumnDeleteOnly(context.Context, MakeDroppedColumnDeleteOnly) error
 	MakeColumnAbsent(context.Context, MakeColumnAbsent) error
 	AddCheckConstraint(context.Context, AddCheckConstraint) error
+	MakeAddedCheckConstraintPublic(context.Context, MakeAddedCheckConstraintPublic) error
+	RemoveCheckConstraint(context.Context, RemoveCheckConstraint) error
+	AddForeignKey(context.Context, AddForeignKey) error
+	MakeAddedForeignKeyPublic(context.Context, MakeAddedForeignKeyPublic) error
+	RemoveForeignKey(context.Context, RemoveForeignKey) error
 	AddColumnFamily(context.Context, AddColumnFamily) error
+	MarkTableAsDropped(context.Context, MarkTableAsDropped) error
+	DrainTableName(context.Context, DrainTableName) error
+	CreateGcJobForTable(context.Context, CreateGcJobForTable) error
 }
 
 // Visit is part of the MutationOp interface.
@@ -54,6 +63,11 @@ func (op MakeAddedPrimaryIndexPublic) Visit(ctx context.Context, v MutationVisit
 	return v.MakeAddedPrimaryIndexPublic(ctx, op)
 }
 
+// Visit is part of the MutationOp interface.
+func (op MakeAddedSecondaryIndexPublic) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.MakeAddedSecondaryIndexPublic(ctx, op)
+}
+
 // Visit is part of the MutationOp interface.
 func (op MakeDroppedPrimaryIndexDeleteAndWriteOnly) Visit(ctx context.Context, v MutationVisitor) error {
 	return v.MakeDroppedPrimaryIndexDeleteAndWriteOnly(ctx, op)
@@ -109,7 +123,47 @@ func (op AddCheckConstraint) Visit(ctx context.Context, v MutationVisitor) error
 	return v.AddCheckConstraint(ctx, op)
 }
 
+// Visit is part of the MutationOp interface.
+func (op MakeAddedCheckConstraintPublic) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.MakeAddedCheckConstraintPublic(ctx, op)
+}
+
+// Visit is part of the MutationOp interface.
+func (op RemoveCheckConstraint) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.RemoveCheckConstraint(ctx, op)
+}
+
+// Visit is part of the MutationOp interface.
+func (op AddForeignKey) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.AddForeignKey(ctx, op)
+}
+
+// Visit is part of the MutationOp interface.
+func (op MakeAddedForeignKeyPublic) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.MakeAddedForeignKeyPublic(ctx, op)
+}
+
+// Visit is part of the MutationOp interface.
+func (op RemoveForeignKey) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.RemoveForeignKey(ctx, op)
+}
+
 // Visit is part of the MutationOp interface.
 func (op AddColumnFamily) Visit(ctx context.Context, v MutationVisitor) error {
 	return v.AddColumnFamily(ctx, op)
 }
+
+// Visit is part of the MutationOp interface.
+func (op MarkTableAsDropped) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.MarkTableAsDropped(ctx, op)
+}
+
+// Visit is part of the MutationOp interface.
+func (op DrainTableName) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.DrainTableName(ctx, op)
+}
+
+// Visit is part of the MutationOp interface.
+func (op CreateGcJobForTable) Visit(ctx context.Context, v MutationVisitor) error {
+	return v.CreateGcJobForTable(ctx, op)
+}
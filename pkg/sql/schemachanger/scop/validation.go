@@ -32,3 +32,11 @@ type ValidateCheckConstraint struct {
 	TableID descpb.ID
 	Name    string
 }
+
+// ValidateForeignKey validates that every row of the origin table of a foreign
+// key references an existing row of the referenced table.
+type ValidateForeignKey struct {
+	validationOp
+	TableID descpb.ID
+	Name    string
+}
@@ -24,6 +24,7 @@ type ValidationOp interface {
 type ValidationVisitor interface {
 	ValidateUniqueIndex(context.Context, ValidateUniqueIndex) error
 	ValidateCheckConstraint(context.Context, ValidateCheckConstraint) error
+	ValidateForeignKey(context.Context, ValidateForeignKey) error
 }
 
 // Visit is part of the ValidationOp interface.
@@ -35,3 +36,8 @@ func (op ValidateUniqueIndex) Visit(ctx context.Context, v ValidationVisitor) er
 func (op ValidateCheckConstraint) Visit(ctx context.Context, v ValidationVisitor) error {
 	return v.ValidateCheckConstraint(ctx, op)
 }
+
+// Visit is part of the ValidationOp interface.
+func (op ValidateForeignKey) Visit(ctx context.Context, v ValidationVisitor) error {
+	return v.ValidateForeignKey(ctx, op)
+}
@@ -71,3 +71,9 @@ func (e *UniqueConstraint) DescriptorID() descpb.ID { return e.TableID }
 
 // DescriptorID implements the Element interface.
 func (e *CheckConstraint) DescriptorID() descpb.ID { return e.TableID }
+
+// DescriptorID implements the Element interface.
+func (e *Table) DescriptorID() descpb.ID { return e.TableID }
+
+// DescriptorID implements the Element interface.
+func (e *ForeignKey) DescriptorID() descpb.ID { return e.TableID }
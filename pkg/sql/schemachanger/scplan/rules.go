@@ -36,6 +36,31 @@ func primaryIndexesReferenceEachOther(this, that *scpb.PrimaryIndex) bool {
 		this.OtherPrimaryIndexID == that.Index.ID
 }
 
+func secondaryIndexReferencesPrimaryIndex(
+	this *scpb.SecondaryIndex, that *scpb.PrimaryIndex,
+) bool {
+	return this.TableID == that.TableID &&
+		this.PrimaryIndex == that.Index.ID
+}
+
+func primaryIndexHasSecondaryIndex(
+	this *scpb.PrimaryIndex, that *scpb.SecondaryIndex,
+) bool {
+	return secondaryIndexReferencesPrimaryIndex(that, this)
+}
+
+func secondaryIndexReferencesReplacedPrimaryIndex(
+	this *scpb.SecondaryIndex, that *scpb.PrimaryIndex,
+) bool {
+	return this.TableID == that.TableID &&
+		this.PrimaryIndex == that.OtherPrimaryIndexID
+}
+
+func secondaryIndexReplacedBySecondaryIndex(this, that *scpb.SecondaryIndex) bool {
+	return this.TableID == that.TableID &&
+		that.FinalName != "" && that.FinalName == this.Index.Name
+}
+
 func sameDirection(a, b scpb.Target_Direction) bool {
 	return a == b
 }
@@ -185,6 +210,13 @@ var rules = map[scpb.Element]targetRules{
 					thatState:    scpb.State_DELETE_AND_WRITE_ONLY,
 					predicate:    primaryIndexesReferenceEachOther,
 				},
+				// The secondary indexes which are keyed on a new primary index must
+				// be ready to become public along with it.
+				{
+					dirPredicate: bothDirectionsEqual(scpb.Target_ADD),
+					thatState:    scpb.State_VALIDATED,
+					predicate:    primaryIndexHasSecondaryIndex,
+				},
 			},
 			scpb.State_DELETE_AND_WRITE_ONLY: {
 				{
@@ -330,6 +362,30 @@ var rules = map[scpb.Element]targetRules{
 					},
 				},
 			},
+			// The BACKFILLED and VALIDATED states are only reached when rolling
+			// back the addition of a primary index.
+			scpb.State_VALIDATED: {
+				{
+					nextState: scpb.State_DELETE_ONLY,
+					op: func(this *scpb.PrimaryIndex) scop.Op {
+						return scop.MakeDroppedIndexDeleteOnly{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+			},
+			scpb.State_BACKFILLED: {
+				{
+					nextState: scpb.State_DELETE_ONLY,
+					op: func(this *scpb.PrimaryIndex) scop.Op {
+						return scop.MakeDroppedIndexDeleteOnly{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+			},
 			scpb.State_DELETE_ONLY: {
 				{
 					nextState: scpb.State_ABSENT,
@@ -343,4 +399,428 @@ var rules = map[scpb.Element]targetRules{
 			},
 		},
 	},
+	(*scpb.SecondaryIndex)(nil): {
+		deps: targetDepRules{
+			scpb.State_PUBLIC: {
+				{
+					dirPredicate: bothDirectionsEqual(scpb.Target_ADD),
+					thatState:    scpb.State_PUBLIC,
+					predicate:    secondaryIndexReferencesPrimaryIndex,
+				},
+			},
+			// A secondary index which is keyed on a primary index which is being
+			// replaced stays public until it is replaced by its rewritten copy,
+			// at the same time as the primary index is replaced.
+			scpb.State_DELETE_AND_WRITE_ONLY: {
+				{
+					dirPredicate: directionsMatch(scpb.Target_DROP, scpb.Target_ADD),
+					thatState:    scpb.State_PUBLIC,
+					predicate:    secondaryIndexReferencesReplacedPrimaryIndex,
+				},
+				{
+					dirPredicate: directionsMatch(scpb.Target_DROP, scpb.Target_ADD),
+					thatState:    scpb.State_PUBLIC,
+					predicate:    secondaryIndexReplacedBySecondaryIndex,
+				},
+			},
+		},
+		forward: targetOpRules{
+			scpb.State_ABSENT: {
+				{
+					predicate: func(this *scpb.SecondaryIndex, flags Params) bool {
+						return flags.ExecutionPhase == StatementPhase &&
+							!flags.CreatedDescriptorIDs.Contains(this.TableID)
+					},
+				},
+				{
+					nextState: scpb.State_DELETE_ONLY,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.MakeAddedIndexDeleteOnly{
+							TableID: this.TableID,
+							Index:   this.Index,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_ONLY: {
+				{
+					predicate: func(this *scpb.SecondaryIndex, flags Params) bool {
+						return flags.ExecutionPhase == PreCommitPhase &&
+							!flags.CreatedDescriptorIDs.Contains(this.TableID)
+					},
+				},
+				{
+					nextState: scpb.State_DELETE_AND_WRITE_ONLY,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.MakeAddedIndexDeleteAndWriteOnly{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_AND_WRITE_ONLY: {
+				{
+					// Unique indexes need to be validated after the backfill as the
+					// backfill does not detect duplicate keys.
+					predicate: func(this *scpb.SecondaryIndex, flags Params) bool {
+						return this.Index.Unique
+					},
+					nextState: scpb.State_BACKFILLED,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.BackfillIndex{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+				{
+					nextState: scpb.State_VALIDATED,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.BackfillIndex{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+			},
+			scpb.State_BACKFILLED: {
+				{
+					nextState: scpb.State_VALIDATED,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.ValidateUniqueIndex{
+							TableID:        this.TableID,
+							PrimaryIndexID: this.PrimaryIndex,
+							IndexID:        this.Index.ID,
+						}
+					},
+				},
+			},
+			scpb.State_VALIDATED: {
+				{
+					nextState: scpb.State_PUBLIC,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.MakeAddedSecondaryIndexPublic{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+							Name:    this.FinalName,
+						}
+					},
+				},
+			},
+		},
+		backwards: targetOpRules{
+			scpb.State_PUBLIC: {
+				{
+					nextState: scpb.State_DELETE_AND_WRITE_ONLY,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.MakeDroppedNonPrimaryIndexDeleteAndWriteOnly{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+			},
+			// The BACKFILLED and VALIDATED states are only reached when rolling
+			// back the addition of an index.
+			scpb.State_VALIDATED: {
+				{
+					nextState: scpb.State_DELETE_ONLY,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.MakeDroppedIndexDeleteOnly{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+			},
+			scpb.State_BACKFILLED: {
+				{
+					nextState: scpb.State_DELETE_ONLY,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.MakeDroppedIndexDeleteOnly{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_AND_WRITE_ONLY: {
+				{
+					predicate: func(this *scpb.SecondaryIndex, flags Params) bool {
+						return !flags.CreatedDescriptorIDs.Contains(this.TableID) &&
+							(flags.ExecutionPhase == StatementPhase ||
+								flags.ExecutionPhase == PreCommitPhase)
+					},
+				},
+				{
+					nextState: scpb.State_DELETE_ONLY,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.MakeDroppedIndexDeleteOnly{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_ONLY: {
+				{
+					nextState: scpb.State_ABSENT,
+					op: func(this *scpb.SecondaryIndex) scop.Op {
+						return scop.MakeIndexAbsent{
+							TableID: this.TableID,
+							IndexID: this.Index.ID,
+						}
+					},
+				},
+			},
+		},
+	},
+	(*scpb.CheckConstraint)(nil): {
+		forward: targetOpRules{
+			scpb.State_ABSENT: {
+				{
+					predicate: func(this *scpb.CheckConstraint, flags Params) bool {
+						return flags.ExecutionPhase == StatementPhase &&
+							!flags.CreatedDescriptorIDs.Contains(this.TableID)
+					},
+				},
+				{
+					// Constraints added with NOT VALID are public right away and are
+					// never validated.
+					predicate: func(this *scpb.CheckConstraint, flags Params) bool {
+						return !this.Validated
+					},
+					nextState: scpb.State_PUBLIC,
+					op: func(this *scpb.CheckConstraint) scop.Op {
+						return scop.AddCheckConstraint{
+							TableID:     this.TableID,
+							Name:        this.Name,
+							Expr:        this.Expr,
+							ColumnIDs:   this.ColumnIDs,
+							Unvalidated: true,
+						}
+					},
+				},
+				{
+					nextState: scpb.State_DELETE_AND_WRITE_ONLY,
+					op: func(this *scpb.CheckConstraint) scop.Op {
+						return scop.AddCheckConstraint{
+							TableID:   this.TableID,
+							Name:      this.Name,
+							Expr:      this.Expr,
+							ColumnIDs: this.ColumnIDs,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_AND_WRITE_ONLY: {
+				{
+					predicate: func(this *scpb.CheckConstraint, flags Params) bool {
+						return flags.ExecutionPhase == PreCommitPhase &&
+							!flags.CreatedDescriptorIDs.Contains(this.TableID)
+					},
+				},
+				{
+					nextState: scpb.State_VALIDATED,
+					op: func(this *scpb.CheckConstraint) scop.Op {
+						return scop.ValidateCheckConstraint{
+							TableID: this.TableID,
+							Name:    this.Name,
+						}
+					},
+				},
+			},
+			scpb.State_VALIDATED: {
+				{
+					nextState: scpb.State_PUBLIC,
+					op: func(this *scpb.CheckConstraint) scop.Op {
+						return scop.MakeAddedCheckConstraintPublic{
+							TableID: this.TableID,
+							Name:    this.Name,
+						}
+					},
+				},
+			},
+		},
+		backwards: targetOpRules{
+			scpb.State_PUBLIC: {
+				{
+					nextState: scpb.State_ABSENT,
+					op: func(this *scpb.CheckConstraint) scop.Op {
+						return scop.RemoveCheckConstraint{
+							TableID: this.TableID,
+							Name:    this.Name,
+						}
+					},
+				},
+			},
+			scpb.State_VALIDATED: {
+				{
+					nextState: scpb.State_ABSENT,
+					op: func(this *scpb.CheckConstraint) scop.Op {
+						return scop.RemoveCheckConstraint{
+							TableID: this.TableID,
+							Name:    this.Name,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_AND_WRITE_ONLY: {
+				{
+					nextState: scpb.State_ABSENT,
+					op: func(this *scpb.CheckConstraint) scop.Op {
+						return scop.RemoveCheckConstraint{
+							TableID: this.TableID,
+							Name:    this.Name,
+						}
+					},
+				},
+			},
+		},
+	},
+	(*scpb.ForeignKey)(nil): {
+		forward: targetOpRules{
+			scpb.State_ABSENT: {
+				{
+					predicate: func(this *scpb.ForeignKey, flags Params) bool {
+						return flags.ExecutionPhase == StatementPhase &&
+							!flags.CreatedDescriptorIDs.Contains(this.TableID)
+					},
+				},
+				{
+					// Constraints added with NOT VALID are public right away and are
+					// never validated.
+					predicate: func(this *scpb.ForeignKey, flags Params) bool {
+						return !this.Validated
+					},
+					nextState: scpb.State_PUBLIC,
+					op: func(this *scpb.ForeignKey) scop.Op {
+						return scop.AddForeignKey{
+							FK:          this.FK,
+							Unvalidated: true,
+						}
+					},
+				},
+				{
+					nextState: scpb.State_DELETE_AND_WRITE_ONLY,
+					op: func(this *scpb.ForeignKey) scop.Op {
+						return scop.AddForeignKey{
+							FK: this.FK,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_AND_WRITE_ONLY: {
+				{
+					predicate: func(this *scpb.ForeignKey, flags Params) bool {
+						return flags.ExecutionPhase == PreCommitPhase &&
+							!flags.CreatedDescriptorIDs.Contains(this.TableID)
+					},
+				},
+				{
+					nextState: scpb.State_VALIDATED,
+					op: func(this *scpb.ForeignKey) scop.Op {
+						return scop.ValidateForeignKey{
+							TableID: this.TableID,
+							Name:    this.FK.Name,
+						}
+					},
+				},
+			},
+			scpb.State_VALIDATED: {
+				{
+					nextState: scpb.State_PUBLIC,
+					op: func(this *scpb.ForeignKey) scop.Op {
+						return scop.MakeAddedForeignKeyPublic{
+							TableID:           this.TableID,
+							ReferencedTableID: this.FK.ReferencedTableID,
+							Name:              this.FK.Name,
+						}
+					},
+				},
+			},
+		},
+		backwards: targetOpRules{
+			scpb.State_PUBLIC: {
+				{
+					nextState: scpb.State_ABSENT,
+					op: func(this *scpb.ForeignKey) scop.Op {
+						return scop.RemoveForeignKey{
+							TableID:           this.TableID,
+							ReferencedTableID: this.FK.ReferencedTableID,
+							Name:              this.FK.Name,
+						}
+					},
+				},
+			},
+			scpb.State_VALIDATED: {
+				{
+					nextState: scpb.State_ABSENT,
+					op: func(this *scpb.ForeignKey) scop.Op {
+						return scop.RemoveForeignKey{
+							TableID:           this.TableID,
+							ReferencedTableID: this.FK.ReferencedTableID,
+							Name:              this.FK.Name,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_AND_WRITE_ONLY: {
+				{
+					nextState: scpb.State_ABSENT,
+					op: func(this *scpb.ForeignKey) scop.Op {
+						return scop.RemoveForeignKey{
+							TableID:           this.TableID,
+							ReferencedTableID: this.FK.ReferencedTableID,
+							Name:              this.FK.Name,
+						}
+					},
+				},
+			},
+		},
+	},
+	(*scpb.Table)(nil): {
+		backwards: targetOpRules{
+			scpb.State_PUBLIC: {
+				{
+					nextState: scpb.State_DELETE_AND_WRITE_ONLY,
+					op: func(this *scpb.Table) scop.Op {
+						return scop.MarkTableAsDropped{
+							TableID: this.TableID,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_AND_WRITE_ONLY: {
+				{
+					// The namespace entry must stay in place until the transaction
+					// which dropped the table has committed and all leases on the
+					// table have been released.
+					predicate: func(this *scpb.Table, flags Params) bool {
+						return flags.ExecutionPhase == StatementPhase ||
+							flags.ExecutionPhase == PreCommitPhase
+					},
+				},
+				{
+					nextState: scpb.State_DELETE_ONLY,
+					op: func(this *scpb.Table) scop.Op {
+						return scop.DrainTableName{
+							TableID: this.TableID,
+						}
+					},
+				},
+			},
+			scpb.State_DELETE_ONLY: {
+				{
+					nextState: scpb.State_ABSENT,
+					op: func(this *scpb.Table) scop.Op {
+						return scop.CreateGcJobForTable{
+							TableID: this.TableID,
+						}
+					},
+				},
+			},
+		},
+	},
 }
@@ -60,6 +60,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/fuzzystrmatch"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
@@ -2203,6 +2204,48 @@ nearest replica.`, defaultFollowerReadDuration),
 		},
 	),
 
+	tree.WithMinTimestampFunctionName: makeBuiltin(
+		tree.FunctionProperties{},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"min_timestamp", types.TimestampTZ}},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn:         withMinTimestamp,
+			Info:       withMinTimestampInfo(false /* nearestOnly */),
+			Volatility: tree.VolatilityVolatile,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"min_timestamp", types.TimestampTZ},
+				{"nearest_only", types.Bool},
+			},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn:         withMinTimestamp,
+			Info:       withMinTimestampInfo(true /* nearestOnly */),
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	tree.WithMaxStalenessFunctionName: makeBuiltin(
+		tree.FunctionProperties{},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"max_staleness", types.Interval}},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn:         withMaxStaleness,
+			Info:       withMaxStalenessInfo(false /* nearestOnly */),
+			Volatility: tree.VolatilityVolatile,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"max_staleness", types.Interval},
+				{"nearest_only", types.Bool},
+			},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn:         withMaxStaleness,
+			Info:       withMaxStalenessInfo(true /* nearestOnly */),
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	"cluster_logical_timestamp": makeBuiltin(
 		tree.FunctionProperties{
 			Category: categorySystemInfo,
@@ -4675,6 +4718,98 @@ may increase either contention or retry errors, or both.`,
 		},
 	),
 
+	"crdb_internal.pin_plan": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"query", types.String}},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				fingerprint, err := ctx.Planner.PinPlan(ctx.Context, string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDString(fingerprint), nil
+			},
+			Info: "Captures the plan currently chosen by the optimizer for the given query and " +
+				"pins it for the query's fingerprint, which is returned. Later executions of " +
+				"statements with the same fingerprint use the pinned plan, regardless of changes " +
+				"to table statistics. Only the index used to read each table and the join " +
+				"algorithms are pinned.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	"crdb_internal.unpin_plan": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"fingerprint", types.String}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				found, err := ctx.Planner.UnpinPlan(ctx.Context, string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDBool(tree.DBool(found)), nil
+			},
+			Info: "Removes the plan pinned for the given statement fingerprint. Returns whether " +
+				"a plan was pinned.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	"crdb_internal.check_fingerprints": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"start_key", types.Bytes},
+				{"end_key", types.Bytes},
+				{"source", types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return startFingerprintCheck(ctx, args, hlc.Timestamp{})
+			},
+			Info: "Starts a job that compares the fingerprints of the live data in the " +
+				"specified key range with those of an external source of truth, and returns its " +
+				"ID. An empty start or end key is treated as the minimum and maximum possible, " +
+				"respectively. The source is either the URI of a backup collection, in which " +
+				"case the most recent backup in it is compared with the live data as of the " +
+				"backup's end time, or the postgres connection string of a peer cluster, in " +
+				"which case the data of both clusters is compared as of the current time. The " +
+				"backup and its incremental layers must have been taken with " +
+				"bulkio.backup.span_fingerprints.enabled set. " +
+				"The job fails and lists the diverging spans if any are found.",
+			Volatility: tree.VolatilityVolatile,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"start_key", types.Bytes},
+				{"end_key", types.Bytes},
+				{"source", types.String},
+				{"as_of", types.TimestampTZ},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				asOf := hlc.Timestamp{WallTime: tree.MustBeDTimestampTZ(args[3]).UnixNano()}
+				return startFingerprintCheck(ctx, args, asOf)
+			},
+			Info: "Starts a job that compares the fingerprints of the live data in the " +
+				"specified key range with those of a peer cluster as of the given time, and " +
+				"returns its ID. The source is the postgres connection string of the peer " +
+				"cluster.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	"num_nulls": makeBuiltin(
 		tree.FunctionProperties{
 			Category:     categoryComparison,
@@ -6877,6 +7012,49 @@ func followerReadTimestamp(ctx *tree.EvalContext, _ tree.Datums) (tree.Datum, er
 	return tree.MakeDTimestampTZ(ts, time.Microsecond)
 }
 
+const nearestOnlyInfo = `
+
+If nearest_only is set to true, reads that cannot be served by the nearest
+replica of the data without blocking or redirecting to the leaseholder will
+error instead.`
+
+func withMinTimestampInfo(nearestOnly bool) string {
+	var nearestOnlyText string
+	if nearestOnly {
+		nearestOnlyText = nearestOnlyInfo
+	}
+	return fmt.Sprintf(`When used in the AS OF SYSTEM TIME clause of a single-statement,
+read-only transaction, CockroachDB chooses the newest timestamp at or above
+min_timestamp at which the read can be served by the nearest replica of the data
+without blocking. If no such timestamp exists, the read is served at the
+statement time by the leaseholder.%s`, nearestOnlyText)
+}
+
+func withMaxStalenessInfo(nearestOnly bool) string {
+	var nearestOnlyText string
+	if nearestOnly {
+		nearestOnlyText = nearestOnlyInfo
+	}
+	return fmt.Sprintf(`When used in the AS OF SYSTEM TIME clause of a single-statement,
+read-only transaction, CockroachDB chooses the newest timestamp within the
+max_staleness interval of the statement time at which the read can be served by
+the nearest replica of the data without blocking. If no such timestamp exists,
+the read is served at the statement time by the leaseholder.%s`, nearestOnlyText)
+}
+
+func withMinTimestamp(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+	return args[0], nil
+}
+
+func withMaxStaleness(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+	interval := tree.MustBeDInterval(args[0])
+	if interval.Duration.Compare(duration.Duration{}) < 0 {
+		return nil, pgerror.New(pgcode.InvalidParameterValue,
+			"interval duration must be greater or equal to 0")
+	}
+	return tree.MakeDTimestampTZ(duration.Add(ctx.GetStmtTimestamp(), interval.Duration.Mul(-1)), time.Microsecond)
+}
+
 func jsonNumInvertedIndexEntries(_ *tree.EvalContext, val tree.Datum) (tree.Datum, error) {
 	if val == tree.DNull {
 		return tree.DZero, nil
@@ -6913,3 +7091,21 @@ func arrayNumInvertedIndexEntries(
 	}
 	return tree.NewDInt(tree.DInt(len(keys))), nil
 }
+
+// startFingerprintCheck implements the crdb_internal.check_fingerprints
+// builtin. asOf is empty unless it was specified by the user.
+func startFingerprintCheck(
+	ctx *tree.EvalContext, args tree.Datums, asOf hlc.Timestamp,
+) (tree.Datum, error) {
+	span, err := resolveFingerprintSpan(ctx,
+		roachpb.Key(tree.MustBeDBytes(args[0])), roachpb.Key(tree.MustBeDBytes(args[1])))
+	if err != nil {
+		return nil, err
+	}
+	jobID, err := ctx.Planner.StartFingerprintCheck(
+		ctx.Context, span, string(tree.MustBeDString(args[2])), asOf)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDInt(tree.DInt(jobID)), nil
+}
@@ -35,9 +35,53 @@ const FollowerReadTimestampFunctionName = "follower_read_timestamp"
 // "experimental_" function, which we keep for backwards compatibility.
 const FollowerReadTimestampExperimentalFunctionName = "experimental_follower_read_timestamp"
 
-var errInvalidExprForAsOf = errors.Errorf("AS OF SYSTEM TIME: only constant expressions or " +
+// WithMinTimestampFunctionName is the name of the function which can be used
+// with AOST clauses to perform a bounded staleness read with a minimum
+// timestamp bound.
+const WithMinTimestampFunctionName = "with_min_timestamp"
+
+// WithMaxStalenessFunctionName is the name of the function which can be used
+// with AOST clauses to perform a bounded staleness read with a maximum
+// staleness bound.
+const WithMaxStalenessFunctionName = "with_max_staleness"
+
+var errInvalidExprForAsOf = errors.Errorf("AS OF SYSTEM TIME: only constant expressions, " +
+	WithMinTimestampFunctionName + ", " + WithMaxStalenessFunctionName + ", or " +
 	FollowerReadTimestampFunctionName + " are allowed")
 
+var errBoundedStalenessNotAllowed = pgerror.Newf(pgcode.FeatureNotSupported,
+	"AS OF SYSTEM TIME: %s and %s are only allowed on single statement SELECT queries",
+	WithMinTimestampFunctionName, WithMaxStalenessFunctionName)
+
+// AsOfSystemTime represents the resolved AS OF SYSTEM TIME clause of a query.
+type AsOfSystemTime struct {
+	// Timestamp is the HLC timestamp at which the query is evaluated. For
+	// bounded staleness reads, it is the upper bound of the timestamps at which
+	// the query may be evaluated and it is used during planning, before the
+	// final timestamp is negotiated with KV.
+	Timestamp hlc.Timestamp
+	// BoundedStaleness is true if the AS OF SYSTEM TIME clause specifies a
+	// bounded staleness read using with_min_timestamp or with_max_staleness.
+	BoundedStaleness bool
+	// MinTimestampBound is the lower bound of the timestamps at which a
+	// bounded staleness query may be evaluated. Only set if BoundedStaleness
+	// is true.
+	MinTimestampBound hlc.Timestamp
+	// NearestOnly is true if a bounded staleness query must be served by the
+	// nearest replicas of the data it reads, without blocking on or being
+	// redirected to their leaseholders. Only set if BoundedStaleness is true.
+	NearestOnly bool
+}
+
+// EvalAsOfTimestampOption is an option for EvalAsOfTimestamp.
+type EvalAsOfTimestampOption int
+
+const (
+	// EvalAsOfTimestampOptionAllowBoundedStaleness signifies that the AS OF
+	// SYSTEM TIME clause may use with_min_timestamp and with_max_staleness.
+	EvalAsOfTimestampOptionAllowBoundedStaleness EvalAsOfTimestampOption = iota
+)
+
 // IsFollowerReadTimestampFunction determines whether the AS OF SYSTEM TIME
 // clause contains a simple invocation of the follower_read_timestamp function.
 func IsFollowerReadTimestampFunction(asOf AsOfClause, searchPath sessiondata.SearchPath) bool {
@@ -52,10 +96,40 @@ func IsFollowerReadTimestampFunction(asOf AsOfClause, searchPath sessiondata.Sea
 	return def.Name == FollowerReadTimestampFunctionName || def.Name == FollowerReadTimestampExperimentalFunctionName
 }
 
+// resolveBoundedStalenessFunction returns the name of the bounded staleness
+// function invoked by the AS OF SYSTEM TIME clause, or the empty string if the
+// clause is not a simple invocation of one.
+func resolveBoundedStalenessFunction(asOf AsOfClause, searchPath sessiondata.SearchPath) string {
+	fe, ok := asOf.Expr.(*FuncExpr)
+	if !ok {
+		return ""
+	}
+	def, err := fe.Func.Resolve(searchPath)
+	if err != nil {
+		return ""
+	}
+	switch def.Name {
+	case WithMinTimestampFunctionName, WithMaxStalenessFunctionName:
+		return def.Name
+	}
+	return ""
+}
+
 // EvalAsOfTimestamp evaluates the timestamp argument to an AS OF SYSTEM TIME query.
 func EvalAsOfTimestamp(
-	ctx context.Context, asOf AsOfClause, semaCtx *SemaContext, evalCtx *EvalContext,
-) (tsss hlc.Timestamp, err error) {
+	ctx context.Context,
+	asOf AsOfClause,
+	semaCtx *SemaContext,
+	evalCtx *EvalContext,
+	opts ...EvalAsOfTimestampOption,
+) (AsOfSystemTime, error) {
+	allowBoundedStaleness := false
+	for _, o := range opts {
+		if o == EvalAsOfTimestampOptionAllowBoundedStaleness {
+			allowBoundedStaleness = true
+		}
+	}
+
 	// We need to save and restore the previous value of the field in
 	// semaCtx in case we are recursively called within a subquery
 	// context.
@@ -64,39 +138,71 @@ func EvalAsOfTimestamp(
 	scalarProps.Require("AS OF SYSTEM TIME", RejectSpecial|RejectSubqueries)
 
 	// In order to support the follower reads feature we permit this expression
-	// to be a simple invocation of the follower_read_timestamp function.
-	// Over time we could expand the set of allowed functions or expressions.
+	// to be a simple invocation of the follower_read_timestamp function. In
+	// order to support bounded staleness reads, we also permit simple
+	// invocations of the with_min_timestamp and with_max_staleness functions.
 	// All non-function expressions must be const and must TypeCheck into a
 	// string.
 	var te TypedExpr
+	boundedStalenessFn := ""
 	if _, ok := asOf.Expr.(*FuncExpr); ok {
-		if !IsFollowerReadTimestampFunction(asOf, semaCtx.SearchPath) {
-			return hlc.Timestamp{}, errInvalidExprForAsOf
+		boundedStalenessFn = resolveBoundedStalenessFunction(asOf, semaCtx.SearchPath)
+		if boundedStalenessFn != "" {
+			if !allowBoundedStaleness {
+				return AsOfSystemTime{}, errBoundedStalenessNotAllowed
+			}
+		} else if !IsFollowerReadTimestampFunction(asOf, semaCtx.SearchPath) {
+			return AsOfSystemTime{}, errInvalidExprForAsOf
 		}
 		var err error
 		te, err = asOf.Expr.TypeCheck(ctx, semaCtx, types.TimestampTZ)
 		if err != nil {
-			return hlc.Timestamp{}, err
+			return AsOfSystemTime{}, err
 		}
 	} else {
 		var err error
 		te, err = asOf.Expr.TypeCheck(ctx, semaCtx, types.String)
 		if err != nil {
-			return hlc.Timestamp{}, err
+			return AsOfSystemTime{}, err
 		}
 		if !IsConst(evalCtx, te) {
-			return hlc.Timestamp{}, errInvalidExprForAsOf
+			return AsOfSystemTime{}, errInvalidExprForAsOf
 		}
 	}
 
 	d, err := te.Eval(evalCtx)
 	if err != nil {
-		return hlc.Timestamp{}, err
+		return AsOfSystemTime{}, err
 	}
 
 	stmtTimestamp := evalCtx.GetStmtTimestamp()
 	ts, err := DatumToHLC(evalCtx, stmtTimestamp, d)
-	return ts, errors.Wrap(err, "AS OF SYSTEM TIME")
+	if err != nil {
+		return AsOfSystemTime{}, errors.Wrap(err, "AS OF SYSTEM TIME")
+	}
+	if boundedStalenessFn == "" {
+		return AsOfSystemTime{Timestamp: ts}, nil
+	}
+
+	// For bounded staleness reads, the evaluated timestamp is the minimum
+	// timestamp bound and the statement timestamp is the maximum one.
+	ret := AsOfSystemTime{
+		Timestamp:         hlc.Timestamp{WallTime: stmtTimestamp.UnixNano()},
+		BoundedStaleness:  true,
+		MinTimestampBound: ts,
+	}
+	if ret.Timestamp.Less(ret.MinTimestampBound) {
+		return AsOfSystemTime{}, pgerror.Newf(pgcode.InvalidParameterValue,
+			"AS OF SYSTEM TIME: minimum timestamp bound %s is in the future", ts)
+	}
+	if fe := te.(*FuncExpr); len(fe.Exprs) > 1 {
+		nearestOnly, err := fe.Exprs[1].(TypedExpr).Eval(evalCtx)
+		if err != nil {
+			return AsOfSystemTime{}, err
+		}
+		ret.NearestOnly = bool(MustBeDBool(nearestOnly))
+	}
+	return ret, nil
 }
 
 // DatumToHLC performs the conversion from a Datum to an HLC timestamp.
@@ -1365,6 +1365,28 @@ func (node *CreateTable) FormatBody(ctx *FmtCtx) {
 	}
 }
 
+// CreateForeignTable represents a CREATE FOREIGN TABLE statement.
+type CreateForeignTable struct {
+	IfNotExists bool
+	Table       TableName
+	Defs        TableDefs
+	Options     KVOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateForeignTable) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE FOREIGN TABLE ")
+	if node.IfNotExists {
+		ctx.WriteString("IF NOT EXISTS ")
+	}
+	ctx.FormatNode(&node.Table)
+	ctx.WriteString(" (")
+	ctx.FormatNode(&node.Defs)
+	ctx.WriteString(") OPTIONS (")
+	ctx.FormatNode(&node.Options)
+	ctx.WriteByte(')')
+}
+
 // HoistConstraints finds column check and foreign key constraints defined
 // inline with their columns and makes them table-level constraints, stored in
 // n.Defs. For example, the foreign key constraint in
@@ -1937,3 +1959,22 @@ func (node *CreateExtension) Format(ctx *FmtCtx) {
 	}
 	ctx.WriteString(node.Name)
 }
+
+// CreatePublication represents a CREATE PUBLICATION statement.
+type CreatePublication struct {
+	Name      Name
+	AllTables bool
+	Tables    TableNames
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreatePublication) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE PUBLICATION ")
+	ctx.FormatNode(&node.Name)
+	if node.AllTables {
+		ctx.WriteString(" FOR ALL TABLES")
+	} else if len(node.Tables) > 0 {
+		ctx.WriteString(" FOR TABLE ")
+		ctx.FormatNode(&node.Tables)
+	}
+}
@@ -3081,6 +3081,23 @@ type EvalPlanner interface {
 		ctx context.Context,
 		member security.SQLUsername,
 	) (map[security.SQLUsername]bool, error)
+
+	// PinPlan captures the plan currently chosen for the given statement and
+	// pins it for the statement's fingerprint, which it returns. See the
+	// comment on the planner implementation in plan_hints.go.
+	PinPlan(ctx context.Context, sql string) (string, error)
+
+	// UnpinPlan removes the plan pinned for the given statement fingerprint,
+	// and returns whether there was one.
+	UnpinPlan(ctx context.Context, fingerprint string) (bool, error)
+
+	// StartFingerprintCheck starts a job that compares the fingerprints of the
+	// live data in the given span with those of a backup or of a peer cluster,
+	// and returns its ID. See the comment on the planner implementation in
+	// fingerprint_check.go.
+	StartFingerprintCheck(
+		ctx context.Context, span roachpb.Span, source string, asOf hlc.Timestamp,
+	) (int64, error)
 }
 
 // EvalSessionAccessor is a limited interface to access session variables.
@@ -0,0 +1,107 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import "fmt"
+
+// The statements in this file are the commands of the Postgres streaming
+// replication protocol. They are not part of the SQL grammar and are only
+// accepted on connections opened with the replication=database startup
+// parameter; see the pgrepl package for their parser.
+
+// IdentifySystem represents an IDENTIFY_SYSTEM replication command.
+type IdentifySystem struct{}
+
+var _ Statement = &IdentifySystem{}
+
+// Format implements the NodeFormatter interface.
+func (node *IdentifySystem) Format(ctx *FmtCtx) {
+	ctx.WriteString("IDENTIFY_SYSTEM")
+}
+
+// CreateReplicationSlot represents a CREATE_REPLICATION_SLOT replication
+// command.
+type CreateReplicationSlot struct {
+	Name      Name
+	Temporary bool
+	Plugin    string
+	// Snapshot is the snapshot action requested by the client, for example
+	// NOEXPORT_SNAPSHOT. It is empty if none was specified.
+	Snapshot string
+}
+
+var _ Statement = &CreateReplicationSlot{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateReplicationSlot) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE_REPLICATION_SLOT ")
+	ctx.FormatNode(&node.Name)
+	if node.Temporary {
+		ctx.WriteString(" TEMPORARY")
+	}
+	ctx.WriteString(" LOGICAL ")
+	ctx.WriteString(node.Plugin)
+	if node.Snapshot != "" {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.Snapshot)
+	}
+}
+
+// DropReplicationSlot represents a DROP_REPLICATION_SLOT replication command.
+type DropReplicationSlot struct {
+	Name Name
+	Wait bool
+}
+
+var _ Statement = &DropReplicationSlot{}
+
+// Format implements the NodeFormatter interface.
+func (node *DropReplicationSlot) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP_REPLICATION_SLOT ")
+	ctx.FormatNode(&node.Name)
+	if node.Wait {
+		ctx.WriteString(" WAIT")
+	}
+}
+
+// StartReplication represents a START_REPLICATION replication command for a
+// logical replication slot.
+type StartReplication struct {
+	Slot Name
+	// LSN is the position from which the client wants to receive changes.
+	LSN uint64
+	// Options are the options passed to the output plugin. Their values are
+	// always string literals.
+	Options KVOptions
+}
+
+var _ Statement = &StartReplication{}
+
+// Format implements the NodeFormatter interface.
+func (node *StartReplication) Format(ctx *FmtCtx) {
+	ctx.WriteString("START_REPLICATION SLOT ")
+	ctx.FormatNode(&node.Slot)
+	ctx.WriteString(fmt.Sprintf(" LOGICAL %X/%X", uint32(node.LSN>>32), uint32(node.LSN)))
+	if len(node.Options) > 0 {
+		ctx.WriteString(" (")
+		for i := range node.Options {
+			if i > 0 {
+				ctx.WriteString(", ")
+			}
+			ctx.FormatNode(&node.Options[i].Key)
+			if node.Options[i].Value != nil {
+				ctx.WriteByte(' ')
+				ctx.FormatNode(node.Options[i].Value)
+			}
+		}
+		ctx.WriteByte(')')
+	}
+}
@@ -621,6 +621,14 @@ func (node *ShowHistogram) Format(ctx *FmtCtx) {
 	ctx.Printf("SHOW HISTOGRAM %d", node.HistogramID)
 }
 
+// ShowHotKeys represents a SHOW HOT KEYS statement.
+type ShowHotKeys struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowHotKeys) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW HOT KEYS")
+}
+
 // ShowPartitions represents a SHOW PARTITIONS statement.
 type ShowPartitions struct {
 	IsDB     bool
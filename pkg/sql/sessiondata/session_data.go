@@ -116,6 +116,9 @@ type LocalOnlySessionData struct {
 	SaveTablesPrefix string
 	// RemoteAddr is used to generate logging events.
 	RemoteAddr net.Addr
+	// TraceParent is the W3C traceparent of the client span under which
+	// the spans of new transactions are created, if not empty.
+	TraceParent string
 	// VectorizeRowCountThreshold indicates the row count above which the
 	// vectorized execution engine will be used if possible.
 	VectorizeRowCountThreshold uint64
@@ -158,6 +161,11 @@ type LocalOnlySessionData struct {
 	// NOTE: we'd prefer to use tree.UserPriority here, but doing so would
 	// introduce a package dependency cycle.
 	DefaultTxnPriority int
+	// DefaultTxnIsolationLevel indicates the default isolation level of newly
+	// created transactions.
+	// NOTE: we'd prefer to use tree.IsolationLevel here, but doing so would
+	// introduce a package dependency cycle.
+	DefaultTxnIsolationLevel int
 	// DefaultTxnReadOnly indicates the default read-only status of newly
 	// created transactions.
 	DefaultTxnReadOnly bool
@@ -212,6 +220,10 @@ type LocalOnlySessionData struct {
 	// ImplicitSelectForUpdate is true if FOR UPDATE locking may be used during
 	// the row-fetch phase of mutation statements.
 	ImplicitSelectForUpdate bool
+	// ReplicatedLocking is true if the row-level locks acquired by SELECT FOR
+	// UPDATE and FOR SHARE are replicated, so that they survive lease transfers
+	// and node restarts.
+	ReplicatedLocking bool
 	// InsertFastPath is true if the fast path for insert (with VALUES input) may
 	// be used.
 	InsertFastPath bool
@@ -24,11 +24,12 @@ func (p *planner) SetTransaction(ctx context.Context, n *tree.SetTransaction) (p
 	var asOfTs hlc.Timestamp
 	if n.Modes.AsOf.Expr != nil {
 		var err error
-		asOfTs, err = p.EvalAsOfTimestamp(ctx, n.Modes.AsOf)
+		asOf, err := p.EvalAsOfTimestamp(ctx, n.Modes.AsOf)
 		if err != nil {
 			return nil, err
 		}
-		p.semaCtx.AsOfTimestamp = &asOfTs
+		asOfTs = asOf.Timestamp
+		p.semaCtx.AsOfSystemTime = &asOf
 	}
 	if n.Modes.Deferrable == tree.Deferrable {
 		return nil, unimplemented.NewWithIssue(53432, "DEFERRABLE transactions")
@@ -144,7 +144,7 @@ func (n *showFingerprintsNode) Next(params runParams) (bool, error) {
 	// If were'in in an AOST context, propagate it to the inner statement so that
 	// the inner statement gets planned with planner.avoidCachedDescriptors set,
 	// like the outter one.
-	if params.p.semaCtx.AsOfTimestamp != nil {
+	if params.p.semaCtx.AsOfSystemTime != nil {
 		ts := params.p.txn.ReadTimestamp()
 		sql = sql + " AS OF SYSTEM TIME " + ts.AsOfSystemTime()
 	}
@@ -0,0 +1,169 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/google/pprof/profile"
+)
+
+// The profiler labels set on the goroutines executing a statement while a CPU
+// profile with labels is being collected, which identify the statistics the
+// CPU time sampled on these goroutines is attributed to.
+const (
+	appNameProfilerLabel = "appname"
+	stmtProfilerLabel    = "stmt.anonymized"
+)
+
+// The CPU time of statements is a sampled estimate. It is derived from the
+// profiles periodically collected by the continuous profiler, when it is
+// enabled, so only the executions recorded while such a profile is being
+// collected get a CPU time, and it is the CPU time sampled for their
+// fingerprint divided evenly among them rather than their own. With the
+// default settings, a profile covers a thirtieth of the time, so the mean CPU
+// time of the sampled executions is scaled up to all of them to estimate the
+// CPU time of a fingerprint, and the fraction of executions sampled is
+// reported along with it (see roachpb.StatementStatistics.CPUTimeCoverage).
+// CPU profiles with labels collected by other means, such as through the
+// debug pages, are not attributed to statements.
+
+// CPUProfileStarted is called by the continuous profiler when it starts
+// collecting a CPU profile with labels. The statement executions recorded
+// from then on share the CPU time sampled for their fingerprint.
+func (s *Server) CPUProfileStarted(ctx context.Context) {
+	s.sqlStats.resetCPUProfiledCounts()
+	s.cpuProfiling.Set(true)
+}
+
+// CPUProfileFinished is called by the continuous profiler when it is done
+// collecting the CPU profile it announced with CPUProfileStarted, with the
+// profile, or nil if it failed to collect it. The CPU time sampled for each
+// statement fingerprint is attributed to the executions of the statement
+// recorded since the profile started.
+func (s *Server) CPUProfileFinished(ctx context.Context, p *profile.Profile) {
+	s.cpuProfiling.Set(false)
+	if p == nil {
+		return
+	}
+	s.sqlStats.attributeCPUProfile(stmtCPUTimes(p))
+}
+
+// stmtCPUTimes returns the CPU time sampled in the given profile for each
+// statement fingerprint, by application name.
+func stmtCPUTimes(p *profile.Profile) map[string]map[string]time.Duration {
+	valueIdx := -1
+	for i, st := range p.SampleType {
+		if st.Type == "cpu" && st.Unit == "nanoseconds" {
+			valueIdx = i
+		}
+	}
+	if valueIdx < 0 {
+		return nil
+	}
+	res := make(map[string]map[string]time.Duration)
+	for _, sample := range p.Sample {
+		apps, stmts := sample.Label[appNameProfilerLabel], sample.Label[stmtProfilerLabel]
+		if len(apps) != 1 || len(stmts) != 1 {
+			continue
+		}
+		if res[apps[0]] == nil {
+			res[apps[0]] = make(map[string]time.Duration)
+		}
+		res[apps[0]][stmts[0]] += time.Duration(sample.Value[valueIdx])
+	}
+	return res
+}
+
+// resetCPUProfiledCounts resets the count of executions of each statement
+// recorded since the start of the current CPU profile.
+func (s *sqlStats) resetCPUProfiledCounts() {
+	s.Lock()
+	apps := make([]*appStats, 0, len(s.apps))
+	for _, a := range s.apps {
+		apps = append(apps, a)
+	}
+	s.Unlock()
+	for _, a := range apps {
+		a.Lock()
+		for _, stats := range a.stmts {
+			stats.mu.Lock()
+			stats.mu.cpuProfiledCount = 0
+			stats.mu.Unlock()
+		}
+		a.Unlock()
+	}
+}
+
+// attributeCPUProfile attributes the CPU time sampled for each statement
+// fingerprint, by application name, to the statistics of the statement.
+func (s *sqlStats) attributeCPUProfile(cpuTimes map[string]map[string]time.Duration) {
+	for appName, appCPUTimes := range cpuTimes {
+		s.Lock()
+		a, ok := s.apps[appName]
+		s.Unlock()
+		if ok {
+			a.attributeCPUProfile(appCPUTimes)
+		}
+	}
+}
+
+// attributeCPUProfile attributes the CPU time sampled for each statement
+// fingerprint to the statistics of the statement. As a fingerprint can be
+// executed with different outcomes, and thus have several statistics, the
+// CPU time is divided evenly among all the executions of the fingerprint
+// recorded since the profile started.
+func (a *appStats) attributeCPUProfile(cpuTimes map[string]time.Duration) {
+	byFingerprint := make(map[string][]*stmtStats)
+	a.Lock()
+	for key, stats := range a.stmts {
+		if _, ok := cpuTimes[key.anonymizedStmt]; ok {
+			byFingerprint[key.anonymizedStmt] = append(byFingerprint[key.anonymizedStmt], stats)
+		}
+	}
+	a.Unlock()
+
+	for fingerprint, allStats := range byFingerprint {
+		counts := make([]int64, len(allStats))
+		var total int64
+		for i, stats := range allStats {
+			stats.mu.Lock()
+			counts[i] = stats.mu.cpuProfiledCount
+			stats.mu.Unlock()
+			total += counts[i]
+		}
+		if total == 0 {
+			continue
+		}
+		perExecution := cpuTimes[fingerprint].Seconds() / float64(total)
+		for i, stats := range allStats {
+			if counts[i] == 0 {
+				continue
+			}
+			stats.mu.Lock()
+			stats.mu.data.CPUTime.Add(
+				roachpb.NumericStat{Mean: perExecution}, stats.mu.data.CPUTimeSampleCount, counts[i],
+			)
+			stats.mu.data.CPUTimeSampleCount += counts[i]
+			// The CPU time of the executions which have not been persisted yet
+			// is persisted with those of the latest aggregation interval.
+			if n := len(stats.mu.unflushed); n > 0 {
+				u := &stats.mu.unflushed[n-1].data
+				u.CPUTime.Add(roachpb.NumericStat{Mean: perExecution}, u.CPUTimeSampleCount, counts[i])
+				u.CPUTimeSampleCount += counts[i]
+			}
+			stats.mu.cpuProfiledCount -= counts[i]
+			stats.mu.Unlock()
+		}
+	}
+}
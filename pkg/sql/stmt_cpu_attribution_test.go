@@ -0,0 +1,127 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStmtCPUAndMemoryAttribution(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(ctx)
+	sqlServer := s.SQLServer().(*sql.Server)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO t SELECT i, -i FROM generate_series(1, 1000) AS g(i)`)
+
+	// Run the statements under test with a custom application name.
+	pgURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(security.RootUser),
+		Host:     s.ServingSQLAddr(),
+		RawQuery: "sslmode=disable&application_name=attribution",
+	}
+	rawAppDB, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer rawAppDB.Close()
+	appDB := sqlutils.MakeSQLRunner(rawAppDB)
+
+	// Executions during CPU profiles with labels which are not collected by
+	// the continuous profiler, such as those requested through the debug
+	// pages, are not attributed any CPU time.
+	const fingerprint = `SELECT k FROM t ORDER BY v`
+	require.NoError(t, s.ClusterSettings().SetCPUProfiling(cluster.CPUProfileWithLabels))
+	appDB.Exec(t, fingerprint)
+	require.NoError(t, s.ClusterSettings().SetCPUProfiling(cluster.CPUProfileNone))
+
+	// Simulate the collection of a CPU profile with labels by the continuous
+	// profiler, during which the statement is executed four times.
+	require.NoError(t, s.ClusterSettings().SetCPUProfiling(cluster.CPUProfileWithLabels))
+	sqlServer.CPUProfileStarted(ctx)
+	for i := 0; i < 4; i++ {
+		appDB.Exec(t, fingerprint)
+	}
+	require.NoError(t, s.ClusterSettings().SetCPUProfiling(cluster.CPUProfileNone))
+	sqlServer.CPUProfileFinished(ctx, &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		Sample: []*profile.Sample{
+			{
+				Value: []int64{20, int64(200 * time.Millisecond)},
+				Label: map[string][]string{"appname": {"attribution"}, "stmt.anonymized": {fingerprint}},
+			},
+			{
+				Value: []int64{10, int64(100 * time.Millisecond)},
+				Label: map[string][]string{"appname": {"attribution"}, "stmt.anonymized": {fingerprint}},
+			},
+			{
+				// Samples for other applications are not attributed to the
+				// statement.
+				Value: []int64{10, int64(100 * time.Millisecond)},
+				Label: map[string][]string{"appname": {"other"}, "stmt.anonymized": {fingerprint}},
+			},
+		},
+	})
+
+	// Executions after the profile are recorded without CPU time.
+	appDB.Exec(t, fingerprint)
+
+	var count, cpuSampleCount int
+	var cpuTimeAvg, cpuTimeCoverage, cpuTimeTotal, peakMemUsageAvg float64
+	sqlDB.QueryRow(t, `
+SELECT count, cpu_time_avg, cpu_time_sample_count, cpu_time_coverage, cpu_time_total,
+       peak_mem_usage_avg
+  FROM crdb_internal.node_statement_statistics
+ WHERE application_name = 'attribution' AND key = $1`, fingerprint,
+	).Scan(&count, &cpuTimeAvg, &cpuSampleCount, &cpuTimeCoverage, &cpuTimeTotal, &peakMemUsageAvg)
+	require.Equal(t, 6, count)
+	require.Equal(t, 4, cpuSampleCount)
+	require.InDelta(t, 0.075, cpuTimeAvg, 1e-9)
+	// The CPU time of the sampled executions is scaled up to all of them.
+	require.InDelta(t, 4.0/6, cpuTimeCoverage, 1e-9)
+	require.InDelta(t, 0.45, cpuTimeTotal, 1e-9)
+	// The sort buffers the rows of the table in memory.
+	require.Greater(t, peakMemUsageAvg, float64(0))
+
+	// The statistics served by the Statements RPC include them as well.
+	found := false
+	for _, stmt := range sqlServer.GetUnscrubbedStmtStats() {
+		if stmt.Key.App == "attribution" && stmt.Key.Query == fingerprint {
+			found = true
+			require.Equal(t, int64(4), stmt.Stats.CPUTimeSampleCount)
+			require.InDelta(t, 0.075, stmt.Stats.CPUTime.Mean, 1e-9)
+			require.Greater(t, stmt.Stats.PeakMemUsage.Mean, float64(0))
+		}
+	}
+	require.True(t, found, "statistics for %q not found", fingerprint)
+}
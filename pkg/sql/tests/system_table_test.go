@@ -191,6 +191,14 @@ func TestSystemTableLiterals(t *testing.T) {
 		{keys.StatementDiagnosticsTableID, systemschema.StatementDiagnosticsTableSchema, systemschema.StatementDiagnosticsTable},
 		{keys.ScheduledJobsTableID, systemschema.ScheduledJobsTableSchema, systemschema.ScheduledJobsTable},
 		{keys.SqllivenessID, systemschema.SqllivenessTableSchema, systemschema.SqllivenessTable},
+		{keys.PublicationsTableID, systemschema.PublicationsTableSchema, systemschema.PublicationsTable},
+		{keys.ReplicationSlotsTableID, systemschema.ReplicationSlotsTableSchema, systemschema.ReplicationSlotsTable},
+		{keys.StatementStatisticsTableID, systemschema.StatementStatisticsTableSchema, systemschema.StatementStatisticsTable},
+		{keys.TransactionStatisticsTableID, systemschema.TransactionStatisticsTableSchema, systemschema.TransactionStatisticsTable},
+		{keys.StatementHintsTableID, systemschema.StatementHintsTableSchema, systemschema.StatementHintsTable},
+		{keys.TenantUsageTableID, systemschema.TenantUsageTableSchema, systemschema.TenantUsageTable},
+		{keys.AlertRulesTableID, systemschema.AlertRulesTableSchema, systemschema.AlertRulesTable},
+		{keys.TransactionContentionEventsTableID, systemschema.TransactionContentionEventsTableSchema, systemschema.TransactionContentionEventsTable},
 	} {
 		privs := *test.pkg.GetPrivileges()
 		gen, err := sql.CreateTestTableDescriptor(
@@ -17,7 +17,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
@@ -132,6 +135,7 @@ const (
 //   and should be fixed to this timestamp.
 // priority: The transaction's priority. Pass roachpb.UnspecifiedUserPriority if the txn arg is
 //   not nil.
+// isoLevel: The transaction's isolation level.
 // readOnly: The read-only character of the new txn.
 // txn: If not nil, this txn will be used instead of creating a new txn. If so,
 //   all the other arguments need to correspond to the attributes of this txn
@@ -143,8 +147,10 @@ func (ts *txnState) resetForNewSQLTxn(
 	sqlTimestamp time.Time,
 	historicalTimestamp *hlc.Timestamp,
 	priority roachpb.UserPriority,
+	isoLevel enginepb.IsolationLevel,
 	readOnly tree.ReadWriteMode,
 	txn *kv.Txn,
+	remoteParent *tracing.SpanMeta,
 	tranCtx transitionCtx,
 ) {
 	// Reset state vars to defaults.
@@ -157,7 +163,13 @@ func (ts *txnState) resetForNewSQLTxn(
 	// TODO(andrei): figure out how to close these spans on server shutdown? Ties
 	// into a larger discussion about how to drain SQL and rollback open txns.
 	opName := sqlTxnName
-	txnCtx, sp := createRootOrChildSpan(connCtx, opName, tranCtx.tracer, tracing.WithBypassRegistry())
+	spanOpts := []tracing.SpanOption{tracing.WithBypassRegistry()}
+	if remoteParent != nil && tracing.SpanFromContext(connCtx) == nil {
+		// The span is a child of the client span, if the client provided its
+		// trace context and no session span overrides it.
+		spanOpts = append(spanOpts, tracing.WithParentAndManualCollection(remoteParent))
+	}
+	txnCtx, sp := createRootOrChildSpan(connCtx, opName, tranCtx.tracer, spanOpts...)
 	if txnType == implicitTxn {
 		sp.SetTag("implicit", "true")
 	}
@@ -182,6 +194,9 @@ func (ts *txnState) resetForNewSQLTxn(
 		if err := ts.setPriorityLocked(priority); err != nil {
 			panic(err)
 		}
+		if err := ts.mu.txn.SetIsolationLevel(isoLevel); err != nil {
+			panic(err)
+		}
 	} else {
 		if priority != roachpb.UnspecifiedUserPriority {
 			panic(errors.AssertionFailedf("unexpected priority when using an existing txn: %s", priority))
@@ -277,6 +292,19 @@ func (ts *txnState) setPriorityLocked(userPriority roachpb.UserPriority) error {
 	return nil
 }
 
+func (ts *txnState) setIsolationLevel(isoLevel enginepb.IsolationLevel) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.mu.txn.IsolationLevel() == isoLevel {
+		return nil
+	}
+	if ts.mu.txn.Active() {
+		return pgerror.New(pgcode.ActiveSQLTransaction,
+			"SET TRANSACTION ISOLATION LEVEL must be called before any query")
+	}
+	return ts.mu.txn.SetIsolationLevel(isoLevel)
+}
+
 func (ts *txnState) setReadOnlyMode(mode tree.ReadWriteMode) error {
 	switch mode {
 	case tree.UnspecifiedReadWriteMode:
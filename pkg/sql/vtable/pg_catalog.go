@@ -595,6 +595,42 @@ CREATE TABLE pg_catalog.pg_proc (
 	proacl STRING[]
 )`
 
+// PGCatalogPublication describes the schema of the pg_catalog.pg_publication
+// table.
+// https://www.postgresql.org/docs/13/catalog-pg-publication.html,
+const PGCatalogPublication = `
+CREATE TABLE pg_catalog.pg_publication (
+	oid OID,
+	pubname NAME,
+	pubowner OID,
+	puballtables BOOL,
+	pubinsert BOOL,
+	pubupdate BOOL,
+	pubdelete BOOL,
+	pubtruncate BOOL,
+	pubviaroot BOOL
+)`
+
+// PGCatalogPublicationRel describes the schema of the
+// pg_catalog.pg_publication_rel table.
+// https://www.postgresql.org/docs/13/catalog-pg-publication-rel.html,
+const PGCatalogPublicationRel = `
+CREATE TABLE pg_catalog.pg_publication_rel (
+	oid OID,
+	prpubid OID,
+	prrelid OID
+)`
+
+// PGCatalogPublicationTables describes the schema of the
+// pg_catalog.pg_publication_tables view.
+// https://www.postgresql.org/docs/13/view-pg-publication-tables.html,
+const PGCatalogPublicationTables = `
+CREATE TABLE pg_catalog.pg_publication_tables (
+	pubname NAME,
+	schemaname NAME,
+	tablename NAME
+)`
+
 // PGCatalogRange describes the schema of the pg_catalog.pg_range table.
 // https://www.postgresql.org/docs/9.5/catalog-pg-range.html,
 const PGCatalogRange = `
@@ -607,6 +643,27 @@ CREATE TABLE pg_catalog.pg_range (
 	rngsubdiff OID
 )`
 
+// PGCatalogReplicationSlots describes the schema of the
+// pg_catalog.pg_replication_slots view.
+// https://www.postgresql.org/docs/13/view-pg-replication-slots.html,
+const PGCatalogReplicationSlots = `
+CREATE TABLE pg_catalog.pg_replication_slots (
+	slot_name NAME,
+	plugin NAME,
+	slot_type STRING,
+	datoid OID,
+	database NAME,
+	temporary BOOL,
+	active BOOL,
+	active_pid INT4,
+	xmin INT8,
+	catalog_xmin INT8,
+	restart_lsn STRING,
+	confirmed_flush_lsn STRING,
+	wal_status STRING,
+	safe_wal_size INT8
+)`
+
 // PGCatalogRewrite describes the schema of the pg_catalog.pg_rewrite table.
 // https://www.postgresql.org/docs/9.5/catalog-pg-rewrite.html,
 const PGCatalogRewrite = `
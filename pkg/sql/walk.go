@@ -359,7 +359,9 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&controlSchedulesNode{}):           "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):             "create database",
 	reflect.TypeOf(&createExtensionNode{}):            "create extension",
+	reflect.TypeOf(&createForeignTableNode{}):         "create foreign table",
 	reflect.TypeOf(&createIndexNode{}):                "create index",
+	reflect.TypeOf(&createPublicationNode{}):          "create publication",
 	reflect.TypeOf(&createSequenceNode{}):             "create sequence",
 	reflect.TypeOf(&createSchemaNode{}):               "create schema",
 	reflect.TypeOf(&createStatsNode{}):                "create statistics",
@@ -373,6 +375,8 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&distinctNode{}):                   "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):               "drop database",
 	reflect.TypeOf(&dropIndexNode{}):                  "drop index",
+	reflect.TypeOf(&dropPublicationNode{}):            "drop publication",
+	reflect.TypeOf(&dropReplicationSlotNode{}):        "drop replication slot",
 	reflect.TypeOf(&dropSequenceNode{}):               "drop sequence",
 	reflect.TypeOf(&dropSchemaNode{}):                 "drop schema",
 	reflect.TypeOf(&dropTableNode{}):                  "drop table",
@@ -330,6 +330,51 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		// Introduced in v20.2.
 		name: "mark non-terminal schema change jobs with a pre-20.1 format version as failed",
 	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.publications and system.replication_slots tables",
+		workFn:              createPublicationsAndReplicationSlotsTables,
+		includedInBootstrap: clusterversion.ByKey(clusterversion.PublicationsAndReplicationSlots),
+		newDescriptorIDs:    staticIDs(keys.PublicationsTableID, keys.ReplicationSlotsTableID),
+	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.statement_statistics and system.transaction_statistics tables",
+		workFn:              createSQLStatsTables,
+		includedInBootstrap: clusterversion.ByKey(clusterversion.SQLStatsTables),
+		newDescriptorIDs:    staticIDs(keys.StatementStatisticsTableID, keys.TransactionStatisticsTableID),
+	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.statement_hints table",
+		workFn:              createStatementHintsTable,
+		includedInBootstrap: clusterversion.ByKey(clusterversion.StatementHintsTable),
+		newDescriptorIDs:    staticIDs(keys.StatementHintsTableID),
+	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.tenant_usage table",
+		workFn:              createTenantUsageTable,
+		includedInBootstrap: clusterversion.ByKey(clusterversion.TenantUsageTable),
+		newDescriptorIDs:    staticIDs(keys.TenantUsageTableID),
+		clusterWide:         true,
+	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.alert_rules table",
+		workFn:              createAlertRulesTable,
+		includedInBootstrap: clusterversion.ByKey(clusterversion.AlertRulesTable),
+		newDescriptorIDs:    staticIDs(keys.AlertRulesTableID),
+		clusterWide:         true,
+	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.transaction_contention_events table",
+		workFn:              createTransactionContentionEventsTable,
+		includedInBootstrap: clusterversion.ByKey(clusterversion.TransactionContentionEventsTable),
+		newDescriptorIDs:    staticIDs(keys.TransactionContentionEventsTableID),
+		clusterWide:         true,
+	},
 }
 
 func staticIDs(
@@ -1266,6 +1311,36 @@ func createTenantsTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, systemschema.TenantsTable)
 }
 
+func createPublicationsAndReplicationSlotsTables(ctx context.Context, r runner) error {
+	if err := createSystemTable(ctx, r, systemschema.PublicationsTable); err != nil {
+		return err
+	}
+	return createSystemTable(ctx, r, systemschema.ReplicationSlotsTable)
+}
+
+func createSQLStatsTables(ctx context.Context, r runner) error {
+	if err := createSystemTable(ctx, r, systemschema.StatementStatisticsTable); err != nil {
+		return err
+	}
+	return createSystemTable(ctx, r, systemschema.TransactionStatisticsTable)
+}
+
+func createStatementHintsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, systemschema.StatementHintsTable)
+}
+
+func createTenantUsageTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, systemschema.TenantUsageTable)
+}
+
+func createAlertRulesTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, systemschema.AlertRulesTable)
+}
+
+func createTransactionContentionEventsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, systemschema.TransactionContentionEventsTable)
+}
+
 func alterSystemScheduledJobsFixTableSchema(ctx context.Context, r runner) error {
 	setOwner := "UPDATE system.scheduled_jobs SET owner='root' WHERE owner IS NULL"
 	asNode := sessiondata.InternalExecutorOverride{User: security.NodeUserName()}
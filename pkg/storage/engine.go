@@ -389,6 +389,16 @@ type Reader interface {
 	// that the different iterators constructed by this Reader will see the
 	// same underlying Engine state.
 	ConsistentIterators() bool
+	// MayHaveRangeTombstones returns false if the Reader is known not to
+	// contain any MVCC range tombstone fragments, in which case MVCC operations
+	// skip looking them up. It is a cheap check, which may return true even if
+	// there are none.
+	MayHaveRangeTombstones() bool
+	// MayHaveReplicatedLocks returns false if the Reader is known not to
+	// contain any replicated locks, in which case MVCC writes skip checking
+	// for them. It is a cheap check, which may return true even if there are
+	// none.
+	MayHaveReplicatedLocks() bool
 }
 
 // PrecedingIntentState is information needed when writing or clearing an
@@ -756,6 +766,8 @@ type Metrics struct {
 	PendingCompactionBytesEstimate int64
 	L0FileCount                    int64
 	L0SublevelCount                int64
+	L0BytesIngested                int64
+	L0Size                         int64
 	ReadAmplification              int64
 	NumSSTables                    int64
 }
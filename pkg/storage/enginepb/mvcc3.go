@@ -46,3 +46,10 @@ func (op *MVCCLogicalOp) MustSetValue(value interface{}) {
 		panic(errors.AssertionFailedf("%T excludes %T", op, value))
 	}
 }
+
+// ToleratesWriteSkew returns whether transactions running at the isolation
+// level are permitted to commit with a write timestamp that differs from
+// their read timestamp without first refreshing their reads.
+func (l IsolationLevel) ToleratesWriteSkew() bool {
+	return l == READ_COMMITTED
+}
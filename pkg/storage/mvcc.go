@@ -816,9 +816,13 @@ func newMVCCIterator(reader Reader, inlineMeta bool, opts IterOptions) MVCCItera
 func MVCCGet(
 	ctx context.Context, reader Reader, key roachpb.Key, timestamp hlc.Timestamp, opts MVCCGetOptions,
 ) (*roachpb.Value, *roachpb.Intent, error) {
+	rangeTombstones, err := maybeReadRangeTombstoneFragments(reader, key, nil /* end */, timestamp)
+	if err != nil {
+		return nil, nil, err
+	}
 	iter := newMVCCIterator(reader, timestamp.IsEmpty(), IterOptions{Prefix: true})
 	defer iter.Close()
-	value, intent, err := mvccGet(ctx, iter, key, timestamp, opts)
+	value, intent, err := mvccGet(ctx, iter, key, timestamp, rangeTombstones, opts)
 	return value.ToPointer(), intent, err
 }
 
@@ -827,6 +831,7 @@ func mvccGet(
 	iter MVCCIterator,
 	key roachpb.Key,
 	timestamp hlc.Timestamp,
+	rangeTombstones rangeTombstoneFragments,
 	opts MVCCGetOptions,
 ) (value optionalValue, intent *roachpb.Intent, err error) {
 	if len(key) == 0 {
@@ -854,6 +859,7 @@ func mvccGet(
 		tombstones:       opts.Tombstones,
 		failOnMoreRecent: opts.FailOnMoreRecent,
 		keyBuf:           mvccScanner.keyBuf,
+		rangeTombstones:  rangeTombstones,
 	}
 
 	mvccScanner.init(opts.Txn)
@@ -1089,12 +1095,21 @@ func MVCCPut(
 	// If we're not tracking stats for the key and we're writing a non-versioned
 	// key we can utilize a blind put to avoid reading any existing value.
 	var iter MVCCIterator
+	var rangeTombstones rangeTombstoneFragments
 	blind := ms == nil && timestamp.IsEmpty()
 	if !blind {
+		var err error
+		rangeTombstones, err = maybeReadRangeTombstoneFragments(rw, key, nil /* end */, timestamp)
+		if err != nil {
+			return err
+		}
+		if err := checkForReplicatedLocks(ctx, rw, key, timestamp, txn); err != nil {
+			return err
+		}
 		iter = rw.NewMVCCIterator(MVCCKeyAndIntentsIterKind, IterOptions{Prefix: true})
 		defer iter.Close()
 	}
-	return mvccPutUsingIter(ctx, rw, iter, ms, key, timestamp, value, txn, nil /* valueFn */)
+	return mvccPutUsingIter(ctx, rw, iter, rangeTombstones, ms, key, timestamp, value, txn, nil /* valueFn */)
 }
 
 // MVCCBlindPut is a fast-path of MVCCPut. See the MVCCPut comments for details
@@ -1116,7 +1131,7 @@ func MVCCBlindPut(
 	value roachpb.Value,
 	txn *roachpb.Transaction,
 ) error {
-	return mvccPutUsingIter(ctx, writer, nil, ms, key, timestamp, value, txn, nil /* valueFn */)
+	return mvccPutUsingIter(ctx, writer, nil, nil, ms, key, timestamp, value, txn, nil /* valueFn */)
 }
 
 // MVCCDelete marks the key deleted so that it will not be returned in
@@ -1133,22 +1148,31 @@ func MVCCDelete(
 	timestamp hlc.Timestamp,
 	txn *roachpb.Transaction,
 ) error {
+	rangeTombstones, err := maybeReadRangeTombstoneFragments(rw, key, nil /* end */, timestamp)
+	if err != nil {
+		return err
+	}
+	if err := checkForReplicatedLocks(ctx, rw, key, timestamp, txn); err != nil {
+		return err
+	}
 	iter := newMVCCIterator(rw, timestamp.IsEmpty(), IterOptions{Prefix: true})
 	defer iter.Close()
 
-	return mvccPutUsingIter(ctx, rw, iter, ms, key, timestamp, noValue, txn, nil /* valueFn */)
+	return mvccPutUsingIter(ctx, rw, iter, rangeTombstones, ms, key, timestamp, noValue, txn, nil /* valueFn */)
 }
 
 var noValue = roachpb.Value{}
 
 // mvccPutUsingIter sets the value for a specified key using the provided
-// MVCCIterator. The function takes a value and a valueFn, only one of which
-// should be provided. If the valueFn is nil, value's raw bytes will be set
-// for the key, else the bytes provided by the valueFn will be used.
+// MVCCIterator and the range tombstone fragments covering the key. The function
+// takes a value and a valueFn, only one of which should be provided. If the
+// valueFn is nil, value's raw bytes will be set for the key, else the bytes
+// provided by the valueFn will be used.
 func mvccPutUsingIter(
 	ctx context.Context,
 	writer Writer,
 	iter MVCCIterator,
+	rangeTombstones rangeTombstoneFragments,
 	ms *enginepb.MVCCStats,
 	key roachpb.Key,
 	timestamp hlc.Timestamp,
@@ -1166,7 +1190,7 @@ func mvccPutUsingIter(
 
 	buf := newPutBuffer()
 
-	err := mvccPutInternal(ctx, writer, iter, ms, key, timestamp, rawBytes,
+	err := mvccPutInternal(ctx, writer, iter, rangeTombstones, ms, key, timestamp, rawBytes,
 		txn, buf, valueFn)
 
 	// Using defer would be more convenient, but it is measurably slower.
@@ -1179,6 +1203,7 @@ func mvccPutUsingIter(
 func maybeGetValue(
 	ctx context.Context,
 	iter MVCCIterator,
+	rangeTombstones rangeTombstoneFragments,
 	key roachpb.Key,
 	value []byte,
 	exists bool,
@@ -1193,7 +1218,7 @@ func maybeGetValue(
 	var exVal optionalValue
 	if exists {
 		var err error
-		exVal, _, err = mvccGet(ctx, iter, key, readTimestamp, MVCCGetOptions{Txn: txn, Tombstones: true})
+		exVal, _, err = mvccGet(ctx, iter, key, readTimestamp, rangeTombstones, MVCCGetOptions{Txn: txn, Tombstones: true})
 		if err != nil {
 			return nil, err
 		}
@@ -1248,6 +1273,7 @@ func MVCCScanDecodeKeyValues(repr [][]byte, fn func(key MVCCKey, rawBytes []byte
 func replayTransactionalWrite(
 	ctx context.Context,
 	iter MVCCIterator,
+	rangeTombstones rangeTombstoneFragments,
 	meta *enginepb.MVCCMetadata,
 	key roachpb.Key,
 	timestamp hlc.Timestamp,
@@ -1262,7 +1288,7 @@ func replayTransactionalWrite(
 		// This is a special case. This is when the intent hasn't made it
 		// to the intent history yet. We must now assert the value written
 		// in the intent to the value we're trying to write.
-		exVal, _, err := mvccGet(ctx, iter, key, timestamp, MVCCGetOptions{Txn: txn, Tombstones: true})
+		exVal, _, err := mvccGet(ctx, iter, key, timestamp, rangeTombstones, MVCCGetOptions{Txn: txn, Tombstones: true})
 		if err != nil {
 			return err
 		}
@@ -1296,7 +1322,7 @@ func replayTransactionalWrite(
 			// last committed value on the key. Since we want the last committed
 			// value on the key, we must make an inconsistent read so we ignore
 			// our previous intents here.
-			exVal, _, err = mvccGet(ctx, iter, key, timestamp, MVCCGetOptions{Inconsistent: true, Tombstones: true})
+			exVal, _, err = mvccGet(ctx, iter, key, timestamp, rangeTombstones, MVCCGetOptions{Inconsistent: true, Tombstones: true})
 			if err != nil {
 				return err
 			}
@@ -1346,6 +1372,7 @@ func mvccPutInternal(
 	ctx context.Context,
 	writer Writer,
 	iter MVCCIterator,
+	rangeTombstones rangeTombstoneFragments,
 	ms *enginepb.MVCCStats,
 	key roachpb.Key,
 	timestamp hlc.Timestamp,
@@ -1382,7 +1409,7 @@ func mvccPutInternal(
 		}
 		var metaKeySize, metaValSize int64
 		if value, err = maybeGetValue(
-			ctx, iter, key, value, ok, timestamp, txn, valueFn); err != nil {
+			ctx, iter, rangeTombstones, key, value, ok, timestamp, txn, valueFn); err != nil {
 			return err
 		}
 		if value == nil {
@@ -1403,6 +1430,46 @@ func mvccPutInternal(
 		return err
 	}
 
+	// If a range tombstone deleted the key after its newest committed version,
+	// materialize that deletion as a point tombstone at the range tombstone's
+	// timestamp. This way the logic below only has to consider point versions:
+	// writes under the range tombstone are pushed above it, and the write's
+	// stats are computed relative to the deletion.
+	if f := rangeTombstones.find(key); f != nil && (!ok || buf.meta.Txn == nil) {
+		var newestTimestamp hlc.Timestamp
+		if ok {
+			newestTimestamp = buf.meta.Timestamp.ToTimestamp()
+		}
+		if tombstoneTimestamp, covered := f.newestIn(newestTimestamp, hlc.MaxTimestamp); covered {
+			var orig *enginepb.MVCCMetadata
+			if ok {
+				orig = &buf.meta
+				if !orig.Deleted {
+					// The newest version has been accounted for as deleted at the
+					// oldest range tombstone above it. See ComputeStatsForRange.
+					deletedTimestamp, _ := f.oldestAbove(newestTimestamp)
+					orig.Deleted = true
+					orig.Timestamp = deletedTimestamp.ToLegacyTimestamp()
+				}
+			}
+			tombstoneMeta := enginepb.MVCCMetadata{
+				Timestamp: tombstoneTimestamp.ToLegacyTimestamp(),
+				Deleted:   true,
+				KeyBytes:  MVCCVersionTimestampSize,
+			}
+			if err := writer.PutMVCC(MVCCKey{Key: key, Timestamp: tombstoneTimestamp}, nil); err != nil {
+				return err
+			}
+			metaKeySize := int64(metaKey.EncodedSize())
+			if ms != nil {
+				ms.Add(updateStatsOnPut(key, 0 /* prevValSize */, origMetaKeySize, origMetaValSize,
+					metaKeySize, 0 /* metaValSize */, orig, &tombstoneMeta))
+			}
+			buf.meta = tombstoneMeta
+			ok, origMetaKeySize, origMetaValSize = true, metaKeySize, 0
+		}
+	}
+
 	// Compute PrecedingIntentState and whether the transaction has previously
 	// updated the intent. This is to prepare for a later Put.
 	var precedingIntentState PrecedingIntentState
@@ -1479,7 +1546,7 @@ func mvccPutInternal(
 				// The transaction has executed at this sequence before. This is merely a
 				// replay of the transactional write. Assert that all is in order and return
 				// early.
-				return replayTransactionalWrite(ctx, iter, meta, key, readTimestamp, value, txn, valueFn)
+				return replayTransactionalWrite(ctx, iter, rangeTombstones, meta, key, readTimestamp, value, txn, valueFn)
 			}
 
 			// We're overwriting the intent that was present at this key, before we do
@@ -1511,7 +1578,7 @@ func mvccPutInternal(
 				if !enginepb.TxnSeqIsIgnored(meta.Txn.Sequence, txn.IgnoredSeqNums) {
 					// Seqnum of last write is not ignored. Retrieve the value
 					// using a consistent read.
-					exVal, _, err = mvccGet(ctx, iter, key, readTimestamp, MVCCGetOptions{Txn: txn, Tombstones: true})
+					exVal, _, err = mvccGet(ctx, iter, key, readTimestamp, rangeTombstones, MVCCGetOptions{Txn: txn, Tombstones: true})
 					if err != nil {
 						return err
 					}
@@ -1532,7 +1599,7 @@ func mvccPutInternal(
 				//
 				// Since we want the last committed value on the key, we must make
 				// an inconsistent read so we ignore our previous intents here.
-				exVal, _, err = mvccGet(ctx, iter, key, readTimestamp, MVCCGetOptions{Inconsistent: true, Tombstones: true})
+				exVal, _, err = mvccGet(ctx, iter, key, readTimestamp, rangeTombstones, MVCCGetOptions{Inconsistent: true, Tombstones: true})
 				if err != nil {
 					return err
 				}
@@ -1639,7 +1706,7 @@ func mvccPutInternal(
 			// timestamp.
 			if txn != nil {
 				if value, err = maybeGetValue(
-					ctx, iter, key, value, ok, readTimestamp, txn, valueFn); err != nil {
+					ctx, iter, rangeTombstones, key, value, ok, readTimestamp, txn, valueFn); err != nil {
 					return err
 				}
 			} else {
@@ -1650,13 +1717,13 @@ func mvccPutInternal(
 				// value, but that's a concern of evaluateBatch and not here.
 				readTimestamp = writeTimestamp
 				if value, err = maybeGetValue(
-					ctx, iter, key, value, ok, readTimestamp, txn, valueFn); err != nil {
+					ctx, iter, rangeTombstones, key, value, ok, readTimestamp, txn, valueFn); err != nil {
 					return err
 				}
 			}
 		} else {
 			if value, err = maybeGetValue(
-				ctx, iter, key, value, ok, readTimestamp, txn, valueFn); err != nil {
+				ctx, iter, rangeTombstones, key, value, ok, readTimestamp, txn, valueFn); err != nil {
 				return err
 			}
 		}
@@ -1763,12 +1830,19 @@ func MVCCIncrement(
 	txn *roachpb.Transaction,
 	inc int64,
 ) (int64, error) {
+	rangeTombstones, err := maybeReadRangeTombstoneFragments(rw, key, nil /* end */, timestamp)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkForReplicatedLocks(ctx, rw, key, timestamp, txn); err != nil {
+		return 0, err
+	}
 	iter := newMVCCIterator(rw, timestamp.IsEmpty(), IterOptions{Prefix: true})
 	defer iter.Close()
 
 	var int64Val int64
 	var newInt64Val int64
-	err := mvccPutUsingIter(ctx, rw, iter, ms, key, timestamp, noValue, txn, func(value optionalValue) ([]byte, error) {
+	err = mvccPutUsingIter(ctx, rw, iter, rangeTombstones, ms, key, timestamp, noValue, txn, func(value optionalValue) ([]byte, error) {
 		if value.IsPresent() {
 			var err error
 			if int64Val, err = value.GetInt(); err != nil {
@@ -1835,10 +1909,18 @@ func MVCCConditionalPut(
 	allowIfDoesNotExist CPutMissingBehavior,
 	txn *roachpb.Transaction,
 ) error {
+	rangeTombstones, err := maybeReadRangeTombstoneFragments(rw, key, nil /* end */, timestamp)
+	if err != nil {
+		return err
+	}
+	if err := checkForReplicatedLocks(ctx, rw, key, timestamp, txn); err != nil {
+		return err
+	}
 	iter := newMVCCIterator(rw, timestamp.IsEmpty(), IterOptions{Prefix: true})
 	defer iter.Close()
 
-	return mvccConditionalPutUsingIter(ctx, rw, iter, ms, key, timestamp, value, expVal, allowIfDoesNotExist, txn)
+	return mvccConditionalPutUsingIter(
+		ctx, rw, iter, rangeTombstones, ms, key, timestamp, value, expVal, allowIfDoesNotExist, txn)
 }
 
 // MVCCBlindConditionalPut is a fast-path of MVCCConditionalPut. See the
@@ -1861,13 +1943,14 @@ func MVCCBlindConditionalPut(
 	allowIfDoesNotExist CPutMissingBehavior,
 	txn *roachpb.Transaction,
 ) error {
-	return mvccConditionalPutUsingIter(ctx, writer, nil, ms, key, timestamp, value, expVal, allowIfDoesNotExist, txn)
+	return mvccConditionalPutUsingIter(ctx, writer, nil, nil, ms, key, timestamp, value, expVal, allowIfDoesNotExist, txn)
 }
 
 func mvccConditionalPutUsingIter(
 	ctx context.Context,
 	writer Writer,
 	iter MVCCIterator,
+	rangeTombstones rangeTombstoneFragments,
 	ms *enginepb.MVCCStats,
 	key roachpb.Key,
 	timestamp hlc.Timestamp,
@@ -1877,7 +1960,7 @@ func mvccConditionalPutUsingIter(
 	txn *roachpb.Transaction,
 ) error {
 	return mvccPutUsingIter(
-		ctx, writer, iter, ms, key, timestamp, noValue, txn,
+		ctx, writer, iter, rangeTombstones, ms, key, timestamp, noValue, txn,
 		func(existVal optionalValue) ([]byte, error) {
 			if expValPresent, existValPresent := len(expBytes) != 0, existVal.IsPresent(); expValPresent && existValPresent {
 				if !bytes.Equal(expBytes, existVal.TagAndDataBytes()) {
@@ -1913,9 +1996,16 @@ func MVCCInitPut(
 	failOnTombstones bool,
 	txn *roachpb.Transaction,
 ) error {
+	rangeTombstones, err := maybeReadRangeTombstoneFragments(rw, key, nil /* end */, timestamp)
+	if err != nil {
+		return err
+	}
+	if err := checkForReplicatedLocks(ctx, rw, key, timestamp, txn); err != nil {
+		return err
+	}
 	iter := newMVCCIterator(rw, timestamp.IsEmpty(), IterOptions{Prefix: true})
 	defer iter.Close()
-	return mvccInitPutUsingIter(ctx, rw, iter, ms, key, timestamp, value, failOnTombstones, txn)
+	return mvccInitPutUsingIter(ctx, rw, iter, rangeTombstones, ms, key, timestamp, value, failOnTombstones, txn)
 }
 
 // MVCCBlindInitPut is a fast-path of MVCCInitPut. See the MVCCInitPut
@@ -1936,13 +2026,14 @@ func MVCCBlindInitPut(
 	failOnTombstones bool,
 	txn *roachpb.Transaction,
 ) error {
-	return mvccInitPutUsingIter(ctx, rw, nil, ms, key, timestamp, value, failOnTombstones, txn)
+	return mvccInitPutUsingIter(ctx, rw, nil, nil, ms, key, timestamp, value, failOnTombstones, txn)
 }
 
 func mvccInitPutUsingIter(
 	ctx context.Context,
 	rw ReadWriter,
 	iter MVCCIterator,
+	rangeTombstones rangeTombstoneFragments,
 	ms *enginepb.MVCCStats,
 	key roachpb.Key,
 	timestamp hlc.Timestamp,
@@ -1951,7 +2042,7 @@ func mvccInitPutUsingIter(
 	txn *roachpb.Transaction,
 ) error {
 	return mvccPutUsingIter(
-		ctx, rw, iter, ms, key, timestamp, noValue, txn,
+		ctx, rw, iter, rangeTombstones, ms, key, timestamp, noValue, txn,
 		func(existVal optionalValue) ([]byte, error) {
 			if failOnTombstones && existVal.IsTombstone() {
 				// We found a tombstone and failOnTombstones is true: fail.
@@ -2045,7 +2136,68 @@ func MVCCMerge(
 //
 // If the underlying iterator encounters an intent with a timestamp in the span
 // (startTime, endTime], or any inline meta, this method will return an error.
+//
+// MVCC range tombstones with timestamps in (startTime, endTime] are removed
+// from the cleared part of the span as well.
 func MVCCClearTimeRange(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	key, endKey roachpb.Key,
+	startTime, endTime hlc.Timestamp,
+	maxBatchSize int64,
+	useTBI bool,
+) (*roachpb.Span, error) {
+	if ms == nil {
+		return nil, errors.AssertionFailedf(
+			"MVCCStats passed in to MVCCClearTimeRange must be non-nil to ensure proper stats" +
+				" computation during Clear operations")
+	}
+	rangeTombstones, err := readRangeTombstoneFragments(rw, key, endKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(rangeTombstones) == 0 {
+		return mvccClearTimeRangeVersions(ctx, rw, ms, key, endKey, startTime, endTime, maxBatchSize, useTBI)
+	}
+
+	// The incremental stats computation for the cleared versions does not
+	// account for the range tombstones covering them, so recompute the stats of
+	// the span before and after clearing instead.
+	before, err := ComputeStats(rw, key, endKey, endTime.WallTime)
+	if err != nil {
+		return nil, err
+	}
+	var scratch enginepb.MVCCStats
+	resume, err := mvccClearTimeRangeVersions(
+		ctx, rw, &scratch, key, endKey, startTime, endTime, maxBatchSize, useTBI)
+	if err != nil {
+		return nil, err
+	}
+	clearedEndKey := endKey
+	if resume != nil {
+		clearedEndKey = resume.Key
+	}
+	if rangeTombstones, err = readRangeTombstoneFragments(rw, key, clearedEndKey); err != nil {
+		return nil, err
+	}
+	if err := removeRangeTombstones(
+		ctx, rw, ms, rangeTombstones, key, clearedEndKey, startTime, endTime,
+	); err != nil {
+		return nil, err
+	}
+	after, err := ComputeStats(rw, key, endKey, endTime.WallTime)
+	if err != nil {
+		return nil, err
+	}
+	ms.Subtract(before)
+	ms.Add(after)
+	return resume, nil
+}
+
+// mvccClearTimeRangeVersions implements MVCCClearTimeRange for the point
+// versions in the span, ignoring range tombstones.
+func mvccClearTimeRangeVersions(
 	_ context.Context,
 	rw ReadWriter,
 	ms *enginepb.MVCCStats,
@@ -2073,12 +2225,6 @@ func MVCCClearTimeRange(
 	var bufSize int
 	var clearRangeStart MVCCKey
 
-	if ms == nil {
-		return nil, errors.AssertionFailedf(
-			"MVCCStats passed in to MVCCClearTimeRange must be non-nil to ensure proper stats" +
-				" computation during Clear operations")
-	}
-
 	clearMatchingKey := func(k MVCCKey) {
 		if len(clearRangeStart.Key) == 0 {
 			// Currently buffering keys to clear one-by-one.
@@ -2145,6 +2291,7 @@ func MVCCClearTimeRange(
 		EndKey:                              endKey,
 		StartTime:                           startTime,
 		EndTime:                             endTime,
+		IgnoreRangeTombstones:               true,
 	})
 	defer iter.Close()
 
@@ -2260,6 +2407,10 @@ func MVCCDeleteRange(
 		return nil, nil, 0, err
 	}
 
+	rangeTombstones, err := maybeReadRangeTombstoneFragments(rw, key, endKey, timestamp)
+	if err != nil {
+		return nil, nil, 0, err
+	}
 	buf := newPutBuffer()
 	defer buf.release()
 	iter := newMVCCIterator(rw, timestamp.IsEmpty(), IterOptions{Prefix: true})
@@ -2267,7 +2418,10 @@ func MVCCDeleteRange(
 
 	var keys []roachpb.Key
 	for i, kv := range res.KVs {
-		if err := mvccPutInternal(ctx, rw, iter, ms, kv.Key, timestamp, nil, txn, buf, nil); err != nil {
+		if err := checkForReplicatedLocks(ctx, rw, kv.Key, timestamp, txn); err != nil {
+			return nil, nil, 0, err
+		}
+		if err := mvccPutInternal(ctx, rw, iter, rangeTombstones, ms, kv.Key, timestamp, nil, txn, buf, nil); err != nil {
 			return nil, nil, 0, err
 		}
 		if returnKeys {
@@ -2285,6 +2439,7 @@ func mvccScanToBytes(
 	iter MVCCIterator,
 	key, endKey roachpb.Key,
 	timestamp hlc.Timestamp,
+	rangeTombstones rangeTombstoneFragments,
 	opts MVCCScanOptions,
 ) (MVCCScanResult, error) {
 	if len(endKey) == 0 {
@@ -2313,6 +2468,7 @@ func mvccScanToBytes(
 		tombstones:       opts.Tombstones,
 		failOnMoreRecent: opts.FailOnMoreRecent,
 		keyBuf:           mvccScanner.keyBuf,
+		rangeTombstones:  rangeTombstones,
 	}
 
 	mvccScanner.init(opts.Txn)
@@ -2347,9 +2503,10 @@ func mvccScanToKvs(
 	iter MVCCIterator,
 	key, endKey roachpb.Key,
 	timestamp hlc.Timestamp,
+	rangeTombstones rangeTombstoneFragments,
 	opts MVCCScanOptions,
 ) (MVCCScanResult, error) {
-	res, err := mvccScanToBytes(ctx, iter, key, endKey, timestamp, opts)
+	res, err := mvccScanToBytes(ctx, iter, key, endKey, timestamp, rangeTombstones, opts)
 	if err != nil {
 		return MVCCScanResult{}, err
 	}
@@ -2491,9 +2648,13 @@ func MVCCScan(
 	timestamp hlc.Timestamp,
 	opts MVCCScanOptions,
 ) (MVCCScanResult, error) {
+	rangeTombstones, err := maybeReadRangeTombstoneFragments(reader, key, endKey, timestamp)
+	if err != nil {
+		return MVCCScanResult{}, err
+	}
 	iter := newMVCCIterator(reader, timestamp.IsEmpty(), IterOptions{LowerBound: key, UpperBound: endKey})
 	defer iter.Close()
-	return mvccScanToKvs(ctx, iter, key, endKey, timestamp, opts)
+	return mvccScanToKvs(ctx, iter, key, endKey, timestamp, rangeTombstones, opts)
 }
 
 // MVCCScanToBytes is like MVCCScan, but it returns the results in a byte array.
@@ -2504,9 +2665,13 @@ func MVCCScanToBytes(
 	timestamp hlc.Timestamp,
 	opts MVCCScanOptions,
 ) (MVCCScanResult, error) {
+	rangeTombstones, err := maybeReadRangeTombstoneFragments(reader, key, endKey, timestamp)
+	if err != nil {
+		return MVCCScanResult{}, err
+	}
 	iter := newMVCCIterator(reader, timestamp.IsEmpty(), IterOptions{LowerBound: key, UpperBound: endKey})
 	defer iter.Close()
-	return mvccScanToBytes(ctx, iter, key, endKey, timestamp, opts)
+	return mvccScanToBytes(ctx, iter, key, endKey, timestamp, rangeTombstones, opts)
 }
 
 // MVCCScanAsTxn constructs a temporary transaction from the given transaction
@@ -2546,6 +2711,10 @@ func MVCCIterate(
 	opts MVCCScanOptions,
 	f func(roachpb.KeyValue) error,
 ) ([]roachpb.Intent, error) {
+	rangeTombstones, err := maybeReadRangeTombstoneFragments(reader, key, endKey, timestamp)
+	if err != nil {
+		return nil, err
+	}
 	iter := newMVCCIterator(
 		reader, timestamp.IsEmpty(), IterOptions{LowerBound: key, UpperBound: endKey})
 	defer iter.Close()
@@ -2556,7 +2725,7 @@ func MVCCIterate(
 		opts := opts
 		opts.MaxKeys = maxKeysPerScan
 		res, err := mvccScanToKvs(
-			ctx, iter, key, endKey, timestamp, opts)
+			ctx, iter, key, endKey, timestamp, rangeTombstones, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -2641,6 +2810,9 @@ func MVCCResolveWriteIntentUsingIter(
 	if len(intent.EndKey) > 0 {
 		return false, errors.Errorf("can't resolve range intent as point intent")
 	}
+	if err := mvccReleaseReplicatedLocks(ctx, rw, ms, intent, intent.Key, nil /* endKey */); err != nil {
+		return false, err
+	}
 	return mvccResolveWriteIntent(ctx, rw, iterAndBuf.iter, ms, intent, iterAndBuf.buf)
 }
 
@@ -3075,6 +3247,9 @@ func MVCCResolveWriteIntentRangeUsingIter(
 
 	for {
 		if max > 0 && num == max {
+			if err := mvccReleaseReplicatedLocks(ctx, rw, ms, intent, encKey.Key, nextKey.Key); err != nil {
+				return 0, nil, err
+			}
 			return num, &roachpb.Span{Key: nextKey.Key, EndKey: encEndKey.Key}, nil
 		}
 
@@ -3113,6 +3288,9 @@ func MVCCResolveWriteIntentRangeUsingIter(
 		}
 	}
 
+	if err := mvccReleaseReplicatedLocks(ctx, rw, ms, intent, encKey.Key, encEndKey.Key); err != nil {
+		return 0, nil, err
+	}
 	return num, nil, nil
 }
 
@@ -3161,6 +3339,11 @@ func MVCCGarbageCollect(
 	// which is already examining all the keys and can separate out the local
 	// keys to call MVCCGarbageCollect separately.
 	// TODO(sumeer): do we even need these coarse bounds?
+	rangeTombstones, err := readRangeTombstoneFragments(
+		rw, keys[0].Key, keys[len(keys)-1].Key.Next())
+	if err != nil {
+		return err
+	}
 	iter := rw.NewMVCCIterator(MVCCKeyAndIntentsIterKind, IterOptions{
 		LowerBound: keys[0].Key,
 		UpperBound: keys[len(keys)-1].Key.Next(),
@@ -3171,6 +3354,7 @@ func MVCCGarbageCollect(
 	// Iterate through specified GC keys.
 	meta := &enginepb.MVCCMetadata{}
 	for _, gcKey := range keys {
+		rangeTombstone := rangeTombstones.find(gcKey.Key)
 		encKey := MakeMVCCMetadataKey(gcKey.Key)
 		ok, _, metaKeySize, metaValSize, err := mvccGetMetadata(iter, encKey, meta)
 		if err != nil {
@@ -3190,6 +3374,14 @@ func MVCCGarbageCollect(
 		// should no longer be necessary since the higher levels already make
 		// sure each individual GCRequest does bounded work.
 		if meta.Timestamp.ToTimestamp().LessEq(gcKey.Timestamp) {
+			// A latest value deleted by a range tombstone is treated as deleted at
+			// the range tombstone's timestamp.
+			if !meta.Deleted && meta.Txn == nil && rangeTombstone != nil {
+				if ts, ok := rangeTombstone.oldestAbove(meta.Timestamp.ToTimestamp()); ok {
+					meta.Deleted = true
+					meta.Timestamp = ts.ToLegacyTimestamp()
+				}
+			}
 			// For version keys, don't allow GC'ing the meta key if it's
 			// not marked deleted. However, for inline values we allow it;
 			// they are internal and GCing them directly saves the extra
@@ -3319,11 +3511,16 @@ func MVCCGarbageCollect(
 				// when it's a deletion.
 				valSize := int64(len(iter.UnsafeValue()))
 
-				// A non-deletion becomes non-live when its newer neighbor shows up.
+				// A non-deletion becomes non-live when its newer neighbor shows up,
+				// or when a range tombstone deletes it if that happens first.
 				// A deletion tombstone becomes non-live right when it is created.
 				fromNS := prevNanos
 				if valSize == 0 {
 					fromNS = unsafeIterKey.Timestamp.WallTime
+				} else if rangeTombstone != nil {
+					if ts, ok := rangeTombstone.oldestAbove(unsafeIterKey.Timestamp); ok && ts.WallTime < fromNS {
+						fromNS = ts.WallTime
+					}
 				}
 
 				ms.Add(updateStatsOnGC(gcKey.Key, MVCCVersionTimestampSize,
@@ -3459,17 +3656,50 @@ func willOverflow(a, b int64) bool {
 // on the first error returned from any of them.
 //
 // Callbacks must copy any data they intend to hold on to.
+//
+// MVCC range tombstones are not taken into account, see ComputeStats.
 func ComputeStatsForRange(
 	iter SimpleMVCCIterator,
 	start, end roachpb.Key,
 	nowNanos int64,
 	callbacks ...func(MVCCKey, []byte) error,
+) (enginepb.MVCCStats, error) {
+	return computeStatsForRange(iter, start, end, nowNanos, nil /* rangeTombstones */, callbacks...)
+}
+
+// ComputeStats is like ComputeStatsForRange, but reads the key span from the
+// given reader and takes into account the MVCC range tombstones covering it.
+// A version deleted by a range tombstone is accounted for as if a point
+// deletion had been written at the range tombstone's timestamp.
+func ComputeStats(
+	reader Reader,
+	start, end roachpb.Key,
+	nowNanos int64,
+	callbacks ...func(MVCCKey, []byte) error,
+) (enginepb.MVCCStats, error) {
+	rangeTombstones, err := readRangeTombstoneFragments(reader, start, end)
+	if err != nil {
+		return enginepb.MVCCStats{}, err
+	}
+	iter := reader.NewMVCCIterator(MVCCKeyAndIntentsIterKind, IterOptions{UpperBound: end})
+	defer iter.Close()
+	return computeStatsForRange(iter, start, end, nowNanos, rangeTombstones, callbacks...)
+}
+
+func computeStatsForRange(
+	iter SimpleMVCCIterator,
+	start, end roachpb.Key,
+	nowNanos int64,
+	rangeTombstones rangeTombstoneFragments,
+	callbacks ...func(MVCCKey, []byte) error,
 ) (enginepb.MVCCStats, error) {
 	var ms enginepb.MVCCStats
 
 	var meta enginepb.MVCCMetadata
 	var prevKey []byte
 	first := false
+	// rangeTombstone is the range tombstone fragment covering the current key.
+	var rangeTombstone *rangeTombstoneFragment
 
 	// Values start accruing GCBytesAge at the timestamp at which they
 	// are shadowed (i.e. overwritten) whereas deletion tombstones
@@ -3520,6 +3750,16 @@ func ComputeStatsForRange(
 		implicitMeta := isValue && !bytes.Equal(unsafeKey.Key, prevKey)
 		prevKey = append(prevKey[:0], unsafeKey.Key...)
 
+		if !isValue || implicitMeta {
+			rangeTombstone = nil
+			if !isSys {
+				rangeTombstone = rangeTombstones.find(unsafeKey.Key)
+			}
+		}
+
+		// rangeTombstoneDeleted is set if the newest version of the key was
+		// deleted by a range tombstone.
+		var rangeTombstoneDeleted bool
 		if implicitMeta {
 			// No MVCCMetadata entry for this series of keys.
 			meta.Reset()
@@ -3527,6 +3767,13 @@ func ComputeStatsForRange(
 			meta.ValBytes = int64(len(unsafeValue))
 			meta.Deleted = len(unsafeValue) == 0
 			meta.Timestamp.WallTime = unsafeKey.Timestamp.WallTime
+			if !meta.Deleted && rangeTombstone != nil {
+				if ts, ok := rangeTombstone.oldestAbove(unsafeKey.Timestamp); ok {
+					meta.Deleted = true
+					meta.Timestamp.WallTime = ts.WallTime
+					rangeTombstoneDeleted = true
+				}
+			}
 		}
 
 		if !isValue || implicitMeta {
@@ -3596,6 +3843,9 @@ func ComputeStatsForRange(
 						"(meta: %s)", len(unsafeValue), meta.ValBytes, &meta)
 				}
 				accrueGCAgeNanos = meta.Timestamp.WallTime
+				if rangeTombstoneDeleted {
+					accrueGCAgeNanos = unsafeKey.Timestamp.WallTime
+				}
 			} else {
 				// Overwritten value. Is it a deletion tombstone?
 				isTombstone := len(unsafeValue) == 0
@@ -3604,8 +3854,15 @@ func ComputeStatsForRange(
 					ms.GCBytesAge += totalBytes * (nowNanos/1e9 - unsafeKey.Timestamp.WallTime/1e9)
 				} else {
 					// The kv pair is an overwritten value, so it became non-live when the closest more
-					// recent value was written.
-					ms.GCBytesAge += totalBytes * (nowNanos/1e9 - accrueGCAgeNanos/1e9)
+					// recent value was written, or when a range tombstone deleted it if that
+					// happened first.
+					nonLiveNanos := accrueGCAgeNanos
+					if rangeTombstone != nil {
+						if ts, ok := rangeTombstone.oldestAbove(unsafeKey.Timestamp); ok && ts.WallTime < nonLiveNanos {
+							nonLiveNanos = ts.WallTime
+						}
+					}
+					ms.GCBytesAge += totalBytes * (nowNanos/1e9 - nonLiveNanos/1e9)
 				}
 				// Update for the next version we may end up looking at.
 				accrueGCAgeNanos = unsafeKey.Timestamp.WallTime
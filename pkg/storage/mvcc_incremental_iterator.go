@@ -11,6 +11,7 @@
 package storage
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -65,6 +66,12 @@ import (
 // no delete in the subset of sstables used by timeBoundIter that deletes
 // k@t#n1, so the timeBoundIter will see k@t.
 //
+// Keys deleted by an MVCC range tombstone with a timestamp in (startTime,
+// endTime] are signaled with a synthesized point deletion at the range
+// tombstone's timestamp, which is positioned before the versions of the key it
+// deleted. Since the range tombstone may delete versions older than startTime,
+// the time-bound iterator optimization is disabled in that case.
+//
 // NOTE: This is not used by CockroachDB and has been preserved to serve as an
 // oracle to prove the correctness of the new export logic.
 type MVCCIncrementalIterator struct {
@@ -86,6 +93,22 @@ type MVCCIncrementalIterator struct {
 	// For allocation avoidance, meta is used to store the timestamp of keys
 	// regardless if they are metakeys.
 	meta enginepb.MVCCMetadata
+
+	// rangeTombstones are the range tombstone fragments with timestamps in
+	// (startTime, endTime], restricted to those timestamps.
+	rangeTombstones    rangeTombstoneFragments
+	rangeTombstonesErr error
+	// rangeTombstoneKey is the key for which pendingRangeTombstones were
+	// determined. pendingRangeTombstones are the timestamps of the range
+	// tombstones covering it for which no point deletion has been synthesized
+	// yet, in descending order.
+	rangeTombstoneKey      roachpb.Key
+	pendingRangeTombstones []hlc.Timestamp
+	// onRangeTombstone is set if the iterator is positioned on a point deletion
+	// at rangeTombstoneTimestamp synthesized for rangeTombstoneKey. The
+	// underlying iterator is then positioned on the version of the key below it.
+	onRangeTombstone        bool
+	rangeTombstoneTimestamp hlc.Timestamp
 }
 
 var _ SimpleMVCCIterator = &MVCCIncrementalIterator{}
@@ -100,6 +123,10 @@ type MVCCIncrementalIterOptions struct {
 	// time.
 	StartTime hlc.Timestamp
 	EndTime   hlc.Timestamp
+	// IgnoreRangeTombstones disables the synthesis of point deletions for the
+	// keys deleted by MVCC range tombstones, for callers which only operate on
+	// the physical versions.
+	IgnoreRangeTombstones bool
 }
 
 // NewMVCCIncrementalIterator creates an MVCCIncrementalIterator with the
@@ -111,9 +138,19 @@ type MVCCIncrementalIterOptions struct {
 func NewMVCCIncrementalIterator(
 	reader Reader, opts MVCCIncrementalIterOptions,
 ) *MVCCIncrementalIterator {
+	// The range tombstones are read before opening the iterators below, since a
+	// batch only supports a single non-prefix iterator.
+	var rangeTombstones rangeTombstoneFragments
+	var rangeTombstonesErr error
+	if !opts.IgnoreRangeTombstones {
+		rangeTombstones, rangeTombstonesErr = readRangeTombstoneFragments(
+			reader, keys.LocalMax, opts.EndKey)
+		rangeTombstones = rangeTombstones.restrictTo(opts.StartTime, opts.EndTime)
+	}
+
 	var iter MVCCIterator
 	var timeBoundIter MVCCIterator
-	if opts.EnableTimeBoundIteratorOptimization {
+	if opts.EnableTimeBoundIteratorOptimization && len(rangeTombstones) == 0 {
 		// An iterator without the timestamp hints is created to ensure that the
 		// iterator visits every required version of every key that has changed.
 		iter = reader.NewMVCCIterator(MVCCKeyAndIntentsIterKind, IterOptions{
@@ -135,10 +172,12 @@ func NewMVCCIncrementalIterator(
 	}
 
 	return &MVCCIncrementalIterator{
-		iter:          iter,
-		startTime:     opts.StartTime,
-		endTime:       opts.EndTime,
-		timeBoundIter: timeBoundIter,
+		iter:               iter,
+		startTime:          opts.StartTime,
+		endTime:            opts.EndTime,
+		timeBoundIter:      timeBoundIter,
+		rangeTombstones:    rangeTombstones,
+		rangeTombstonesErr: rangeTombstonesErr,
 	}
 }
 
@@ -146,6 +185,14 @@ func NewMVCCIncrementalIterator(
 // provided key. startKey should be a metadata key to ensure that the iterator
 // has a chance to observe any intents on the key if they are there.
 func (i *MVCCIncrementalIterator) SeekGE(startKey MVCCKey) {
+	if i.rangeTombstonesErr != nil {
+		i.err = i.rangeTombstonesErr
+		i.valid = false
+		return
+	}
+	i.onRangeTombstone = false
+	i.rangeTombstoneKey = i.rangeTombstoneKey[:0]
+	i.pendingRangeTombstones = nil
 	if i.timeBoundIter != nil {
 		// Check which is the first key seen by the TBI.
 		i.timeBoundIter.SeekGE(startKey)
@@ -184,6 +231,12 @@ func (i *MVCCIncrementalIterator) Close() {
 // call, Valid() will be true if the iterator was not positioned at the last
 // key.
 func (i *MVCCIncrementalIterator) Next() {
+	if i.onRangeTombstone {
+		// Move on to the version below the synthesized point deletion.
+		i.onRangeTombstone = false
+		i.advance()
+		return
+	}
 	i.iter.Next()
 	if ok, err := i.iter.Valid(); !ok {
 		i.err = err
@@ -197,6 +250,7 @@ func (i *MVCCIncrementalIterator) Next() {
 // from Next which advances to the next version of the current key or the next
 // key if the iterator is currently located at the last version for a key.
 func (i *MVCCIncrementalIterator) NextKey() {
+	i.onRangeTombstone = false
 	i.iter.NextKey()
 	if ok, err := i.iter.Valid(); !ok {
 		i.err = err
@@ -357,6 +411,9 @@ func (i *MVCCIncrementalIterator) advance() {
 		// order with the exception of the metakey (timestamp 0) being sorted
 		// first. See mvcc.h for more information.
 		metaTimestamp := i.meta.Timestamp.ToTimestamp()
+		if i.maybeSynthesizeRangeTombstone(metaTimestamp) {
+			break
+		}
 		if i.endTime.Less(metaTimestamp) {
 			i.iter.Next()
 		} else if metaTimestamp.LessEq(i.startTime) {
@@ -375,6 +432,35 @@ func (i *MVCCIncrementalIterator) advance() {
 	}
 }
 
+// maybeSynthesizeRangeTombstone positions the iterator on a synthesized point
+// deletion if a range tombstone within the time bounds deleted the current
+// version, which is at the given timestamp, and returns whether it did.
+func (i *MVCCIncrementalIterator) maybeSynthesizeRangeTombstone(ts hlc.Timestamp) bool {
+	if len(i.rangeTombstones) == 0 {
+		return false
+	}
+	unsafeKey := i.iter.UnsafeKey().Key
+	if !unsafeKey.Equal(i.rangeTombstoneKey) {
+		i.rangeTombstoneKey = append(i.rangeTombstoneKey[:0], unsafeKey...)
+		i.pendingRangeTombstones = nil
+		if f := i.rangeTombstones.find(unsafeKey); f != nil {
+			i.pendingRangeTombstones = f.timestamps
+		}
+	}
+	if len(i.pendingRangeTombstones) > 0 && i.pendingRangeTombstones[0] == ts {
+		// A write over the range tombstone materialized a physical deletion at
+		// its timestamp, which is emitted in its place.
+		i.pendingRangeTombstones = i.pendingRangeTombstones[1:]
+	}
+	if len(i.pendingRangeTombstones) == 0 || !ts.Less(i.pendingRangeTombstones[0]) {
+		return false
+	}
+	i.rangeTombstoneTimestamp = i.pendingRangeTombstones[0]
+	i.pendingRangeTombstones = i.pendingRangeTombstones[1:]
+	i.onRangeTombstone = true
+	return true
+}
+
 // Valid must be called after any call to Reset(), Next(), or similar methods.
 // It returns (true, nil) if the iterator points to a valid key (it is undefined
 // to call Key(), Value(), or similar methods unless Valid() has returned (true,
@@ -387,23 +473,38 @@ func (i *MVCCIncrementalIterator) Valid() (bool, error) {
 
 // Key returns the current key.
 func (i *MVCCIncrementalIterator) Key() MVCCKey {
+	if i.onRangeTombstone {
+		return MVCCKey{
+			Key:       append(roachpb.Key(nil), i.rangeTombstoneKey...),
+			Timestamp: i.rangeTombstoneTimestamp,
+		}
+	}
 	return i.iter.Key()
 }
 
 // Value returns the current value as a byte slice.
 func (i *MVCCIncrementalIterator) Value() []byte {
+	if i.onRangeTombstone {
+		return nil
+	}
 	return i.iter.Value()
 }
 
 // UnsafeKey returns the same key as Key, but the memory is invalidated on the
 // next call to {Next,Reset,Close}.
 func (i *MVCCIncrementalIterator) UnsafeKey() MVCCKey {
+	if i.onRangeTombstone {
+		return MVCCKey{Key: i.rangeTombstoneKey, Timestamp: i.rangeTombstoneTimestamp}
+	}
 	return i.iter.UnsafeKey()
 }
 
 // UnsafeValue returns the same value as Value, but the memory is invalidated on
 // the next call to {Next,Reset,Close}.
 func (i *MVCCIncrementalIterator) UnsafeValue() []byte {
+	if i.onRangeTombstone {
+		return nil
+	}
 	return i.iter.UnsafeValue()
 }
 
@@ -412,6 +513,10 @@ func (i *MVCCIncrementalIterator) UnsafeValue() []byte {
 // This method throws an error if it encounters an intent in the time range
 // (startTime, endTime] or sees an inline value.
 func (i *MVCCIncrementalIterator) NextIgnoringTime() {
+	if i.onRangeTombstone {
+		i.onRangeTombstone = false
+		return
+	}
 	for {
 		i.iter.Next()
 		if ok, err := i.iter.Valid(); !ok {
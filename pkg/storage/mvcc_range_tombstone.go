@@ -0,0 +1,573 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble/vfs"
+)
+
+// MVCC range tombstones delete all versions of the keys in a span [start, end)
+// at a timestamp, without writing a point deletion for every key. A key is
+// deleted at a range tombstone's timestamp T if the tombstone covers it and the
+// key has a version below T. Reads at or above T do not see the versions below
+// T, reads below T are unaffected.
+//
+// Range tombstones are stored as non-overlapping fragments in the range-local
+// keyspace under keys.MVCCRangeTombstoneKey(start), which sorts (and addresses)
+// by the fragment's start key. Every fragment records all the timestamps at
+// which its span was deleted. Writing a range tombstone splits the fragments it
+// partially overlaps, so that a key is always covered by at most one fragment.
+// Fragments never straddle a range boundary.
+//
+// Since fragments are read by every MVCC operation on global keys, they are
+// not subject to latching for reads: writers of fragments declare a write latch
+// over the range's entire range tombstone keyspace, and readers rely on the
+// latches they hold over the user keys they read.
+
+// MVCCRangeTombstone is an MVCC range tombstone, which deletes all the keys in
+// [StartKey, EndKey) at Timestamp.
+type MVCCRangeTombstone struct {
+	StartKey  roachpb.Key
+	EndKey    roachpb.Key
+	Timestamp hlc.Timestamp
+}
+
+// rangeTombstoneFragment is the in-memory form of an
+// enginepb.MVCCRangeTombstoneFragment.
+type rangeTombstoneFragment struct {
+	startKey, endKey roachpb.Key
+	// timestamps are in descending order.
+	timestamps []hlc.Timestamp
+}
+
+// newestIn returns the newest timestamp of the fragment in the interval
+// (after, upTo].
+func (f *rangeTombstoneFragment) newestIn(after, upTo hlc.Timestamp) (hlc.Timestamp, bool) {
+	for _, ts := range f.timestamps {
+		if ts.LessEq(upTo) {
+			if after.Less(ts) {
+				return ts, true
+			}
+			break
+		}
+	}
+	return hlc.Timestamp{}, false
+}
+
+// oldestAbove returns the oldest timestamp of the fragment above ts, i.e. the
+// timestamp at which a version written at ts was deleted by the fragment.
+func (f *rangeTombstoneFragment) oldestAbove(ts hlc.Timestamp) (hlc.Timestamp, bool) {
+	for i := len(f.timestamps) - 1; i >= 0; i-- {
+		if ts.Less(f.timestamps[i]) {
+			return f.timestamps[i], true
+		}
+	}
+	return hlc.Timestamp{}, false
+}
+
+// rangeTombstoneFragments is a set of fragments, sorted by start key.
+type rangeTombstoneFragments []rangeTombstoneFragment
+
+// find returns the fragment covering key, or nil.
+func (fs rangeTombstoneFragments) find(key roachpb.Key) *rangeTombstoneFragment {
+	if len(fs) == 0 {
+		return nil
+	}
+	i := sort.Search(len(fs), func(i int) bool {
+		return key.Compare(fs[i].endKey) < 0
+	})
+	if i == len(fs) || key.Compare(fs[i].startKey) < 0 {
+		return nil
+	}
+	return &fs[i]
+}
+
+// restrictTo returns the fragments with their timestamps restricted to the
+// interval (startTime, endTime], omitting fragments with no timestamps left.
+func (fs rangeTombstoneFragments) restrictTo(
+	startTime, endTime hlc.Timestamp,
+) rangeTombstoneFragments {
+	var restricted rangeTombstoneFragments
+	for _, f := range fs {
+		var timestamps []hlc.Timestamp
+		for _, ts := range f.timestamps {
+			if startTime.Less(ts) && ts.LessEq(endTime) {
+				timestamps = append(timestamps, ts)
+			}
+		}
+		if len(timestamps) > 0 {
+			restricted = append(restricted, rangeTombstoneFragment{
+				startKey: f.startKey, endKey: f.endKey, timestamps: timestamps,
+			})
+		}
+	}
+	return restricted
+}
+
+// readRangeTombstoneFragments returns the range tombstone fragments
+// overlapping the span [start, end), or the fragment covering start if end is
+// empty. Range tombstones only cover global keys.
+//
+// This opens a non-prefix iterator, so callers which operate on a batch must
+// read the fragments before opening their own iterators. No iterator is opened
+// if the reader cannot contain any fragments.
+func readRangeTombstoneFragments(
+	reader Reader, start, end roachpb.Key,
+) (rangeTombstoneFragments, error) {
+	if !reader.MayHaveRangeTombstones() {
+		return nil, nil
+	}
+	if len(end) == 0 {
+		end = start.Next()
+	}
+	if bytes.Compare(start, keys.LocalMax) < 0 {
+		start = keys.LocalMax
+	}
+	if bytes.Compare(start, end) >= 0 {
+		return nil, nil
+	}
+	// The fragments are unversioned keys, so they are read using an
+	// EngineIterator, which also leaves any cached MVCCIterator of the reader
+	// for the caller.
+	iter := reader.NewEngineIterator(IterOptions{
+		LowerBound: keys.LocalMVCCRangeTombstonePrefix,
+		UpperBound: keys.MVCCRangeTombstoneKey(end),
+	})
+	defer iter.Close()
+
+	var fragments rangeTombstoneFragments
+	var meta enginepb.MVCCMetadata
+	var frag enginepb.MVCCRangeTombstoneFragment
+	decode := func() (rangeTombstoneFragment, error) {
+		engineKey, err := iter.UnsafeEngineKey()
+		if err != nil {
+			return rangeTombstoneFragment{}, err
+		}
+		startKey, err := keys.DecodeMVCCRangeTombstoneKey(engineKey.Key)
+		if err != nil {
+			return rangeTombstoneFragment{}, err
+		}
+		if err := protoutil.Unmarshal(iter.UnsafeValue(), &meta); err != nil {
+			return rangeTombstoneFragment{}, errors.Wrap(err, "unable to decode MVCCMetadata")
+		}
+		frag.Reset()
+		if err := (roachpb.Value{RawBytes: meta.RawBytes}).GetProto(&frag); err != nil {
+			return rangeTombstoneFragment{}, errors.Wrapf(err,
+				"unable to decode MVCC range tombstone fragment at %s", startKey)
+		}
+		return rangeTombstoneFragment{
+			startKey:   append(roachpb.Key(nil), startKey...),
+			endKey:     frag.EndKey,
+			timestamps: frag.Timestamps,
+		}, nil
+	}
+
+	startTombstoneKey := EngineKey{Key: keys.MVCCRangeTombstoneKey(start)}
+	// The fragment starting before start may still overlap it.
+	if ok, err := iter.SeekEngineKeyLT(startTombstoneKey); err != nil {
+		return nil, err
+	} else if ok {
+		f, err := decode()
+		if err != nil {
+			return nil, err
+		}
+		if f.endKey.Compare(start) > 0 {
+			fragments = append(fragments, f)
+		}
+	}
+	ok, err := iter.SeekEngineKeyGE(startTombstoneKey)
+	for ; ok; ok, err = iter.NextEngineKey() {
+		f, err := decode()
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fragments, nil
+}
+
+// maybeReadRangeTombstoneFragments is like readRangeTombstoneFragments, but
+// returns no fragments for inline (non-versioned) accesses.
+func maybeReadRangeTombstoneFragments(
+	reader Reader, start, end roachpb.Key, timestamp hlc.Timestamp,
+) (rangeTombstoneFragments, error) {
+	if timestamp.IsEmpty() || len(start) == 0 {
+		return nil, nil
+	}
+	return readRangeTombstoneFragments(reader, start, end)
+}
+
+// isMVCCRangeTombstoneKey returns whether the given key, which may be followed
+// by the rest of its engine key encoding, is the key of an MVCC range
+// tombstone fragment.
+func isMVCCRangeTombstoneKey(key []byte) bool {
+	return bytes.HasPrefix(key, keys.LocalMVCCRangeTombstonePrefix)
+}
+
+// sstHasKeyWithPrefix returns whether the sstable at the given path contains
+// a key with the given prefix.
+func sstHasKeyWithPrefix(fs vfs.FS, path string, prefix roachpb.Key) (bool, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return false, err
+	}
+	// The iterator takes ownership of the file.
+	iter, err := NewSSTIterator(f)
+	if err != nil {
+		return false, err
+	}
+	defer iter.Close()
+	iter.SeekGE(MakeMVCCMetadataKey(prefix))
+	if ok, err := iter.Valid(); err != nil || !ok {
+		return false, err
+	}
+	return bytes.HasPrefix(iter.UnsafeKey().Key, prefix), nil
+}
+
+// MVCCScanRangeTombstones returns the MVCC range tombstones overlapping the
+// span [start, end), truncated to the span. The tombstones are ordered by start
+// key and then by descending timestamp, and tombstones with the same start key
+// have the same end key.
+func MVCCScanRangeTombstones(
+	_ context.Context, reader Reader, start, end roachpb.Key,
+) ([]MVCCRangeTombstone, error) {
+	fragments, err := readRangeTombstoneFragments(reader, start, end)
+	if err != nil {
+		return nil, err
+	}
+	var tombstones []MVCCRangeTombstone
+	for _, f := range fragments {
+		startKey, endKey := f.startKey, f.endKey
+		if startKey.Compare(start) < 0 {
+			startKey = start
+		}
+		if endKey.Compare(end) > 0 {
+			endKey = end
+		}
+		for _, ts := range f.timestamps {
+			tombstones = append(tombstones, MVCCRangeTombstone{
+				StartKey:  startKey,
+				EndKey:    endKey,
+				Timestamp: ts,
+			})
+		}
+	}
+	return tombstones, nil
+}
+
+// MVCCDeleteRangeUsingTombstone deletes all the keys in [startKey, endKey) at
+// the given timestamp by writing an MVCC range tombstone, which takes time
+// proportional to the number of keys in the span but only writes to the
+// range tombstone keyspace. Range tombstones can only be written outside of a
+// transaction and only over global keys.
+//
+// A WriteTooOldError is returned if any key in the span has a version at or
+// above the timestamp, or if the span is already covered by a range tombstone
+// at or above it. If intents are encountered, a WriteIntentError is returned
+// with up to maxIntents intents (zero means unlimited). Inline values in the
+// span are an error.
+func MVCCDeleteRangeUsingTombstone(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	startKey, endKey roachpb.Key,
+	timestamp hlc.Timestamp,
+	maxIntents int64,
+) error {
+	if len(startKey) == 0 || len(endKey) == 0 || startKey.Compare(endKey) >= 0 {
+		return errors.Errorf("invalid span [%s,%s) for MVCC range tombstone", startKey, endKey)
+	}
+	if startKey.Compare(keys.LocalMax) < 0 {
+		return errors.Errorf("cannot write MVCC range tombstone over local keys [%s,%s)",
+			startKey, endKey)
+	}
+	if timestamp.IsEmpty() {
+		return errors.Errorf("cannot write MVCC range tombstone without a timestamp")
+	}
+
+	fragments, err := readRangeTombstoneFragments(rw, startKey, endKey)
+	if err != nil {
+		return err
+	}
+	for i := range fragments {
+		if newest := fragments[i].timestamps[0]; timestamp.LessEq(newest) {
+			return roachpb.NewWriteTooOldError(timestamp, newest.Next())
+		}
+	}
+
+	// Check the keys in the span for conflicts and compute the stats delta of
+	// deleting the live ones. Only the newest version of a key and its meta key
+	// turn from live to non-live; older versions are unaffected, since they
+	// were already shadowed at an older timestamp.
+	var intents []roachpb.Intent
+	var delta enginepb.MVCCStats
+	delta.AgeTo(timestamp.WallTime)
+	if err := func() error {
+		iter := rw.NewMVCCIterator(MVCCKeyAndIntentsIterKind, IterOptions{
+			LowerBound: startKey,
+			UpperBound: endKey,
+		})
+		defer iter.Close()
+
+		var meta enginepb.MVCCMetadata
+		for iter.SeekGE(MakeMVCCMetadataKey(startKey)); ; iter.NextKey() {
+			if ok, err := iter.Valid(); err != nil {
+				return err
+			} else if !ok {
+				break
+			}
+			unsafeKey := iter.UnsafeKey()
+			if !unsafeKey.IsValue() {
+				if err := protoutil.Unmarshal(iter.UnsafeValue(), &meta); err != nil {
+					return errors.Wrap(err, "unable to decode MVCCMetadata")
+				}
+				if meta.Txn == nil {
+					return errors.Errorf("cannot write MVCC range tombstone over inline value at %s",
+						unsafeKey.Key)
+				}
+				intents = append(intents, roachpb.MakeIntent(meta.Txn, iter.Key().Key))
+				if maxIntents > 0 && int64(len(intents)) >= maxIntents {
+					break
+				}
+				continue
+			}
+			if timestamp.LessEq(unsafeKey.Timestamp) {
+				return roachpb.NewWriteTooOldError(timestamp, unsafeKey.Timestamp.Next())
+			}
+			if len(iter.UnsafeValue()) == 0 {
+				continue
+			}
+			if f := fragments.find(unsafeKey.Key); f != nil {
+				if _, ok := f.oldestAbove(unsafeKey.Timestamp); ok {
+					// Already deleted by an older range tombstone.
+					continue
+				}
+			}
+			metaKeySize := int64(len(unsafeKey.Key)) + 1
+			delta.LiveBytes -= metaKeySize + MVCCVersionTimestampSize + int64(len(iter.UnsafeValue()))
+			delta.LiveCount--
+		}
+		return nil
+	}(); err != nil {
+		return err
+	}
+	if len(intents) > 0 {
+		return &roachpb.WriteIntentError{Intents: intents}
+	}
+
+	if err := rewriteRangeTombstoneFragments(ctx, rw, ms, fragments, startKey, endKey,
+		func(timestamps []hlc.Timestamp) []hlc.Timestamp {
+			return append([]hlc.Timestamp{timestamp}, timestamps...)
+		},
+	); err != nil {
+		return err
+	}
+	if ms != nil {
+		ms.Add(delta)
+	}
+
+	rw.LogLogicalOp(MVCCDeleteRangeOpType, MVCCLogicalOpDetails{
+		Key:       startKey,
+		EndKey:    endKey,
+		Timestamp: timestamp,
+	})
+	return nil
+}
+
+// rewriteRangeTombstoneFragments replaces the timestamps of the range tombstone
+// fragments within [startKey, endKey) by the result of fn, which is also
+// applied to the parts of the span not covered by any fragment and must not
+// modify its argument. fragments must be the fragments overlapping the span.
+// Fragments straddling the span's boundaries are split, and adjacent fragments
+// with equal timestamps are merged.
+//
+// The caller is responsible for the stats of the user keys affected by the
+// rewrite; the fragments themselves are accounted for as system keys.
+func rewriteRangeTombstoneFragments(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	fragments rangeTombstoneFragments,
+	startKey, endKey roachpb.Key,
+	fn func([]hlc.Timestamp) []hlc.Timestamp,
+) error {
+	var rewritten rangeTombstoneFragments
+	add := func(start, end roachpb.Key, timestamps []hlc.Timestamp) {
+		if len(timestamps) == 0 || start.Compare(end) >= 0 {
+			return
+		}
+		if n := len(rewritten); n > 0 && rewritten[n-1].endKey.Equal(start) &&
+			timestampsEqual(rewritten[n-1].timestamps, timestamps) {
+			rewritten[n-1].endKey = end
+			return
+		}
+		rewritten = append(rewritten, rangeTombstoneFragment{
+			startKey: start, endKey: end, timestamps: timestamps,
+		})
+	}
+	cur := startKey
+	for _, f := range fragments {
+		if f.startKey.Compare(startKey) < 0 {
+			add(f.startKey, startKey, f.timestamps)
+		} else {
+			add(cur, f.startKey, fn(nil))
+		}
+		overlapStart, overlapEnd := f.startKey, f.endKey
+		if overlapStart.Compare(startKey) < 0 {
+			overlapStart = startKey
+		}
+		if overlapEnd.Compare(endKey) > 0 {
+			overlapEnd = endKey
+		}
+		add(overlapStart, overlapEnd, fn(f.timestamps))
+		add(endKey, f.endKey, f.timestamps)
+		cur = overlapEnd
+	}
+	add(cur, endKey, fn(nil))
+	return replaceRangeTombstoneFragments(ctx, rw, ms, fragments, rewritten)
+}
+
+// replaceRangeTombstoneFragments deletes the fragments in old and writes the
+// fragments in new.
+func replaceRangeTombstoneFragments(
+	ctx context.Context, rw ReadWriter, ms *enginepb.MVCCStats, old, new rangeTombstoneFragments,
+) error {
+	for _, f := range old {
+		reused := false
+		for _, nf := range new {
+			if nf.startKey.Equal(f.startKey) {
+				reused = true
+				break
+			}
+		}
+		if reused {
+			continue
+		}
+		if err := MVCCDelete(
+			ctx, rw, ms, keys.MVCCRangeTombstoneKey(f.startKey), hlc.Timestamp{}, nil, /* txn */
+		); err != nil {
+			return err
+		}
+	}
+	for _, f := range new {
+		if err := MVCCPutProto(ctx, rw, ms, keys.MVCCRangeTombstoneKey(f.startKey),
+			hlc.Timestamp{}, nil /* txn */, &enginepb.MVCCRangeTombstoneFragment{
+				EndKey:     f.endKey,
+				Timestamps: f.timestamps,
+			}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func timestampsEqual(a, b []hlc.Timestamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// removeRangeTombstones removes the range tombstones with timestamps in
+// (startTime, endTime] from the span [startKey, endKey). The caller is
+// responsible for the stats of the user keys affected by the removal.
+func removeRangeTombstones(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	fragments rangeTombstoneFragments,
+	startKey, endKey roachpb.Key,
+	startTime, endTime hlc.Timestamp,
+) error {
+	return rewriteRangeTombstoneFragments(ctx, rw, ms, fragments, startKey, endKey,
+		func(timestamps []hlc.Timestamp) []hlc.Timestamp {
+			var remaining []hlc.Timestamp
+			for _, ts := range timestamps {
+				if startTime.Less(ts) && ts.LessEq(endTime) {
+					continue
+				}
+				remaining = append(remaining, ts)
+			}
+			return remaining
+		},
+	)
+}
+
+// MVCCClearRangeTombstones removes all MVCC range tombstones from the span
+// [startKey, endKey), for use when the span's data is cleared without regard
+// for MVCC.
+func MVCCClearRangeTombstones(
+	ctx context.Context, rw ReadWriter, ms *enginepb.MVCCStats, startKey, endKey roachpb.Key,
+) error {
+	fragments, err := readRangeTombstoneFragments(rw, startKey, endKey)
+	if err != nil || len(fragments) == 0 {
+		return err
+	}
+	return rewriteRangeTombstoneFragments(ctx, rw, ms, fragments, startKey, endKey,
+		func([]hlc.Timestamp) []hlc.Timestamp { return nil })
+}
+
+// MVCCGarbageCollectRangeTombstones removes the MVCC range tombstones with
+// timestamps at or below threshold from the span [startKey, endKey). The caller
+// must have garbage collected all versions the removed tombstones delete
+// beforehand, or they would reappear.
+func MVCCGarbageCollectRangeTombstones(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	startKey, endKey roachpb.Key,
+	threshold hlc.Timestamp,
+) error {
+	fragments, err := readRangeTombstoneFragments(rw, startKey, endKey)
+	if err != nil || len(fragments) == 0 {
+		return err
+	}
+	return removeRangeTombstones(ctx, rw, ms, fragments, startKey, endKey,
+		hlc.Timestamp{}, threshold)
+}
+
+// MVCCSplitRangeTombstones splits the MVCC range tombstone fragment straddling
+// splitKey, if any, so that no fragment straddles the boundary of the ranges
+// created by a split at splitKey.
+func MVCCSplitRangeTombstones(
+	ctx context.Context, rw ReadWriter, ms *enginepb.MVCCStats, splitKey roachpb.Key,
+) error {
+	fragments, err := readRangeTombstoneFragments(rw, splitKey, nil /* end */)
+	if err != nil || len(fragments) == 0 {
+		return err
+	}
+	f := fragments[0]
+	if f.startKey.Equal(splitKey) {
+		return nil
+	}
+	return replaceRangeTombstoneFragments(ctx, rw, ms, fragments, rangeTombstoneFragments{
+		{startKey: f.startKey, endKey: splitKey, timestamps: f.timestamps},
+		{startKey: splitKey, endKey: f.endKey, timestamps: f.timestamps},
+	})
+}
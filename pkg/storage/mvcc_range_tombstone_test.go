@@ -0,0 +1,334 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/kr/pretty"
+	"github.com/stretchr/testify/require"
+)
+
+// assertRangeTombstoneStats asserts that the stats match the stats recomputed
+// over the whole keyspace, accounting for range tombstones.
+func assertRangeTombstoneStats(
+	t *testing.T, reader Reader, debug string, ms *enginepb.MVCCStats, nowNanos int64,
+) {
+	t.Helper()
+	msCpy := *ms
+	msCpy.AgeTo(nowNanos)
+	computed, err := ComputeStats(reader, roachpb.KeyMin, roachpb.KeyMax, nowNanos)
+	require.NoError(t, err)
+	if !msCpy.Equal(computed) {
+		t.Errorf("%s: diff(ms, computed) = %s", debug, pretty.Diff(msCpy, computed))
+	}
+}
+
+func scanKeys(
+	t *testing.T, reader Reader, start, end roachpb.Key, ts hlc.Timestamp,
+) []roachpb.Key {
+	t.Helper()
+	res, err := MVCCScan(context.Background(), reader, start, end, ts, MVCCScanOptions{})
+	require.NoError(t, err)
+	var scanned []roachpb.Key
+	for _, kv := range res.KVs {
+		scanned = append(scanned, kv.Key)
+	}
+	return scanned
+}
+
+func TestMVCCDeleteRangeUsingTombstone(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			for _, key := range []roachpb.Key{testKey1, testKey2, testKey3} {
+				require.NoError(t, MVCCPut(ctx, engine, nil, key, hlc.Timestamp{WallTime: 1}, value1, nil))
+			}
+			require.NoError(t, MVCCDeleteRangeUsingTombstone(
+				ctx, engine, nil, testKey1, testKey3, hlc.Timestamp{WallTime: 3}, 0 /* maxIntents */))
+
+			// The keys are visible below the range tombstone, and deleted at and
+			// above it.
+			require.Equal(t, []roachpb.Key{testKey1, testKey2, testKey3},
+				scanKeys(t, engine, testKey1, keyMax, hlc.Timestamp{WallTime: 2}))
+			require.Equal(t, []roachpb.Key{testKey3},
+				scanKeys(t, engine, testKey1, keyMax, hlc.Timestamp{WallTime: 3}))
+
+			val, _, err := MVCCGet(ctx, engine, testKey2, hlc.Timestamp{WallTime: 2}, MVCCGetOptions{})
+			require.NoError(t, err)
+			require.NotNil(t, val)
+			val, _, err = MVCCGet(ctx, engine, testKey2, hlc.Timestamp{WallTime: 4}, MVCCGetOptions{})
+			require.NoError(t, err)
+			require.Nil(t, val)
+			val, _, err = MVCCGet(ctx, engine, testKey2, hlc.Timestamp{WallTime: 4},
+				MVCCGetOptions{Tombstones: true})
+			require.NoError(t, err)
+			require.NotNil(t, val)
+			require.False(t, val.IsPresent())
+			require.Equal(t, hlc.Timestamp{WallTime: 3}, val.Timestamp)
+
+			// A key written above the range tombstone is visible again.
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey2, hlc.Timestamp{WallTime: 5}, value2, nil))
+			require.Equal(t, []roachpb.Key{testKey2, testKey3},
+				scanKeys(t, engine, testKey1, keyMax, hlc.Timestamp{WallTime: 5}))
+			require.Equal(t, []roachpb.Key{testKey3},
+				scanKeys(t, engine, testKey1, keyMax, hlc.Timestamp{WallTime: 4}))
+
+			// Writes below the range tombstone are too old.
+			err = MVCCPut(ctx, engine, nil, testKey1, hlc.Timestamp{WallTime: 2}, value2, nil)
+			require.True(t, errors.HasType(err, (*roachpb.WriteTooOldError)(nil)), "%+v", err)
+			err = MVCCDeleteRangeUsingTombstone(
+				ctx, engine, nil, testKey1, testKey3, hlc.Timestamp{WallTime: 2}, 0 /* maxIntents */)
+			require.True(t, errors.HasType(err, (*roachpb.WriteTooOldError)(nil)), "%+v", err)
+
+			// Reads which fail on more recent writes see the range tombstone.
+			_, _, err = MVCCGet(ctx, engine, testKey1, hlc.Timestamp{WallTime: 2},
+				MVCCGetOptions{FailOnMoreRecent: true})
+			require.True(t, errors.HasType(err, (*roachpb.WriteTooOldError)(nil)), "%+v", err)
+
+			// The range tombstone is uncertain for transactions below it.
+			txn := makeTxn(*txn1, hlc.Timestamp{WallTime: 2})
+			txn.MaxTimestamp = hlc.Timestamp{WallTime: 4}
+			_, _, err = MVCCGet(ctx, engine, testKey1, txn.ReadTimestamp, MVCCGetOptions{Txn: txn})
+			require.True(t, errors.HasType(err, (*roachpb.ReadWithinUncertaintyIntervalError)(nil)),
+				"%+v", err)
+
+			// Intents in the span prevent the deletion.
+			txn = makeTxn(*txn1, hlc.Timestamp{WallTime: 6})
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey3, txn.ReadTimestamp, value3, txn))
+			err = MVCCDeleteRangeUsingTombstone(
+				ctx, engine, nil, testKey1, testKey4, hlc.Timestamp{WallTime: 7}, 0 /* maxIntents */)
+			require.True(t, errors.HasType(err, (*roachpb.WriteIntentError)(nil)), "%+v", err)
+
+			rangeTombstones, err := MVCCScanRangeTombstones(ctx, engine, keys.LocalMax, keyMax)
+			require.NoError(t, err)
+			require.Equal(t, []MVCCRangeTombstone{
+				{StartKey: testKey1, EndKey: testKey3, Timestamp: hlc.Timestamp{WallTime: 3}},
+			}, rangeTombstones)
+		})
+	}
+}
+
+func TestMVCCRangeTombstoneStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			var ms enginepb.MVCCStats
+			ts := func(wallTime int64) hlc.Timestamp {
+				return hlc.Timestamp{WallTime: wallTime * 1e9}
+			}
+
+			require.NoError(t, MVCCPut(ctx, engine, &ms, testKey1, ts(1), value1, nil))
+			require.NoError(t, MVCCPut(ctx, engine, &ms, testKey2, ts(1), value2, nil))
+			require.NoError(t, MVCCPut(ctx, engine, &ms, testKey2, ts(2), value3, nil))
+			require.NoError(t, MVCCDelete(ctx, engine, &ms, testKey3, ts(2), nil))
+			require.NoError(t, MVCCPut(ctx, engine, &ms, testKey5, ts(2), value5, nil))
+			assertRangeTombstoneStats(t, engine, "after puts", &ms, ts(2).WallTime)
+
+			require.NoError(t, MVCCDeleteRangeUsingTombstone(
+				ctx, engine, &ms, testKey1, testKey4, ts(3), 0 /* maxIntents */))
+			assertRangeTombstoneStats(t, engine, "after range tombstone", &ms, ts(3).WallTime)
+
+			require.NoError(t, MVCCDeleteRangeUsingTombstone(
+				ctx, engine, &ms, testKey2, testKey6, ts(4), 0 /* maxIntents */))
+			assertRangeTombstoneStats(t, engine, "after overlapping range tombstone", &ms, ts(4).WallTime)
+
+			require.NoError(t, MVCCPut(ctx, engine, &ms, testKey2, ts(5), value4, nil))
+			require.NoError(t, MVCCDelete(ctx, engine, &ms, testKey1, ts(5), nil))
+			assertRangeTombstoneStats(t, engine, "after writes over range tombstones", &ms, ts(5).WallTime)
+
+			require.NoError(t, MVCCSplitRangeTombstones(ctx, engine, &ms, testKey3))
+			assertRangeTombstoneStats(t, engine, "after split", &ms, ts(5).WallTime)
+
+			batch := engine.NewBatch()
+			defer batch.Close()
+			msRevert := ms
+			_, err := MVCCClearTimeRange(
+				ctx, batch, &msRevert, testKey1, keyMax, ts(3), ts(5), math.MaxInt64 /* maxBatchSize */, false /* useTBI */)
+			require.NoError(t, err)
+			assertRangeTombstoneStats(t, batch, "after clearing time range", &msRevert, ts(6).WallTime)
+			require.Equal(t, []roachpb.Key{testKey5},
+				scanKeys(t, batch, testKey1, keyMax, ts(6)))
+			rangeTombstones, err := MVCCScanRangeTombstones(ctx, batch, keys.LocalMax, keyMax)
+			require.NoError(t, err)
+			require.Equal(t, []MVCCRangeTombstone{
+				{StartKey: testKey1, EndKey: testKey4, Timestamp: ts(3)},
+			}, rangeTombstones)
+
+			// Garbage collect everything the range tombstones deleted, then the
+			// range tombstones themselves.
+			gcKeys := []roachpb.GCRequest_GCKey{
+				{Key: testKey1, Timestamp: ts(5)},
+				{Key: testKey2, Timestamp: ts(2)},
+				{Key: testKey3, Timestamp: ts(4)},
+				{Key: testKey5, Timestamp: ts(4)},
+			}
+			require.NoError(t, MVCCGarbageCollect(ctx, engine, &ms, gcKeys, ts(10)))
+			assertRangeTombstoneStats(t, engine, "after GC", &ms, ts(10).WallTime)
+			require.NoError(t, MVCCGarbageCollectRangeTombstones(
+				ctx, engine, &ms, keys.LocalMax, keyMax, ts(4)))
+			assertRangeTombstoneStats(t, engine, "after range tombstone GC", &ms, ts(10).WallTime)
+
+			rangeTombstones, err = MVCCScanRangeTombstones(ctx, engine, keys.LocalMax, keyMax)
+			require.NoError(t, err)
+			require.Empty(t, rangeTombstones)
+			require.Equal(t, []roachpb.Key{testKey2},
+				scanKeys(t, engine, testKey1, keyMax, ts(10)))
+		})
+	}
+}
+
+func TestMVCCIncrementalIteratorRangeTombstones(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey1, hlc.Timestamp{WallTime: 1}, value1, nil))
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey2, hlc.Timestamp{WallTime: 1}, value2, nil))
+			require.NoError(t, MVCCDeleteRangeUsingTombstone(
+				ctx, engine, nil, testKey1, testKey3, hlc.Timestamp{WallTime: 3}, 0 /* maxIntents */))
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey2, hlc.Timestamp{WallTime: 4}, value3, nil))
+
+			iterate := func(startTime, endTime hlc.Timestamp, ignoreRangeTombstones bool) []MVCCKey {
+				iter := NewMVCCIncrementalIterator(engine, MVCCIncrementalIterOptions{
+					EnableTimeBoundIteratorOptimization: true,
+					EndKey:                              keyMax,
+					StartTime:                           startTime,
+					EndTime:                             endTime,
+					IgnoreRangeTombstones:               ignoreRangeTombstones,
+				})
+				defer iter.Close()
+				var iterated []MVCCKey
+				for iter.SeekGE(MakeMVCCMetadataKey(localMax)); ; iter.Next() {
+					ok, err := iter.Valid()
+					require.NoError(t, err)
+					if !ok {
+						break
+					}
+					if iter.UnsafeKey().Timestamp == (hlc.Timestamp{WallTime: 3}) {
+						require.Empty(t, iter.UnsafeValue())
+					}
+					iterated = append(iterated, iter.Key())
+				}
+				return iterated
+			}
+
+			// The deletions are synthesized above the versions they delete.
+			require.Equal(t, []MVCCKey{
+				{Key: testKey1, Timestamp: hlc.Timestamp{WallTime: 3}},
+				{Key: testKey1, Timestamp: hlc.Timestamp{WallTime: 1}},
+				{Key: testKey2, Timestamp: hlc.Timestamp{WallTime: 4}},
+				{Key: testKey2, Timestamp: hlc.Timestamp{WallTime: 3}},
+				{Key: testKey2, Timestamp: hlc.Timestamp{WallTime: 1}},
+			}, iterate(hlc.Timestamp{}, hlc.Timestamp{WallTime: 5}, false /* ignoreRangeTombstones */))
+			// Deletions are synthesized even if the deleted versions are below
+			// the time bounds.
+			require.Equal(t, []MVCCKey{
+				{Key: testKey1, Timestamp: hlc.Timestamp{WallTime: 3}},
+				{Key: testKey2, Timestamp: hlc.Timestamp{WallTime: 3}},
+			}, iterate(hlc.Timestamp{WallTime: 2}, hlc.Timestamp{WallTime: 3}, false /* ignoreRangeTombstones */))
+			// Only the deletion materialized by the write above the range
+			// tombstone remains when range tombstones are ignored.
+			require.Equal(t, []MVCCKey{
+				{Key: testKey2, Timestamp: hlc.Timestamp{WallTime: 3}},
+			}, iterate(hlc.Timestamp{WallTime: 2}, hlc.Timestamp{WallTime: 3}, true /* ignoreRangeTombstones */))
+		})
+	}
+}
+
+func TestMVCCRangeTombstonesMayExist(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	open := func(fs vfs.FS) *Pebble {
+		opts := DefaultPebbleOptions()
+		opts.FS = fs
+		eng, err := NewPebble(ctx, PebbleConfig{
+			Opts: opts,
+		})
+		require.NoError(t, err)
+		return eng
+	}
+
+	// Write a range tombstone to a batch of one engine, as on a leaseholder.
+	src := open(vfs.NewMem())
+	defer src.Close()
+	require.NoError(t, MVCCPut(ctx, src, nil, testKey1, hlc.Timestamp{WallTime: 1}, value1, nil))
+	require.False(t, src.MayHaveRangeTombstones())
+	batch := src.NewBatch()
+	defer batch.Close()
+	require.False(t, batch.MayHaveRangeTombstones())
+	require.NoError(t, MVCCDeleteRangeUsingTombstone(
+		ctx, batch, nil, testKey1, testKey3, hlc.Timestamp{WallTime: 2}, 0 /* maxIntents */))
+	require.True(t, batch.MayHaveRangeTombstones())
+	require.True(t, src.MayHaveRangeTombstones())
+
+	// Applying the batch on another engine, as on a follower, makes it look up
+	// the range tombstone.
+	fs := vfs.NewMem()
+	eng := open(fs)
+	require.NoError(t, MVCCPut(ctx, eng, nil, testKey1, hlc.Timestamp{WallTime: 1}, value1, nil))
+	require.False(t, eng.MayHaveRangeTombstones())
+	require.NoError(t, eng.ApplyBatchRepr(batch.Repr(), false /* sync */))
+	require.True(t, eng.MayHaveRangeTombstones())
+	require.Empty(t, scanKeys(t, eng, testKey1, keyMax, hlc.Timestamp{WallTime: 2}))
+	eng.Close()
+
+	// The range tombstone is found when the engine is reopened.
+	eng = open(fs)
+	require.True(t, eng.MayHaveRangeTombstones())
+	eng.Close()
+
+	// Ingesting an sstable containing a range tombstone, as for a snapshot.
+	eng = open(vfs.NewMem())
+	defer eng.Close()
+	memFile := &MemFile{}
+	sst := MakeIngestionSSTWriter(memFile)
+	defer sst.Close()
+	require.NoError(t, batch.MVCCIterate(keys.LocalMVCCRangeTombstonePrefix,
+		keys.LocalMVCCRangeTombstonePrefix.PrefixEnd(), MVCCKeyIterKind,
+		func(kv MVCCKeyValue) error {
+			return sst.Put(kv.Key, kv.Value)
+		}))
+	require.NoError(t, sst.Finish())
+	require.NoError(t, eng.WriteFile(`ingest`, memFile.Data()))
+	require.False(t, eng.MayHaveRangeTombstones())
+	require.NoError(t, eng.IngestExternalFiles(ctx, []string{`ingest`}))
+	require.True(t, eng.MayHaveRangeTombstones())
+}
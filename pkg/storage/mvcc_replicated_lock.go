@@ -0,0 +1,266 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// Replicated locks are the Shared and Upgrade locks acquired with the
+// Replicated durability by locking reads. Unlike Unreplicated locks, which are
+// only held in the leaseholder's in-memory lock table, they are written through
+// Raft to the range-local keyspace under keys.ReplicatedLockKey(key, txnID), so
+// they survive lease transfers and node restarts. Exclusive replicated locks
+// are intents, which are stored alongside the provisional values they protect.
+//
+// A replicated lock conflicts with the writes of other transactions to the
+// locked key, and with the locking reads of other transactions whose strength
+// is incompatible with its own (see lock.Strength). It does not conflict with
+// non-locking reads. Conflicts are reported as WriteIntentErrors naming the
+// lock holders, which makes the conflicting request wait on (and push) the
+// holders in the concurrency manager, just like it would for an intent. A
+// replicated lock is released when its holder's locks are resolved after the
+// holder has committed or aborted.
+//
+// Replicated locks are not subject to latching for reads, since every MVCC
+// write to a global key checks the locks on it. Acquirers and releasers of
+// replicated locks declare write latches over the lock keys they modify, in
+// addition to the latches they hold over the locked keys.
+//
+// Looking up the locks on a key requires an additional iterator over the lock
+// keyspace, so the lookups are skipped unless Reader.MayHaveReplicatedLocks
+// indicates that the engine may contain any replicated locks.
+
+// MVCCAcquireReplicatedLock acquires a replicated lock of the given strength
+// (Shared or Upgrade) on the key for the transaction. If the transaction
+// already holds a lock of the same or a stronger strength on the key, the call
+// is a no-op. If other transactions hold conflicting replicated locks on the
+// key, a WriteIntentError naming them is returned.
+func MVCCAcquireReplicatedLock(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	txn *roachpb.Transaction,
+	str lock.Strength,
+	key roachpb.Key,
+) error {
+	if str != lock.Shared && str != lock.Upgrade {
+		return errors.AssertionFailedf("cannot acquire a replicated lock with strength %s", str)
+	}
+	if keys.IsLocal(key) {
+		return errors.Errorf("cannot acquire a replicated lock on local key %s", key)
+	}
+	held, err := mvccCheckReplicatedLocks(ctx, rw, txn, str, key)
+	if err != nil {
+		return err
+	}
+	if held >= str {
+		return nil
+	}
+	newLock := enginepb.ReplicatedLock{Txn: txn.TxnMeta, Strength: str}
+	return MVCCPutProto(ctx, rw, ms, keys.ReplicatedLockKey(key, txn.ID), hlc.Timestamp{}, nil, &newLock)
+}
+
+// MVCCCheckReplicatedLocks returns a WriteIntentError naming the holders if
+// transactions other than txn hold replicated locks on the key which conflict
+// with a lock of the given strength. It is used by locking reads which acquire
+// Unreplicated locks, which must still respect the Replicated ones.
+func MVCCCheckReplicatedLocks(
+	ctx context.Context, reader Reader, txn *roachpb.Transaction, str lock.Strength, key roachpb.Key,
+) error {
+	_, err := mvccCheckReplicatedLocks(ctx, reader, txn, str, key)
+	return err
+}
+
+// mvccCheckReplicatedLocks is like MVCCCheckReplicatedLocks, but also returns
+// the strength of the replicated lock held by txn on the key, if any.
+func mvccCheckReplicatedLocks(
+	ctx context.Context, reader Reader, txn *roachpb.Transaction, str lock.Strength, key roachpb.Key,
+) (held lock.Strength, _ error) {
+	locks, err := MVCCGetReplicatedLocks(ctx, reader, key)
+	if err != nil {
+		return lock.None, err
+	}
+	var conflicts []roachpb.Intent
+	for i := range locks {
+		l := &locks[i]
+		if l.Txn.ID == txn.ID {
+			held = l.Strength
+			continue
+		}
+		if replicatedLocksConflict(l.Strength, str) {
+			conflicts = append(conflicts, roachpb.MakeIntent(&l.Txn, key))
+		}
+	}
+	if len(conflicts) > 0 {
+		return lock.None, &roachpb.WriteIntentError{Intents: conflicts}
+	}
+	return held, nil
+}
+
+// MVCCGetReplicatedLocks returns the replicated locks held on the key, ordered
+// by the ID of the transaction holding them.
+func MVCCGetReplicatedLocks(
+	ctx context.Context, reader Reader, key roachpb.Key,
+) ([]enginepb.ReplicatedLock, error) {
+	if !reader.MayHaveReplicatedLocks() {
+		return nil, nil
+	}
+	prefix := keys.ReplicatedLockKeyPrefix(key)
+	var locks []enginepb.ReplicatedLock
+	err := iterateReplicatedLocks(reader, prefix, prefix.PrefixEnd(),
+		func(_ roachpb.Key, _ int64, l *enginepb.ReplicatedLock) error {
+			locks = append(locks, *l)
+			return nil
+		})
+	return locks, err
+}
+
+// iterateReplicatedLocks calls f for each replicated lock stored at a lock key
+// in [start, end), along with the encoded size of its value. The key and lock
+// passed to f are only valid for the duration of the call.
+//
+// The lock keys are unversioned, so they are read using an EngineIterator,
+// which leaves any cached MVCCIterator of the reader for the caller. This lets
+// intent resolution release locks while its own iterator is still open.
+func iterateReplicatedLocks(
+	reader Reader,
+	start, end roachpb.Key,
+	f func(lockKey roachpb.Key, valSize int64, l *enginepb.ReplicatedLock) error,
+) error {
+	iter := reader.NewEngineIterator(IterOptions{LowerBound: start, UpperBound: end})
+	defer iter.Close()
+
+	var meta enginepb.MVCCMetadata
+	var l enginepb.ReplicatedLock
+	ok, err := iter.SeekEngineKeyGE(EngineKey{Key: start})
+	for ; ok; ok, err = iter.NextEngineKey() {
+		engineKey, err := iter.UnsafeEngineKey()
+		if err != nil {
+			return err
+		}
+		if err := protoutil.Unmarshal(iter.UnsafeValue(), &meta); err != nil {
+			return errors.Wrap(err, "unable to decode MVCCMetadata")
+		}
+		l.Reset()
+		if err := (roachpb.Value{RawBytes: meta.RawBytes}).GetProto(&l); err != nil {
+			return errors.Wrapf(err, "decoding replicated lock %s", engineKey.Key)
+		}
+		if err := f(engineKey.Key, int64(len(iter.UnsafeValue())), &l); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// checkForReplicatedLocks returns a WriteIntentError if transactions other than
+// the writer hold replicated locks on the key it is about to write. Inline
+// writes and writes to local keys are not checked, since replicated locks are
+// only acquired on versioned global keys.
+func checkForReplicatedLocks(
+	ctx context.Context,
+	reader Reader,
+	key roachpb.Key,
+	timestamp hlc.Timestamp,
+	txn *roachpb.Transaction,
+) error {
+	if timestamp.IsEmpty() || keys.IsLocal(key) || !reader.MayHaveReplicatedLocks() {
+		return nil
+	}
+	locks, err := MVCCGetReplicatedLocks(ctx, reader, key)
+	if err != nil {
+		return err
+	}
+	var conflicts []roachpb.Intent
+	for i := range locks {
+		l := &locks[i]
+		if txn != nil && l.Txn.ID == txn.ID {
+			continue
+		}
+		conflicts = append(conflicts, roachpb.MakeIntent(&l.Txn, key))
+	}
+	if len(conflicts) > 0 {
+		return &roachpb.WriteIntentError{Intents: conflicts}
+	}
+	return nil
+}
+
+// isReplicatedLockKey returns whether the given key, which may be followed by
+// the rest of its engine key encoding, is the key of a replicated lock.
+func isReplicatedLockKey(key []byte) bool {
+	return bytes.HasPrefix(key, keys.LocalReplicatedLockPrefix)
+}
+
+// replicatedLocksConflict returns whether a replicated lock of the held
+// strength conflicts with the acquisition of a lock of the requested strength
+// by another transaction. Shared locks are compatible with each other, all
+// other combinations (including those with Exclusive requests) conflict.
+func replicatedLocksConflict(held, requested lock.Strength) bool {
+	return held != lock.Shared || requested != lock.Shared
+}
+
+// mvccReleaseReplicatedLocks releases the replicated locks held by the
+// transaction on the keys in the span [key, endKey), or on key alone if endKey
+// is nil, if the lock update finalizes the transaction. Locks are not released
+// by updates of pending transactions, since replicated locks do not depend on
+// the holder's timestamp and are only released when the holder finishes.
+func mvccReleaseReplicatedLocks(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	update roachpb.LockUpdate,
+	key, endKey roachpb.Key,
+) error {
+	if !update.Status.IsFinalized() || keys.IsLocal(key) || !rw.MayHaveReplicatedLocks() {
+		return nil
+	}
+	if len(endKey) == 0 {
+		endKey = key.Next()
+	}
+	// The locks are cleared directly rather than through MVCCDelete, since
+	// intent resolution may hold the cached MVCCIterators of a batch open.
+	type releasedLock struct {
+		key     roachpb.Key
+		valSize int64
+	}
+	var released []releasedLock
+	if err := iterateReplicatedLocks(rw, keys.ReplicatedLockKeyPrefix(key), keys.ReplicatedLockKeyPrefix(endKey),
+		func(lockKey roachpb.Key, valSize int64, l *enginepb.ReplicatedLock) error {
+			if l.Txn.ID == update.Txn.ID {
+				released = append(released, releasedLock{
+					key:     append(roachpb.Key(nil), lockKey...),
+					valSize: valSize,
+				})
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	for _, l := range released {
+		if err := rw.ClearUnversioned(l.key); err != nil {
+			return err
+		}
+		if ms != nil {
+			keySize := int64(MakeMVCCMetadataKey(l.key).EncodedSize())
+			updateStatsForInline(ms, l.key, keySize, l.valSize, 0, 0)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/require"
+)
+
+// requireLockConflict asserts that err is a WriteIntentError naming the
+// given transaction as the holder of a lock on the key.
+func requireLockConflict(t *testing.T, err error, key roachpb.Key, holder *roachpb.Transaction) {
+	t.Helper()
+	var wiErr *roachpb.WriteIntentError
+	require.True(t, errors.As(err, &wiErr), "expected WriteIntentError, got %v", err)
+	require.Len(t, wiErr.Intents, 1)
+	require.Equal(t, key, wiErr.Intents[0].Key)
+	require.Equal(t, holder.ID, wiErr.Intents[0].Txn.ID)
+}
+
+func TestMVCCAcquireReplicatedLock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	strengths := []lock.Strength{lock.Shared, lock.Upgrade}
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			for _, held := range strengths {
+				for _, requested := range strengths {
+					t.Run(fmt.Sprintf("%s/%s", held, requested), func(t *testing.T) {
+						engine := engineImpl.create()
+						defer engine.Close()
+
+						require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn1, held, testKey1))
+						// Reacquiring the lock is a no-op.
+						require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn1, held, testKey1))
+
+						err := MVCCAcquireReplicatedLock(ctx, engine, nil, txn2, requested, testKey1)
+						if held == lock.Shared && requested == lock.Shared {
+							require.NoError(t, err)
+							locks, err := MVCCGetReplicatedLocks(ctx, engine, testKey1)
+							require.NoError(t, err)
+							require.Len(t, locks, 2)
+						} else {
+							requireLockConflict(t, err, testKey1, txn1)
+						}
+
+						// Locks on other keys are unaffected.
+						require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn2, requested, testKey2))
+					})
+				}
+			}
+		})
+	}
+}
+
+func TestMVCCAcquireReplicatedLockUpgrade(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	engine := createTestPebbleEngine()
+	defer engine.Close()
+
+	require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn1, lock.Upgrade, testKey1))
+	// Acquiring a weaker lock does not downgrade the held lock.
+	require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn1, lock.Shared, testKey1))
+	locks, err := MVCCGetReplicatedLocks(ctx, engine, testKey1)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	require.Equal(t, lock.Upgrade, locks[0].Strength)
+	require.Equal(t, txn1.ID, locks[0].Txn.ID)
+
+	// A Shared lock can't be upgraded while another transaction shares it.
+	require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn1, lock.Shared, testKey2))
+	require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn2, lock.Shared, testKey2))
+	requireLockConflict(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn1, lock.Upgrade, testKey2), testKey2, txn2)
+
+	// Exclusive locks are intents and are not acquired as replicated locks.
+	require.Error(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn1, lock.Exclusive, testKey3))
+}
+
+func TestMVCCReplicatedLockConflictsWithWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			ts := hlc.Timestamp{WallTime: 1}
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey1, ts, value1, nil))
+			require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, nil, txn1, lock.Shared, testKey1))
+
+			// Writes by other transactions, and non-transactional writes,
+			// conflict with the lock.
+			txn := makeTxn(*txn2, hlc.Timestamp{WallTime: 2})
+			requireLockConflict(t, MVCCPut(ctx, engine, nil, testKey1, txn.ReadTimestamp, value2, txn), testKey1, txn1)
+			requireLockConflict(t, MVCCDelete(ctx, engine, nil, testKey1, txn.ReadTimestamp, txn), testKey1, txn1)
+			requireLockConflict(t, MVCCPut(ctx, engine, nil, testKey1, hlc.Timestamp{WallTime: 2}, value2, nil), testKey1, txn1)
+			_, _, _, err := MVCCDeleteRange(ctx, engine, nil, testKey1, testKey2, 0, txn.ReadTimestamp, txn, false)
+			requireLockConflict(t, err, testKey1, txn1)
+
+			// Non-locking reads do not conflict with the lock.
+			val, _, err := MVCCGet(ctx, engine, testKey1, txn.ReadTimestamp, MVCCGetOptions{Txn: txn})
+			require.NoError(t, err)
+			require.Equal(t, value1.RawBytes, val.RawBytes)
+
+			// The lock holder can write the key.
+			holder := makeTxn(*txn1, hlc.Timestamp{WallTime: 2})
+			require.NoError(t, MVCCPut(ctx, engine, nil, testKey1, holder.ReadTimestamp, value2, holder))
+		})
+	}
+}
+
+func TestMVCCResolveReleasesReplicatedLocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			var ms enginepb.MVCCStats
+			for _, key := range []roachpb.Key{testKey1, testKey2, testKey3} {
+				require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, &ms, txn1, lock.Shared, key))
+				require.NoError(t, MVCCAcquireReplicatedLock(ctx, engine, &ms, txn2, lock.Shared, key))
+			}
+			require.Equal(t, int64(6), ms.SysCount)
+			numLocks := func(key roachpb.Key) int {
+				locks, err := MVCCGetReplicatedLocks(ctx, engine, key)
+				require.NoError(t, err)
+				return len(locks)
+			}
+
+			// Updates of a pending transaction do not release its locks.
+			_, err := MVCCResolveWriteIntent(ctx, engine, &ms,
+				roachpb.MakeLockUpdate(txn1, roachpb.Span{Key: testKey1}))
+			require.NoError(t, err)
+			require.Equal(t, 2, numLocks(testKey1))
+
+			// Committing releases the locks of the transaction only.
+			_, err = MVCCResolveWriteIntent(ctx, engine, &ms,
+				roachpb.MakeLockUpdate(txn1Commit, roachpb.Span{Key: testKey1}))
+			require.NoError(t, err)
+			require.Equal(t, 1, numLocks(testKey1))
+
+			_, _, err = MVCCResolveWriteIntentRange(ctx, engine, &ms,
+				roachpb.MakeLockUpdate(txn1Abort, roachpb.Span{Key: testKey1, EndKey: testKey3}), 0)
+			require.NoError(t, err)
+			require.Equal(t, 1, numLocks(testKey2))
+			require.Equal(t, 2, numLocks(testKey3))
+			require.Equal(t, int64(4), ms.SysCount)
+
+			_, _, err = MVCCResolveWriteIntentRange(ctx, engine, &ms,
+				roachpb.MakeLockUpdate(txn2Commit, roachpb.Span{Key: testKey1, EndKey: keyMax}), 0)
+			require.NoError(t, err)
+			for _, key := range []roachpb.Key{testKey1, testKey2, testKey3} {
+				locks, err := MVCCGetReplicatedLocks(ctx, engine, key)
+				require.NoError(t, err)
+				for _, l := range locks {
+					require.Equal(t, txn1.ID, l.Txn.ID)
+				}
+			}
+			require.Equal(t, int64(1), ms.SysCount)
+		})
+	}
+}
+
+func TestMVCCReplicatedLocksMayExist(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	open := func(fs vfs.FS) *Pebble {
+		opts := DefaultPebbleOptions()
+		opts.FS = fs
+		eng, err := NewPebble(ctx, PebbleConfig{
+			Opts: opts,
+		})
+		require.NoError(t, err)
+		return eng
+	}
+
+	// Acquire a lock in a batch of one engine, as on a leaseholder. Writes do
+	// not look up locks until then, and do not mark the engine.
+	src := open(vfs.NewMem())
+	defer src.Close()
+	require.NoError(t, MVCCPut(ctx, src, nil, testKey1, hlc.Timestamp{WallTime: 1}, value1, nil))
+	require.False(t, src.MayHaveReplicatedLocks())
+	batch := src.NewBatch()
+	defer batch.Close()
+	require.False(t, batch.MayHaveReplicatedLocks())
+	require.NoError(t, MVCCAcquireReplicatedLock(ctx, batch, nil, txn1, lock.Shared, testKey1))
+	require.True(t, batch.MayHaveReplicatedLocks())
+	require.True(t, src.MayHaveReplicatedLocks())
+	require.False(t, src.MayHaveRangeTombstones())
+
+	// Applying the batch on another engine, as on a follower, makes its writes
+	// check for the lock.
+	fs := vfs.NewMem()
+	eng := open(fs)
+	require.False(t, eng.MayHaveReplicatedLocks())
+	require.NoError(t, eng.ApplyBatchRepr(batch.Repr(), false /* sync */))
+	require.True(t, eng.MayHaveReplicatedLocks())
+	requireLockConflict(t, MVCCPut(ctx, eng, nil, testKey1, hlc.Timestamp{WallTime: 2}, value2, nil), testKey1, txn1)
+	eng.Close()
+
+	// The lock is found when the engine is reopened.
+	eng = open(fs)
+	require.True(t, eng.MayHaveReplicatedLocks())
+	eng.Close()
+
+	// Ingesting an sstable containing a lock, as for a snapshot.
+	eng = open(vfs.NewMem())
+	defer eng.Close()
+	memFile := &MemFile{}
+	sst := MakeIngestionSSTWriter(memFile)
+	defer sst.Close()
+	require.NoError(t, batch.MVCCIterate(keys.LocalReplicatedLockPrefix,
+		keys.LocalReplicatedLockPrefix.PrefixEnd(), MVCCKeyIterKind,
+		func(kv MVCCKeyValue) error {
+			return sst.Put(kv.Key, kv.Value)
+		}))
+	require.NoError(t, sst.Finish())
+	require.NoError(t, eng.WriteFile(`ingest`, memFile.Data()))
+	require.False(t, eng.MayHaveReplicatedLocks())
+	require.NoError(t, eng.IngestExternalFiles(ctx, []string{`ingest`}))
+	require.True(t, eng.MayHaveReplicatedLocks())
+	require.False(t, eng.MayHaveRangeTombstones())
+}
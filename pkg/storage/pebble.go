@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -448,6 +449,15 @@ type Pebble struct {
 
 	useWrappedIntentWriter bool
 	wrappedIntentWriter    intentDemuxWriter
+
+	// rangeTombstonesMayExist is set to 1 once the engine may contain MVCC
+	// range tombstone fragments, i.e. when it was opened with fragments present
+	// or when a fragment is written or ingested. It is never reset. Updated and
+	// retrieved atomically.
+	rangeTombstonesMayExist int32
+	// replicatedLocksMayExist is like rangeTombstonesMayExist, but for
+	// replicated locks.
+	replicatedLocksMayExist int32
 }
 
 var _ Engine = &Pebble{}
@@ -555,9 +565,96 @@ func NewPebble(ctx context.Context, cfg PebbleConfig) (*Pebble, error) {
 	}
 	p.db = db
 
+	if err := p.initKeysMayExist(); err != nil {
+		p.Close()
+		return nil, err
+	}
+
 	return p, nil
 }
 
+// initKeysMayExist sets rangeTombstonesMayExist and replicatedLocksMayExist
+// if the engine contains any MVCC range tombstone fragments or replicated
+// locks, respectively.
+func (p *Pebble) initKeysMayExist() error {
+	for _, prefix := range []roachpb.Key{
+		keys.LocalMVCCRangeTombstonePrefix, keys.LocalReplicatedLockPrefix,
+	} {
+		iter := p.NewEngineIterator(IterOptions{
+			LowerBound: prefix,
+			UpperBound: prefix.PrefixEnd(),
+		})
+		ok, err := iter.SeekEngineKeyGE(EngineKey{Key: prefix})
+		iter.Close()
+		if err != nil {
+			return err
+		}
+		if ok {
+			p.noteKeyWritten(prefix)
+		}
+	}
+	return nil
+}
+
+// noteKeyWritten records that the engine may contain MVCC range tombstone
+// fragments or replicated locks if the given key, which may be followed by the
+// rest of its engine key encoding, is one. It must be called before the key is
+// written.
+func (p *Pebble) noteKeyWritten(key []byte) {
+	if isMVCCRangeTombstoneKey(key) {
+		markMayExist(&p.rangeTombstonesMayExist)
+	} else if isReplicatedLockKey(key) {
+		markMayExist(&p.replicatedLocksMayExist)
+	}
+}
+
+// noteBatchWritten calls noteKeyWritten for the keys written by the batch,
+// stopping early once both kinds of keys may exist.
+func (p *Pebble) noteBatchWritten(batch *pebble.Batch) {
+	r := batch.Reader()
+	for !p.allKeysMayExist() {
+		kind, ukey, _, ok := r.Next()
+		if !ok {
+			return
+		}
+		switch kind {
+		case pebble.InternalKeyKindSet, pebble.InternalKeyKindMerge:
+			p.noteKeyWritten(ukey)
+		}
+	}
+}
+
+// noteSSTsIngested calls noteKeyWritten for the MVCC range tombstone fragments
+// and replicated locks in the sstables at the given paths.
+func (p *Pebble) noteSSTsIngested(paths []string) error {
+	for _, path := range paths {
+		if p.allKeysMayExist() {
+			return nil
+		}
+		for _, prefix := range []roachpb.Key{
+			keys.LocalMVCCRangeTombstonePrefix, keys.LocalReplicatedLockPrefix,
+		} {
+			if ok, err := sstHasKeyWithPrefix(p.fs, path, prefix); err != nil {
+				return err
+			} else if ok {
+				p.noteKeyWritten(prefix)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Pebble) allKeysMayExist() bool {
+	return atomic.LoadInt32(&p.rangeTombstonesMayExist) != 0 &&
+		atomic.LoadInt32(&p.replicatedLocksMayExist) != 0
+}
+
+func markMayExist(flag *int32) {
+	if atomic.LoadInt32(flag) == 0 {
+		atomic.StoreInt32(flag, 1)
+	}
+}
+
 func newPebbleInMem(
 	ctx context.Context, attrs roachpb.Attributes, cacheSize int64, settings *cluster.Settings,
 ) *Pebble {
@@ -728,6 +825,16 @@ func (p *Pebble) ConsistentIterators() bool {
 	return false
 }
 
+// MayHaveRangeTombstones implements the Engine interface.
+func (p *Pebble) MayHaveRangeTombstones() bool {
+	return atomic.LoadInt32(&p.rangeTombstonesMayExist) != 0
+}
+
+// MayHaveReplicatedLocks implements the Engine interface.
+func (p *Pebble) MayHaveReplicatedLocks() bool {
+	return atomic.LoadInt32(&p.replicatedLocksMayExist) != 0
+}
+
 // ApplyBatchRepr implements the Engine interface.
 func (p *Pebble) ApplyBatchRepr(repr []byte, sync bool) error {
 	// batch.SetRepr takes ownership of the underlying slice, so make a copy.
@@ -738,6 +845,7 @@ func (p *Pebble) ApplyBatchRepr(repr []byte, sync bool) error {
 	if err := batch.SetRepr(reprCopy); err != nil {
 		return err
 	}
+	p.noteBatchWritten(batch)
 
 	opts := pebble.NoSync
 	if sync {
@@ -871,6 +979,7 @@ func (p *Pebble) PutEngineKey(key EngineKey, value []byte) error {
 	if len(key.Key) == 0 {
 		return emptyKeyError()
 	}
+	p.noteKeyWritten(key.Key)
 	return p.db.Set(key.Encode(), value, pebble.Sync)
 }
 
@@ -878,6 +987,7 @@ func (p *Pebble) put(key MVCCKey, value []byte) error {
 	if len(key.Key) == 0 {
 		return emptyKeyError()
 	}
+	p.noteKeyWritten(key.Key)
 	return p.db.Set(EncodeKey(key), value, pebble.Sync)
 }
 
@@ -939,6 +1049,8 @@ func (p *Pebble) GetMetrics() (*Metrics, error) {
 		PendingCompactionBytesEstimate: int64(m.Compact.EstimatedDebt),
 		L0FileCount:                    m.Levels[0].NumFiles,
 		L0SublevelCount:                int64(m.Levels[0].Sublevels),
+		L0BytesIngested:                int64(m.Levels[0].BytesIngested),
+		L0Size:                         m.Levels[0].Size,
 		ReadAmplification:              int64(m.ReadAmp()),
 		NumSSTables:                    numSSTables,
 	}, nil
@@ -1037,7 +1149,7 @@ func (p *Pebble) GetAuxiliaryDir() string {
 
 // NewBatch implements the Engine interface.
 func (p *Pebble) NewBatch() Batch {
-	return newPebbleBatch(p.db, p.db.NewIndexedBatch(), false /* writeOnly */)
+	return newPebbleBatch(p, p.db.NewIndexedBatch(), false /* writeOnly */)
 }
 
 // NewReadOnly implements the Engine interface.
@@ -1049,12 +1161,13 @@ func (p *Pebble) NewReadOnly() ReadWriter {
 
 // NewUnindexedBatch implements the Engine interface.
 func (p *Pebble) NewUnindexedBatch(writeOnly bool) Batch {
-	return newPebbleBatch(p.db, p.db.NewBatch(), writeOnly)
+	return newPebbleBatch(p, p.db.NewBatch(), writeOnly)
 }
 
 // NewSnapshot implements the Engine interface.
 func (p *Pebble) NewSnapshot() Reader {
 	return &pebbleSnapshot{
+		parent:   p,
 		snapshot: p.db.NewSnapshot(),
 	}
 }
@@ -1066,6 +1179,9 @@ func (p *Pebble) Type() enginepb.EngineType {
 
 // IngestExternalFiles implements the Engine interface.
 func (p *Pebble) IngestExternalFiles(ctx context.Context, paths []string) error {
+	if err := p.noteSSTsIngested(paths); err != nil {
+		return err
+	}
 	return p.db.Ingest(paths)
 }
 
@@ -1374,6 +1490,16 @@ func (p *pebbleReadOnly) ConsistentIterators() bool {
 	return true
 }
 
+// MayHaveRangeTombstones implements the Engine interface.
+func (p *pebbleReadOnly) MayHaveRangeTombstones() bool {
+	return p.parent.MayHaveRangeTombstones()
+}
+
+// MayHaveReplicatedLocks implements the Engine interface.
+func (p *pebbleReadOnly) MayHaveReplicatedLocks() bool {
+	return p.parent.MayHaveReplicatedLocks()
+}
+
 // Writer methods are not implemented for pebbleReadOnly. Ideally, the code
 // could be refactored so that a Reader could be supplied to evaluateBatch
 
@@ -1456,6 +1582,7 @@ func (p *pebbleReadOnly) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalO
 
 // pebbleSnapshot represents a snapshot created using Pebble.NewSnapshot().
 type pebbleSnapshot struct {
+	parent   *Pebble
 	snapshot *pebble.Snapshot
 	closed   bool
 }
@@ -1546,6 +1673,16 @@ func (p pebbleSnapshot) ConsistentIterators() bool {
 	return true
 }
 
+// MayHaveRangeTombstones implements the Reader interface.
+func (p pebbleSnapshot) MayHaveRangeTombstones() bool {
+	return p.parent.MayHaveRangeTombstones()
+}
+
+// MayHaveReplicatedLocks implements the Reader interface.
+func (p pebbleSnapshot) MayHaveReplicatedLocks() bool {
+	return p.parent.MayHaveReplicatedLocks()
+}
+
 // pebbleGetProto uses Reader.MVCCGet, so it not as efficient as a function
 // that can unmarshal without copying bytes. But we don't care about
 // efficiency, since this is used to implement Reader.MVCCGetProto, which is
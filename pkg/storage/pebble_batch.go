@@ -23,9 +23,10 @@ import (
 
 // Wrapper struct around a pebble.Batch.
 type pebbleBatch struct {
-	db    *pebble.DB
-	batch *pebble.Batch
-	buf   []byte
+	parent *Pebble
+	db     *pebble.DB
+	batch  *pebble.Batch
+	buf    []byte
 	// The iterator reuse optimization in pebbleBatch is for servicing a
 	// BatchRequest, such that the iterators get reused across different
 	// requests in the batch.
@@ -62,12 +63,13 @@ var pebbleBatchPool = sync.Pool{
 }
 
 // Instantiates a new pebbleBatch.
-func newPebbleBatch(db *pebble.DB, batch *pebble.Batch, writeOnly bool) *pebbleBatch {
+func newPebbleBatch(parent *Pebble, batch *pebble.Batch, writeOnly bool) *pebbleBatch {
 	pb := pebbleBatchPool.Get().(*pebbleBatch)
 	*pb = pebbleBatch{
-		db:    db,
-		batch: batch,
-		buf:   pb.buf,
+		parent: parent,
+		db:     parent.db,
+		batch:  batch,
+		buf:    pb.buf,
 		prefixIter: pebbleIterator{
 			lowerBoundBuf: pb.prefixIter.lowerBoundBuf,
 			upperBoundBuf: pb.prefixIter.upperBoundBuf,
@@ -273,12 +275,26 @@ func (p *pebbleBatch) ConsistentIterators() bool {
 	return true
 }
 
+// MayHaveRangeTombstones implements the Batch interface.
+func (p *pebbleBatch) MayHaveRangeTombstones() bool {
+	// Writes of fragments to the batch are recorded in the parent, so this also
+	// accounts for the batch's own writes.
+	return p.parent.MayHaveRangeTombstones()
+}
+
+// MayHaveReplicatedLocks implements the Batch interface.
+func (p *pebbleBatch) MayHaveReplicatedLocks() bool {
+	// Like MayHaveRangeTombstones, this accounts for the batch's own writes.
+	return p.parent.MayHaveReplicatedLocks()
+}
+
 // NewMVCCIterator implements the Batch interface.
 func (p *pebbleBatch) ApplyBatchRepr(repr []byte, sync bool) error {
 	var batch pebble.Batch
 	if err := batch.SetRepr(repr); err != nil {
 		return err
 	}
+	p.parent.noteBatchWritten(&batch)
 
 	return p.batch.Apply(&batch, nil)
 }
@@ -435,6 +451,7 @@ func (p *pebbleBatch) PutEngineKey(key EngineKey, value []byte) error {
 	if len(key.Key) == 0 {
 		return emptyKeyError()
 	}
+	p.parent.noteKeyWritten(key.Key)
 
 	p.buf = key.EncodeToBuf(p.buf[:0])
 	return p.batch.Set(p.buf, value, nil)
@@ -444,6 +461,7 @@ func (p *pebbleBatch) put(key MVCCKey, value []byte) error {
 	if len(key.Key) == 0 {
 		return emptyKeyError()
 	}
+	p.parent.noteKeyWritten(key.Key)
 
 	p.buf = EncodeKeyToBuf(p.buf[:0], key)
 	return p.batch.Set(p.buf, value, nil)
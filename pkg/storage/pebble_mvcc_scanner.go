@@ -137,6 +137,11 @@ type pebbleMVCCScanner struct {
 	// Number of iterations to try before we do a Seek/SeekReverse. Stays within
 	// [1, maxItersBeforeSeek] and defaults to maxItersBeforeSeek/2 .
 	itersBeforeSeek int
+	// rangeTombstones are the MVCC range tombstone fragments overlapping the
+	// scanned span. Versions they delete are treated as deleted at the range
+	// tombstone's timestamp.
+	rangeTombstones   rangeTombstoneFragments
+	rangeTombstoneBuf []byte
 }
 
 // Pool for allocating pebble MVCC Scanners.
@@ -607,6 +612,33 @@ func (p *pebbleMVCCScanner) advanceKeyAtNewKey(key []byte) bool {
 // p.tombstones is true. Advances to the next key unless we've reached the max
 // results limit.
 func (p *pebbleMVCCScanner) addAndAdvance(rawKey []byte, val []byte) bool {
+	if len(p.rangeTombstones) > 0 && !p.curKey.Timestamp.IsEmpty() {
+		if f := p.rangeTombstones.find(p.curKey.Key); f != nil {
+			if ts, ok := f.newestIn(p.curKey.Timestamp, hlc.MaxTimestamp); ok && p.ts.LessEq(ts) &&
+				p.failOnMoreRecent {
+				// The version was deleted by a range tombstone at or above our read
+				// timestamp, which conflicts like a point deletion would. See case 4
+				// in getAndAdvance.
+				p.mostRecentTS.Forward(ts)
+				return p.advanceKey()
+			}
+			if ts, ok := f.newestIn(p.curKey.Timestamp, p.ts); ok {
+				// The version was deleted by a range tombstone at or below our read
+				// timestamp, so the key reads as a deletion at the range tombstone's
+				// timestamp.
+				rawKey = EncodeKeyToBuf(p.rangeTombstoneBuf[:0], MVCCKey{Key: p.curKey.Key, Timestamp: ts})
+				p.rangeTombstoneBuf = rawKey
+				val = nil
+			} else if p.checkUncertainty {
+				if ts, ok := f.newestIn(p.curKey.Timestamp, p.txn.MaxTimestamp); ok {
+					// The version was possibly deleted by a range tombstone in our
+					// uncertainty interval. See case 5 in getAndAdvance.
+					return p.uncertaintyError(ts)
+				}
+			}
+		}
+	}
+
 	// Don't include deleted versions len(val) == 0, unless we've been instructed
 	// to include tombstones in the results.
 	if len(val) > 0 || p.tombstones {
@@ -637,6 +637,25 @@ func (tc *TestCluster) AddNonVotersOrFatal(
 	return desc
 }
 
+// AddWitnesses adds witness replicas on the given targets to the range
+// containing startKey.
+func (tc *TestCluster) AddWitnesses(
+	startKey roachpb.Key, targets ...roachpb.ReplicationTarget,
+) (roachpb.RangeDescriptor, error) {
+	return tc.addReplica(startKey, roachpb.ADD_WITNESS, targets...)
+}
+
+// AddWitnessesOrFatal is like AddWitnesses, but fails the test on error.
+func (tc *TestCluster) AddWitnessesOrFatal(
+	t testing.TB, startKey roachpb.Key, targets ...roachpb.ReplicationTarget,
+) roachpb.RangeDescriptor {
+	desc, err := tc.addReplica(startKey, roachpb.ADD_WITNESS, targets...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return desc
+}
+
 // AddVotersMulti is part of TestClusterInterface.
 func (tc *TestCluster) AddVotersMulti(
 	kts ...serverutils.KeyAndTargets,
@@ -759,6 +778,14 @@ func (tc *TestCluster) RemoveNonVoters(
 	return tc.changeReplicas(roachpb.REMOVE_NON_VOTER, keys.MustAddr(startKey), targets...)
 }
 
+// RemoveWitnesses removes the witness replicas on the given targets from the
+// range containing startKey.
+func (tc *TestCluster) RemoveWitnesses(
+	startKey roachpb.Key, targets ...roachpb.ReplicationTarget,
+) (roachpb.RangeDescriptor, error) {
+	return tc.changeReplicas(roachpb.REMOVE_WITNESS, keys.MustAddr(startKey), targets...)
+}
+
 // RemoveNonVotersOrFatal is part of TestClusterInterface.
 func (tc *TestCluster) RemoveNonVotersOrFatal(
 	t testing.TB, startKey roachpb.Key, targets ...roachpb.ReplicationTarget,
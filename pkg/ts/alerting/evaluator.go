@@ -0,0 +1,193 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package alerting evaluates the user-defined alert rules stored in
+// system.alert_rules against the internal time series database, and reports
+// their state changes on the ALERTS logging channel.
+package alerting
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// Enabled controls whether the alert rules are evaluated.
+var Enabled = settings.RegisterBoolSetting(
+	"timeseries.alerting.enabled",
+	"if set, the alert rules in system.alert_rules are periodically evaluated "+
+		"against the internal time series database",
+	true,
+).WithPublic()
+
+// EvaluationInterval is the interval at which the alert rules are evaluated.
+var EvaluationInterval = settings.RegisterDurationSetting(
+	"timeseries.alerting.evaluation_interval",
+	"the interval at which the alert rules in system.alert_rules are evaluated",
+	10*time.Second,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+).WithPublic()
+
+// lookback is how far back in time the time series are queried. The rules
+// are evaluated against the most recent datapoint in that window, which
+// spans a few samples so that a late sample does not resolve a firing alert.
+const lookback = time.Minute
+
+// sampleDuration is the sample period of the time series queries, which is
+// the resolution at which the time series are recorded.
+const sampleDuration = 10 * time.Second
+
+// Querier queries the time series database. It is implemented by ts.Server.
+type Querier interface {
+	Query(context.Context, *tspb.TimeSeriesQueryRequest) (*tspb.TimeSeriesQueryResponse, error)
+}
+
+// Evaluator periodically evaluates the alert rules.
+type Evaluator struct {
+	st      *cluster.Settings
+	ie      sqlutil.InternalExecutor
+	querier Querier
+	// shouldEvaluate indicates whether this node is the one evaluating the
+	// rules, so that each state change is only reported once per cluster.
+	shouldEvaluate func(context.Context) bool
+
+	// invalid records the validation error of each invalid rule, so that
+	// each error is only logged once.
+	invalid map[string]string
+}
+
+// NewEvaluator creates an Evaluator. The shouldEvaluate function is called
+// before each evaluation, and should only return true on a single node of
+// the cluster at a time.
+func NewEvaluator(
+	st *cluster.Settings,
+	ie sqlutil.InternalExecutor,
+	querier Querier,
+	shouldEvaluate func(context.Context) bool,
+) *Evaluator {
+	return &Evaluator{
+		st:             st,
+		ie:             ie,
+		querier:        querier,
+		shouldEvaluate: shouldEvaluate,
+		invalid:        make(map[string]string),
+	}
+}
+
+// Start spawns a loop that periodically evaluates the alert rules.
+func (e *Evaluator) Start(ctx context.Context, stopper *stop.Stopper) {
+	_ = stopper.RunAsyncTask(ctx, "alert-rule-evaluator", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(EvaluationInterval.Get(&e.st.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if !Enabled.Get(&e.st.SV) || !e.shouldEvaluate(ctx) {
+				continue
+			}
+			if err := e.Evaluate(ctx, timeutil.Now()); err != nil {
+				log.Warningf(ctx, "failed to evaluate alert rules: %v", err)
+			}
+		}
+	})
+}
+
+// Evaluate evaluates every alert rule at the given time, persists the state
+// changes and reports them on the ALERTS channel. A rule which cannot be
+// evaluated keeps its current state.
+func (e *Evaluator) Evaluate(ctx context.Context, now time.Time) error {
+	if !e.st.Version.IsActive(ctx, clusterversion.AlertRulesTable) {
+		return nil
+	}
+	rules, err := LoadRules(ctx, e.ie)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]struct{}, len(rules))
+	for i := range rules {
+		r := &rules[i]
+		seen[r.Name] = struct{}{}
+		if err := r.Validate(); err != nil {
+			if e.invalid[r.Name] != err.Error() {
+				e.invalid[r.Name] = err.Error()
+				log.Warningf(ctx, "not evaluating invalid alert rule %q: %v", r.Name, err)
+			}
+			continue
+		}
+		delete(e.invalid, r.Name)
+
+		value, ok, err := e.latestValue(ctx, r, now)
+		if err != nil {
+			log.Warningf(ctx, "failed to evaluate alert rule %q: %v", r.Name, err)
+			continue
+		}
+		if !ok {
+			// In the absence of data, the condition does not hold, but the
+			// last known value is retained.
+			value = r.LastValue
+		}
+		changed, ev := r.transition(now, value, ok && operators[r.Operator](value, r.Threshold))
+		if !changed {
+			continue
+		}
+		if err := saveState(ctx, e.ie, r); err != nil {
+			return errors.Wrapf(err, "saving state of alert rule %q", r.Name)
+		}
+		if ev != nil {
+			log.StructuredEvent(ctx, ev)
+		}
+	}
+	for name := range e.invalid {
+		if _, ok := seen[name]; !ok {
+			delete(e.invalid, name)
+		}
+	}
+	return nil
+}
+
+// latestValue returns the most recent value of the time series of the rule
+// at the given time. The boolean return value is false if there is no data.
+func (e *Evaluator) latestValue(ctx context.Context, r *Rule, now time.Time) (float64, bool, error) {
+	resp, err := e.querier.Query(ctx, &tspb.TimeSeriesQueryRequest{
+		StartNanos:  now.Add(-lookback).UnixNano(),
+		EndNanos:    now.UnixNano(),
+		Queries:     []tspb.Query{r.query()},
+		SampleNanos: sampleDuration.Nanoseconds(),
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if len(resp.Results) == 0 || len(resp.Results[0].Datapoints) == 0 {
+		return 0, false, nil
+	}
+	datapoints := resp.Results[0].Datapoints
+	return datapoints[len(datapoints)-1].Value, true, nil
+}
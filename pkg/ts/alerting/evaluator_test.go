@@ -0,0 +1,233 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package alerting_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/ts/alerting"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/redact"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQuerier returns a single datapoint with the configured value of each
+// metric, and no data for the other metrics.
+type fakeQuerier struct {
+	syncutil.Mutex
+	values map[string]float64
+}
+
+func (q *fakeQuerier) set(metric string, value float64) {
+	q.Lock()
+	defer q.Unlock()
+	q.values[metric] = value
+}
+
+func (q *fakeQuerier) clear(metric string) {
+	q.Lock()
+	defer q.Unlock()
+	delete(q.values, metric)
+}
+
+func (q *fakeQuerier) Query(
+	_ context.Context, req *tspb.TimeSeriesQueryRequest,
+) (*tspb.TimeSeriesQueryResponse, error) {
+	q.Lock()
+	defer q.Unlock()
+	resp := &tspb.TimeSeriesQueryResponse{}
+	for _, query := range req.Queries {
+		result := tspb.TimeSeriesQueryResponse_Result{Query: query}
+		if v, ok := q.values[query.Name]; ok {
+			result.Datapoints = []tspb.TimeSeriesDatapoint{{TimestampNanos: req.EndNanos, Value: v}}
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+// alertEvent is the subset of the alerting events checked by the test.
+type alertEvent struct {
+	EventType      string
+	RuleName       string
+	Condition      string
+	Severity       string
+	Value          float64
+	ActiveDuration int64
+}
+
+func TestEvaluator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	// The rules are evaluated explicitly below.
+	alerting.Enabled.Override(&st.SV, false)
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Settings: st})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	var events struct {
+		syncutil.Mutex
+		events []alertEvent
+	}
+	log.Intercept(ctx, func(entry logpb.Entry) {
+		if entry.Channel != channel.ALERTS {
+			return
+		}
+		var ev alertEvent
+		msg := redact.RedactableString(entry.Message[strings.IndexByte(entry.Message, '{'):])
+		if err := json.Unmarshal([]byte(msg.StripMarkers()), &ev); err != nil {
+			t.Error(err)
+		}
+		events.Lock()
+		defer events.Unlock()
+		events.events = append(events.events, ev)
+	})
+	defer log.Intercept(ctx, nil)
+	takeEvents := func() []alertEvent {
+		events.Lock()
+		defer events.Unlock()
+		res := events.events
+		events.events = nil
+		return res
+	}
+
+	sqlDB.Exec(t, `INSERT INTO system.alert_rules (name, metric, threshold, duration, severity)
+VALUES ('high_conns', 'cr.node.sql.conns', 10, '30s', 'critical')`)
+	sqlDB.Exec(t, `INSERT INTO system.alert_rules (name, metric, aggregator, operator, threshold)
+VALUES ('low_capacity', 'cr.store.capacity.available', 'min', '<', 5)`)
+	sqlDB.Exec(t, `INSERT INTO system.alert_rules (name, metric, aggregator, threshold)
+VALUES ('invalid', 'cr.node.sql.conns', 'median', 10)`)
+
+	q := &fakeQuerier{values: make(map[string]float64)}
+	ev := alerting.NewEvaluator(st, s.InternalExecutor().(sqlutil.InternalExecutor), q,
+		func(context.Context) bool { return true })
+	states := func() [][]string {
+		return sqlDB.QueryStr(t, `SELECT name, state, last_value FROM system.alert_rules ORDER BY name`)
+	}
+
+	t0 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	q.set("cr.node.sql.conns", 20)
+	q.set("cr.store.capacity.available", 1)
+	require.NoError(t, ev.Evaluate(ctx, t0))
+	// The rule without a duration fires immediately, the other one only
+	// becomes pending.
+	require.Equal(t, [][]string{
+		{"high_conns", "pending", "20"},
+		{"invalid", "inactive", "NULL"},
+		{"low_capacity", "firing", "1"},
+	}, states())
+	require.Equal(t, []alertEvent{{
+		EventType: "alert_firing",
+		RuleName:  "low_capacity",
+		Condition: "min(cr.store.capacity.available) < 5",
+		Severity:  "warning",
+		Value:     1,
+	}}, takeEvents())
+
+	require.NoError(t, ev.Evaluate(ctx, t0.Add(20*time.Second)))
+	require.Equal(t, "pending", states()[0][1])
+	require.Empty(t, takeEvents())
+
+	q.set("cr.node.sql.conns", 30)
+	require.NoError(t, ev.Evaluate(ctx, t0.Add(30*time.Second)))
+	require.Equal(t, []string{"high_conns", "firing", "30"}, states()[0])
+	require.Equal(t, []alertEvent{{
+		EventType: "alert_firing",
+		RuleName:  "high_conns",
+		Condition: "avg(cr.node.sql.conns) > 10",
+		Severity:  "critical",
+		Value:     30,
+	}}, takeEvents())
+
+	// A rule is resolved when its condition does not hold any more, or when
+	// there is no data.
+	q.set("cr.node.sql.conns", 5)
+	q.clear("cr.store.capacity.available")
+	require.NoError(t, ev.Evaluate(ctx, t0.Add(50*time.Second)))
+	require.Equal(t, [][]string{
+		{"high_conns", "inactive", "5"},
+		{"invalid", "inactive", "NULL"},
+		{"low_capacity", "inactive", "1"},
+	}, states())
+	require.ElementsMatch(t, []alertEvent{{
+		EventType:      "alert_resolved",
+		RuleName:       "high_conns",
+		Condition:      "avg(cr.node.sql.conns) > 10",
+		Severity:       "critical",
+		Value:          5,
+		ActiveDuration: (50 * time.Second).Nanoseconds(),
+	}, {
+		EventType:      "alert_resolved",
+		RuleName:       "low_capacity",
+		Condition:      "min(cr.store.capacity.available) < 5",
+		Severity:       "warning",
+		Value:          1,
+		ActiveDuration: (50 * time.Second).Nanoseconds(),
+	}}, takeEvents())
+
+	// A pending rule goes back to inactive without reporting anything.
+	q.set("cr.node.sql.conns", 20)
+	require.NoError(t, ev.Evaluate(ctx, t0.Add(60*time.Second)))
+	q.set("cr.node.sql.conns", 0)
+	require.NoError(t, ev.Evaluate(ctx, t0.Add(70*time.Second)))
+	require.Equal(t, []string{"high_conns", "inactive", "0"}, states()[0])
+	require.Empty(t, takeEvents())
+}
+
+func TestRuleValidate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	valid := alerting.Rule{
+		Name:       "r",
+		Metric:     "cr.node.sql.conns",
+		Aggregator: "avg",
+		Operator:   ">",
+		Threshold:  10,
+		Severity:   "warning",
+	}
+	require.NoError(t, valid.Validate())
+	require.Equal(t, "avg(cr.node.sql.conns) > 10", valid.Condition())
+	valid.Rate = true
+	require.Equal(t, "avg(rate(cr.node.sql.conns)) > 10", valid.Condition())
+
+	for _, tc := range []struct {
+		mutate func(r *alerting.Rule)
+		err    string
+	}{
+		{func(r *alerting.Rule) { r.Metric = "" }, "metric must not be empty"},
+		{func(r *alerting.Rule) { r.Aggregator = "median" }, `unknown aggregator "median"`},
+		{func(r *alerting.Rule) { r.Operator = "~" }, `unknown operator "~"`},
+		{func(r *alerting.Rule) { r.Duration = -time.Second }, "duration must not be negative"},
+		{func(r *alerting.Rule) { r.Severity = "fatal" }, `unknown severity "fatal"`},
+	} {
+		r := valid
+		tc.mutate(&r)
+		require.Error(t, r.Validate())
+		require.Contains(t, r.Validate().Error(), tc.err)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+// State is the evaluation state of an alert rule.
+type State string
+
+const (
+	// StateInactive is the state of a rule whose condition does not hold.
+	StateInactive State = "inactive"
+	// StatePending is the state of a rule whose condition holds, but has not
+	// held for the duration configured for the rule yet.
+	StatePending State = "pending"
+	// StateFiring is the state of a rule whose condition has held for at
+	// least the duration configured for the rule.
+	StateFiring State = "firing"
+)
+
+// aggregators maps the aggregators accepted in system.alert_rules to the
+// time series aggregator used both to downsample the datapoints of each
+// source and to combine the sources.
+var aggregators = map[string]tspb.TimeSeriesQueryAggregator{
+	"avg": tspb.TimeSeriesQueryAggregator_AVG,
+	"sum": tspb.TimeSeriesQueryAggregator_SUM,
+	"max": tspb.TimeSeriesQueryAggregator_MAX,
+	"min": tspb.TimeSeriesQueryAggregator_MIN,
+}
+
+// operators maps the operators accepted in system.alert_rules to the
+// comparison of a metric value against the threshold of a rule.
+var operators = map[string]func(value, threshold float64) bool{
+	">":  func(v, t float64) bool { return v > t },
+	">=": func(v, t float64) bool { return v >= t },
+	"<":  func(v, t float64) bool { return v < t },
+	"<=": func(v, t float64) bool { return v <= t },
+	"=":  func(v, t float64) bool { return v == t },
+	"!=": func(v, t float64) bool { return v != t },
+}
+
+// severities lists the severities accepted in system.alert_rules.
+var severities = map[string]struct{}{
+	"info":     {},
+	"warning":  {},
+	"critical": {},
+}
+
+// Rule is an alert rule, as stored in system.alert_rules.
+type Rule struct {
+	// Name uniquely identifies the rule.
+	Name string
+	// Metric is the name of the time series, for example cr.node.sql.conns.
+	Metric string
+	// Aggregator is the function used to combine the sources of the
+	// time series.
+	Aggregator string
+	// Rate, if set, evaluates the rule against the per-second rate of change
+	// of the time series instead of its value.
+	Rate bool
+	// Operator compares the value of the time series against Threshold.
+	Operator  string
+	Threshold float64
+	// Duration is how long the condition must hold before the rule fires.
+	Duration time.Duration
+	// Severity is reported along with the state changes of the rule.
+	Severity string
+
+	// State is the current evaluation state of the rule.
+	State State
+	// ActiveSince is the time at which the condition of the rule started to
+	// hold. It is zero if the rule is inactive.
+	ActiveSince time.Time
+	// LastValue is the value of the time series at the last state change.
+	LastValue float64
+}
+
+// Validate returns an error if the rule cannot be evaluated.
+func (r *Rule) Validate() error {
+	if r.Metric == "" {
+		return errors.New("metric must not be empty")
+	}
+	if _, ok := aggregators[r.Aggregator]; !ok {
+		return errors.Newf("unknown aggregator %q", r.Aggregator)
+	}
+	if _, ok := operators[r.Operator]; !ok {
+		return errors.Newf("unknown operator %q", r.Operator)
+	}
+	if r.Duration < 0 {
+		return errors.Newf("duration must not be negative: %s", r.Duration)
+	}
+	if _, ok := severities[r.Severity]; !ok {
+		return errors.Newf("unknown severity %q", r.Severity)
+	}
+	return nil
+}
+
+// Condition returns a textual representation of the condition of the rule,
+// for example avg(cr.node.sql.conns) > 100.
+func (r *Rule) Condition() string {
+	metric := r.Metric
+	if r.Rate {
+		metric = fmt.Sprintf("rate(%s)", metric)
+	}
+	return fmt.Sprintf("%s(%s) %s %g", r.Aggregator, metric, r.Operator, r.Threshold)
+}
+
+// query returns the time series query that the rule is evaluated against.
+func (r *Rule) query() tspb.Query {
+	agg := aggregators[r.Aggregator]
+	q := tspb.Query{
+		Name:             r.Metric,
+		Downsampler:      agg.Enum(),
+		SourceAggregator: agg.Enum(),
+	}
+	if r.Rate {
+		q.Derivative = tspb.TimeSeriesQueryDerivative_NON_NEGATIVE_DERIVATIVE.Enum()
+	}
+	return q
+}
+
+// transition advances the state of the rule, given whether its condition
+// held at the given time. It returns whether the state changed, along with
+// the event to report for that change, if any.
+func (r *Rule) transition(now time.Time, value float64, holds bool) (bool, eventpb.EventPayload) {
+	switch {
+	case holds && r.State == StateInactive:
+		r.ActiveSince = now
+		r.LastValue = value
+		r.State = StatePending
+		if r.Duration > 0 {
+			return true, nil
+		}
+		r.State = StateFiring
+		return true, r.firingEvent(now)
+
+	case holds && r.State == StatePending:
+		if now.Sub(r.ActiveSince) < r.Duration {
+			return false, nil
+		}
+		r.LastValue = value
+		r.State = StateFiring
+		return true, r.firingEvent(now)
+
+	case !holds && r.State == StatePending:
+		r.ActiveSince = time.Time{}
+		r.LastValue = value
+		r.State = StateInactive
+		return true, nil
+
+	case !holds && r.State == StateFiring:
+		ev := &eventpb.AlertResolved{
+			CommonEventDetails:      eventpb.CommonEventDetails{Timestamp: now.UnixNano()},
+			CommonAlertEventDetails: r.eventDetails(value),
+			ActiveDuration:          now.Sub(r.ActiveSince).Nanoseconds(),
+		}
+		r.ActiveSince = time.Time{}
+		r.LastValue = value
+		r.State = StateInactive
+		return true, ev
+	}
+	return false, nil
+}
+
+func (r *Rule) firingEvent(now time.Time) eventpb.EventPayload {
+	return &eventpb.AlertFiring{
+		CommonEventDetails:      eventpb.CommonEventDetails{Timestamp: now.UnixNano()},
+		CommonAlertEventDetails: r.eventDetails(r.LastValue),
+		ActiveSince:             r.ActiveSince.UnixNano(),
+	}
+}
+
+func (r *Rule) eventDetails(value float64) eventpb.CommonAlertEventDetails {
+	return eventpb.CommonAlertEventDetails{
+		RuleName:  r.Name,
+		Metric:    r.Metric,
+		Condition: r.Condition(),
+		Severity:  r.Severity,
+		Value:     value,
+	}
+}
+
+// LoadRules reads the alert rules from system.alert_rules, ordered by name.
+func LoadRules(ctx context.Context, ie sqlutil.InternalExecutor) ([]Rule, error) {
+	rows, err := ie.QueryEx(ctx, "load-alert-rules", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT name, metric, aggregator, rate, operator, threshold, duration, severity,
+       state, active_since, last_value
+  FROM system.alert_rules
+ ORDER BY name`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]Rule, len(rows))
+	for i, row := range rows {
+		r := &rules[i]
+		r.Name = string(tree.MustBeDString(row[0]))
+		r.Metric = string(tree.MustBeDString(row[1]))
+		r.Aggregator = string(tree.MustBeDString(row[2]))
+		r.Rate = bool(tree.MustBeDBool(row[3]))
+		r.Operator = string(tree.MustBeDString(row[4]))
+		r.Threshold = float64(tree.MustBeDFloat(row[5]))
+		r.Duration = time.Duration(row[6].(*tree.DInterval).AsFloat64() * float64(time.Second))
+		r.Severity = string(tree.MustBeDString(row[7]))
+		r.State = State(tree.MustBeDString(row[8]))
+		if ts, ok := row[9].(*tree.DTimestampTZ); ok {
+			r.ActiveSince = ts.Time
+		}
+		if v, ok := row[10].(*tree.DFloat); ok {
+			r.LastValue = float64(*v)
+		}
+	}
+	return rules, nil
+}
+
+// saveState persists the evaluation state of the rule.
+func saveState(ctx context.Context, ie sqlutil.InternalExecutor, r *Rule) error {
+	var activeSince interface{}
+	if !r.ActiveSince.IsZero() {
+		activeSince = r.ActiveSince
+	}
+	_, err := ie.ExecEx(ctx, "save-alert-rule-state", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`UPDATE system.alert_rules SET state = $2, active_since = $3, last_value = $4 WHERE name = $1`,
+		r.Name, string(r.State), activeSince, r.LastValue,
+	)
+	return err
+}
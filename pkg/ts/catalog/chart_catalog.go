@@ -297,6 +297,7 @@ var charts = []sectionDescription{
 					"distsender.rpc.deleterange.sent",
 					"distsender.rpc.endtxn.sent",
 					"distsender.rpc.export.sent",
+					"distsender.rpc.fingerprint.sent",
 					"distsender.rpc.gc.sent",
 					"distsender.rpc.get.sent",
 					"distsender.rpc.heartbeattxn.sent",
@@ -309,6 +310,7 @@ var charts = []sectionDescription{
 					"distsender.rpc.pushtxn.sent",
 					"distsender.rpc.put.sent",
 					"distsender.rpc.queryintent.sent",
+					"distsender.rpc.queryresolvedtimestamp.sent",
 					"distsender.rpc.querytxn.sent",
 					"distsender.rpc.rangestats.sent",
 					"distsender.rpc.recomputestats.sent",
@@ -497,6 +499,7 @@ var charts = []sectionDescription{
 					"range.snapshots.applied-voter",
 					"range.snapshots.applied-initial",
 					"range.snapshots.applied-non-voter",
+					"range.snapshots.applied-witness",
 				},
 			},
 		},
@@ -534,6 +537,14 @@ var charts = []sectionDescription{
 				Title:   "QPS",
 				Metrics: []string{"rebalancing.queriespersecond"},
 			},
+			{
+				Title:   "Eval Nanos/Sec",
+				Metrics: []string{"rebalancing.evalnanospersecond"},
+			},
+			{
+				Title:   "Write Bytes/Sec",
+				Metrics: []string{"rebalancing.writebytespersecond"},
+			},
 		},
 	},
 	{
@@ -773,6 +784,79 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{
+			{KVTransactionLayer, "Requests", "Admission Control"}},
+		Charts: []chartDescription{
+			{
+				Title:       "Requests Subject to Admission Control",
+				Downsampler: DescribeAggregator_MAX,
+				Rate:        DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+				Percentiles: false,
+				Metrics: []string{
+					"admission.requested.kv",
+					"admission.requested.sql_kv_response",
+				},
+			},
+			{
+				Title:       "Requests Admitted",
+				Downsampler: DescribeAggregator_MAX,
+				Rate:        DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+				Percentiles: false,
+				Metrics: []string{
+					"admission.admitted.kv",
+					"admission.admitted.sql_kv_response",
+				},
+			},
+			{
+				Title:       "Requests Not Admitted Due to Error",
+				Downsampler: DescribeAggregator_MAX,
+				Rate:        DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+				Percentiles: false,
+				Metrics: []string{
+					"admission.errored.kv",
+					"admission.errored.sql_kv_response",
+				},
+			},
+			{
+				Title:       "KV Admission Wait Durations",
+				Downsampler: DescribeAggregator_MAX,
+				Percentiles: true,
+				Metrics:     []string{"admission.wait_durations.kv"},
+			},
+			{
+				Title:       "SQL KV Response Admission Wait Durations",
+				Downsampler: DescribeAggregator_MAX,
+				Percentiles: true,
+				Metrics:     []string{"admission.wait_durations.sql_kv_response"},
+			},
+			{
+				Title:       "Admission Wait Queue Length",
+				Downsampler: DescribeAggregator_MAX,
+				Percentiles: false,
+				Metrics: []string{
+					"admission.wait_queue_length.kv",
+					"admission.wait_queue_length.sql_kv_response",
+				},
+			},
+			{
+				Title:       "KV Admission Slots",
+				Downsampler: DescribeAggregator_MAX,
+				Percentiles: false,
+				Metrics: []string{
+					"admission.granter.total_slots.kv",
+					"admission.granter.used_slots.kv",
+				},
+			},
+			{
+				Title:       "KV Admission IO Tokens Exhausted Duration",
+				Downsampler: DescribeAggregator_MAX,
+				Rate:        DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+				Percentiles: false,
+				Metrics:     []string{"admission.granter.io_tokens_exhausted_duration.kv"},
+			},
+		},
+	},
 	{
 		Organization: [][]string{
 			{KVTransactionLayer, "Requests", "Slow"},
@@ -2313,6 +2397,7 @@ var charts = []sectionDescription{
 					"jobs.backup.currently_running",
 					"jobs.changefeed.currently_running",
 					"jobs.create_stats.currently_running",
+					"jobs.fingerprint_check.currently_running",
 					"jobs.import.currently_running",
 					"jobs.restore.currently_running",
 					"jobs.schema_change.currently_running",
@@ -2370,6 +2455,18 @@ var charts = []sectionDescription{
 				},
 				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
 			},
+			{
+				Title: "Fingerprint Check",
+				Metrics: []string{
+					"jobs.fingerprint_check.fail_or_cancel_completed",
+					"jobs.fingerprint_check.fail_or_cancel_failed",
+					"jobs.fingerprint_check.fail_or_cancel_retry_error",
+					"jobs.fingerprint_check.resume_completed",
+					"jobs.fingerprint_check.resume_failed",
+					"jobs.fingerprint_check.resume_retry_error",
+				},
+				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+			},
 			{
 				Title: "Import",
 				Metrics: []string{
@@ -0,0 +1,171 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ts
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+)
+
+// estimatedHistogramBucketsPerSource is the number of non-empty buckets
+// which a histogram is assumed to have when estimating the memory needed to
+// compute its percentiles. It is the number of buckets covering the values
+// of latency histograms, the most common ones, from 1ns to MaxLatency.
+var estimatedHistogramBucketsPerSource = int64(tspb.HistogramBucket(metric.MaxLatency.Nanoseconds()) + 1)
+
+func verifyPercentile(percentile float64) error {
+	if !(percentile > 0 && percentile <= 100) {
+		return errors.Errorf("percentile %g is not in the range (0, 100]", percentile)
+	}
+	return nil
+}
+
+// makeHistogramBucketsQuery returns the query reading the bucket counts of
+// the histogram whose percentile is requested by the given query. Each bucket
+// count is a counter; the query computes the rate at which values were
+// recorded in each bucket, which can then be summed across sources.
+func makeHistogramBucketsQuery(query tspb.Query) tspb.Query {
+	query.Name = tspb.HistogramBucketsName(query.Name)
+	query.Downsampler = tspb.TimeSeriesQueryAggregator_MAX.Enum()
+	query.SourceAggregator = tspb.TimeSeriesQueryAggregator_SUM.Enum()
+	query.Derivative = tspb.TimeSeriesQueryDerivative_NON_NEGATIVE_DERIVATIVE.Enum()
+	return query
+}
+
+// aggregateHistogramSpansToDatapoints computes the requested percentile of a
+// histogram from the spans of its bucket counts, as read by a query built by
+// makeHistogramBucketsQuery. The rates of each bucket are aggregated across
+// the queried sources, and the percentile is then computed at each timestamp
+// from the aggregated rates of all buckets. The sources of the histogram,
+// rather than those of its buckets, are added to the supplied source set.
+func aggregateHistogramSpansToDatapoints(
+	ctx context.Context,
+	spans map[string]timeSeriesSpan,
+	query tspb.Query,
+	timespan QueryTimespan,
+	mem QueryMemoryContext,
+	acc *mon.BoundAccount,
+	dest *[]tspb.TimeSeriesDatapoint,
+	sourceSet map[string]struct{},
+) error {
+	var querySources map[string]struct{}
+	if len(query.Sources) > 0 {
+		querySources = make(map[string]struct{}, len(query.Sources))
+		for _, source := range query.Sources {
+			querySources[source] = struct{}{}
+		}
+	}
+
+	// Group the spans by bucket, skipping sources which are not queried.
+	bucketSpans := make(map[int]map[string]timeSeriesSpan)
+	for s, span := range spans {
+		source, bucket, ok := tspb.DecodeHistogramBucketSource(s)
+		if !ok {
+			continue
+		}
+		if querySources != nil {
+			if _, ok := querySources[source]; !ok {
+				continue
+			}
+		}
+		if bucketSpans[bucket] == nil {
+			bucketSpans[bucket] = make(map[string]timeSeriesSpan)
+		}
+		bucketSpans[bucket][source] = span
+		sourceSet[source] = struct{}{}
+	}
+	buckets := make([]int, 0, len(bucketSpans))
+	for bucket := range bucketSpans {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+
+	// Compute the rate of each bucket across all sources.
+	rates := make([][]tspb.TimeSeriesDatapoint, len(buckets))
+	for i, bucket := range buckets {
+		aggregateSpansToDatapoints(
+			bucketSpans[bucket], query, timespan, mem.InterpolationLimitNanos, &rates[i],
+		)
+		if err := acc.Grow(ctx, sizeOfDataPoint*int64(cap(rates[i]))); err != nil {
+			return err
+		}
+	}
+
+	// Compute the percentile at each timestamp for which any bucket has a rate,
+	// increasing our memory usage if the destination slice is expanded.
+	oldCap := cap(*dest)
+	next := make([]int, len(rates))
+	counts := make([]float64, len(rates))
+	for {
+		lowestTimestamp := int64(math.MaxInt64)
+		for i := range rates {
+			if next[i] < len(rates[i]) && rates[i][next[i]].TimestampNanos < lowestTimestamp {
+				lowestTimestamp = rates[i][next[i]].TimestampNanos
+			}
+		}
+		if lowestTimestamp == math.MaxInt64 {
+			break
+		}
+		for i := range rates {
+			counts[i] = 0
+			if next[i] < len(rates[i]) && rates[i][next[i]].TimestampNanos == lowestTimestamp {
+				counts[i] = rates[i][next[i]].Value
+				next[i]++
+			}
+		}
+		if value, ok := histogramPercentile(buckets, counts, query.GetPercentile()); ok {
+			*dest = append(*dest, tspb.TimeSeriesDatapoint{
+				TimestampNanos: lowestTimestamp,
+				Value:          value,
+			})
+		}
+	}
+	if cap(*dest) > oldCap {
+		if err := mem.resultAccount.Grow(ctx, sizeOfDataPoint*int64(cap(*dest)-oldCap)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// histogramPercentile returns the given percentile of the values counted in
+// the given buckets, which must be sorted. The value is interpolated linearly
+// within the bucket holding it. It returns false if no values were counted.
+func histogramPercentile(buckets []int, counts []float64, percentile float64) (float64, bool) {
+	total := aggSum(counts)
+	if total <= 0 {
+		return 0, false
+	}
+	rank := total * percentile / 100
+	last := -1
+	cumulative := 0.0
+	for i, count := range counts {
+		if count <= 0 {
+			continue
+		}
+		last = i
+		if cumulative+count >= rank {
+			lower := tspb.HistogramBucketLowerBound(buckets[i])
+			upper := tspb.HistogramBucketUpperBound(buckets[i])
+			return lower + (upper-lower)*(rank-cumulative)/count, true
+		}
+		cumulative += count
+	}
+	// Rounding errors can leave the rank slightly above the cumulative count
+	// of the last bucket.
+	return tspb.HistogramBucketUpperBound(buckets[last]), true
+}
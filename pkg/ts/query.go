@@ -459,6 +459,12 @@ func (db *DB) Query(
 	if err := verifyDownsampler(query.GetDownsampler()); err != nil {
 		return nil, nil, err
 	}
+	if query.Percentile != nil {
+		if err := verifyPercentile(query.GetPercentile()); err != nil {
+			return nil, nil, err
+		}
+		query = makeHistogramBucketsQuery(query)
+	}
 
 	// Adjust timespan based on the current time.
 	if err := timespan.adjustForCurrentTime(diskResolution); err != nil {
@@ -551,7 +557,9 @@ func (db *DB) queryChunk(
 
 	var data []kv.KeyValue
 	var err error
-	if len(query.Sources) == 0 {
+	// The sources of the bucket counts of a histogram are not known in advance,
+	// so all of them are read.
+	if len(query.Sources) == 0 || query.Percentile != nil {
 		data, err = db.readAllSourcesFromDatabase(ctx, query.Name, diskResolution, diskTimespan)
 	} else {
 		data, err = db.readFromDatabase(ctx, query.Name, diskResolution, diskTimespan, query.Sources)
@@ -581,6 +589,12 @@ func (db *DB) queryChunk(
 		query.Downsampler = tspb.TimeSeriesQueryAggregator_SUM.Enum()
 	}
 
+	if query.Percentile != nil {
+		return aggregateHistogramSpansToDatapoints(
+			ctx, sourceSpans, query, timespan, mem, &acc, dest, sourceSet,
+		)
+	}
+
 	// Aggregate spans, increasing our memory usage if the destination slice is
 	// expanded.
 	oldCap := cap(*dest)
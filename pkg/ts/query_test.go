@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/ts/testmodel"
 	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -698,3 +699,87 @@ func TestQueryRollup(t *testing.T) {
 		query.assertSuccess(13, 2)
 	}
 }
+
+// TestQueryHistogramPercentile verifies that percentiles of histograms are
+// computed from the bucket counts recorded by all sources.
+func TestQueryHistogramPercentile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, v := range []int64{1, 2, 3, 4, 5, 1000, 1 << 40} {
+		b := tspb.HistogramBucket(v)
+		if lower, upper := tspb.HistogramBucketLowerBound(b), tspb.HistogramBucketUpperBound(b); !(lower < float64(v) && float64(v) <= upper) {
+			t.Errorf("value %d is not in bucket %d (%g, %g]", v, b, lower, upper)
+		}
+	}
+
+	runTestCaseMultipleFormats(t, func(t *testing.T, tm testModelRunner) {
+		// Source 1 records 10 values per sample period in bucket 4, while source
+		// 2 records 10 and then 30 values in bucket 8.
+		name := tspb.HistogramBucketsName("metric.histogram")
+		tm.storeTimeSeriesData(resolution1ns, []tspb.TimeSeriesData{
+			tsd(name, tspb.MakeHistogramBucketSource("1", 4),
+				tsdp(1, 10),
+				tsdp(2, 20),
+				tsdp(3, 30),
+			),
+			tsd(name, tspb.MakeHistogramBucketSource("2", 8),
+				tsdp(1, 0),
+				tsdp(2, 10),
+				tsdp(3, 40),
+			),
+		})
+		lower4, upper4 := tspb.HistogramBucketLowerBound(4), tspb.HistogramBucketUpperBound(4)
+		lower8, upper8 := tspb.HistogramBucketLowerBound(8), tspb.HistogramBucketUpperBound(8)
+
+		for _, tc := range []struct {
+			percentile float64
+			sources    []string
+			expected   []tspb.TimeSeriesDatapoint
+		}{
+			{
+				percentile: 50,
+				expected: []tspb.TimeSeriesDatapoint{
+					tsdp(2, upper4),
+					tsdp(3, lower8+(upper8-lower8)*10/30),
+				},
+			},
+			{
+				percentile: 100,
+				expected: []tspb.TimeSeriesDatapoint{
+					tsdp(2, upper8),
+					tsdp(3, upper8),
+				},
+			},
+			{
+				percentile: 50,
+				sources:    []string{"1"},
+				expected: []tspb.TimeSeriesDatapoint{
+					tsdp(2, lower4+(upper4-lower4)/2),
+					tsdp(3, lower4+(upper4-lower4)/2),
+				},
+			},
+		} {
+			query := tm.makeQuery("metric.histogram", resolution1ns, 0, 10)
+			query.Percentile = &tc.percentile
+			query.Sources = tc.sources
+			actual, sources, err := query.queryDB()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !testmodel.DataSeriesEquivalent(actual, tc.expected) {
+				t.Errorf("p%g of sources %v: expected %v, got %v", tc.percentile, tc.sources, tc.expected, actual)
+			}
+			expectedSources := 2
+			if len(tc.sources) > 0 {
+				expectedSources = len(tc.sources)
+			}
+			if len(sources) != expectedSources {
+				t.Errorf("expected %d sources, got %v", expectedSources, sources)
+			}
+		}
+
+		query := tm.makeQuery("metric.histogram", resolution1ns, 0, 10)
+		query.Percentile = new(float64)
+		query.assertError("percentile 0 is not in the range")
+	})
+}
@@ -241,12 +241,17 @@ func (s *Server) Query(
 				func(ctx context.Context) {
 					// Estimated source count is either the count of requested sources
 					// *or* the estimated cluster node count if no sources are specified.
+					// The percentile of a histogram is computed from its buckets, which
+					// are read as separate sources for all nodes.
 					var estimatedSourceCount int64
-					if len(query.Sources) > 0 {
+					if len(query.Sources) > 0 && query.Percentile == nil {
 						estimatedSourceCount = int64(len(query.Sources))
 					} else {
 						estimatedSourceCount = estimatedClusterNodeCount
 					}
+					if query.Percentile != nil {
+						estimatedSourceCount *= estimatedHistogramBucketsPerSource
+					}
 
 					// Create a memory account for the results of this query.
 					memContexts[queryIdx] = MakeQueryMemoryContext(
@@ -0,0 +1,93 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tspb
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The bucket counts of a histogram are stored in a single time series, named
+// after the histogram with histogramBucketsSuffix appended. Each bucket of
+// each source is a separate source of that time series, named after the
+// source and the index of the bucket. Storing the buckets of all sources in
+// one series allows them to be read with a single scan, while each of them is
+// a counter which, unlike a precomputed quantile, can be meaningfully
+// downsampled, rolled up and summed across sources.
+//
+// All histograms share the same bucket layout: bucket i holds the values in
+// (2^((i-1)/histogramBucketsPerOctave), 2^(i/histogramBucketsPerOctave)],
+// except for bucket 0 which holds all values up to 1. With four buckets per
+// octave, percentiles interpolated within a bucket are off by less than 10%.
+const (
+	histogramBucketsSuffix    = "-buckets"
+	histogramBucketSeparator  = "/"
+	histogramBucketsPerOctave = 4
+)
+
+// HistogramBucketsName returns the name of the time series holding the bucket
+// counts of the histogram recorded under the given name.
+func HistogramBucketsName(name string) string {
+	return name + histogramBucketsSuffix
+}
+
+// MakeHistogramBucketSource returns the source under which the count of the
+// given bucket of a histogram recorded by the given source is stored.
+func MakeHistogramBucketSource(source string, bucket int) string {
+	return source + histogramBucketSeparator + strconv.Itoa(bucket)
+}
+
+// DecodeHistogramBucketSource decodes a source created by
+// MakeHistogramBucketSource into the source of the histogram and the index of
+// the bucket. It returns false if the source is malformed.
+func DecodeHistogramBucketSource(s string) (source string, bucket int, ok bool) {
+	i := strings.LastIndex(s, histogramBucketSeparator)
+	if i < 0 {
+		return "", 0, false
+	}
+	bucket, err := strconv.Atoi(s[i+len(histogramBucketSeparator):])
+	if err != nil || bucket < 0 {
+		return "", 0, false
+	}
+	return s[:i], bucket, true
+}
+
+// HistogramBucket returns the index of the bucket holding the given value.
+func HistogramBucket(v int64) int {
+	if v <= 1 {
+		return 0
+	}
+	b := int(math.Ceil(math.Log2(float64(v)) * histogramBucketsPerOctave))
+	// Correct for floating point rounding near the bounds of the buckets.
+	for HistogramBucketUpperBound(b) < float64(v) {
+		b++
+	}
+	for b > 0 && HistogramBucketUpperBound(b-1) >= float64(v) {
+		b--
+	}
+	return b
+}
+
+// HistogramBucketUpperBound returns the inclusive upper bound of the values
+// held by the given bucket.
+func HistogramBucketUpperBound(bucket int) float64 {
+	return math.Exp2(float64(bucket) / histogramBucketsPerOctave)
+}
+
+// HistogramBucketLowerBound returns the exclusive lower bound of the values
+// held by the given bucket.
+func HistogramBucketLowerBound(bucket int) float64 {
+	if bucket == 0 {
+		return 0
+	}
+	return HistogramBucketUpperBound(bucket - 1)
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package admission contains the admission control subsystem, which queues
+// work when the node is overloaded so that the work which is admitted
+// continues to see good latency.
+//
+// Work is classified by WorkKind. Each kind has a WorkQueue, which orders
+// waiting work first across tenants, preferring the tenant that is using the
+// fewest resources, and then within a tenant by WorkPriority and creation
+// time. Background work, such as bulk ingestion, backfills and GC, uses
+// LowPri and so yields to foreground transactions.
+//
+// Each WorkQueue is paired with a granter, which decides when work may be
+// admitted. The granters of a node are owned by a single GrantCoordinator,
+// which is fed two overload signals:
+//
+// - CPU utilization, sampled frequently. KVWork is admitted using slots, and
+//   the number of slots is decreased while the CPU is saturated and increased
+//   while the slots are exhausted but the CPU is not. SQLKVResponseWork is
+//   admitted using tokens, which are only replenished while the CPU is not
+//   saturated.
+//
+// - The L0 sublevel and file counts of the Pebble LSM of each store. While
+//   L0 is overloaded, KVWork additionally needs an IO token, and the tokens
+//   are handed out at a rate which lets compactions drain L0. See
+//   ioLoadListener.
+//
+// The GrantCoordinator prefers KVWork over SQLKVResponseWork when granting,
+// since the former is usually further along in its execution.
+//
+// Admission control for each WorkKind is disabled by default, and is enabled
+// by the admission.kv.enabled and admission.sql_kv_response.enabled cluster
+// settings. Work that is in the critical path of the health of the cluster,
+// such as node liveness heartbeats and meta range lookups, bypasses the
+// queues but is accounted for by the granters.
+package admission
@@ -0,0 +1,424 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"context"
+	"math"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/elastic/gosigar"
+)
+
+// targetCPUUtilization is the CPU utilization above which the node is
+// considered overloaded.
+var targetCPUUtilization = settings.RegisterFloatSetting(
+	"admission.cpu_overload_threshold",
+	"the fraction of the CPU capacity of the node above which admission control considers "+
+		"the CPU to be overloaded",
+	0.9,
+	func(v float64) error {
+		if v <= 0 || v > 1 {
+			return errors.Errorf("cannot set to a value outside of (0, 1]: %f", v)
+		}
+		return nil
+	},
+)
+
+const (
+	// cpuSampleInterval is the interval at which the CPU utilization of the
+	// process is sampled.
+	cpuSampleInterval = 250 * time.Millisecond
+	// ioTickInterval is the interval at which IO tokens are handed out, and
+	// at which the usage of tenants is reset.
+	ioTickInterval = time.Second
+	// minKVSlots is the minimum number of KVWork slots.
+	minKVSlots = 1
+	// sqlKVResponseBurstTokensPerProc is the number of SQLKVResponseWork
+	// tokens, per processor, that are made available every time the CPU is
+	// sampled and not found to be overloaded.
+	sqlKVResponseBurstTokensPerProc = 100
+	// unlimitedTokens represents an unlimited number of IO tokens.
+	unlimitedTokens = math.MaxInt64
+)
+
+// granter is paired with a requester, and decides when the requester can be
+// granted admission.
+type granter interface {
+	// tryGet is used by a requester to get a slot or token for admission.
+	// It returns true if the grant was acquired.
+	tryGet() bool
+	// returnGrant is called for returning a slot after the admitted work is
+	// done, or a grant which was acquired but not used.
+	returnGrant()
+	// tookWithoutPermission informs the granter that a slot or token was
+	// taken without calling tryGet.
+	tookWithoutPermission()
+}
+
+// requester is paired with a granter, and is informed by the granter when
+// it can admit waiting work.
+type requester interface {
+	// hasWaitingRequests returns whether there are any waiting requests.
+	hasWaitingRequests() bool
+	// granted is called by the granter to grant admission to the waiting
+	// request at the front of the queue. It returns false if there was no
+	// waiting request, in which case the grant must be returned.
+	granted() bool
+}
+
+// StoreMetrics are the metrics of a store that are used to detect IO
+// overload.
+type StoreMetrics struct {
+	StoreID int32
+	// L0FileCount is the number of files in L0 of the LSM.
+	L0FileCount int64
+	// L0SublevelCount is the number of sublevels in L0 of the LSM.
+	L0SublevelCount int64
+	// L0BytesAdded is the cumulative number of bytes that have been added to
+	// L0 by flushes and ingestions.
+	L0BytesAdded int64
+	// L0Size is the number of bytes in L0.
+	L0Size int64
+}
+
+// StoreMetricsProvider provides the StoreMetrics of the stores of a node.
+type StoreMetricsProvider interface {
+	GetStoreMetrics() []StoreMetrics
+}
+
+// GrantCoordinator is the granter for all the WorkKinds of a node. It owns
+// a WorkQueue for each WorkKind, and decides when each can admit work based
+// on the CPU utilization of the process and the IO load of the stores.
+type GrantCoordinator struct {
+	settings *cluster.Settings
+	queues   [numWorkKinds]*WorkQueue
+	// numProcs is the number of processors available to the process.
+	numProcs int
+	// cpuTime returns the cumulative CPU time used by the process. It is
+	// overridden in tests.
+	cpuTime func() (time.Duration, error)
+
+	mu struct {
+		syncutil.Mutex
+		// totalKVSlots and usedKVSlots are the number of slots for KVWork,
+		// which is adjusted based on CPU utilization, and the number in use.
+		totalKVSlots int
+		usedKVSlots  int
+		// availableIOTokens is the number of IO tokens available to KVWork,
+		// or unlimitedTokens when no store is overloaded.
+		availableIOTokens int64
+		// availableSQLKVResponseTokens is the number of tokens available to
+		// SQLKVResponseWork.
+		availableSQLKVResponseTokens int64
+		// ioTokensExhaustedStart is the time at which the IO tokens were
+		// exhausted, or zero.
+		ioTokensExhaustedStart time.Time
+		// ioLoadListeners contain the IO load state of each store.
+		ioLoadListeners map[int32]*ioLoadListener
+	}
+
+	metrics GranterMetrics
+}
+
+// NewGrantCoordinator constructs a GrantCoordinator and its WorkQueues.
+// Start must be called to start monitoring the load of the node.
+func NewGrantCoordinator(st *cluster.Settings) *GrantCoordinator {
+	numProcs := runtime.GOMAXPROCS(0)
+	coord := &GrantCoordinator{
+		settings: st,
+		numProcs: numProcs,
+		cpuTime:  processCPUTime,
+		metrics:  makeGranterMetrics(),
+	}
+	coord.mu.totalKVSlots = numProcs
+	coord.mu.availableIOTokens = unlimitedTokens
+	coord.mu.availableSQLKVResponseTokens = int64(numProcs * sqlKVResponseBurstTokensPerProc)
+	coord.mu.ioLoadListeners = make(map[int32]*ioLoadListener)
+	coord.metrics.KVTotalSlots.Update(int64(numProcs))
+
+	coord.queues[KVWork] = makeWorkQueue(
+		KVWork, kvGranter{coord}, false /* usesTokens */, st, KVAdmissionControlEnabled)
+	coord.queues[SQLKVResponseWork] = makeWorkQueue(
+		SQLKVResponseWork, sqlKVResponseGranter{coord}, true /* usesTokens */, st,
+		SQLKVResponseAdmissionControlEnabled)
+	return coord
+}
+
+// GetWorkQueue returns the WorkQueue for the given WorkKind.
+func (coord *GrantCoordinator) GetWorkQueue(workKind WorkKind) *WorkQueue {
+	return coord.queues[workKind]
+}
+
+// MetricStructs returns the metrics of the GrantCoordinator and of its
+// WorkQueues.
+func (coord *GrantCoordinator) MetricStructs() []metric.Struct {
+	structs := []metric.Struct{&coord.metrics}
+	for _, q := range coord.queues {
+		structs = append(structs, q.Metrics())
+	}
+	return structs
+}
+
+// Start starts the goroutines which sample the CPU utilization of the
+// process and the IO load of the stores.
+func (coord *GrantCoordinator) Start(
+	ctx context.Context, stopper *stop.Stopper, provider StoreMetricsProvider,
+) error {
+	if err := stopper.RunAsyncTask(ctx, "admission-cpu-sampler", func(ctx context.Context) {
+		ticker := time.NewTicker(cpuSampleInterval)
+		defer ticker.Stop()
+		logEvery := log.Every(time.Minute)
+		lastSample := timeutil.Now()
+		lastCPUTime, err := coord.cpuTime()
+		for {
+			select {
+			case <-ticker.C:
+				now := timeutil.Now()
+				cpuTime, cpuErr := coord.cpuTime()
+				if err == nil && cpuErr == nil {
+					utilization := float64(cpuTime-lastCPUTime) /
+						(float64(now.Sub(lastSample)) * float64(coord.numProcs))
+					coord.CPULoad(utilization)
+				} else if cpuErr != nil && logEvery.ShouldLog() {
+					log.Warningf(ctx, "unable to sample CPU usage: %v", cpuErr)
+				}
+				lastSample, lastCPUTime, err = now, cpuTime, cpuErr
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	}); err != nil {
+		return err
+	}
+	return stopper.RunAsyncTask(ctx, "admission-io-load-listener", func(ctx context.Context) {
+		ticker := time.NewTicker(ioTickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				coord.IOTick(provider.GetStoreMetrics())
+				for _, q := range coord.queues {
+					q.resetTenantUsage()
+				}
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// processCPUTime returns the cumulative user and system CPU time of the
+// process.
+func processCPUTime() (time.Duration, error) {
+	var cpuTime gosigar.ProcTime
+	if err := cpuTime.Get(os.Getpid()); err != nil {
+		return 0, err
+	}
+	// cpuTime.{User,Sys} are in milliseconds.
+	return time.Duration(cpuTime.User+cpuTime.Sys) * time.Millisecond, nil
+}
+
+// CPULoad informs the GrantCoordinator of the CPU utilization of the
+// process, as a fraction of the capacity of the processors available to
+// it.
+//
+// While the CPU is overloaded, the number of KVWork slots is decreased if
+// they are all in use. While the CPU is not overloaded, the number of slots
+// is increased if they are all in use, since the work holding them is not
+// using the CPU, e.g. because it is waiting for IO or locks. The
+// SQLKVResponseWork tokens are replenished only while the CPU is not
+// overloaded.
+func (coord *GrantCoordinator) CPULoad(utilization float64) {
+	overloaded := utilization >= targetCPUUtilization.Get(&coord.settings.SV)
+	coord.mu.Lock()
+	defer coord.mu.Unlock()
+	if overloaded {
+		if coord.mu.usedKVSlots >= coord.mu.totalKVSlots && coord.mu.totalKVSlots > minKVSlots {
+			coord.mu.totalKVSlots--
+		}
+	} else {
+		if coord.mu.usedKVSlots >= coord.mu.totalKVSlots {
+			coord.mu.totalKVSlots++
+		}
+		coord.mu.availableSQLKVResponseTokens = int64(coord.numProcs * sqlKVResponseBurstTokensPerProc)
+	}
+	coord.metrics.KVTotalSlots.Update(int64(coord.mu.totalKVSlots))
+	coord.tryGrantLocked()
+}
+
+// IOTick informs the GrantCoordinator of the current metrics of the stores.
+// It is called every ioTickInterval, and hands out the IO tokens for the
+// next interval.
+func (coord *GrantCoordinator) IOTick(storeMetrics []StoreMetrics) {
+	admitted := coord.queues[KVWork].admittedCountSnapshot()
+	coord.mu.Lock()
+	defer coord.mu.Unlock()
+	tokens := int64(unlimitedTokens)
+	for _, m := range storeMetrics {
+		l, ok := coord.mu.ioLoadListeners[m.StoreID]
+		if !ok {
+			l = &ioLoadListener{settings: coord.settings}
+			coord.mu.ioLoadListeners[m.StoreID] = l
+		}
+		if t := l.tick(m, admitted); t < tokens {
+			tokens = t
+		}
+	}
+	coord.setAvailableIOTokensLocked(tokens)
+	coord.tryGrantLocked()
+}
+
+func (coord *GrantCoordinator) setAvailableIOTokensLocked(tokens int64) {
+	coord.mu.availableIOTokens = tokens
+	if tokens > 0 {
+		coord.recordIOTokensExhaustedLocked()
+	} else if coord.mu.ioTokensExhaustedStart.IsZero() {
+		coord.mu.ioTokensExhaustedStart = timeutil.Now()
+	}
+}
+
+// recordIOTokensExhaustedLocked records the duration for which the IO tokens
+// were exhausted, once they are no longer exhausted.
+func (coord *GrantCoordinator) recordIOTokensExhaustedLocked() {
+	if !coord.mu.ioTokensExhaustedStart.IsZero() {
+		coord.metrics.IOTokensExhaustedDuration.Inc(
+			timeutil.Since(coord.mu.ioTokensExhaustedStart).Nanoseconds())
+		coord.mu.ioTokensExhaustedStart = time.Time{}
+	}
+}
+
+// tryGrantLocked grants admission to waiting work while there are slots and
+// tokens available. KVWork is preferred over SQLKVResponseWork.
+//
+// The WorkQueue mutexes are acquired while holding coord.mu, so the
+// WorkQueues must not call into the granters while holding their mutexes.
+func (coord *GrantCoordinator) tryGrantLocked() {
+	for kind := WorkKind(0); kind < numWorkKinds; kind++ {
+		q := coord.queues[kind]
+		for q.hasWaitingRequests() && coord.tryGetLocked(kind) {
+			if !q.granted() {
+				coord.returnGrantLocked(kind)
+				break
+			}
+		}
+	}
+}
+
+func (coord *GrantCoordinator) tryGetLocked(kind WorkKind) bool {
+	switch kind {
+	case KVWork:
+		if coord.mu.usedKVSlots >= coord.mu.totalKVSlots {
+			return false
+		}
+		if coord.mu.availableIOTokens <= 0 {
+			return false
+		}
+		coord.tookKVSlotLocked()
+		return true
+	case SQLKVResponseWork:
+		if coord.mu.availableSQLKVResponseTokens <= 0 {
+			return false
+		}
+		coord.mu.availableSQLKVResponseTokens--
+		return true
+	default:
+		panic(errors.AssertionFailedf("unknown WorkKind %d", kind))
+	}
+}
+
+func (coord *GrantCoordinator) tookKVSlotLocked() {
+	coord.mu.usedKVSlots++
+	coord.metrics.KVUsedSlots.Update(int64(coord.mu.usedKVSlots))
+	if coord.mu.availableIOTokens != unlimitedTokens {
+		coord.mu.availableIOTokens--
+		if coord.mu.availableIOTokens <= 0 && coord.mu.ioTokensExhaustedStart.IsZero() {
+			coord.mu.ioTokensExhaustedStart = timeutil.Now()
+		}
+	}
+}
+
+func (coord *GrantCoordinator) returnGrantLocked(kind WorkKind) {
+	switch kind {
+	case KVWork:
+		coord.mu.usedKVSlots--
+		coord.metrics.KVUsedSlots.Update(int64(coord.mu.usedKVSlots))
+	case SQLKVResponseWork:
+		coord.mu.availableSQLKVResponseTokens++
+	default:
+		panic(errors.AssertionFailedf("unknown WorkKind %d", kind))
+	}
+}
+
+// kvGranter is the granter for KVWork. It admits work using slots, which are
+// returned when the work is done, and IO tokens while the stores are
+// overloaded.
+type kvGranter struct {
+	coord *GrantCoordinator
+}
+
+var _ granter = kvGranter{}
+
+func (g kvGranter) tryGet() bool {
+	g.coord.mu.Lock()
+	defer g.coord.mu.Unlock()
+	return g.coord.tryGetLocked(KVWork)
+}
+
+func (g kvGranter) returnGrant() {
+	g.coord.mu.Lock()
+	defer g.coord.mu.Unlock()
+	g.coord.returnGrantLocked(KVWork)
+	g.coord.tryGrantLocked()
+}
+
+func (g kvGranter) tookWithoutPermission() {
+	g.coord.mu.Lock()
+	defer g.coord.mu.Unlock()
+	g.coord.tookKVSlotLocked()
+}
+
+// sqlKVResponseGranter is the granter for SQLKVResponseWork. It admits work
+// using tokens, which are replenished while the CPU is not overloaded.
+type sqlKVResponseGranter struct {
+	coord *GrantCoordinator
+}
+
+var _ granter = sqlKVResponseGranter{}
+
+func (g sqlKVResponseGranter) tryGet() bool {
+	g.coord.mu.Lock()
+	defer g.coord.mu.Unlock()
+	return g.coord.tryGetLocked(SQLKVResponseWork)
+}
+
+func (g sqlKVResponseGranter) returnGrant() {
+	g.coord.mu.Lock()
+	defer g.coord.mu.Unlock()
+	g.coord.returnGrantLocked(SQLKVResponseWork)
+	g.coord.tryGrantLocked()
+}
+
+func (g sqlKVResponseGranter) tookWithoutPermission() {
+	g.coord.mu.Lock()
+	defer g.coord.mu.Unlock()
+	g.coord.mu.availableSQLKVResponseTokens--
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestGrantCoordinator(totalKVSlots int) *GrantCoordinator {
+	st := cluster.MakeTestingClusterSettings()
+	KVAdmissionControlEnabled.Override(&st.SV, true)
+	SQLKVResponseAdmissionControlEnabled.Override(&st.SV, true)
+	coord := NewGrantCoordinator(st)
+	coord.mu.totalKVSlots = totalKVSlots
+	return coord
+}
+
+func (coord *GrantCoordinator) kvSlots() (used, total int) {
+	coord.mu.Lock()
+	defer coord.mu.Unlock()
+	return coord.mu.usedKVSlots, coord.mu.totalKVSlots
+}
+
+func TestGrantCoordinatorKVSlots(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	coord := makeTestGrantCoordinator(1)
+	q := coord.GetWorkQueue(KVWork)
+	info := WorkInfo{TenantID: roachpb.SystemTenantID}
+
+	enabled, err := q.Admit(ctx, info)
+	require.NoError(t, err)
+	require.True(t, enabled)
+
+	// The second request waits for the slot to be returned.
+	admitted := make(chan error)
+	go func() {
+		_, err := q.Admit(ctx, info)
+		admitted <- err
+	}()
+	waitForQueueLength(t, q, 1)
+	q.AdmittedWorkDone(info.TenantID)
+	require.NoError(t, <-admitted)
+	used, total := coord.kvSlots()
+	require.Equal(t, 1, used)
+	require.Equal(t, 1, total)
+
+	// While the CPU is not overloaded and the slots are exhausted, the slots
+	// are increased and the waiting work is granted.
+	go func() {
+		_, err := q.Admit(ctx, info)
+		admitted <- err
+	}()
+	waitForQueueLength(t, q, 1)
+	coord.CPULoad(0.5)
+	require.NoError(t, <-admitted)
+	used, total = coord.kvSlots()
+	require.Equal(t, 2, used)
+	require.Equal(t, 2, total)
+
+	// While the CPU is overloaded and the slots are exhausted, the slots are
+	// decreased, but not below the minimum.
+	for i := 0; i < 3; i++ {
+		coord.CPULoad(0.95)
+	}
+	_, total = coord.kvSlots()
+	require.Equal(t, minKVSlots, total)
+	q.AdmittedWorkDone(info.TenantID)
+	q.AdmittedWorkDone(info.TenantID)
+	used, _ = coord.kvSlots()
+	require.Equal(t, 0, used)
+	require.Equal(t, int64(1), coord.metrics.KVTotalSlots.Value())
+}
+
+func TestGrantCoordinatorIOTokens(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	coord := makeTestGrantCoordinator(100)
+	q := coord.GetWorkQueue(KVWork)
+	info := WorkInfo{TenantID: roachpb.SystemTenantID}
+
+	coord.mu.Lock()
+	coord.setAvailableIOTokensLocked(1)
+	coord.mu.Unlock()
+	_, err := q.Admit(ctx, info)
+	require.NoError(t, err)
+
+	// The IO tokens are exhausted, so the work waits despite free slots.
+	admitted := make(chan error)
+	go func() {
+		_, err := q.Admit(ctx, info)
+		admitted <- err
+	}()
+	waitForQueueLength(t, q, 1)
+	q.AdmittedWorkDone(info.TenantID)
+	select {
+	case err := <-admitted:
+		t.Fatalf("unexpectedly admitted: %v", err)
+	default:
+	}
+
+	// New tokens admit the waiting work.
+	coord.IOTick(nil)
+	require.NoError(t, <-admitted)
+	require.Greater(t, coord.metrics.IOTokensExhaustedDuration.Count(), int64(0))
+	q.AdmittedWorkDone(info.TenantID)
+}
+
+func TestGrantCoordinatorSQLKVResponseTokens(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	coord := makeTestGrantCoordinator(1)
+	q := coord.GetWorkQueue(SQLKVResponseWork)
+	info := WorkInfo{TenantID: roachpb.SystemTenantID}
+
+	coord.mu.Lock()
+	coord.mu.availableSQLKVResponseTokens = 1
+	coord.mu.Unlock()
+	_, err := q.Admit(ctx, info)
+	require.NoError(t, err)
+
+	admitted := make(chan error)
+	go func() {
+		_, err := q.Admit(ctx, info)
+		admitted <- err
+	}()
+	waitForQueueLength(t, q, 1)
+
+	// The tokens are not replenished while the CPU is overloaded.
+	coord.CPULoad(0.95)
+	select {
+	case err := <-admitted:
+		t.Fatalf("unexpectedly admitted: %v", err)
+	default:
+	}
+	coord.CPULoad(0.5)
+	require.NoError(t, <-admitted)
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+)
+
+// L0FileCountOverloadThreshold sets a file count threshold that signals an
+// overloaded store.
+var L0FileCountOverloadThreshold = settings.RegisterIntSetting(
+	"admission.l0_file_count_overload_threshold",
+	"when the L0 file count exceeds this threshold, the store is considered overloaded",
+	1000,
+	settings.PositiveInt,
+)
+
+// L0SubLevelCountOverloadThreshold sets a sub-level count threshold that
+// signals an overloaded store.
+var L0SubLevelCountOverloadThreshold = settings.RegisterIntSetting(
+	"admission.l0_sub_level_count_overload_threshold",
+	"when the L0 sub-level count exceeds this threshold, the store is considered overloaded",
+	20,
+	settings.PositiveInt,
+)
+
+// adjustmentInterval is the number of ioTickIntervals over which the IO
+// tokens are computed. Metrics are only reliable over intervals longer than
+// a typical flush or compaction, so the tokens are computed at this coarser
+// granularity, and handed out evenly at every tick to avoid bursts.
+const adjustmentInterval = 15
+
+// ioLoadListener computes the IO tokens of a store. While the store is not
+// overloaded, the tokens are unlimited. While it is overloaded, the tokens
+// are computed such that the bytes added to L0 by the admitted work are half
+// of the bytes that compactions are removing from L0, so that L0 drains.
+//
+// The number of bytes added to L0 per admitted request is estimated from
+// the previous interval, since the work that is admitted does not know how
+// many bytes it will add.
+type ioLoadListener struct {
+	settings *cluster.Settings
+
+	// Cumulative stats, as of the last adjustment.
+	statsInitialized bool
+	admittedCount    uint64
+	l0BytesAdded     int64
+	l0Size           int64
+
+	// Exponentially smoothed per-interval values.
+	smoothedBytesRemoved int64
+	smoothedNumAdmit     float64
+
+	// totalTokens is the number of tokens to hand out over the current
+	// adjustment interval, and tokensAllocated the number handed out so far.
+	totalTokens     int64
+	tokensAllocated int64
+	ticks           int
+}
+
+// tick is called every ioTickInterval with the current metrics of the store
+// and the cumulative number of admitted KVWork requests. It returns the
+// number of tokens available until the next tick.
+func (io *ioLoadListener) tick(m StoreMetrics, admittedCount uint64) int64 {
+	if !io.statsInitialized {
+		io.statsInitialized = true
+		io.adjustTokens(m, admittedCount, false /* computeTokens */)
+		return unlimitedTokens
+	}
+	io.ticks++
+	if io.ticks >= adjustmentInterval {
+		io.ticks = 0
+		io.adjustTokens(m, admittedCount, true /* computeTokens */)
+	}
+	if io.totalTokens == unlimitedTokens {
+		return unlimitedTokens
+	}
+	// Hand out the tokens evenly over the remaining ticks of the interval,
+	// rounding up.
+	remainingTicks := int64(adjustmentInterval - io.ticks)
+	toAllocate := (io.totalTokens - io.tokensAllocated + remainingTicks - 1) / remainingTicks
+	if toAllocate < 0 {
+		toAllocate = 0
+	}
+	io.tokensAllocated += toAllocate
+	return toAllocate
+}
+
+// adjustTokens computes the tokens for the next adjustment interval.
+func (io *ioLoadListener) adjustTokens(m StoreMetrics, admittedCount uint64, computeTokens bool) {
+	bytesAdded := m.L0BytesAdded - io.l0BytesAdded
+	bytesRemoved := bytesAdded - (m.L0Size - io.l0Size)
+	if bytesRemoved < 0 {
+		bytesRemoved = 0
+	}
+	admitted := admittedCount - io.admittedCount
+	io.admittedCount = admittedCount
+	io.l0BytesAdded = m.L0BytesAdded
+	io.l0Size = m.L0Size
+	io.tokensAllocated = 0
+	io.totalTokens = unlimitedTokens
+	if !computeTokens {
+		return
+	}
+
+	const alpha = 0.5
+	io.smoothedBytesRemoved = int64(alpha*float64(bytesRemoved) + (1-alpha)*float64(io.smoothedBytesRemoved))
+	io.smoothedNumAdmit = alpha*float64(admitted) + (1-alpha)*io.smoothedNumAdmit
+	if !io.overloaded(m) {
+		return
+	}
+	if bytesAdded <= 0 || admitted == 0 {
+		// Without an estimate of the bytes added per request, admit half as
+		// many requests as in the previous intervals.
+		io.totalTokens = int64(io.smoothedNumAdmit / 2)
+	} else {
+		bytesAddedPerWork := float64(bytesAdded) / float64(admitted)
+		io.totalTokens = int64(float64(io.smoothedBytesRemoved) / 2 / bytesAddedPerWork)
+	}
+	// Always admit some work, so that progress is made and the estimates
+	// for the next interval are based on recent work.
+	if io.totalTokens < adjustmentInterval {
+		io.totalTokens = adjustmentInterval
+	}
+}
+
+func (io *ioLoadListener) overloaded(m StoreMetrics) bool {
+	return m.L0FileCount > L0FileCountOverloadThreshold.Get(&io.settings.SV) ||
+		m.L0SublevelCount > L0SubLevelCountOverloadThreshold.Get(&io.settings.SV)
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIOLoadListener(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	io := &ioLoadListener{settings: cluster.MakeTestingClusterSettings()}
+	var m StoreMetrics
+	var admitted uint64
+	// runInterval runs the ticks of an adjustment interval, during which
+	// 1000 requests are admitted, each adding 1KB to L0, and compactions
+	// remove bytesRemoved from L0. It returns the tokens handed out at each
+	// tick.
+	runInterval := func(bytesRemoved int64) []int64 {
+		var tokens []int64
+		for i := 0; i < adjustmentInterval; i++ {
+			admitted += 1000 / adjustmentInterval
+			m.L0BytesAdded += 1000 / adjustmentInterval << 10
+			m.L0Size += 1000/adjustmentInterval<<10 - bytesRemoved/adjustmentInterval
+			tokens = append(tokens, io.tick(m, admitted))
+		}
+		return tokens
+	}
+
+	require.Equal(t, int64(unlimitedTokens), io.tick(m, admitted))
+	// The tokens are unlimited while the store is not overloaded.
+	for _, tokens := range runInterval(0) {
+		require.Equal(t, int64(unlimitedTokens), tokens)
+	}
+
+	// Once overloaded, the tokens admit half of the bytes removed from L0,
+	// spread evenly across the interval.
+	m.L0SublevelCount = 30
+	tokens := runInterval(300 << 10)
+	for _, tok := range tokens[:len(tokens)-1] {
+		require.Equal(t, int64(unlimitedTokens), tok)
+	}
+	// The tokens are computed at the end of the interval.
+	total := tokens[len(tokens)-1]
+	require.NotEqual(t, int64(unlimitedTokens), total)
+	tokens = runInterval(300 << 10)
+	for _, tok := range tokens[:len(tokens)-1] {
+		require.NotEqual(t, int64(unlimitedTokens), tok)
+		total += tok
+	}
+	// The smoothed bytes removed are 150KB, and each request adds 1KB, so
+	// about 75 requests are admitted over the interval.
+	require.InDelta(t, 75, total, adjustmentInterval)
+
+	// The tokens are unlimited again once the store is no longer overloaded.
+	m.L0SublevelCount = 0
+	tokens = runInterval(300 << 10)
+	require.Equal(t, int64(unlimitedTokens), tokens[len(tokens)-1])
+}
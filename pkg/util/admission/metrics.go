@@ -0,0 +1,121 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+// WorkQueueMetrics are the metrics of a WorkQueue.
+type WorkQueueMetrics struct {
+	Requested       *metric.Counter
+	Admitted        *metric.Counter
+	Errored         *metric.Counter
+	WaitDurations   *metric.Histogram
+	WaitQueueLength *metric.Gauge
+}
+
+var _ metric.Struct = (*WorkQueueMetrics)(nil)
+
+// MetricStruct implements the metric.Struct interface.
+func (*WorkQueueMetrics) MetricStruct() {}
+
+// waitDurationsMaxValue is the maximum wait duration tracked by the
+// WaitDurations histogram.
+const waitDurationsMaxValue = time.Minute
+
+// workKindMetricNames are the names used for each WorkKind in the names of
+// the metrics of its WorkQueue.
+var workKindMetricNames = [numWorkKinds]string{
+	KVWork:            "kv",
+	SQLKVResponseWork: "sql_kv_response",
+}
+
+func makeWorkQueueMetrics(workKind WorkKind) WorkQueueMetrics {
+	name := workKindMetricNames[workKind]
+	return WorkQueueMetrics{
+		Requested: metric.NewCounter(metric.Metadata{
+			Name:        fmt.Sprintf("admission.requested.%s", name),
+			Help:        fmt.Sprintf("Number of %s requests subject to admission control", workKind),
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		Admitted: metric.NewCounter(metric.Metadata{
+			Name:        fmt.Sprintf("admission.admitted.%s", name),
+			Help:        fmt.Sprintf("Number of %s requests admitted", workKind),
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		Errored: metric.NewCounter(metric.Metadata{
+			Name:        fmt.Sprintf("admission.errored.%s", name),
+			Help:        fmt.Sprintf("Number of %s requests not admitted due to error", workKind),
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		WaitDurations: metric.NewHistogram(metric.Metadata{
+			Name:        fmt.Sprintf("admission.wait_durations.%s", name),
+			Help:        fmt.Sprintf("Wait time durations for %s requests that waited", workKind),
+			Measurement: "Wait time Duration",
+			Unit:        metric.Unit_NANOSECONDS,
+		}, base.DefaultHistogramWindowInterval(), waitDurationsMaxValue.Nanoseconds(), 1),
+		WaitQueueLength: metric.NewGauge(metric.Metadata{
+			Name:        fmt.Sprintf("admission.wait_queue_length.%s", name),
+			Help:        fmt.Sprintf("Length of %s wait queue", workKind),
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+	}
+}
+
+// GranterMetrics are the metrics of a GrantCoordinator.
+type GranterMetrics struct {
+	KVTotalSlots              *metric.Gauge
+	KVUsedSlots               *metric.Gauge
+	IOTokensExhaustedDuration *metric.Counter
+}
+
+var _ metric.Struct = (*GranterMetrics)(nil)
+
+// MetricStruct implements the metric.Struct interface.
+func (*GranterMetrics) MetricStruct() {}
+
+var (
+	metaKVTotalSlots = metric.Metadata{
+		Name:        "admission.granter.total_slots.kv",
+		Help:        "Total slots for kv work",
+		Measurement: "Slots",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaKVUsedSlots = metric.Metadata{
+		Name:        "admission.granter.used_slots.kv",
+		Help:        "Used slots for kv work",
+		Measurement: "Slots",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaIOTokensExhaustedDuration = metric.Metadata{
+		Name:        "admission.granter.io_tokens_exhausted_duration.kv",
+		Help:        "Total duration when IO tokens were exhausted, in nanoseconds",
+		Measurement: "Duration",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+)
+
+func makeGranterMetrics() GranterMetrics {
+	return GranterMetrics{
+		KVTotalSlots:              metric.NewGauge(metaKVTotalSlots),
+		KVUsedSlots:               metric.NewGauge(metaKVUsedSlots),
+		IOTokensExhaustedDuration: metric.NewCounter(metaIOTokensExhaustedDuration),
+	}
+}
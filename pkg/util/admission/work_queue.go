@@ -0,0 +1,469 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// KVAdmissionControlEnabled controls whether KV server-side admission control
+// is enabled.
+var KVAdmissionControlEnabled = settings.RegisterBoolSetting(
+	"admission.kv.enabled",
+	"when true, work performed by the KV layer is subject to admission control",
+	false,
+).WithPublic()
+
+// SQLKVResponseAdmissionControlEnabled controls whether response processing
+// in SQL, for KV requests, is enabled.
+var SQLKVResponseAdmissionControlEnabled = settings.RegisterBoolSetting(
+	"admission.sql_kv_response.enabled",
+	"when true, work performed by the SQL layer when receiving a KV response is subject to "+
+		"admission control",
+	false,
+).WithPublic()
+
+// WorkPriority represents the priority of work. In an WorkQueue, it is only
+// used for ordering within a tenant. High priority work can starve lower
+// priority work.
+type WorkPriority int8
+
+const (
+	// LowPri is low priority work, such as bulk ingestion, backfills and GC.
+	LowPri WorkPriority = math.MinInt8
+	// NormalPri is normal priority work.
+	NormalPri WorkPriority = 0
+	// HighPri is high priority work.
+	HighPri WorkPriority = math.MaxInt8
+)
+
+func (w WorkPriority) String() string {
+	switch w {
+	case LowPri:
+		return "low-pri"
+	case NormalPri:
+		return "normal-pri"
+	case HighPri:
+		return "high-pri"
+	default:
+		return fmt.Sprintf("pri(%d)", int8(w))
+	}
+}
+
+// WorkKind represents various types of work that are subject to admission
+// control.
+type WorkKind int8
+
+const (
+	// KVWork represents requests submitted to the KV layer, from the same node
+	// or a different node. They may originate from the SQL layer or the KV
+	// layer.
+	KVWork WorkKind = iota
+	// SQLKVResponseWork is response processing in SQL for a KV response from a
+	// local or remote node. This can be either leaf or root DistSQL work, i.e.,
+	// this is inter-layer and not necessarily inter-node.
+	SQLKVResponseWork
+	numWorkKinds
+)
+
+func (wk WorkKind) String() string {
+	switch wk {
+	case KVWork:
+		return "kv"
+	case SQLKVResponseWork:
+		return "sql-kv-response"
+	default:
+		panic(errors.AssertionFailedf("unknown WorkKind %d", wk))
+	}
+}
+
+// WorkInfo provides information that is used to order work within an
+// WorkQueue.
+type WorkInfo struct {
+	// TenantID is the id of the tenant. For single-tenant clusters, this will
+	// always be the SystemTenantID.
+	TenantID roachpb.TenantID
+	// Priority is utilized within a tenant.
+	Priority WorkPriority
+	// CreateTime is equivalent to Time.UnixNano() at the creation time of this
+	// work or a parent work (e.g. could be the start time of the transaction,
+	// if this work was created as part of a transaction). It is used to order
+	// work within a (TenantID, Priority) pair -- earlier CreateTime is given
+	// preference.
+	CreateTime int64
+	// BypassAdmission allows the work to bypass admission control, but allows
+	// for it to be accounted for. Ignored unless TenantID is the
+	// SystemTenantID. It should be used for high-priority intra-KV work, and
+	// when it is known that the work is already admitted (e.g. because of an
+	// internal retry).
+	BypassAdmission bool
+}
+
+// WorkQueue maintains a set of work that is waiting for admission, ordered
+// across tenants by their resource usage, and within a tenant by priority
+// and creation time. It interacts with a granter, which decides when work
+// can be admitted.
+//
+// WorkQueue is safe for concurrent use.
+type WorkQueue struct {
+	workKind   WorkKind
+	granter    granter
+	usesTokens bool
+	settings   *cluster.Settings
+	enabled    *settings.BoolSetting
+
+	mu struct {
+		syncutil.Mutex
+		// tenantHeap contains the tenants with waiting work.
+		tenantHeap tenantHeap
+		// tenants contains all the tenants with waiting work or used
+		// resources.
+		tenants map[uint64]*tenantInfo
+	}
+	// admittedCount is the number of requests admitted since the WorkQueue
+	// was created, including those which bypassed admission. It is accessed
+	// atomically.
+	admittedCount uint64
+
+	metrics WorkQueueMetrics
+}
+
+var _ requester = &WorkQueue{}
+
+func makeWorkQueue(
+	workKind WorkKind,
+	granter granter,
+	usesTokens bool,
+	st *cluster.Settings,
+	enabled *settings.BoolSetting,
+) *WorkQueue {
+	q := &WorkQueue{
+		workKind:   workKind,
+		granter:    granter,
+		usesTokens: usesTokens,
+		settings:   st,
+		enabled:    enabled,
+		metrics:    makeWorkQueueMetrics(workKind),
+	}
+	q.mu.tenants = make(map[uint64]*tenantInfo)
+	return q
+}
+
+// Metrics returns the metrics of the WorkQueue.
+func (q *WorkQueue) Metrics() *WorkQueueMetrics {
+	return &q.metrics
+}
+
+func (q *WorkQueue) admissionEnabled() bool {
+	return q.enabled.Get(&q.settings.SV)
+}
+
+// Admit is called when requesting admission for some work. If err != nil,
+// the request was not admitted, potentially due to the deadline being
+// exceeded. If err == nil and enabled is true, the work was admitted and the
+// caller must call AdmittedWorkDone when it is done. If enabled is false,
+// admission control is disabled and the caller must not call
+// AdmittedWorkDone.
+func (q *WorkQueue) Admit(ctx context.Context, info WorkInfo) (enabled bool, err error) {
+	if !q.admissionEnabled() {
+		return false, nil
+	}
+	q.metrics.Requested.Inc(1)
+	tenantID := info.TenantID.ToUint64()
+	if info.BypassAdmission && roachpb.IsSystemTenantID(tenantID) && q.workKind == KVWork {
+		q.granter.tookWithoutPermission()
+		q.mu.Lock()
+		q.getTenantLocked(tenantID).used++
+		q.mu.Unlock()
+		q.admitted()
+		return true, nil
+	}
+
+	// Fast path: if no work is waiting, try to get a grant without queueing.
+	// The granter must be called without holding q.mu, since the granter
+	// calls into the WorkQueue while holding its own mutex.
+	q.mu.Lock()
+	noWaiting := len(q.mu.tenantHeap) == 0
+	q.mu.Unlock()
+	if noWaiting && q.granter.tryGet() {
+		q.mu.Lock()
+		q.getTenantLocked(tenantID).used++
+		q.mu.Unlock()
+		q.admitted()
+		return true, nil
+	}
+
+	q.mu.Lock()
+	tenant := q.getTenantLocked(tenantID)
+	work := &waitingWork{
+		priority:       info.Priority,
+		createTime:     info.CreateTime,
+		ch:             make(chan struct{}, 1),
+		enqueueingTime: timeutil.Now(),
+	}
+	heap.Push(&tenant.waitingWorkHeap, work)
+	if len(tenant.waitingWorkHeap) == 1 {
+		heap.Push(&q.mu.tenantHeap, tenant)
+	}
+	q.mu.Unlock()
+	q.metrics.WaitQueueLength.Inc(1)
+	defer q.metrics.WaitQueueLength.Dec(1)
+
+	// The grant may have become available between the failed tryGet above and
+	// the work being queued, so check again. Without this, the work could wait
+	// until the next grant is returned.
+	if q.granter.tryGet() && !q.granted() {
+		q.granter.returnGrant()
+	}
+
+	select {
+	case <-ctx.Done():
+		q.mu.Lock()
+		if work.heapIndex == -1 {
+			// The work was granted concurrently with the context being
+			// canceled. Return the grant.
+			q.mu.Unlock()
+			<-work.ch
+			if !q.usesTokens {
+				q.AdmittedWorkDone(info.TenantID)
+			}
+		} else {
+			tenant.waitingWorkHeap.remove(work)
+			if len(tenant.waitingWorkHeap) == 0 {
+				q.mu.tenantHeap.remove(tenant)
+			}
+			q.mu.Unlock()
+		}
+		q.metrics.Errored.Inc(1)
+		waitDur := timeutil.Since(work.enqueueingTime)
+		return true, errors.Wrapf(ctx.Err(),
+			"context canceled while waiting in %s queue for %s", q.workKind, waitDur)
+	case <-work.ch:
+		q.metrics.WaitDurations.RecordValue(timeutil.Since(work.enqueueingTime).Nanoseconds())
+		q.admitted()
+		return true, nil
+	}
+}
+
+func (q *WorkQueue) admitted() {
+	atomic.AddUint64(&q.admittedCount, 1)
+	q.metrics.Admitted.Inc(1)
+}
+
+// admittedCountSnapshot returns the number of requests admitted since the
+// WorkQueue was created.
+func (q *WorkQueue) admittedCountSnapshot() uint64 {
+	return atomic.LoadUint64(&q.admittedCount)
+}
+
+// AdmittedWorkDone is used to inform the WorkQueue that some admitted work is
+// finished. It must be called iff enabled=true was returned by Admit, and
+// only for WorkKinds which are admitted using slots.
+func (q *WorkQueue) AdmittedWorkDone(tenantID roachpb.TenantID) {
+	if q.usesTokens {
+		panic(errors.AssertionFailedf("AdmittedWorkDone called on %s queue", q.workKind))
+	}
+	q.mu.Lock()
+	tenant, ok := q.mu.tenants[tenantID.ToUint64()]
+	if !ok {
+		q.mu.Unlock()
+		panic(errors.AssertionFailedf("tenant %s not found", tenantID))
+	}
+	tenant.used--
+	if tenant.heapIndex >= 0 {
+		heap.Fix(&q.mu.tenantHeap, tenant.heapIndex)
+	}
+	q.mu.Unlock()
+	q.granter.returnGrant()
+}
+
+// hasWaitingRequests implements requester.
+func (q *WorkQueue) hasWaitingRequests() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.mu.tenantHeap) > 0
+}
+
+// granted implements requester.
+func (q *WorkQueue) granted() bool {
+	q.mu.Lock()
+	if len(q.mu.tenantHeap) == 0 {
+		q.mu.Unlock()
+		return false
+	}
+	tenant := q.mu.tenantHeap[0]
+	work := heap.Pop(&tenant.waitingWorkHeap).(*waitingWork)
+	tenant.used++
+	if len(tenant.waitingWorkHeap) == 0 {
+		heap.Pop(&q.mu.tenantHeap)
+	} else {
+		heap.Fix(&q.mu.tenantHeap, 0)
+	}
+	q.mu.Unlock()
+	work.ch <- struct{}{}
+	return true
+}
+
+// getTenantLocked returns the tenantInfo for the tenant, creating it if
+// necessary.
+func (q *WorkQueue) getTenantLocked(tenantID uint64) *tenantInfo {
+	tenant, ok := q.mu.tenants[tenantID]
+	if !ok {
+		tenant = &tenantInfo{id: tenantID, heapIndex: -1}
+		q.mu.tenants[tenantID] = tenant
+	}
+	return tenant
+}
+
+// resetTenantUsage forgets tenants that have no waiting work and hold no
+// resources. For queues which use tokens, the usage of all tenants is also
+// reset, so that fairness is computed over recent usage. It is called
+// periodically by the GrantCoordinator.
+func (q *WorkQueue) resetTenantUsage() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, tenant := range q.mu.tenants {
+		if q.usesTokens {
+			tenant.used = 0
+		}
+		if tenant.used == 0 && len(tenant.waitingWorkHeap) == 0 {
+			delete(q.mu.tenants, id)
+		}
+	}
+	if q.usesTokens {
+		heap.Init(&q.mu.tenantHeap)
+	}
+}
+
+// tenantInfo is the per-tenant information in the tenantHeap.
+type tenantInfo struct {
+	id uint64
+	// used is the number of slots held by the tenant, for queues using
+	// slots, or the number of tokens consumed since the last reset, for
+	// queues using tokens.
+	used            uint64
+	waitingWorkHeap waitingWorkHeap
+	// heapIndex is the index of the tenant in the tenantHeap, or -1 if it
+	// has no waiting work.
+	heapIndex int
+}
+
+// tenantHeap is a heap of tenants with waiting work, with the tenant using
+// the fewest resources at the top.
+type tenantHeap []*tenantInfo
+
+var _ heap.Interface = (*tenantHeap)(nil)
+
+func (th *tenantHeap) remove(item *tenantInfo) {
+	heap.Remove(th, item.heapIndex)
+}
+
+func (th *tenantHeap) Len() int {
+	return len(*th)
+}
+
+func (th *tenantHeap) Less(i, j int) bool {
+	if (*th)[i].used == (*th)[j].used {
+		return (*th)[i].id < (*th)[j].id
+	}
+	return (*th)[i].used < (*th)[j].used
+}
+
+func (th *tenantHeap) Swap(i, j int) {
+	(*th)[i], (*th)[j] = (*th)[j], (*th)[i]
+	(*th)[i].heapIndex = i
+	(*th)[j].heapIndex = j
+}
+
+func (th *tenantHeap) Push(x interface{}) {
+	n := len(*th)
+	item := x.(*tenantInfo)
+	item.heapIndex = n
+	*th = append(*th, item)
+}
+
+func (th *tenantHeap) Pop() interface{} {
+	old := *th
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.heapIndex = -1
+	*th = old[0 : n-1]
+	return item
+}
+
+// waitingWork is the work of a tenant that is waiting for admission.
+type waitingWork struct {
+	priority   WorkPriority
+	createTime int64
+	// ch is used to signal the waiting goroutine that the work was granted.
+	ch             chan struct{}
+	enqueueingTime time.Time
+	// heapIndex is the index of the work in the waitingWorkHeap, or -1 once
+	// the work has been granted.
+	heapIndex int
+}
+
+// waitingWorkHeap is a heap of waiting work within a tenant, ordered by
+// priority and then by creation time.
+type waitingWorkHeap []*waitingWork
+
+var _ heap.Interface = (*waitingWorkHeap)(nil)
+
+func (wwh *waitingWorkHeap) remove(item *waitingWork) {
+	heap.Remove(wwh, item.heapIndex)
+}
+
+func (wwh *waitingWorkHeap) Len() int {
+	return len(*wwh)
+}
+
+func (wwh *waitingWorkHeap) Less(i, j int) bool {
+	if (*wwh)[i].priority == (*wwh)[j].priority {
+		return (*wwh)[i].createTime < (*wwh)[j].createTime
+	}
+	return (*wwh)[i].priority > (*wwh)[j].priority
+}
+
+func (wwh *waitingWorkHeap) Swap(i, j int) {
+	(*wwh)[i], (*wwh)[j] = (*wwh)[j], (*wwh)[i]
+	(*wwh)[i].heapIndex = i
+	(*wwh)[j].heapIndex = j
+}
+
+func (wwh *waitingWorkHeap) Push(x interface{}) {
+	n := len(*wwh)
+	item := x.(*waitingWork)
+	item.heapIndex = n
+	*wwh = append(*wwh, item)
+}
+
+func (wwh *waitingWorkHeap) Pop() interface{} {
+	old := *wwh
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.heapIndex = -1
+	*wwh = old[0 : n-1]
+	return item
+}
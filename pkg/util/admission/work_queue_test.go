@@ -0,0 +1,193 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// testGranter is a granter which only grants when told to.
+type testGranter struct {
+	mu struct {
+		syncutil.Mutex
+		available             int
+		returned              int
+		tookWithoutPermission int
+	}
+}
+
+var _ granter = &testGranter{}
+
+func (g *testGranter) tryGet() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.mu.available > 0 {
+		g.mu.available--
+		return true
+	}
+	return false
+}
+
+func (g *testGranter) returnGrant() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.mu.returned++
+}
+
+func (g *testGranter) tookWithoutPermission() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.mu.tookWithoutPermission++
+}
+
+func makeTestWorkQueue(g granter) *WorkQueue {
+	st := cluster.MakeTestingClusterSettings()
+	KVAdmissionControlEnabled.Override(&st.SV, true)
+	return makeWorkQueue(KVWork, g, false /* usesTokens */, st, KVAdmissionControlEnabled)
+}
+
+// waitForQueueLength waits until the given number of requests are waiting
+// in the queue.
+func waitForQueueLength(t *testing.T, q *WorkQueue, n int64) {
+	testutils.SucceedsSoon(t, func() error {
+		if l := q.metrics.WaitQueueLength.Value(); l != n {
+			return errors.Errorf("expected %d waiting requests, found %d", n, l)
+		}
+		return nil
+	})
+}
+
+func TestWorkQueueOrdering(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	g := &testGranter{}
+	q := makeTestWorkQueue(g)
+
+	works := []struct {
+		tenantID   uint64
+		priority   WorkPriority
+		createTime int64
+	}{
+		{5, NormalPri, 2},
+		{5, HighPri, 3},
+		{5, LowPri, 1},
+		{7, NormalPri, 4},
+	}
+	admitted := make(chan string, len(works))
+	for i, w := range works {
+		w := w
+		go func() {
+			enabled, err := q.Admit(ctx, WorkInfo{
+				TenantID:   roachpb.MakeTenantID(w.tenantID),
+				Priority:   w.priority,
+				CreateTime: w.createTime,
+			})
+			if err != nil || !enabled {
+				admitted <- fmt.Sprintf("error: %v %t", err, enabled)
+				return
+			}
+			admitted <- fmt.Sprintf("tenant=%d %s", w.tenantID, w.priority)
+		}()
+		waitForQueueLength(t, q, int64(i+1))
+	}
+
+	// The tenant using the fewest slots is preferred, and within a tenant,
+	// higher priority and then earlier work is preferred.
+	for _, exp := range []string{
+		"tenant=5 high-pri",
+		"tenant=7 normal-pri",
+		"tenant=5 normal-pri",
+		"tenant=5 low-pri",
+	} {
+		require.True(t, q.granted())
+		require.Equal(t, exp, <-admitted)
+	}
+	require.False(t, q.hasWaitingRequests())
+	require.False(t, q.granted())
+	require.Equal(t, int64(4), q.metrics.Admitted.Count())
+
+	// Work done returns the slots.
+	for _, w := range works {
+		q.AdmittedWorkDone(roachpb.MakeTenantID(w.tenantID))
+	}
+	g.mu.Lock()
+	require.Equal(t, 4, g.mu.returned)
+	g.mu.Unlock()
+	q.resetTenantUsage()
+	require.Len(t, q.mu.tenants, 0)
+}
+
+func TestWorkQueueFastPathAndBypass(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	g := &testGranter{}
+	g.mu.available = 1
+	q := makeTestWorkQueue(g)
+
+	// The work is admitted without waiting while a grant is available.
+	enabled, err := q.Admit(ctx, WorkInfo{TenantID: roachpb.SystemTenantID})
+	require.NoError(t, err)
+	require.True(t, enabled)
+	require.Equal(t, int64(0), q.metrics.WaitDurations.TotalCount())
+
+	// Bypassing admission is accounted for by the granter.
+	enabled, err = q.Admit(ctx, WorkInfo{TenantID: roachpb.SystemTenantID, BypassAdmission: true})
+	require.NoError(t, err)
+	require.True(t, enabled)
+	g.mu.Lock()
+	require.Equal(t, 1, g.mu.tookWithoutPermission)
+	g.mu.Unlock()
+	require.Equal(t, uint64(2), q.admittedCountSnapshot())
+
+	// Admission control can be disabled.
+	KVAdmissionControlEnabled.Override(&q.settings.SV, false)
+	enabled, err = q.Admit(ctx, WorkInfo{TenantID: roachpb.SystemTenantID})
+	require.NoError(t, err)
+	require.False(t, enabled)
+	require.Equal(t, int64(2), q.metrics.Requested.Count())
+}
+
+func TestWorkQueueCancel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	g := &testGranter{}
+	q := makeTestWorkQueue(g)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		_, err := q.Admit(ctx, WorkInfo{TenantID: roachpb.SystemTenantID})
+		errCh <- err
+	}()
+	waitForQueueLength(t, q, 1)
+	cancel()
+	err := <-errCh
+	require.True(t, errors.Is(err, context.Canceled), "unexpected error %v", err)
+	require.Equal(t, int64(1), q.metrics.Errored.Count())
+	require.False(t, q.hasWaitingRequests())
+	require.Equal(t, int64(0), q.metrics.WaitQueueLength.Value())
+}
@@ -48,3 +48,18 @@ func TestEventJSON(t *testing.T) {
 		assert.Equal(t, tc.exp, string(b))
 	}
 }
+
+func TestNewEventPayload(t *testing.T) {
+	for _, ev := range []EventPayload{
+		&CreateDatabase{},
+		&ReverseSchemaChange{},
+		&ChangeDatabasePrivilege{},
+		&UnsafeDeleteDescriptor{},
+		&NodeJoin{},
+	} {
+		typeName := GetEventTypeName(ev)
+		assert.Equal(t, ev, NewEventPayload(typeName), typeName)
+	}
+	assert.Nil(t, NewEventPayload("unknown_event"))
+	assert.Nil(t, NewEventPayload("common_event_details"))
+}
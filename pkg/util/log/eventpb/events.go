@@ -16,6 +16,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/redact"
+	"github.com/gogo/protobuf/proto"
 )
 
 // GetEventTypeName retrieves the system.eventlog type name for the given payload.
@@ -35,6 +36,36 @@ func GetEventTypeName(event EventPayload) string {
 	return res.String()
 }
 
+// NewEventPayload returns a new, empty payload for the given system.eventlog
+// type name, as retrieved by GetEventTypeName. It returns nil if there is no
+// payload type with this name.
+func NewEventPayload(eventType string) EventPayload {
+	// This logic takes the type names and converts from snake_case to CamelCase.
+	var typeName strings.Builder
+	upper := true
+	for i := 0; i < len(eventType); i++ {
+		c := eventType[i]
+		switch {
+		case c == '_':
+			upper = true
+			continue
+		case upper && c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		}
+		typeName.WriteByte(c)
+		upper = false
+	}
+	t := proto.MessageType("cockroach.util.log.eventpb." + typeName.String())
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil
+	}
+	event, ok := reflect.New(t.Elem()).Interface().(EventPayload)
+	if !ok {
+		return nil
+	}
+	return event
+}
+
 // EventPayload is implemented by CommonEventDetails.
 type EventPayload interface {
 	// CommonDetails gives access to the common payload.
@@ -65,6 +65,8 @@ func init() {
 	*defaultConfig.Sinks.Stderr.Redactable = false
 	// Remove all sinks other than stderr.
 	defaultConfig.Sinks.FluentServers = nil
+	defaultConfig.Sinks.HTTPServers = nil
+	defaultConfig.Sinks.SyslogServers = nil
 	defaultConfig.Sinks.FileGroups = nil
 
 	if _, err := ApplyConfig(defaultConfig); err != nil {
@@ -300,6 +302,49 @@ func ApplyConfig(config logconfig.Config) (cleanupFn func(), err error) {
 		}
 	}
 
+	// Create the HTTP sinks.
+	for _, fc := range config.Sinks.HTTPServers {
+		if fc.Filter == severity.NONE {
+			continue
+		}
+		httpSinkInfo, httpSink, err := newHTTPSinkInfo(*fc)
+		if err != nil {
+			cleanupFn()
+			return nil, err
+		}
+		sinkInfos = append(sinkInfos, httpSinkInfo)
+		allSinkInfos.put(httpSinkInfo)
+
+		// Start sending the buffered entries.
+		go httpSink.flushDaemon(secLoggersCtx)
+
+		// Connect the channels for this sink.
+		for _, ch := range fc.Channels.Channels {
+			l := chans[ch]
+			l.sinkInfos = append(l.sinkInfos, httpSinkInfo)
+		}
+	}
+
+	// Create the syslog sinks.
+	for _, fc := range config.Sinks.SyslogServers {
+		if fc.Filter == severity.NONE {
+			continue
+		}
+		syslogSinkInfo, err := newSyslogSinkInfo(*fc)
+		if err != nil {
+			cleanupFn()
+			return nil, err
+		}
+		sinkInfos = append(sinkInfos, syslogSinkInfo)
+		allSinkInfos.put(syslogSinkInfo)
+
+		// Connect the channels for this sink.
+		for _, ch := range fc.Channels.Channels {
+			l := chans[ch]
+			l.sinkInfos = append(l.sinkInfos, syslogSinkInfo)
+		}
+	}
+
 	logging.setChannelLoggers(chans, &stderrSinkInfo)
 	setActive()
 
@@ -338,6 +383,48 @@ func newFluentSinkInfo(c logconfig.FluentSinkConfig) (*sinkInfo, error) {
 	return info, nil
 }
 
+// newHTTPSinkInfo creates a new httpSink and its accompanying sinkInfo
+// from the provided configuration.
+func newHTTPSinkInfo(c logconfig.HTTPSinkConfig) (*sinkInfo, *httpSink, error) {
+	info := &sinkInfo{}
+	if err := info.applyConfig(c.CommonSinkConfig); err != nil {
+		return nil, nil, err
+	}
+	httpSink := newHTTPSink(
+		c.Address,
+		*c.Timeout,
+		*c.FlushInterval,
+		int(*c.MaxBatchSize),
+		int(*c.BufferSize),
+		*c.MaxRetries)
+	info.sink = httpSink
+	return info, httpSink, nil
+}
+
+// newSyslogSinkInfo creates a new syslogSink and its accompanying
+// sinkInfo from the provided configuration.
+func newSyslogSinkInfo(c logconfig.SyslogSinkConfig) (*sinkInfo, error) {
+	info := &sinkInfo{}
+	if err := info.applyConfig(c.CommonSinkConfig); err != nil {
+		return nil, err
+	}
+	syslogSink, err := newSyslogSink(c.Net, c.Address, c.TLSCACert)
+	if err != nil {
+		return nil, err
+	}
+	info.sink = syslogSink
+	facility, ok := logconfig.SyslogFacilityCode(*c.Facility)
+	if !ok {
+		return nil, errors.Newf("unknown facility: %q", *c.Facility)
+	}
+	f := syslogFormatter{logFormatter: info.formatter, facility: facility, appName: program}
+	if c.AppName != nil && *c.AppName != "" {
+		f.appName = *c.AppName
+	}
+	info.formatter = f
+	return info, nil
+}
+
 // applyConfig applies a common sink configuration to a sinkInfo.
 func (l *sinkInfo) applyConfig(c logconfig.CommonSinkConfig) error {
 	l.threshold = c.Filter
@@ -495,6 +582,64 @@ func DescribeAppliedConfig() string {
 		return nil
 	})
 
+	// Describe the HTTP sinks.
+	config.Sinks.HTTPServers = make(map[string]*logconfig.HTTPSinkConfig)
+	hIdx := 1
+	_ = allSinkInfos.iter(func(l *sinkInfo) error {
+		httpSink, ok := l.sink.(*httpSink)
+		if !ok {
+			return nil
+		}
+
+		hc := &logconfig.HTTPSinkConfig{}
+		hc.CommonSinkConfig = l.describeAppliedConfig()
+		hc.Address = httpSink.address
+		hc.Timeout = &httpSink.client.Timeout
+		hc.FlushInterval = &httpSink.flushInterval
+		mb := logconfig.ByteSize(httpSink.maxBatchSize)
+		hc.MaxBatchSize = &mb
+		bs := logconfig.ByteSize(httpSink.bufferSize)
+		hc.BufferSize = &bs
+		hc.MaxRetries = &httpSink.maxRetries
+
+		// Describe the connections to this HTTP sink.
+		for ch, logger := range chans {
+			describeConnections(logger, ch, l, &hc.Channels)
+		}
+		skey := fmt.Sprintf("h%d", hIdx)
+		hIdx++
+		config.Sinks.HTTPServers[skey] = hc
+		return nil
+	})
+
+	// Describe the syslog sinks.
+	config.Sinks.SyslogServers = make(map[string]*logconfig.SyslogSinkConfig)
+	yIdx := 1
+	_ = allSinkInfos.iter(func(l *sinkInfo) error {
+		syslogSink, ok := l.sink.(*syslogSink)
+		if !ok {
+			return nil
+		}
+
+		sc := &logconfig.SyslogSinkConfig{}
+		sc.CommonSinkConfig = l.describeAppliedConfig()
+		sc.Net = syslogSink.network
+		sc.Address = syslogSink.addr
+		f := l.formatter.(syslogFormatter)
+		sc.AppName = &f.appName
+		facility := logconfig.SyslogFacilityName(f.facility)
+		sc.Facility = &facility
+
+		// Describe the connections to this syslog sink.
+		for ch, logger := range chans {
+			describeConnections(logger, ch, l, &sc.Channels)
+		}
+		skey := fmt.Sprintf("y%d", yIdx)
+		yIdx++
+		config.Sinks.SyslogServers[skey] = sc
+		return nil
+	})
+
 	// Note: we cannot return 'config' directly, because this captures
 	// certain variables from the loggers by reference and thus could be
 	// invalidated by concurrent uses of ApplyConfig().
@@ -0,0 +1,268 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+// httpSink represents a log collector that accepts batches of log
+// entries in the body of HTTP POST requests.
+//
+// Log entries are buffered in memory and sent asynchronously by
+// flushDaemon(), as a JSON array. This requires the entries to be
+// formatted using one of the JSON formats.
+type httpSink struct {
+	// The URL of the log collector.
+	address string
+	client  http.Client
+
+	// flushInterval is the maximum amount of time entries are buffered
+	// before they are sent.
+	flushInterval time.Duration
+	// maxBatchSize is the approximate maximum size of the body of a
+	// single request.
+	maxBatchSize int
+	// bufferSize is the maximum amount of log data kept in memory.
+	bufferSize int
+	// maxRetries is the number of times a failed request is retried.
+	maxRetries int
+
+	// flushC is signaled when enough entries have been buffered to
+	// fill a batch.
+	flushC chan struct{}
+
+	// sendMu serializes the sending of batches by flushDaemon() and by
+	// output() for fatal events.
+	sendMu syncutil.Mutex
+
+	mu struct {
+		syncutil.Mutex
+		// entries are the formatted entries not sent yet.
+		entries [][]byte
+		// size is the combined size of entries.
+		size int
+		// err is the last error encountered while delivering entries,
+		// reported upon the next call to output().
+		err error
+	}
+}
+
+// httpInitialBackoff and httpMaxBackoff bound the delay between two
+// attempts at sending a batch.
+const httpInitialBackoff = 100 * time.Millisecond
+const httpMaxBackoff = 5 * time.Second
+
+func newHTTPSink(
+	address string,
+	timeout, flushInterval time.Duration,
+	maxBatchSize, bufferSize, maxRetries int,
+) *httpSink {
+	return &httpSink{
+		address:       address,
+		client:        http.Client{Timeout: timeout},
+		flushInterval: flushInterval,
+		maxBatchSize:  maxBatchSize,
+		bufferSize:    bufferSize,
+		maxRetries:    maxRetries,
+		flushC:        make(chan struct{}, 1),
+	}
+}
+
+func (l *httpSink) String() string {
+	return fmt.Sprintf("http:%s", l.address)
+}
+
+// active implements the logSink interface.
+func (l *httpSink) active() bool { return true }
+
+// attachHints implements the logSink interface.
+func (l *httpSink) attachHints(stacks []byte) []byte {
+	return stacks
+}
+
+// exitCode implements the logSink interface.
+func (l *httpSink) exitCode() exit.Code {
+	return exit.LoggingNetCollectorUnavailable()
+}
+
+// output implements the logSink interface.
+//
+// The entry is only buffered: any error returned pertains to the
+// delivery of previous entries.
+func (l *httpSink) output(extraSync bool, b []byte) error {
+	entry := append([]byte(nil), bytes.TrimRight(b, "\n")...)
+
+	l.mu.Lock()
+	err := l.mu.err
+	l.mu.err = nil
+	l.mu.entries = append(l.mu.entries, entry)
+	l.mu.size += len(entry)
+	dropped := 0
+	for l.mu.size > l.bufferSize && len(l.mu.entries) > 1 {
+		l.mu.size -= len(l.mu.entries[0])
+		l.mu.entries = l.mu.entries[1:]
+		dropped++
+	}
+	batchFull := l.mu.size >= l.maxBatchSize
+	l.mu.Unlock()
+
+	if dropped > 0 {
+		err = errors.CombineErrors(err,
+			errors.Newf("%s: buffer full, dropped %d log entries", l, dropped))
+	}
+
+	if extraSync {
+		l.flush(context.Background(), true /* retry */)
+	} else if batchFull {
+		select {
+		case l.flushC <- struct{}{}:
+		default:
+		}
+	}
+	return err
+}
+
+// emergencyOutput implements the logSink interface.
+func (l *httpSink) emergencyOutput(b []byte) {
+	body := l.makeBody([][]byte{bytes.TrimRight(b, "\n")})
+	_ = l.send(body)
+}
+
+// flushDaemon periodically sends the buffered entries, until the
+// context is canceled.
+func (l *httpSink) flushDaemon(ctx context.Context) {
+	ticker := time.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Make a last attempt at delivering the remaining entries,
+			// without retries.
+			l.flush(context.Background(), false /* retry */)
+			l.client.CloseIdleConnections()
+			return
+		case <-ticker.C:
+		case <-l.flushC:
+		}
+		l.flush(ctx, true /* retry */)
+	}
+}
+
+// flush sends the buffered entries, in batches of at most
+// maxBatchSize bytes. It stops at the first batch that cannot be
+// delivered after maxRetries retries; the entries of that batch are
+// dropped. If retry is false, failed requests are not retried. The
+// context only interrupts the wait between two attempts: requests in
+// flight are bounded by the timeout of the sink instead, so that the
+// entries buffered upon shutdown still have a chance to be delivered.
+func (l *httpSink) flush(ctx context.Context, retry bool) {
+	l.sendMu.Lock()
+	defer l.sendMu.Unlock()
+	for {
+		batch := l.takeBatch()
+		if len(batch) == 0 {
+			return
+		}
+		if err := l.sendWithRetries(ctx, l.makeBody(batch), retry); err != nil {
+			err = errors.Wrapf(err, "%s: dropped %d log entries", l, len(batch))
+			fmt.Fprintf(OrigStderr, "%v\n", err)
+			l.mu.Lock()
+			l.mu.err = err
+			l.mu.Unlock()
+			return
+		}
+	}
+}
+
+// takeBatch removes the oldest entries from the buffer, up to
+// maxBatchSize bytes but at least one entry if the buffer is not
+// empty.
+func (l *httpSink) takeBatch() [][]byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n, size := 0, 0
+	for n < len(l.mu.entries) && (n == 0 || size+len(l.mu.entries[n]) <= l.maxBatchSize) {
+		size += len(l.mu.entries[n])
+		n++
+	}
+	batch := l.mu.entries[:n:n]
+	l.mu.entries = l.mu.entries[n:]
+	l.mu.size -= size
+	return batch
+}
+
+// makeBody assembles JSON entries into a JSON array.
+func (l *httpSink) makeBody(entries [][]byte) []byte {
+	size := 2
+	for _, e := range entries {
+		size += len(e) + 1
+	}
+	body := make([]byte, 0, size)
+	body = append(body, '[')
+	for i, e := range entries {
+		if i > 0 {
+			body = append(body, ',')
+		}
+		body = append(body, e...)
+	}
+	return append(body, ']')
+}
+
+func (l *httpSink) sendWithRetries(ctx context.Context, body []byte, retry bool) error {
+	if !retry {
+		return l.send(body)
+	}
+	backoff := httpInitialBackoff
+	for i := 0; ; i++ {
+		err := l.send(body)
+		if err == nil || i >= l.maxRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.CombineErrors(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > httpMaxBackoff {
+			backoff = httpMaxBackoff
+		}
+	}
+}
+
+func (l *httpSink) send(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, l.address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Newf("unexpected HTTP status: %s", resp.Status)
+	}
+	return nil
+}
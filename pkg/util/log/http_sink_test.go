@@ -0,0 +1,118 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := Scope(t)
+	defer sc.Close(t)
+
+	// The server fails the first request, to exercise the retries, and
+	// reports the bodies of the subsequent requests over the channel.
+	var numRequests int32
+	bodies := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&numRequests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		bodies <- body
+	}))
+	defer server.Close()
+
+	// Set up a logging configuration with the server we've just set up
+	// as target for the OPS channel. The flush interval is long enough
+	// for both events below to be sent in the same batch.
+	cfg := logconfig.DefaultConfig()
+	flushInterval := 200 * time.Millisecond
+	cfg.Sinks.HTTPServers = map[string]*logconfig.HTTPSinkConfig{
+		"ops": {
+			Address:       server.URL,
+			FlushInterval: &flushInterval,
+			Channels:      logconfig.ChannelList{Channels: []Channel{channel.OPS}}},
+	}
+	// Derive a full config using the same directory as the
+	// TestLogScope.
+	require.NoError(t, cfg.Validate(&sc.logDir))
+
+	// Apply the configuration.
+	TestingResetActive()
+	cleanup, err := ApplyConfig(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+
+	// Send two log events on the OPS channel.
+	Ops.Infof(context.Background(), "hello world")
+	Ops.Warningf(context.Background(), "hello %s", Safe("again"))
+
+	// Check that the events were sent as one batch via the HTTP sink.
+	var body []byte
+	select {
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	case body = <-bodies:
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(body, &entries); err != nil {
+		t.Fatalf("unable to decode json: %q: %v", body, err)
+	}
+	require.Len(t, entries, 2)
+	require.Equal(t, "hello world", entries[0]["message"])
+	require.Equal(t, "I", entries[0]["sev"])
+	require.Equal(t, "hello again", entries[1]["message"])
+	require.Equal(t, "W", entries[1]["sev"])
+	require.Equal(t, int32(2), atomic.LoadInt32(&numRequests))
+}
+
+func TestHTTPSinkBuffering(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s := newHTTPSink("http://invalid", time.Second, time.Hour,
+		15 /* maxBatchSize */, 20 /* bufferSize */, 0 /* maxRetries */)
+
+	// Entries are buffered until the buffer is full; then the oldest
+	// entries are dropped.
+	require.NoError(t, s.output(false /* extraSync */, []byte("\"aaaaaa\"\n")))
+	require.NoError(t, s.output(false /* extraSync */, []byte("\"bbbbbb\"\n")))
+	require.Regexp(t, "buffer full, dropped 1 log entries",
+		s.output(false /* extraSync */, []byte("\"cccccc\"\n")))
+	require.Regexp(t, "buffer full, dropped 1 log entries",
+		s.output(false /* extraSync */, []byte("\"dddddddddd\"\n")))
+
+	// Batches do not exceed the maximum batch size, unless they contain
+	// a single entry.
+	require.Equal(t, `["cccccc"]`, string(s.makeBody(s.takeBatch())))
+	require.Equal(t, `["dddddddddd"]`, string(s.makeBody(s.takeBatch())))
+	require.Len(t, s.takeBatch(), 0)
+}
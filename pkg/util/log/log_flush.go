@@ -26,7 +26,8 @@ type flushSyncWriter interface {
 	io.Writer
 }
 
-// Flush explicitly flushes all pending log file I/O.
+// Flush explicitly flushes all pending log file I/O, and sends the
+// log entries buffered by HTTP sinks.
 // See also flushDaemon() that manages background (asynchronous)
 // flushes, and signalFlusher() that manages flushes in reaction to a
 // user signal.
@@ -35,6 +36,10 @@ func Flush() {
 		l.lockAndFlushAndMaybeSync(true /*doSync*/)
 		return nil
 	})
+	_ = allSinkInfos.iterHTTPSinks(func(l *httpSink) error {
+		l.flush(context.Background(), true /* retry */)
+		return nil
+	})
 }
 
 func init() {
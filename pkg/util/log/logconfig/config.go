@@ -14,7 +14,9 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
 	"github.com/cockroachdb/errors"
@@ -34,6 +36,25 @@ const DefaultStderrFormat = `crdb-v2-tty`
 // when not specified in a configuration.
 const DefaultFluentFormat = `json-fluent-compact`
 
+// DefaultHTTPFormat is the entry format for HTTP sinks
+// when not specified in a configuration.
+const DefaultHTTPFormat = `json-compact`
+
+// DefaultSyslogFormat is the entry format for syslog sinks
+// when not specified in a configuration.
+const DefaultSyslogFormat = `json-compact`
+
+// The following are the defaults for the HTTP and syslog sink
+// parameters when not specified in a configuration.
+const (
+	defaultHTTPTimeout       = 2 * time.Second
+	defaultHTTPFlushInterval = time.Second
+	defaultHTTPMaxBatchSize  = 512 << 10
+	defaultHTTPBufferSize    = 16 << 20
+	defaultHTTPMaxRetries    = 3
+	defaultSyslogFacility    = "user"
+)
+
 // DefaultConfig returns a suitable default configuration when logging
 // is meant to primarily go to files.
 func DefaultConfig() (c Config) {
@@ -53,6 +74,16 @@ fluent-defaults:
     format: ` + DefaultFluentFormat + `
     redactable: true
     exit-on-error: false
+http-defaults:
+    filter: INFO
+    format: ` + DefaultHTTPFormat + `
+    redactable: true
+    exit-on-error: false
+syslog-defaults:
+    filter: INFO
+    format: ` + DefaultSyslogFormat + `
+    redactable: true
+    exit-on-error: false
 sinks:
   stderr:
     filter: NONE
@@ -101,6 +132,16 @@ type Config struct {
 	// configuration value.
 	FluentDefaults FluentDefaults `yaml:"fluent-defaults,omitempty"`
 
+	// HTTPDefaults represents the default configuration for HTTP sinks,
+	// inherited when a specific HTTP sink config does not provide a
+	// configuration value.
+	HTTPDefaults HTTPDefaults `yaml:"http-defaults,omitempty"`
+
+	// SyslogDefaults represents the default configuration for syslog
+	// sinks, inherited when a specific syslog sink config does not
+	// provide a configuration value.
+	SyslogDefaults SyslogDefaults `yaml:"syslog-defaults,omitempty"`
+
 	// Sinks represents the sink configurations.
 	Sinks SinkConfig `yaml:",omitempty"`
 
@@ -163,13 +204,20 @@ type SinkConfig struct {
 	FileGroups map[string]*FileSinkConfig `yaml:"file-groups,omitempty"`
 	// FluentServer represents the list of configured fluent sinks.
 	FluentServers map[string]*FluentSinkConfig `yaml:"fluent-servers,omitempty"`
+	// HTTPServers represents the list of configured HTTP sinks.
+	HTTPServers map[string]*HTTPSinkConfig `yaml:"http-servers,omitempty"`
+	// SyslogServers represents the list of configured syslog sinks.
+	SyslogServers map[string]*SyslogSinkConfig `yaml:"syslog-servers,omitempty"`
 	// Stderr represents the configuration for the stderr sink.
 	Stderr StderrSinkConfig `yaml:",omitempty"`
 
-	// sortedFileGroupNames and sortedServerNames are used internally to
-	// make the Export() function deterministic.
-	sortedFileGroupNames []string
-	sortedServerNames    []string
+	// sortedFileGroupNames, sortedServerNames, sortedHTTPServerNames
+	// and sortedSyslogServerNames are used internally to make the
+	// Export() function deterministic.
+	sortedFileGroupNames    []string
+	sortedServerNames       []string
+	sortedHTTPServerNames   []string
+	sortedSyslogServerNames []string
 }
 
 // StderrSinkConfig represents the configuration for the stderr sink.
@@ -314,6 +362,294 @@ type FluentSinkConfig struct {
 	serverName string
 }
 
+// HTTPDefaults represent configuration defaults for HTTP sinks.
+type HTTPDefaults struct {
+	// Timeout stores the default timeout for each HTTP request.
+	Timeout *time.Duration `yaml:",omitempty"`
+
+	// FlushInterval stores the default maximum amount of time log
+	// entries are buffered before they are sent.
+	FlushInterval *time.Duration `yaml:"flush-interval,omitempty"`
+
+	// MaxBatchSize stores the default approximate maximum size of the
+	// payload of a single HTTP request.
+	MaxBatchSize *ByteSize `yaml:"max-batch-size,omitempty"`
+
+	// BufferSize stores the default maximum amount of log data kept in
+	// memory while it cannot be delivered.
+	BufferSize *ByteSize `yaml:"buffer-size,omitempty"`
+
+	// MaxRetries stores the default number of times a failed request
+	// is retried before its log entries are dropped.
+	MaxRetries *int `yaml:"max-retries,omitempty"`
+
+	// CommonSinkConfig is the configuration common to all sinks. Note
+	// that although the idiom in Go is to place embedded fields at the
+	// beginning of a struct, we purposefully deviate from the idiom
+	// here to ensure that "general" options appear after the
+	// sink-specific options in YAML config dumps.
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// HTTPSinkConfig represents the configuration for one HTTP sink.
+//
+// User-facing documentation follows.
+// TITLE: output to HTTP servers
+//
+// This sink type causes logging data to be sent over the network, to
+// a log collector that accepts log events in the body of HTTP POST
+// requests.
+//
+// Log entries are accumulated in memory and sent in batches, as a
+// JSON array containing one JSON object per log entry. For this
+// reason, only the JSON formats are supported by this sink. A batch
+// is sent every `flush-interval`, or as soon as it reaches
+// `max-batch-size`. Fatal events are sent immediately.
+//
+// A request that fails, either because of a network error or because
+// the server did not reply with a 2xx status code, is retried up to
+// `max-retries` times with exponential backoff. Log entries that
+// cannot be delivered after that are dropped and an error is reported
+// to the process' standard error output. While a server is
+// unavailable, up to `buffer-size` bytes of log data are kept in
+// memory; beyond that, the oldest log entries are dropped.
+//
+// Because delivery is asynchronous, delivery errors are reported to
+// the logging system (and cause the process to terminate if
+// `exit-on-error` is set) upon the next logging event sent to the
+// sink.
+//
+// The configuration key under the `sinks` key in the YAML
+// configuration is `http-servers`. Example configuration:
+//
+//     sinks:
+//        http-servers:          # HTTP configurations start here
+//           siem:               # defines one sink called "siem"
+//              channels: [SESSIONS, SENSITIVE_ACCESS]
+//              address: https://siem.example.com:8088/ingest
+//
+// A cascading defaults mechanism is available for configurations:
+// every new server sink configured automatically inherits the
+// configurations set in the `http-defaults` section.
+//
+// For example:
+//
+//      http-defaults:
+//          redact: true # default: remove sensitive data
+//      sinks:
+//        http-servers:
+//          siem:
+//             channels: [SESSIONS, SENSITIVE_ACCESS]
+//             address: https://siem.example.com:8088/ingest
+//             # This sink has redact set to true,
+//             # as the setting is inherited from http-defaults
+//             # unless overridden here.
+//
+// The default output format for HTTP sinks is `json-compact`.
+//
+// Users are invited to peruse the `check-log-config` tool to
+// verify the effect of defaults inheritance.
+//
+type HTTPSinkConfig struct {
+	// Channels is the list of logging channels that use this sink.
+	Channels ChannelList `yaml:",omitempty,flow"`
+
+	// Address is the URL of the HTTP server, including the scheme
+	// (`http` or `https`) and the path. User credentials included in
+	// the URL are sent using HTTP basic authentication.
+	Address string `yaml:""`
+
+	// Timeout is the maximum amount of time to wait for a reply to each
+	// HTTP request. Inherited from `http-defaults.timeout` if not
+	// specified.
+	Timeout *time.Duration `yaml:",omitempty"`
+
+	// FlushInterval is the maximum amount of time log entries are
+	// buffered before they are sent. Inherited from
+	// `http-defaults.flush-interval` if not specified.
+	FlushInterval *time.Duration `yaml:"flush-interval,omitempty"`
+
+	// MaxBatchSize is the approximate maximum size of the payload of a
+	// single HTTP request. Inherited from `http-defaults.max-batch-size`
+	// if not specified.
+	MaxBatchSize *ByteSize `yaml:"max-batch-size,omitempty"`
+
+	// BufferSize is the maximum amount of log data kept in memory
+	// while it cannot be delivered. Beyond this size, the oldest log
+	// entries are dropped. Inherited from `http-defaults.buffer-size` if
+	// not specified.
+	BufferSize *ByteSize `yaml:"buffer-size,omitempty"`
+
+	// MaxRetries is the number of times a failed request is retried
+	// before its log entries are dropped. Inherited from
+	// `http-defaults.max-retries` if not specified.
+	MaxRetries *int `yaml:"max-retries,omitempty"`
+
+	// CommonSinkConfig is the configuration common to all sinks. Note
+	// that although the idiom in Go is to place embedded fields at the
+	// beginning of a struct, we purposefully deviate from the idiom
+	// here to ensure that "general" options appear after the
+	// sink-specific options in YAML config dumps.
+	CommonSinkConfig `yaml:",inline"`
+
+	// serverName is populated/used during validation.
+	serverName string
+}
+
+// SyslogDefaults represent configuration defaults for syslog sinks.
+type SyslogDefaults struct {
+	// AppName stores the default APP-NAME field of syslog messages.
+	AppName *string `yaml:"app-name,omitempty"`
+
+	// Facility stores the default facility of syslog messages.
+	Facility *string `yaml:",omitempty"`
+
+	// CommonSinkConfig is the configuration common to all sinks. Note
+	// that although the idiom in Go is to place embedded fields at the
+	// beginning of a struct, we purposefully deviate from the idiom
+	// here to ensure that "general" options appear after the
+	// sink-specific options in YAML config dumps.
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// SyslogSinkConfig represents the configuration for one syslog sink.
+//
+// User-facing documentation follows.
+// TITLE: output to syslog servers
+//
+// This sink type causes logging data to be sent over the network, to
+// a syslog server. Log events are sent as syslog messages in the
+// [RFC 5424](https://tools.ietf.org/html/rfc5424) format, one
+// message per log entry. The severity of the syslog message is
+// derived from the severity of the log entry; the formatted log
+// entry is sent as the message text.
+//
+// Messages can be sent over UDP
+// ([RFC 5426](https://tools.ietf.org/html/rfc5426)), or over TCP or
+// TLS using octet-counting framing
+// ([RFC 5425](https://tools.ietf.org/html/rfc5425)). With TLS, the
+// certificate of the server is verified using the CA certificate in
+// `tls-ca-cert` if specified, or the system's trusted CAs otherwise.
+//
+// At the time of this writing, a syslog sink does not buffer log
+// entries and retries sending an event at most one time if a network
+// error is encountered, like the Fluent sink.
+//
+// The configuration key under the `sinks` key in the YAML
+// configuration is `syslog-servers`. Example configuration:
+//
+//     sinks:
+//        syslog-servers:        # syslog configurations start here
+//           siem:               # defines one sink called "siem"
+//              channels: [SESSIONS, SENSITIVE_ACCESS]
+//              net: tls
+//              address: siem.example.com:6514
+//
+// A cascading defaults mechanism is available for configurations:
+// every new server sink configured automatically inherits the
+// configurations set in the `syslog-defaults` section.
+//
+// For example:
+//
+//      syslog-defaults:
+//          facility: local0 # default: use facility local0
+//      sinks:
+//        syslog-servers:
+//          siem:
+//             channels: [SESSIONS, SENSITIVE_ACCESS]
+//             address: siem.example.com:514
+//             # This sink uses facility local0,
+//             # as the setting is inherited from syslog-defaults
+//             # unless overridden here.
+//
+// The default output format for syslog sinks is `json-compact`.
+//
+// Users are invited to peruse the `check-log-config` tool to
+// verify the effect of defaults inheritance.
+//
+type SyslogSinkConfig struct {
+	// Channels is the list of logging channels that use this sink.
+	Channels ChannelList `yaml:",omitempty,flow"`
+
+	// Net is the protocol for the syslog server. Can be "udp", "tcp",
+	// "tls", "udp4", "tcp6", etc. Defaults to "udp".
+	Net string `yaml:",omitempty"`
+
+	// Address is the network address of the syslog server. The
+	// host/address and port parts are separated with a colon. IPv6
+	// numeric addresses should be included within square brackets,
+	// e.g.: [::1]:514.
+	Address string `yaml:""`
+
+	// TLSCACert is the path to a PEM file containing the CA
+	// certificate used to verify the certificate of the server when
+	// `net` is `tls`.
+	TLSCACert string `yaml:"tls-ca-cert,omitempty"`
+
+	// AppName is the APP-NAME field of the syslog messages. Inherited
+	// from `syslog-defaults.app-name` if not specified. Defaults to the
+	// name of the process executable.
+	AppName *string `yaml:"app-name,omitempty"`
+
+	// Facility is the facility of the syslog messages, e.g. "user",
+	// "daemon" or "local0". Inherited from `syslog-defaults.facility`
+	// if not specified.
+	Facility *string `yaml:",omitempty"`
+
+	// CommonSinkConfig is the configuration common to all sinks. Note
+	// that although the idiom in Go is to place embedded fields at the
+	// beginning of a struct, we purposefully deviate from the idiom
+	// here to ensure that "general" options appear after the
+	// sink-specific options in YAML config dumps.
+	CommonSinkConfig `yaml:",inline"`
+
+	// serverName is populated/used during validation.
+	serverName string
+}
+
+// syslogFacilities maps the names of the syslog facilities to their
+// numerical codes, as defined in RFC 5424.
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// SyslogFacilityCode returns the numerical code of the syslog facility
+// with the given name.
+func SyslogFacilityCode(name string) (code int, ok bool) {
+	code, ok = syslogFacilities[strings.ToLower(name)]
+	return code, ok
+}
+
+// SyslogFacilityName returns the name of the syslog facility with the
+// given numerical code.
+func SyslogFacilityName(code int) string {
+	for name, c := range syslogFacilities {
+		if c == code {
+			return name
+		}
+	}
+	return strconv.Itoa(code)
+}
+
 // FileDefaults represent configuration defaults for file sinks.
 type FileDefaults struct {
 	// Dir stores the default output directory for file sinks.
@@ -198,6 +198,50 @@ func (c *Config) Export(onlyChans ChannelList) (string, string) {
 		}
 	}
 
+	// Collect the HTTP servers.
+	httpServers := map[string]string{}
+	for _, fn := range c.Sinks.sortedHTTPServerNames {
+		fc := c.Sinks.HTTPServers[fn]
+		skey := fmt.Sprintf("h__%s", fc.serverName)
+		target, thisprocs, thislinks := process(skey, fc.CommonSinkConfig)
+		hasLink := false
+		for _, ch := range fc.Channels.Channels {
+			if !chanSel.HasChannel(ch) {
+				continue
+			}
+			hasLink = true
+			links = append(links, fmt.Sprintf("%s --> %s", ch, target))
+		}
+		if hasLink {
+			processing = append(processing, thisprocs...)
+			links = append(links, thislinks...)
+			httpServers[fc.serverName] = fmt.Sprintf("queue %s as \"http: %s\"",
+				skey, fc.Address)
+		}
+	}
+
+	// Collect the syslog servers.
+	syslogServers := map[string]string{}
+	for _, fn := range c.Sinks.sortedSyslogServerNames {
+		fc := c.Sinks.SyslogServers[fn]
+		skey := fmt.Sprintf("y__%s", fc.serverName)
+		target, thisprocs, thislinks := process(skey, fc.CommonSinkConfig)
+		hasLink := false
+		for _, ch := range fc.Channels.Channels {
+			if !chanSel.HasChannel(ch) {
+				continue
+			}
+			hasLink = true
+			links = append(links, fmt.Sprintf("%s --> %s", ch, target))
+		}
+		if hasLink {
+			processing = append(processing, thisprocs...)
+			links = append(links, thislinks...)
+			syslogServers[fc.serverName] = fmt.Sprintf("queue %s as \"syslog: %s:%s\"",
+				skey, fc.Net, fc.Address)
+		}
+	}
+
 	// Export the stderr redirects.
 	if c.Sinks.Stderr.Filter != logpb.Severity_NONE {
 		target, thisprocs, thislinks := process("stderr", c.Sinks.Stderr.CommonSinkConfig)
@@ -238,10 +282,22 @@ func (c *Config) Export(onlyChans ChannelList) (string, string) {
 	}
 
 	// Represent the network servers, if any.
-	if len(c.Sinks.sortedServerNames) > 0 {
+	if len(servers)+len(httpServers)+len(syslogServers) > 0 {
 		buf.WriteString("cloud network {\n")
 		for _, s := range c.Sinks.sortedServerNames {
-			fmt.Fprintf(&buf, " %s\n", servers[s])
+			if decl, ok := servers[s]; ok {
+				fmt.Fprintf(&buf, " %s\n", decl)
+			}
+		}
+		for _, s := range c.Sinks.sortedHTTPServerNames {
+			if decl, ok := httpServers[s]; ok {
+				fmt.Fprintf(&buf, " %s\n", decl)
+			}
+		}
+		for _, s := range c.Sinks.sortedSyslogServerNames {
+			if decl, ok := syslogServers[s]; ok {
+				fmt.Fprintf(&buf, " %s\n", decl)
+			}
 		}
 		buf.WriteString("}\n")
 	}
@@ -235,7 +235,7 @@ func readInput(infos map[string]*sinkInfo) error {
 	return nil
 }
 
-var configStructRe = regexp.MustCompile(`^type (?P<name>[A-Z][a-z0-9]*)SinkConfig struct`)
+var configStructRe = regexp.MustCompile(`^type (?P<name>[A-Z][A-Za-z0-9]*)SinkConfig struct`)
 
 var fieldDefRe = regexp.MustCompile(`^\s*` +
 	// Field name in Go.
@@ -13,6 +13,7 @@ package logconfig
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -49,6 +50,12 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	if c.FluentDefaults.Filter == logpb.Severity_UNKNOWN {
 		c.FluentDefaults.Filter = logpb.Severity_INFO
 	}
+	if c.HTTPDefaults.Filter == logpb.Severity_UNKNOWN {
+		c.HTTPDefaults.Filter = logpb.Severity_INFO
+	}
+	if c.SyslogDefaults.Filter == logpb.Severity_UNKNOWN {
+		c.SyslogDefaults.Filter = logpb.Severity_INFO
+	}
 	// Sinks are not auditable by default.
 	if c.FileDefaults.Auditable == nil {
 		c.FileDefaults.Auditable = &bf
@@ -56,6 +63,12 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	if c.FluentDefaults.Auditable == nil {
 		c.FluentDefaults.Auditable = &bf
 	}
+	if c.HTTPDefaults.Auditable == nil {
+		c.HTTPDefaults.Auditable = &bf
+	}
+	if c.SyslogDefaults.Auditable == nil {
+		c.SyslogDefaults.Auditable = &bf
+	}
 	// File sinks are buffered by default.
 	if c.FileDefaults.BufferedWrites == nil {
 		c.FileDefaults.BufferedWrites = &bt
@@ -69,6 +82,14 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		s := DefaultFluentFormat
 		c.FluentDefaults.Format = &s
 	}
+	if c.HTTPDefaults.Format == nil {
+		s := DefaultHTTPFormat
+		c.HTTPDefaults.Format = &s
+	}
+	if c.SyslogDefaults.Format == nil {
+		s := DefaultSyslogFormat
+		c.SyslogDefaults.Format = &s
+	}
 	// No redaction markers -> default keep them.
 	if c.FileDefaults.Redactable == nil {
 		c.FileDefaults.Redactable = &bt
@@ -76,6 +97,12 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	if c.FluentDefaults.Redactable == nil {
 		c.FluentDefaults.Redactable = &bt
 	}
+	if c.HTTPDefaults.Redactable == nil {
+		c.HTTPDefaults.Redactable = &bt
+	}
+	if c.SyslogDefaults.Redactable == nil {
+		c.SyslogDefaults.Redactable = &bt
+	}
 	// No redaction specification -> default false.
 	if c.FileDefaults.Redact == nil {
 		c.FileDefaults.Redact = &bf
@@ -83,13 +110,51 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	if c.FluentDefaults.Redact == nil {
 		c.FluentDefaults.Redact = &bf
 	}
-	// No criticality -> default true for files, false for fluent.
+	if c.HTTPDefaults.Redact == nil {
+		c.HTTPDefaults.Redact = &bf
+	}
+	if c.SyslogDefaults.Redact == nil {
+		c.SyslogDefaults.Redact = &bf
+	}
+	// No criticality -> default true for files, false for network sinks.
 	if c.FileDefaults.Criticality == nil {
 		c.FileDefaults.Criticality = &bt
 	}
 	if c.FluentDefaults.Criticality == nil {
 		c.FluentDefaults.Criticality = &bf
 	}
+	if c.HTTPDefaults.Criticality == nil {
+		c.HTTPDefaults.Criticality = &bf
+	}
+	if c.SyslogDefaults.Criticality == nil {
+		c.SyslogDefaults.Criticality = &bf
+	}
+	// HTTP-specific defaults.
+	if c.HTTPDefaults.Timeout == nil {
+		d := defaultHTTPTimeout
+		c.HTTPDefaults.Timeout = &d
+	}
+	if c.HTTPDefaults.FlushInterval == nil {
+		d := defaultHTTPFlushInterval
+		c.HTTPDefaults.FlushInterval = &d
+	}
+	if c.HTTPDefaults.MaxBatchSize == nil {
+		sz := ByteSize(defaultHTTPMaxBatchSize)
+		c.HTTPDefaults.MaxBatchSize = &sz
+	}
+	if c.HTTPDefaults.BufferSize == nil {
+		sz := ByteSize(defaultHTTPBufferSize)
+		c.HTTPDefaults.BufferSize = &sz
+	}
+	if c.HTTPDefaults.MaxRetries == nil {
+		n := defaultHTTPMaxRetries
+		c.HTTPDefaults.MaxRetries = &n
+	}
+	// Syslog-specific defaults.
+	if c.SyslogDefaults.Facility == nil {
+		f := defaultSyslogFacility
+		c.SyslogDefaults.Facility = &f
+	}
 
 	// Validate and fill in defaults for file sinks.
 	for prefix, fc := range c.Sinks.FileGroups {
@@ -115,6 +180,30 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	// Validate and defaults for HTTP.
+	for serverName, fc := range c.Sinks.HTTPServers {
+		if fc == nil {
+			fc = &HTTPSinkConfig{}
+			c.Sinks.HTTPServers[serverName] = fc
+		}
+		fc.serverName = serverName
+		if err := c.validateHTTPSinkConfig(fc); err != nil {
+			fmt.Fprintf(&errBuf, "http server %q: %v\n", serverName, err)
+		}
+	}
+
+	// Validate and defaults for syslog.
+	for serverName, fc := range c.Sinks.SyslogServers {
+		if fc == nil {
+			fc = &SyslogSinkConfig{}
+			c.Sinks.SyslogServers[serverName] = fc
+		}
+		fc.serverName = serverName
+		if err := c.validateSyslogSinkConfig(fc); err != nil {
+			fmt.Fprintf(&errBuf, "syslog server %q: %v\n", serverName, err)
+		}
+	}
+
 	// Defaults for stderr.
 	c.inheritCommonDefaults(&c.Sinks.Stderr.CommonSinkConfig, &c.FileDefaults.CommonSinkConfig)
 	if c.Sinks.Stderr.Filter == logpb.Severity_UNKNOWN {
@@ -136,6 +225,10 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 	fileSinks := make(map[logpb.Channel]*FileSinkConfig)
 	// fluentSinks maps channels to fluent servers.
 	fluentSinks := make(map[logpb.Channel]*FluentSinkConfig)
+	// httpSinks maps channels to HTTP servers.
+	httpSinks := make(map[logpb.Channel]*HTTPSinkConfig)
+	// syslogSinks maps channels to syslog servers.
+	syslogSinks := make(map[logpb.Channel]*SyslogSinkConfig)
 
 	// Check that no channel is listed by more than one file sink,
 	// and every file has at least one channel.
@@ -175,6 +268,40 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	// Check that no channel is listed by more than one HTTP sink, and
+	// every sink has at least one channel.
+	for _, fc := range c.Sinks.HTTPServers {
+		if len(fc.Channels.Channels) == 0 {
+			fmt.Fprintf(&errBuf, "http server %q: no channel selected\n", fc.serverName)
+		}
+		fc.Channels.Sort()
+		for _, ch := range fc.Channels.Channels {
+			if prev := httpSinks[ch]; prev != nil {
+				fmt.Fprintf(&errBuf, "http server %q: channel %s already captured by server %q\n",
+					fc.serverName, ch, prev.serverName)
+			} else {
+				httpSinks[ch] = fc
+			}
+		}
+	}
+
+	// Check that no channel is listed by more than one syslog sink, and
+	// every sink has at least one channel.
+	for _, fc := range c.Sinks.SyslogServers {
+		if len(fc.Channels.Channels) == 0 {
+			fmt.Fprintf(&errBuf, "syslog server %q: no channel selected\n", fc.serverName)
+		}
+		fc.Channels.Sort()
+		for _, ch := range fc.Channels.Channels {
+			if prev := syslogSinks[ch]; prev != nil {
+				fmt.Fprintf(&errBuf, "syslog server %q: channel %s already captured by server %q\n",
+					fc.serverName, ch, prev.serverName)
+			} else {
+				syslogSinks[ch] = fc
+			}
+		}
+	}
+
 	// If capture-stray-errors was enabled, then perform some additional
 	// validation on it.
 	if c.CaptureFd2.Enable {
@@ -257,12 +384,34 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	// Ditto for the HTTP and syslog servers.
+	httpServerNames := make([]string, 0, len(c.Sinks.HTTPServers))
+	for serverName, fc := range c.Sinks.HTTPServers {
+		if fc.Filter == logpb.Severity_NONE {
+			delete(c.Sinks.HTTPServers, serverName)
+		} else {
+			httpServerNames = append(httpServerNames, serverName)
+		}
+	}
+	syslogServerNames := make([]string, 0, len(c.Sinks.SyslogServers))
+	for serverName, fc := range c.Sinks.SyslogServers {
+		if fc.Filter == logpb.Severity_NONE {
+			delete(c.Sinks.SyslogServers, serverName)
+		} else {
+			syslogServerNames = append(syslogServerNames, serverName)
+		}
+	}
+
 	// Remember the sorted names, so we get deterministic output in
 	// export.
 	sort.Strings(fileGroupNames)
 	c.Sinks.sortedFileGroupNames = fileGroupNames
 	sort.Strings(serverNames)
 	c.Sinks.sortedServerNames = serverNames
+	sort.Strings(httpServerNames)
+	c.Sinks.sortedHTTPServerNames = httpServerNames
+	sort.Strings(syslogServerNames)
+	c.Sinks.sortedSyslogServerNames = syslogServerNames
 
 	return nil
 }
@@ -363,6 +512,123 @@ func (c *Config) validateFluentSinkConfig(fc *FluentSinkConfig) error {
 	return nil
 }
 
+func (c *Config) validateHTTPSinkConfig(fc *HTTPSinkConfig) error {
+	c.inheritCommonDefaults(&fc.CommonSinkConfig, &c.HTTPDefaults.CommonSinkConfig)
+
+	// Inherit HTTP-specific defaults.
+	if fc.Timeout == nil {
+		fc.Timeout = c.HTTPDefaults.Timeout
+	}
+	if fc.FlushInterval == nil {
+		fc.FlushInterval = c.HTTPDefaults.FlushInterval
+	}
+	if fc.MaxBatchSize == nil {
+		fc.MaxBatchSize = c.HTTPDefaults.MaxBatchSize
+	}
+	if fc.BufferSize == nil {
+		fc.BufferSize = c.HTTPDefaults.BufferSize
+	}
+	if fc.MaxRetries == nil {
+		fc.MaxRetries = c.HTTPDefaults.MaxRetries
+	}
+
+	fc.Address = strings.TrimSpace(fc.Address)
+	if fc.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	u, err := url.Parse(fc.Address)
+	if err != nil {
+		return errors.Wrap(err, "invalid address")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Newf("unsupported URL scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("address must contain a host")
+	}
+	if !strings.HasPrefix(*fc.Format, "json") {
+		return errors.Newf("unsupported format: %q; only JSON formats can be used", *fc.Format)
+	}
+	if *fc.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	if *fc.FlushInterval <= 0 {
+		return errors.New("flush-interval must be positive")
+	}
+	if *fc.MaxBatchSize == 0 {
+		return errors.New("max-batch-size must be positive")
+	}
+	if *fc.BufferSize < *fc.MaxBatchSize {
+		return errors.New("buffer-size cannot be smaller than max-batch-size")
+	}
+	if *fc.MaxRetries < 0 {
+		return errors.New("max-retries cannot be negative")
+	}
+
+	// Apply the auditable flag if set.
+	if *fc.Auditable {
+		bt := true
+		fc.Criticality = &bt
+	}
+	fc.Auditable = nil
+
+	return nil
+}
+
+func (c *Config) validateSyslogSinkConfig(fc *SyslogSinkConfig) error {
+	c.inheritCommonDefaults(&fc.CommonSinkConfig, &c.SyslogDefaults.CommonSinkConfig)
+
+	// Inherit syslog-specific defaults.
+	if fc.AppName == nil {
+		fc.AppName = c.SyslogDefaults.AppName
+	}
+	if fc.Facility == nil {
+		fc.Facility = c.SyslogDefaults.Facility
+	}
+
+	fc.Net = strings.ToLower(strings.TrimSpace(fc.Net))
+	switch fc.Net {
+	case "tcp", "tcp4", "tcp6":
+	case "udp", "udp4", "udp6":
+	case "tls":
+	case "":
+		fc.Net = "udp"
+	default:
+		return errors.Newf("unknown protocol: %q", fc.Net)
+	}
+	fc.Address = strings.TrimSpace(fc.Address)
+	if fc.Address == "" {
+		return errors.New("address cannot be empty")
+	}
+	if fc.TLSCACert != "" && fc.Net != "tls" {
+		return errors.New("tls-ca-cert can only be specified with net: tls")
+	}
+	if fc.AppName != nil {
+		// RFC 5424 restricts the APP-NAME field to 48 printable ASCII
+		// characters.
+		if len(*fc.AppName) > 48 {
+			return errors.Newf("app-name too long: %q", *fc.AppName)
+		}
+		for _, r := range *fc.AppName {
+			if r <= ' ' || r > '~' {
+				return errors.Newf("invalid character in app-name: %q", *fc.AppName)
+			}
+		}
+	}
+	if _, ok := SyslogFacilityCode(*fc.Facility); !ok {
+		return errors.Newf("unknown facility: %q", *fc.Facility)
+	}
+
+	// Apply the auditable flag if set.
+	if *fc.Auditable {
+		bt := true
+		fc.Criticality = &bt
+	}
+	fc.Auditable = nil
+
+	return nil
+}
+
 func normalizeDir(dir **string) error {
 	if *dir == nil {
 		return nil
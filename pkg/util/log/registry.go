@@ -79,6 +79,19 @@ func (r *sinkInfoRegistry) iterFileSinks(fn func(l *fileSink) error) error {
 	})
 }
 
+// iterHTTPSinks iterates over all the HTTP sinks and stops at the
+// first error encountered.
+func (r *sinkInfoRegistry) iterHTTPSinks(fn func(l *httpSink) error) error {
+	return r.iter(func(si *sinkInfo) error {
+		if hs, ok := si.sink.(*httpSink); ok {
+			if err := fn(hs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // put adds a sinkInfo into the registry.
 func (r *sinkInfoRegistry) put(l *sinkInfo) {
 	r.mu.Lock()
@@ -0,0 +1,224 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// syslogSink represents a syslog server. The log entries are sent as
+// RFC 5424 messages, formatted by a syslogFormatter.
+type syslogSink struct {
+	// The network address of the syslog server. The network is either
+	// one understood by net.Dial, or "tls".
+	network string
+	addr    string
+
+	// tlsConfig is used to connect to the server when the network is
+	// "tls".
+	tlsConfig *tls.Config
+
+	// good indicates that the connection can be used.
+	good bool
+	conn net.Conn
+}
+
+const syslogDialTimeout = 5 * time.Second
+const syslogWriteTimeout = time.Second
+
+func newSyslogSink(network, addr, caCertPath string) (*syslogSink, error) {
+	l := &syslogSink{
+		network: network,
+		addr:    addr,
+	}
+	if network == "tls" {
+		l.tlsConfig = &tls.Config{}
+		if caCertPath != "" {
+			pem, err := ioutil.ReadFile(caCertPath)
+			if err != nil {
+				return nil, errors.Wrap(err, "reading CA certificate")
+			}
+			l.tlsConfig.RootCAs = x509.NewCertPool()
+			if !l.tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, errors.Newf("no CA certificate found in %s", caCertPath)
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l *syslogSink) String() string {
+	return fmt.Sprintf("syslog:%s://%s", l.network, l.addr)
+}
+
+// active implements the logSink interface.
+func (l *syslogSink) active() bool { return true }
+
+// attachHints implements the logSink interface.
+func (l *syslogSink) attachHints(stacks []byte) []byte {
+	return stacks
+}
+
+// exitCode implements the logSink interface.
+func (l *syslogSink) exitCode() exit.Code {
+	return exit.LoggingNetCollectorUnavailable()
+}
+
+// output implements the logSink interface.
+func (l *syslogSink) output(extraSync bool, b []byte) error {
+	b = l.frame(b)
+	// Try to write and reconnect immediately if the first write fails.
+	_ = l.tryWrite(b)
+	if l.good {
+		return nil
+	}
+
+	if err := l.ensureConn(b); err != nil {
+		return err
+	}
+	return l.tryWrite(b)
+}
+
+// emergencyOutput implements the logSink interface.
+func (l *syslogSink) emergencyOutput(b []byte) {
+	b = l.frame(b)
+	_ = l.tryWrite(b)
+	if !l.good {
+		_ = l.ensureConn(b)
+		_ = l.tryWrite(b)
+	}
+}
+
+// frame prepares a message for transmission. Over UDP, each message is
+// sent in its own datagram (RFC 5426). Over TCP and TLS, each message
+// is prefixed by its length (the octet-counting method of RFC 5425).
+func (l *syslogSink) frame(b []byte) []byte {
+	if strings.HasPrefix(l.network, "udp") {
+		return b
+	}
+	framed := make([]byte, 0, len(b)+8)
+	framed = strconv.AppendInt(framed, int64(len(b)), 10)
+	framed = append(framed, ' ')
+	return append(framed, b...)
+}
+
+func (l *syslogSink) close() {
+	l.good = false
+	if l.conn != nil {
+		if err := l.conn.Close(); err != nil {
+			fmt.Fprintf(OrigStderr, "error closing network logger: %v\n", err)
+		}
+		l.conn = nil
+	}
+}
+
+func (l *syslogSink) ensureConn(b []byte) error {
+	if l.good {
+		return nil
+	}
+	l.close()
+	var err error
+	if l.network == "tls" {
+		dialer := &net.Dialer{Timeout: syslogDialTimeout}
+		l.conn, err = tls.DialWithDialer(dialer, "tcp", l.addr, l.tlsConfig)
+	} else {
+		l.conn, err = net.DialTimeout(l.network, l.addr, syslogDialTimeout)
+	}
+	if err != nil {
+		fmt.Fprintf(OrigStderr, "%s: error dialing network logger: %v\n%s\n", l, err, b)
+		return err
+	}
+	fmt.Fprintf(OrigStderr, "%s: connection to network logger resumed\n", l)
+	l.good = true
+	return nil
+}
+
+func (l *syslogSink) tryWrite(b []byte) error {
+	if !l.good {
+		return errNoConn
+	}
+	if err := l.conn.SetWriteDeadline(timeutil.Now().Add(syslogWriteTimeout)); err != nil {
+		// An error here is suggestive of a bug in the Go runtime.
+		fmt.Fprintf(OrigStderr, "%s: set write deadline error: %v\n%s\n",
+			l, err, b)
+		l.good = false
+		return err
+	}
+	n, err := l.conn.Write(b)
+	if err != nil || n < len(b) {
+		fmt.Fprintf(OrigStderr, "%s: logging error: %v or short write (%d/%d)\n%s\n",
+			l, err, n, len(b), b)
+		l.good = false
+	}
+	return err
+}
+
+// syslogFormatter wraps the formatter configured for a syslog sink, to
+// turn each formatted entry into a RFC 5424 syslog message.
+type syslogFormatter struct {
+	logFormatter
+
+	// facility is the numerical code of the syslog facility.
+	facility int
+	// appName is the APP-NAME field of the messages.
+	appName string
+}
+
+// syslogTimeFormat is the format of the TIMESTAMP field of syslog
+// messages, as specified by RFC 5424.
+const syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// formatEntry implements the logFormatter interface.
+//
+// The message header is followed by the entry formatted by the wrapped
+// formatter. The MSGID field of the header is the logging channel.
+func (f syslogFormatter) formatEntry(entry logEntry) *buffer {
+	msg := f.logFormatter.formatEntry(entry)
+	defer putBuffer(msg)
+
+	buf := getBuffer()
+	fmt.Fprintf(buf, "<%d>1 %s %s %s %d %s - ",
+		f.facility*8+syslogSeverity(entry.sev),
+		timeutil.Unix(0, entry.ts).UTC().Format(syslogTimeFormat),
+		host, f.appName, pid, entry.ch)
+	buf.Write(bytes.TrimRight(msg.Bytes(), "\n"))
+	return buf
+}
+
+// syslogSeverity returns the syslog severity code corresponding to a
+// logging severity.
+func syslogSeverity(sev Severity) int {
+	switch sev {
+	case severity.FATAL:
+		return 2 // Critical.
+	case severity.ERROR:
+		return 3 // Error.
+	case severity.WARNING:
+		return 4 // Warning.
+	case severity.INFO:
+		return 6 // Informational.
+	default:
+		return 7 // Debug.
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := Scope(t)
+	defer sc.Close(t)
+
+	// A UDP server receives one message per datagram.
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer udpConn.Close()
+
+	// A TCP server receives messages prefixed by their length.
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer tcpListener.Close()
+	tcpData := make(chan string, 1)
+	go func() {
+		conn, err := tcpListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		lenStr, err := r.ReadString(' ')
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		n, err := strconv.Atoi(lenStr[:len(lenStr)-1])
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			t.Error(err)
+			return
+		}
+		tcpData <- string(msg)
+	}()
+
+	// Set up a logging configuration with the servers we've just set up
+	// as targets for the OPS and HEALTH channels.
+	cfg := logconfig.DefaultConfig()
+	appName, facility := "crdb", "local0"
+	cfg.Sinks.SyslogServers = map[string]*logconfig.SyslogSinkConfig{
+		"ops": {
+			Address:  udpConn.LocalAddr().String(),
+			AppName:  &appName,
+			Facility: &facility,
+			Channels: logconfig.ChannelList{Channels: []Channel{channel.OPS}}},
+		"health": {
+			Net:      "tcp",
+			Address:  tcpListener.Addr().String(),
+			Channels: logconfig.ChannelList{Channels: []Channel{channel.HEALTH}}},
+	}
+	// Derive a full config using the same directory as the
+	// TestLogScope.
+	require.NoError(t, cfg.Validate(&sc.logDir))
+
+	// Apply the configuration.
+	TestingResetActive()
+	cleanup, err := ApplyConfig(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+
+	// Send a log event on each channel.
+	Ops.Warningf(context.Background(), "hello world")
+	Health.Infof(context.Background(), "hello health")
+
+	// Check that the events were sent via the syslog sinks. The
+	// priority is computed from the facility (local0 = 16, user = 1) and
+	// the severity (warning = 4, info = 6).
+	require.NoError(t, udpConn.SetReadDeadline(timeutil.Now().Add(10*time.Second)))
+	buf := make([]byte, 65536)
+	n, _, err := udpConn.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(fmt.Sprintf(
+		`^<132>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z %s crdb %d OPS - \{.*"message":"hello world".*\}$`,
+		regexp.QuoteMeta(host), pid)), string(buf[:n]))
+
+	var msg string
+	select {
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	case msg = <-tcpData:
+	}
+	require.Regexp(t, regexp.MustCompile(fmt.Sprintf(
+		`^<14>1 \S+ %s %s %d HEALTH - \{.*"message":"hello health".*\}$`,
+		regexp.QuoteMeta(host), regexp.QuoteMeta(program), pid)), msg)
+}
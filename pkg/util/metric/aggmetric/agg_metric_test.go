@@ -16,6 +16,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -24,24 +25,27 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// writePrometheusMetrics returns the sorted metrics exported to prometheus by
+// the registry, including the child metrics.
+func writePrometheusMetrics(t *testing.T, r *metric.Registry) string {
+	var in bytes.Buffer
+	ex := metric.MakePrometheusExporter()
+	ex.ScrapeRegistry(r, true /* includeChildMetrics */)
+	require.NoError(t, ex.PrintAsText(&in))
+	var lines []string
+	for sc := bufio.NewScanner(&in); sc.Scan(); {
+		if !bytes.HasPrefix(sc.Bytes(), []byte{'#'}) {
+			lines = append(lines, sc.Text())
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
 func TestAggMetric(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
 	r := metric.NewRegistry()
-	writePrometheusMetrics := func(t *testing.T) string {
-		var in bytes.Buffer
-		ex := metric.MakePrometheusExporter()
-		ex.ScrapeRegistry(r, true /* includeChildMetrics */)
-		require.NoError(t, ex.PrintAsText(&in))
-		var lines []string
-		for sc := bufio.NewScanner(&in); sc.Scan(); {
-			if !bytes.HasPrefix(sc.Bytes(), []byte{'#'}) {
-				lines = append(lines, sc.Text())
-			}
-		}
-		sort.Strings(lines)
-		return strings.Join(lines, "\n")
-	}
 
 	c := aggmetric.NewCounter(metric.Metadata{
 		Name: "foo_counter",
@@ -71,7 +75,7 @@ bar_gauge{tenant_id="3"} 2
 foo_counter 6
 foo_counter{tenant_id="2"} 2
 foo_counter{tenant_id="3"} 4`,
-			writePrometheusMetrics(t))
+			writePrometheusMetrics(t, r))
 	})
 
 	t.Run("destroy", func(t *testing.T) {
@@ -82,7 +86,7 @@ foo_counter{tenant_id="3"} 4`,
 bar_gauge{tenant_id="2"} 2
 foo_counter 6
 foo_counter{tenant_id="3"} 4`,
-			writePrometheusMetrics(t))
+			writePrometheusMetrics(t, r))
 	})
 
 	t.Run("panic on already exists", func(t *testing.T) {
@@ -105,7 +109,7 @@ bar_gauge{tenant_id="3"} 0
 foo_counter 6
 foo_counter{tenant_id="2"} 0
 foo_counter{tenant_id="3"} 4`,
-			writePrometheusMetrics(t))
+			writePrometheusMetrics(t, r))
 	})
 
 	t.Run("panic on label length mismatch", func(t *testing.T) {
@@ -113,3 +117,46 @@ foo_counter{tenant_id="3"} 4`,
 		require.Panics(t, func() { g.AddChild("", "") })
 	})
 }
+
+func TestAggHistogram(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := metric.NewRegistry()
+	h := aggmetric.MakeBuilder("tenant_id").Histogram(metric.Metadata{
+		Name: "baz_histogram",
+	}, time.Minute, 100, 1)
+	r.AddMetric(h)
+	h2 := h.AddChild(roachpb.MakeTenantID(2).String())
+	h3 := h.AddChild(roachpb.MakeTenantID(3).String())
+
+	h2.RecordValue(5)
+	h3.RecordValue(5)
+	h3.RecordValue(10)
+	require.Equal(t, int64(3), h.TotalCount())
+	require.Equal(t,
+		`baz_histogram_bucket{le="+Inf"} 3
+baz_histogram_bucket{le="10"} 3
+baz_histogram_bucket{le="5"} 2
+baz_histogram_bucket{tenant_id="2",le="+Inf"} 1
+baz_histogram_bucket{tenant_id="2",le="5"} 1
+baz_histogram_bucket{tenant_id="3",le="+Inf"} 2
+baz_histogram_bucket{tenant_id="3",le="10"} 2
+baz_histogram_bucket{tenant_id="3",le="5"} 1
+baz_histogram_count 3
+baz_histogram_count{tenant_id="2"} 1
+baz_histogram_count{tenant_id="3"} 2
+baz_histogram_sum 20
+baz_histogram_sum{tenant_id="2"} 5
+baz_histogram_sum{tenant_id="3"} 15`,
+		writePrometheusMetrics(t, r))
+
+	// The windowed data recorded in the internal time series is that of the
+	// parent.
+	windowed, _ := h.Windowed()
+	require.Equal(t, int64(3), windowed.TotalCount())
+
+	// Destroying a child leaves its values in the parent.
+	h2.Destroy()
+	require.Equal(t, int64(3), h.TotalCount())
+	require.Panics(t, func() { h.AddChild(roachpb.MakeTenantID(3).String()) })
+}
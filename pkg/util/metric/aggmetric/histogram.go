@@ -0,0 +1,145 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package aggmetric
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/codahale/hdrhistogram"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+)
+
+// AggHistogram maintains a histogram of the values recorded by all of its
+// children. The histogram will report to crdb-internal time series only the
+// aggregate histogram, while its children are additionally exported to
+// prometheus via the PrometheusIterable interface.
+type AggHistogram struct {
+	h      *metric.Histogram
+	create func() *metric.Histogram
+	childSet
+}
+
+var _ metric.Iterable = (*AggHistogram)(nil)
+var _ metric.PrometheusIterable = (*AggHistogram)(nil)
+var _ metric.PrometheusExportable = (*AggHistogram)(nil)
+var _ metric.WindowedHistogram = (*AggHistogram)(nil)
+var _ metric.CumulativeHistogram = (*AggHistogram)(nil)
+
+// NewHistogram constructs a new AggHistogram. The parameters of the
+// histogram, which are shared by its children, are those of
+// metric.NewHistogram.
+func NewHistogram(
+	metadata metric.Metadata,
+	duration time.Duration,
+	maxVal int64,
+	sigFigs int,
+	childLabels ...string,
+) *AggHistogram {
+	create := func() *metric.Histogram {
+		return metric.NewHistogram(metadata, duration, maxVal, sigFigs)
+	}
+	a := &AggHistogram{h: create(), create: create}
+	a.init(childLabels)
+	return a
+}
+
+// GetName is part of the metric.Iterable interface.
+func (a *AggHistogram) GetName() string { return a.h.GetName() }
+
+// GetHelp is part of the metric.Iterable interface.
+func (a *AggHistogram) GetHelp() string { return a.h.GetHelp() }
+
+// GetMeasurement is part of the metric.Iterable interface.
+func (a *AggHistogram) GetMeasurement() string { return a.h.GetMeasurement() }
+
+// GetUnit is part of the metric.Iterable interface.
+func (a *AggHistogram) GetUnit() metric.Unit { return a.h.GetUnit() }
+
+// GetMetadata is part of the metric.Iterable interface.
+func (a *AggHistogram) GetMetadata() metric.Metadata { return a.h.GetMetadata() }
+
+// Inspect is part of the metric.Iterable interface.
+func (a *AggHistogram) Inspect(f func(interface{})) {
+	// Inspecting the parent histogram rotates its windowed data as needed.
+	a.h.Inspect(func(interface{}) {})
+	f(a)
+}
+
+// GetType is part of the metric.PrometheusExportable interface.
+func (a *AggHistogram) GetType() *io_prometheus_client.MetricType {
+	return a.h.GetType()
+}
+
+// GetLabels is part of the metric.PrometheusExportable interface.
+func (a *AggHistogram) GetLabels() []*io_prometheus_client.LabelPair {
+	return a.h.GetLabels()
+}
+
+// ToPrometheusMetric is part of the metric.PrometheusExportable interface.
+func (a *AggHistogram) ToPrometheusMetric() *io_prometheus_client.Metric {
+	return a.h.ToPrometheusMetric()
+}
+
+// Windowed is part of the metric.WindowedHistogram interface.
+func (a *AggHistogram) Windowed() (*hdrhistogram.Histogram, time.Duration) {
+	return a.h.Windowed()
+}
+
+// Snapshot is part of the metric.CumulativeHistogram interface.
+func (a *AggHistogram) Snapshot() *hdrhistogram.Histogram {
+	return a.h.Snapshot()
+}
+
+// TotalCount returns the number of values recorded by all of its current and
+// past children.
+func (a *AggHistogram) TotalCount() int64 {
+	return a.h.TotalCount()
+}
+
+// AddChild adds a Histogram to this AggHistogram. This method panics if a
+// Histogram already exists for this set of labelVals.
+func (a *AggHistogram) AddChild(labelVals ...string) *Histogram {
+	child := &Histogram{
+		parent:           a,
+		labelValuesSlice: labelValuesSlice(labelVals),
+		h:                a.create(),
+	}
+	a.add(child)
+	return child
+}
+
+// Histogram is a child of an AggHistogram. When a value is recorded, it is
+// also recorded in the parent. When metrics are collected by prometheus, each
+// of the children will appear with a distinct label, however, when cockroach
+// internally collects metrics, only the parent is collected.
+type Histogram struct {
+	parent *AggHistogram
+	labelValuesSlice
+	h *metric.Histogram
+}
+
+// ToPrometheusMetric constructs a prometheus metric for this Histogram.
+func (g *Histogram) ToPrometheusMetric() *io_prometheus_client.Metric {
+	return g.h.ToPrometheusMetric()
+}
+
+// Destroy disconnects this Histogram from its parent. The values it recorded
+// remain in the parent.
+func (g *Histogram) Destroy() {
+	g.parent.remove(g)
+}
+
+// RecordValue adds the given value to the histogram and to its parent.
+func (g *Histogram) RecordValue(v int64) {
+	g.h.RecordValue(v)
+	g.parent.h.RecordValue(v)
+}
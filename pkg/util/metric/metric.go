@@ -179,6 +179,26 @@ func maybeTick(m periodic) {
 	}
 }
 
+// WindowedHistogram represents a histogram with data over a recent window of
+// time. Its windowed data is recorded at fixed quantiles in the internal time
+// series database.
+type WindowedHistogram interface {
+	// Windowed returns a copy of the current windowed histogram data and its
+	// rotation interval.
+	Windowed() (*hdrhistogram.Histogram, time.Duration)
+}
+
+var _ WindowedHistogram = (*Histogram)(nil)
+
+// CumulativeHistogram represents a histogram with data over all time. Its
+// bucket counts may be recorded in the internal time series database.
+type CumulativeHistogram interface {
+	// Snapshot returns a copy of the cumulative histogram data.
+	Snapshot() *hdrhistogram.Histogram
+}
+
+var _ CumulativeHistogram = (*Histogram)(nil)
+
 // A Histogram collects observed values by keeping bucketed counts. For
 // convenience, internally two sets of buckets are kept: A cumulative set (i.e.
 // data is never evicted) and a windowed set (which keeps only recently
@@ -0,0 +1,162 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/errors"
+	"github.com/golang/snappy"
+	prometheusgo "github.com/prometheus/client_model/go"
+)
+
+var errNoRemoteWriteEndpoint = errors.New("external.prometheus.remote_write.endpoint is not set")
+
+// remoteWriteTimeout bounds the duration of each push.
+const remoteWriteTimeout = 10 * time.Second
+
+// RemoteWriteExporter scrapes PrometheusExporter for metrics and pushes them
+// to an endpoint implementing the Prometheus remote write protocol, such as
+// Prometheus itself or any of the many systems compatible with it. Unlike the
+// PrometheusExporter, which serves metrics for Prometheus to scrape, it does
+// not require the nodes to be reachable from the monitoring system.
+type RemoteWriteExporter struct {
+	pm     *PrometheusExporter
+	client *httputil.Client
+}
+
+// MakeRemoteWriteExporter returns an initialized remote write exporter.
+func MakeRemoteWriteExporter(pm *PrometheusExporter) RemoteWriteExporter {
+	return RemoteWriteExporter{
+		pm:     pm,
+		client: httputil.NewClientWithTimeout(remoteWriteTimeout),
+	}
+}
+
+// Push sends the metrics scraped into the PrometheusExporter to the remote
+// write endpoint, as samples taken at the given time. The given labels are
+// added to every time series, in addition to the labels of the registries
+// and of the metrics.
+func (re *RemoteWriteExporter) Push(
+	ctx context.Context, endpoint string, labels []RemoteWriteLabel, now time.Time,
+) error {
+	if endpoint == "" {
+		return errNoRemoteWriteEndpoint
+	}
+	// Regardless of whether the push succeeds, clear metrics. As with the
+	// GraphiteExporter, only the latest metrics are pushed.
+	defer re.pm.clearMetrics()
+	families, err := re.pm.Gather()
+	if err != nil {
+		return err
+	}
+	wr := MakeRemoteWriteRequest(families, labels, now)
+	data, err := wr.Marshal()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(snappy.Encode(nil, data)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("User-Agent", "cockroachdb")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	resp, err := re.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.Errorf("remote write endpoint returned %s: %s",
+			resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// MakeRemoteWriteRequest converts the metric families into a remote write
+// request holding one sample per time series, taken at the given time. The
+// given labels are added to every time series. Histograms are converted
+// into the _bucket, _sum and _count series which Prometheus would have
+// scraped from their text representation.
+func MakeRemoteWriteRequest(
+	families []*prometheusgo.MetricFamily, labels []RemoteWriteLabel, now time.Time,
+) RemoteWriteRequest {
+	timestamp := now.UnixNano() / int64(time.Millisecond)
+	var req RemoteWriteRequest
+	add := func(name string, m *prometheusgo.Metric, value float64, extra ...RemoteWriteLabel) {
+		ts := RemoteWriteTimeSeries{
+			Labels:  make([]RemoteWriteLabel, 0, len(labels)+len(m.Label)+len(extra)+1),
+			Samples: []RemoteWriteSample{{Value: value, Timestamp: timestamp}},
+		}
+		ts.Labels = append(ts.Labels, RemoteWriteLabel{Name: "__name__", Value: name})
+		ts.Labels = append(ts.Labels, labels...)
+		for _, l := range m.Label {
+			ts.Labels = append(ts.Labels, RemoteWriteLabel{Name: l.GetName(), Value: l.GetValue()})
+		}
+		ts.Labels = append(ts.Labels, extra...)
+		sort.Slice(ts.Labels, func(i, j int) bool {
+			return ts.Labels[i].Name < ts.Labels[j].Name
+		})
+		req.Timeseries = append(req.Timeseries, ts)
+	}
+
+	// Sort the families to make the request deterministic.
+	sorted := append([]*prometheusgo.MetricFamily(nil), families...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
+	for _, family := range sorted {
+		name := family.GetName()
+		for _, m := range family.Metric {
+			switch family.GetType() {
+			case prometheusgo.MetricType_COUNTER:
+				add(name, m, m.GetCounter().GetValue())
+			case prometheusgo.MetricType_GAUGE:
+				add(name, m, m.GetGauge().GetValue())
+			case prometheusgo.MetricType_UNTYPED:
+				add(name, m, m.GetUntyped().GetValue())
+			case prometheusgo.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.Bucket {
+					add(name+"_bucket", m, float64(b.GetCumulativeCount()),
+						RemoteWriteLabel{Name: "le", Value: formatBound(b.GetUpperBound())})
+				}
+				add(name+"_bucket", m, float64(h.GetSampleCount()),
+					RemoteWriteLabel{Name: "le", Value: formatBound(math.Inf(1))})
+				add(name+"_sum", m, h.GetSampleSum())
+				add(name+"_count", m, float64(h.GetSampleCount()))
+			}
+		}
+	}
+	return req
+}
+
+// formatBound formats the upper bound of a histogram bucket the way the
+// Prometheus text format does.
+func formatBound(b float64) string {
+	if math.IsInf(b, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(b, 'g', -1, 64)
+}
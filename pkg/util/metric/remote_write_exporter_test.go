@@ -0,0 +1,127 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+func TestRemoteWriteExporter(t *testing.T) {
+	r := NewRegistry()
+	r.AddLabel("store", "1")
+	g := NewGauge(Metadata{Name: "some.gauge"})
+	g.Update(3)
+	r.AddMetric(g)
+	cMeta := Metadata{Name: "some.counter"}
+	cMeta.AddLabel("kind", "x")
+	c := NewCounter(cMeta)
+	c.Inc(7)
+	r.AddMetric(c)
+	h := NewHistogram(Metadata{Name: "some.histogram"}, time.Minute, 100, 1)
+	h.RecordValue(5)
+	h.RecordValue(5)
+	// With one significant figure, 50 is recorded in the bucket ending at 51.
+	h.RecordValue(50)
+	r.AddMetric(h)
+
+	var requests []RemoteWriteRequest
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for header, expected := range map[string]string{
+			"Content-Type":                      "application/x-protobuf",
+			"Content-Encoding":                  "snappy",
+			"X-Prometheus-Remote-Write-Version": "0.1.0",
+		} {
+			if actual := req.Header.Get(header); actual != expected {
+				t.Errorf("expected header %s to be %q, got %q", header, expected, actual)
+			}
+		}
+		compressed, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		data, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			t.Error(err)
+		}
+		var wr RemoteWriteRequest
+		if err := wr.Unmarshal(data); err != nil {
+			t.Error(err)
+		}
+		requests = append(requests, wr)
+		w.WriteHeader(status)
+		fmt.Fprint(w, "some error")
+	}))
+	defer srv.Close()
+
+	pm := MakePrometheusExporter()
+	pm.ScrapeRegistry(r, false /* includeChildMetrics */)
+	re := MakeRemoteWriteExporter(&pm)
+	now := time.Unix(1600000000, 0)
+	labels := []RemoteWriteLabel{{Name: "node_id", Value: "2"}}
+	if err := re.Push(context.Background(), srv.URL, labels, now); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	var series []string
+	for _, ts := range requests[0].Timeseries {
+		var ls []string
+		for _, l := range ts.Labels {
+			ls = append(ls, fmt.Sprintf("%s=%q", l.Name, l.Value))
+		}
+		if len(ts.Samples) != 1 || ts.Samples[0].Timestamp != now.UnixNano()/1e6 {
+			t.Errorf("unexpected samples %v", ts.Samples)
+		}
+		series = append(series, fmt.Sprintf("{%s} %g", strings.Join(ls, ","), ts.Samples[0].Value))
+	}
+	expected := []string{
+		`{__name__="some_counter",kind="x",node_id="2",store="1"} 7`,
+		`{__name__="some_gauge",node_id="2",store="1"} 3`,
+		`{__name__="some_histogram_bucket",le="5",node_id="2",store="1"} 2`,
+		`{__name__="some_histogram_bucket",le="51",node_id="2",store="1"} 3`,
+		`{__name__="some_histogram_bucket",le="+Inf",node_id="2",store="1"} 3`,
+		`{__name__="some_histogram_sum",node_id="2",store="1"} 61`,
+		`{__name__="some_histogram_count",node_id="2",store="1"} 3`,
+	}
+	if strings.Join(series, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(series, "\n"))
+	}
+
+	// The metrics are cleared after each push.
+	if err := re.Push(context.Background(), srv.URL, labels, now); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(requests[1].Timeseries); n != 0 {
+		t.Errorf("expected no time series after the metrics were cleared, got %d", n)
+	}
+
+	// Errors returned by the endpoint are reported.
+	status = http.StatusBadRequest
+	if err := re.Push(context.Background(), srv.URL, labels, now); err == nil ||
+		!strings.Contains(err.Error(), "400 Bad Request: some error") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := re.Push(context.Background(), "", labels, now); err != errNoRemoteWriteEndpoint {
+		t.Errorf("unexpected error %v", err)
+	}
+}
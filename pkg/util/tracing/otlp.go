@@ -0,0 +1,830 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/otlppb"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/tracingpb"
+	"github.com/cockroachdb/errors"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// This file implements a shadow tracer exporting spans to OpenTelemetry
+// collectors using the OpenTelemetry protocol (OTLP), either over gRPC
+// or over HTTP. The spans carry W3C trace context identifiers, and
+// their context is propagated using the W3C traceparent format, which
+// makes it possible to connect them to traces started by applications.
+
+const (
+	// otlpManagerName is the type of the OTLP shadow tracer.
+	otlpManagerName = "otlp"
+
+	// traceParentKey and traceStateKey are the keys used to propagate
+	// span contexts in the W3C trace context format.
+	traceParentKey = "traceparent"
+	traceStateKey  = "tracestate"
+
+	// otlpDefaultHTTPPath is the path to which spans are sent when the
+	// URL of an OTLP/HTTP collector does not specify one.
+	otlpDefaultHTTPPath = "/v1/traces"
+
+	// otlpMaxAttributesPerSpan limits the number of attributes of a
+	// span; further attributes are dropped.
+	otlpMaxAttributesPerSpan = 128
+	// otlpMaxQueueSize limits the number of finished spans waiting to be
+	// exported; further spans are dropped.
+	otlpMaxQueueSize = 2048
+	// otlpMaxBatchSize is the maximum number of spans exported at once.
+	otlpMaxBatchSize = 512
+	// otlpFlushInterval is the maximum amount of time finished spans
+	// wait before being exported.
+	otlpFlushInterval = time.Second
+	// otlpExportTimeout bounds the duration of an export request.
+	otlpExportTimeout = 10 * time.Second
+	// otlpCloseTimeout bounds the duration of the export of the spans
+	// remaining when the exporter is closed.
+	otlpCloseTimeout = 5 * time.Second
+)
+
+// validateOTLPCollector checks the value of the
+// trace.opentelemetry.collector setting.
+func validateOTLPCollector(_ *settings.Values, s string) error {
+	if s == "" {
+		return nil
+	}
+	_, err := parseOTLPCollector(s)
+	return err
+}
+
+// parseOTLPCollector parses the URL of an OpenTelemetry collector. The
+// grpc and grpcs schemes select OTLP/gRPC, without and with TLS
+// respectively; the http and https schemes select OTLP/HTTP.
+func parseOTLPCollector(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid collector URL")
+	}
+	switch u.Scheme {
+	case "grpc", "grpcs":
+		if u.Path != "" && u.Path != "/" {
+			return nil, errors.Newf("invalid collector URL %q: OTLP/gRPC does not use a path", s)
+		}
+	case "http", "https":
+		if u.Path == "" || u.Path == "/" {
+			u.Path = otlpDefaultHTTPPath
+		}
+	default:
+		return nil, errors.WithHint(
+			errors.Newf("invalid collector URL %q: unsupported scheme", s),
+			"Use grpc:// or grpcs:// for OTLP/gRPC, and http:// or https:// for OTLP/HTTP.")
+	}
+	if u.Host == "" {
+		return nil, errors.Newf("invalid collector URL %q: missing host", s)
+	}
+	return u, nil
+}
+
+type otlpManager struct {
+	exporter *otlpExporter
+}
+
+func (*otlpManager) Name() string {
+	return otlpManagerName
+}
+
+// Close is part of the shadowTracerManager interface. It doesn't wait
+// for the remaining spans to be exported, since it is called when the
+// tracing settings change.
+func (m *otlpManager) Close(tr opentracing.Tracer) {
+	m.exporter.close()
+}
+
+// createOTLPTracer creates a shadow tracer exporting spans to the given
+// collector. If the URL of the collector is invalid, the error is
+// printed and nil is returned, which disables the shadow tracer.
+func createOTLPTracer(
+	collector string, sampleRate func() float64,
+) (shadowTracerManager, opentracing.Tracer) {
+	u, err := parseOTLPCollector(collector)
+	if err != nil {
+		// The setting is validated, but its default value, which comes from
+		// the COCKROACH_TEST_OTLP_COLLECTOR environment variable, is not. We
+		// can't use `log` from this package so print the error to stderr.
+		fmt.Fprintf(os.Stderr, "OpenTelemetry exporter disabled: %v\n", err)
+		return nil, nil
+	}
+	exporter := newOTLPExporter(u)
+	return &otlpManager{exporter: exporter}, &otlpTracer{
+		exporter:   exporter,
+		sampleRate: sampleRate,
+	}
+}
+
+// otlpTracer is an opentracing.Tracer creating spans identified by W3C
+// trace context identifiers, and exporting them to an OpenTelemetry
+// collector when they are finished.
+//
+// Sampling decisions are made for root spans, based on their trace ID
+// and the configured sample rate, and inherited by their descendants,
+// including remote ones. Spans which are not sampled are not recorded,
+// but still propagate their context.
+type otlpTracer struct {
+	exporter   *otlpExporter
+	sampleRate func() float64
+}
+
+var _ opentracing.Tracer = (*otlpTracer)(nil)
+
+// otlpSpanContext is the opentracing.SpanContext of an otlpSpan. It
+// corresponds to the W3C trace context of the span.
+type otlpSpanContext struct {
+	traceID    [16]byte
+	spanID     [8]byte
+	sampled    bool
+	traceState string
+	baggage    map[string]string
+}
+
+var _ opentracing.SpanContext = otlpSpanContext{}
+
+// ForeachBaggageItem is part of the opentracing.SpanContext interface.
+func (c otlpSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range c.baggage {
+		if !handler(k, v) {
+			break
+		}
+	}
+}
+
+// traceParent formats the context in the W3C traceparent format.
+func (c otlpSpanContext) traceParent() string {
+	var flags byte
+	if c.sampled {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%x-%x-%02x", c.traceID, c.spanID, flags)
+}
+
+// ValidateTraceParent returns an error if the given string is not a
+// valid W3C traceparent.
+func ValidateTraceParent(s string) error {
+	_, err := parseTraceParent(s)
+	return err
+}
+
+// RemoteParentFromTraceParent returns a SpanMeta which makes the spans
+// started with WithParentAndManualCollection children of the span
+// identified by the given W3C traceparent. This is used to connect the
+// traces of clients to the spans of the operations they request. nil
+// is returned if the traceparent is invalid, or if spans are not sent
+// to an OpenTelemetry collector.
+func (t *Tracer) RemoteParentFromTraceParent(traceParent string) *SpanMeta {
+	if typ, ok := t.getShadowTracer().Type(); !ok || typ != otlpManagerName {
+		return nil
+	}
+	c, err := parseTraceParent(traceParent)
+	if err != nil {
+		return nil
+	}
+	return &SpanMeta{
+		shadowTracerType: otlpManagerName,
+		shadowCtx:        c,
+	}
+}
+
+// parseTraceParent parses a W3C traceparent, of the form
+// version-traceid-parentid-flags, e.g.
+// 00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01.
+func parseTraceParent(s string) (otlpSpanContext, error) {
+	var c otlpSpanContext
+	invalid := func() (otlpSpanContext, error) {
+		return otlpSpanContext{}, errors.Newf("invalid traceparent: %q", s)
+	}
+	const length = 55
+	// Future versions may append fields to the ones of version 00.
+	if len(s) < length || (len(s) > length && s[length] != '-') {
+		return invalid()
+	}
+	for i := 0; i < length; i++ {
+		switch i {
+		case 2, 35, 52:
+			if s[i] != '-' {
+				return invalid()
+			}
+		default:
+			if !(s[i] >= '0' && s[i] <= '9') && !(s[i] >= 'a' && s[i] <= 'f') {
+				return invalid()
+			}
+		}
+	}
+	version := s[0:2]
+	if version == "ff" || (version == "00" && len(s) != length) {
+		return invalid()
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(c.traceID[:], []byte(s[3:35])); err != nil {
+		return invalid()
+	}
+	if _, err := hex.Decode(c.spanID[:], []byte(s[36:52])); err != nil {
+		return invalid()
+	}
+	if _, err := hex.Decode(flags[:], []byte(s[53:55])); err != nil {
+		return invalid()
+	}
+	if c.traceID == ([16]byte{}) || c.spanID == ([8]byte{}) {
+		return invalid()
+	}
+	c.sampled = flags[0]&1 != 0
+	return c, nil
+}
+
+// StartSpan is part of the opentracing.Tracer interface.
+func (t *otlpTracer) StartSpan(
+	operationName string, opts ...opentracing.StartSpanOption,
+) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, o := range opts {
+		o.Apply(&sso)
+	}
+	s := &otlpSpan{
+		tracer:    t,
+		startTime: sso.StartTime,
+	}
+	if s.startTime.IsZero() {
+		s.startTime = timeutil.Now()
+	}
+	var parent *otlpSpanContext
+	for _, ref := range sso.References {
+		if c, ok := ref.ReferencedContext.(otlpSpanContext); ok {
+			parent = &c
+			break
+		}
+	}
+	if parent != nil {
+		s.ctx.traceID = parent.traceID
+		s.ctx.sampled = parent.sampled
+		s.ctx.traceState = parent.traceState
+		s.ctx.baggage = parent.baggage
+		s.parentSpanID = parent.spanID
+	} else {
+		binary.BigEndian.PutUint64(s.ctx.traceID[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(s.ctx.traceID[8:], rand.Uint64())
+		s.ctx.sampled = shouldSample(s.ctx.traceID, t.sampleRate())
+	}
+	binary.BigEndian.PutUint64(s.ctx.spanID[:], rand.Uint64()|1)
+	s.mu.name = operationName
+	for k, v := range sso.Tags {
+		s.SetTag(k, v)
+	}
+	return s
+}
+
+// shouldSample decides whether a trace is sampled. The decision is
+// derived from the trace ID, similarly to the TraceIDRatioBased sampler
+// of the OpenTelemetry SDKs.
+func shouldSample(traceID [16]byte, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	bound := uint64(rate * (1 << 63))
+	return binary.BigEndian.Uint64(traceID[8:])>>1 < bound
+}
+
+// Inject is part of the opentracing.Tracer interface.
+func (t *otlpTracer) Inject(
+	sc opentracing.SpanContext, format interface{}, carrier interface{},
+) error {
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return opentracing.ErrUnsupportedFormat
+	}
+	c, ok := sc.(otlpSpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	w, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	w.Set(traceParentKey, c.traceParent())
+	if c.traceState != "" {
+		w.Set(traceStateKey, c.traceState)
+	}
+	return nil
+}
+
+// Extract is part of the opentracing.Tracer interface.
+func (t *otlpTracer) Extract(
+	format interface{}, carrier interface{},
+) (opentracing.SpanContext, error) {
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return nil, opentracing.ErrUnsupportedFormat
+	}
+	r, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+	var traceParent, traceState string
+	if err := r.ForeachKey(func(k, v string) error {
+		switch strings.ToLower(k) {
+		case traceParentKey:
+			traceParent = v
+		case traceStateKey:
+			traceState = v
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if traceParent == "" {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+	c, err := parseTraceParent(traceParent)
+	if err != nil {
+		return nil, opentracing.ErrSpanContextCorrupted
+	}
+	c.traceState = traceState
+	return c, nil
+}
+
+// otlpSpan is the opentracing.Span created by an otlpTracer.
+type otlpSpan struct {
+	tracer       *otlpTracer
+	ctx          otlpSpanContext
+	parentSpanID [8]byte
+	startTime    time.Time
+
+	mu struct {
+		syncutil.Mutex
+		name              string
+		kind              otlppb.Span_SpanKind
+		isError           bool
+		attributes        []otlppb.KeyValue
+		droppedAttributes uint32
+		events            []otlppb.Span_Event
+		droppedEvents     uint32
+		finished          bool
+	}
+}
+
+var _ opentracing.Span = (*otlpSpan)(nil)
+
+// Finish is part of the opentracing.Span interface.
+func (s *otlpSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+// FinishWithOptions is part of the opentracing.Span interface.
+func (s *otlpSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	if !s.ctx.sampled {
+		return
+	}
+	for _, lr := range opts.LogRecords {
+		s.logFields(lr.Timestamp, lr.Fields)
+	}
+	finishTime := opts.FinishTime
+	if finishTime.IsZero() {
+		finishTime = timeutil.Now()
+	}
+
+	s.mu.Lock()
+	if s.mu.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.finished = true
+	span := otlppb.Span{
+		TraceID:                append([]byte(nil), s.ctx.traceID[:]...),
+		SpanID:                 append([]byte(nil), s.ctx.spanID[:]...),
+		TraceState:             s.ctx.traceState,
+		Name:                   s.mu.name,
+		Kind:                   s.mu.kind,
+		StartTimeUnixNano:      uint64(s.startTime.UnixNano()),
+		EndTimeUnixNano:        uint64(finishTime.UnixNano()),
+		Attributes:             s.mu.attributes,
+		DroppedAttributesCount: s.mu.droppedAttributes,
+		Events:                 s.mu.events,
+		DroppedEventsCount:     s.mu.droppedEvents,
+	}
+	if s.mu.isError {
+		span.Status.Code = otlppb.Status_STATUS_CODE_ERROR
+	}
+	s.mu.Unlock()
+
+	if s.parentSpanID != ([8]byte{}) {
+		span.ParentSpanID = append([]byte(nil), s.parentSpanID[:]...)
+	}
+	if span.Kind == otlppb.Span_SPAN_KIND_UNSPECIFIED {
+		span.Kind = otlppb.Span_SPAN_KIND_INTERNAL
+	}
+	s.tracer.exporter.enqueue(span)
+}
+
+// Context is part of the opentracing.Span interface.
+func (s *otlpSpan) Context() opentracing.SpanContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx
+}
+
+// SetOperationName is part of the opentracing.Span interface.
+func (s *otlpSpan) SetOperationName(operationName string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.name = operationName
+	return s
+}
+
+// SetTag is part of the opentracing.Span interface.
+//
+// The span.kind and error tags defined by opentracing are translated
+// into the kind and the status of the span, respectively. Other tags
+// become attributes of the span.
+func (s *otlpSpan) SetTag(key string, value interface{}) opentracing.Span {
+	if !s.ctx.sampled {
+		return s
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch key {
+	case string(ext.SpanKind):
+		switch fmt.Sprint(value) {
+		case string(ext.SpanKindRPCServerEnum):
+			s.mu.kind = otlppb.Span_SPAN_KIND_SERVER
+		case string(ext.SpanKindRPCClientEnum):
+			s.mu.kind = otlppb.Span_SPAN_KIND_CLIENT
+		case string(ext.SpanKindProducerEnum):
+			s.mu.kind = otlppb.Span_SPAN_KIND_PRODUCER
+		case string(ext.SpanKindConsumerEnum):
+			s.mu.kind = otlppb.Span_SPAN_KIND_CONSUMER
+		}
+		return s
+	case string(ext.Error):
+		if b, ok := value.(bool); ok {
+			s.mu.isError = b
+			return s
+		}
+	}
+	for i := range s.mu.attributes {
+		if s.mu.attributes[i].Key == key {
+			s.mu.attributes[i].Value = otlpValue(value)
+			return s
+		}
+	}
+	if len(s.mu.attributes) >= otlpMaxAttributesPerSpan {
+		s.mu.droppedAttributes++
+		return s
+	}
+	s.mu.attributes = append(s.mu.attributes, otlppb.KeyValue{Key: key, Value: otlpValue(value)})
+	return s
+}
+
+// LogFields is part of the opentracing.Span interface.
+func (s *otlpSpan) LogFields(fields ...otlog.Field) {
+	s.logFields(timeutil.Now(), fields)
+}
+
+// logFields adds an event to the span. The message field, if any,
+// becomes the name of the event, and the other fields its attributes.
+func (s *otlpSpan) logFields(t time.Time, fields []otlog.Field) {
+	if !s.ctx.sampled {
+		return
+	}
+	ev := otlppb.Span_Event{TimeUnixNano: uint64(t.UnixNano())}
+	for _, f := range fields {
+		if f.Key() == tracingpb.LogMessageField && ev.Name == "" {
+			ev.Name = fmt.Sprint(f.Value())
+			continue
+		}
+		ev.Attributes = append(ev.Attributes, otlppb.KeyValue{Key: f.Key(), Value: otlpValue(f.Value())})
+	}
+	if ev.Name == "" {
+		ev.Name = "log"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.mu.events) >= maxLogsPerSpan {
+		s.mu.droppedEvents++
+		return
+	}
+	s.mu.events = append(s.mu.events, ev)
+}
+
+// LogKV is part of the opentracing.Span interface.
+func (s *otlpSpan) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := otlog.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(otlog.Error(err), otlog.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+// SetBaggageItem is part of the opentracing.Span interface.
+func (s *otlpSpan) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The baggage map may be shared with the parent and the children of
+	// the span: copy it on write.
+	baggage := make(map[string]string, len(s.ctx.baggage)+1)
+	for k, v := range s.ctx.baggage {
+		baggage[k] = v
+	}
+	baggage[restrictedKey] = value
+	s.ctx.baggage = baggage
+	return s
+}
+
+// BaggageItem is part of the opentracing.Span interface.
+func (s *otlpSpan) BaggageItem(restrictedKey string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx.baggage[restrictedKey]
+}
+
+// Tracer is part of the opentracing.Span interface.
+func (s *otlpSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+// LogEvent is part of the opentracing.Span interface. Deprecated.
+func (s *otlpSpan) LogEvent(event string) {
+	s.LogFields(otlog.String(tracingpb.LogMessageField, event))
+}
+
+// LogEventWithPayload is part of the opentracing.Span interface. Deprecated.
+func (s *otlpSpan) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(otlog.String(tracingpb.LogMessageField, event), otlog.Object("payload", payload))
+}
+
+// Log is part of the opentracing.Span interface. Deprecated.
+func (s *otlpSpan) Log(data opentracing.LogData) {
+	s.logFields(data.Timestamp, data.ToLogRecord().Fields)
+}
+
+// otlpValue converts the value of a tag or log field to the value of an
+// OTLP attribute.
+func otlpValue(v interface{}) otlppb.AnyValue {
+	switch t := v.(type) {
+	case string:
+		return otlppb.AnyValue{Value: &otlppb.AnyValue_StringValue{StringValue: t}}
+	case bool:
+		return otlppb.AnyValue{Value: &otlppb.AnyValue_BoolValue{BoolValue: t}}
+	case int:
+		return otlppb.AnyValue{Value: &otlppb.AnyValue_IntValue{IntValue: int64(t)}}
+	case int32:
+		return otlppb.AnyValue{Value: &otlppb.AnyValue_IntValue{IntValue: int64(t)}}
+	case int64:
+		return otlppb.AnyValue{Value: &otlppb.AnyValue_IntValue{IntValue: t}}
+	case uint32:
+		return otlppb.AnyValue{Value: &otlppb.AnyValue_IntValue{IntValue: int64(t)}}
+	case uint64:
+		if t <= math.MaxInt64 {
+			return otlppb.AnyValue{Value: &otlppb.AnyValue_IntValue{IntValue: int64(t)}}
+		}
+	case float32:
+		return otlppb.AnyValue{Value: &otlppb.AnyValue_DoubleValue{DoubleValue: float64(t)}}
+	case float64:
+		return otlppb.AnyValue{Value: &otlppb.AnyValue_DoubleValue{DoubleValue: t}}
+	}
+	return otlppb.AnyValue{Value: &otlppb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}}
+}
+
+var otlpLogEveryN = util.Every(5 * time.Second)
+
+// otlpExporter sends finished spans to an OpenTelemetry collector, in
+// batches.
+type otlpExporter struct {
+	// send exports a batch of spans.
+	send func(ctx context.Context, req *otlppb.ExportTraceServiceRequest) error
+	// closeTransport releases the resources used by send.
+	closeTransport func()
+
+	resource otlppb.Resource
+
+	// flushC is signaled when a batch of spans is ready to be exported.
+	flushC chan struct{}
+	// stopC is closed to stop the exporter, and doneC is closed when it
+	// has stopped.
+	stopC, doneC chan struct{}
+
+	mu struct {
+		syncutil.Mutex
+		spans   []otlppb.Span
+		dropped int
+		closed  bool
+	}
+}
+
+func newOTLPExporter(u *url.URL) *otlpExporter {
+	e := &otlpExporter{
+		flushC: make(chan struct{}, 1),
+		stopC:  make(chan struct{}),
+		doneC:  make(chan struct{}),
+	}
+	e.resource.Attributes = append(e.resource.Attributes,
+		otlppb.KeyValue{Key: "service.name", Value: otlpValue("cockroach")},
+		otlppb.KeyValue{Key: "process.pid", Value: otlpValue(os.Getpid())},
+	)
+	if hostname, err := os.Hostname(); err == nil {
+		e.resource.Attributes = append(e.resource.Attributes,
+			otlppb.KeyValue{Key: "host.name", Value: otlpValue(hostname)})
+	}
+
+	switch u.Scheme {
+	case "grpc", "grpcs":
+		e.initGRPC(u)
+	default:
+		e.initHTTP(u)
+	}
+	go e.run()
+	return e
+}
+
+// initGRPC sets up the exporter to send spans using OTLP/gRPC.
+func (e *otlpExporter) initGRPC(u *url.URL) {
+	creds := grpc.WithInsecure()
+	if u.Scheme == "grpcs" {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	}
+	// Dialing is non-blocking: connection errors are reported by the
+	// export requests.
+	conn, err := grpc.Dial(u.Host, creds)
+	if err != nil {
+		e.send = func(context.Context, *otlppb.ExportTraceServiceRequest) error {
+			return err
+		}
+		e.closeTransport = func() {}
+		return
+	}
+	client := otlppb.NewTraceServiceClient(conn)
+	e.send = func(ctx context.Context, req *otlppb.ExportTraceServiceRequest) error {
+		_, err := client.Export(ctx, req)
+		return err
+	}
+	e.closeTransport = func() { _ = conn.Close() }
+}
+
+// initHTTP sets up the exporter to send spans using OTLP/HTTP, with
+// protobuf-encoded payloads.
+func (e *otlpExporter) initHTTP(u *url.URL) {
+	client := httputil.NewClientWithTimeout(otlpExportTimeout)
+	address := u.String()
+	e.send = func(ctx context.Context, req *otlppb.ExportTraceServiceRequest) error {
+		body, err := protoutil.Marshal(req)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Post(ctx, address, "application/x-protobuf", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		// Drain the body so that the connection can be reused.
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return errors.Newf("unexpected HTTP status: %s", resp.Status)
+		}
+		return nil
+	}
+	e.closeTransport = func() {}
+}
+
+// enqueue adds a finished span to the spans to export. The span is
+// dropped if too many spans are waiting already, or if the exporter is
+// closed.
+func (e *otlpExporter) enqueue(span otlppb.Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.mu.closed || len(e.mu.spans) >= otlpMaxQueueSize {
+		e.mu.dropped++
+		return
+	}
+	e.mu.spans = append(e.mu.spans, span)
+	if len(e.mu.spans) == otlpMaxBatchSize {
+		select {
+		case e.flushC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports the finished spans periodically, until the exporter is
+// closed.
+func (e *otlpExporter) run() {
+	defer close(e.doneC)
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stopC:
+			ctx, cancel := context.WithTimeout(context.Background(), otlpCloseTimeout)
+			e.flush(ctx)
+			cancel()
+			e.closeTransport()
+			return
+		case <-ticker.C:
+		case <-e.flushC:
+		}
+		e.flush(context.Background())
+	}
+}
+
+// flush exports the finished spans, in batches of at most
+// otlpMaxBatchSize spans. Spans which cannot be exported are dropped,
+// including the spans remaining once the context is done.
+func (e *otlpExporter) flush(ctx context.Context) {
+	for {
+		e.mu.Lock()
+		n := len(e.mu.spans)
+		if n > otlpMaxBatchSize {
+			n = otlpMaxBatchSize
+		}
+		batch := e.mu.spans[:n:n]
+		e.mu.spans = e.mu.spans[n:]
+		dropped := e.mu.dropped
+		e.mu.dropped = 0
+		e.mu.Unlock()
+
+		if dropped > 0 && otlpLogEveryN.ShouldProcess(timeutil.Now()) {
+			fmt.Fprintf(os.Stderr, "OpenTelemetry exporter: queue full, dropped %d spans\n", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			e.mu.Lock()
+			e.mu.dropped += len(batch) + len(e.mu.spans)
+			e.mu.spans = nil
+			e.mu.Unlock()
+			return
+		}
+		req := &otlppb.ExportTraceServiceRequest{
+			ResourceSpans: []otlppb.ResourceSpans{{
+				Resource: e.resource,
+				InstrumentationLibrarySpans: []otlppb.InstrumentationLibrarySpans{{
+					InstrumentationLibrary: otlppb.InstrumentationLibrary{
+						Name: "github.com/cockroachdb/cockroach/pkg/util/tracing",
+					},
+					Spans: batch,
+				}},
+			}},
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, otlpExportTimeout)
+		err := e.send(sendCtx, req)
+		cancel()
+		if err != nil && otlpLogEveryN.ShouldProcess(timeutil.Now()) {
+			// We can't use `log` from this package so print errors to stderr.
+			fmt.Fprintf(os.Stderr, "OpenTelemetry exporter: dropped %d spans: %v\n", len(batch), err)
+		}
+	}
+}
+
+// close stops the exporter. The remaining spans are exported in the
+// background, for at most otlpCloseTimeout, and doneC is closed once
+// they are. Spans finished afterwards are dropped.
+func (e *otlpExporter) close() {
+	e.mu.Lock()
+	if e.mu.closed {
+		e.mu.Unlock()
+		return
+	}
+	e.mu.closed = true
+	e.mu.Unlock()
+	close(e.stopC)
+}
@@ -0,0 +1,255 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing/otlppb"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	testCases := []struct {
+		s       string
+		sampled bool
+		err     bool
+	}{
+		{s: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", sampled: true},
+		{s: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00", sampled: false},
+		// Future versions may have more fields.
+		{s: "01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-03-xyz", sampled: true},
+		{s: "", err: true},
+		{s: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-xyz", err: true},
+		{s: "ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", err: true},
+		{s: "00-0AF7651916CD43DD8448EB211C80319C-B7AD6B7169203331-01", err: true},
+		{s: "00-00000000000000000000000000000000-b7ad6b7169203331-01", err: true},
+		{s: "00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01", err: true},
+		{s: "00_0af7651916cd43dd8448eb211c80319c_b7ad6b7169203331_01", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			c, err := parseTraceParent(tc.s)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "0af7651916cd43dd8448eb211c80319c", hex.EncodeToString(c.traceID[:]))
+			require.Equal(t, "b7ad6b7169203331", hex.EncodeToString(c.spanID[:]))
+			require.Equal(t, tc.sampled, c.sampled)
+		})
+	}
+}
+
+func TestParseOTLPCollector(t *testing.T) {
+	testCases := []struct {
+		s   string
+		exp string
+		err string
+	}{
+		{s: "grpc://127.0.0.1:4317", exp: "grpc://127.0.0.1:4317"},
+		{s: "grpcs://collector:4317", exp: "grpcs://collector:4317"},
+		{s: "http://127.0.0.1:4318", exp: "http://127.0.0.1:4318/v1/traces"},
+		{s: "https://collector/otlp/traces", exp: "https://collector/otlp/traces"},
+		{s: "127.0.0.1:4317", err: "invalid collector URL"},
+		{s: "udp://127.0.0.1:4317", err: "unsupported scheme"},
+		{s: "grpc://127.0.0.1:4317/v1/traces", err: "OTLP/gRPC does not use a path"},
+		{s: "http:///v1/traces", err: "missing host"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			u, err := parseOTLPCollector(tc.s)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, u.String())
+		})
+	}
+}
+
+func TestShouldSample(t *testing.T) {
+	var low, high [16]byte
+	high[8] = 0xff
+	require.True(t, shouldSample(high, 1))
+	require.False(t, shouldSample(low, 0))
+	require.True(t, shouldSample(low, 0.5))
+	require.False(t, shouldSample(high, 0.5))
+}
+
+func TestOTLPTracer(t *testing.T) {
+	requests := make(chan *otlppb.ExportTraceServiceRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != otlpDefaultHTTPPath || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("unexpected request: %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var req otlppb.ExportTraceServiceRequest
+		if err := protoutil.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+			return
+		}
+		requests <- &req
+	}))
+	defer server.Close()
+
+	tr := NewTracer()
+	sv := settings.Values{}
+	// Only traces started by clients are sampled.
+	otlpSampleRate.Override(&sv, 0)
+	tr.Configure(&sv)
+	require.NoError(t, settings.NewUpdater(&sv).Set(
+		"trace.opentelemetry.collector", server.URL, otlpCollector.Typ()))
+	defer tr.Close()
+
+	// A span not connected to a client span is not exported.
+	unsampled := tr.StartSpan("unsampled")
+	unsampled.Finish()
+
+	// A span connected to a sampled client span is exported, along with
+	// its descendants, including remote ones.
+	const traceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	remoteParent := tr.RemoteParentFromTraceParent(traceParent)
+	require.NotNil(t, remoteParent)
+	root := tr.StartSpan("root", WithParentAndManualCollection(remoteParent))
+	root.SetTag("foo", "bar")
+	root.SetTag("count", 3)
+	child := tr.StartSpan("child", WithParentAndAutoCollection(root), WithTags(ext.SpanKindRPCClient))
+	child.Record("hello")
+
+	// The context of the child span is propagated using the W3C format.
+	carrier := make(opentracing.HTTPHeadersCarrier)
+	require.NoError(t, tr.Inject(child.Meta(), opentracing.HTTPHeaders, carrier))
+	require.Regexp(t, `^00-0af7651916cd43dd8448eb211c80319c-[0-9a-f]{16}-01$`,
+		http.Header(carrier).Get(prefixShadow+traceParentKey))
+	wireContext, err := tr.Extract(opentracing.HTTPHeaders, carrier)
+	require.NoError(t, err)
+	remote := tr.StartSpan("remote", WithParentAndManualCollection(wireContext),
+		WithTags(ext.SpanKindRPCServer))
+	remote.SetTag(string(ext.Error), true)
+
+	remote.Finish()
+	child.Finish()
+	root.Finish()
+
+	var spans []otlppb.Span
+	for len(spans) < 3 {
+		select {
+		case req := <-requests:
+			require.Len(t, req.ResourceSpans, 1)
+			require.Len(t, req.ResourceSpans[0].InstrumentationLibrarySpans, 1)
+			spans = append(spans, req.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans...)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout; received %d spans", len(spans))
+		}
+	}
+	require.Len(t, spans, 3)
+	byName := make(map[string]otlppb.Span)
+	for _, sp := range spans {
+		require.Equal(t, "0af7651916cd43dd8448eb211c80319c", hex.EncodeToString(sp.TraceID))
+		byName[sp.Name] = sp
+	}
+
+	rootSp := byName["root"]
+	require.Equal(t, "b7ad6b7169203331", hex.EncodeToString(rootSp.ParentSpanID))
+	require.Equal(t, otlppb.Span_SPAN_KIND_INTERNAL, rootSp.Kind)
+	require.Contains(t, rootSp.Attributes, otlppb.KeyValue{Key: "foo", Value: otlpValue("bar")})
+	require.Contains(t, rootSp.Attributes, otlppb.KeyValue{Key: "count", Value: otlpValue(3)})
+
+	childSp := byName["child"]
+	require.Equal(t, rootSp.SpanID, childSp.ParentSpanID)
+	require.Equal(t, otlppb.Span_SPAN_KIND_CLIENT, childSp.Kind)
+	require.Len(t, childSp.Events, 1)
+	require.Equal(t, "hello", childSp.Events[0].Name)
+
+	remoteSp := byName["remote"]
+	require.Equal(t, childSp.SpanID, remoteSp.ParentSpanID)
+	require.Equal(t, otlppb.Span_SPAN_KIND_SERVER, remoteSp.Kind)
+	require.Equal(t, otlppb.Status_STATUS_CODE_ERROR, remoteSp.Status.Code)
+}
+
+func TestOTLPTracerInvalidCollector(t *testing.T) {
+	// The setting is validated, but not the COCKROACH_TEST_OTLP_COLLECTOR
+	// environment variable providing its default value. An invalid URL
+	// disables the shadow tracer.
+	manager, otlpTr := createOTLPTracer("otlp://collector", func() float64 { return 1 })
+	require.Nil(t, manager)
+	require.Nil(t, otlpTr)
+
+	tr := NewTracer()
+	tr.setShadowTracer(manager, otlpTr)
+	defer tr.Close()
+	require.Nil(t, tr.getShadowTracer())
+}
+
+func TestOTLPTracerCloseDoesNotBlock(t *testing.T) {
+	received := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	var unblockOnce sync.Once
+	unblockServer := func() { unblockOnce.Do(func() { close(unblock) }) }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+		<-unblock
+	}))
+	defer server.Close()
+	defer unblockServer()
+
+	tr := NewTracer()
+	sv := settings.Values{}
+	otlpSampleRate.Override(&sv, 1)
+	tr.Configure(&sv)
+	u := settings.NewUpdater(&sv)
+	require.NoError(t, u.Set("trace.opentelemetry.collector", server.URL, otlpCollector.Typ()))
+	defer tr.Close()
+	exporter := tr.getShadowTracer().manager.(*otlpManager).exporter
+
+	sp := tr.StartSpan("op", WithForceRealSpan())
+	sp.Finish()
+	select {
+	case <-received:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the export request")
+	}
+
+	// Disabling the exporter while the collector is unresponsive doesn't
+	// wait for the export requests.
+	start := time.Now()
+	require.NoError(t, u.Set("trace.opentelemetry.collector", "", otlpCollector.Typ()))
+	require.Less(t, int64(time.Since(start)), int64(otlpCloseTimeout))
+	require.Nil(t, tr.getShadowTracer())
+
+	unblockServer()
+	select {
+	case <-exporter.doneC:
+	case <-time.After(otlpExportTimeout + otlpCloseTimeout):
+		t.Fatal("timeout waiting for the exporter to stop")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/iterutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/logtags"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/petermattis/goid"
@@ -115,6 +116,29 @@ var zipkinCollector = settings.RegisterStringSetting(
 	envutil.EnvOrDefaultString("COCKROACH_TEST_ZIPKIN_COLLECTOR", ""),
 ).WithPublic()
 
+var otlpCollector = settings.RegisterValidatedStringSetting(
+	"trace.opentelemetry.collector",
+	"if set, traces go to the given OpenTelemetry collector, using OTLP/gRPC "+
+		"(example: 'grpc://127.0.0.1:4317', or 'grpcs://' for TLS) or OTLP/HTTP "+
+		"(example: 'http://127.0.0.1:4318/v1/traces'); "+
+		"ignored if trace.lightstep.token or trace.zipkin.collector is set",
+	envutil.EnvOrDefaultString("COCKROACH_TEST_OTLP_COLLECTOR", ""),
+	validateOTLPCollector,
+).WithPublic()
+
+var otlpSampleRate = settings.RegisterFloatSetting(
+	"trace.opentelemetry.sample_rate",
+	"the probability that a trace not started by a client is sent to the OpenTelemetry collector "+
+		"(traces started by clients follow the sampling decision of the client)",
+	1,
+	func(f float64) error {
+		if f < 0 || f > 1 {
+			return errors.New("value must be between 0 and 1 inclusive")
+		}
+		return nil
+	},
+).WithPublic()
+
 // Tracer is our own custom implementation of opentracing.Tracer. It supports:
 //
 //  - forwarding events to x/net/trace instances
@@ -186,6 +210,10 @@ func (t *Tracer) Configure(sv *settings.Values) {
 			t.setShadowTracer(createLightStepTracer(lsToken))
 		} else if zipkinAddr := zipkinCollector.Get(sv); zipkinAddr != "" {
 			t.setShadowTracer(createZipkinTracer(zipkinAddr))
+		} else if otlpAddr := otlpCollector.Get(sv); otlpAddr != "" {
+			t.setShadowTracer(createOTLPTracer(otlpAddr, func() float64 {
+				return otlpSampleRate.Get(sv)
+			}))
 		} else {
 			t.setShadowTracer(nil, nil)
 		}
@@ -201,6 +229,7 @@ func (t *Tracer) Configure(sv *settings.Values) {
 	enableNetTrace.SetOnChange(sv, reconfigure)
 	lightstepToken.SetOnChange(sv, reconfigure)
 	zipkinCollector.SetOnChange(sv, reconfigure)
+	otlpCollector.SetOnChange(sv, reconfigure)
 }
 
 func (t *Tracer) useNetTrace() bool {
@@ -330,6 +359,8 @@ func (t *Tracer) startSpanGeneric(
 			var shadowCtx opentracing.SpanContext
 			if opts.Parent != nil && opts.Parent.ot.shadowSpan != nil {
 				shadowCtx = opts.Parent.ot.shadowSpan.Context()
+			} else if opts.RemoteParent != nil {
+				shadowCtx = opts.RemoteParent.shadowCtx
 			}
 			ot = makeShadowSpan(shadowTr, shadowCtx, opts.RefType, opName, startTime)
 			// If LogTags are given, pass them as tags to the shadow span.